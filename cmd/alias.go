@@ -0,0 +1,327 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements command aliases from the configuration file and the 'alias' commands managing them.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// aliasesKey is the section of the configuration file mapping alias names to the commands they run.
+const aliasesKey = "aliases"
+
+// aliasListOutput is the output format of alias list.
+var aliasListOutput string
+
+// aliasCmd represents the alias command.
+// It serves as a parent command for managing the aliases of the configuration file.
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage command aliases",
+	Long: `Manage command aliases, which the aliases section of the configuration file
+maps to the commands they run:
+
+  aliases:
+    ld: list deployments -o wide
+
+'kc ld -n web' then runs 'kc list deployments -o wide -n web'. The command is
+split into words at whitespace, and aliases do not expand within aliases.
+Built-in commands take precedence over aliases of the same name.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// aliasListCmd represents the alias list command.
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the command aliases",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runAliasList(os.Stdout, aliasConfigFile()); err != nil {
+			log.Error().Err(err).Msg("Failed to list aliases")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// aliasAddCmd represents the alias add command.
+var aliasAddCmd = &cobra.Command{
+	Use:   "add NAME COMMAND...",
+	Short: "Add or replace a command alias",
+	Long: `Add a command alias to the configuration file, replacing an alias of the same name.
+Flags after NAME belong to the aliased command.
+
+Examples:
+  kc alias add ld list deployments -o wide
+  kc alias add prod-pods top pods --context prod`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runAliasAdd(os.Stdout, aliasConfigFile(), args[0], args[1:]); err != nil {
+			log.Error().Err(err).Msg("Failed to add alias")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// aliasRemoveCmd represents the alias remove command.
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a command alias",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runAliasRemove(os.Stdout, aliasConfigFile(), args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to remove alias")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// aliasConfigFile returns the configuration file holding the aliases: --config, or the default one.
+func aliasConfigFile() string {
+	if configFile != "" {
+		return configFile
+	}
+	path, err := defaultConfigFile()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// loadAliases returns the aliases of the configuration file at path. A missing file has none.
+func loadAliases(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	var config struct {
+		Aliases map[string]string `yaml:"aliases"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid aliases in config file %s: %w", path, err)
+	}
+	if config.Aliases == nil {
+		config.Aliases = map[string]string{}
+	}
+	return config.Aliases, nil
+}
+
+// expandAlias replaces an alias at the start of the command-line arguments with the command it runs.
+// The configuration file is taken from --config or KC_CONFIG, as the flags are not parsed yet.
+// Arguments that do not start with an alias, or whose aliases cannot be read, are returned as is.
+func expandAlias(args []string) []string {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(args) {
+		return args
+	}
+	path := commandLineConfigFile(args)
+	if path == "" {
+		if path = os.Getenv(envName("config")); path == "" {
+			path = aliasConfigFile()
+		}
+	}
+	aliases, err := loadAliases(path)
+	if err != nil {
+		return args
+	}
+	command, ok := aliases[args[0]]
+	if !ok {
+		return args
+	}
+	return append(strings.Fields(command), args[1:]...)
+}
+
+// commandLineConfigFile returns the value of --config in the command-line arguments, if any.
+func commandLineConfigFile(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			return value
+		}
+		if arg == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// validateAliasName checks that name can be used as an alias.
+func validateAliasName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n") {
+		return newUsageError("invalid alias name %q", name)
+	}
+	if isBuiltinCommand([]string{name}) {
+		return fmt.Errorf("alias %q would be hidden by the command of the same name", name)
+	}
+	return nil
+}
+
+// runAliasList lists the aliases of the configuration file in the selected output format.
+func runAliasList(out io.Writer, path string) error {
+	if err := validateOutputFormat(aliasListOutput); err != nil {
+		return err
+	}
+	aliases, err := loadAliases(path)
+	if err != nil {
+		return err
+	}
+
+	switch aliasListOutput {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(aliases)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(aliases)
+	}
+	if len(aliases) == 0 {
+		_, err := fmt.Fprintln(out, "No aliases found.")
+		return err
+	}
+	w, flush := newTableWriter(out, "")
+	defer flush()
+	if _, err := fmt.Fprintln(w, "ALIAS\tCOMMAND"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", name, aliases[name]); err != nil {
+			return fmt.Errorf("failed to write alias row: %w", err)
+		}
+	}
+	return nil
+}
+
+// runAliasAdd adds the alias of the command to the configuration file at path.
+func runAliasAdd(out io.Writer, path, name string, command []string) error {
+	if err := validateAliasName(name); err != nil {
+		return err
+	}
+	value := strings.Join(command, " ")
+	err := updateAliases(path, func(aliases *yaml.Node) error {
+		if node := mappingValue(aliases, name); node != nil {
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+			return nil
+		}
+		aliases.Content = append(aliases.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Alias %q set to %q.\n", name, value)
+	return err
+}
+
+// runAliasRemove removes the alias from the configuration file at path.
+func runAliasRemove(out io.Writer, path, name string) error {
+	err := updateAliases(path, func(aliases *yaml.Node) error {
+		for i := 0; i+1 < len(aliases.Content); i += 2 {
+			if aliases.Content[i].Value == name {
+				aliases.Content = slices.Delete(aliases.Content, i, i+2)
+				return nil
+			}
+		}
+		return fmt.Errorf("alias %q not found", name)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Alias %q removed.\n", name)
+	return err
+}
+
+// mappingValue returns the value of key in a YAML mapping node, or nil if it has none.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// updateAliases applies update to the aliases mapping of the configuration file at path and writes
+// the file back. The file is edited as a YAML document, so its other settings and comments are kept.
+// A missing file is created.
+func updateAliases(path string, update func(aliases *yaml.Node) error) error {
+	if path == "" {
+		return fmt.Errorf("no config file: set --config")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a mapping of settings", path)
+	}
+	aliases := mappingValue(root, aliasesKey)
+	if aliases == nil {
+		aliases = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: aliasesKey}, aliases)
+	}
+	if aliases.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s in config file %s is not a mapping of names to commands", aliasesKey, path)
+	}
+	aliases.Style &^= yaml.FlowStyle
+	if err := update(aliases); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasListCmd, aliasAddCmd, aliasRemoveCmd)
+
+	aliasListCmd.Flags().StringVarP(&aliasListOutput, "output", "o", "table",
+		"Output format (table|json|yaml)")
+	aliasAddCmd.Flags().SetInterspersed(false)
+}
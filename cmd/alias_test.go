@@ -0,0 +1,171 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the command aliases and the alias commands.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeAliasConfig writes a configuration file with the given content and returns its path.
+func writeAliasConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestExpandAlias verifies that aliases at the start of the arguments are replaced by their commands.
+func TestExpandAlias(t *testing.T) {
+	path := writeAliasConfig(t, "aliases:\n  ld: list deployments -o wide\n  version: list deployments\n")
+	t.Setenv("KC_CONFIG", path)
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"alias", []string{"ld", "-n", "web"}, []string{"list", "deployments", "-o", "wide", "-n", "web"}},
+		{"builtin wins", []string{"version"}, []string{"version"}},
+		{"not an alias", []string{"lp"}, []string{"lp"}},
+		{"flag first", []string{"--log-level", "debug", "ld"}, []string{"--log-level", "debug", "ld"}},
+		{"no arguments", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandAlias(tt.args); !slices.Equal(got, tt.want) {
+				t.Errorf("expandAlias(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExpandAliasConfigFlag verifies that --config selects the configuration file of the aliases.
+func TestExpandAliasConfigFlag(t *testing.T) {
+	path := writeAliasConfig(t, "aliases:\n  ld: list deployments\n")
+	t.Setenv("KC_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	for _, args := range [][]string{{"ld", "--config", path}, {"ld", "--config=" + path}} {
+		want := append([]string{"list", "deployments"}, args[1:]...)
+		if got := expandAlias(args); !slices.Equal(got, want) {
+			t.Errorf("expandAlias(%v) = %v, want %v", args, got, want)
+		}
+	}
+}
+
+// TestRunAliasAdd verifies that aliases are added and replaced while other settings and comments are kept.
+func TestRunAliasAdd(t *testing.T) {
+	path := writeAliasConfig(t, "# defaults\nnamespace: staging\n")
+
+	var buf bytes.Buffer
+	if err := runAliasAdd(&buf, path, "ld", []string{"list", "deployments"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAliasAdd(&buf, path, "ld", []string{"list", "deployments", "-o", "wide"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAliasAdd(&buf, path, "tp", []string{"top", "pods"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# defaults\nnamespace: staging\naliases:\n  ld: list deployments -o wide\n  tp: top pods\n"
+	if string(data) != want {
+		t.Errorf("expected config file:\n%s\ngot:\n%s", want, data)
+	}
+}
+
+// TestRunAliasAddInvalid verifies that aliases hidden by commands and invalid names are rejected.
+func TestRunAliasAddInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	for _, name := range []string{"version", "list", "-x", "l d", ""} {
+		if err := runAliasAdd(&bytes.Buffer{}, path, name, []string{"top", "pods"}); err == nil {
+			t.Errorf("expected an error for alias %q", name)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no config file to be written, got %v", err)
+	}
+}
+
+// TestRunAliasAddCreatesFile verifies that a missing configuration file and its directory are created.
+func TestRunAliasAddCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k8s-controller", "config.yaml")
+	if err := runAliasAdd(&bytes.Buffer{}, path, "ld", []string{"list", "deployments"}); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := loadAliases(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aliases["ld"] != "list deployments" {
+		t.Errorf("expected alias ld, got %v", aliases)
+	}
+}
+
+// TestRunAliasRemove verifies removing aliases.
+func TestRunAliasRemove(t *testing.T) {
+	path := writeAliasConfig(t, "aliases:\n  ld: list deployments\n  tp: top pods\n")
+
+	if err := runAliasRemove(&bytes.Buffer{}, path, "ld"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAliasRemove(&bytes.Buffer{}, path, "ld"); err == nil {
+		t.Error("expected an error for a missing alias")
+	}
+	aliases, err := loadAliases(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 1 || aliases["tp"] != "top pods" {
+		t.Errorf("expected only alias tp, got %v", aliases)
+	}
+}
+
+// TestRunAliasList verifies listing aliases as a table and as JSON.
+func TestRunAliasList(t *testing.T) {
+	defer func() { aliasListOutput = "table" }()
+	path := writeAliasConfig(t, "aliases:\n  tp: top pods\n  ld: list deployments\n")
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"table", []string{"ALIAS", "ld     list deployments\ntp     top pods"}},
+		{"json", []string{`"ld": "list deployments"`, `"tp": "top pods"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			aliasListOutput = tt.format
+			var buf bytes.Buffer
+			if err := runAliasList(&buf, path); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected %q in output, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	aliasListOutput = "table"
+	var buf bytes.Buffer
+	if err := runAliasList(&buf, filepath.Join(t.TempDir(), "missing.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "No aliases found.\n" {
+		t.Errorf("expected no aliases, got %q", buf.String())
+	}
+}
@@ -0,0 +1,177 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'api-resources' and 'api-versions' discovery commands.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags of the api-resources command.
+var (
+	// apiResourcesGroup limits the listing to one API group.
+	apiResourcesGroup string
+
+	// apiResourcesNamespaced limits the listing to namespaced or cluster-scoped resources, if set.
+	apiResourcesNamespaced bool
+
+	// apiResourcesVerbs limits the listing to resources supporting all of these verbs.
+	apiResourcesVerbs []string
+
+	// apiResourcesOpts are the connection and timeout flags of api-resources.
+	apiResourcesOpts clientOptions
+
+	// apiVersionsOpts are the connection and timeout flags of api-versions.
+	apiVersionsOpts clientOptions
+)
+
+// apiResourcesCmd represents the api-resources command.
+var apiResourcesCmd = &cobra.Command{
+	Use:   "api-resources",
+	Short: "List the API resources served by the cluster",
+	Long: `List the resources served by the cluster at the preferred version of each
+API group, including custom resources, with their short names, scope and
+supported verbs. Use --api-group=core for the core ("v1") group.
+
+Examples:
+  kc api-resources
+  kc api-resources --api-group=apps
+  kc api-resources --namespaced=false
+  kc api-resources --verbs=list,watch -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		filter := k8s.APIResourcesOptions{APIGroup: apiResourcesGroup, Verbs: apiResourcesVerbs}
+		if cmd.Flags().Changed("namespaced") {
+			filter.Namespaced = &apiResourcesNamespaced
+		}
+		if err := runAPIResources(os.Stdout, &apiResourcesOpts, filter); err != nil {
+			log.Error().Err(err).Msg("Failed to list API resources")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// apiVersionsCmd represents the api-versions command.
+var apiVersionsCmd = &cobra.Command{
+	Use:   "api-versions",
+	Short: "List the API versions served by the cluster",
+	Long: `List the API versions served by the cluster, in the form "group/version".
+The core group is listed as "v1".
+
+Examples:
+  kc api-versions`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runAPIVersions(os.Stdout, &apiVersionsOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to list API versions")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runAPIResources lists the API resources matching filter.
+func runAPIResources(out io.Writer, opts *clientOptions, filter k8s.APIResourcesOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	resources, err := client.APIResources(filter)
+	if err != nil {
+		return err
+	}
+	return formatAPIResourcesOutput(out, resources, opts.output)
+}
+
+// runAPIVersions lists the API versions, one per line.
+func runAPIVersions(out io.Writer, opts *clientOptions) error {
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	versions, err := client.APIVersions()
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if _, err := fmt.Fprintln(out, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatAPIResourcesOutput writes API resources in the given output format.
+func formatAPIResourcesOutput(out io.Writer, resources []k8s.APIResourceInfo, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resources)
+	case "yaml":
+		data, err := yaml.Marshal(resources)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case "table":
+		if len(resources) == 0 {
+			_, err := fmt.Fprintln(out, "No resources found.")
+			return err
+		}
+		return writeAPIResourcesTable(out, resources)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writeAPIResourcesTable writes API resources as an aligned table.
+func writeAPIResourcesTable(out io.Writer, resources []k8s.APIResourceInfo) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintln(w, "NAME\tSHORTNAMES\tAPIVERSION\tNAMESPACED\tKIND\tVERBS"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, r := range resources {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n", r.Name, strings.Join(r.ShortNames, ","),
+			r.APIVersion, r.Namespaced, r.Kind, strings.Join(r.Verbs, ",")); err != nil {
+			return fmt.Errorf("failed to write resource row: %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(apiResourcesCmd, apiVersionsCmd)
+
+	apiResourcesCmd.Flags().StringVar(&apiResourcesGroup, "api-group", "",
+		"Limit to resources in the API group (core for the core group)")
+	apiResourcesCmd.Flags().BoolVar(&apiResourcesNamespaced, "namespaced", true,
+		"If set, limit to namespaced (true) or cluster-scoped (false) resources")
+	apiResourcesCmd.Flags().StringSliceVar(&apiResourcesVerbs, "verbs", nil,
+		"Limit to resources supporting all of the given verbs")
+	apiResourcesCmd.Flags().StringVarP(&apiResourcesOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+
+	addClientFlags(apiResourcesCmd, &apiResourcesOpts, 30)
+	addClientFlags(apiVersionsCmd, &apiVersionsOpts, 30)
+}
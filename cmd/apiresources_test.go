@@ -0,0 +1,87 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the api-resources and api-versions commands.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestAPIResourcesCommandsDefined verifies that the discovery commands are registered with the expected flags.
+func TestAPIResourcesCommandsDefined(t *testing.T) {
+	for _, name := range []string{"api-group", "namespaced", "verbs", "output", "kubeconfig", "timeout"} {
+		if apiResourcesCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined on api-resources", name)
+		}
+	}
+	if apiVersionsCmd.Flags().Lookup("context") == nil {
+		t.Error("expected 'context' flag to be defined on api-versions")
+	}
+}
+
+// TestRunAPIDiscoveryDemo verifies listing the versions and resources of the demo cluster.
+func TestRunAPIDiscoveryDemo(t *testing.T) {
+	demoMode = true
+	defer func() { demoMode = false }()
+
+	var out bytes.Buffer
+	if err := runAPIVersions(&out, newTestClientOptions("")); err != nil {
+		t.Fatalf("runAPIVersions() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "apps/v1\n") || !strings.HasSuffix(out.String(), "\nv1\n") {
+		t.Errorf("expected one version per line, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runAPIResources(&out, newTestClientOptions(""), k8s.APIResourcesOptions{APIGroup: "apps"}); err != nil {
+		t.Fatalf("runAPIResources() error = %v", err)
+	}
+	for _, want := range []string{"NAME", "SHORTNAMES", "deploy", "apps/v1", "StatefulSet"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "ConfigMap") {
+		t.Errorf("expected only the apps group, got:\n%s", out.String())
+	}
+}
+
+// TestFormatAPIResourcesOutput verifies all output formats of the api-resources command.
+func TestFormatAPIResourcesOutput(t *testing.T) {
+	resources := []k8s.APIResourceInfo{{
+		Name: "deployments", ShortNames: []string{"deploy"}, APIVersion: "apps/v1", Namespaced: true,
+		Kind: "Deployment", Verbs: []string{"get", "list"},
+	}}
+
+	tests := []struct {
+		name      string
+		resources []k8s.APIResourceInfo
+		format    string
+		contains  []string
+		wantErr   bool
+	}{
+		{"table", resources, "table", []string{"APIVERSION", "deploy", "true", "get,list"}, false},
+		{"empty table", nil, "table", []string{"No resources found."}, false},
+		{"json", resources, "json", []string{`"shortNames": [`, `"namespaced": true`}, false},
+		{"yaml", resources, "yaml", []string{"apiVersion: apps/v1", "kind: Deployment"}, false},
+		{"unsupported", resources, "xml", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := formatAPIResourcesOutput(&out, tt.resources, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatAPIResourcesOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'auth can-i' and 'auth whoami' commands which inspect the current user.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+var (
+	// canIList dumps all permitted actions instead of checking a single one.
+	canIList bool
+
+	// canIOpts are the namespace, connection and timeout flags of auth can-i.
+	canIOpts clientOptions
+
+	// whoamiOpts are the connection and timeout flags of auth whoami.
+	whoamiOpts clientOptions
+)
+
+// authCmd represents the auth command.
+// It serves as a parent command for authorization checks.
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect authorization",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// canICmd represents the auth can-i command.
+var canICmd = &cobra.Command{
+	Use:   "can-i VERB RESOURCE [NAME]",
+	Short: "Check whether an action is allowed",
+	Long: `Check whether the current user may perform an action.
+
+Prints "yes" and exits with status 0 if the action is allowed, or prints "no"
+and exits with status 1 otherwise. With --list, all actions permitted in the
+namespace are printed instead.
+
+RESOURCE accepts names and aliases (deploy, po, svc), subresources (pods/log)
+and group-qualified custom resources (widgets.example.com).
+
+Examples:
+  kc auth can-i create deployments -n web
+  kc auth can-i get pods/log
+  kc auth can-i delete deployment nginx
+  kc auth can-i --list -n kube-system`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if canIList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.RangeArgs(2, 3)(cmd, args)
+	},
+	Run: func(_ *cobra.Command, args []string) {
+		allowed, err := runCanI(os.Stdout, &canIOpts, args)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to check access")
+			exit(exitCode(err))
+		}
+		if !allowed {
+			exit(exitFailure)
+		}
+	},
+}
+
+// whoamiCmd represents the auth whoami command.
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the current user",
+	Long: `Show the user, groups and API server the client is authenticated against.
+
+The identity is resolved with a SelfSubjectReview on Kubernetes 1.28+. Older servers
+fall back to a TokenReview of the configured bearer token, or to the subject of the
+configured client certificate.
+
+Examples:
+  kc auth whoami
+  kc auth whoami --context prod -o json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runWhoAmI(&whoamiOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to resolve identity")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runWhoAmI resolves the current identity and prints it.
+func runWhoAmI(opts *clientOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	identity, err := client.WhoAmI(ctx)
+	if err != nil {
+		return err
+	}
+	return formatIdentityOutput(os.Stdout, identity, opts.output)
+}
+
+// formatIdentityOutput writes an identity in the given output format.
+func formatIdentityOutput(out io.Writer, identity k8s.Identity, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(identity)
+	case "yaml":
+		data, err := yaml.Marshal(identity)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case "table":
+		return writeIdentityTable(out, identity)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writeIdentityTable writes an identity as an ATTRIBUTE/VALUE table.
+func writeIdentityTable(out io.Writer, identity k8s.Identity) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	rows := [][2]string{{"Username", identity.Username}}
+	if identity.UID != "" {
+		rows = append(rows, [2]string{"UID", identity.UID})
+	}
+	rows = append(rows, [2]string{"Groups", bracketList(identity.Groups)})
+
+	keys := make([]string, 0, len(identity.Extra))
+	for key := range identity.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		rows = append(rows, [2]string{"Extra: " + key, bracketList(identity.Extra[key])})
+	}
+	rows = append(rows, [2]string{"Server", identity.Server}, [2]string{"Source", identity.Source})
+
+	if _, err := fmt.Fprintln(w, "ATTRIBUTE\tVALUE"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", row[0], row[1]); err != nil {
+			return fmt.Errorf("failed to write identity row: %w", err)
+		}
+	}
+	return nil
+}
+
+// runCanI performs the access check or rules listing and reports whether the action is allowed.
+func runCanI(out io.Writer, opts *clientOptions, args []string) (bool, error) {
+	client, err := opts.newClient()
+	if err != nil {
+		return false, err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	if canIList {
+		rules, incomplete, err := client.ListPermissions(ctx, opts.namespace)
+		if err != nil {
+			return false, err
+		}
+		return true, writePermissionRules(out, rules, incomplete)
+	}
+
+	check := k8s.AccessCheck{Verb: args[0], Resource: args[1], Namespace: opts.namespaceOrDefault()}
+	if len(args) == 3 {
+		check.Name = args[2]
+	}
+
+	result, err := client.CanI(ctx, check)
+	if err != nil {
+		return false, err
+	}
+	return result.Allowed, writeAccessResult(out, result)
+}
+
+// writeAccessResult prints "yes" or "no", followed by the reason if one was given.
+func writeAccessResult(out io.Writer, result k8s.AccessResult) error {
+	answer := "no"
+	if result.Allowed {
+		answer = "yes"
+	}
+	if result.Reason != "" {
+		answer += " - " + result.Reason
+	}
+	_, err := fmt.Fprintln(out, answer)
+	return err
+}
+
+// writePermissionRules prints permission rules as an aligned table.
+func writePermissionRules(out io.Writer, rules []k8s.PermissionRule, incomplete bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "RESOURCES\tNON-RESOURCE URLS\tRESOURCE NAMES\tVERBS"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, rule := range rules {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", qualifiedResources(rule),
+			bracketList(rule.NonResourceURLs), bracketList(rule.ResourceNames), bracketList(rule.Verbs)); err != nil {
+			return fmt.Errorf("failed to write rule row: %w", err)
+		}
+	}
+	flushTableWriter(w)
+
+	if incomplete {
+		_, err := fmt.Fprintln(out, "\nThe list may be incomplete: not all authorizers support listing rules.")
+		return err
+	}
+	return nil
+}
+
+// qualifiedResources formats the resources of a rule as resource.group, like kubectl.
+func qualifiedResources(rule k8s.PermissionRule) string {
+	var names []string
+	for _, resource := range rule.Resources {
+		for _, group := range rule.APIGroups {
+			if group == "" {
+				names = append(names, resource)
+			} else {
+				names = append(names, resource+"."+group)
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// bracketList formats a list as "[a b c]", or "[]" if it is empty.
+func bracketList(values []string) string {
+	return "[" + strings.Join(values, " ") + "]"
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(canICmd)
+
+	canICmd.Flags().BoolVar(&canIList, "list", false,
+		"List all actions permitted in the namespace")
+	canICmd.Flags().StringVarP(&canIOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addClientFlags(canICmd, &canIOpts, 30)
+
+	authCmd.AddCommand(whoamiCmd)
+	whoamiCmd.Flags().StringVarP(&whoamiOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+	addClientFlags(whoamiCmd, &whoamiOpts, 30)
+}
@@ -0,0 +1,116 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the auth can-i command and its output.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestCanICommandArgs verifies argument validation with and without --list.
+func TestCanICommandArgs(t *testing.T) {
+	defer func() { canIList = false }()
+
+	tests := []struct {
+		name    string
+		list    bool
+		args    []string
+		wantErr bool
+	}{
+		{"verb and resource", false, []string{"get", "pods"}, false},
+		{"with name", false, []string{"get", "pods", "web"}, false},
+		{"missing resource", false, []string{"get"}, true},
+		{"list", true, nil, false},
+		{"list with args", true, []string{"get", "pods"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canIList = tt.list
+			if err := canICmd.Args(canICmd, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("Args() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWriteAccessResult verifies the yes/no answers.
+func TestWriteAccessResult(t *testing.T) {
+	tests := []struct {
+		result k8s.AccessResult
+		want   string
+	}{
+		{k8s.AccessResult{Allowed: true}, "yes\n"},
+		{k8s.AccessResult{Reason: "no RBAC policy matched"}, "no - no RBAC policy matched\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := writeAccessResult(&out, tt.result); err != nil {
+			t.Fatalf("writeAccessResult() error = %v", err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("writeAccessResult() = %q, want %q", out.String(), tt.want)
+		}
+	}
+}
+
+// TestWritePermissionRules verifies the rules table and the incomplete notice.
+func TestWritePermissionRules(t *testing.T) {
+	rules := []k8s.PermissionRule{
+		{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+		{Verbs: []string{"get", "list"}, APIGroups: []string{"", "apps"}, Resources: []string{"deployments"}},
+	}
+
+	var out bytes.Buffer
+	if err := writePermissionRules(&out, rules, true); err != nil {
+		t.Fatalf("writePermissionRules() error = %v", err)
+	}
+	for _, want := range []string{"RESOURCES", "[/healthz]", "deployments, deployments.apps", "[get list]",
+		"may be incomplete"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+// TestFormatIdentityOutput verifies the output formats of the whoami command.
+func TestFormatIdentityOutput(t *testing.T) {
+	identity := k8s.Identity{
+		Username: "jane",
+		Groups:   []string{"devs", "system:authenticated"},
+		Extra:    map[string][]string{"scopes": {"read"}},
+		Server:   "https://k8s.example.com",
+		Source:   k8s.IdentitySourceSelfSubjectReview,
+	}
+
+	tests := []struct {
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{"table", []string{"ATTRIBUTE", "jane", "[devs system:authenticated]", "Extra: scopes",
+			"https://k8s.example.com"}, false},
+		{"json", []string{`"username": "jane"`, `"source": "SelfSubjectReview"`}, false},
+		{"yaml", []string{"username: jane"}, false},
+		{"xml", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			err := formatIdentityOutput(&out, identity, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatIdentityOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements listing deployments across all contexts of the kubeconfig.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// allContexts lists resources from the clusters of all kubeconfig contexts instead of the current one.
+var allContexts bool
+
+// runListDeploymentsAllContexts lists deployments in all clusters concurrently.
+// Clusters that fail are reported as warnings; it fails only if no cluster could be listed.
+func runListDeploymentsAllContexts(out io.Writer, opts *clientOptions) error {
+	if opts.contextName != "" {
+		return newUsageError("--all-contexts cannot be combined with --context")
+	}
+
+	set, err := createClusterSet(opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := set.Close(); closeErr != nil {
+			log.Warn().Err(closeErr).Msg("Failed to close Kubernetes clients")
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	deployments, err := set.ListDeployments(ctx, k8s.ListDeploymentsOptions{
+		Namespace:     opts.namespace,
+		LabelSelector: opts.labelSelector,
+		Limit:         chunkSize,
+	})
+	failures := k8s.ClusterErrors(err)
+	if len(failures) == len(set.Contexts()) && err != nil {
+		return enhanceK8sError(err, opts.namespace)
+	}
+	for _, failure := range failures {
+		log.Warn().Err(failure.Err).Str("context", failure.Context).Msg("Failed to list deployments in cluster")
+	}
+	return formatClusterDeploymentOutput(out, deployments, opts)
+}
+
+// createClusterSet creates clients for all contexts of the kubeconfig, or a single demo cluster in demo mode.
+func createClusterSet(opts *clientOptions) (*k8s.ClusterSet, error) {
+	contexts := []string{"demo"}
+	if !demoMode {
+		var err error
+		if contexts, err = k8s.KubeconfigContexts(opts.kubeconfigPath); err != nil {
+			return nil, err
+		}
+		if len(contexts) == 0 {
+			return nil, fmt.Errorf("no contexts found in kubeconfig")
+		}
+	}
+
+	configs := make([]k8s.ClientConfig, len(contexts))
+	for i, name := range contexts {
+		configs[i] = opts.clientConfig()
+		configs[i].Context = name
+	}
+	return k8s.NewClusterSet(configs, log.Logger)
+}
+
+// formatClusterDeploymentOutput prints deployments of several clusters in the output format of opts.
+func formatClusterDeploymentOutput(out io.Writer, deployments []k8s.ClusterDeploymentInfo, opts *clientOptions) error {
+	output := struct {
+		Kind       string                      `json:"kind" yaml:"kind"`
+		APIVersion string                      `json:"apiVersion" yaml:"apiVersion"`
+		Items      []k8s.ClusterDeploymentInfo `json:"items" yaml:"items"`
+		Count      int                         `json:"count" yaml:"count"`
+	}{
+		Kind:       "DeploymentList",
+		APIVersion: "apps/v1",
+		Items:      deployments,
+		Count:      len(deployments),
+	}
+
+	switch opts.output {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(output)
+	case "table", outputWide, outputMarkdown:
+		return writeClusterDeploymentTable(out, deployments, opts)
+	case outputName:
+		for _, deployment := range deployments {
+			if err := writeDeploymentName(out, deployment.DeploymentInfo); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return newUsageError("unsupported output format: %s", opts.output)
+	}
+}
+
+// writeClusterDeploymentTable writes deployments as a table with a leading CONTEXT column,
+// as a Markdown table for markdown output.
+func writeClusterDeploymentTable(out io.Writer, deployments []k8s.ClusterDeploymentInfo, opts *clientOptions) error {
+	if len(deployments) == 0 {
+		_, err := fmt.Fprintln(out, "No deployments found.")
+		return err
+	}
+
+	w, flush := newTableWriter(out, opts.output)
+	defer flush()
+
+	if err := writeTableHeader(w, opts, "CONTEXT"); err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		if _, err := fmt.Fprintf(w, "%s\t", deployment.Context); err != nil {
+			return fmt.Errorf("failed to write deployment row: %w", err)
+		}
+		if err := writeDeploymentRow(w, opts, deployment.DeploymentInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	listDeploymentsCmd.Flags().BoolVar(&allContexts, "all-contexts", false,
+		"List deployments from the clusters of all kubeconfig contexts, with a CONTEXT column")
+}
@@ -0,0 +1,71 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests listing deployments across all kubeconfig contexts.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestAllContextsFlagDefined verifies that list deployments accepts --all-contexts.
+func TestAllContextsFlagDefined(t *testing.T) {
+	flag := listDeploymentsCmd.Flags().Lookup("all-contexts")
+	if flag == nil {
+		t.Fatal("expected 'all-contexts' flag to be defined")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("expected default false, got %s", flag.DefValue)
+	}
+}
+
+// TestRunListDeploymentsAllContextsRejectsContext verifies that --context and --all-contexts conflict.
+func TestRunListDeploymentsAllContextsRejectsContext(t *testing.T) {
+	opts := newTestClientOptions("")
+	opts.contextName = "prod"
+
+	err := runListDeploymentsAllContexts(&bytes.Buffer{}, opts)
+	if err == nil || !strings.Contains(err.Error(), "--context") {
+		t.Errorf("expected an error about --context, got %v", err)
+	}
+}
+
+// TestFormatClusterDeploymentOutput verifies the CONTEXT column and the context field of JSON items.
+func TestFormatClusterDeploymentOutput(t *testing.T) {
+	deployment := k8s.ClusterDeploymentInfo{Context: "prod"}
+	deployment.Name, deployment.Namespace = "web", "default"
+	deployment.Images = []string{"nginx:1.25"}
+	deployments := []k8s.ClusterDeploymentInfo{deployment}
+
+	var table bytes.Buffer
+	if err := formatClusterDeploymentOutput(&table, deployments, &clientOptions{output: "table"}); err != nil {
+		t.Fatalf("table output failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "CONTEXT") || !strings.HasPrefix(lines[1], "prod") {
+		t.Errorf("expected a CONTEXT column, got:\n%s", table.String())
+	}
+
+	var out bytes.Buffer
+	if err := formatClusterDeploymentOutput(&out, deployments, &clientOptions{output: "json"}); err != nil {
+		t.Fatalf("json output failed: %v", err)
+	}
+	var decoded struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Items) != 1 || decoded.Items[0]["context"] != "prod" || decoded.Items[0]["name"] != "web" {
+		t.Errorf("expected a flat item with context and name, got %v", decoded.Items)
+	}
+
+	var empty bytes.Buffer
+	err := formatClusterDeploymentOutput(&empty, nil, &clientOptions{output: "table"})
+	if err != nil || !strings.Contains(empty.String(), "No deployments found") {
+		t.Errorf("expected the empty message, got %q (%v)", empty.String(), err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the ANSI coloring of table output.
+package cmd
+
+import (
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// ANSI color sequences. The colors all have the same length, so that tabwriter, which counts the
+// escape sequences as text, pads cells of the same column equally; see colorize.
+const (
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorDefault = "\x1b[39m"
+	colorDim     = "\x1b[90m"
+	colorReset   = "\x1b[0m"
+)
+
+var (
+	// noColor disables colored output even on a terminal.
+	noColor bool
+
+	// colorOutput colors table output. It is set when a command starts, see colorEnabled.
+	colorOutput bool
+)
+
+// colorEnabled reports whether output to f is colored: f must be a terminal, and neither
+// --no-color nor the NO_COLOR environment variable (https://no-color.org) may be set.
+func colorEnabled(f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// colorize wraps text in a color sequence if colored output is enabled. All cells of a column,
+// including its header, must be colorized to stay aligned; colorDefault keeps the terminal's color.
+func colorize(color, text string) string {
+	if !colorOutput {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// readyColor returns the color of a deployment's READY cell: red if no replica is ready,
+// yellow while a rollout is progressing and green once all replicas are ready and updated.
+func readyColor(deployment k8s.DeploymentInfo) string {
+	replicas := deployment.Replicas
+	switch {
+	case replicas.Desired > 0 && replicas.Ready == 0:
+		return colorRed
+	case replicas.Ready < replicas.Desired || replicas.Updated < replicas.Desired:
+		return colorYellow
+	default:
+		return colorGreen
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false,
+		"Disable colored table output (also disabled by NO_COLOR and when output is not a terminal)")
+}
@@ -0,0 +1,98 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the coloring of table output.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestColorEnabled verifies that colors are disabled for files that are not terminals and by NO_COLOR.
+func TestColorEnabled(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if colorEnabled(f) {
+		t.Error("expected no colors for a regular file")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if colorEnabled(os.Stdout) {
+		t.Error("expected NO_COLOR to disable colors")
+	}
+}
+
+// TestReadyColor verifies the colors of the READY column.
+func TestReadyColor(t *testing.T) {
+	tests := []struct {
+		name                    string
+		desired, ready, updated int32
+		want                    string
+	}{
+		{"none ready", 3, 0, 3, colorRed},
+		{"progressing", 3, 2, 3, colorYellow},
+		{"updating", 3, 3, 1, colorYellow},
+		{"healthy", 3, 3, 3, colorGreen},
+		{"scaled to zero", 0, 0, 0, colorGreen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deployment k8s.DeploymentInfo
+			deployment.Replicas.Desired = tt.desired
+			deployment.Replicas.Ready = tt.ready
+			deployment.Replicas.Updated = tt.updated
+			if got := readyColor(deployment); got != tt.want {
+				t.Errorf("readyColor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestColoredTableAlignment verifies that colored cells keep the columns of the table aligned.
+func TestColoredTableAlignment(t *testing.T) {
+	colorOutput = true
+	defer func() { colorOutput = false }()
+
+	var healthy, failing k8s.DeploymentInfo
+	healthy.Name, failing.Name = "web", "a-much-longer-name"
+	healthy.Replicas.Desired, healthy.Replicas.Ready, healthy.Replicas.Updated = 2, 2, 2
+	failing.Replicas.Desired = 10
+
+	opts := newTestClientOptions(testNamespaceDefault)
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	if err := writeTableHeader(w, opts); err != nil {
+		t.Fatal(err)
+	}
+	for _, deployment := range []k8s.DeploymentInfo{healthy, failing} {
+		if err := writeDeploymentRow(w, opts, deployment); err != nil {
+			t.Fatal(err)
+		}
+	}
+	flushTableWriter(w)
+
+	if !strings.Contains(out.String(), colorGreen+"2/2"+colorReset) ||
+		!strings.Contains(out.String(), colorRed+"0/10"+colorReset) {
+		t.Fatalf("expected colored READY cells, got %q", out.String())
+	}
+	plain := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(out.String(), "")
+	lines := strings.Split(strings.TrimSpace(plain), "\n")
+	for _, column := range []string{"UP-TO-DATE", "IMAGES"} {
+		offset := strings.Index(lines[0], column)
+		for _, line := range lines[1:] {
+			if offset >= len(line) || line[offset-1] != ' ' || line[offset] == ' ' {
+				t.Errorf("expected column %s at offset %d in:\n%s", column, offset, plain)
+			}
+		}
+	}
+}
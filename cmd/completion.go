@@ -0,0 +1,186 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements dynamic shell completion of namespaces, contexts and resource names.
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+const (
+	// completionTimeout bounds the API requests of a completion, so that a slow cluster does not block the shell.
+	completionTimeout = 3 * time.Second
+
+	// completionCacheTTL is how long completed names are reused, since every TAB runs a new process.
+	completionCacheTTL = 30 * time.Second
+)
+
+// completionCommands are the Kubernetes-facing commands with the options their completions connect with.
+// addConnectionFlags registers the commands; registerCompletions adds the flag completions.
+var completionCommands = map[*cobra.Command]*clientOptions{}
+
+// registerCompletions completes --namespace and --context of the Kubernetes-facing commands.
+// It runs once all commands have registered their flags.
+func registerCompletions() {
+	for cmd, opts := range completionCommands {
+		if cmd.Flags().Lookup("namespace") != nil {
+			_ = cmd.RegisterFlagCompletionFunc("namespace", completeNamespaceFlag(opts))
+		}
+		_ = cmd.RegisterFlagCompletionFunc("context", completeContexts(&opts.kubeconfigPath))
+	}
+}
+
+// completeNamespaceFlag completes --namespace with the namespaces of the cluster.
+func completeNamespaceFlag(opts *clientOptions) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return completeResourceNames(cmd, opts, "namespaces", "", toComplete)
+	}
+}
+
+// completeContexts completes the contexts of the kubeconfig at *kubeconfigPath, which is read
+// when completing, after the flags have been parsed.
+func completeContexts(kubeconfigPath *string) cobra.CompletionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		contexts, err := k8s.KubeconfigContexts(*kubeconfigPath)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterCompletions(contexts, "", toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFirstArg completes the first argument of a command, e.g. the NAME of 'get deployment NAME',
+// with the names of the resource.
+func completeFirstArg(opts *clientOptions, resource string) cobra.CompletionFunc {
+	return firstArgOnly(func(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion,
+		cobra.ShellCompDirective) {
+		return completeResourceNames(cmd, opts, resource, "", toComplete)
+	})
+}
+
+// firstArgOnly restricts the completion to the first argument of a command.
+func firstArgOnly(complete cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// completeResourceArgs completes the object names of commands taking TYPE/NAME or TYPE NAME.
+func completeResourceArgs(opts *clientOptions) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		switch {
+		case len(args) == 0 && strings.Contains(toComplete, "/"):
+			resource, _, _ := strings.Cut(toComplete, "/")
+			return completeResourceNames(cmd, opts, resource, resource+"/", toComplete)
+		case len(args) == 1 && !strings.Contains(args[0], "/"):
+			return completeResourceNames(cmd, opts, args[0], "", toComplete)
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
+
+// completeResourceNames completes the names of the objects of the resource, in the namespace of the
+// command for namespaced resources, each preceded by prefix. The KC_ environment variables and the
+// configuration file apply, as the command itself does not run. Errors complete nothing.
+func completeResourceNames(cmd *cobra.Command, opts *clientOptions, resource, prefix,
+	toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if err := applyEnvironment(cmd); err == nil {
+		_ = applyConfigFile(cmd)
+	}
+	info, err := k8s.LookupResource(resource)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ns := ""
+	if info.Namespaced {
+		ns = opts.namespaceOrDefault()
+	}
+
+	names, err := cachedCompletionNames(opts, info, ns)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterCompletions(names, prefix, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterCompletions returns the names preceded by prefix that start with toComplete.
+func filterCompletions(names []string, prefix, toComplete string) []cobra.Completion {
+	var completions []cobra.Completion
+	for _, name := range names {
+		if completion := prefix + name; strings.HasPrefix(completion, toComplete) {
+			completions = append(completions, completion)
+		}
+	}
+	return completions
+}
+
+// cachedCompletionNames returns the names of the objects of the resource in ns, from the completion
+// cache if it was filled within completionCacheTTL for the same cluster. The demo cluster is not cached.
+func cachedCompletionNames(opts *clientOptions, info k8s.ResourceInfo, ns string) ([]string, error) {
+	path := completionCachePath(opts, info, ns)
+	if path != "" && !demoMode {
+		if stat, err := os.Stat(path); err == nil && time.Since(stat.ModTime()) < completionCacheTTL {
+			if data, err := os.ReadFile(path); err == nil {
+				var names []string
+				if json.Unmarshal(data, &names) == nil {
+					return names, nil
+				}
+			}
+		}
+	}
+
+	names, err := fetchCompletionNames(opts, info, ns)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" && !demoMode {
+		if data, err := json.Marshal(names); err == nil && os.MkdirAll(filepath.Dir(path), 0o700) == nil {
+			_ = os.WriteFile(path, data, 0o600)
+		}
+	}
+	return names, nil
+}
+
+// fetchCompletionNames lists the names of the objects of the resource in ns within completionTimeout.
+func fetchCompletionNames(opts *clientOptions, info k8s.ResourceInfo, ns string) ([]string, error) {
+	client, err := k8s.CreateClient(opts.clientConfig(), zerolog.Nop())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	return client.ListNames(ctx, info.GVR, ns)
+}
+
+// completionCachePath returns the cache file of the names of the resource in ns of the cluster selected
+// by opts, in the user's cache directory, or "" if there is none.
+func completionCachePath(opts *clientOptions, info k8s.ResourceInfo, ns string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	key := strings.Join([]string{k8s.KubeconfigPath(opts.kubeconfigPath), opts.contextName, opts.apiServer,
+		opts.impersonateUser, info.GVR.String(), ns}, "\x00")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, "k8s-controller", "completion", hex.EncodeToString(sum[:16])+".json")
+}
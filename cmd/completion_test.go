@@ -0,0 +1,124 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the dynamic shell completion.
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestCompleteResourceArgs verifies completing object names against the demo cluster.
+func TestCompleteResourceArgs(t *testing.T) {
+	demoMode = true
+	defer func() { demoMode = false }()
+	opts, system := newTestClientOptions(""), newTestClientOptions("kube-system")
+
+	tests := []struct {
+		name       string
+		complete   cobra.CompletionFunc
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		{"namespaces", completeNamespaceFlag(opts), nil, "", []string{"default", "kube-system", "shop"}},
+		{"namespace prefix", completeNamespaceFlag(opts), nil, "s", []string{"shop"}},
+		{"first arg", completeFirstArg(system, "deployments"), nil, "", []string{"coredns"}},
+		{"first arg only", completeFirstArg(opts, "deployments"), []string{"hello"}, "", nil},
+		{"type/name", completeResourceArgs(opts), nil, "deploy/", []string{"deploy/hello"}},
+		{"type name", completeResourceArgs(opts), []string{"deployment"}, "h", []string{"hello"}},
+		{"type only", completeResourceArgs(opts), nil, "deploy", nil},
+		{"unknown type", completeResourceArgs(opts), []string{"widgets"}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := tt.complete(&cobra.Command{Use: "test"}, tt.args, tt.toComplete)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected completions %v, got %v", tt.want, got)
+			}
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("expected no file completion, got directive %d", directive)
+			}
+		})
+	}
+}
+
+// TestCompleteContexts verifies completing the contexts of the kubeconfig.
+func TestCompleteContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	config := "apiVersion: v1\nkind: Config\ncontexts:\n- name: prod\n  context: {cluster: c}\n" +
+		"- name: staging\n  context: {cluster: c}\nclusters:\n- name: c\n  cluster: {server: https://127.0.0.1}\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := completeContexts(&path)(&cobra.Command{Use: "test"}, nil, "")
+	if !slices.Equal(got, []string{"prod", "staging"}) {
+		t.Errorf("expected contexts prod and staging, got %v", got)
+	}
+	got, _ = completeContexts(&path)(&cobra.Command{Use: "test"}, nil, "st")
+	if !slices.Equal(got, []string{"staging"}) {
+		t.Errorf("expected context staging, got %v", got)
+	}
+}
+
+// TestCachedCompletionNames verifies that fresh cached names are reused and stale ones are refetched.
+func TestCachedCompletionNames(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	opts := newTestClientOptions("web")
+	opts.kubeconfigPath = filepath.Join(t.TempDir(), "missing")
+	info, err := k8s.LookupResource("deployments")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := completionCachePath(opts, info, "web")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal([]string{"cached"})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := cachedCompletionNames(opts, info, "web")
+	if err != nil || !slices.Equal(names, []string{"cached"}) {
+		t.Errorf("expected the cached names, got %v, %v", names, err)
+	}
+	if other := completionCachePath(opts, info, "api"); other == path {
+		t.Error("expected namespaces to be cached separately")
+	}
+
+	stale := time.Now().Add(-2 * completionCacheTTL)
+	if err := os.Chtimes(path, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cachedCompletionNames(opts, info, "web"); err == nil {
+		t.Error("expected stale names to be refetched, which fails without a kubeconfig")
+	}
+}
+
+// TestRegisterCompletions verifies that the Kubernetes-facing commands complete --namespace and --context.
+func TestRegisterCompletions(t *testing.T) {
+	registerCompletions()
+
+	for _, cmd := range []*cobra.Command{listDeploymentsCmd, getDeploymentCmd, scaleDeploymentCmd} {
+		for _, flag := range []string{"namespace", "context"} {
+			if _, ok := cmd.GetFlagCompletionFunc(flag); !ok {
+				t.Errorf("expected %s to complete --%s", cmd.CommandPath(), flag)
+			}
+		}
+	}
+	if _, ok := connectionCmd.GetFlagCompletionFunc("context"); !ok {
+		t.Error("expected connection to complete --context")
+	}
+}
@@ -0,0 +1,159 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'config get-contexts', 'config current-context' and 'config use-context' commands.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// configKubeconfigPath is the kubeconfig of the config commands, or a list of files to merge.
+var configKubeconfigPath string
+
+// getContextsOutput is the output format of config get-contexts.
+var getContextsOutput string
+
+// configCmd represents the config command.
+// It serves as a parent command for inspecting and modifying the kubeconfig.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and modify the kubeconfig",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// getContextsCmd represents the config get-contexts command.
+var getContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List the contexts of the kubeconfig",
+	Long: `List the contexts of the kubeconfig, marking the current one with '*'.
+
+Examples:
+  kc config get-contexts
+  kc config get-contexts -o json
+  kc config get-contexts --kubeconfig=~/.kube/clusters:~/.kube/users`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runGetContexts(os.Stdout, configKubeconfigPath); err != nil {
+			log.Error().Err(err).Msg("Failed to list contexts")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// currentContextCmd represents the config current-context command.
+var currentContextCmd = &cobra.Command{
+	Use:   "current-context",
+	Short: "Print the current context of the kubeconfig",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runCurrentContext(os.Stdout, configKubeconfigPath); err != nil {
+			log.Error().Err(err).Msg("Failed to read current context")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// useContextCmd represents the config use-context command.
+var useContextCmd = &cobra.Command{
+	Use:   "use-context NAME",
+	Short: "Set the current context of the kubeconfig",
+	Long: `Set the current context of the kubeconfig, which later commands use
+unless they are given --context.
+
+Examples:
+  kc config use-context staging`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: firstArgOnly(completeContexts(&configKubeconfigPath)),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runUseContext(os.Stdout, configKubeconfigPath, args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to switch context")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runGetContexts lists the contexts of the kubeconfig in the selected output format.
+func runGetContexts(out io.Writer, kubeconfigPath string) error {
+	if err := validateOutputFormat(getContextsOutput); err != nil {
+		return err
+	}
+	contexts, err := k8s.ListContexts(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	switch getContextsOutput {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(contexts)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(contexts)
+	default:
+		return writeContextsTable(out, contexts)
+	}
+}
+
+// writeContextsTable writes the contexts as a table in the layout of kubectl.
+func writeContextsTable(out io.Writer, contexts []k8s.ContextInfo) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintln(w, "CURRENT\tNAME\tCLUSTER\tAUTHINFO\tNAMESPACE"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, context := range contexts {
+		current := ""
+		if context.Current {
+			current = "*"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", current, context.Name, context.Cluster, context.User,
+			context.Namespace); err != nil {
+			return fmt.Errorf("failed to write context row: %w", err)
+		}
+	}
+	return nil
+}
+
+// runCurrentContext prints the current context of the kubeconfig.
+func runCurrentContext(out io.Writer, kubeconfigPath string) error {
+	current, err := k8s.CurrentContext(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		return fmt.Errorf("current-context is not set")
+	}
+	_, err = fmt.Fprintln(out, current)
+	return err
+}
+
+// runUseContext switches the current context of the kubeconfig.
+func runUseContext(out io.Writer, kubeconfigPath, name string) error {
+	if err := k8s.SwitchContext(kubeconfigPath, name); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "Switched to context %q.\n", name)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(getContextsCmd, currentContextCmd, useContextCmd)
+
+	configCmd.PersistentFlags().StringVar(&configKubeconfigPath, "kubeconfig", "",
+		"Path to kubeconfig file, or a list of files to merge (default: $KUBECONFIG or $HOME/.kube/config)")
+	getContextsCmd.Flags().StringVarP(&getContextsOutput, "output", "o", "table",
+		"Output format (table|json|yaml)")
+}
@@ -0,0 +1,132 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the config get-contexts, current-context and use-context commands.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configTestKubeconfig is a kubeconfig with two contexts, the first being current.
+const configTestKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: prod
+  context:
+    cluster: test
+    user: admin
+- name: staging
+  context:
+    cluster: test
+    user: admin
+    namespace: web
+current-context: prod
+`
+
+// TestConfigCommandsDefined verifies that the config subcommands are registered.
+func TestConfigCommandsDefined(t *testing.T) {
+	for _, name := range []string{"get-contexts", "current-context", "use-context"} {
+		cmd, _, err := rootCmd.Find([]string{"config", name})
+		if err != nil || cmd.Name() != name {
+			t.Errorf("expected 'config %s' to be defined", name)
+		}
+	}
+}
+
+// TestConfigContextCommands verifies listing, printing and switching contexts.
+func TestConfigContextCommands(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfigPath, []byte(configTestKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	getContextsOutput = "table"
+	var table bytes.Buffer
+	if err := runGetContexts(&table, kubeconfigPath); err != nil {
+		t.Fatalf("runGetContexts() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "*") || !strings.Contains(lines[2], "web") {
+		t.Errorf("expected prod marked current and the staging namespace, got:\n%s", table.String())
+	}
+
+	var out bytes.Buffer
+	if err := runUseContext(&out, kubeconfigPath, "staging"); err != nil {
+		t.Fatalf("runUseContext() error = %v", err)
+	}
+	out.Reset()
+	if err := runCurrentContext(&out, kubeconfigPath); err != nil {
+		t.Fatalf("runCurrentContext() error = %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "staging" {
+		t.Errorf("expected current context staging, got %q", out.String())
+	}
+
+	if err := runUseContext(&out, kubeconfigPath, "missing"); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+}
+
+// TestApplyDefaultNamespace verifies that -n and -A take precedence over the namespace of the
+// kubeconfig context, and that contexts without a namespace, or --context-namespace=false,
+// span all namespaces.
+func TestApplyDefaultNamespace(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfigPath, []byte(configTestKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		context          string
+		namespace        string
+		allNamespaces    bool
+		contextNamespace bool
+		want             string
+	}{
+		{"context without namespace", "prod", "", false, true, ""},
+		{"context namespace", "staging", "", false, true, "web"},
+		{"explicit namespace", "staging", "api", false, true, "api"},
+		{"all namespaces", "staging", "", true, true, ""},
+		{"context namespace disabled", "staging", "", false, false, ""},
+		{"explicit namespace with context namespace disabled", "staging", "api", false, false, "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := contextNamespace
+			contextNamespace = tt.contextNamespace
+			t.Cleanup(func() { contextNamespace = saved })
+
+			opts := &clientOptions{
+				kubeconfigPath: kubeconfigPath,
+				contextName:    tt.context,
+				namespace:      tt.namespace,
+				allNamespaces:  tt.allNamespaces,
+			}
+			opts.applyDefaultNamespace()
+			if opts.namespace != tt.want {
+				t.Errorf("expected namespace %q, got %q", tt.want, opts.namespace)
+			}
+		})
+	}
+
+	flags := listDeploymentsCmd.Flags()
+	if flag := flags.Lookup("all-namespaces"); flag == nil || flag.Shorthand != "A" {
+		t.Fatal("expected 'all-namespaces' flag with shorthand 'A' to be defined")
+	}
+	if flag := rootCmd.PersistentFlags().Lookup("context-namespace"); flag == nil || flag.DefValue != "false" {
+		t.Error("expected --context-namespace to be off by default, keeping all namespaces as default")
+	}
+}
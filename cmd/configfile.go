@@ -0,0 +1,90 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the configuration file, which sets defaults for command-line flags.
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configFile is the configuration file set with --config. Empty uses defaultConfigFile if it exists.
+var configFile string
+
+// configKeys are the settings of the configuration file, keyed by the flags they set, with the
+// type of the flags they apply to. Commands whose flag of that name has another type, e.g. the
+// duration --timeout of wait, are not affected.
+var configKeys = map[string]string{
+	"log-level":        "string",
+	"quiet":            "bool",
+	"kubeconfig":       "string",
+	"context":          "string",
+	"namespace":        "string",
+	"output":           "string",
+	"port":             "int",
+	"timeout":          "int",
+	"upstream-timeout": "duration",
+	"authz-timeout":    "duration",
+}
+
+// defaultConfigFile returns the configuration file in the user's configuration directory,
+// e.g. ~/.config/k8s-controller/config.yaml on Linux.
+func defaultConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "k8s-controller", "config.yaml"), nil
+}
+
+// applyConfigFile sets the flags of cmd that were not given on the command line to the values of
+// the configuration file. Without --config, a missing default configuration file is not an error.
+func applyConfigFile(cmd *cobra.Command) error {
+	path := configFile
+	if path == "" {
+		var err error
+		if path, err = defaultConfigFile(); err != nil {
+			return nil
+		}
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	for _, key := range v.AllKeys() {
+		if key == aliasesKey || strings.HasPrefix(key, aliasesKey+".") {
+			continue
+		}
+		if _, ok := configKeys[key]; !ok {
+			return fmt.Errorf("unknown setting %q in config file %s", key, path)
+		}
+	}
+
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || !v.IsSet(flag.Name) || configKeys[flag.Name] != flag.Value.Type() {
+			return
+		}
+		if setErr := flag.Value.Set(v.GetString(flag.Name)); setErr != nil {
+			err = fmt.Errorf("invalid %s %q in config file %s: %w", flag.Name, v.GetString(flag.Name), path, setErr)
+		}
+	})
+	return err
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "",
+		"Configuration file setting flag defaults (default: $XDG_CONFIG_HOME/k8s-controller/config.yaml)")
+}
@@ -0,0 +1,92 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the configuration file setting flag defaults.
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TestApplyConfigFile verifies that configuration file settings apply to flags not given on the
+// command line and that invalid configuration files are rejected.
+func TestApplyConfigFile(t *testing.T) {
+	defer func() { configFile = "" }()
+
+	tests := []struct {
+		name          string
+		config        string
+		args          []string
+		wantNamespace string
+		wantPort      int
+		wantTimeout   time.Duration
+		wantErr       bool
+	}{
+		{
+			name:          "config applies",
+			config:        "namespace: staging\nport: 9090\ntimeout: 30s\n",
+			wantNamespace: "staging", wantPort: 9090, wantTimeout: time.Minute,
+		},
+		{
+			name:          "flag takes precedence",
+			config:        "namespace: staging\nport: 9090\n",
+			args:          []string{"--namespace", "prod"},
+			wantNamespace: "prod", wantPort: 9090, wantTimeout: time.Minute,
+		},
+		{
+			name:          "aliases are not flags",
+			config:        "namespace: staging\naliases:\n  ld: list deployments\n",
+			wantNamespace: "staging", wantPort: 8080, wantTimeout: time.Minute,
+		},
+		{name: "unknown setting", config: "namesapce: staging\n", wantErr: true},
+		{name: "invalid value", config: "port: high\n", wantErr: true},
+		{name: "malformed file", config: "namespace: [\n", wantErr: true},
+		{name: "missing file", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile = filepath.Join(t.TempDir(), "config.yaml")
+			if tt.config != "" {
+				if err := os.WriteFile(configFile, []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var ns string
+			var port int
+			var timeout time.Duration
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().StringVar(&ns, "namespace", "default", "")
+			cmd.Flags().IntVar(&port, "port", 8080, "")
+			cmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := applyConfigFile(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ns != tt.wantNamespace || port != tt.wantPort || timeout != tt.wantTimeout {
+				t.Errorf("got namespace=%q port=%d timeout=%v, want %q %d %v",
+					ns, port, timeout, tt.wantNamespace, tt.wantPort, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+// TestApplyConfigFileMissingDefault verifies that a missing default configuration file is ignored.
+func TestApplyConfigFileMissingDefault(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	if err := applyConfigFile(&cobra.Command{Use: "test"}); err != nil {
+		t.Errorf("applyConfigFile() error = %v, want nil", err)
+	}
+}
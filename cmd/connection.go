@@ -4,15 +4,14 @@ package cmd
 
 import (
 	"context"
-	"os"
-	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
-
-	"github.com/Searge/k8s-controller/pkg/k8s"
 )
 
+// connectionOpts are the connection and timeout flags of the connection command.
+var connectionOpts clientOptions
+
 // connectionCmd represents the connection command.
 // It creates a Kubernetes client and verifies connectivity to the API server.
 var connectionCmd = &cobra.Command{
@@ -32,22 +31,16 @@ Examples:
   k8s-controller connection --context=my-context --timeout=30`,
 	Run: func(_ *cobra.Command, _ []string) {
 		// Create context with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), connectionOpts.timeout())
 		defer cancel()
 
-		// Configure client
-		config := k8s.ClientConfig{
-			KubeconfigPath: kubeconfigPath,
-			Context:        contextName,
-		}
-
 		log.Info().Msg("Testing Kubernetes API connection...")
 
 		// Create client
-		client, err := k8s.CreateClient(config, log.Logger)
+		client, err := connectionOpts.newClient()
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to create Kubernetes client")
-			os.Exit(1)
+			exit(exitCode(err))
 		}
 		defer func() {
 			if closeErr := client.Close(); closeErr != nil {
@@ -58,7 +51,7 @@ Examples:
 		// Test connection
 		if err := client.TestConnection(ctx); err != nil {
 			log.Error().Err(err).Msg("Connection test failed")
-			os.Exit(1)
+			exit(exitCode(err))
 		}
 
 		log.Info().Msg("✅ Connection test successful! Kubernetes API is reachable.")
@@ -69,15 +62,10 @@ Examples:
 func init() {
 	rootCmd.AddCommand(connectionCmd)
 
-	// Kubeconfig path flag
-	connectionCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "",
-		"Path to kubeconfig file (default: $KUBECONFIG or $HOME/.kube/config)")
-
-	// Context name flag
-	connectionCmd.Flags().StringVar(&contextName, "context", "",
-		"Kubernetes context to use (default: current context from kubeconfig)")
+	// Kubeconfig, context and impersonation flags
+	addConnectionFlags(connectionCmd, &connectionOpts)
 
 	// Timeout flag
-	connectionCmd.Flags().IntVar(&timeoutSeconds, "timeout", 10,
+	connectionCmd.Flags().IntVar(&connectionOpts.timeoutSeconds, "timeout", 10,
 		"Connection timeout in seconds")
 }
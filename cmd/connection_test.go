@@ -25,6 +25,20 @@ func TestConnectionCommandDefined(t *testing.T) {
 		{"kubeconfig", false},
 		{"context", false},
 		{"timeout", false},
+		{"as", false},
+		{"as-group", false},
+		{"as-uid", false},
+		{"server", false},
+		{"certificate-authority", false},
+		{"token", false},
+		{"exec-command", false},
+		{"exec-arg", false},
+		{"exec-api-version", false},
+		{"proxy", false},
+		{"qps", false},
+		{"burst", false},
+		{"breaker-threshold", false},
+		{"breaker-cooldown", false},
 	}
 
 	for _, tt := range tests {
@@ -40,9 +54,7 @@ func TestConnectionCommandDefined(t *testing.T) {
 // TestConnectionFlagDefaults verifies that the connection command flags have correct default values.
 func TestConnectionFlagDefaults(t *testing.T) {
 	// Reset variables to test defaults
-	kubeconfigPath = ""
-	contextName = ""
-	timeoutSeconds = 0
+	connectionOpts = clientOptions{}
 
 	// Parse empty args to get defaults
 	if err := connectionCmd.ParseFlags([]string{}); err != nil {
@@ -50,12 +62,12 @@ func TestConnectionFlagDefaults(t *testing.T) {
 	}
 
 	// Check defaults - these should remain empty/zero until flags are parsed
-	if kubeconfigPath != "" {
-		t.Errorf("expected default kubeconfig path to be empty, got %s", kubeconfigPath)
+	if connectionOpts.kubeconfigPath != "" {
+		t.Errorf("expected default kubeconfig path to be empty, got %s", connectionOpts.kubeconfigPath)
 	}
 
-	if contextName != "" {
-		t.Errorf("expected default context to be empty, got %s", contextName)
+	if connectionOpts.contextName != "" {
+		t.Errorf("expected default context to be empty, got %s", connectionOpts.contextName)
 	}
 
 	// Note: timeout has a default value set in the flag definition,
@@ -104,9 +116,7 @@ func TestConnectionFlagParsing(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Reset variables
-			kubeconfigPath = ""
-			contextName = ""
-			timeoutSeconds = 0
+			connectionOpts = clientOptions{}
 
 			// Parse flags
 			err := connectionCmd.ParseFlags(tt.args)
@@ -115,17 +125,35 @@ func TestConnectionFlagParsing(t *testing.T) {
 			}
 
 			// Check values
-			if kubeconfigPath != tt.expectedPath {
-				t.Errorf("expected kubeconfig path %s, got %s", tt.expectedPath, kubeconfigPath)
+			if connectionOpts.kubeconfigPath != tt.expectedPath {
+				t.Errorf("expected kubeconfig path %s, got %s", tt.expectedPath, connectionOpts.kubeconfigPath)
 			}
 
-			if contextName != tt.expectedCtx {
-				t.Errorf("expected context %s, got %s", tt.expectedCtx, contextName)
+			if connectionOpts.contextName != tt.expectedCtx {
+				t.Errorf("expected context %s, got %s", tt.expectedCtx, connectionOpts.contextName)
 			}
 
-			if timeoutSeconds != tt.expectedTime {
-				t.Errorf("expected timeout %d, got %d", tt.expectedTime, timeoutSeconds)
+			if connectionOpts.timeoutSeconds != tt.expectedTime {
+				t.Errorf("expected timeout %d, got %d", tt.expectedTime, connectionOpts.timeoutSeconds)
 			}
 		})
 	}
 }
+
+// TestConnectionImpersonationFlags verifies that the impersonation flags are parsed, with repeatable groups.
+func TestConnectionImpersonationFlags(t *testing.T) {
+	defer func() { connectionOpts = clientOptions{} }()
+
+	args := []string{"--as=jane", "--as-group=dev", "--as-group=ops", "--as-uid=42"}
+	if err := connectionCmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+
+	opts := connectionOpts
+	if opts.impersonateUser != "jane" || opts.impersonateUID != "42" {
+		t.Errorf("expected user jane with UID 42, got %q with UID %q", opts.impersonateUser, opts.impersonateUID)
+	}
+	if len(opts.impersonateGroups) != 2 || opts.impersonateGroups[0] != "dev" || opts.impersonateGroups[1] != "ops" {
+		t.Errorf("expected groups [dev ops], got %v", opts.impersonateGroups)
+	}
+}
@@ -0,0 +1,118 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'controller' command which runs the reconciliation controllers.
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/controller"
+)
+
+// Flags for the controller command
+var (
+	// labelPolicy is the label policy for namespaces without a policy label.
+	labelPolicy string
+
+	// controllerWorkers is the number of concurrent reconcile workers.
+	controllerWorkers int
+
+	// controllerResync is the informer resync period.
+	controllerResync time.Duration
+
+	// recordAnnotations writes the last reconcile decision onto managed objects.
+	recordAnnotations bool
+
+	// controllerOpts are the connection and timeout flags of the controller command.
+	controllerOpts clientOptions
+)
+
+// controllerCmd represents the controller command.
+// It runs the label controller until interrupted.
+var controllerCmd = &cobra.Command{
+	Use:   "controller",
+	Short: "Run the reconciliation controllers",
+	Long: `Run the reconciliation controllers against the cluster until interrupted.
+
+The label controller keeps the recommended app.kubernetes.io/* labels (name,
+instance, version, and any others already set on the Deployment) consistent
+across each Deployment, its pod template and the Services selecting its pods.
+
+It is opt-in per namespace via the label
+  k8s-controller.searge.dev/label-policy=off|report|enforce
+Namespaces without the label use --label-policy (default: off).
+
+  report   log and record drift without changing anything
+  enforce  patch the missing or inconsistent labels; fixing pod template labels
+           rolls out new pods
+
+With --dry-run, enforced fixes and annotations are only logged (client) or
+submitted without persisting them (server).
+
+Examples:
+  kc controller
+  kc controller --label-policy=report
+  kc controller --label-policy=enforce --annotate --workers=4`,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runController(&controllerOpts); err != nil {
+			log.Error().Err(err).Msg("Controller failed")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runController creates the controllers and runs them until SIGINT or SIGTERM.
+func runController(opts *clientOptions) error {
+	policy, err := controller.ParseLabelPolicy(labelPolicy)
+	if err != nil {
+		return err
+	}
+	if controllerWorkers < 1 {
+		return newUsageError("invalid worker count: %d, must be at least 1", controllerWorkers)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	clientset := client.GetClientset()
+	recorder := controller.NewRecorder(clientset, log.Logger, recordAnnotations)
+	recorder.SetDryRun(opts.dryRun)
+	labels, err := controller.NewLabelController(clientset, recorder, log.Logger, controller.LabelControllerOptions{
+		DefaultPolicy: policy,
+		Resync:        controllerResync,
+		DryRun:        opts.dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	return labels.Run(ctx, controllerWorkers)
+}
+
+func init() {
+	rootCmd.AddCommand(controllerCmd)
+
+	controllerCmd.Flags().StringVar(&labelPolicy, "label-policy", string(controller.LabelPolicyOff),
+		"Label policy for namespaces without the policy label (off, report, enforce)")
+	controllerCmd.Flags().IntVar(&controllerWorkers, "workers", 2,
+		"Number of concurrent reconcile workers")
+	controllerCmd.Flags().DurationVar(&controllerResync, "resync", controller.DefaultResync,
+		"Informer resync period")
+	controllerCmd.Flags().BoolVar(&recordAnnotations, "annotate", false,
+		"Record the last reconcile decision as an annotation on managed objects")
+
+	addClientFlags(controllerCmd, &controllerOpts, 30)
+	addDryRunFlag(controllerCmd, &controllerOpts)
+}
@@ -0,0 +1,49 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the controller command definition and flag validation.
+package cmd
+
+import (
+	"testing"
+)
+
+// TestControllerCommandDefined verifies that the controller command is registered with the expected flags.
+func TestControllerCommandDefined(t *testing.T) {
+	if controllerCmd == nil {
+		t.Fatal("controllerCmd should be defined")
+	}
+
+	for _, name := range []string{"label-policy", "workers", "resync", "annotate", "kubeconfig", "dry-run"} {
+		if controllerCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+
+	if got := controllerCmd.Flags().Lookup("label-policy").DefValue; got != "off" {
+		t.Errorf("expected the label controller to be opt-in, got default policy %q", got)
+	}
+}
+
+// TestRunControllerValidation verifies that invalid flags are rejected before connecting.
+func TestRunControllerValidation(t *testing.T) {
+	defer func(policy string, workers int) {
+		labelPolicy, controllerWorkers = policy, workers
+	}(labelPolicy, controllerWorkers)
+
+	tests := []struct {
+		name    string
+		policy  string
+		workers int
+	}{
+		{"unknown policy", "fix", 1},
+		{"no workers", "report", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labelPolicy, controllerWorkers = tt.policy, tt.workers
+			if err := runController(newTestClientOptions("")); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'create' command which creates Kubernetes resources.
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// namespaceLabels holds the labels of a namespace to create.
+var namespaceLabels map[string]string
+
+// createNamespaceOpts are the connection and timeout flags of create namespace.
+var createNamespaceOpts clientOptions
+
+// createCmd represents the create command.
+// It serves as a parent command for creating resources.
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a Kubernetes resource",
+	Long: `Create Kubernetes resources.
+
+Examples:
+  kc create namespace staging`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// createNamespaceCmd represents the create namespace command.
+var createNamespaceCmd = &cobra.Command{
+	Use:     "namespace NAME",
+	Aliases: []string{"ns"},
+	Short:   "Create a namespace",
+	Long: `Create a namespace, optionally with labels.
+
+Examples:
+  kc create namespace staging
+  kc create ns team-a --labels=team=a,env=dev`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runCreateNamespace(&createNamespaceOpts, args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to create namespace")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runCreateNamespace validates the name and creates the namespace.
+func runCreateNamespace(opts *clientOptions, name string) error {
+	if err := validateNamespace(name); err != nil {
+		return fmt.Errorf("invalid namespace: %w", err)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	if err := client.CreateNamespace(ctx, name, namespaceLabels); err != nil {
+		return err
+	}
+
+	fmt.Printf("namespace/%s created%s\n", name, opts.dryRunSuffix())
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(createCmd)
+	createCmd.AddCommand(createNamespaceCmd)
+
+	createNamespaceCmd.Flags().StringToStringVar(&namespaceLabels, "labels", nil,
+		"Labels to set on the namespace (key=value pairs)")
+
+	addClientFlags(createNamespaceCmd, &createNamespaceOpts, 30)
+	addDryRunFlag(createNamespaceCmd, &createNamespaceOpts)
+}
@@ -0,0 +1,28 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the create command definitions.
+package cmd
+
+import "testing"
+
+// TestCreateNamespaceCommandDefined verifies that create namespace is registered with the expected flags.
+func TestCreateNamespaceCommandDefined(t *testing.T) {
+	cmd, _, err := createCmd.Find([]string{"ns"})
+	if err != nil || cmd != createNamespaceCmd {
+		t.Fatalf("expected 'ns' to resolve to create namespace, got %v", err)
+	}
+
+	for _, name := range []string{"labels", "kubeconfig", "context", "timeout"} {
+		if createNamespaceCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestRunCreateNamespaceValidation verifies that invalid names are rejected before contacting the cluster.
+func TestRunCreateNamespaceValidation(t *testing.T) {
+	for _, name := range []string{"Invalid_Name", "-leading", ""} {
+		if err := runCreateNamespace(newTestClientOptions(""), name); err == nil {
+			t.Errorf("expected error for namespace %q", name)
+		}
+	}
+}
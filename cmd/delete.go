@@ -0,0 +1,122 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'delete' command which deletes Kubernetes resources.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// namespaceWaitInterval is how often namespace deletion progress is polled with --wait.
+const namespaceWaitInterval = 2 * time.Second
+
+var (
+	// deleteWait blocks until the deleted resource is gone.
+	deleteWait bool
+
+	// deleteNamespaceOpts are the connection and timeout flags of delete namespace.
+	deleteNamespaceOpts clientOptions
+)
+
+// deleteCmd represents the delete command.
+// It serves as a parent command for deleting resources.
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a Kubernetes resource",
+	Long: `Delete Kubernetes resources.
+
+Examples:
+  kc delete namespace staging --wait`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// deleteNamespaceCmd represents the delete namespace command.
+var deleteNamespaceCmd = &cobra.Command{
+	Use:     "namespace NAME",
+	Aliases: []string{"ns"},
+	Short:   "Delete a namespace",
+	Long: `Delete a namespace and everything in it.
+
+Namespace deletion is asynchronous: the namespace stays terminating until all
+its content is removed and its finalizers are cleared. With --wait the command
+blocks until the namespace is gone or --timeout expires. Namespaces stuck on
+finalizers are reported together with the conditions blocking their deletion.
+
+Examples:
+  kc delete namespace staging
+  kc delete ns staging --wait --timeout=300`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstArg(&deleteNamespaceOpts, "namespaces"),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runDeleteNamespace(&deleteNamespaceOpts, args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to delete namespace")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runDeleteNamespace deletes the namespace and optionally waits for it to be gone.
+func runDeleteNamespace(opts *clientOptions, name string) error {
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	status, err := client.DeleteNamespace(ctx, name)
+	if err != nil {
+		return err
+	}
+	if opts.dryRun.Enabled() {
+		_, err = fmt.Printf("namespace/%s deleted%s\n", name, opts.dryRunSuffix())
+		return err
+	}
+	if !status.Deleted && deleteWait {
+		stop := startProgress(fmt.Sprintf("Waiting for namespace/%s to be deleted", name))
+		status, err = client.WaitForNamespaceDeletion(ctx, name, namespaceWaitInterval)
+		stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeNamespaceTermination(os.Stdout, status)
+}
+
+// writeNamespaceTermination reports the outcome of a namespace deletion.
+func writeNamespaceTermination(out io.Writer, status k8s.NamespaceTermination) error {
+	var err error
+	switch {
+	case status.Deleted:
+		_, err = fmt.Fprintf(out, "namespace/%s deleted\n", status.Name)
+	case status.Stuck:
+		_, err = fmt.Fprintf(out, "namespace/%s is stuck terminating: %s\n", status.Name, status)
+	default:
+		_, err = fmt.Fprintf(out, "namespace/%s deletion requested, terminating\n", status.Name)
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+	deleteCmd.AddCommand(deleteNamespaceCmd)
+
+	deleteNamespaceCmd.Flags().BoolVar(&deleteWait, "wait", false,
+		"Wait until the namespace is fully deleted")
+
+	addClientFlags(deleteNamespaceCmd, &deleteNamespaceOpts, 120)
+	addDryRunFlag(deleteNamespaceCmd, &deleteNamespaceOpts)
+}
@@ -0,0 +1,55 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the delete command definitions and deletion reporting.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestDeleteNamespaceCommandDefined verifies that delete namespace is registered with the expected flags.
+func TestDeleteNamespaceCommandDefined(t *testing.T) {
+	cmd, _, err := deleteCmd.Find([]string{"namespace"})
+	if err != nil || cmd != deleteNamespaceCmd {
+		t.Fatalf("expected delete namespace subcommand, got %v", err)
+	}
+
+	for _, name := range []string{"wait", "kubeconfig", "context", "timeout"} {
+		if deleteNamespaceCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestWriteNamespaceTermination verifies the messages for deleted, terminating and stuck namespaces.
+func TestWriteNamespaceTermination(t *testing.T) {
+	tests := []struct {
+		name   string
+		status k8s.NamespaceTermination
+		want   string
+	}{
+		{"deleted", k8s.NamespaceTermination{Name: "staging", Deleted: true}, "namespace/staging deleted"},
+		{"terminating", k8s.NamespaceTermination{Name: "staging", Terminating: time.Second},
+			"deletion requested, terminating"},
+		{"stuck", k8s.NamespaceTermination{Name: "staging", Terminating: time.Hour, Stuck: true,
+			Finalizers: []string{"kubernetes"}, Blockers: []string{"content remaining"}},
+			"stuck terminating: namespace \"staging\" terminating for 1h0m0s, pending finalizers: kubernetes; " +
+				"content remaining"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeNamespaceTermination(&out, tt.status); err != nil {
+				t.Fatalf("writeNamespaceTermination() error = %v", err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("expected output to contain %q, got %q", tt.want, out.String())
+			}
+		})
+	}
+}
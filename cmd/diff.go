@@ -0,0 +1,157 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'diff' command which compares manifests with live objects.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+var (
+	// diffFile is the manifest file or directory to diff, or "-" for stdin.
+	diffFile string
+
+	// diffOpts are the namespace, connection and timeout flags of the diff command.
+	diffOpts clientOptions
+)
+
+// diffCmd represents the diff command.
+var diffCmd = &cobra.Command{
+	Use:   "diff -f PATH",
+	Short: "Show changes applying manifests would make",
+	Long: `Compare manifests with the live objects in the cluster.
+
+Each object is applied with a server-side dry run, so defaulting, admission and
+conflicts are taken into account without changing anything. The result is shown
+as a unified diff from the live object to the object the server would store.
+Server-managed metadata is left out, and Secret values are masked unless
+--no-redact is set.
+
+PATH is a file, a directory of .yaml, .yml and .json files, or "-" for stdin.
+Exits with status 1 if any object would change.
+
+Examples:
+  kc diff -f deployment.yaml
+  kc diff -f manifests/ -n staging
+  cat app.yaml | kc diff -f - -o json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		changed, err := runDiff(os.Stdout, os.Stdin, &diffOpts)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to diff manifests")
+			exit(exitCode(err))
+		}
+		if changed {
+			exit(exitFailure)
+		}
+	},
+}
+
+// runDiff diffs the manifests at --filename against the cluster and reports whether any object would change.
+func runDiff(out io.Writer, stdin io.Reader, opts *clientOptions) (bool, error) {
+	if diffFile == "" {
+		return false, newUsageError("--filename is required")
+	}
+	if err := validateOutputFormat(opts.output); err != nil {
+		return false, err
+	}
+	objects, err := readManifests(diffFile, stdin)
+	if err != nil {
+		return false, err
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return false, err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	diffs, err := client.Diff(ctx, objects, k8s.DiffOptions{
+		Namespace:   opts.namespace,
+		ShowSecrets: noRedact,
+		Redactor:    outputRedactor,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	for _, diff := range diffs {
+		changed = changed || diff.Changed()
+	}
+	return changed, formatDiffOutput(out, diffs, opts.output)
+}
+
+// readManifests decodes the manifests in a file, in the manifest files of a directory, or on stdin for "-".
+func readManifests(path string, stdin io.Reader) ([]*unstructured.Unstructured, error) {
+	if path != "-" {
+		return k8s.ReadManifests(path)
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return k8s.DecodeManifests(data)
+}
+
+// formatDiffOutput prints the diffs in the given output format.
+func formatDiffOutput(out io.Writer, diffs []k8s.ObjectDiff, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diffs)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(diffs)
+	case "table":
+		return writeUnifiedDiffs(out, diffs)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writeUnifiedDiffs prints the unified diff of each changed object followed by a summary line.
+func writeUnifiedDiffs(out io.Writer, diffs []k8s.ObjectDiff) error {
+	var created, changed int
+	for _, diff := range diffs {
+		if !diff.Changed() {
+			continue
+		}
+		if diff.Created {
+			created++
+		} else {
+			changed++
+		}
+		if _, err := io.WriteString(out, diff.Diff); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	}
+	_, err := fmt.Fprintf(out, "%d to create, %d to change, %d unchanged\n",
+		created, changed, len(diffs)-created-changed)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVarP(&diffFile, "filename", "f", "",
+		`Manifest file or directory, or "-" for stdin`)
+	diffCmd.Flags().StringVarP(&diffOpts.namespace, "namespace", "n", "",
+		"Namespace for manifests without one (default: default)")
+	diffCmd.Flags().StringVarP(&diffOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+	addClientFlags(diffCmd, &diffOpts, 30)
+}
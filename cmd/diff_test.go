@@ -0,0 +1,111 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the diff command.
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+const testDiffConfigMap = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n"
+
+// TestDiffCommandDefined verifies that the diff command is registered with the expected flags.
+func TestDiffCommandDefined(t *testing.T) {
+	for _, name := range []string{"filename", "namespace", "output", "timeout"} {
+		if diffCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestRunDiffValidation verifies that invalid flags fail before connecting.
+func TestRunDiffValidation(t *testing.T) {
+	defer func() { diffFile = "" }()
+
+	tests := []struct {
+		name   string
+		file   string
+		format string
+	}{
+		{"missing filename", "", "table"},
+		{"invalid format", "-", "xml"},
+		{"missing file", filepath.Join(t.TempDir(), "absent.yaml"), "table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffFile = tt.file
+			opts := newTestClientOptions("")
+			opts.output = tt.format
+			if _, err := runDiff(nil, strings.NewReader(""), opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestReadManifests verifies reading manifests from a directory, a single file and stdin.
+func TestReadManifests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.yaml":    fmt.Sprintf(testDiffConfigMap, "second"),
+		"a.json":    `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"first"}}`,
+		"notes.txt": "not a manifest",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		stdin     string
+		wantNames []string
+	}{
+		{"directory", dir, "", []string{"first", "second"}},
+		{"file", filepath.Join(dir, "b.yaml"), "", []string{"second"}},
+		{"stdin", "-", fmt.Sprintf(testDiffConfigMap, "piped"), []string{"piped"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := readManifests(tt.path, strings.NewReader(tt.stdin))
+			if err != nil {
+				t.Fatalf("readManifests() error = %v", err)
+			}
+			var names []string
+			for _, obj := range objects {
+				names = append(names, obj.GetName())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("got objects %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+// TestWriteUnifiedDiffs verifies that only changed objects are printed, followed by a summary.
+func TestWriteUnifiedDiffs(t *testing.T) {
+	diffs := []k8s.ObjectDiff{
+		{Kind: "ConfigMap", Name: "new", Created: true, Diff: "+new\n"},
+		{Kind: "ConfigMap", Name: "changed", Diff: "-old\n+new\n"},
+		{Kind: "ConfigMap", Name: "same"},
+	}
+
+	var out bytes.Buffer
+	if err := writeUnifiedDiffs(&out, diffs); err != nil {
+		t.Fatalf("writeUnifiedDiffs() error = %v", err)
+	}
+	want := "+new\n-old\n+new\n1 to create, 1 to change, 1 unchanged\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
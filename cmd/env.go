@@ -0,0 +1,40 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements setting flags from KC_ environment variables.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix is the prefix of the environment variables setting flags, e.g. KC_LOG_LEVEL for --log-level.
+const envPrefix = "KC_"
+
+// envName returns the environment variable setting the flag of the given name.
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// applyEnvironment sets the flags of cmd that were not given on the command line to the values of
+// their KC_ environment variables. The flags count as given, so they take precedence over the
+// configuration file. Flags whose values are lists take a comma-separated list.
+func applyEnvironment(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		value, ok := os.LookupEnv(envName(flag.Name))
+		if !ok {
+			return
+		}
+		if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid %s %q: %w", envName(flag.Name), value, setErr)
+		}
+	})
+	return err
+}
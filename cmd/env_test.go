@@ -0,0 +1,117 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests setting flags from KC_ environment variables.
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestEnvName verifies the environment variable names of flags.
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		flag string
+		want string
+	}{
+		{"namespace", "KC_NAMESPACE"},
+		{"log-level", "KC_LOG_LEVEL"},
+		{"upstream-timeout", "KC_UPSTREAM_TIMEOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			if got := envName(tt.flag); got != tt.want {
+				t.Errorf("envName(%q) = %q, want %q", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplyEnvironment verifies that environment variables set flags not given on the command
+// line and that invalid values are rejected.
+func TestApplyEnvironment(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		args          []string
+		wantNamespace string
+		wantPort      int
+		wantGroups    []string
+		wantErr       bool
+	}{
+		{
+			name:          "environment applies",
+			env:           map[string]string{"KC_NAMESPACE": "staging", "KC_PORT": "9090", "KC_AS_GROUP": "dev,ops"},
+			wantNamespace: "staging", wantPort: 9090, wantGroups: []string{"dev", "ops"},
+		},
+		{
+			name:          "flag takes precedence",
+			env:           map[string]string{"KC_NAMESPACE": "staging"},
+			args:          []string{"-n", "prod"},
+			wantNamespace: "prod", wantPort: 8080,
+		},
+		{name: "invalid value", env: map[string]string{"KC_PORT": "high"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var ns string
+			var port int
+			var groups []string
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().StringVarP(&ns, "namespace", "n", "default", "")
+			cmd.Flags().IntVar(&port, "port", 8080, "")
+			cmd.Flags().StringSliceVar(&groups, "as-group", nil, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := applyEnvironment(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyEnvironment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ns != tt.wantNamespace || port != tt.wantPort || !slices.Equal(groups, tt.wantGroups) {
+				t.Errorf("got namespace=%q port=%d groups=%v, want %q %d %v",
+					ns, port, groups, tt.wantNamespace, tt.wantPort, tt.wantGroups)
+			}
+			if !cmd.Flags().Changed("namespace") {
+				t.Error("expected the namespace flag to count as given")
+			}
+		})
+	}
+}
+
+// TestApplyEnvironmentBeforeConfigFile verifies that environment variables take precedence over
+// the configuration file.
+func TestApplyEnvironmentBeforeConfigFile(t *testing.T) {
+	defer func() { configFile = "" }()
+	configFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("namespace: staging\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KC_NAMESPACE", "prod")
+
+	var ns string
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringVar(&ns, "namespace", "default", "")
+	if err := applyEnvironment(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfigFile(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if ns != "prod" {
+		t.Errorf("namespace = %q, want %q", ns, "prod")
+	}
+}
@@ -0,0 +1,135 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'events' command which shows the events of a specific object.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// eventsOpts are the namespace, connection and timeout flags of the events command.
+var eventsOpts clientOptions
+
+// eventsCmd represents the events command.
+// It lists the events whose involved object is the given resource.
+var eventsCmd = &cobra.Command{
+	Use:   "events (TYPE/NAME | TYPE NAME)",
+	Short: "Show events of an object",
+	Long: `Show the events related to a specific object, oldest first.
+
+Examples:
+  kc events deployment/nginx
+  kc events deploy nginx -n web
+  kc events pod/nginx-7c5ddbdf54-x8kz2 -o json
+  kc events node/worker-1`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeResourceArgs(&eventsOpts),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runEvents(&eventsOpts, args); err != nil {
+			log.Error().Err(err).Msg("Failed to get events")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runEvents resolves the object reference and prints its events.
+func runEvents(opts *clientOptions, args []string) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+
+	info, name, err := parseResourceArgs(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	events, err := client.GetEventsForObject(ctx, k8s.ObjectReference{
+		Kind:      info.Kind,
+		Namespace: resolveNamespace(info, opts.namespace),
+		Name:      name,
+	})
+	if err != nil {
+		return err
+	}
+
+	return formatEventsOutput(os.Stdout, events, opts.output)
+}
+
+// formatEventsOutput writes events in the given output format.
+func formatEventsOutput(out io.Writer, events []k8s.EventInfo, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(events)
+	case "yaml":
+		data, err := yaml.Marshal(events)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case "table":
+		if len(events) == 0 {
+			_, err := fmt.Fprintln(out, "No events found.")
+			return err
+		}
+		return writeEventsTable(out, "", events)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writeEventsTable writes events as an aligned table, each line starting with indent.
+func writeEventsTable(out io.Writer, indent string, events []k8s.EventInfo) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintf(w, "%sLAST SEEN\tTYPE\tREASON\tFROM\tMESSAGE\n", indent); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		lastSeen := formatAge(now.Sub(event.LastSeen))
+		if event.Count > 1 {
+			lastSeen = fmt.Sprintf("%s (x%d)", lastSeen, event.Count)
+		}
+		if _, err := fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\n",
+			indent, lastSeen, event.Type, event.Reason, event.Source, event.Message); err != nil {
+			return fmt.Errorf("failed to write event row: %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+
+	eventsCmd.Flags().StringVarP(&eventsOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+	eventsCmd.Flags().StringVarP(&eventsOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+
+	addClientFlags(eventsCmd, &eventsOpts, 30)
+}
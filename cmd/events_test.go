@@ -0,0 +1,78 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the events command definition and event formatting.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// testEvents returns a repeated warning followed by a normal event.
+func testEvents() []k8s.EventInfo {
+	now := time.Now()
+	return []k8s.EventInfo{
+		{Type: "Warning", Reason: "FailedCreate", Message: "quota exceeded", Count: 3,
+			Source: "replicaset-controller", LastSeen: now.Add(-10 * time.Minute)},
+		{Type: "Normal", Reason: "ScalingReplicaSet", Message: "Scaled up", Count: 1,
+			Source: "deployment-controller", LastSeen: now.Add(-2 * time.Minute)},
+	}
+}
+
+// TestEventsCommandDefined verifies that the events command is registered with the expected flags.
+func TestEventsCommandDefined(t *testing.T) {
+	if eventsCmd == nil {
+		t.Fatal("eventsCmd should be defined")
+	}
+
+	for _, name := range []string{"namespace", "output", "kubeconfig", "context", "timeout"} {
+		if eventsCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestFormatEventsOutput verifies all output formats of the events command.
+func TestFormatEventsOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []k8s.EventInfo
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{"table", testEvents(), "table",
+			[]string{"LAST SEEN", "10m (x3)", "FailedCreate", "deployment-controller"}, false},
+		{"empty table", nil, "table", []string{"No events found."}, false},
+		{"yaml", testEvents(), "yaml", []string{"reason: FailedCreate"}, false},
+		{"unsupported", testEvents(), "xml", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := formatEventsOutput(&out, tt.events, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatEventsOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if err := formatEventsOutput(&out, testEvents(), "json"); err != nil {
+		t.Fatalf("formatEventsOutput() error = %v", err)
+	}
+	var decoded []k8s.EventInfo
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("expected 2 JSON events, got %d (%v)", len(decoded), err)
+	}
+}
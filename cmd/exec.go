@@ -0,0 +1,162 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'exec' command which runs a command inside a pod container.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags for the exec command
+var (
+	// execContainer selects the container to execute in.
+	execContainer string
+
+	// execStdin passes stdin to the container.
+	execStdin bool
+
+	// execTTY allocates a TTY for the command.
+	execTTY bool
+
+	// execOpts are the namespace, connection and timeout flags of the exec command.
+	execOpts clientOptions
+)
+
+// execCmd represents the exec command.
+// It executes a command in a pod container, optionally interactively with a TTY.
+var execCmd = &cobra.Command{
+	Use:   "exec POD [-c CONTAINER] -- COMMAND [args...]",
+	Short: "Execute a command in a container",
+	Long: `Execute a command in a container of a pod.
+
+Use -i to pass stdin to the container and -t to allocate a TTY.
+Combine them as -it for an interactive shell.
+
+Examples:
+  kc exec nginx-7c5ddbdf54-x8kz2 -- ls -la /usr/share/nginx/html
+  kc exec nginx-7c5ddbdf54-x8kz2 -c sidecar -- env
+  kc exec -it nginx-7c5ddbdf54-x8kz2 -n web -- /bin/sh`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		pod, command, err := splitExecArgs(args, cmd.ArgsLenAtDash())
+		if err != nil {
+			log.Error().Err(err).Msg("Invalid arguments")
+			exit(exitCode(err))
+		}
+
+		if err := runExec(&execOpts, pod, command); err != nil {
+			log.Error().Err(err).Msg("Failed to execute command")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// splitExecArgs separates the pod name from the command following "--".
+func splitExecArgs(args []string, dashIndex int) (string, []string, error) {
+	if dashIndex != 1 {
+		return "", nil, fmt.Errorf("expected exactly one pod name followed by -- and the command")
+	}
+	if len(args) < 2 {
+		return "", nil, fmt.Errorf("no command specified after --")
+	}
+	return args[0], args[1:], nil
+}
+
+// runExec executes the command in the pod with the configured stream settings.
+func runExec(opts *clientOptions, pod string, command []string) error {
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	execOptions := k8s.ExecOptions{
+		Container: execContainer,
+		Command:   command,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		TTY:       execTTY,
+	}
+	if execStdin {
+		execOptions.Stdin = os.Stdin
+	}
+
+	ns := opts.namespaceOrDefault()
+	if !execTTY {
+		return client.ExecInPod(ctx, ns, pod, execOptions)
+	}
+	return withRawTerminal(func(sizes remotecommand.TerminalSizeQueue) error {
+		execOptions.TerminalSizeQueue = sizes
+		return client.ExecInPod(ctx, ns, pod, execOptions)
+	})
+}
+
+// withRawTerminal puts the local terminal into raw mode while fn runs and restores it afterwards.
+// If stdin is not a terminal, fn runs without raw mode or size reporting.
+func withRawTerminal(fn func(remotecommand.TerminalSizeQueue) error) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		log.Warn().Msg("Unable to use a TTY - input is not a terminal")
+		return fn(nil)
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	defer func() {
+		if restoreErr := term.Restore(fd, state); restoreErr != nil {
+			log.Warn().Err(restoreErr).Msg("Failed to restore terminal")
+		}
+	}()
+
+	return fn(newInitialSizeQueue(fd))
+}
+
+// initialSizeQueue reports the terminal size once when the session starts.
+type initialSizeQueue struct {
+	sizes chan *remotecommand.TerminalSize
+}
+
+// newInitialSizeQueue creates a size queue seeded with the current terminal size.
+func newInitialSizeQueue(fd int) *initialSizeQueue {
+	q := &initialSizeQueue{sizes: make(chan *remotecommand.TerminalSize, 1)}
+	if width, height, err := term.GetSize(fd); err == nil {
+		q.sizes <- &remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+	}
+	close(q.sizes)
+	return q
+}
+
+// Next returns the next terminal size, or nil when no more sizes will be reported.
+func (q *initialSizeQueue) Next() *remotecommand.TerminalSize {
+	return <-q.sizes
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().StringVarP(&execContainer, "container", "c", "",
+		"Container name (default: the only container of the pod)")
+	execCmd.Flags().BoolVarP(&execStdin, "stdin", "i", false,
+		"Pass stdin to the container")
+	execCmd.Flags().BoolVarP(&execTTY, "tty", "t", false,
+		"Allocate a TTY for the command")
+	execCmd.Flags().StringVarP(&execOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addClientFlags(execCmd, &execOpts, 30)
+}
@@ -0,0 +1,58 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the exec command definition and argument handling.
+package cmd
+
+import (
+	"testing"
+)
+
+// TestExecCommandDefined verifies that the exec command is registered with the expected flags.
+func TestExecCommandDefined(t *testing.T) {
+	if execCmd == nil {
+		t.Fatal("execCmd should be defined")
+	}
+
+	for _, name := range []string{"container", "stdin", "tty", "namespace"} {
+		if execCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestSplitExecArgs verifies separation of pod name and command.
+func TestSplitExecArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		dashIndex   int
+		wantPod     string
+		wantCommand int
+		wantErr     bool
+	}{
+		{"pod and command", []string{"web-1", "ls", "-la"}, 1, "web-1", 2, false},
+		{"missing dash", []string{"web-1", "ls"}, -1, "", 0, true},
+		{"two pods before dash", []string{"a", "b", "ls"}, 2, "", 0, true},
+		{"no command", []string{"web-1"}, 1, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod, command, err := splitExecArgs(tt.args, tt.dashIndex)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitExecArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if pod != tt.wantPod || len(command) != tt.wantCommand {
+				t.Errorf("splitExecArgs() = %q, %v", pod, command)
+			}
+		})
+	}
+}
+
+// TestInitialSizeQueue verifies that the queue terminates after the initial size.
+func TestInitialSizeQueue(t *testing.T) {
+	// File descriptor -1 is never a terminal, so no size is reported.
+	q := newInitialSizeQueue(-1)
+	if size := q.Next(); size != nil {
+		t.Errorf("expected nil size, got %+v", size)
+	}
+}
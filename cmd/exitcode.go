@@ -0,0 +1,67 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file defines the exit codes of the commands, which tell scripts why a command failed.
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Exit codes of the commands. Commands reporting a negative result rather than an error,
+// e.g. 'auth can-i' for a denied action or 'diff' for differences, exit with exitFailure.
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsage       = 2
+	exitUnreachable = 3
+	exitForbidden   = 4
+	exitNotFound    = 5
+	exitTimeout     = 6
+)
+
+// usageError is an error in the command line or the configuration, which exits with exitUsage.
+type usageError struct {
+	err error
+}
+
+// Error returns the message of the error.
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error.
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// newUsageError returns a usage error formatted like fmt.Errorf.
+func newUsageError(format string, args ...any) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// exitCode returns the exit code of a command that failed with err: exitUsage for usage errors,
+// the code of the category of Kubernetes API errors, see k8s.ClassifyError, and exitFailure otherwise.
+func exitCode(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	}
+
+	switch err = k8s.ClassifyError(err); {
+	case errors.Is(err, k8s.ErrUnreachable):
+		return exitUnreachable
+	case errors.Is(err, k8s.ErrForbidden), errors.Is(err, k8s.ErrUnauthorized):
+		return exitForbidden
+	case errors.Is(err, k8s.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, k8s.ErrTimeout):
+		return exitTimeout
+	default:
+		return exitFailure
+	}
+}
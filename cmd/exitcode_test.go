@@ -0,0 +1,55 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the exit codes of the commands.
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestExitCode verifies that errors map to the exit code of their category.
+func TestExitCode(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	_, _, resourceArgsErr := parseResourceArgs([]string{"widgets/a"})
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, exitOK},
+		{"other", errors.New("boom"), exitFailure},
+		{"usage", newUsageError("--for is required"), exitUsage},
+		{"wrapped usage", fmt.Errorf("invalid output format: %w", validateOutputFormat("xml")), exitUsage},
+		{"invalid namespace", validateNamespace("Web"), exitUsage},
+		{"resource args", resourceArgsErr, exitUsage},
+		{"unreachable", fmt.Errorf("list: %w", k8s.ErrCircuitOpen), exitUnreachable},
+		{"forbidden", apierrors.NewForbidden(deployments, "web", errors.New("no RBAC")), exitForbidden},
+		{"unauthorized", apierrors.NewUnauthorized("bad token"), exitForbidden},
+		{"not found", fmt.Errorf("failed to get: %w", apierrors.NewNotFound(deployments, "web")), exitNotFound},
+		{"timeout", fmt.Errorf("list: %w", context.DeadlineExceeded), exitTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUsageErrorMessage verifies that usage errors keep the message and the wrapped error.
+func TestUsageErrorMessage(t *testing.T) {
+	err := &usageError{err: fmt.Errorf("invalid --since: %w", context.Canceled)}
+	if err.Error() != "invalid --since: context canceled" || !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected usage error %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'explain' command which documents custom resource and HTTP API fields.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/explain"
+	"github.com/Searge/k8s-controller/pkg/server"
+)
+
+// Flags of the explain command.
+var (
+	// explainRecursive lists all nested fields instead of the direct children.
+	explainRecursive bool
+
+	// explainAPI documents the HTTP API types instead of custom resources.
+	explainAPI bool
+
+	// explainOpts are the connection and timeout flags of the explain command.
+	explainOpts clientOptions
+)
+
+// explainCmd represents the explain command.
+var explainCmd = &cobra.Command{
+	Use:   "explain RESOURCE[.FIELD]...",
+	Short: "Document the fields of custom resources and HTTP API types",
+	Long: `Show the description and fields of a custom resource or one of its fields,
+generated from the OpenAPI schema of its CustomResourceDefinition. RESOURCE is
+the kind, plural, singular or short name of the resource.
+
+With --api, document the JSON responses of the HTTP API served by 'serve'
+instead. Without arguments, --api lists the documented responses.
+
+Examples:
+  kc explain managedapp
+  kc explain managedapp.spec.strategy
+  kc explain managedapps.spec --recursive
+  kc explain --api
+  kc explain --api deployments.items.replicas`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if explainAPI {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runExplain(os.Stdout, &explainOpts, args); err != nil {
+			log.Error().Err(err).Msg("Failed to explain resource")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runExplain prints the documentation of the field referenced by args.
+func runExplain(out io.Writer, opts *clientOptions, args []string) error {
+	if explainAPI {
+		if len(args) == 0 {
+			return writeAPITypes(out, server.APITypes())
+		}
+		return explainAPIType(out, args[0])
+	}
+
+	resource, path := splitFieldPath(args[0])
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	crd, err := client.GetCustomResourceSchema(ctx, resource)
+	if err != nil {
+		return err
+	}
+	if crd.Schema == nil {
+		return fmt.Errorf("custom resource %s does not define a schema", crd.Kind)
+	}
+
+	version := crd.Version
+	if crd.Group != "" {
+		version = crd.Group + "/" + crd.Version
+	}
+	return renderField(out, crd.Kind, version, explain.ParseSchema(crd.Schema), path)
+}
+
+// explainAPIType prints the documentation of a field of an HTTP API response type.
+func explainAPIType(out io.Writer, ref string) error {
+	name, path := splitFieldPath(ref)
+	apiType, ok := server.APITypes()[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown API type %q, see 'kc explain --api'", name)
+	}
+
+	root := explain.FromType(apiType.Type)
+	root.Description = apiType.Description + "\nServed by " + apiType.Endpoint + "."
+	return renderField(out, strings.ToLower(name), "server/v1", root, path)
+}
+
+// renderField looks up the field at path below root and renders its documentation.
+func renderField(out io.Writer, kind, version string, root *explain.Schema, path []string) error {
+	field, err := root.Lookup(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+	return explain.Render(out, explain.Document{Kind: kind, Version: version, Path: path, Schema: field},
+		explainRecursive)
+}
+
+// splitFieldPath splits "resource.field.subfield" into the resource and the field path.
+func splitFieldPath(ref string) (string, []string) {
+	parts := strings.Split(ref, ".")
+	return parts[0], parts[1:]
+}
+
+// writeAPITypes lists the documented HTTP API response types.
+func writeAPITypes(out io.Writer, types map[string]server.APIType) error {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintln(w, "NAME\tENDPOINT\tDESCRIPTION"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, name := range names {
+		apiType := types[name]
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", name, apiType.Endpoint, apiType.Description); err != nil {
+			return fmt.Errorf("failed to write API type row: %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().BoolVar(&explainRecursive, "recursive", false,
+		"List all nested fields with their types")
+	explainCmd.Flags().BoolVar(&explainAPI, "api", false,
+		"Document the HTTP API response types instead of custom resources")
+	addClientFlags(explainCmd, &explainOpts, 30)
+}
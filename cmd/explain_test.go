@@ -0,0 +1,71 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the explain command.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestExplainCommandArgs verifies that a resource is required unless --api is set.
+func TestExplainCommandArgs(t *testing.T) {
+	defer func() { explainAPI = false }()
+
+	tests := []struct {
+		name    string
+		api     bool
+		args    []string
+		wantErr bool
+	}{
+		{"resource", false, []string{"managedapp.spec"}, false},
+		{"missing resource", false, nil, true},
+		{"api listing", true, nil, false},
+		{"api field", true, []string{"limits.maxRetries"}, false},
+		{"too many", true, []string{"limits", "deployments"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			explainAPI = tt.api
+			if err := explainCmd.Args(explainCmd, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("Args() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRunExplainAPI verifies documentation of HTTP API types and their fields.
+func TestRunExplainAPI(t *testing.T) {
+	explainAPI = true
+	defer func() { explainAPI = false }()
+
+	tests := []struct {
+		name     string
+		args     []string
+		contains []string
+		wantErr  bool
+	}{
+		{"listing", nil, []string{"NAME", "limits", "GET /api/v1/limits"}, false},
+		{"type", []string{"limits"}, []string{"KIND:     limits", "maxRetries\t<integer>"}, false},
+		{"nested field", []string{"deployments.items.replicas"},
+			[]string{"FIELD: replicas <Object>", "desired\t<integer>"}, false},
+		{"unknown type", []string{"widgets"}, nil, true},
+		{"unknown field", []string{"limits.retries"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := runExplain(&out, newTestClientOptions(""), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runExplain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the export command.
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/redact"
+)
+
+// TestExportCommandDefined verifies that the export command is registered with a YAML default.
+func TestExportCommandDefined(t *testing.T) {
+	for _, name := range []string{"namespace", "output", "timeout", "kubeconfig"} {
+		if exportCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+	if got := exportCmd.Flags().Lookup("output").DefValue; got != "yaml" {
+		t.Errorf("expected yaml default output, got %q", got)
+	}
+}
+
+// TestExportDefaultOutput verifies that the export command prints YAML without -o, although the output
+// flags of the other commands default to table.
+func TestExportDefaultOutput(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = oldStdout
+		demoMode, exportOpts.namespace = false, ""
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"--demo", "export", "deployment", "frontend", "-n", "shop"})
+	execErr := rootCmd.Execute()
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	if execErr != nil {
+		t.Fatalf("Execute() error = %v", execErr)
+	}
+	if !strings.HasPrefix(string(out), "apiVersion: apps/v1\nkind: Deployment\n") {
+		t.Errorf("expected a YAML manifest, got:\n%s", out)
+	}
+}
+
+// TestRunExportValidation verifies that invalid arguments fail before connecting.
+func TestRunExportValidation(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		format string
+		args   []string
+	}{
+		{"table format", "table", []string{"deployment/nginx"}},
+		{"unknown resource", "yaml", []string{"widget", "nginx"}},
+		{"missing name", "yaml", []string{"deployment"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := newTestClientOptions("")
+			opts.output = tt.format
+			if err := runExport(nil, opts, tt.args); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestWriteManifest verifies that written manifests decode again and have sensitive env values masked.
+func TestWriteManifest(t *testing.T) {
+	redactor, err := redact.New(redact.DefaultPatterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputRedactor = redactor
+	defer func() { outputRedactor = nil }()
+
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]any{"name": "web"},
+		"spec": map[string]any{"containers": []any{map[string]any{
+			"name": "app",
+			"env":  []any{map[string]any{"name": "DB_PASSWORD", "value": "hunter2"}},
+		}}},
+	}}
+
+	for _, format := range []string{"yaml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeManifest(&out, obj, format); err != nil {
+				t.Fatalf("writeManifest() error = %v", err)
+			}
+			if strings.Contains(out.String(), "hunter2") {
+				t.Errorf("expected the password to be masked, got:\n%s", out.String())
+			}
+			decoded, err := k8s.DecodeManifests(out.Bytes())
+			if err != nil || len(decoded) != 1 || decoded[0].GetName() != "web" {
+				t.Errorf("expected a re-applyable manifest, got %v (%v)", decoded, err)
+			}
+		})
+	}
+}
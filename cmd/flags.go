@@ -2,17 +2,392 @@
 // This file defines common Kubernetes-related flags used across multiple commands.
 package cmd
 
-// Shared flags for Kubernetes operations
-var (
-	// kubeconfigPath specifies the path to kubeconfig file.
-	// Used by commands that interact with Kubernetes API.
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/logger"
+	"github.com/Searge/k8s-controller/pkg/redact"
+)
+
+// clientOptions are the flags of a Kubernetes-facing command selecting the cluster, the credentials,
+// the namespace and the timeout, and the output flags of the command. Each command has its own, bound to
+// its own flag set by addClientFlags and the command's init, so that commands do not share flag values,
+// e.g. the default of -o, and passes them to its runner.
+type clientOptions struct {
+	// namespace is the namespace of the command. Empty spans all namespaces where supported.
+	namespace string
+
+	// allNamespaces selects all namespaces explicitly, overriding the namespace of the kubeconfig context.
+	allNamespaces bool
+
+	// kubeconfigPath is the path to the kubeconfig file, or a list of files to merge.
 	kubeconfigPath string
 
-	// contextName specifies the Kubernetes context to use.
-	// Used by commands that interact with Kubernetes API.
+	// contextName is the kubeconfig context to use. Empty uses the current context.
 	contextName string
 
-	// timeoutSeconds specifies the timeout for Kubernetes operations.
-	// Used by commands that interact with Kubernetes API.
+	// timeoutSeconds is the timeout of the command's Kubernetes operations.
 	timeoutSeconds int
+
+	// impersonateUser is the user to act as. Empty disables impersonation.
+	impersonateUser string
+
+	// impersonateGroups are the groups to act as, together with impersonateUser.
+	impersonateGroups []string
+
+	// impersonateUID is the UID to act as, together with impersonateUser.
+	impersonateUID string
+
+	// apiServer is the API server URL. Empty uses the server of the kubeconfig.
+	apiServer string
+
+	// certificateAuthority is a CA bundle file for verifying the API server.
+	certificateAuthority string
+
+	// bearerToken authenticates with a bearer token instead of the kubeconfig credentials.
+	bearerToken string
+
+	// execCommand authenticates with an exec credential plugin instead of the kubeconfig credentials.
+	execCommand string
+
+	// execArgs are the arguments of the exec credential plugin.
+	execArgs []string
+
+	// execAPIVersion is the credential API version of the exec plugin.
+	execAPIVersion string
+
+	// proxyURL is the HTTP(S) or SOCKS5 proxy for reaching the API server.
+	proxyURL string
+
+	// apiQPS and apiBurst limit the rate of API requests; zero keeps the client-go defaults.
+	apiQPS   float32
+	apiBurst int
+
+	// breakerThreshold is the number of consecutive API server failures that opens the circuit breaker.
+	breakerThreshold int
+
+	// breakerCooldown is how long the open circuit breaker rejects requests.
+	breakerCooldown time.Duration
+
+	// dryRun simulates the command's mutating operations instead of performing them.
+	dryRun k8s.DryRun
+
+	// output is the output format of the command, e.g. table or json.
+	output string
+
+	// labelSelector filters the listed objects by their labels, e.g. "app=nginx,tier!=cache".
+	labelSelector string
+
+	// noHeaders omits the header row of table output, e.g. for processing it with awk.
+	noHeaders bool
+
+	// showLabels appends a LABELS column with the labels of each object to table output.
+	showLabels bool
+}
+
+// Demo mode flags, shared by all Kubernetes-facing commands.
+var (
+	// demoMode backs all commands with a seeded in-memory fake cluster.
+	demoMode bool
+
+	// demoFixture points to a YAML/JSON file used to seed the demo cluster.
+	demoFixture string
+)
+
+// Authorization hook flags, shared by all commands that mutate cluster state.
+var (
+	// authzWebhookURL is the endpoint consulted before mutating operations.
+	// If empty, mutating operations are not subject to an external authorization hook.
+	authzWebhookURL string
+
+	// authzTimeout bounds each call to the authorization endpoint.
+	authzTimeout time.Duration
+)
+
+// contextNamespace makes commands that can span namespaces default to the namespace of the kubeconfig
+// context, see applyDefaultNamespace. By default they span all namespaces unless -n is given.
+var contextNamespace bool
+
+// Redaction flags, shared by all commands that print, log or store cluster data.
+var (
+	// noRedact disables masking of sensitive values, for authorized use.
+	noRedact bool
+
+	// redactPatterns are the key patterns whose values are masked.
+	redactPatterns []string
+
+	// outputRedactor masks sensitive values in command output. It is nil when redaction is disabled.
+	outputRedactor *redact.Redactor
 )
+
+// setupRedaction builds the redactor configured via global flags and applies it to logging.
+func setupRedaction() error {
+	if noRedact {
+		outputRedactor = nil
+		return nil
+	}
+
+	redactor, err := redact.New(redactPatterns)
+	if err != nil {
+		return err
+	}
+	outputRedactor = redactor
+	logger.EnableRedaction(redactor)
+	return nil
+}
+
+// newAuthorizer builds the authorization hook configured via global flags.
+// It returns nil when no hook is configured, which allows all operations.
+func newAuthorizer() authz.Authorizer {
+	if authzWebhookURL == "" {
+		return nil
+	}
+	return authz.NewHTTPAuthorizer(authzWebhookURL, authzTimeout)
+}
+
+// addClientFlags registers the kubeconfig, context and timeout flags on a Kubernetes-facing command.
+func addClientFlags(cmd *cobra.Command, opts *clientOptions, defaultTimeout int) {
+	addConnectionFlags(cmd, opts)
+
+	cmd.Flags().IntVar(&opts.timeoutSeconds, "timeout", defaultTimeout,
+		"Timeout for Kubernetes operations in seconds")
+}
+
+// addConnectionFlags registers the kubeconfig, context, impersonation and credential flags,
+// for commands with their own timeout flag.
+func addConnectionFlags(cmd *cobra.Command, opts *clientOptions) {
+	completionCommands[cmd] = opts
+
+	cmd.Flags().StringVar(&opts.kubeconfigPath, "kubeconfig", "",
+		"Path to kubeconfig file, or a list of files to merge (default: $KUBECONFIG or $HOME/.kube/config)")
+
+	cmd.Flags().StringVar(&opts.contextName, "context", "",
+		"Kubernetes context to use (default: current context from kubeconfig)")
+
+	cmd.Flags().StringVar(&opts.impersonateUser, "as", "",
+		"Username to impersonate for the operation, e.g. to test its RBAC permissions")
+	cmd.Flags().StringArrayVar(&opts.impersonateGroups, "as-group", nil,
+		"Group to impersonate, can be repeated; requires --as")
+	cmd.Flags().StringVar(&opts.impersonateUID, "as-uid", "",
+		"UID to impersonate; requires --as")
+
+	addCredentialFlags(cmd, opts)
+	addRateLimitFlags(cmd, opts)
+}
+
+// addCredentialFlags registers the flags that override the API server, credentials and proxy of the kubeconfig.
+func addCredentialFlags(cmd *cobra.Command, opts *clientOptions) {
+	cmd.Flags().StringVar(&opts.apiServer, "server", "",
+		"Address of the Kubernetes API server, overriding the kubeconfig")
+	cmd.Flags().StringVar(&opts.certificateAuthority, "certificate-authority", "",
+		"Path to a CA bundle for verifying the API server certificate")
+	cmd.Flags().StringVar(&opts.bearerToken, "token", "",
+		"Bearer token for authentication, instead of the kubeconfig credentials")
+	cmd.Flags().StringVar(&opts.execCommand, "exec-command", "",
+		"Exec credential plugin for authentication, e.g. aws or gke-gcloud-auth-plugin")
+	cmd.Flags().StringArrayVar(&opts.execArgs, "exec-arg", nil,
+		"Argument of the exec credential plugin, can be repeated; requires --exec-command")
+	cmd.Flags().StringVar(&opts.execAPIVersion, "exec-api-version", "",
+		"Credential API version of the exec plugin (default "+k8s.DefaultExecAPIVersion+")")
+	cmd.Flags().StringVar(&opts.proxyURL, "proxy", "",
+		"HTTP(S) or SOCKS5 proxy for reaching the API server, e.g. socks5://127.0.0.1:1080")
+}
+
+// addRateLimitFlags registers the client-side rate limit and circuit breaker flags.
+func addRateLimitFlags(cmd *cobra.Command, opts *clientOptions) {
+	cmd.Flags().Float32Var(&opts.apiQPS, "qps", 0,
+		"Maximum API requests per second (default: client-go's 5)")
+	cmd.Flags().IntVar(&opts.apiBurst, "burst", 0,
+		"Maximum burst of API requests above --qps (default: client-go's 10)")
+	cmd.Flags().IntVar(&opts.breakerThreshold, "breaker-threshold", k8s.DefaultBreakerThreshold,
+		"Consecutive API server errors after which requests fail fast; 0 disables the circuit breaker")
+	cmd.Flags().DurationVar(&opts.breakerCooldown, "breaker-cooldown", k8s.DefaultBreakerCooldown,
+		"How long requests fail fast before the API server is tried again")
+}
+
+// dryRunFlag is the value of a --dry-run flag, which accepts none, client and server.
+type dryRunFlag struct {
+	mode *k8s.DryRun
+}
+
+// String returns the dry run mode.
+func (f dryRunFlag) String() string {
+	if *f.mode == "" {
+		return string(k8s.DryRunNone)
+	}
+	return string(*f.mode)
+}
+
+// Set parses the dry run mode.
+func (f dryRunFlag) Set(value string) error {
+	mode, err := k8s.ParseDryRun(value)
+	if err != nil {
+		return err
+	}
+	*f.mode = mode
+	return nil
+}
+
+// Type returns the type name shown in the help.
+func (f dryRunFlag) Type() string {
+	return "string"
+}
+
+// addDryRunFlag registers --dry-run on a mutating command. Like kubectl, a bare --dry-run
+// means --dry-run=client.
+func addDryRunFlag(cmd *cobra.Command, opts *clientOptions) {
+	flag := cmd.Flags().VarPF(dryRunFlag{mode: &opts.dryRun}, "dry-run", "",
+		"Only print what would change: client does not contact the API server for changes, "+
+			"server submits them without persisting (none|client|server)")
+	flag.NoOptDefVal = string(k8s.DryRunClient)
+}
+
+// dryRunSuffix returns the suffix marking the command's output as the result of a dry run, like kubectl's.
+func (o *clientOptions) dryRunSuffix() string {
+	switch o.dryRun {
+	case k8s.DryRunClient:
+		return " (dry run)"
+	case k8s.DryRunServer:
+		return " (server dry run)"
+	default:
+		return ""
+	}
+}
+
+// addNamespaceFlags registers -n/--namespace and -A/--all-namespaces, which exclude each other,
+// on a command that can span all namespaces. See applyDefaultNamespace.
+func addNamespaceFlags(cmd *cobra.Command, opts *clientOptions) {
+	cmd.Flags().StringVarP(&opts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: all namespaces)")
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false,
+		"Span all namespaces, even with --context-namespace")
+	cmd.MarkFlagsMutuallyExclusive("namespace", "all-namespaces")
+}
+
+// applyDefaultNamespace sets the namespace of a command registered with addNamespaceFlags. Without -n the
+// namespace stays empty and the command spans all namespaces, unless --context-namespace selects the
+// namespace of the kubeconfig context, like kubectl. -A always spans all namespaces.
+func (o *clientOptions) applyDefaultNamespace() {
+	if o.allNamespaces {
+		o.namespace = ""
+		return
+	}
+	if o.namespace != "" || !contextNamespace || demoMode || o.apiServer != "" {
+		return
+	}
+
+	ns, err := k8s.ContextNamespace(o.kubeconfigPath, o.contextName)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to read the namespace of the kubeconfig context")
+		return
+	}
+	if ns != "" {
+		log.Debug().Str("namespace", ns).Msg("Using the namespace of the kubeconfig context, pass -A for all")
+		o.namespace = ns
+	}
+}
+
+// namespaceOrDefault returns the namespace, or "default" if none is set.
+func (o *clientOptions) namespaceOrDefault() string {
+	if o.namespace == "" {
+		return "default"
+	}
+	return o.namespace
+}
+
+// timeout returns the timeout of the command's Kubernetes operations.
+func (o *clientOptions) timeout() time.Duration {
+	return time.Duration(o.timeoutSeconds) * time.Second
+}
+
+// newClient creates a Kubernetes client for the command, showing a spinner if it takes a while,
+// e.g. for exec credential plugins.
+func (o *clientOptions) newClient() (*k8s.Client, error) {
+	stop := startProgress("Connecting to the cluster")
+	defer stop()
+	return k8s.CreateClient(o.clientConfig(), log.Logger)
+}
+
+// clientConfig returns the client configuration selected by the command's connection flags
+// and the global demo and authorization flags.
+func (o *clientOptions) clientConfig() k8s.ClientConfig {
+	return k8s.ClientConfig{
+		KubeconfigPath: o.kubeconfigPath,
+		Context:        o.contextName,
+		Demo:           demoMode,
+		DemoFixture:    demoFixture,
+		Authorizer:     newAuthorizer(),
+		DryRun:         o.dryRun,
+
+		ImpersonateUser:   o.impersonateUser,
+		ImpersonateGroups: o.impersonateGroups,
+		ImpersonateUID:    o.impersonateUID,
+
+		Server:               o.apiServer,
+		CertificateAuthority: o.certificateAuthority,
+		Token:                o.bearerToken,
+		ExecCommand:          o.execCommand,
+		ExecArgs:             o.execArgs,
+		ExecAPIVersion:       o.execAPIVersion,
+		ProxyURL:             o.proxyURL,
+
+		QPS:              o.apiQPS,
+		Burst:            o.apiBurst,
+		BreakerThreshold: o.breakerThreshold,
+		BreakerCooldown:  o.breakerCooldown,
+		UserAgent:        k8s.UserAgent(Version, currentCommand),
+	}
+}
+
+// parseResourceArgs accepts either "TYPE/NAME" or "TYPE NAME" positional arguments
+// and resolves the resource type.
+func parseResourceArgs(args []string) (k8s.ResourceInfo, string, error) {
+	var resource, name string
+	switch len(args) {
+	case 1:
+		var err error
+		if resource, name, err = k8s.ParseResourceRef(args[0]); err != nil {
+			return k8s.ResourceInfo{}, "", &usageError{err: err}
+		}
+	case 2:
+		resource, name = args[0], args[1]
+	default:
+		return k8s.ResourceInfo{}, "", newUsageError("expected TYPE/NAME or TYPE NAME, got %d arguments", len(args))
+	}
+
+	info, err := k8s.LookupResource(resource)
+	if err != nil {
+		return k8s.ResourceInfo{}, "", &usageError{err: err}
+	}
+	return info, name, nil
+}
+
+// lookupResources resolves a list of resource names, e.g. from a --kinds flag.
+func lookupResources(names []string) ([]k8s.ResourceInfo, error) {
+	resources := make([]k8s.ResourceInfo, 0, len(names))
+	for _, name := range names {
+		info, err := k8s.LookupResource(name)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, info)
+	}
+	return resources, nil
+}
+
+// resolveNamespace returns the namespace to use for an object of the given resource type.
+// Namespaced resources default to "default"; cluster-scoped resources ignore the namespace.
+func resolveNamespace(info k8s.ResourceInfo, ns string) string {
+	if !info.Namespaced {
+		return ""
+	}
+	if ns == "" {
+		return "default"
+	}
+	return ns
+}
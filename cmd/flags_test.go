@@ -0,0 +1,129 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the per-command client options.
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// newTestClientOptions returns client options for the namespace with the default timeout of the commands.
+func newTestClientOptions(namespace string) *clientOptions {
+	return &clientOptions{namespace: namespace, timeoutSeconds: 30, output: "table"}
+}
+
+// TestClientOptionsIsolated verifies that commands sharing flag names bind them to their own options.
+func TestClientOptionsIsolated(t *testing.T) {
+	defer func() {
+		listDeploymentsOpts.namespace, getDeploymentOpts.namespace = "", ""
+		listDeploymentsOpts.output, listDeploymentsOpts.labelSelector = "table", ""
+		_ = listDeploymentsCmd.Flags().Set("timeout", "30")
+	}()
+
+	if err := listDeploymentsCmd.Flags().Set("namespace", "web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := listDeploymentsCmd.Flags().Set("timeout", "5"); err != nil {
+		t.Fatal(err)
+	}
+
+	if listDeploymentsOpts.namespace != "web" || listDeploymentsOpts.timeout() != 5*time.Second {
+		t.Errorf("expected list deployments to get namespace web and timeout 5s, got %q and %v",
+			listDeploymentsOpts.namespace, listDeploymentsOpts.timeout())
+	}
+	if getDeploymentOpts.namespace != "" || getDeploymentOpts.timeout() != 30*time.Second {
+		t.Errorf("expected get deployment to keep its defaults, got %q and %v",
+			getDeploymentOpts.namespace, getDeploymentOpts.timeout())
+	}
+	if deleteNamespaceOpts.timeout() != 120*time.Second {
+		t.Errorf("expected delete namespace to keep its own default timeout, got %v", deleteNamespaceOpts.timeout())
+	}
+
+	for flag, value := range map[string]string{"output": "json", "selector": "app=web"} {
+		if err := listDeploymentsCmd.Flags().Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if topPodsOpts.output != "table" || topPodsOpts.labelSelector != "" || exportOpts.output != "yaml" {
+		t.Errorf("expected top pods and export to keep their output defaults, got %q, %q and %q",
+			topPodsOpts.output, topPodsOpts.labelSelector, exportOpts.output)
+	}
+}
+
+// TestClientOptionsNamespaceOrDefault verifies the namespace of commands acting on a single namespace.
+func TestClientOptionsNamespaceOrDefault(t *testing.T) {
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{"", "default"},
+		{"web", "web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := newTestClientOptions(tt.namespace).namespaceOrDefault(); got != tt.want {
+				t.Errorf("namespaceOrDefault() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClientOptionsClientConfig verifies that the client configuration carries the command's flags.
+func TestClientOptionsClientConfig(t *testing.T) {
+	opts := &clientOptions{
+		kubeconfigPath:    "/tmp/config",
+		contextName:       "prod",
+		impersonateUser:   "jane",
+		impersonateGroups: []string{"dev"},
+		apiServer:         "https://10.0.0.1:6443",
+		apiQPS:            20,
+		dryRun:            k8s.DryRunServer,
+	}
+
+	config := opts.clientConfig()
+	if config.KubeconfigPath != "/tmp/config" || config.Context != "prod" || config.ImpersonateUser != "jane" ||
+		len(config.ImpersonateGroups) != 1 || config.Server != "https://10.0.0.1:6443" || config.QPS != 20 ||
+		config.DryRun != k8s.DryRunServer {
+		t.Errorf("expected the configuration of the options, got %+v", config)
+	}
+}
+
+// TestDryRunFlag verifies the values of --dry-run and the output suffix of each mode.
+func TestDryRunFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		want       k8s.DryRun
+		wantSuffix string
+		wantErr    bool
+	}{
+		{"unset", nil, "", "", false},
+		{"bare", []string{"--dry-run"}, k8s.DryRunClient, " (dry run)", false},
+		{"client", []string{"--dry-run=client"}, k8s.DryRunClient, " (dry run)", false},
+		{"server", []string{"--dry-run=server"}, k8s.DryRunServer, " (server dry run)", false},
+		{"none", []string{"--dry-run=none"}, k8s.DryRunNone, "", false},
+		{"invalid", []string{"--dry-run=all"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts clientOptions
+			cmd := &cobra.Command{Use: "test"}
+			addDryRunFlag(cmd, &opts)
+
+			err := cmd.ParseFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if opts.dryRun != tt.want || opts.dryRunSuffix() != tt.wantSuffix {
+				t.Errorf("expected dry run %q with suffix %q, got %q with %q",
+					tt.want, tt.wantSuffix, opts.dryRun, opts.dryRunSuffix())
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'get' command which shows details of a single resource.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// getDeploymentOpts are the namespace, connection and timeout flags of get deployment.
+var getDeploymentOpts clientOptions
+
+// getCmd represents the get command.
+// It serves as a parent command for showing single resources.
+var getCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show details of a Kubernetes resource",
+	Long: `Show details of a single Kubernetes resource, including its recent events.
+
+Examples:
+  kc get deployment nginx
+  kc get deploy nginx -n web -o json`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// getDeploymentCmd represents the get deployment command.
+var getDeploymentCmd = &cobra.Command{
+	Use:     "deployment NAME",
+	Aliases: []string{"deploy", "deployments"},
+	Short:   "Show details and events of a deployment",
+	Long: `Show the replica status, strategy, selector, conditions, images and events of a deployment.
+
+Examples:
+  kc get deployment nginx
+  kc get deployment nginx -n web
+  kc get deploy nginx -o yaml`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstArg(&getDeploymentOpts, "deployments"),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runGetDeployment(&getDeploymentOpts, args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to get deployment")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// deploymentDetail is a deployment together with its events.
+type deploymentDetail struct {
+	k8s.DeploymentInfo `yaml:",inline"`
+	Events             []k8s.EventInfo `json:"events" yaml:"events"`
+}
+
+// runGetDeployment fetches a deployment and its events and prints them.
+func runGetDeployment(opts *clientOptions, name string) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	detail, err := fetchDeploymentDetail(ctx, client, opts.namespaceOrDefault(), name)
+	if err != nil {
+		return err
+	}
+	return formatDeploymentDetail(os.Stdout, detail, opts.output)
+}
+
+// fetchDeploymentDetail fetches a deployment and its events.
+func fetchDeploymentDetail(ctx context.Context, client *k8s.Client, ns, name string) (deploymentDetail, error) {
+	info, err := client.GetDeployment(ctx, ns, name)
+	if err != nil {
+		return deploymentDetail{}, err
+	}
+
+	events, err := client.GetEventsForObject(ctx, k8s.ObjectReference{Kind: "Deployment", Namespace: ns, Name: name})
+	if err != nil {
+		return deploymentDetail{}, err
+	}
+	return deploymentDetail{DeploymentInfo: info, Events: events}, nil
+}
+
+// formatDeploymentDetail writes a deployment and its events in the given output format.
+func formatDeploymentDetail(out io.Writer, detail deploymentDetail, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(detail)
+	case "yaml":
+		data, err := yaml.Marshal(detail)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case "table":
+		return writeDeploymentDetail(out, detail)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writeDeploymentDetail writes a human-readable description of a deployment followed by its events.
+func writeDeploymentDetail(out io.Writer, detail deploymentDetail) error {
+	if err := writeDeploymentSummary(out, detail.DeploymentInfo); err != nil {
+		return err
+	}
+	if err := writeDeploymentConditions(out, detail.Conditions); err != nil {
+		return err
+	}
+
+	if len(detail.Events) == 0 {
+		_, err := fmt.Fprintln(out, "Events:      <none>")
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "Events:"); err != nil {
+		return err
+	}
+	return writeEventsTable(out, "  ", detail.Events)
+}
+
+// writeDeploymentSummary writes the metadata, replica status and spec summary of a deployment.
+func writeDeploymentSummary(out io.Writer, info k8s.DeploymentInfo) error {
+	replicas := info.Replicas
+	images := "<none>"
+	if len(info.Images) > 0 {
+		images = strings.Join(info.Images, ", ")
+	}
+
+	if _, err := fmt.Fprintf(out,
+		"Name:        %s\nNamespace:   %s\nLabels:      %s\nAnnotations: %s\nSelector:    %s\n"+
+			"Replicas:    %d desired | %d updated | %d ready | %d available\nStrategy:    %s\n"+
+			"Generation:  %d (observed %d)\nImages:      %s\nAge:         %s\n\n",
+		info.Name, info.Namespace, formatLabelMap(info.Labels, ", "), formatLabelMap(info.Annotations, ", "),
+		valueOrNone(info.Selector), replicas.Desired, replicas.Updated, replicas.Ready, replicas.Available,
+		formatStrategy(info.Strategy), info.Generation, info.ObservedGeneration,
+		images, formatAge(info.Age)); err != nil {
+		return fmt.Errorf("failed to write deployment: %w", err)
+	}
+	return nil
+}
+
+// writeDeploymentConditions writes the conditions of a deployment as an indented table.
+func writeDeploymentConditions(out io.Writer, conditions []k8s.DeploymentCondition) error {
+	if len(conditions) == 0 {
+		_, err := fmt.Fprint(out, "Conditions:  <none>\n\n")
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "Conditions:"); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, condition := range conditions {
+		if _, err := fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", condition.Type, condition.Status,
+			valueOrNone(condition.Reason), condition.Message); err != nil {
+			return fmt.Errorf("failed to write condition row: %w", err)
+		}
+	}
+	flushTableWriter(w)
+	_, err := fmt.Fprintln(out)
+	return err
+}
+
+// formatLabelMap formats labels or annotations as sorted KEY=VALUE pairs joined by sep.
+func formatLabelMap(values map[string]string, sep string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	pairs := make([]string, 0, len(values))
+	for key, value := range values {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, sep)
+}
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+	getCmd.AddCommand(getDeploymentCmd)
+
+	getDeploymentCmd.Flags().StringVarP(&getDeploymentOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+	getDeploymentCmd.Flags().StringVarP(&getDeploymentOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+
+	addClientFlags(getDeploymentCmd, &getDeploymentOpts, 30)
+}
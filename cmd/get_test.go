@@ -0,0 +1,105 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the get command definition and deployment detail output.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestGetDeploymentCommandDefined verifies that the get deployment command is registered with aliases and flags.
+func TestGetDeploymentCommandDefined(t *testing.T) {
+	if getDeploymentCmd.Parent() != getCmd {
+		t.Fatal("deployment should be a subcommand of get")
+	}
+	if len(getDeploymentCmd.Aliases) == 0 || getDeploymentCmd.Aliases[0] != "deploy" {
+		t.Errorf("expected 'deploy' alias, got %v", getDeploymentCmd.Aliases)
+	}
+
+	for _, name := range []string{"namespace", "output", "kubeconfig", "context", "timeout"} {
+		if getDeploymentCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestFetchDeploymentDetail verifies that a deployment is returned together with its events.
+func TestFetchDeploymentDetail(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+
+	detail, err := fetchDeploymentDetail(context.Background(), client, "shop", "checkout")
+	if err != nil {
+		t.Fatalf("fetchDeploymentDetail() error = %v", err)
+	}
+	if detail.Name != "checkout" || len(detail.Events) != 1 || detail.Events[0].Reason != "ScalingReplicaSet" {
+		t.Errorf("unexpected detail %+v", detail)
+	}
+
+	if _, err := fetchDeploymentDetail(context.Background(), client, "shop", "missing"); err == nil {
+		t.Error("expected error for missing deployment")
+	}
+}
+
+// TestFormatDeploymentDetail verifies the output formats of the get deployment command.
+func TestFormatDeploymentDetail(t *testing.T) {
+	detail := deploymentDetail{
+		DeploymentInfo: k8s.DeploymentInfo{Name: testDeploymentName, Namespace: testNamespaceDefault,
+			Images: []string{testImageNginx}, Age: time.Hour,
+			Labels: map[string]string{"tier": "web", "app": "nginx"}, Selector: "app=nginx",
+			Strategy:   k8s.DeploymentStrategy{Type: "RollingUpdate", MaxSurge: "25%", MaxUnavailable: "0"},
+			Generation: 3, ObservedGeneration: 2,
+			Conditions: []k8s.DeploymentCondition{{Type: "Progressing", Status: "False",
+				Reason: "ProgressDeadlineExceeded", Message: "deadline exceeded"}}},
+		Events: testEvents(),
+	}
+
+	tests := []struct {
+		name     string
+		detail   deploymentDetail
+		format   string
+		contains []string
+	}{
+		{"table with events", detail, "table", []string{"Name:        " + testDeploymentName, testImageNginx,
+			"Labels:      app=nginx, tier=web", "Selector:    app=nginx",
+			"Strategy:    RollingUpdate (25% surge, 0 unavailable)", "Generation:  3 (observed 2)",
+			"Conditions:\n  TYPE", "ProgressDeadlineExceeded  deadline exceeded",
+			"Events:\n  LAST SEEN", "FailedCreate"}},
+		{"table without events", deploymentDetail{DeploymentInfo: k8s.DeploymentInfo{Name: testDeploymentName}},
+			"table", []string{"Conditions:  <none>", "Events:      <none>"}},
+		{"yaml", detail, "yaml", []string{"name: " + testDeploymentName, "events:"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := formatDeploymentDetail(&out, tt.detail, tt.format); err != nil {
+				t.Fatalf("formatDeploymentDetail() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if err := formatDeploymentDetail(&out, detail, "json"); err != nil {
+		t.Fatalf("formatDeploymentDetail() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if decoded["name"] != testDeploymentName || decoded["events"] == nil {
+		t.Errorf("expected flattened deployment fields and events, got %v", decoded)
+	}
+}
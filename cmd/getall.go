@@ -0,0 +1,258 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'get all' command which summarizes the workloads and services of a namespace.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// getAllOpts are the namespace, connection and timeout flags of get all.
+var getAllOpts clientOptions
+
+// getAllCmd represents the get all command.
+var getAllCmd = &cobra.Command{
+	Use:   "all",
+	Short: "List the deployments, statefulsets, daemonsets, services and pods of a namespace",
+	Long: `List the deployments, statefulsets, daemonsets, services and pods of a namespace
+in one section per kind, like kubectl get all. Kinds without objects are left out.
+
+The namespace defaults to "default", or with --context-namespace to the namespace
+of the kubeconfig context; -A lists all namespaces.
+
+Examples:
+  kc get all
+  kc get all -n shop
+  kc get all -A -o json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runGetAll(os.Stdout, &getAllOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to list resources")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// allResources are the objects listed by get all, grouped by kind.
+type allResources struct {
+	Deployments  []k8s.DeploymentInfo  `json:"deployments" yaml:"deployments"`
+	StatefulSets []k8s.StatefulSetInfo `json:"statefulsets" yaml:"statefulsets"`
+	DaemonSets   []k8s.DaemonSetInfo   `json:"daemonsets" yaml:"daemonsets"`
+	Services     []k8s.ServiceInfo     `json:"services" yaml:"services"`
+	Pods         []k8s.PodInfo         `json:"pods" yaml:"pods"`
+}
+
+// empty reports whether no objects were found.
+func (r allResources) empty() bool {
+	return len(r.Deployments)+len(r.StatefulSets)+len(r.DaemonSets)+len(r.Services)+len(r.Pods) == 0
+}
+
+// runGetAll lists the objects of each kind and prints them in the selected output format.
+func runGetAll(out io.Writer, opts *clientOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return err
+	}
+	opts.applyDefaultNamespace()
+	if !opts.allNamespaces {
+		opts.namespace = opts.namespaceOrDefault()
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	stop := startProgress("Listing resources")
+	resources, err := fetchAllResources(ctx, client, opts.namespace)
+	stop()
+	if err != nil {
+		return err
+	}
+	return formatAllResources(out, resources, opts)
+}
+
+// fetchAllResources lists the objects of each kind in a namespace, or in all namespaces if ns is empty.
+func fetchAllResources(ctx context.Context, client *k8s.Client, ns string) (allResources, error) {
+	var resources allResources
+	var err error
+	if resources.Deployments, err = client.ListDeployments(ctx, k8s.ListDeploymentsOptions{Namespace: ns}); err != nil {
+		return allResources{}, err
+	}
+	if resources.StatefulSets, err = client.ListStatefulSets(ctx, ns); err != nil {
+		return allResources{}, err
+	}
+	if resources.DaemonSets, err = client.ListDaemonSets(ctx, ns); err != nil {
+		return allResources{}, err
+	}
+	if resources.Services, err = client.ListServices(ctx, ns); err != nil {
+		return allResources{}, err
+	}
+	if resources.Pods, err = client.ListPods(ctx, ns); err != nil {
+		return allResources{}, err
+	}
+	return resources, nil
+}
+
+// formatAllResources writes the objects in the output format of opts. Table output has one section per kind,
+// with a NAMESPACE column if the namespace of opts is empty.
+func formatAllResources(out io.Writer, resources allResources, opts *clientOptions) error {
+	ns := opts.namespace
+	switch opts.output {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resources)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(resources)
+	}
+
+	if resources.empty() {
+		if ns == "" {
+			_, err := fmt.Fprintln(out, "No resources found.")
+			return err
+		}
+		_, err := fmt.Fprintf(out, "No resources found in %s namespace.\n", ns)
+		return err
+	}
+
+	sections := []resourceSection{
+		podsSection(resources.Pods),
+		servicesSection(resources.Services),
+		daemonSetsSection(resources.DaemonSets),
+		deploymentsSection(resources.Deployments),
+		statefulSetsSection(resources.StatefulSets),
+	}
+	first := true
+	for _, section := range sections {
+		if len(section.rows) == 0 {
+			continue
+		}
+		if !first {
+			if _, err := fmt.Fprintln(out); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := section.write(out, ns == "", !opts.noHeaders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourceSection is the table of one kind in the output of get all. The first column of each row
+// is the namespace, which is only written for all namespaces.
+type resourceSection struct {
+	header []string
+	rows   [][]string
+}
+
+// write writes the section as an aligned table, with a header row if withHeader is set.
+func (s resourceSection) write(out io.Writer, withNamespace, withHeader bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	defer flushTableWriter(w)
+
+	columns := func(row []string) string {
+		if withNamespace {
+			return strings.Join(row, "\t")
+		}
+		return strings.Join(row[1:], "\t")
+	}
+	if withHeader {
+		if _, err := fmt.Fprintln(w, columns(append([]string{"NAMESPACE"}, s.header...))); err != nil {
+			return fmt.Errorf("failed to write table header: %w", err)
+		}
+	}
+	for _, row := range s.rows {
+		if _, err := fmt.Fprintln(w, columns(row)); err != nil {
+			return fmt.Errorf("failed to write table row: %w", err)
+		}
+	}
+	return nil
+}
+
+// podsSection returns the pod table of get all.
+func podsSection(pods []k8s.PodInfo) resourceSection {
+	section := resourceSection{header: []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"}}
+	for _, pod := range pods {
+		section.rows = append(section.rows, []string{pod.Namespace, "pod/" + pod.Name,
+			fmt.Sprintf("%d/%d", pod.Ready, pod.Containers), pod.Status,
+			strconv.Itoa(int(pod.Restarts)), formatAge(pod.Age)})
+	}
+	return section
+}
+
+// servicesSection returns the service table of get all.
+func servicesSection(services []k8s.ServiceInfo) resourceSection {
+	section := resourceSection{header: []string{"NAME", "TYPE", "CLUSTER-IP", "EXTERNAL-IP", "PORT(S)", "AGE"}}
+	for _, svc := range services {
+		section.rows = append(section.rows, []string{svc.Namespace, "service/" + svc.Name, svc.Type,
+			valueOrNone(svc.ClusterIP), valueOrNone(svc.ExternalIP), valueOrNone(strings.Join(svc.Ports, ",")),
+			formatAge(svc.Age)})
+	}
+	return section
+}
+
+// daemonSetsSection returns the daemonset table of get all.
+func daemonSetsSection(daemonSets []k8s.DaemonSetInfo) resourceSection {
+	section := resourceSection{header: []string{"NAME", "DESIRED", "CURRENT", "READY", "UP-TO-DATE", "AVAILABLE",
+		"NODE SELECTOR", "AGE"}}
+	for _, ds := range daemonSets {
+		section.rows = append(section.rows, []string{ds.Namespace, "daemonset.apps/" + ds.Name,
+			strconv.Itoa(int(ds.Desired)), strconv.Itoa(int(ds.Current)), strconv.Itoa(int(ds.Ready)),
+			strconv.Itoa(int(ds.UpToDate)), strconv.Itoa(int(ds.Available)), valueOrNone(ds.NodeSelector),
+			formatAge(ds.Age)})
+	}
+	return section
+}
+
+// deploymentsSection returns the deployment table of get all.
+func deploymentsSection(deployments []k8s.DeploymentInfo) resourceSection {
+	section := resourceSection{header: []string{"NAME", "READY", "UP-TO-DATE", "AVAILABLE", "AGE"}}
+	for _, d := range deployments {
+		section.rows = append(section.rows, []string{d.Namespace, "deployment.apps/" + d.Name,
+			fmt.Sprintf("%d/%d", d.Replicas.Ready, d.Replicas.Desired), strconv.Itoa(int(d.Replicas.Updated)),
+			strconv.Itoa(int(d.Replicas.Available)), formatAge(d.Age)})
+	}
+	return section
+}
+
+// statefulSetsSection returns the statefulset table of get all.
+func statefulSetsSection(statefulSets []k8s.StatefulSetInfo) resourceSection {
+	section := resourceSection{header: []string{"NAME", "READY", "AGE"}}
+	for _, sts := range statefulSets {
+		section.rows = append(section.rows, []string{sts.Namespace, "statefulset.apps/" + sts.Name,
+			fmt.Sprintf("%d/%d", sts.Ready, sts.Desired), formatAge(sts.Age)})
+	}
+	return section
+}
+
+func init() {
+	getCmd.AddCommand(getAllCmd)
+
+	addNamespaceFlags(getAllCmd, &getAllOpts)
+	getAllCmd.Flags().Lookup("namespace").Usage =
+		"Kubernetes namespace (default: default, or the namespace of the kubeconfig context with --context-namespace)"
+	getAllCmd.Flags().StringVarP(&getAllOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+	getAllCmd.Flags().BoolVar(&getAllOpts.noHeaders, "no-headers", false,
+		"Don't print the header rows of table output")
+
+	addClientFlags(getAllCmd, &getAllOpts, 30)
+}
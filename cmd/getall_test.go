@@ -0,0 +1,97 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the get all command.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestGetAllCommandDefined verifies that the get all command is registered with its flags.
+func TestGetAllCommandDefined(t *testing.T) {
+	if getAllCmd.Parent() != getCmd {
+		t.Fatal("all should be a subcommand of get")
+	}
+	for _, name := range []string{"namespace", "all-namespaces", "output", "no-headers", "kubeconfig", "timeout"} {
+		if getAllCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestFetchAllResources verifies that the objects of each kind are listed for a namespace.
+func TestFetchAllResources(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+
+	resources, err := fetchAllResources(context.Background(), client, "shop")
+	if err != nil {
+		t.Fatalf("fetchAllResources() error = %v", err)
+	}
+	if len(resources.Deployments) != 3 || len(resources.Pods) != 0 {
+		t.Errorf("expected the 3 deployments of shop, got %+v", resources)
+	}
+}
+
+// TestFormatAllResources verifies the sections of table output and the structured formats.
+func TestFormatAllResources(t *testing.T) {
+	resources := allResources{
+		Deployments: []k8s.DeploymentInfo{{Name: "web", Namespace: "shop", Age: time.Hour}},
+		Services: []k8s.ServiceInfo{{Name: "web", Namespace: "shop", Type: "ClusterIP", ClusterIP: "10.0.0.1",
+			Ports: []string{"80/TCP"}, Age: time.Hour}},
+		Pods: []k8s.PodInfo{{Name: "web-1", Namespace: "shop", Ready: 1, Containers: 1, Status: "Running",
+			Age: time.Minute}},
+	}
+
+	tests := []struct {
+		name      string
+		resources allResources
+		ns        string
+		format    string
+		want      []string
+		notWant   []string
+	}{
+		{"sections", resources, "shop", "table",
+			[]string{"pod/web-1", "1/1", "service/web", "10.0.0.1", "<none>", "80/TCP", "deployment.apps/web"},
+			[]string{"NAMESPACE", "statefulset", "daemonset"}},
+		{"all namespaces", resources, "", "table", []string{"NAMESPACE", "shop"}, nil},
+		{"empty namespace", allResources{}, "shop", "table", []string{"No resources found in shop namespace."}, nil},
+		{"yaml", resources, "shop", "yaml", []string{"deployments:", "services:", "name: web-1"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := &clientOptions{namespace: tt.ns, output: tt.format}
+			if err := formatAllResources(&buf, tt.resources, opts); err != nil {
+				t.Fatalf("formatAllResources() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected %q in output:\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("did not expect %q in output:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := formatAllResources(&buf, resources, &clientOptions{namespace: "shop", output: "json"}); err != nil {
+		t.Fatalf("formatAllResources(json) error = %v", err)
+	}
+	var decoded allResources
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Pods) != 1 {
+		t.Errorf("expected the resources as JSON, got %s (%v)", buf.String(), err)
+	}
+}
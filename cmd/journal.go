@@ -0,0 +1,260 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'journal' command which records and queries the cluster change journal.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/journal"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags for the journal commands
+var (
+	// journalDir is the directory holding the journal files.
+	journalDir string
+
+	// journalKinds lists the resources recorded by 'journal record'.
+	journalKinds []string
+
+	// journalMaxFileMiB is the size in MiB after which a new journal file is started.
+	journalMaxFileMiB int
+
+	// journalMaxFiles is the number of journal files kept.
+	journalMaxFiles int
+
+	// journalIncludeObjects stores full objects with each entry.
+	journalIncludeObjects bool
+
+	// journalSince and journalUntil bound 'journal query' results.
+	journalSince, journalUntil string
+
+	// journalKind filters 'journal query' results by resource type.
+	journalKind string
+
+	// journalQueryOutput is the output format of 'journal query'.
+	journalQueryOutput string
+
+	// journalQueryNamespace filters 'journal query' results by namespace.
+	journalQueryNamespace string
+
+	// journalRecordOpts are the namespace, connection and timeout flags of 'journal record'.
+	journalRecordOpts clientOptions
+)
+
+// journalCmd represents the journal command.
+// It serves as a parent command for recording and querying the change journal.
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Record and query a journal of cluster changes",
+	Long: `Record the stream of cluster changes to disk and query it later,
+e.g. to review what changed during an incident.
+
+Examples:
+  kc journal record --dir=/var/lib/kc/journal
+  kc journal query --dir=/var/lib/kc/journal --since=14:00 --until=14:10 --kind=deployment`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// journalRecordCmd represents the journal record command.
+var journalRecordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record cluster changes to disk until interrupted",
+	Long: `Watch the given resources and append every add, update and delete to
+rotating NDJSON files in --dir.
+
+The last recorded resourceVersion of each kind is checkpointed, so after a restart
+only objects that changed in the meantime are journaled again, as SYNCED entries.
+Deletions that happened while no recorder was running are not journaled.
+
+Examples:
+  kc journal record --dir=./journal
+  kc journal record --dir=./journal --kinds=deployments,pods -n shop --include-objects`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runJournalRecord(&journalRecordOpts); err != nil {
+			log.Error().Err(err).Msg("Journal recording failed")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// journalQueryCmd represents the journal query command.
+var journalQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Query recorded cluster changes",
+	Long: `Print journaled changes, oldest first.
+
+--since and --until accept RFC 3339 timestamps, a time of day today ("14:00",
+"14:10:30"), a date and time ("2026-10-15 14:00") or a duration before now ("2h").
+
+Examples:
+  kc journal query --dir=./journal --since=14:00 --until=14:10
+  kc journal query --dir=./journal --since=2h --kind=deploy -n shop -o json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runJournalQuery(os.Stdout, journalQueryNamespace, time.Now()); err != nil {
+			log.Error().Err(err).Msg("Journal query failed")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runJournalRecord records changes of the configured resources until SIGINT or SIGTERM.
+func runJournalRecord(opts *clientOptions) error {
+	resources, err := lookupResources(journalKinds)
+	if err != nil {
+		return err
+	}
+	opts.applyDefaultNamespace()
+
+	writer, err := journal.NewWriter(journalDir, journal.WriterOptions{
+		MaxFileBytes: int64(journalMaxFileMiB) << 20,
+		MaxFiles:     journalMaxFiles,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := writer.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close journal")
+		}
+	}()
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	recorder := journal.NewRecorder(client.GetDynamicClient(), resources, writer, log.Logger,
+		journal.RecorderOptions{
+			Namespace:      opts.namespace,
+			IncludeObjects: journalIncludeObjects,
+			Redactor:       outputRedactor,
+		})
+	return recorder.Run(ctx)
+}
+
+// runJournalQuery prints the journal entries of the namespace, or all namespaces if it is empty,
+// matching the query flags.
+func runJournalQuery(out io.Writer, namespace string, now time.Time) error {
+	if journalQueryOutput != "table" && journalQueryOutput != "json" {
+		return newUsageError("unsupported format '%s', must be one of: table, json", journalQueryOutput)
+	}
+
+	filter := journal.Filter{Namespace: namespace}
+	var err error
+	if filter.Since, err = parseJournalTime(journalSince, now); err != nil {
+		return newUsageError("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseJournalTime(journalUntil, now); err != nil {
+		return newUsageError("invalid --until: %w", err)
+	}
+	if journalKind != "" {
+		info, err := k8s.LookupResource(journalKind)
+		if err != nil {
+			return err
+		}
+		filter.Kind = info.Kind
+	}
+
+	if journalQueryOutput == "json" {
+		encoder := json.NewEncoder(out)
+		return journal.Query(journalDir, filter, func(e journal.Entry) error { return encoder.Encode(e) })
+	}
+	return writeJournalTable(out, filter)
+}
+
+// writeJournalTable prints the matching journal entries as an aligned table.
+func writeJournalTable(out io.Writer, filter journal.Filter) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintln(w, "TIME\tTYPE\tKIND\tNAMESPACE\tNAME\tRESOURCE VERSION"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	return journal.Query(journalDir, filter, func(e journal.Entry) error {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(time.DateTime),
+			e.Type, e.Kind, e.Namespace, e.Name, e.ResourceVersion); err != nil {
+			return fmt.Errorf("failed to write journal row: %w", err)
+		}
+		return nil
+	})
+}
+
+// parseJournalTime parses a --since/--until value relative to now. An empty value yields the zero time.
+func parseJournalTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			year, month, day := now.Date()
+			return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+func init() {
+	rootCmd.AddCommand(journalCmd)
+	journalCmd.AddCommand(journalRecordCmd, journalQueryCmd)
+
+	for _, cmd := range []*cobra.Command{journalRecordCmd, journalQueryCmd} {
+		cmd.Flags().StringVar(&journalDir, "dir", "journal",
+			"Directory holding the journal files")
+	}
+	addNamespaceFlags(journalRecordCmd, &journalRecordOpts)
+	journalQueryCmd.Flags().StringVarP(&journalQueryNamespace, "namespace", "n", "",
+		"Namespace of the journaled objects (default: all namespaces)")
+
+	journalRecordCmd.Flags().StringSliceVar(&journalKinds, "kinds",
+		[]string{"deployments", "replicasets", "pods", "services", "configmaps"},
+		"Resources to record")
+	journalRecordCmd.Flags().IntVar(&journalMaxFileMiB, "max-file-size", journal.DefaultMaxFileBytes>>20,
+		"Size in MiB after which a new journal file is started")
+	journalRecordCmd.Flags().IntVar(&journalMaxFiles, "max-files", journal.DefaultMaxFiles,
+		"Number of journal files to keep")
+	journalRecordCmd.Flags().BoolVar(&journalIncludeObjects, "include-objects", false,
+		"Store the full object with each entry")
+	addClientFlags(journalRecordCmd, &journalRecordOpts, 30)
+
+	journalQueryCmd.Flags().StringVar(&journalSince, "since", "",
+		"Only show changes at or after this time")
+	journalQueryCmd.Flags().StringVar(&journalUntil, "until", "",
+		"Only show changes at or before this time")
+	journalQueryCmd.Flags().StringVar(&journalKind, "kind", "",
+		"Only show changes of this resource type")
+	journalQueryCmd.Flags().StringVarP(&journalQueryOutput, "output", "o", "table",
+		"Output format (table|json)")
+}
@@ -0,0 +1,108 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the journal command definitions, time parsing and querying.
+package cmd
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Searge/k8s-controller/pkg/journal"
+)
+
+// TestJournalCommandsDefined verifies that the journal subcommands are registered with the expected flags.
+func TestJournalCommandsDefined(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags []string
+	}{
+		{"record", []string{"dir", "namespace", "kinds", "max-file-size", "max-files", "include-objects", "timeout"}},
+		{"query", []string{"dir", "namespace", "since", "until", "kind", "output"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, _, err := journalCmd.Find([]string{tt.name})
+			if err != nil || cmd.Name() != tt.name {
+				t.Fatalf("expected journal %s subcommand, got %v", tt.name, err)
+			}
+			for _, name := range tt.flags {
+				if cmd.Flags().Lookup(name) == nil {
+					t.Errorf("expected '%s' flag to be defined", name)
+				}
+			}
+		})
+	}
+}
+
+// TestParseJournalTime verifies all accepted time formats.
+func TestParseJournalTime(t *testing.T) {
+	now := time.Date(2026, 10, 15, 16, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2026-10-15T14:00:00Z", time.Date(2026, 10, 15, 14, 0, 0, 0, time.UTC), false},
+		{"2026-10-14 09:15", time.Date(2026, 10, 14, 9, 15, 0, 0, time.UTC), false},
+		{"14:10", time.Date(2026, 10, 15, 14, 10, 0, 0, time.UTC), false},
+		{"14:10:30", time.Date(2026, 10, 15, 14, 10, 30, 0, time.UTC), false},
+		{"2h", now.Add(-2 * time.Hour), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseJournalTime(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJournalTime(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseJournalTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunJournalQuery verifies filtering by time range and resource type alias.
+func TestRunJournalQuery(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := journal.NewWriter(dir, journal.WriterOptions{})
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	now := time.Now()
+	for i, kind := range []string{"Deployment", "Pod", "Deployment"} {
+		if err := writer.Write(journal.Entry{
+			Time: now.Add(time.Duration(i-3) * time.Hour), Type: journal.EventModified,
+			Kind: kind, Namespace: testNamespaceDefault, Name: "web", ResourceVersion: strconv.Itoa(i + 1),
+		}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	journalDir, journalSince, journalUntil, journalKind = dir, "150m", "", "deploy"
+	journalQueryOutput = "table"
+	defer func() { journalDir, journalSince, journalKind, journalQueryOutput = "journal", "", "", "table" }()
+
+	var out bytes.Buffer
+	if err := runJournalQuery(&out, "", now); err != nil {
+		t.Fatalf("runJournalQuery() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "Deployment") || !strings.Contains(lines[1], "3") {
+		t.Errorf("expected header and the last deployment change, got:\n%s", out.String())
+	}
+
+	journalQueryOutput = "yaml"
+	if err := runJournalQuery(&out, "", now); err == nil {
+		t.Error("expected error for unsupported output format")
+	}
+}
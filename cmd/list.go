@@ -5,7 +5,9 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
@@ -40,18 +42,8 @@ Examples:
 
 // Shared flags for list operations
 var (
-	// namespace specifies the Kubernetes namespace to list resources from.
-	// If empty, resources from all namespaces will be listed.
-	namespace string
-
-	// outputFormat specifies the output format for the listed resources.
-	// Supported formats: table, json
-	outputFormat string
-
-	// labelSelector allows filtering resources by labels.
-	// Uses the standard Kubernetes label selector syntax (e.g., "app=nginx", "tier=frontend,environment=prod").
-	// Supports equality-based (=, ==, !=) and set-based (in, notin, exists) selectors.
-	labelSelector string
+	// listDeploymentsOpts are the namespace, connection, timeout, output and selector flags of list deployments.
+	listDeploymentsOpts clientOptions
 )
 
 // listDeploymentsCmd represents the list deployments command.
@@ -67,70 +59,100 @@ You can filter by namespace and choose different output formats.
 Examples:
   kc list deployments                           # List all deployments
   kc list deployments -n default               # List deployments in default namespace
+  kc list deployments -A                       # List deployments in all namespaces
   kc list deployments -o json                  # Output in JSON format
+  kc list deployments -o wide                  # Add strategy, selector and condition columns
+  kc list deployments -o name                  # Print deployment.apps/NAME lines for scripting
+  kc list deployments -o markdown              # Print a Markdown table for issues and runbooks
   kc list deployments -n kube-system -o table  # Specific namespace, table format
   kc list deployments -l app=nginx             # Filter by label selector
+  kc list deployments --show-labels            # Add a LABELS column
+  kc list deployments --no-headers             # Omit the header row
+  kc list deployments --chunk-size=100         # Fetch and print 100 deployments per API call
+  kc list deployments --all-contexts           # List deployments of all kubeconfig contexts
+  kc list deployments -w                       # List, then print changes until interrupted
+  kc list deployments --watch-only             # Print only changes
   kc list deployments --kubeconfig=/path/to/config  # Use specific kubeconfig`,
 	Run: func(_ *cobra.Command, _ []string) {
 		log.Info().
-			Str("namespace", namespace).
-			Str("output", outputFormat).
-			Str("labelSelector", labelSelector).
+			Str("namespace", listDeploymentsOpts.namespace).
+			Str("output", listDeploymentsOpts.output).
+			Str("labelSelector", listDeploymentsOpts.labelSelector).
 			Msg("Listing deployments")
 
-		if err := runListDeployments(); err != nil {
+		if err := runListDeployments(&listDeploymentsOpts); err != nil {
 			log.Error().Err(err).Msg("Failed to list deployments")
-			os.Exit(1)
+			exit(exitCode(err))
 		}
 	},
 }
 
 // runListDeployments executes the deployment listing logic.
 // It creates a Kubernetes client, fetches deployments, and formats the output.
-func runListDeployments() error {
+func runListDeployments(opts *clientOptions) error {
 	// Validate input parameters
-	if err := validateListParameters(); err != nil {
+	if err := validateListParameters(opts); err != nil {
 		return err
 	}
+	if !allContexts {
+		opts.applyDefaultNamespace()
+	}
+	if opts.output == outputMarkdown {
+		colorOutput = false
+	}
+	if watchDeployments || watchOnly {
+		return runWatchDeployments(os.Stdout, opts)
+	}
+	if allContexts {
+		return runListDeploymentsAllContexts(os.Stdout, opts)
+	}
 
 	// Create Kubernetes client
-	client, err := createK8sClient()
+	client, err := opts.newClient()
 	if err != nil {
 		return err
 	}
 	defer closeClient(client)
 
+	// Table output is rendered chunk by chunk as the pages arrive
+	switch opts.output {
+	case "table", outputWide, outputMarkdown:
+		return streamDeploymentTable(client, os.Stdout, opts)
+	}
+
 	// Fetch deployments
-	deployments, err := fetchDeployments(client)
+	deployments, err := fetchDeployments(client, opts)
 	if err != nil {
 		return err
 	}
 
 	// Format and display output
-	return formatDeploymentOutput(deployments, outputFormat)
+	return formatDeploymentOutput(deployments, opts)
 }
 
-// validateListParameters validates the input parameters for list command.
-func validateListParameters() error {
-	if err := validateOutputFormat(outputFormat); err != nil {
-		return fmt.Errorf("invalid output format: %w", err)
+// validateListParameters validates the output and namespace flags of the list command.
+func validateListParameters(opts *clientOptions) error {
+	switch opts.output {
+	case outputWide, outputName:
+	case outputMarkdown:
+		if opts.noHeaders {
+			return newUsageError("--no-headers cannot be combined with markdown output, which needs a header row")
+		}
+	default:
+		if err := validateOutputFormat(opts.output); err != nil {
+			return fmt.Errorf("invalid output format: %w", err)
+		}
 	}
 
-	if err := validateNamespace(namespace); err != nil {
+	if err := validateNamespace(opts.namespace); err != nil {
 		return fmt.Errorf("invalid namespace: %w", err)
 	}
-
-	return nil
-}
-
-// createK8sClient creates and returns a Kubernetes client.
-func createK8sClient() (*k8s.Client, error) {
-	clientConfig := k8s.ClientConfig{
-		KubeconfigPath: kubeconfigPath,
-		Context:        contextName,
+	// Lists are always paged, so chunking cannot be turned off with 0.
+	if chunkSize < 1 {
+		return newUsageError("--chunk-size must be at least 1, got %d", chunkSize)
 	}
 
-	return k8s.CreateClient(clientConfig, log.Logger)
+	return nil
 }
 
 // closeClient safely closes the Kubernetes client.
@@ -141,50 +163,60 @@ func closeClient(client *k8s.Client) {
 }
 
 // fetchDeployments retrieves deployments from the Kubernetes cluster.
-func fetchDeployments(client *k8s.Client) ([]k8s.DeploymentInfo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+func fetchDeployments(client *k8s.Client, opts *clientOptions) ([]k8s.DeploymentInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
 	defer cancel()
 
-	listOptions := k8s.ListDeploymentsOptions{
-		Namespace:     namespace,
-		LabelSelector: labelSelector,
-	}
-
-	deployments, err := client.ListDeployments(ctx, listOptions)
+	stop := startProgress("Listing deployments")
+	deployments, err := client.ListDeployments(ctx, listDeploymentsOptions(opts))
+	stop()
 	if err != nil {
-		return nil, enhanceK8sError(err)
+		return nil, enhanceK8sError(err, opts.namespace)
 	}
 
 	return deployments, nil
 }
 
-// enhanceK8sError provides better error messages for common Kubernetes errors.
-func enhanceK8sError(err error) error {
-	if strings.Contains(err.Error(), "connection refused") {
-		return fmt.Errorf("failed to connect to Kubernetes API server - "+
-			"is the cluster running and accessible? %w", err)
-	}
-	if strings.Contains(err.Error(), "forbidden") {
-		return fmt.Errorf("insufficient permissions to list deployments - "+
-			"check your RBAC configuration: %w", err)
+// listDeploymentsOptions returns the list options selected by the list flags.
+func listDeploymentsOptions(opts *clientOptions) k8s.ListDeploymentsOptions {
+	return k8s.ListDeploymentsOptions{
+		Namespace:     opts.namespace,
+		LabelSelector: opts.labelSelector,
+		Limit:         chunkSize,
 	}
-	if strings.Contains(err.Error(), "not found") && namespace != "" {
+}
+
+// enhanceK8sError provides better error messages for common Kubernetes errors in the given namespace.
+func enhanceK8sError(err error, namespace string) error {
+	err = k8s.ClassifyError(err)
+	switch {
+	case errors.Is(err, k8s.ErrNotFound) && namespace != "":
 		return fmt.Errorf("namespace '%s' not found: %w", namespace, err)
+	case errors.Is(err, k8s.ErrUnreachable):
+		return fmt.Errorf("failed to connect to Kubernetes API server - %s: %w", k8s.ErrorHint(err), err)
+	case errors.Is(err, k8s.ErrForbidden):
+		return fmt.Errorf("insufficient permissions to list deployments - %s: %w", k8s.ErrorHint(err), err)
+	case k8s.ErrorHint(err) != "":
+		return fmt.Errorf("failed to list deployments - %s: %w", k8s.ErrorHint(err), err)
+	default:
+		return fmt.Errorf("failed to list deployments: %w", err)
 	}
-	return fmt.Errorf("failed to list deployments: %w", err)
 }
 
-// formatDeploymentOutput formats and displays deployments in the specified format.
-func formatDeploymentOutput(deployments []k8s.DeploymentInfo, format string) error {
-	switch format {
+// formatDeploymentOutput formats and displays deployments of the namespace of opts, or all namespaces if
+// it is empty, in the output format of opts.
+func formatDeploymentOutput(deployments []k8s.DeploymentInfo, opts *clientOptions) error {
+	switch opts.output {
 	case "json":
 		return formatDeploymentJSON(deployments)
 	case "yaml":
 		return formatDeploymentYAML(deployments)
-	case "table":
-		return formatDeploymentTable(deployments)
+	case "table", outputWide, outputMarkdown:
+		return formatDeploymentTable(deployments, opts)
+	case outputName:
+		return writeDeploymentNames(os.Stdout, deployments)
 	default:
-		return fmt.Errorf("unsupported output format: %s", format)
+		return newUsageError("unsupported output format: %s", opts.output)
 	}
 }
 
@@ -231,21 +263,21 @@ func formatDeploymentYAML(deployments []k8s.DeploymentInfo) error {
 	return nil
 }
 
-// formatDeploymentTable outputs deployments in table format.
-func formatDeploymentTable(deployments []k8s.DeploymentInfo) error {
+// formatDeploymentTable outputs deployments in table format, or as a Markdown table for markdown output.
+func formatDeploymentTable(deployments []k8s.DeploymentInfo, opts *clientOptions) error {
 	if len(deployments) == 0 {
 		fmt.Println("No deployments found.")
 		return nil
 	}
 
-	w := createTableWriter()
-	defer flushTableWriter(w)
+	w, flush := newTableWriter(os.Stdout, opts.output)
+	defer flush()
 
-	if err := writeTableHeader(w); err != nil {
+	if err := writeTableHeader(w, opts); err != nil {
 		return err
 	}
 
-	return writeDeploymentRows(w, deployments)
+	return writeDeploymentRows(w, opts, deployments)
 }
 
 // createTableWriter creates a new tabwriter for aligned output.
@@ -260,13 +292,27 @@ func flushTableWriter(w *tabwriter.Writer) {
 	}
 }
 
-// writeTableHeader writes the appropriate table header based on namespace scope.
-func writeTableHeader(w *tabwriter.Writer) error {
-	var header string
-	if namespace == "" {
-		header = "NAMESPACE\tNAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE\tIMAGES"
-	} else {
-		header = "NAME\tREADY\tUP-TO-DATE\tAVAILABLE\tAGE\tIMAGES"
+// writeTableHeader writes the appropriate table header based on namespace scope, after the
+// leading columns, e.g. CONTEXT. An empty namespace adds the NAMESPACE column, wide output and
+// --show-labels add theirs. Nothing is written with --no-headers.
+func writeTableHeader(w io.Writer, opts *clientOptions, leading ...string) error {
+	if opts.noHeaders {
+		return nil
+	}
+
+	header := "NAME\t" + colorize(colorDefault, "READY") + "\tUP-TO-DATE\tAVAILABLE\t" +
+		colorize(colorDefault, "AGE") + "\tIMAGES"
+	if opts.namespace == "" {
+		header = "NAMESPACE\t" + header
+	}
+	if opts.output == outputWide {
+		header += wideDeploymentHeader
+	}
+	if opts.showLabels {
+		header += labelsHeader
+	}
+	if len(leading) > 0 {
+		header = strings.Join(leading, "\t") + "\t" + header
 	}
 
 	if _, err := fmt.Fprintln(w, header); err != nil {
@@ -276,24 +322,25 @@ func writeTableHeader(w *tabwriter.Writer) error {
 }
 
 // writeDeploymentRows writes all deployment rows to the table.
-func writeDeploymentRows(w *tabwriter.Writer, deployments []k8s.DeploymentInfo) error {
+func writeDeploymentRows(w io.Writer, opts *clientOptions, deployments []k8s.DeploymentInfo) error {
 	for _, deployment := range deployments {
-		if err := writeDeploymentRow(w, deployment); err != nil {
+		if err := writeDeploymentRow(w, opts, deployment); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// writeDeploymentRow writes a single deployment row to the table.
-func writeDeploymentRow(w *tabwriter.Writer, deployment k8s.DeploymentInfo) error {
-	readyStatus := fmt.Sprintf("%d/%d", deployment.Replicas.Ready, deployment.Replicas.Desired)
-	ageString := formatAge(deployment.Age)
+// writeDeploymentRow writes a single deployment row to the table, see writeTableHeader.
+func writeDeploymentRow(w io.Writer, opts *clientOptions, deployment k8s.DeploymentInfo) error {
+	readyStatus := colorize(readyColor(deployment),
+		fmt.Sprintf("%d/%d", deployment.Replicas.Ready, deployment.Replicas.Desired))
+	ageString := colorize(colorDim, formatAge(deployment.Age))
 	imagesString := formatImages(deployment.Images)
 
 	var err error
-	if namespace == "" {
-		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+	if opts.namespace == "" {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s",
 			deployment.Namespace,
 			deployment.Name,
 			readyStatus,
@@ -303,7 +350,7 @@ func writeDeploymentRow(w *tabwriter.Writer, deployment k8s.DeploymentInfo) erro
 			imagesString,
 		)
 	} else {
-		_, err = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n",
+		_, err = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s",
 			deployment.Name,
 			readyStatus,
 			deployment.Replicas.Updated,
@@ -312,6 +359,15 @@ func writeDeploymentRow(w *tabwriter.Writer, deployment k8s.DeploymentInfo) erro
 			imagesString,
 		)
 	}
+	if err == nil && opts.output == outputWide {
+		err = writeWideDeploymentColumns(w, deployment)
+	}
+	if err == nil && opts.showLabels {
+		err = writeLabelsColumn(w, deployment)
+	}
+	if err == nil {
+		_, err = fmt.Fprintln(w)
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to write deployment row: %w", err)
@@ -380,7 +436,7 @@ func validateOutputFormat(format string) error {
 	case "table", "json", "yaml":
 		return nil
 	default:
-		return fmt.Errorf("unsupported format '%s', must be one of: table, json, yaml", format)
+		return newUsageError("unsupported format '%s', must be one of: table, json, yaml", format)
 	}
 }
 
@@ -392,10 +448,12 @@ func validateNamespace(ns string) error {
 	}
 
 	if err := validateNamespaceLength(ns); err != nil {
-		return err
+		return &usageError{err: err}
 	}
-
-	return validateNamespaceCharacters(ns)
+	if err := validateNamespaceCharacters(ns); err != nil {
+		return &usageError{err: err}
+	}
+	return nil
 }
 
 // validateNamespaceLength checks if the namespace name length is within limits.
@@ -449,21 +507,13 @@ func init() {
 	listCmd.AddCommand(listDeploymentsCmd)
 
 	// Add flags to the deployments command
-	listDeploymentsCmd.Flags().StringVarP(&namespace, "namespace", "n", "",
-		"Kubernetes namespace (default: all namespaces)")
+	addNamespaceFlags(listDeploymentsCmd, &listDeploymentsOpts)
 
-	listDeploymentsCmd.Flags().StringVarP(&outputFormat, "output", "o", "table",
-		"Output format (table|json|yaml)")
+	listDeploymentsCmd.Flags().StringVarP(&listDeploymentsOpts.output, "output", "o", "table",
+		"Output format (table|wide|name|markdown|json|yaml)")
 
-	listDeploymentsCmd.Flags().StringVarP(&labelSelector, "selector", "l", "",
+	listDeploymentsCmd.Flags().StringVarP(&listDeploymentsOpts.labelSelector, "selector", "l", "",
 		"Label selector to filter deployments")
 
-	listDeploymentsCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "",
-		"Path to kubeconfig file (default: $KUBECONFIG or $HOME/.kube/config)")
-
-	listDeploymentsCmd.Flags().StringVar(&contextName, "context", "",
-		"Kubernetes context to use (default: current context from kubeconfig)")
-
-	listDeploymentsCmd.Flags().IntVar(&timeoutSeconds, "timeout", 30,
-		"Timeout for Kubernetes operations in seconds")
+	addClientFlags(listDeploymentsCmd, &listDeploymentsOpts, 30)
 }
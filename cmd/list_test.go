@@ -3,12 +3,18 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
 	"github.com/Searge/k8s-controller/pkg/k8s"
 )
 
@@ -72,6 +78,7 @@ func TestListDeploymentsCommandDefined(t *testing.T) {
 		{"kubeconfig", "", true},
 		{"context", "", true},
 		{"timeout", "", true},
+		{"chunk-size", "", true},
 	}
 
 	for _, tt := range tests {
@@ -190,10 +197,9 @@ func runFlagParsingTest(t *testing.T, args []string, expectedNamespace, expected
 	t.Helper()
 
 	// Reset variables
-	namespace = ""
-	outputFormat = "table"
-	labelSelector = ""
-	timeoutSeconds = 30
+	listDeploymentsOpts.namespace = ""
+	listDeploymentsOpts.output = "table"
+	listDeploymentsOpts.labelSelector = ""
 
 	// Parse flags
 	err := listDeploymentsCmd.ParseFlags(args)
@@ -206,11 +212,11 @@ func runFlagParsingTest(t *testing.T, args []string, expectedNamespace, expected
 
 	// Check values if no error expected
 	if !shouldErr {
-		if namespace != expectedNamespace {
-			t.Errorf("expected namespace %s, got %s", expectedNamespace, namespace)
+		if listDeploymentsOpts.namespace != expectedNamespace {
+			t.Errorf("expected namespace %s, got %s", expectedNamespace, listDeploymentsOpts.namespace)
 		}
-		if outputFormat != expectedOutput {
-			t.Errorf("expected output %s, got %s", expectedOutput, outputFormat)
+		if listDeploymentsOpts.output != expectedOutput {
+			t.Errorf("expected output %s, got %s", expectedOutput, listDeploymentsOpts.output)
 		}
 	}
 }
@@ -283,6 +289,38 @@ func TestValidateNamespace(t *testing.T) {
 	}
 }
 
+// TestEnhanceK8sError tests that Kubernetes API errors get category-specific messages.
+func TestEnhanceK8sError(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	tests := []struct {
+		name       string
+		namespace  string
+		err        error
+		wantKind   error
+		wantPrefix string
+	}{
+		{"missing namespace", "absent", apierrors.NewNotFound(deployments, ""), k8s.ErrNotFound,
+			"namespace 'absent' not found"},
+		{"forbidden", "", apierrors.NewForbidden(deployments, "", errors.New("no RBAC")), k8s.ErrForbidden,
+			"insufficient permissions to list deployments - check your RBAC permissions"},
+		{"timeout", "", context.DeadlineExceeded, k8s.ErrTimeout, "failed to list deployments - the API server"},
+		{"other", "", errors.New("boom"), nil, "failed to list deployments: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := enhanceK8sError(tt.err, tt.namespace)
+			if !strings.HasPrefix(err.Error(), tt.wantPrefix) {
+				t.Errorf("expected message starting with %q, got %q", tt.wantPrefix, err.Error())
+			}
+			if tt.wantKind != nil && !errors.Is(err, tt.wantKind) {
+				t.Errorf("expected the error to match %v", tt.wantKind)
+			}
+		})
+	}
+}
+
 // TestFormatAge tests the age formatting function.
 func TestFormatAge(t *testing.T) {
 	tests := []struct {
@@ -431,7 +469,7 @@ func TestFormatDeploymentOutput(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := formatDeploymentOutput(testDeployments, tt.format)
+			err := formatDeploymentOutput(testDeployments, &clientOptions{output: tt.format})
 			if tt.shouldError && err == nil {
 				t.Errorf("formatDeploymentOutput() should return error for format %s", tt.format)
 			}
@@ -553,13 +591,7 @@ func TestFormatDeploymentTable(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			originalNamespace := namespace
-			namespace = tt.namespace
-			defer func() {
-				namespace = originalNamespace
-			}()
-
-			err := formatDeploymentTable(tt.deployments)
+			err := formatDeploymentTable(tt.deployments, &clientOptions{namespace: tt.namespace, output: "table"})
 			if err != nil {
 				t.Errorf("formatDeploymentTable() should not return error, got: %v", err)
 			}
@@ -0,0 +1,133 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements listing deployments in chunks and printing tables as the chunks arrive.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// chunkSize is the number of objects requested per API call when listing.
+// All chunks are fetched, so it trades the number of calls for the size of each response.
+var chunkSize int64
+
+// streamDeploymentTable lists deployments in chunks of --chunk-size and writes the rows of each
+// chunk as soon as it arrives, so that huge lists start rendering right away. The columns are sized
+// to the first chunk and keep their widths in the later ones, see chunkTableWriter.
+func streamDeploymentTable(client *k8s.Client, out io.Writer, opts *clientOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	// The spinner only runs until the first page arrives; after that, the rows show the progress.
+	stop := startProgress("Listing deployments")
+	defer stop()
+	w, flush := newChunkTableWriter(out, opts.output)
+	count := 0
+	err := client.ListDeploymentPages(ctx, listDeploymentsOptions(opts), func(page []k8s.DeploymentInfo) error {
+		stop()
+		if count == 0 {
+			if err := writeTableHeader(w, opts); err != nil {
+				return err
+			}
+		}
+		count += len(page)
+		if err := writeDeploymentRows(w, opts, page); err != nil {
+			return err
+		}
+		return flush()
+	})
+	if err != nil {
+		return enhanceK8sError(err, opts.namespace)
+	}
+
+	if count == 0 {
+		if _, err := fmt.Fprintln(out, "No deployments found."); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return nil
+}
+
+// chunkTableWriter aligns the columns of the tab-separated lines of a table written chunk by chunk.
+// The widths of the columns are computed once, from the lines of the first chunk, header included,
+// so that the rows of all chunks line up. A later cell wider than its column is followed by the
+// column gap only and shifts the rest of its row, as no earlier row can be realigned.
+type chunkTableWriter struct {
+	out     io.Writer
+	pending bytes.Buffer
+	widths  []int
+}
+
+// newChunkTableWriter returns the writer for a table in the given format written chunk by chunk, and
+// the function writing the lines of each chunk: a Markdown table for markdown, aligned columns otherwise.
+func newChunkTableWriter(out io.Writer, format string) (w io.Writer, flush func() error) {
+	if format == outputMarkdown {
+		return &markdownTableWriter{out: out}, func() error { return nil }
+	}
+	c := &chunkTableWriter{out: out}
+	return c, c.flush
+}
+
+// Write buffers p until the chunk is flushed.
+func (c *chunkTableWriter) Write(p []byte) (int, error) {
+	return c.pending.Write(p)
+}
+
+// flush writes the complete lines buffered since the last flush with their cells padded to the
+// widths of the columns, which the first flush computes.
+func (c *chunkTableWriter) flush() error {
+	end := bytes.LastIndexByte(c.pending.Bytes(), '\n')
+	if end < 0 {
+		return nil
+	}
+	lines := strings.Split(string(c.pending.Next(end + 1)[:end]), "\n")
+	if c.widths == nil {
+		c.widths = columnWidths(lines)
+	}
+
+	var chunk strings.Builder
+	for _, line := range lines {
+		cells := strings.Split(line, "\t")
+		for i, cell := range cells[:len(cells)-1] {
+			chunk.WriteString(cell)
+			width := 0
+			if i < len(c.widths) {
+				width = c.widths[i]
+			}
+			// Columns are separated by two spaces, as in the tables of the other commands.
+			chunk.WriteString(strings.Repeat(" ", max(width-utf8.RuneCountInString(cell), 0)+2))
+		}
+		chunk.WriteString(cells[len(cells)-1] + "\n")
+	}
+	if _, err := io.WriteString(c.out, chunk.String()); err != nil {
+		return fmt.Errorf("failed to write table rows: %w", err)
+	}
+	return nil
+}
+
+// columnWidths returns the width of the widest cell of each column of the tab-separated lines.
+// The last cell of a line is not padded, so it does not widen its column.
+func columnWidths(lines []string) []int {
+	var widths []int
+	for _, line := range lines {
+		cells := strings.Split(line, "\t")
+		for i, cell := range cells[:len(cells)-1] {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	return widths
+}
+
+func init() {
+	listDeploymentsCmd.Flags().Int64Var(&chunkSize, "chunk-size", k8s.DefaultPageSize,
+		"Number of deployments fetched per API call, at least 1; all are listed, tables are printed chunk by chunk")
+}
@@ -0,0 +1,94 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the chunked deployment table.
+package cmd
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestStreamDeploymentTable verifies the chunked table output of the demo cluster.
+func TestStreamDeploymentTable(t *testing.T) {
+	demoMode, chunkSize = true, 1
+	defer func() { demoMode, chunkSize = false, k8s.DefaultPageSize }()
+
+	opts := newTestClientOptions("")
+	client, err := opts.newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeClient(client)
+
+	var out strings.Builder
+	if err := streamDeploymentTable(client, &out, opts); err != nil {
+		t.Fatalf("streamDeploymentTable() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "NAMESPACE") || strings.Count(out.String(), "NAMESPACE") != 1 {
+		t.Errorf("expected a single header followed by rows, got:\n%s", out.String())
+	}
+
+	opts.namespace = "missing"
+	out.Reset()
+	if err := streamDeploymentTable(client, &out, opts); err != nil {
+		t.Fatalf("streamDeploymentTable() error = %v", err)
+	}
+	if out.String() != "No deployments found.\n" {
+		t.Errorf("expected no deployments, got %q", out.String())
+	}
+}
+
+// TestChunkSizeValidation verifies that chunking cannot be turned off with a non-positive --chunk-size.
+func TestChunkSizeValidation(t *testing.T) {
+	defer func() { chunkSize = k8s.DefaultPageSize }()
+
+	for _, size := range []int64{0, -1} {
+		chunkSize = size
+		if err := validateListParameters(&clientOptions{output: "table"}); exitCode(err) != exitUsage {
+			t.Errorf("--chunk-size=%d: expected a usage error, got %v", size, err)
+		}
+	}
+	chunkSize = 1
+	if err := validateListParameters(&clientOptions{output: "table"}); err != nil {
+		t.Errorf("--chunk-size=1: unexpected error %v", err)
+	}
+}
+
+// TestChunkTableWriter verifies that the rows of later chunks keep the column widths of the first.
+func TestChunkTableWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"single chunk", []string{"NAME\tAGE\nweb\t5d\n"}, "NAME  AGE\nweb   5d\n"},
+		{"narrower later chunk", []string{"NAME\tAGE\nfrontend\t5d\n", "web\t3h\ndb\t10d\n"},
+			"NAME      AGE\nfrontend  5d\nweb       3h\ndb        10d\n"},
+		{"wider later cell", []string{"NAME\tAGE\nweb\t5d\n", "checkout\t3h\n", "db\t1h\n"},
+			"NAME  AGE\nweb   5d\ncheckout  3h\ndb    1h\n"},
+		{"last cells not padded", []string{"NAME\tIMAGES\nweb\tnginx:1.27\n", "db\tpostgres:16\n"},
+			"NAME  IMAGES\nweb   nginx:1.27\ndb    postgres:16\n"},
+		{"line split across writes", []string{"NAME\tAGE\nweb\t", "5d\n"}, "NAME  AGE\nweb   5d\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			w, flush := newChunkTableWriter(&out, "table")
+			for _, chunk := range tt.chunks {
+				if _, err := io.WriteString(w, chunk); err != nil {
+					t.Fatal(err)
+				}
+				if err := flush(); err != nil {
+					t.Fatalf("flush() error = %v", err)
+				}
+			}
+			if out.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements watching deployments for changes after listing them.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags of the deployment watch.
+var (
+	// watchDeployments keeps listing deployments open and prints their changes as they happen.
+	watchDeployments bool
+
+	// watchOnly prints only the changes, without the deployments that exist when the watch starts.
+	watchOnly bool
+)
+
+// watchColumnWidth is the minimum width of the columns of the watch table. Rows are printed as
+// they arrive, so the columns cannot be sized to their contents.
+const watchColumnWidth = 12
+
+// runWatchDeployments prints the deployments matching the list flags, unless only changes were
+// requested, followed by their changes until interrupted.
+func runWatchDeployments(out io.Writer, opts *clientOptions) error {
+	if allContexts {
+		return newUsageError("--watch cannot be combined with --all-contexts")
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watchDeploymentEvents(ctx, client, out, opts)
+}
+
+// watchDeploymentEvents writes events of the deployments in the namespace of opts, or all namespaces if
+// it is empty, in the output format of opts until ctx is cancelled.
+func watchDeploymentEvents(ctx context.Context, client *k8s.Client, out io.Writer, opts *clientOptions) error {
+	write, err := newDeploymentEventWriter(out, opts)
+	if err != nil {
+		return err
+	}
+
+	watchOpts := k8s.WatchOptions{Namespace: opts.namespace, LabelSelector: opts.labelSelector, SkipExisting: watchOnly}
+	if err := client.WatchDeployments(ctx, watchOpts, write); err != nil {
+		return enhanceK8sError(err, opts.namespace)
+	}
+	return nil
+}
+
+// newDeploymentEventWriter returns a function writing each deployment event in the output format of opts:
+// a table row prefixed with the event type, the deployment's name, or one JSON or YAML document per event.
+func newDeploymentEventWriter(out io.Writer, opts *clientOptions) (func(k8s.DeploymentEvent) error, error) {
+	switch opts.output {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return func(event k8s.DeploymentEvent) error {
+			return encoder.Encode(event)
+		}, nil
+	case "yaml":
+		return func(event k8s.DeploymentEvent) error {
+			data, err := yaml.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("failed to marshal YAML: %w", err)
+			}
+			_, err = fmt.Fprintf(out, "---\n%s", data)
+			return err
+		}, nil
+	case "table", outputWide:
+		return newDeploymentEventTable(out, opts)
+	case outputName:
+		return func(event k8s.DeploymentEvent) error {
+			return writeDeploymentName(out, event.Deployment)
+		}, nil
+	default:
+		return nil, newUsageError("unsupported output format: %s", opts.output)
+	}
+}
+
+// newDeploymentEventTable writes the table header and returns a function writing event rows.
+// Each row is flushed right away, so it appears as soon as the event arrives.
+func newDeploymentEventTable(out io.Writer, opts *clientOptions) (func(k8s.DeploymentEvent) error, error) {
+	w := tabwriter.NewWriter(out, watchColumnWidth, 0, 2, ' ', 0)
+	if err := writeTableHeader(w, opts, "EVENT"); err != nil {
+		return nil, err
+	}
+	flushTableWriter(w)
+
+	return func(event k8s.DeploymentEvent) error {
+		if _, err := fmt.Fprintf(w, "%s\t", event.Type); err != nil {
+			return fmt.Errorf("failed to write deployment row: %w", err)
+		}
+		if err := writeDeploymentRow(w, opts, event.Deployment); err != nil {
+			return err
+		}
+		flushTableWriter(w)
+		return nil
+	}, nil
+}
+
+func init() {
+	listDeploymentsCmd.Flags().BoolVarP(&watchDeployments, "watch", "w", false,
+		"After listing, keep watching and print ADDED, MODIFIED and DELETED rows until interrupted")
+	listDeploymentsCmd.Flags().BoolVar(&watchOnly, "watch-only", false,
+		"Watch for changes without listing the existing deployments first")
+}
@@ -0,0 +1,108 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests watching deployments with list deployments --watch.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestListWatchFlagsDefined verifies that the watch flags are registered on list deployments.
+func TestListWatchFlagsDefined(t *testing.T) {
+	if flag := listDeploymentsCmd.Flags().Lookup("watch"); flag == nil || flag.Shorthand != "w" {
+		t.Error("expected 'watch' flag with shorthand 'w' to be defined")
+	}
+	if listDeploymentsCmd.Flags().Lookup("watch-only") == nil {
+		t.Error("expected 'watch-only' flag to be defined")
+	}
+}
+
+// TestWatchDeploymentEventsDemo verifies that existing deployments are printed as ADDED rows,
+// unless only changes are watched.
+func TestWatchDeploymentEventsDemo(t *testing.T) {
+	demoMode = true
+	defer func() { demoMode, watchOnly = false, false }()
+
+	tests := []struct {
+		name      string
+		watchOnly bool
+		wantRows  bool
+	}{
+		{"list and watch", false, true},
+		{"watch only", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watchOnly = tt.watchOnly
+			opts := newTestClientOptions(testNamespaceDefault)
+			client, err := opts.newClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closeClient(client)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			var out bytes.Buffer
+			if err := watchDeploymentEvents(ctx, client, &out, opts); err != nil {
+				t.Fatalf("watchDeploymentEvents() error = %v", err)
+			}
+
+			if !strings.HasPrefix(out.String(), "EVENT") {
+				t.Errorf("expected the table header first, got:\n%s", out.String())
+			}
+			if strings.Contains(out.String(), "ADDED") != tt.wantRows {
+				t.Errorf("expected ADDED rows: %v, got:\n%s", tt.wantRows, out.String())
+			}
+		})
+	}
+}
+
+// TestDeploymentEventWriter verifies the formats of watched deployment events.
+func TestDeploymentEventWriter(t *testing.T) {
+	event := k8s.DeploymentEvent{
+		Type:       watch.Modified,
+		Deployment: k8s.DeploymentInfo{Name: testDeploymentName, Namespace: testNamespaceDefault},
+	}
+	tests := []struct {
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{"table", []string{"EVENT", "MODIFIED", testDeploymentName}, false},
+		{"json", []string{`"type": "MODIFIED"`, `"name": "` + testDeploymentName + `"`}, false},
+		{"yaml", []string{"---\n", "type: MODIFIED"}, false},
+		{"name", []string{"deployment.apps/" + testDeploymentName + "\n"}, false},
+		{"xml", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := &clientOptions{namespace: testNamespaceDefault, output: tt.format}
+			write, err := newDeploymentEventWriter(&out, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newDeploymentEventWriter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := write(event); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
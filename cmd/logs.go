@@ -0,0 +1,236 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'logs' command which prints or streams pod logs.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags for the logs command
+var (
+	// logsContainer selects the container to print logs from.
+	logsContainer string
+
+	// logsFollow keeps streaming new log lines.
+	logsFollow bool
+
+	// logsTail limits the output to the last N lines (-1 for all).
+	logsTail int64
+
+	// logsSince returns only logs newer than a relative duration.
+	logsSince time.Duration
+
+	// logsPrevious prints logs of the previous container instance.
+	logsPrevious bool
+
+	// logsOpts are the namespace, connection and timeout flags of the logs command.
+	logsOpts clientOptions
+)
+
+// logsCmd represents the logs command.
+// It prints the logs of a pod, or of all pods selected by a deployment.
+var logsCmd = &cobra.Command{
+	Use:   "logs (POD | pod/NAME | deployment/NAME)",
+	Short: "Print the logs of a pod or deployment",
+	Long: `Print the logs of a container in a pod, or of all pods of a deployment.
+
+When a deployment is given, its pods are resolved via the deployment's label
+selector and each log line is prefixed with the pod name.
+
+Examples:
+  kc logs nginx-7c5ddbdf54-x8kz2
+  kc logs nginx-7c5ddbdf54-x8kz2 -c sidecar -f
+  kc logs deployment/nginx --tail=100 -n web
+  kc logs deploy/nginx --since=10m -f
+  kc logs pod/nginx-7c5ddbdf54-x8kz2 --previous`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runLogs(&logsOpts, args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to get logs")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runLogs resolves the target pods and prints or streams their logs.
+func runLogs(opts *clientOptions, target string) error {
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := logsContext(opts.timeout())
+	defer cancel()
+
+	ns := opts.namespaceOrDefault()
+
+	pods, err := resolveLogPods(ctx, client, ns, target)
+	if err != nil {
+		return err
+	}
+
+	logOptions := k8s.LogOptions{
+		Container: logsContainer,
+		TailLines: logsTail,
+		Since:     logsSince,
+		Follow:    logsFollow,
+		Previous:  logsPrevious,
+	}
+	return streamLogs(ctx, client, ns, pods, logOptions)
+}
+
+// logsContext returns a context that is cancelled on interrupt and, unless following, after the timeout.
+func logsContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if logsFollow {
+		return ctx, stop
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	return timeoutCtx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// resolveLogPods turns the target argument into the list of pods to read logs from.
+func resolveLogPods(ctx context.Context, client *k8s.Client, ns, target string) ([]string, error) {
+	if !strings.Contains(target, "/") {
+		return []string{target}, nil
+	}
+
+	info, name, err := parseResourceArgs([]string{target})
+	if err != nil {
+		return nil, err
+	}
+
+	switch info.GVR.Resource {
+	case "pods":
+		return []string{name}, nil
+	case "deployments":
+		pods, err := client.ListPodsForDeployment(ctx, ns, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(pods) == 0 {
+			return nil, fmt.Errorf("deployment %q has no pods", name)
+		}
+		return pods, nil
+	default:
+		return nil, fmt.Errorf("logs are not supported for resource type %q", info.GVR.Resource)
+	}
+}
+
+// streamLogs streams the logs of all pods concurrently.
+// With more than one pod, each line is prefixed with the pod name.
+func streamLogs(ctx context.Context, client *k8s.Client, ns string, pods []string, opts k8s.LogOptions) error {
+	if len(pods) == 1 {
+		return client.StreamPodLogs(ctx, ns, pods[0], opts, os.Stdout)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, len(pods))
+
+	for i, pod := range pods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := newPrefixWriter(os.Stdout, &mu, "[pod/"+pod+"] ")
+			errs[i] = client.StreamPodLogs(ctx, ns, pod, opts, w)
+			w.Flush()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefixWriter prefixes every complete line with a fixed string.
+// Writes to the shared output are serialized through a mutex so lines from
+// concurrent writers don't interleave.
+type prefixWriter struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+// newPrefixWriter creates a prefixWriter writing to out.
+func newPrefixWriter(out io.Writer, mu *sync.Mutex, prefix string) *prefixWriter {
+	return &prefixWriter{out: out, mu: mu, prefix: prefix}
+}
+
+// Write buffers p and emits every complete line with the prefix.
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadBytes('\n')
+		if err != nil {
+			// Incomplete line: keep it buffered until the rest arrives.
+			w.buf.Reset()
+			w.buf.Write(line)
+			return len(p), nil
+		}
+		if err := w.emit(line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush emits any buffered partial line.
+func (w *prefixWriter) Flush() {
+	if w.buf.Len() == 0 {
+		return
+	}
+	line := append(w.buf.Bytes(), '\n')
+	w.buf.Reset()
+	_ = w.emit(line)
+}
+
+// emit writes a single prefixed line to the shared output.
+func (w *prefixWriter) emit(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().StringVarP(&logsContainer, "container", "c", "",
+		"Container name (default: the only container of the pod)")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false,
+		"Follow the log stream")
+	logsCmd.Flags().Int64Var(&logsTail, "tail", -1,
+		"Number of recent lines to show (-1 for all)")
+	logsCmd.Flags().DurationVar(&logsSince, "since", 0,
+		"Only show logs newer than a relative duration like 5s, 2m or 3h")
+	logsCmd.Flags().BoolVarP(&logsPrevious, "previous", "p", false,
+		"Print the logs of the previous container instance")
+	logsCmd.Flags().StringVarP(&logsOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addClientFlags(logsCmd, &logsOpts, 30)
+}
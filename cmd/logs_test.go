@@ -0,0 +1,112 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the logs command definition, target resolution and line prefixing.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestLogsCommandDefined verifies that the logs command is registered with the expected flags.
+func TestLogsCommandDefined(t *testing.T) {
+	if logsCmd == nil {
+		t.Fatal("logsCmd should be defined")
+	}
+
+	flags := []struct {
+		name      string
+		shorthand string
+	}{
+		{"container", "c"},
+		{"follow", "f"},
+		{"tail", ""},
+		{"since", ""},
+		{"previous", "p"},
+		{"namespace", "n"},
+	}
+	for _, tt := range flags {
+		t.Run("flag_"+tt.name, func(t *testing.T) {
+			flag := logsCmd.Flags().Lookup(tt.name)
+			if flag == nil {
+				t.Fatalf("expected '%s' flag to be defined", tt.name)
+			}
+			if flag.Shorthand != tt.shorthand {
+				t.Errorf("expected shorthand '%s', got '%s'", tt.shorthand, flag.Shorthand)
+			}
+		})
+	}
+}
+
+// TestResolveLogPods verifies the different ways of addressing pods.
+func TestResolveLogPods(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	client := k8s.NewFakeClient(zerolog.Nop(),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: testNamespaceDefault, Labels: labels}},
+	)
+
+	tests := []struct {
+		name    string
+		target  string
+		want    []string
+		wantErr bool
+	}{
+		{"bare pod name", "web-1", []string{"web-1"}, false},
+		{"pod reference", "pod/web-1", []string{"web-1"}, false},
+		{"deployment reference", "deployment/web", []string{"web-1"}, false},
+		{"missing deployment", "deployment/missing", nil, true},
+		{"unsupported type", "svc/web", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			pods, err := resolveLogPods(ctx, client, testNamespaceDefault, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveLogPods(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if len(pods) != len(tt.want) || (len(pods) > 0 && pods[0] != tt.want[0]) {
+				t.Errorf("resolveLogPods(%q) = %v, want %v", tt.target, pods, tt.want)
+			}
+		})
+	}
+}
+
+// TestPrefixWriter verifies that complete lines are prefixed and partial lines are buffered.
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := newPrefixWriter(&out, &mu, "[p] ")
+
+	if _, err := w.Write([]byte("first\nsec")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if out.String() != "[p] first\n" {
+		t.Errorf("expected only the complete line to be written, got %q", out.String())
+	}
+
+	if _, err := w.Write([]byte("ond\nthird")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	w.Flush()
+
+	want := "[p] first\n[p] second\n[p] third\n"
+	if out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+}
@@ -0,0 +1,64 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the markdown output format, which prints tables as GitHub-flavored Markdown.
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// outputMarkdown is the output format printing tables as GitHub-flavored Markdown, e.g. for issues.
+const outputMarkdown = "markdown"
+
+// markdownTableWriter converts the tab-separated lines written for a tabwriter into the rows of a
+// Markdown table. The first line is the header, which is followed by the delimiter row.
+type markdownTableWriter struct {
+	out     io.Writer
+	pending []byte
+	rows    int
+}
+
+// Write converts the complete lines of p into table rows and keeps the rest for the next write.
+func (m *markdownTableWriter) Write(p []byte) (int, error) {
+	m.pending = append(m.pending, p...)
+	for {
+		end := bytes.IndexByte(m.pending, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		if err := m.writeRow(string(m.pending[:end])); err != nil {
+			return 0, err
+		}
+		m.pending = m.pending[end+1:]
+	}
+}
+
+// writeRow writes a line as a table row. Pipes within cells are escaped, since they would
+// otherwise end the cell.
+func (m *markdownTableWriter) writeRow(line string) error {
+	cells := strings.Split(line, "\t")
+	for i, cell := range cells {
+		cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+	row := "| " + strings.Join(cells, " | ") + " |\n"
+	if m.rows++; m.rows == 1 {
+		row += "|" + strings.Repeat(" --- |", len(cells)) + "\n"
+	}
+	if _, err := io.WriteString(m.out, row); err != nil {
+		return fmt.Errorf("failed to write markdown row: %w", err)
+	}
+	return nil
+}
+
+// newTableWriter returns the writer for the tab-separated lines of a table in the given format:
+// a Markdown table for markdown, aligned columns otherwise. flush must be called after the last line.
+func newTableWriter(out io.Writer, format string) (w io.Writer, flush func()) {
+	if format == outputMarkdown {
+		return &markdownTableWriter{out: out}, func() {}
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	return tw, func() { flushTableWriter(tw) }
+}
@@ -0,0 +1,69 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the markdown output format.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestMarkdownTableWriter verifies the conversion of tab-separated lines into Markdown rows.
+func TestMarkdownTableWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  string
+	}{
+		{"header only", []string{"NAME\tAGE\n"}, "| NAME | AGE |\n| --- | --- |\n"},
+		{"rows", []string{"NAME\tAGE\n", "web\t5d\n"}, "| NAME | AGE |\n| --- | --- |\n| web | 5d |\n"},
+		{"split writes", []string{"NA", "ME\n", "web", "\n"}, "| NAME |\n| --- |\n| web |\n"},
+		{"escaped pipe", []string{"EXPR\n", "a|b\n"}, "| EXPR |\n| --- |\n| a\\|b |\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := &markdownTableWriter{out: &out}
+			for _, chunk := range tt.input {
+				if _, err := w.Write([]byte(chunk)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestMarkdownDeploymentOutput verifies the Markdown table of deployments across contexts and
+// that markdown output requires a header.
+func TestMarkdownDeploymentOutput(t *testing.T) {
+	opts := &clientOptions{output: outputMarkdown}
+	if err := validateListParameters(opts); err != nil {
+		t.Fatalf("expected markdown output to be accepted, got %v", err)
+	}
+	opts.noHeaders = true
+	if err := validateListParameters(opts); err == nil {
+		t.Error("expected markdown output to be rejected with --no-headers")
+	}
+	opts.noHeaders = false
+
+	deployments := []k8s.ClusterDeploymentInfo{{
+		Context:        "prod",
+		DeploymentInfo: k8s.DeploymentInfo{Name: testDeploymentName, Namespace: testNamespaceDefault},
+	}}
+	var out bytes.Buffer
+	if err := formatClusterDeploymentOutput(&out, deployments, opts); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	wantRow := "| prod | default | " + testDeploymentName + " |"
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "| CONTEXT | NAMESPACE | NAME |") ||
+		!strings.HasPrefix(lines[1], "| --- | --- |") || !strings.HasPrefix(lines[2], wantRow) {
+		t.Errorf("unexpected markdown table:\n%s", out.String())
+	}
+}
@@ -0,0 +1,226 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'migrate-labels' command which relabels objects in bulk.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/migrate"
+)
+
+// Flags of the migrate-labels command.
+var (
+	// migrateFrom is the label being replaced.
+	migrateFrom string
+
+	// migrateTo is the replacement label.
+	migrateTo string
+
+	// migrateKinds lists the resources to relabel.
+	migrateKinds []string
+
+	// migrateRate limits how many objects are changed per interval.
+	migrateRate string
+
+	// migrateCheckpoint is the checkpoint file used to resume an interrupted migration.
+	migrateCheckpoint string
+
+	// migrateLabelsOpts are the namespace, connection and timeout flags of migrate-labels.
+	migrateLabelsOpts clientOptions
+)
+
+// migrateLabelsCmd represents the migrate-labels command.
+var migrateLabelsCmd = &cobra.Command{
+	Use:   "migrate-labels --from KEY=VALUE --to KEY=VALUE",
+	Short: "Replace a label on many objects with throttling",
+	Long: `Replace a label on all matching objects of the given kinds, cluster-wide or in one namespace.
+
+Objects are patched at most --rate per second, so large relabeling projects don't
+overload the API server. Progress is printed per object and a report is printed at the end.
+Only object labels are changed; pod templates and selectors are left alone.
+
+With --checkpoint, progress is saved after every object. Rerunning the same command
+resumes the migration: completed kinds are skipped and failed objects are retried.
+
+Examples:
+  kc migrate-labels --from team=old --to team=new --kinds=deployments,services --rate=10/s
+  kc migrate-labels --from team=payments --to owner=payments -n shop --dry-run
+  kc migrate-labels --from team=payments --to owner=payments --dry-run=server
+  kc migrate-labels --from team=old --to team=new --checkpoint=relabel.json --rate=300/m`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runMigrateLabels(os.Stdout, &migrateLabelsOpts); err != nil {
+			log.Error().Err(err).Msg("Label migration failed")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runMigrateLabels runs the label migration until it completes, fails or is interrupted.
+func runMigrateLabels(out io.Writer, opts *clientOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+	if migrateFrom == "" || migrateTo == "" {
+		return newUsageError("--from and --to are required")
+	}
+	change, err := migrate.ParseLabelChange(migrateFrom, migrateTo)
+	if err != nil {
+		return err
+	}
+	rate, err := migrate.ParseRate(migrateRate)
+	if err != nil {
+		return err
+	}
+	kinds, err := lookupResources(migrateKinds)
+	if err != nil {
+		return err
+	}
+	opts.applyDefaultNamespace()
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Server dry runs patch each object with dryRun=All, so they must not record progress.
+	checkpoint := migrateCheckpoint
+	if opts.dryRun.Enabled() {
+		checkpoint = ""
+	}
+	dryRun := opts.dryRun.Enabled()
+	report, err := migrate.MigrateLabels(ctx, client, change, migrate.Options{
+		Kinds:          kinds,
+		Namespace:      opts.namespace,
+		Rate:           rate,
+		CheckpointPath: checkpoint,
+		DryRun:         opts.dryRun == k8s.DryRunClient,
+		OnProgress:     func(p migrate.Progress) { writeMigrationProgress(os.Stderr, p, dryRun) },
+	})
+	if err != nil {
+		return err
+	}
+	if err := formatMigrationReport(out, report, opts.output); err != nil {
+		return err
+	}
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d objects could not be relabeled", len(report.Failed))
+	}
+	return nil
+}
+
+// writeMigrationProgress prints one processed object.
+func writeMigrationProgress(out io.Writer, p migrate.Progress, dryRun bool) {
+	status := "relabeled"
+	switch {
+	case p.Err != nil:
+		status = "failed: " + p.Err.Error()
+	case dryRun:
+		status = "would be relabeled"
+	}
+
+	object := p.Name
+	if p.Namespace != "" {
+		object = p.Namespace + "/" + p.Name
+	}
+	_, _ = fmt.Fprintf(out, "[%d/%d] %s %s %s\n", p.Done, p.Total, p.Kind, object, status)
+}
+
+// formatMigrationReport writes the final migration report in the given output format.
+func formatMigrationReport(out io.Writer, report migrate.Report, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "yaml":
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case "table":
+		return writeMigrationReport(out, report)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writeMigrationReport writes the migrated counts per kind followed by the failed objects.
+func writeMigrationReport(out io.Writer, report migrate.Report) error {
+	if _, err := fmt.Fprintf(out, "\nMigrated %s to %s: %d objects in %s\n\n", report.From, report.To,
+		report.Total(), formatAge(report.Finished.Sub(report.Started))); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	kinds := make([]string, 0, len(report.Migrated))
+	for kind := range report.Migrated {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "KIND\tMIGRATED"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, kind := range kinds {
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", kind, report.Migrated[kind]); err != nil {
+			return fmt.Errorf("failed to write report row: %w", err)
+		}
+	}
+	flushTableWriter(w)
+
+	if len(report.Failed) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(out, "\nFailed:"); err != nil {
+		return err
+	}
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+	for _, f := range report.Failed {
+		if _, err := fmt.Fprintf(w, "  %s\t%s/%s\t%s\n", f.Kind, f.Namespace, f.Name, f.Error); err != nil {
+			return fmt.Errorf("failed to write failure row: %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(migrateLabelsCmd)
+
+	migrateLabelsCmd.Flags().StringVar(&migrateFrom, "from", "",
+		"Label to replace, as key=value (required)")
+	migrateLabelsCmd.Flags().StringVar(&migrateTo, "to", "",
+		"Replacement label, as key=value; a different key renames the label (required)")
+	migrateLabelsCmd.Flags().StringSliceVar(&migrateKinds, "kinds", []string{"deployments", "services"},
+		"Resources to relabel")
+	migrateLabelsCmd.Flags().StringVar(&migrateRate, "rate", "10/s",
+		"Maximum objects changed per second (/s), minute (/m) or hour (/h)")
+	migrateLabelsCmd.Flags().StringVar(&migrateCheckpoint, "checkpoint", "",
+		"File to save progress to and resume from")
+	addNamespaceFlags(migrateLabelsCmd, &migrateLabelsOpts)
+	migrateLabelsCmd.Flags().StringVarP(&migrateLabelsOpts.output, "output", "o", "table",
+		"Output format of the final report (table|json|yaml)")
+
+	addClientFlags(migrateLabelsCmd, &migrateLabelsOpts, 30)
+	addDryRunFlag(migrateLabelsCmd, &migrateLabelsOpts)
+}
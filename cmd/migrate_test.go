@@ -0,0 +1,92 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the migrate-labels command output.
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Searge/k8s-controller/pkg/migrate"
+)
+
+// testMigrationReport returns a report with one failed object.
+func testMigrationReport() migrate.Report {
+	started := time.Now().Add(-2 * time.Minute)
+	return migrate.Report{
+		From:     "team=old",
+		To:       "team=new",
+		Migrated: map[string]int{"deployment.apps": 12, "service": 4},
+		Failed:   []migrate.Failure{{Kind: "service", Namespace: "shop", Name: "cart", Error: "denied"}},
+		Started:  started,
+		Finished: started.Add(2 * time.Minute),
+	}
+}
+
+// TestMigrateLabelsCommandDefined verifies that the command is registered with the expected flags.
+func TestMigrateLabelsCommandDefined(t *testing.T) {
+	for _, name := range []string{"from", "to", "kinds", "rate", "checkpoint", "dry-run", "namespace", "output"} {
+		if migrateLabelsCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestFormatMigrationReport verifies the output formats of the final report.
+func TestFormatMigrationReport(t *testing.T) {
+	tests := []struct {
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{"table", []string{"Migrated team=old to team=new: 16 objects in 2m", "deployment.apps  12",
+			"Failed:", "shop/cart"}, false},
+		{"json", []string{`"from": "team=old"`, `"deployment.apps": 12`}, false},
+		{"yaml", []string{"from: team=old"}, false},
+		{"xml", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			err := formatMigrationReport(&out, testMigrationReport(), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatMigrationReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestWriteMigrationProgress verifies the per-object progress lines.
+func TestWriteMigrationProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress migrate.Progress
+		dryRun   bool
+		want     string
+	}{
+		{"relabeled", migrate.Progress{Kind: "service", Namespace: "shop", Name: "web", Done: 1, Total: 3}, false,
+			"[1/3] service shop/web relabeled\n"},
+		{"dry run", migrate.Progress{Kind: "namespace", Name: "shop", Done: 2, Total: 2}, true,
+			"[2/2] namespace shop would be relabeled\n"},
+		{"failed", migrate.Progress{Kind: "service", Namespace: "shop", Name: "web", Done: 1, Total: 1,
+			Err: errors.New("denied")}, false, "[1/1] service shop/web failed: denied\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			writeMigrationProgress(&out, tt.progress, tt.dryRun)
+			if out.String() != tt.want {
+				t.Errorf("writeMigrationProgress() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the name output format, which prints resource references for scripting.
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// outputName is the output format printing one KIND.GROUP/NAME reference per line, e.g. for xargs.
+const outputName = "name"
+
+// deploymentNamePrefix prefixes the names of deployments in the name output, as in kubectl.
+const deploymentNamePrefix = "deployment.apps/"
+
+// writeDeploymentName writes the reference of a deployment, e.g. "deployment.apps/nginx", on its own line.
+func writeDeploymentName(out io.Writer, deployment k8s.DeploymentInfo) error {
+	if _, err := fmt.Fprintln(out, deploymentNamePrefix+deployment.Name); err != nil {
+		return fmt.Errorf("failed to write deployment name: %w", err)
+	}
+	return nil
+}
+
+// writeDeploymentNames writes the references of deployments, one per line. Nothing is written
+// if there are none, so the output can be piped without filtering.
+func writeDeploymentNames(out io.Writer, deployments []k8s.DeploymentInfo) error {
+	for _, deployment := range deployments {
+		if err := writeDeploymentName(out, deployment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the name output format.
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestWriteDeploymentNames verifies that deployments are printed as one reference per line.
+func TestWriteDeploymentNames(t *testing.T) {
+	if err := validateListParameters(&clientOptions{output: outputName}); err != nil {
+		t.Fatalf("expected name output to be accepted, got %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		deployments []k8s.DeploymentInfo
+		want        string
+	}{
+		{"none", nil, ""},
+		{"one", []k8s.DeploymentInfo{{Name: "nginx"}}, "deployment.apps/nginx\n"},
+		{"several", []k8s.DeploymentInfo{{Name: "api"}, {Name: "web"}}, "deployment.apps/api\ndeployment.apps/web\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeDeploymentNames(&out, tt.deployments); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("writeDeploymentNames() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'cordon', 'uncordon' and 'drain' node maintenance commands.
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags for the drain command
+var (
+	// drainGracePeriod overrides the termination grace period of evicted pods.
+	drainGracePeriod int
+
+	// drainIgnoreDaemonSets skips DaemonSet-managed pods.
+	drainIgnoreDaemonSets bool
+
+	// drainForce evicts pods not managed by a controller.
+	drainForce bool
+
+	// cordonOpts, uncordonOpts and drainOpts are the connection and timeout flags of the node commands.
+	cordonOpts, uncordonOpts, drainOpts clientOptions
+)
+
+// cordonCmd represents the cordon command.
+var cordonCmd = &cobra.Command{
+	Use:   "cordon NODE",
+	Short: "Mark a node as unschedulable",
+	Long: `Mark a node as unschedulable, so no new pods are scheduled onto it.
+Pods already running on the node are not affected.
+
+Examples:
+  kc cordon worker-1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runSetSchedulable(&cordonOpts, args[0], false); err != nil {
+			log.Error().Err(err).Msg("Failed to cordon node")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// uncordonCmd represents the uncordon command.
+var uncordonCmd = &cobra.Command{
+	Use:   "uncordon NODE",
+	Short: "Mark a node as schedulable",
+	Long: `Mark a node as schedulable again after maintenance.
+
+Examples:
+  kc uncordon worker-1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runSetSchedulable(&uncordonOpts, args[0], true); err != nil {
+			log.Error().Err(err).Msg("Failed to uncordon node")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// drainCmd represents the drain command.
+var drainCmd = &cobra.Command{
+	Use:   "drain NODE",
+	Short: "Cordon a node and evict its pods",
+	Long: `Cordon a node and evict all its pods in preparation for maintenance.
+
+Pods are evicted through the eviction API, so PodDisruptionBudgets are respected:
+evictions a budget disallows are retried until --timeout expires. Mirror pods
+are skipped. The drain refuses to start if the node runs DaemonSet-managed pods
+(skip them with --ignore-daemonsets) or pods not managed by a controller, which
+would not be recreated elsewhere (evict them anyway with --force).
+
+Examples:
+  kc drain worker-1 --ignore-daemonsets
+  kc drain worker-1 --ignore-daemonsets --grace-period=30 --timeout=600`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runDrain(&drainOpts, args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to drain node")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runSetSchedulable cordons or uncordons a node.
+func runSetSchedulable(opts *clientOptions, node string, schedulable bool) error {
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	operation, action := client.CordonNode, "cordoned"
+	if schedulable {
+		operation, action = client.UncordonNode, "uncordoned"
+	}
+
+	changed, err := operation(ctx, node)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		action = "already " + action
+	}
+	fmt.Printf("node/%s %s%s\n", node, action, opts.dryRunSuffix())
+	return nil
+}
+
+// runDrain drains a node, printing each pod once it is evicted.
+func runDrain(opts *clientOptions, node string) error {
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	stop := startProgress(fmt.Sprintf("Draining node/%s", node))
+	result, err := client.DrainNode(ctx, node, k8s.DrainOptions{
+		GracePeriodSeconds: drainGracePeriod,
+		IgnoreDaemonSets:   drainIgnoreDaemonSets,
+		Force:              drainForce,
+		OnEvicted: func(pod *corev1.Pod) {
+			// Erase the spinner, which redraws itself below the line.
+			progressOutput.clear()
+			fmt.Printf("pod/%s evicted (namespace %s)%s\n", pod.Name, pod.Namespace, opts.dryRunSuffix())
+		},
+	})
+	stop()
+	if err != nil {
+		return err
+	}
+
+	for _, skipped := range result.Skipped {
+		fmt.Printf("skipped %s\n", skipped)
+	}
+	fmt.Printf("node/%s drained%s\n", node, opts.dryRunSuffix())
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(cordonCmd, uncordonCmd, drainCmd)
+
+	drainCmd.Flags().IntVar(&drainGracePeriod, "grace-period", -1,
+		"Seconds given to each pod to terminate gracefully (negative: use the pod's own value)")
+	drainCmd.Flags().BoolVar(&drainIgnoreDaemonSets, "ignore-daemonsets", false,
+		"Skip DaemonSet-managed pods")
+	drainCmd.Flags().BoolVar(&drainForce, "force", false,
+		"Also evict pods not managed by a controller")
+
+	addClientFlags(cordonCmd, &cordonOpts, 30)
+	addClientFlags(uncordonCmd, &uncordonOpts, 30)
+	addClientFlags(drainCmd, &drainOpts, 300)
+	addDryRunFlag(cordonCmd, &cordonOpts)
+	addDryRunFlag(uncordonCmd, &uncordonOpts)
+	addDryRunFlag(drainCmd, &drainOpts)
+}
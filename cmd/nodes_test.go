@@ -0,0 +1,39 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the node maintenance command definitions.
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestNodeCommandsDefined verifies that cordon, uncordon and drain are registered with the expected flags.
+func TestNodeCommandsDefined(t *testing.T) {
+	tests := []struct {
+		cmd   *cobra.Command
+		flags []string
+	}{
+		{cordonCmd, []string{"kubeconfig", "context", "timeout"}},
+		{uncordonCmd, []string{"kubeconfig", "context", "timeout"}},
+		{drainCmd, []string{"grace-period", "ignore-daemonsets", "force", "timeout"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmd.Name(), func(t *testing.T) {
+			found, _, err := rootCmd.Find([]string{tt.cmd.Name()})
+			if err != nil || found != tt.cmd {
+				t.Fatalf("expected %s to be registered, got %v", tt.cmd.Name(), err)
+			}
+			for _, name := range tt.flags {
+				if tt.cmd.Flags().Lookup(name) == nil {
+					t.Errorf("expected '%s' flag to be defined", name)
+				}
+			}
+		})
+	}
+
+	if got := drainCmd.Flags().Lookup("grace-period").DefValue; got != "-1" {
+		t.Errorf("expected grace-period default -1, got %s", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'patch' command which updates fields of a resource using a patch.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags for the patch command
+var (
+	// patchTypeName selects the patch strategy: strategic, merge or json.
+	patchTypeName string
+
+	// patchContent holds an inline patch document.
+	patchContent string
+
+	// patchFile points to a file containing the patch document.
+	patchFile string
+
+	// patchOpts are the namespace, connection and timeout flags of the patch command.
+	patchOpts clientOptions
+)
+
+// patchCmd represents the patch command.
+// It applies a strategic merge, JSON merge or JSON patch to a single resource.
+var patchCmd = &cobra.Command{
+	Use:   "patch (TYPE/NAME | TYPE NAME)",
+	Short: "Update fields of a resource using a patch",
+	Long: `Update fields of a Kubernetes resource using a strategic merge patch,
+a JSON merge patch, or a JSON patch.
+
+The patch can be passed inline with --patch or read from a file with --patch-file.
+Both JSON and YAML patch documents are accepted.
+
+Examples:
+  kc patch deployment/nginx -n default -p '{"spec":{"replicas":3}}'
+  kc patch deployment nginx --type=merge -p '{"metadata":{"labels":{"tier":"web"}}}'
+  kc patch deploy/nginx --type=json -p '[{"op":"replace","path":"/spec/replicas","value":2}]'
+  kc patch node/worker-1 --patch-file=unschedulable.yaml`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeResourceArgs(&patchOpts),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runPatch(&patchOpts, args); err != nil {
+			log.Error().Err(err).Msg("Failed to patch resource")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runPatch executes the patch logic for the given positional arguments.
+func runPatch(opts *clientOptions, args []string) error {
+	info, name, err := parseResourceArgs(args)
+	if err != nil {
+		return err
+	}
+
+	patchType, err := k8s.ParsePatchType(patchTypeName)
+	if err != nil {
+		return err
+	}
+
+	data, err := readPatchData(patchContent, patchFile)
+	if err != nil {
+		return err
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	ns := resolveNamespace(info, opts.namespace)
+	if _, err := client.Patch(ctx, info.GVR, ns, name, patchType, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s/%s patched%s\n", info.QualifiedName(), name, opts.dryRunSuffix())
+	return nil
+}
+
+// readPatchData returns the patch document from the inline value or the patch file.
+// Exactly one of them must be provided. YAML documents are converted to JSON.
+func readPatchData(inline, file string) ([]byte, error) {
+	if (inline == "") == (file == "") {
+		return nil, fmt.Errorf("exactly one of --patch or --patch-file must be specified")
+	}
+
+	data := []byte(inline)
+	if file != "" {
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("failed to read patch file: %w", err)
+		}
+	}
+
+	jsonData, err := sigsyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, newUsageError("invalid patch document: %w", err)
+	}
+	return jsonData, nil
+}
+
+func init() {
+	rootCmd.AddCommand(patchCmd)
+
+	patchCmd.Flags().StringVar(&patchTypeName, "type", k8s.PatchTypeStrategic,
+		"Patch type (strategic|merge|json)")
+
+	patchCmd.Flags().StringVarP(&patchContent, "patch", "p", "",
+		"Inline patch document (JSON or YAML)")
+
+	patchCmd.Flags().StringVar(&patchFile, "patch-file", "",
+		"File containing the patch document (JSON or YAML)")
+
+	patchCmd.Flags().StringVarP(&patchOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addClientFlags(patchCmd, &patchOpts, 30)
+	addDryRunFlag(patchCmd, &patchOpts)
+}
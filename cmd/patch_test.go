@@ -0,0 +1,87 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the patch command definition, argument parsing and patch loading.
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPatchCommandDefined verifies that the patch command is registered with the expected flags.
+func TestPatchCommandDefined(t *testing.T) {
+	if patchCmd == nil {
+		t.Fatal("patchCmd should be defined")
+	}
+
+	for _, flagName := range []string{"type", "patch", "patch-file", "namespace", "kubeconfig", "context", "timeout"} {
+		t.Run("flag_"+flagName, func(t *testing.T) {
+			if patchCmd.Flags().Lookup(flagName) == nil {
+				t.Errorf("expected '%s' flag to be defined", flagName)
+			}
+		})
+	}
+}
+
+// TestParseResourceArgs verifies resource argument parsing in both accepted forms.
+func TestParseResourceArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantResource string
+		wantName     string
+		wantErr      bool
+	}{
+		{"slash form", []string{"deployment/nginx"}, "deployments", "nginx", false},
+		{"two args", []string{"svc", "web"}, "services", "web", false},
+		{"unknown type", []string{"widget/x"}, "", "", true},
+		{"bad reference", []string{"nginx"}, "", "", true},
+		{"too many args", []string{"a", "b", "c"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, name, err := parseResourceArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseResourceArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if info.GVR.Resource != tt.wantResource || name != tt.wantName {
+				t.Errorf("parseResourceArgs(%v) = %s, %s", tt.args, info.GVR.Resource, name)
+			}
+		})
+	}
+}
+
+// TestReadPatchData verifies inline and file-based patch loading, including YAML conversion.
+func TestReadPatchData(t *testing.T) {
+	patchPath := filepath.Join(t.TempDir(), "patch.yaml")
+	if err := os.WriteFile(patchPath, []byte("spec:\n  replicas: 2\n"), 0o600); err != nil {
+		t.Fatalf("failed to write patch file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		inline  string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"inline json", `{"spec":{"replicas":3}}`, "", `{"spec":{"replicas":3}}`, false},
+		{"yaml file", "", patchPath, `{"spec":{"replicas":2}}`, false},
+		{"neither", "", "", "", true},
+		{"both", `{}`, patchPath, "", true},
+		{"missing file", "", "/nonexistent/patch.yaml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := readPatchData(tt.inline, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readPatchData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(data) != tt.want {
+				t.Errorf("readPatchData() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements plugins, the kc-<name> executables on PATH run as 'kc <name>', and the 'plugin list' command.
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// pluginPrefix is the prefix of plugin executables, e.g. kc-foo for 'kc foo'.
+const pluginPrefix = "kc-"
+
+// pluginInfo is a plugin executable found on PATH.
+type pluginInfo struct {
+	Name string
+	Path string
+	// ShadowedBy is the plugin of the same name earlier on PATH, which is the one run.
+	ShadowedBy string
+}
+
+// pluginCmd represents the plugin command.
+// It serves as a parent command for inspecting plugins.
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Inspect plugins",
+	Long: `Inspect plugins, the executables named kc-<name> on PATH.
+
+A command that kc does not know is run as a plugin: 'kc foo bar --baz' runs
+kc-foo-bar with --baz if it exists, otherwise kc-foo with bar --baz. Dashes in
+command names are written as underscores in the executable name, so
+'kc foo-bar' runs kc-foo_bar.
+
+Plugins get KC_KUBECONFIG and KC_NAMESPACE set to the kubeconfig and namespace
+kc would use.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// pluginListCmd represents the plugin list command.
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins on PATH",
+	Long: `List the plugins on PATH. Plugins shadowed by a plugin of the same name
+earlier on PATH are listed with the plugin that is run instead.
+
+Examples:
+  kc plugin list`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runPluginList(os.Stdout, os.Getenv("PATH")); err != nil {
+			log.Error().Err(err).Msg("Failed to list plugins")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runPluginList lists the plugins found on the given PATH.
+func runPluginList(out io.Writer, pathList string) error {
+	plugins := findPlugins(pathList)
+	if len(plugins) == 0 {
+		_, err := fmt.Fprintln(out, "No plugins found.")
+		return err
+	}
+
+	w, flush := newTableWriter(out, "")
+	defer flush()
+	if _, err := fmt.Fprintln(w, "NAME\tPATH\tSHADOWED BY"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, plugin := range plugins {
+		shadowedBy := plugin.ShadowedBy
+		if shadowedBy == "" {
+			shadowedBy = "<none>"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", plugin.Name, plugin.Path, shadowedBy); err != nil {
+			return fmt.Errorf("failed to write plugin row: %w", err)
+		}
+	}
+	return nil
+}
+
+// findPlugins returns the plugins in the directories of pathList in PATH order. Directories that
+// cannot be read are skipped, like the shell does.
+func findPlugins(pathList string) []pluginInfo {
+	var plugins []pluginInfo
+	found := map[string]string{}
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			plugins = append(plugins, pluginInfo{Name: name, Path: path, ShadowedBy: found[name]})
+			if found[name] == "" {
+				found[name] = path
+			}
+		}
+	}
+	return plugins
+}
+
+// pluginName returns the plugin name of an executable file name, e.g. foo for kc-foo or kc-foo.exe.
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	name := strings.TrimPrefix(file, pluginPrefix)
+	return name, name != file && name != ""
+}
+
+// isExecutable reports whether path is a file that can be executed.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// lookupPlugin returns the plugin run for the command-line arguments and the arguments passed to it.
+// The longest command name matching a plugin wins, so 'foo bar' prefers kc-foo-bar over kc-foo.
+func lookupPlugin(args []string) (path string, pluginArgs []string, ok bool) {
+	var parts []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		parts = append(parts, strings.ReplaceAll(arg, "-", "_"))
+	}
+	for n := len(parts); n > 0; n-- {
+		path, err := exec.LookPath(pluginPrefix + strings.Join(parts[:n], "-"))
+		if err == nil {
+			return path, args[n:], true
+		}
+	}
+	return "", nil, false
+}
+
+// pluginEnv returns the environment of plugins: the environment of kc with KC_KUBECONFIG and
+// KC_NAMESPACE set to the kubeconfig and namespace kc would use.
+func pluginEnv() []string {
+	kubeconfig := k8s.KubeconfigPath(os.Getenv(envName("kubeconfig")))
+	namespace := os.Getenv(envName("namespace"))
+	if namespace == "" {
+		namespace, _ = k8s.ContextNamespace(kubeconfig, os.Getenv(envName("context")))
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	return append(os.Environ(), envName("kubeconfig")+"="+kubeconfig, envName("namespace")+"="+namespace)
+}
+
+// runPlugin runs the plugin with the arguments and the terminal of kc and returns its exit code.
+func runPlugin(path string, args []string) int {
+	plugin := exec.Command(path, args...)
+	plugin.Stdin, plugin.Stdout, plugin.Stderr = os.Stdin, os.Stdout, os.Stderr
+	plugin.Env = pluginEnv()
+	err := plugin.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		log.Error().Err(err).Str("plugin", path).Msg("Failed to run plugin")
+		return 1
+	}
+	return 0
+}
+
+// isBuiltinCommand reports whether the command-line arguments start with a command of kc,
+// including the help and completion commands cobra adds.
+func isBuiltinCommand(args []string) bool {
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	cmd, _, err := rootCmd.Find(args)
+	return err == nil && cmd != rootCmd
+}
+
+// handlePlugin runs the plugin for the command-line arguments if they do not name a command of kc.
+// It reports whether a plugin was run and its exit code.
+func handlePlugin(args []string) (code int, handled bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(args) {
+		return 0, false
+	}
+	path, pluginArgs, ok := lookupPlugin(args)
+	if !ok {
+		return 0, false
+	}
+	return runPlugin(path, pluginArgs), true
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
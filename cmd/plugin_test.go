@@ -0,0 +1,200 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the plugins and the plugin list command.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writePlugin writes an executable script of the given file name into dir.
+func writePlugin(t *testing.T, dir, file string) string {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestFindPlugins verifies that plugins are found in PATH order and that later plugins of the
+// same name are marked as shadowed.
+func TestFindPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	foo := writePlugin(t, first, "kc-foo")
+	shadowed := writePlugin(t, second, "kc-foo")
+	bar := writePlugin(t, second, "kc-bar")
+	writePlugin(t, second, "kubectl-foo")
+	if err := os.WriteFile(filepath.Join(second, "kc-data"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := findPlugins(strings.Join([]string{first, "", filepath.Join(first, "missing"), second},
+		string(os.PathListSeparator)))
+	want := []pluginInfo{
+		{Name: "foo", Path: foo},
+		{Name: "bar", Path: bar},
+		{Name: "foo", Path: shadowed, ShadowedBy: foo},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findPlugins() = %+v, want %+v", got, want)
+	}
+}
+
+// TestLookupPlugin verifies that the longest command name matching a plugin wins.
+func TestLookupPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	foo := writePlugin(t, dir, "kc-foo")
+	fooBar := writePlugin(t, dir, "kc-foo-bar")
+	dashed := writePlugin(t, dir, "kc-foo_baz")
+	t.Setenv("PATH", dir)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantPath string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{"single", []string{"foo", "--all"}, foo, []string{"--all"}, true},
+		{"nested", []string{"foo", "bar", "baz"}, fooBar, []string{"baz"}, true},
+		{"arguments", []string{"foo", "qux", "bar"}, foo, []string{"qux", "bar"}, true},
+		{"flag ends name", []string{"foo", "--x", "bar"}, foo, []string{"--x", "bar"}, true},
+		{"dashes", []string{"foo-baz"}, dashed, []string{}, true},
+		{"unknown", []string{"qux"}, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, args, ok := lookupPlugin(tt.args)
+			if ok != tt.wantOK || path != tt.wantPath || !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("lookupPlugin(%v) = %q, %v, %v, want %q, %v, %v",
+					tt.args, path, args, ok, tt.wantPath, tt.wantArgs, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestHandlePlugin verifies that plugins run only for unknown commands and pass on their exit code.
+func TestHandlePlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	for _, file := range []string{"kc-version", "kc-fail"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte("#!/bin/sh\nexit 3\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantCode    int
+		wantHandled bool
+	}{
+		{"plugin", []string{"fail", "--now"}, 3, true},
+		{"builtin wins", []string{"version"}, 0, false},
+		{"unknown", []string{"missing"}, 0, false},
+		{"flag first", []string{"--log-level", "debug", "fail"}, 0, false},
+		{"no arguments", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, handled := handlePlugin(tt.args)
+			if code != tt.wantCode || handled != tt.wantHandled {
+				t.Errorf("handlePlugin(%v) = %d, %v, want %d, %v", tt.args, code, handled, tt.wantCode, tt.wantHandled)
+			}
+		})
+	}
+}
+
+// TestPluginEnv verifies that plugins get the kubeconfig and namespace kc would use.
+func TestPluginEnv(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	config := `apiVersion: v1
+kind: Config
+current-context: dev
+contexts:
+- name: dev
+  context: {cluster: dev, user: dev, namespace: team}
+- name: prod
+  context: {cluster: dev, user: dev}
+clusters:
+- name: dev
+  cluster: {server: "https://127.0.0.1:6443"}
+users:
+- name: dev
+  user: {}
+`
+	if err := os.WriteFile(kubeconfig, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	tests := []struct {
+		name          string
+		namespace     string
+		context       string
+		wantNamespace string
+	}{
+		{"context namespace", "", "", "team"},
+		{"namespace set", "web", "", "web"},
+		{"context without namespace", "", "prod", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KC_KUBECONFIG", "")
+			t.Setenv("KC_NAMESPACE", tt.namespace)
+			t.Setenv("KC_CONTEXT", tt.context)
+
+			env := pluginEnv()
+			if !slices.Contains(env, "KC_KUBECONFIG="+kubeconfig) {
+				t.Errorf("expected KC_KUBECONFIG=%s in the environment", kubeconfig)
+			}
+			if last := env[len(env)-1]; last != "KC_NAMESPACE="+tt.wantNamespace {
+				t.Errorf("expected KC_NAMESPACE=%s, got %s", tt.wantNamespace, last)
+			}
+		})
+	}
+}
+
+// TestRunPluginList verifies the table of plugins.
+func TestRunPluginList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	writePlugin(t, dir, "kc-foo")
+
+	var buf bytes.Buffer
+	if err := runPluginList(&buf, dir); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "NAME") || !strings.Contains(buf.String(), filepath.Join(dir, "kc-foo")) ||
+		!strings.Contains(buf.String(), "<none>") {
+		t.Errorf("expected the plugin in the table, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := runPluginList(&buf, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "No plugins found.\n" {
+		t.Errorf("expected no plugins, got %q", buf.String())
+	}
+}
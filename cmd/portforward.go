@@ -0,0 +1,127 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'port-forward' command which forwards local ports to a pod.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+var (
+	// portForwardAddresses holds the local addresses to listen on.
+	portForwardAddresses []string
+
+	// portForwardOpts are the namespace, connection and timeout flags of the port-forward command.
+	portForwardOpts clientOptions
+)
+
+// portForwardCmd represents the port-forward command.
+// It forwards local ports to a pod, or to a running pod backing a deployment or service.
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward (POD | pod/NAME | deployment/NAME | service/NAME) [LOCAL:]REMOTE...",
+	Short: "Forward local ports to a pod",
+	Long: `Forward one or more local ports to a pod.
+
+When a deployment or service is given, a running pod selected by it is used.
+For services, the remote port is a service port and is mapped to the matching
+target port of the pod.
+
+Forwarding runs until interrupted with Ctrl+C.
+
+Examples:
+  kc port-forward nginx-7c5ddbdf54-x8kz2 8080:80
+  kc port-forward pod/nginx-7c5ddbdf54-x8kz2 8080:80 8443:443
+  kc port-forward deployment/nginx 8080:80 -n web
+  kc port-forward svc/nginx :80 --address 0.0.0.0`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runPortForward(&portForwardOpts, args[0], args[1:]); err != nil {
+			log.Error().Err(err).Msg("Failed to forward ports")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runPortForward resolves the target pod and forwards the ports until interrupted.
+func runPortForward(opts *clientOptions, target string, ports []string) error {
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ns := opts.namespaceOrDefault()
+
+	resolveCtx, cancel := context.WithTimeout(ctx, opts.timeout())
+	pod, ports, err := resolvePortForwardTarget(resolveCtx, client, ns, target, ports)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	return client.PortForward(ctx, ns, pod, ports, k8s.PortForwardOptions{
+		Addresses: portForwardAddresses,
+		Out:       os.Stdout,
+		ErrOut:    os.Stderr,
+	})
+}
+
+// resolvePortForwardTarget turns the target argument into a pod name and the ports to forward to it.
+func resolvePortForwardTarget(
+	ctx context.Context, client *k8s.Client, ns, target string, ports []string,
+) (string, []string, error) {
+	if !strings.Contains(target, "/") {
+		return target, ports, nil
+	}
+
+	info, name, err := parseResourceArgs([]string{target})
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch info.GVR.Resource {
+	case "pods":
+		return name, ports, nil
+	case "deployments":
+		pod, err := client.RunningPodForDeployment(ctx, ns, name)
+		if err != nil {
+			return "", nil, err
+		}
+		return pod.Name, ports, nil
+	case "services":
+		service, pod, err := client.RunningPodForService(ctx, ns, name)
+		if err != nil {
+			return "", nil, err
+		}
+		translated, err := k8s.TranslateServicePorts(service, pod, ports)
+		if err != nil {
+			return "", nil, err
+		}
+		return pod.Name, translated, nil
+	default:
+		return "", nil, fmt.Errorf("port forwarding is not supported for resource type %q", info.GVR.Resource)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(portForwardCmd)
+
+	portForwardCmd.Flags().StringSliceVar(&portForwardAddresses, "address", []string{"localhost"},
+		"Local addresses to listen on (comma-separated)")
+	portForwardCmd.Flags().StringVarP(&portForwardOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addClientFlags(portForwardCmd, &portForwardOpts, 30)
+}
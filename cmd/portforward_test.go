@@ -0,0 +1,84 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the port-forward command definition and target resolution.
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestPortForwardCommandDefined verifies that the port-forward command is registered with the expected flags.
+func TestPortForwardCommandDefined(t *testing.T) {
+	if portForwardCmd == nil {
+		t.Fatal("portForwardCmd should be defined")
+	}
+
+	for _, name := range []string{"address", "namespace", "kubeconfig", "context", "timeout"} {
+		if portForwardCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestResolvePortForwardTarget verifies the different ways of addressing the target pod.
+func TestResolvePortForwardTarget(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	client := k8s.NewFakeClient(zerolog.Nop(),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault},
+			Spec: corev1.ServiceSpec{
+				Selector: labels,
+				Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080)}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: testNamespaceDefault, Labels: labels},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	tests := []struct {
+		name      string
+		target    string
+		wantPod   string
+		wantPorts string
+		wantErr   bool
+	}{
+		{"bare pod name", "web-1", "web-1", "8000:80", false},
+		{"pod reference", "pod/web-1", "web-1", "8000:80", false},
+		{"deployment reference", "deploy/web", "web-1", "8000:80", false},
+		{"service reference", "svc/web", "web-1", "8000:8080", false},
+		{"missing service", "svc/missing", "", "", true},
+		{"unsupported type", "cm/web", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			ports := []string{"8000:80"}
+			pod, ports, err := resolvePortForwardTarget(ctx, client, testNamespaceDefault, tt.target, ports)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolvePortForwardTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if pod != tt.wantPod || strings.Join(ports, ",") != tt.wantPorts {
+				t.Errorf("resolvePortForwardTarget(%q) = %q, %v", tt.target, pod, ports)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Package cmd contains shared helpers for CLI commands.
+// This file implements the safety preflight shared by restart and scale-down commands.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// preflightForce lets restart and scale-down commands proceed despite blocking preflight findings.
+var preflightForce bool
+
+// runPreflight runs the deployment preflight for an operation and prints its findings.
+// It returns an error if a finding blocks the operation and force is not set.
+func runPreflight(ctx context.Context, out io.Writer, client *k8s.Client, ns, name, operation string,
+	target int32, force bool) error {
+	stop := startProgress(fmt.Sprintf("Running the preflight for deployment %s/%s", ns, name))
+	report, err := client.PreflightDeployment(ctx, ns, name, operation, target)
+	stop()
+	if err != nil {
+		return err
+	}
+	if err := writePreflightReport(out, report); err != nil {
+		return err
+	}
+
+	if report.Safe() {
+		return nil
+	}
+	if force {
+		_, err := fmt.Fprintln(out, "Proceeding despite blockers (--force).")
+		return err
+	}
+	return fmt.Errorf("refusing unsafe %s of deployment %s/%s, pass --force to proceed anyway", operation, ns, name)
+}
+
+// writePreflightReport writes the findings of a preflight as a short report.
+func writePreflightReport(out io.Writer, report k8s.PreflightReport) error {
+	header := fmt.Sprintf("Preflight for %s of deployment %s/%s", report.Operation, report.Namespace, report.Name)
+	if report.Operation == k8s.OperationScale {
+		header += fmt.Sprintf(" (%d -> %d replicas)", report.Current, report.Target)
+	}
+	if _, err := fmt.Fprintln(out, header+":"); err != nil {
+		return fmt.Errorf("failed to write preflight report: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+	for _, f := range report.Findings {
+		if _, err := fmt.Fprintf(w, "  %s\t%s\t%s\n", strings.ToUpper(f.Severity), f.Check, f.Message); err != nil {
+			return fmt.Errorf("failed to write preflight finding: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the preflight shared by restart and scale-down commands.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// newDegradedDeployment builds a deployment with 3 desired and 1 available replica.
+func newDegradedDeployment() *appsv1.Deployment {
+	replicas := int32(3)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}
+}
+
+// TestRunPreflight verifies that blocking findings refuse the operation unless forced.
+func TestRunPreflight(t *testing.T) {
+	tests := []struct {
+		name     string
+		force    bool
+		wantErr  bool
+		contains []string
+	}{
+		{"refused", false, true, []string{"Preflight for restart of deployment default/web:", "BLOCKER",
+			"only 1/3 replicas are available"}},
+		{"forced", true, false, []string{"Proceeding despite blockers"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8s.NewFakeClient(zerolog.Nop(), newDegradedDeployment())
+			var out bytes.Buffer
+			err := runPreflight(context.Background(), &out, client, testNamespaceDefault, "web",
+				k8s.OperationRestart, 0, tt.force)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runPreflight() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestWritePreflightReportScale verifies that scale reports show the replica change.
+func TestWritePreflightReportScale(t *testing.T) {
+	var out bytes.Buffer
+	report := k8s.PreflightReport{Operation: k8s.OperationScale, Namespace: "shop", Name: "cart", Current: 3,
+		Target: 1, Findings: []k8s.PreflightFinding{{Check: "hpa", Severity: k8s.SeverityOK, Message: "none"}}}
+	if err := writePreflightReport(&out, report); err != nil {
+		t.Fatalf("writePreflightReport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "(3 -> 1 replicas)") || !strings.Contains(out.String(), "OK  hpa") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+}
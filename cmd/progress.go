@@ -0,0 +1,137 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the spinner shown on stderr while long-running operations are in progress.
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Timing of the spinner. Operations finishing within progressDelay show no spinner at all,
+// so quick commands don't flicker.
+const (
+	progressDelay    = 500 * time.Millisecond
+	progressInterval = 100 * time.Millisecond
+)
+
+// clearLine moves the cursor to the start of the line and erases it.
+const clearLine = "\r\x1b[K"
+
+// progressFrames are the frames of the spinner animation.
+var progressFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+var (
+	// noProgress disables the spinner even on a terminal.
+	noProgress bool
+
+	// progressEnabled shows the spinner. It is set when a command starts, see progressEnabledFor.
+	progressEnabled bool
+
+	// progressOutput is stderr, shared by the spinner and the logs, see progressWriter.
+	progressOutput = &progressWriter{out: os.Stderr}
+)
+
+// progressWriter writes to stderr on behalf of both the spinner and the logger. Writes of the
+// logger erase the spinner line first, so log lines are not garbled; the spinner redraws itself
+// below them on its next frame.
+type progressWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+
+	// drawn reports whether the spinner line is currently on the screen.
+	drawn bool
+}
+
+// Write erases the spinner line, if drawn, and writes p.
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.erase()
+	return w.out.Write(p)
+}
+
+// draw replaces the spinner line with line.
+func (w *progressWriter) draw(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = fmt.Fprint(w.out, clearLine+line)
+	w.drawn = true
+}
+
+// clear erases the spinner line, if drawn.
+func (w *progressWriter) clear() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.erase()
+}
+
+// erase erases the spinner line, if drawn. The caller must hold the lock.
+func (w *progressWriter) erase() {
+	if w.drawn {
+		_, _ = fmt.Fprint(w.out, clearLine)
+		w.drawn = false
+	}
+}
+
+// progressEnabledFor reports whether the spinner is shown on f: f must be a terminal
+// and --no-progress must not be set.
+func progressEnabledFor(f *os.File) bool {
+	return !noProgress && term.IsTerminal(int(f.Fd()))
+}
+
+// startProgress shows a spinner with the message and the elapsed time on stderr until the returned
+// function is called, which erases it again. Output to stdout must wait until then, as stdout is
+// usually the same terminal. Without a terminal, or with --no-progress, nothing is shown.
+func startProgress(message string) (stop func()) {
+	if !progressEnabled {
+		return func() {}
+	}
+
+	done, finished := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		delay := time.NewTimer(progressDelay)
+		defer delay.Stop()
+		select {
+		case <-done:
+			return
+		case <-delay.C:
+		}
+
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		started := time.Now().Add(-progressDelay)
+		for frame := 0; ; frame++ {
+			progressOutput.draw(progressLine(message, frame, time.Since(started)))
+			select {
+			case <-done:
+				progressOutput.clear()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// progressLine formats a frame of the spinner, e.g. "⠙ Waiting for deployment/web (12s)".
+func progressLine(message string, frame int, elapsed time.Duration) string {
+	return fmt.Sprintf("%s %s (%s)", progressFrames[frame%len(progressFrames)], message, elapsed.Truncate(time.Second))
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false,
+		"Disable the progress spinner of long-running operations (also disabled when stderr is not a terminal)")
+}
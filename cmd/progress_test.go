@@ -0,0 +1,102 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the progress spinner.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProgressLine verifies the formatting of spinner frames.
+func TestProgressLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   int
+		elapsed time.Duration
+		want    string
+	}{
+		{"first frame", 0, 500 * time.Millisecond, "⠋ Listing deployments (0s)"},
+		{"later frame", 11, 12*time.Second + 300*time.Millisecond, "⠙ Listing deployments (12s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := progressLine("Listing deployments", tt.frame, tt.elapsed); got != tt.want {
+				t.Errorf("progressLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProgressWriter verifies that writes erase a drawn spinner line first.
+func TestProgressWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &progressWriter{out: &buf}
+
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	w.draw("⠋ Waiting")
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	w.clear()
+
+	want := "first\n" + clearLine + "⠋ Waiting" + clearLine + "second\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+// TestStartProgress verifies that the spinner is only drawn when enabled and for slow operations,
+// and that it is erased when stopped.
+func TestStartProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		duration time.Duration
+		wantDraw bool
+	}{
+		{"disabled", false, progressDelay + 3*progressInterval, false},
+		{"quick operation", true, 0, false},
+		{"slow operation", true, progressDelay + 3*progressInterval, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			saved, savedOutput := progressEnabled, progressOutput
+			progressEnabled, progressOutput = tt.enabled, &progressWriter{out: &buf}
+			t.Cleanup(func() { progressEnabled, progressOutput = saved, savedOutput })
+
+			stop := startProgress("Waiting for deployment/web")
+			time.Sleep(tt.duration)
+			stop()
+			stop()
+
+			got := buf.String()
+			if drawn := strings.Contains(got, "Waiting for deployment/web"); drawn != tt.wantDraw {
+				t.Errorf("expected spinner drawn = %v, got %q", tt.wantDraw, got)
+			}
+			if tt.wantDraw && !strings.HasSuffix(got, clearLine) {
+				t.Errorf("expected the spinner erased after stop, got %q", got)
+			}
+		})
+	}
+}
+
+// TestProgressEnabledFor verifies that the spinner is disabled when stderr is not a terminal.
+func TestProgressEnabledFor(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if progressEnabledFor(f) {
+		t.Error("expected the spinner disabled for a regular file")
+	}
+}
@@ -0,0 +1,217 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'report' command which prints cluster reports.
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/reports"
+)
+
+// Flags for the report garbage command
+var (
+	// garbagePodAge is the minimum age of finished pods to report.
+	garbagePodAge time.Duration
+
+	// garbageClean deletes the reported objects after confirmation.
+	garbageClean bool
+
+	// garbageYes skips the confirmation prompt of --clean.
+	garbageYes bool
+
+	// reportGarbageOpts are the namespace, connection and timeout flags of report garbage.
+	reportGarbageOpts clientOptions
+)
+
+// reportCmd represents the report command.
+// It serves as a parent command for cluster reports.
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Print cluster reports",
+	Long: `Print reports about the state of the cluster.
+
+Examples:
+  kc report garbage`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// reportGarbageCmd represents the report garbage command.
+var reportGarbageCmd = &cobra.Command{
+	Use:   "garbage",
+	Short: "List leftover objects that can be cleaned up",
+	Long: `List objects that are most likely left over and safe to clean up:
+
+  - inactive ReplicaSets beyond their deployment's revisionHistoryLimit,
+    whose deployment no longer exists, or that are unowned and scaled to zero
+  - succeeded and failed pods that finished longer than --older-than ago
+  - ConfigMaps not referenced by any pod or deployment pod template
+
+With --clean the listed objects are deleted after an interactive confirmation,
+which --yes skips.
+
+Examples:
+  kc report garbage
+  kc report garbage -n shop --older-than=2h -o json
+  kc report garbage --clean`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runReportGarbage(os.Stdin, os.Stdout, &reportGarbageOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to report garbage")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runReportGarbage finds garbage objects, prints them and optionally deletes them.
+func runReportGarbage(in io.Reader, out io.Writer, opts *clientOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+	opts.applyDefaultNamespace()
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	items, err := reports.FindGarbage(ctx, client.GetClientset(), reports.GarbageOptions{
+		Namespace:      opts.namespace,
+		FinishedPodAge: garbagePodAge,
+	}, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := formatGarbageOutput(out, items, opts.output); err != nil {
+		return err
+	}
+
+	if !garbageClean || len(items) == 0 {
+		return nil
+	}
+	if !garbageYes && !confirm(in, out, fmt.Sprintf("Delete %d objects?", len(items))) {
+		_, err := fmt.Fprintln(out, "Aborted, nothing deleted.")
+		return err
+	}
+	return cleanGarbage(ctx, client, out, items, opts.dryRunSuffix())
+}
+
+// cleanGarbage deletes the garbage objects, continuing past individual failures.
+// The suffix marks the output of dry runs, see clientOptions.dryRunSuffix.
+func cleanGarbage(ctx context.Context, client *k8s.Client, out io.Writer, items []reports.Garbage,
+	suffix string) error {
+	failed := 0
+	for _, item := range items {
+		info, err := k8s.LookupResource(item.Kind)
+		if err == nil {
+			err = client.Delete(ctx, info.GVR, item.Namespace, item.Name)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("kind", item.Kind).Str("name", item.Name).Msg("Failed to delete garbage")
+			failed++
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "%s/%s deleted%s\n", strings.ToLower(item.Kind), item.Name,
+			suffix); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d objects", failed, len(items))
+	}
+	return nil
+}
+
+// confirm asks a yes/no question and reports whether the answer was yes.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	if _, err := fmt.Fprintf(out, "%s [y/N]: ", question); err != nil {
+		return false
+	}
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// formatGarbageOutput writes garbage objects in the given output format.
+func formatGarbageOutput(out io.Writer, items []reports.Garbage, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case "yaml":
+		data, err := yaml.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case "table":
+		if len(items) == 0 {
+			_, err := fmt.Fprintln(out, "No garbage found.")
+			return err
+		}
+		return writeGarbageTable(out, items)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writeGarbageTable writes garbage objects as an aligned table.
+func writeGarbageTable(out io.Writer, items []reports.Garbage) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tAGE\tREASON"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Kind, item.Namespace, item.Name,
+			formatAge(now.Sub(item.Created)), item.Reason); err != nil {
+			return fmt.Errorf("failed to write garbage row: %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportGarbageCmd)
+
+	addNamespaceFlags(reportGarbageCmd, &reportGarbageOpts)
+	reportGarbageCmd.Flags().DurationVar(&garbagePodAge, "older-than", reports.DefaultFinishedPodAge,
+		"Minimum time since a succeeded or failed pod finished")
+	reportGarbageCmd.Flags().BoolVar(&garbageClean, "clean", false,
+		"Delete the reported objects after confirmation")
+	reportGarbageCmd.Flags().BoolVarP(&garbageYes, "yes", "y", false,
+		"Skip the confirmation prompt of --clean")
+	reportGarbageCmd.Flags().StringVarP(&reportGarbageOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+
+	addClientFlags(reportGarbageCmd, &reportGarbageOpts, 60)
+	addDryRunFlag(reportGarbageCmd, &reportGarbageOpts)
+}
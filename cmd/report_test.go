@@ -0,0 +1,123 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the report garbage command, its output and the clean action.
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/reports"
+)
+
+// testGarbage returns an unused ConfigMap and a finished pod as garbage.
+func testGarbage() []reports.Garbage {
+	created := time.Now().Add(-3 * time.Hour)
+	return []reports.Garbage{
+		{Kind: "ConfigMap", Namespace: testNamespaceDefault, Name: "unused",
+			Reason: "not referenced by any pod spec", Created: created},
+		{Kind: "Pod", Namespace: testNamespaceDefault, Name: "job-1", Reason: "succeeded", Created: created},
+	}
+}
+
+// TestReportGarbageCommandDefined verifies that report garbage is registered with the expected flags.
+func TestReportGarbageCommandDefined(t *testing.T) {
+	cmd, _, err := reportCmd.Find([]string{"garbage"})
+	if err != nil || cmd != reportGarbageCmd {
+		t.Fatalf("expected report garbage subcommand, got %v", err)
+	}
+
+	for _, name := range []string{"namespace", "older-than", "clean", "yes", "output", "timeout"} {
+		if reportGarbageCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestConfirm verifies parsing of confirmation answers.
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.input), func(t *testing.T) {
+			var out bytes.Buffer
+			if got := confirm(strings.NewReader(tt.input), &out, "Delete?"); got != tt.want {
+				t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if !strings.Contains(out.String(), "Delete? [y/N]") {
+				t.Errorf("expected prompt, got %q", out.String())
+			}
+		})
+	}
+}
+
+// TestFormatGarbageOutput verifies all output formats of the garbage report.
+func TestFormatGarbageOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []reports.Garbage
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{"table", testGarbage(), "table", []string{"KIND", "ConfigMap", "unused", "3h", "not referenced"}, false},
+		{"empty table", nil, "table", []string{"No garbage found."}, false},
+		{"json", testGarbage(), "json", []string{`"kind": "Pod"`}, false},
+		{"yaml", testGarbage(), "yaml", []string{"name: job-1"}, false},
+		{"unsupported", testGarbage(), "xml", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := formatGarbageOutput(&out, tt.items, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatGarbageOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestCleanGarbage verifies that garbage is deleted and that failures are counted.
+func TestCleanGarbage(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "unused", Namespace: testNamespaceDefault},
+	})
+
+	var out bytes.Buffer
+	err := cleanGarbage(context.Background(), client, &out, testGarbage(), "")
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("expected one failed deletion for the missing pod, got %v", err)
+	}
+	if !strings.Contains(out.String(), "configmap/unused deleted") {
+		t.Errorf("expected deletion message, got %q", out.String())
+	}
+
+	configMaps, _ := k8s.LookupResource("configmaps")
+	_, getErr := client.GetDynamicClient().Resource(configMaps.GVR).Namespace(testNamespaceDefault).
+		Get(context.Background(), "unused", metav1.GetOptions{})
+	if getErr == nil {
+		t.Error("expected ConfigMap to be deleted")
+	}
+}
@@ -0,0 +1,98 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'rollout restart' command which restarts the pods of a deployment.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// rolloutRestartOpts are the namespace, connection and timeout flags of rollout restart.
+var rolloutRestartOpts clientOptions
+
+// rolloutCmd represents the rollout command.
+// It serves as a parent command for managing rollouts.
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Manage the rollout of a workload",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// rolloutRestartCmd represents the rollout restart command.
+var rolloutRestartCmd = &cobra.Command{
+	Use:   "restart deployment NAME",
+	Short: "Restart the pods of a deployment",
+	Long: `Restart the pods of a deployment with a rolling update.
+
+A preflight runs first, which checks current availability, the rollout strategy,
+PodDisruptionBudgets and HorizontalPodAutoscalers. Unsafe restarts are refused
+unless --force is passed.
+
+Examples:
+  kc rollout restart deployment nginx
+  kc rollout restart deploy/nginx -n web
+  kc rollout restart deploy nginx --force`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeResourceArgs(&rolloutRestartOpts),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runRolloutRestart(os.Stdout, &rolloutRestartOpts, args); err != nil {
+			log.Error().Err(err).Msg("Failed to restart deployment")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runRolloutRestart runs the preflight and restarts a deployment.
+func runRolloutRestart(out io.Writer, opts *clientOptions, args []string) error {
+	info, name, err := parseResourceArgs(args)
+	if err != nil {
+		return err
+	}
+	if info.Kind != "Deployment" {
+		return fmt.Errorf("restarting %s is not supported, only deployments can be restarted", info.GVR.Resource)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	ns := resolveNamespace(info, opts.namespace)
+	if err := runPreflight(ctx, out, client, ns, name, k8s.OperationRestart, 0, preflightForce); err != nil {
+		return err
+	}
+	stop := startProgress(fmt.Sprintf("Restarting %s/%s", info.QualifiedName(), name))
+	err = client.RestartDeployment(ctx, ns, name)
+	stop()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s/%s restarted%s\n", info.QualifiedName(), name, opts.dryRunSuffix())
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(rolloutCmd)
+	rolloutCmd.AddCommand(rolloutRestartCmd)
+
+	rolloutRestartCmd.Flags().BoolVar(&preflightForce, "force", false,
+		"Restart even if the preflight finds it unsafe")
+	rolloutRestartCmd.Flags().StringVarP(&rolloutRestartOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addClientFlags(rolloutRestartCmd, &rolloutRestartOpts, 30)
+	addDryRunFlag(rolloutRestartCmd, &rolloutRestartOpts)
+}
@@ -0,0 +1,21 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the rollout restart command.
+package cmd
+
+import "testing"
+
+// TestRolloutRestartCommandDefined verifies that the rollout restart command is registered with the expected flags.
+func TestRolloutRestartCommandDefined(t *testing.T) {
+	for _, name := range []string{"force", "namespace", "timeout"} {
+		if rolloutRestartCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestRunRolloutRestartUnsupportedKind verifies that only deployments can be restarted.
+func TestRunRolloutRestartUnsupportedKind(t *testing.T) {
+	if err := runRolloutRestart(nil, newTestClientOptions(""), []string{"service/web"}); err == nil {
+		t.Error("expected an error for a service")
+	}
+}
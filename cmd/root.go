@@ -3,13 +3,24 @@
 package cmd
 
 import (
+	"os"
+	"strings"
+	"time"
+
 	"github.com/Searge/k8s-controller/pkg/logger"
+	"github.com/Searge/k8s-controller/pkg/redact"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
 var logLevel string
 
+// quiet logs only errors, whatever the log level, so that command output stands alone.
+var quiet bool
+
+// currentCommand is the running command, e.g. "list deployments", sent in the user agent.
+var currentCommand string
+
 // rootCmd represents the base command when called without any subcommands.
 // It serves as the entry point for the CLI application and handles global configuration
 // such as logging setup that applies to all subcommands.
@@ -25,13 +36,31 @@ Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	PersistentPreRun: func(cmd *cobra.Command, _ []string) {
-		// Skip logging for version command - it should be clean output
-		if cmd.Use == "version" {
+		currentCommand = commandName(cmd)
+		configErr := applyEnvironment(cmd)
+		if configErr == nil {
+			configErr = applyConfigFile(cmd)
+		}
+		startTelemetry(cmd)
+		colorOutput = colorEnabled(os.Stdout)
+		progressEnabled = progressEnabledFor(os.Stderr)
+
+		// Skip logging for version command - it should be clean output, unless it connects to the server
+		if cmd == versionCmd && !versionServer {
 			return
 		}
 
-		// Initialize logger with the specified log level
-		logger.Init(logLevel)
+		// Initialize logger with the specified log level, sharing stderr with the progress spinner
+		logger.SetOutput(progressOutput)
+		logger.Init(effectiveLogLevel())
+		if configErr != nil {
+			log.Error().Err(configErr).Msg("Failed to load configuration")
+			exit(exitUsage)
+		}
+		if err := setupRedaction(); err != nil {
+			log.Error().Err(err).Msg("Failed to set up redaction")
+			exit(exitCode(err))
+		}
 		log.Info().Str("version", Version).Msg("Starting k8s-controller")
 	},
 	Run: func(cmd *cobra.Command, _ []string) {
@@ -40,13 +69,37 @@ to quickly create a Cobra application.`,
 	},
 }
 
+// effectiveLogLevel returns the log level of the logger: error with --quiet, --log-level otherwise.
+func effectiveLogLevel() string {
+	if quiet {
+		return "error"
+	}
+	return logLevel
+}
+
+// commandName returns the path of a command without the program name, e.g. "list deployments".
+func commandName(cmd *cobra.Command) string {
+	return strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-// If the command execution fails, the application will exit with status code 1.
+// If the command line is invalid, the application will exit with status code 2, see exitCode.
+// Commands that fail inside Run exit via exit(), which records their telemetry first.
+// Aliases from the configuration file are expanded first. Unknown commands then run the
+// matching kc-<name> plugin, whose exit code becomes that of kc.
 func Execute() {
+	args := expandAlias(os.Args[1:])
+	if code, ok := handlePlugin(args); ok {
+		os.Exit(code)
+	}
+	registerCompletions()
+	rootCmd.SetArgs(args)
 	err := rootCmd.Execute()
+	finishTelemetry(err == nil)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to execute command")
+		log.Error().Err(err).Msg("Failed to execute command")
+		os.Exit(exitUsage)
 	}
 }
 
@@ -54,6 +107,27 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
 		"Log level (debug, info, warn, error, fatal, panic)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false,
+		"Log only errors, e.g. when piping the output of a command to other tools; overrides --log-level")
+	rootCmd.PersistentFlags().BoolVar(&demoMode, "demo", false,
+		"Use a seeded in-memory fake cluster instead of a real Kubernetes API server")
+	rootCmd.PersistentFlags().StringVar(&demoFixture, "demo-fixture", "",
+		"YAML/JSON file with objects to seed the demo cluster (default: built-in demo objects)")
+	rootCmd.PersistentFlags().BoolVar(&contextNamespace, "context-namespace", false,
+		"Default to the namespace of the kubeconfig context when -n is not given, like kubectl; "+
+			"off by default, spanning all namespaces as before")
+	rootCmd.PersistentFlags().StringVar(&authzWebhookURL, "authz-webhook", "",
+		"URL of an HTTP authorization hook consulted before mutating operations")
+	rootCmd.PersistentFlags().DurationVar(&authzTimeout, "authz-timeout", 5*time.Second,
+		"Timeout for authorization hook requests")
+	rootCmd.PersistentFlags().BoolVar(&telemetryEnabled, "telemetry", false,
+		"Record anonymized command timings and failures locally (see 'telemetry stats')")
+	rootCmd.PersistentFlags().StringVar(&telemetryEndpoint, "telemetry-endpoint", "",
+		"URL receiving anonymized command timings and failures as JSON POSTs (opt-in)")
+	rootCmd.PersistentFlags().BoolVar(&noRedact, "no-redact", false,
+		"Show sensitive values (passwords, tokens, keys) instead of masking them, for authorized use")
+	rootCmd.PersistentFlags().StringSliceVar(&redactPatterns, "redact-patterns", redact.DefaultPatterns,
+		"Case-insensitive regular expressions of keys whose values are masked in output and logs")
 
 	// Version flags - using SetVersionTemplate for proper Cobra integration
 	rootCmd.Version = Version
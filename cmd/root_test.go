@@ -83,3 +83,49 @@ func TestLogLevelFlag(t *testing.T) {
 		})
 	}
 }
+
+// TestEffectiveLogLevel verifies that --quiet limits logging to errors, whatever the log level.
+func TestEffectiveLogLevel(t *testing.T) {
+	defer func() { logLevel, quiet = "info", false }()
+
+	tests := []struct {
+		level string
+		quiet bool
+		want  string
+	}{
+		{"debug", false, "debug"},
+		{"debug", true, "error"},
+		{"info", true, "error"},
+	}
+
+	for _, tt := range tests {
+		logLevel, quiet = tt.level, tt.quiet
+		if got := effectiveLogLevel(); got != tt.want {
+			t.Errorf("effectiveLogLevel() with level %s and quiet %v = %s, want %s", tt.level, tt.quiet, got, tt.want)
+		}
+	}
+}
+
+// TestCommandName verifies that command names omit the program name, as sent in the user agent.
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "deployments"}, "list deployments"},
+		{[]string{"version"}, "version"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			cmd, _, err := rootCmd.Find(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := commandName(cmd); got != tt.want {
+				t.Errorf("expected command name %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'run' command which executes a declarative pipeline file.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/pipeline"
+)
+
+// Flags of the run command.
+var (
+	// pipelineFile is the pipeline file to run.
+	pipelineFile string
+
+	// pipelineStepTimeout bounds each attempt of steps without their own timeout.
+	pipelineStepTimeout time.Duration
+
+	// runOpts are the namespace and connection flags of the run command.
+	runOpts clientOptions
+)
+
+// runCmd represents the run command.
+var runCmd = &cobra.Command{
+	Use:   "run -f PIPELINE",
+	Short: "Run a declarative pipeline of cluster operations",
+	Long: `Run the steps of a pipeline file in order.
+
+Each step performs one action:
+  apply    Server-side apply a manifest file, a directory or an inline manifest
+  wait     Wait for an object to meet a condition, as 'kc wait' does
+  scale    Set the replica count of a deployment; scale-downs run the preflight
+  restart  Restart the pods of a deployment, as 'kc rollout restart' does
+  verify   Check once that an object meets a condition
+  notify   POST a templated message to a webhook
+
+Steps run only while no earlier step has failed, unless they set 'when' to
+on-failure or always. Failed attempts are retried 'retries' times. If the
+pipeline fails, its rollback steps run afterwards. Exits with status 1 if the
+pipeline failed.
+
+With --dry-run, apply, scale and restart steps only show what they would change,
+and wait, verify and notify steps are skipped, since the changes they would wait
+for, check or announce are not made.
+
+Example pipeline:
+  name: release-web
+  namespace: web
+  steps:
+    - name: apply
+      apply: {file: manifests/}
+    - name: rollout
+      wait: {resource: deployment/web, for: condition=Available}
+      timeout: 2m
+      retries: 1
+    - name: announce
+      when: always
+      notify:
+        url: https://hooks.example.com/deploys
+        message: "{{.Pipeline}} {{.Status}}"
+  rollback:
+    - name: scale down
+      scale: {deployment: web, replicas: 0, force: true}
+
+Examples:
+  kc run -f release.yaml
+  kc run -f release.yaml -n staging -o json
+  kc run -f release.yaml --dry-run=server`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		succeeded, err := runPipeline(os.Stdout, &runOpts)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to run pipeline")
+			exit(exitCode(err))
+		}
+		if !succeeded {
+			exit(exitFailure)
+		}
+	},
+}
+
+// runPipeline loads and runs the pipeline at --filename and reports whether it succeeded.
+func runPipeline(out io.Writer, opts *clientOptions) (bool, error) {
+	if pipelineFile == "" {
+		return false, newUsageError("--filename is required")
+	}
+	if err := validateOutputFormat(opts.output); err != nil {
+		return false, err
+	}
+	p, err := pipeline.Load(pipelineFile)
+	if err != nil {
+		return false, err
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return false, err
+	}
+	defer closeClient(client)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runner := pipeline.NewRunner(client, pipeline.Options{
+		Namespace:   opts.namespace,
+		StepTimeout: pipelineStepTimeout,
+		DryRun:      opts.dryRun,
+	}, log.Logger)
+	stopProgress := startProgress(fmt.Sprintf("Running pipeline %s", p.Name))
+	report := runner.Run(ctx, p)
+	stopProgress()
+	return report.Succeeded, formatPipelineReport(out, report, opts)
+}
+
+// formatPipelineReport prints the pipeline report in the output format of opts.
+func formatPipelineReport(out io.Writer, report pipeline.Report, opts *clientOptions) error {
+	switch opts.output {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(report)
+	case "table":
+		return writePipelineReport(out, report, opts.dryRunSuffix())
+	default:
+		return newUsageError("unsupported output format: %s", opts.output)
+	}
+}
+
+// writePipelineReport prints the step and rollback results as a table followed by the overall outcome,
+// which ends in the suffix of the dry run mode.
+func writePipelineReport(out io.Writer, report pipeline.Report, dryRunSuffix string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "STEP\tACTION\tRESULT\tATTEMPTS\tDURATION\tERROR"); err != nil {
+		return fmt.Errorf("failed to write report header: %w", err)
+	}
+	for _, step := range report.Steps {
+		if err := writePipelineStep(w, "", step); err != nil {
+			return err
+		}
+	}
+	for _, step := range report.Rollback {
+		if err := writePipelineStep(w, "rollback: ", step); err != nil {
+			return err
+		}
+	}
+	flushTableWriter(w)
+
+	outcome := "succeeded"
+	switch {
+	case !report.Succeeded && len(report.Rollback) > 0:
+		outcome = "failed and was rolled back"
+	case !report.Succeeded:
+		outcome = "failed"
+	}
+	_, err := fmt.Fprintf(out, "\nPipeline %s %s%s\n", report.Pipeline, outcome, dryRunSuffix)
+	return err
+}
+
+// writePipelineStep writes one step result as a table row.
+func writePipelineStep(w io.Writer, prefix string, step pipeline.StepResult) error {
+	status := step.Status
+	if step.Ignored {
+		status += " (ignored)"
+	}
+	attempts := "-"
+	if step.Attempts > 0 {
+		attempts = fmt.Sprint(step.Attempts)
+	}
+	if _, err := fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n", prefix, step.Name, step.Action, status, attempts,
+		step.Duration.Round(time.Millisecond), step.Error); err != nil {
+		return fmt.Errorf("failed to write report row: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVarP(&pipelineFile, "filename", "f", "",
+		"Pipeline file to run")
+	runCmd.Flags().StringVarP(&runOpts.namespace, "namespace", "n", "",
+		"Namespace for steps without one, overriding the pipeline's namespace")
+	runCmd.Flags().StringVarP(&runOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+	runCmd.Flags().DurationVar(&pipelineStepTimeout, "step-timeout", pipeline.DefaultStepTimeout,
+		"Timeout of each step attempt, unless the step sets its own")
+	addConnectionFlags(runCmd, &runOpts)
+	addDryRunFlag(runCmd, &runOpts)
+}
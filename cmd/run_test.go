@@ -0,0 +1,78 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the run command.
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Searge/k8s-controller/pkg/pipeline"
+)
+
+// TestRunCommandDefined verifies that the run command is registered with the expected flags.
+func TestRunCommandDefined(t *testing.T) {
+	for _, name := range []string{"filename", "namespace", "output", "step-timeout", "kubeconfig", "context"} {
+		if runCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestRunPipelineValidation verifies that invalid flags and pipeline files fail before connecting.
+func TestRunPipelineValidation(t *testing.T) {
+	defer func() { pipelineFile = "" }()
+
+	tests := []struct {
+		name   string
+		file   string
+		format string
+	}{
+		{"missing filename", "", "table"},
+		{"invalid format", "pipeline.yaml", "xml"},
+		{"missing file", filepath.Join(t.TempDir(), "absent.yaml"), "table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineFile = tt.file
+			opts := newTestClientOptions("")
+			opts.output = tt.format
+			if _, err := runPipeline(nil, opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// TestWritePipelineReport verifies the step table and the outcome line.
+func TestWritePipelineReport(t *testing.T) {
+	report := pipeline.Report{
+		Pipeline: "release",
+		Steps: []pipeline.StepResult{
+			{Name: "apply", Action: "apply", Status: pipeline.StatusSucceeded, Attempts: 1, Duration: time.Second},
+			{Name: "rollout", Action: "wait", Status: pipeline.StatusFailed, Attempts: 2, Error: "timed out"},
+			{Name: "scale", Action: "scale", Status: pipeline.StatusSkipped},
+		},
+		Rollback: []pipeline.StepResult{{Name: "undo", Action: "scale", Status: pipeline.StatusSucceeded, Attempts: 1}},
+	}
+
+	var out bytes.Buffer
+	if err := writePipelineReport(&out, report, " (dry run)"); err != nil {
+		t.Fatalf("writePipelineReport() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"apply           apply   succeeded  1         1s",
+		"rollout         wait    failed     2         0s        timed out",
+		"scale           scale   skipped    -",
+		"rollback: undo  scale   succeeded  1",
+		"Pipeline release failed and was rolled back (dry run)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'scale' command which changes the replica count of a deployment.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+var (
+	// scaleReplicas is the desired replica count set by the scale command.
+	scaleReplicas int32
+
+	// scaleDeploymentOpts are the namespace, connection and timeout flags of scale deployment.
+	scaleDeploymentOpts clientOptions
+)
+
+// scaleCmd represents the scale command.
+// It serves as a parent command for scaling workloads.
+var scaleCmd = &cobra.Command{
+	Use:   "scale",
+	Short: "Change the replica count of a workload",
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// scaleDeploymentCmd represents the scale deployment command.
+var scaleDeploymentCmd = &cobra.Command{
+	Use:     "deployment NAME --replicas=N",
+	Aliases: []string{"deploy", "deployments"},
+	Short:   "Change the replica count of a deployment",
+	Long: `Change the replica count of a deployment.
+
+Scaling down runs a preflight first, which checks current availability,
+PodDisruptionBudgets and HorizontalPodAutoscalers. Unsafe scale-downs are
+refused unless --force is passed.
+
+Examples:
+  kc scale deployment nginx --replicas=5
+  kc scale deploy nginx -n web --replicas=1
+  kc scale deploy nginx --replicas=0 --force
+  kc scale deploy nginx --replicas=2 --dry-run=server`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstArg(&scaleDeploymentOpts, "deployments"),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runScaleDeployment(os.Stdout, &scaleDeploymentOpts, args[0]); err != nil {
+			log.Error().Err(err).Msg("Failed to scale deployment")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runScaleDeployment scales a deployment, running the preflight first when scaling down.
+func runScaleDeployment(out io.Writer, opts *clientOptions, name string) error {
+	if scaleReplicas < 0 {
+		return newUsageError("--replicas must not be negative, got %d", scaleReplicas)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	ns := opts.namespaceOrDefault()
+
+	current, err := client.GetDeployment(ctx, ns, name)
+	if err != nil {
+		return err
+	}
+	if scaleReplicas < current.Replicas.Desired {
+		if err := runPreflight(ctx, out, client, ns, name, k8s.OperationScale, scaleReplicas,
+			preflightForce); err != nil {
+			return err
+		}
+	}
+
+	if err := client.ScaleDeployment(ctx, ns, name, scaleReplicas); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "deployment.apps/%s scaled to %d%s\n", name, scaleReplicas, opts.dryRunSuffix())
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(scaleCmd)
+	scaleCmd.AddCommand(scaleDeploymentCmd)
+
+	scaleDeploymentCmd.Flags().Int32Var(&scaleReplicas, "replicas", 0,
+		"Desired number of replicas")
+	_ = scaleDeploymentCmd.MarkFlagRequired("replicas")
+	scaleDeploymentCmd.Flags().BoolVar(&preflightForce, "force", false,
+		"Scale down even if the preflight finds it unsafe")
+	scaleDeploymentCmd.Flags().StringVarP(&scaleDeploymentOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addClientFlags(scaleDeploymentCmd, &scaleDeploymentOpts, 30)
+	addDryRunFlag(scaleDeploymentCmd, &scaleDeploymentOpts)
+}
@@ -0,0 +1,52 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the scale command definition.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestScaleCommandDefined verifies that the scale deployment command is registered with the expected flags.
+func TestScaleCommandDefined(t *testing.T) {
+	for _, name := range []string{"replicas", "force", "namespace", "timeout", "dry-run"} {
+		if scaleDeploymentCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestRunScaleDeploymentNegative verifies that negative replica counts are rejected before connecting.
+func TestRunScaleDeploymentNegative(t *testing.T) {
+	defer func() { scaleReplicas = 0 }()
+	scaleReplicas = -1
+	if err := runScaleDeployment(nil, newTestClientOptions(""), "nginx"); err == nil {
+		t.Error("expected an error for negative replicas")
+	}
+}
+
+// TestRunScaleDeploymentDryRun verifies that dry runs of a scale-up report the simulated change.
+func TestRunScaleDeploymentDryRun(t *testing.T) {
+	demoMode = true
+	defer func() { demoMode, scaleReplicas = false, 0 }()
+	scaleReplicas = 5
+
+	for _, mode := range []k8s.DryRun{k8s.DryRunClient, k8s.DryRunServer} {
+		t.Run(string(mode), func(t *testing.T) {
+			opts := newTestClientOptions("")
+			opts.dryRun = mode
+
+			var out bytes.Buffer
+			if err := runScaleDeployment(&out, opts, "hello"); err != nil {
+				t.Fatal(err)
+			}
+			want := "deployment.apps/hello scaled to 5" + opts.dryRunSuffix()
+			if strings.TrimSpace(out.String()) != want {
+				t.Errorf("expected %q, got %q", want, out.String())
+			}
+		})
+	}
+}
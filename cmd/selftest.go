@@ -0,0 +1,130 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'selftest' command which runs an end-to-end acceptance test against a cluster.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/selftest"
+)
+
+// Flags for the selftest command
+var (
+	// selftestNamespace is the temporary namespace created for the self-test.
+	selftestNamespace string
+
+	// selftestImage is the container image of the test workload.
+	selftestImage string
+
+	// selftestOpts are the connection and timeout flags of the selftest command.
+	selftestOpts clientOptions
+)
+
+// selftestCmd represents the selftest command.
+// It verifies the list, watch, scale and delete paths of this tool against a live cluster.
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run an end-to-end smoke test against the cluster",
+	Long: `Run a built-in acceptance test of this tool against a live cluster.
+
+The self-test will:
+  - Create a temporary namespace (it must not already exist)
+  - Deploy a tiny test workload
+  - Verify the list, watch, scale and delete code paths against it
+  - Delete the temporary namespace, even if a step failed
+
+A pass/fail report is printed and the command exits non-zero on failure.
+Dry runs are rejected, since the self-test checks the cluster by changing it.
+
+Examples:
+  kc selftest
+  kc selftest -n kc-selftest
+  kc selftest --context=staging --timeout=60`,
+	Run: func(_ *cobra.Command, _ []string) {
+		passed, err := runSelftest(&selftestOpts)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to run self-test")
+			exit(exitCode(err))
+		}
+		if !passed {
+			exit(exitFailure)
+		}
+	},
+}
+
+// runSelftest executes the self-test and prints its report.
+func runSelftest(opts *clientOptions) (bool, error) {
+	if opts.dryRun != "" && opts.dryRun != k8s.DryRunNone {
+		return false, newUsageError("--dry-run is not supported: the self-test creates, scales and deletes objects")
+	}
+	if err := validateNamespace(selftestNamespace); err != nil {
+		return false, fmt.Errorf("invalid namespace: %w", err)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return false, err
+	}
+	defer closeClient(client)
+
+	runner := selftest.NewRunner(client, selftest.Options{
+		Namespace:   selftestNamespace,
+		Image:       selftestImage,
+		StepTimeout: opts.timeout(),
+	}, log.Logger)
+
+	report := runner.Run(context.Background())
+	if err := printSelftestReport(report); err != nil {
+		return false, err
+	}
+	return report.Passed(), nil
+}
+
+// printSelftestReport prints the step results as a table followed by the overall verdict.
+func printSelftestReport(report selftest.Report) error {
+	w := createTableWriter()
+
+	if _, err := fmt.Fprintln(w, "STEP\tRESULT\tDURATION\tERROR"); err != nil {
+		return fmt.Errorf("failed to write report header: %w", err)
+	}
+	for _, step := range report.Steps {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			step.Name, passFail(step.Passed), step.Duration.Round(time.Millisecond), step.Error); err != nil {
+			return fmt.Errorf("failed to write report row: %w", err)
+		}
+	}
+	flushTableWriter(w)
+
+	fmt.Printf("\nSelf-test %s in namespace %s\n", passFail(report.Passed()), report.Namespace)
+	return nil
+}
+
+// passFail renders a boolean outcome as PASS or FAIL.
+func passFail(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+
+	selftestCmd.Flags().StringVarP(&selftestNamespace, "namespace", "n", selftest.DefaultNamespace,
+		"Temporary namespace to create for the self-test (must not exist)")
+
+	selftestCmd.Flags().StringVar(&selftestImage, "image", selftest.DefaultImage,
+		"Container image used for the test workload")
+
+	addClientFlags(selftestCmd, &selftestOpts, 30)
+
+	// --dry-run is only registered to reject it with the reason, rather than as an unknown flag.
+	addDryRunFlag(selftestCmd, &selftestOpts)
+	selftestCmd.Flags().Lookup("dry-run").Hidden = true
+}
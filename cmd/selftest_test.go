@@ -0,0 +1,55 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the selftest command definition and report rendering.
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/selftest"
+)
+
+// TestSelftestCommandDefined verifies that the selftest command is registered with the expected flags.
+func TestSelftestCommandDefined(t *testing.T) {
+	if selftestCmd == nil {
+		t.Fatal("selftestCmd should be defined")
+	}
+
+	flag := selftestCmd.Flags().Lookup("namespace")
+	if flag == nil {
+		t.Fatal("expected 'namespace' flag to be defined")
+	}
+	if flag.DefValue != selftest.DefaultNamespace {
+		t.Errorf("expected default namespace %s, got %s", selftest.DefaultNamespace, flag.DefValue)
+	}
+	if flag.Shorthand != "n" {
+		t.Errorf("expected namespace shorthand 'n', got '%s'", flag.Shorthand)
+	}
+}
+
+// TestSelftestDryRun verifies that dry runs are rejected as usage errors before connecting.
+func TestSelftestDryRun(t *testing.T) {
+	opts := newTestClientOptions("")
+	opts.dryRun = k8s.DryRunServer
+	if _, err := runSelftest(opts); exitCode(err) != exitUsage {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+}
+
+// TestPrintSelftestReport verifies that reports render without error.
+func TestPrintSelftestReport(t *testing.T) {
+	report := selftest.Report{
+		Namespace: selftest.DefaultNamespace,
+		Steps: []selftest.StepResult{
+			{Name: "create namespace", Passed: true},
+			{Name: "list deployments", Passed: false, Error: "boom"},
+		},
+	}
+
+	if err := printSelftestReport(report); err != nil {
+		t.Errorf("printSelftestReport() error = %v", err)
+	}
+	if passFail(true) != "PASS" || passFail(false) != "FAIL" {
+		t.Error("unexpected passFail rendering")
+	}
+}
@@ -3,63 +3,721 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	"github.com/Searge/k8s-controller/pkg/certs"
+	"github.com/Searge/k8s-controller/pkg/hooks"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/metrics"
+	"github.com/Searge/k8s-controller/pkg/pipeline"
 	"github.com/Searge/k8s-controller/pkg/server"
+	"github.com/Searge/k8s-controller/pkg/startup"
+	"github.com/Searge/k8s-controller/pkg/tracing"
 )
 
-// serverPort holds the port number for the HTTP server, configured via CLI flag.
-var serverPort int
+// startupRetryInterval is the delay between Kubernetes API connection attempts during startup.
+const startupRetryInterval = 5 * time.Second
+
+// tracingFlushTimeout bounds the export of the pending spans on exit.
+const tracingFlushTimeout = 5 * time.Second
+
+// Flags for the serve command
+var (
+	// serverPort holds the port number for the HTTP server, configured via CLI flag.
+	serverPort int
+
+	// listenAddress is the TCP address or Unix domain socket the server listens on instead of serverPort.
+	listenAddress string
+
+	// grpcListen is the address the gRPC API is served on alongside HTTP, if set.
+	grpcListen string
+
+	// socketMode is the octal file mode of the Unix domain sockets of listenAddress and grpcListen.
+	socketMode string
+
+	// upstreamTimeout is the per-request time budget for Kubernetes API calls.
+	upstreamTimeout time.Duration
+
+	// upstreamRetries is the number of retries allowed for transient Kubernetes API errors.
+	upstreamRetries int
+
+	// metricsConfig selects the backend request metrics are exported to.
+	metricsConfig metrics.Config
+
+	// traceConfig selects the collector spans of requests and Kubernetes API calls are exported to.
+	traceConfig tracing.Config
+
+	// enablePprof serves the pprof profiles and expvar variables on the server.
+	enablePprof bool
+
+	// enableSwaggerUI serves the Swagger UI exploring the OpenAPI document of the API.
+	enableSwaggerUI bool
+
+	// enableWriteAPI serves the endpoints creating, replacing and deleting deployments.
+	enableWriteAPI bool
+
+	// enableServiceProxy serves the proxy to in-cluster services.
+	enableServiceProxy bool
+
+	// hooksFile is the file of the webhooks triggering pipelines.
+	hooksFile string
+
+	// apiTokenFile is the file with the static bearer tokens of the API callers.
+	apiTokenFile string
+
+	// requireAuth only serves authenticated callers, except on authOpenPaths.
+	requireAuth bool
+
+	// authOpenPaths are served without authentication with --require-auth.
+	authOpenPaths []string
+
+	// jwtIssuer is the required issuer of JWTs, whose keys are discovered unless jwtJWKSURL is set.
+	jwtIssuer string
+
+	// jwtAudience is the audience JWTs must be issued for.
+	jwtAudience string
+
+	// jwtJWKSURL is the URL of the key set JWTs are verified with.
+	jwtJWKSURL string
+
+	// jwtSecretFile is the file with the shared secret of HMAC-signed JWTs.
+	jwtSecretFile string
+
+	// tokenReview authenticates bearer tokens and authorizes requests by the cluster.
+	tokenReview bool
+
+	// tokenReviewAudiences are the audiences reviewed tokens must be issued for.
+	tokenReviewAudiences []string
+
+	// accessLogSampling logs one in this many successful probe and metrics requests.
+	accessLogSampling int
+
+	// shutdownGracePeriod bounds how long in-flight requests are drained on SIGINT or SIGTERM.
+	shutdownGracePeriod time.Duration
+
+	// serverLimits are the timeouts and resource limits of the HTTP server.
+	serverLimits server.Limits
+
+	// httpBackend names the HTTP server implementation.
+	httpBackend string
+
+	// enableCompression compresses responses for clients accepting brotli or gzip.
+	enableCompression bool
+
+	// acmeHosts are the public host names certificates are obtained for via ACME.
+	// If empty, certificates are taken from --cert-dir, if set.
+	acmeHosts []string
+
+	// acmeCacheDir is the directory the ACME certificates and account key are stored in.
+	acmeCacheDir string
+
+	// serveCache serves deployment reads of the API from an informer-backed cache.
+	serveCache bool
+
+	// cacheResync is the resync period of the read cache.
+	cacheResync time.Duration
+
+	// serveOpts are the connection and timeout flags of the serve command.
+	serveOpts clientOptions
+)
 
 // serveCmd represents the serve command which starts the HTTP server.
 // It accepts a --port flag to specify which port to bind to (default: 8080).
-// The command will block until the server encounters an error or is terminated.
+// The command will block until the server encounters an error or is shut down by SIGINT or SIGTERM.
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start HTTP server",
 	Long: `Start the HTTP server with health check and debug endpoints.
 
 The server provides the following endpoints:
-  - GET /health: Health check endpoint returning JSON status
-  - GET /*: Default greeting message for all other paths
+  - GET /livez: Liveness probe, 200 while the process is alive
+  - GET /readyz: Readiness probe, 503 with the reasons until the Kubernetes API
+    is reachable and the caches have synced
+  - GET /startupz: Staged startup progress as JSON (503 until started)
+  - GET /version: Build metadata and the Kubernetes version as JSON
+  - GET /healthz/detail: Uptime, version, goroutines, cache and API server state
+    and the health of each subsystem as JSON, for triage
+  - GET /api/v1/deployments: Deployments as JSON (?namespace=, ?labelSelector=)
+  - GET /api/v1/watch/deployments: Deployment changes as server-sent events,
+    resumed from the Last-Event-ID header (?namespace=, ?labelSelector=)
+  - GET /api/v1/pods, /api/v1/services: Pods or services as JSON (?namespace=)
+  - GET /api/v1/nodes, /api/v1/namespaces: Nodes or namespaces as JSON
+    (lists are sorted and paged with ?sortBy=, ?order=, ?limit= and ?continue=)
+  - POST /api/v1/namespaces/{namespace}/deployments: Create a deployment, with --enable-write-api
+  - PUT, DELETE /api/v1/namespaces/{namespace}/deployments/{name}: Replace or
+    delete a deployment, with --enable-write-api
+  - * /proxy/namespaces/{namespace}/services/{name}[:{port}]/*: Proxy to an in-cluster
+    service, with --enable-service-proxy
+  - GET /api/v1/limits: Upstream timeout and retry budget as JSON
+  - POST /hooks/{name}: Run the pipeline of a webhook, with --hooks-file
+  - GET, PUT /admin/loglevel: Current log level, or change it at runtime (always authenticated)
+  - GET /metrics: Request metrics in the Prometheus text format
+  - GET /debug/pprof/*, /debug/vars: Runtime profiles and expvar variables, with --enable-pprof
+  - GET /openapi.json: OpenAPI 3 document of the endpoints above
+  - GET /docs: Swagger UI exploring the OpenAPI document, with --enable-swagger-ui
+  - GET /: Default greeting message
+
+Other paths are answered with 404 Not Found, and other methods with 405 Method
+Not Allowed.
+
+If no Kubernetes cluster is reachable, the server still starts and the
+API endpoints respond with 503. Use --demo to serve a seeded in-memory
+fake cluster instead, e.g. for evaluation, UI development or demos.
+
+With --cert-dir the server serves HTTPS. The certificate is generated (self-signed)
+or expected from cert-manager (--cert-mode=cert-manager) and reloaded without a
+restart when the files in the directory are rotated. Alternatively, with
+--acme-host the server obtains and renews Let's Encrypt certificates for its public
+host names itself, keeping them in --acme-cache-dir; it must then be reachable on
+port 443 of these names for the tls-alpn-01 challenge.
+
+Request counts and durations are exported to the --metrics-backend: scraped
+from GET /metrics (prometheus, the default), sent to a StatsD agent over UDP
+(statsd) or pushed to an OpenTelemetry collector over OTLP/HTTP (otlp).
+
+With --trace-endpoint, each request and the Kubernetes API calls made for it are
+recorded as OpenTelemetry spans and exported to the collector over OTLP/HTTP. The
+server continues the traces of callers sending a W3C traceparent header and passes
+them on to the API server, so slow requests can be followed end to end.
+
+With --enable-write-api, deployments can be created from a YAML or JSON manifest,
+replaced and deleted over HTTP by authenticated callers. Changes still go through
+the --authz-webhook and --dry-run of the server.
+
+With --enable-service-proxy, authenticated callers reach in-cluster services through
+the API server with the credentials of the server, like kubectl proxy:
+/proxy/namespaces/NS/services/NAME[:PORT]/PATH is forwarded to
+/api/v1/namespaces/NS/services/NAME[:PORT]/proxy/PATH. Proxied requests go through
+the --authz-webhook as "proxy" operations.
+
+With --hooks-file, webhooks of external systems, e.g. GitHub or a CI system, run
+pipelines (see 'kc run'), e.g. to restart a deployment or re-apply manifests. Each
+hook names a pipeline file and an HMAC-SHA256 secret; deliveries to POST /hooks/NAME
+must carry the signature of their body in X-Hub-Signature-256, as GitHub sends it.
+The pipeline runs in the background; deliveries during a run queue one rerun.
+
+Callers authenticate with a bearer token: a static token listed in --api-token-file,
+one TOKEN[,NAME] per line, or a JWT signed by the keys of --jwt-issuer (discovered
+via OpenID Connect or given by --jwt-jwks-url) or by the secret in --jwt-secret-file.
+With --token-review, tokens such as ServiceAccount tokens are reviewed by the cluster
+(TokenReview), and requests authorized by RBAC on their path (SubjectAccessReview).
+The write API always requires authentication; with --require-auth all endpoints
+but --auth-open-paths (the probes and /metrics by default) do.
+
+With --cache, deployments, pods, services, nodes and namespaces are listed once
+and then kept up to date by watches in an in-memory cache, so API requests no
+longer call the Kubernetes API server, and the server can serve dashboards as a
+cheap read replica. The cache is used once it has synced (see /startupz); requests
+with field selectors still go to the API server. Cached lists carry their age in
+the X-KC-Cache-Age header. Cached deployment lists carry an ETag, and requests
+with a matching If-None-Match header are answered with 304 Not Modified.
+
+Each request is logged with its method, path, status, size, latency, remote IP,
+user agent and request ID, which is taken from or returned in the X-Request-ID
+header. Successful probe and metrics requests are sampled (--access-log-sampling).
+
+With --listen the server listens on a given TCP address, e.g. 127.0.0.1:8080, or
+on a Unix domain socket, e.g. unix:///var/run/kc/kc.sock for a sidecar sharing the
+directory, instead of all interfaces on --port. The socket gets --socket-mode (0660,
+owner and group), and a socket left by a killed server is replaced. Under a systemd
+socket unit, --listen=systemd: serves the socket passed by systemd, or with
+--listen=systemd:NAME the one named NAME with FileDescriptorName=.
+
+With --grpc-listen the cluster API is also served over gRPC on a separate address,
+e.g. :9090, so that other services list and watch deployments and pods with typed
+clients generated from pkg/api/v1/cluster.proto. It shares the TLS, authentication
+and rate limit settings of the HTTP server; authorizers see RPCs as GET requests of
+their full method name, e.g. /k8scontroller.v1.ClusterService/ListPods. The same
+service is served as REST JSON on /api/v2/, generated from the proto by grpc-gateway.
+
+The server is implemented with fasthttp by default. With --http-backend=net/http
+it is served by net/http instead, which also speaks HTTP/2: negotiated via ALPN over
+HTTPS, and unencrypted (h2c) to clients with prior knowledge, e.g. gRPC clients and
+proxies. --max-conns-per-ip is only supported by fasthttp.
+
+Requests are bounded by --read-timeout, --write-timeout and --max-request-body-size,
+connections by --idle-timeout, --max-conns-per-ip and --concurrency. Watch streams are
+closed after an hour and resumed by clients, report exports after ten minutes. With
+--rate-limit, API requests beyond the rate are answered with 429 Too Many Requests.
+With --enable-compression, responses are compressed for clients accepting brotli or gzip.
+
+On SIGINT or SIGTERM the server stops accepting connections, ends the watch
+streams and drains in-flight requests for up to --shutdown-grace-period before
+it stops the informers and closes its clients. A second signal exits immediately.
+
+API responses carry X-KC-Retries and X-KC-Upstream-Latency headers describing
+how many upstream retries were needed and how long the Kubernetes API took.
 
 Examples:
   k8s-controller serve
   k8s-controller serve --port=9090
-  k8s-controller serve --port=8080 --log-level=debug`,
-	Run: func(_ *cobra.Command, _ []string) {
+  k8s-controller serve --listen=unix:///var/run/kc/kc.sock --socket-mode=0660
+  k8s-controller serve --demo
+  k8s-controller serve --demo --demo-fixture=fixtures.yaml
+  k8s-controller serve --port=8080 --log-level=debug
+  k8s-controller serve --port=8443 --cert-dir=/certs --cert-mode=cert-manager
+  k8s-controller serve --metrics-backend=statsd --statsd-address=statsd.monitoring:8125
+  k8s-controller serve --enable-pprof
+  k8s-controller serve --enable-swagger-ui
+  k8s-controller serve --grpc-listen=:9090
+  k8s-controller serve --enable-write-api --api-token-file=/etc/kc/tokens
+  k8s-controller serve --require-auth --jwt-issuer=https://accounts.example.com --jwt-audience=kc
+  k8s-controller serve --metrics-backend=otlp --otlp-endpoint=http://otel-collector:4318/v1/metrics
+  k8s-controller serve --trace-endpoint=http://otel-collector:4318/v1/traces --trace-sample-ratio=0.1`,
+	Run: func(cmd *cobra.Command, _ []string) {
 		// Validate port range
 		if err := validatePort(serverPort); err != nil {
 			log.Error().Err(err).Msg("Invalid port number")
-			os.Exit(1)
+			exit(exitCode(err))
+		}
+		if err := validateListen(listenAddress, cmd.Flags().Changed("port")); err != nil {
+			log.Error().Err(err).Msg("Invalid listen address")
+			exit(exitCode(err))
+		}
+		if err := validateListen(grpcListen, false); err != nil {
+			log.Error().Err(err).Msg("Invalid gRPC listen address")
+			exit(exitCode(err))
+		}
+		mode, err := parseSocketMode(socketMode)
+		if err != nil {
+			log.Error().Err(err).Msg("Invalid socket mode")
+			exit(exitCode(err))
+		}
+		if err := validateBackend(httpBackend, serverLimits); err != nil {
+			log.Error().Err(err).Msg("Invalid HTTP backend")
+			exit(exitCode(err))
+		}
+
+		tracker := startup.NewTracker(startup.DefaultStages...)
+		tlsConfig, err := serverTLSConfig(context.Background())
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to set up serving certificates")
+			exit(exitCode(err))
+		}
+		metricsBackend, err := metrics.New(metricsConfig, log.Logger)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to set up metrics")
+			exit(exitCode(err))
 		}
+		defer closeMetrics(metricsBackend)
+		k8s.RegisterClientMetrics(metricsBackend)
+		closeTracing, err := setupTracing()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to set up tracing")
+			exit(exitCode(err))
+		}
+		defer closeTracing()
+		tracker.Complete(startup.StageConfigLoaded)
+
+		client := createServeClient(&serveOpts)
+		if client != nil {
+			defer closeClient(client)
+		}
+		authenticator, err := apiAuthenticator(client)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to set up API authentication")
+			exit(exitCode(err))
+		}
+		dispatcher, err := hookDispatcher(client)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to load webhooks")
+			exit(exitCode(err))
+		}
+		// The informers run until the server has drained its requests, which may still read the cache.
+		cacheCtx, stopCaches := context.WithCancel(context.Background())
+		defer stopCaches()
+		go trackStartup(cacheCtx, client, tracker)
 
 		// Log server startup information
-		log.Info().Int("port", serverPort).Msg("Starting HTTP server")
+		log.Info().Int("port", serverPort).Str("listen", listenAddress).Bool("demo", demoMode).
+			Msg("Starting HTTP server")
 
 		// Start the server - this blocks until error or termination
-		if err := server.Start(serverPort, log.Logger); err != nil {
-			log.Error().Err(err).Msg("Failed to start server")
-			os.Exit(1)
+		opts := server.Options{
+			Port:           serverPort,
+			Listen:         listenAddress,
+			SocketMode:     mode,
+			Client:         client,
+			Budget:         server.RetryBudget{Timeout: upstreamTimeout, MaxRetries: upstreamRetries},
+			TLSConfig:      tlsConfig,
+			Startup:        tracker,
+			Build:          currentBuildInfo(),
+			EnablePprof:    enablePprof,
+			EnableWriteAPI: enableWriteAPI,
+			Authenticator:  authenticator,
+			RequireAuth:    requireAuth,
+			OpenPaths:      authOpenPaths,
+			Metrics:        metricsBackend,
+
+			EnableServiceProxy:  enableServiceProxy,
+			Hooks:               dispatcher,
+			EnableSwaggerUI:     enableSwaggerUI,
+			AccessLogSampling:   accessLogSampling,
+			Limits:              serverLimits,
+			Backend:             httpBackend,
+			EnableCompression:   enableCompression,
+			GRPCListen:          grpcListen,
+			ShutdownGracePeriod: shutdownGracePeriod,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			// A second signal terminates the process without waiting for the shutdown.
+			<-ctx.Done()
+			stop()
+		}()
+		if err := server.Start(ctx, opts, log.Logger); err != nil {
+			log.Error().Err(err).Msg("Server failed")
+			exit(exitCode(err))
+		}
+		if dispatcher != nil {
+			// The pipelines of hooks are cancelled with the server, and end their running steps.
+			dispatcher.Wait()
 		}
 	},
 }
 
+// hookDispatcher loads the hooks of --hooks-file and returns a dispatcher running their pipelines with
+// client. It returns nil without --hooks-file, or without a client, which the hooks need.
+func hookDispatcher(client *k8s.Client) (*hooks.Dispatcher, error) {
+	if hooksFile == "" {
+		return nil, nil
+	}
+	loaded, err := hooks.Load(hooksFile)
+	if err != nil {
+		return nil, newUsageError("%w", err)
+	}
+	if client == nil {
+		log.Error().Msg("Webhooks are disabled without a Kubernetes client")
+		return nil, nil
+	}
+	log.Info().Int("hooks", len(loaded)).Msg("Serving webhooks on /hooks/")
+	runner := pipeline.NewRunner(client, pipeline.Options{DryRun: serveOpts.dryRun}, log.Logger)
+	return hooks.NewDispatcher(loaded, runner, log.Logger), nil
+}
+
+// createServeClient creates the Kubernetes client backing the server's API endpoints.
+// Failure is not fatal: the server runs without Kubernetes-backed endpoints instead.
+// In demo mode a fixture error is fatal, since the user explicitly asked for that data.
+func createServeClient(opts *clientOptions) *k8s.Client {
+	client, err := opts.newClient()
+	if err == nil {
+		return client
+	}
+
+	if demoMode {
+		log.Error().Err(err).Msg("Failed to create demo cluster")
+		exit(exitCode(err))
+	}
+
+	log.Warn().Err(err).Msg("Kubernetes client unavailable, API endpoints will be disabled")
+	return nil
+}
+
+// apiAuthenticator authenticates API callers by the static tokens of --api-token-file, by JWTs
+// validated with the --jwt-* flags and, with --token-review, by TokenReviews of the client's cluster,
+// tried in this order. It returns a nil authenticator if none is set, which is a usage error with
+// --enable-write-api, --enable-service-proxy or --require-auth.
+func apiAuthenticator(client *k8s.Client) (server.Authenticator, error) {
+	var auths []server.Authenticator
+	if apiTokenFile != "" {
+		tokens, err := server.LoadTokenFile(apiTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		auths = append(auths, tokens)
+	}
+	if jwtIssuer != "" || jwtJWKSURL != "" || jwtSecretFile != "" {
+		opts := server.JWTOptions{Issuer: jwtIssuer, Audience: jwtAudience, JWKSURL: jwtJWKSURL}
+		if jwtSecretFile != "" {
+			secret, err := os.ReadFile(jwtSecretFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT secret: %w", err)
+			}
+			opts.Secret = bytes.TrimSpace(secret)
+		}
+		jwtAuth, err := server.NewJWTAuthenticator(opts)
+		if err != nil {
+			return nil, newUsageError("%w", err)
+		}
+		auths = append(auths, jwtAuth)
+	}
+	if tokenReview {
+		if client == nil {
+			return nil, errors.New("--token-review requires a Kubernetes client")
+		}
+		auths = append(auths, server.NewTokenReviewAuthenticator(client, tokenReviewAudiences))
+	}
+
+	switch {
+	case len(auths) == 0 && (enableWriteAPI || enableServiceProxy || requireAuth):
+		return nil, newUsageError("--enable-write-api, --enable-service-proxy and --require-auth need " +
+			"--api-token-file, --jwt-issuer, --jwt-jwks-url, --jwt-secret-file or --token-review")
+	case len(auths) == 0:
+		return nil, nil
+	case len(auths) == 1:
+		return auths[0], nil
+	default:
+		return server.ChainAuthenticators(auths...), nil
+	}
+}
+
+// serverTLSConfig returns the TLS configuration of the server: with --acme-host, certificates are
+// obtained via ACME, otherwise they are taken from --cert-dir. It returns nil to serve plain HTTP.
+func serverTLSConfig(ctx context.Context) (*tls.Config, error) {
+	if len(acmeHosts) == 0 {
+		return servingTLSConfig(ctx)
+	}
+	if certDir != "" {
+		return nil, newUsageError("--acme-host and --cert-dir are mutually exclusive")
+	}
+	if acmeCacheDir == "" {
+		return nil, newUsageError("--acme-host requires --acme-cache-dir")
+	}
+	config, err := certs.ACMETLSConfig(acmeHosts, acmeCacheDir)
+	if err != nil {
+		return nil, newUsageError("%w", err)
+	}
+	log.Info().Strs("hosts", acmeHosts).Str("cache_dir", acmeCacheDir).Msg("Obtaining certificates via ACME")
+	return config, nil
+}
+
+// closeMetrics flushes and closes the metrics backend, logging failures.
+func closeMetrics(backend metrics.Backend) {
+	if err := backend.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close metrics backend")
+	}
+}
+
+// setupTracing installs the tracer provider of the --trace-* flags. The returned function exports the
+// pending spans, waiting at most tracingFlushTimeout for the collector.
+func setupTracing() (func(), error) {
+	cfg := traceConfig
+	cfg.Version = Version
+	shutdown, err := tracing.Setup(cfg, log.Logger)
+	if err != nil {
+		return nil, newUsageError("%w", err)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to export pending spans")
+		}
+	}, nil
+}
+
+// trackStartup completes the remaining startup stages while the server is already listening.
+// The Kubernetes API connection is retried until it succeeds, recording the last error.
+func trackStartup(ctx context.Context, client *k8s.Client, tracker *startup.Tracker) {
+	if client == nil {
+		tracker.Skip(startup.StageK8sConnected, "kubernetes client unavailable")
+	} else {
+		tracker.Begin(startup.StageK8sConnected)
+		for {
+			err := client.TestConnection(ctx)
+			if err == nil {
+				tracker.Complete(startup.StageK8sConnected)
+				break
+			}
+			tracker.Fail(startup.StageK8sConnected, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(startupRetryInterval):
+			}
+		}
+	}
+
+	syncCaches(ctx, client, tracker)
+	tracker.Skip(startup.StageLeaderElected, "leader election not enabled")
+}
+
+// syncCaches starts the read cache with --cache and waits for it to sync. Until then,
+// and if it fails, API requests are served by the Kubernetes API server directly.
+func syncCaches(ctx context.Context, client *k8s.Client, tracker *startup.Tracker) {
+	if !serveCache || client == nil {
+		// Without the read cache the server runs no informers, so there are no caches to wait for.
+		tracker.Progress(startup.StageCachesSyncing, 0, 0)
+		tracker.Complete(startup.StageCachesSyncing)
+		return
+	}
+
+	tracker.Begin(startup.StageCachesSyncing)
+	tracker.Progress(startup.StageCachesSyncing, 0, 1)
+	if err := client.StartCache(ctx, cacheResync); err != nil {
+		log.Warn().Err(err).Msg("Read cache unavailable, serving requests from the API server")
+		tracker.Skip(startup.StageCachesSyncing, "read cache unavailable: "+err.Error())
+		return
+	}
+	tracker.Progress(startup.StageCachesSyncing, 1, 1)
+	tracker.Complete(startup.StageCachesSyncing)
+}
+
 // validatePort checks if the provided port number is within the valid range.
 // Valid TCP port numbers are 1-65535 (0 is reserved and typically not usable for binding).
 func validatePort(port int) error {
 	if port <= 0 || port > 65535 {
-		return fmt.Errorf("invalid port number: %d, must be between 1 and 65535", port)
+		return newUsageError("invalid port number: %d, must be between 1 and 65535", port)
 	}
 	return nil
 }
 
+// validateListen checks that --listen is a TCP address with a port, a Unix domain socket with a path or
+// a socket passed by systemd, and that it is not combined with --port.
+func validateListen(address string, portSet bool) error {
+	if address == "" {
+		return nil
+	}
+	if portSet {
+		return newUsageError("--listen and --port are mutually exclusive")
+	}
+	if strings.HasPrefix(address, server.SystemdScheme) {
+		return nil
+	}
+	if path, ok := strings.CutPrefix(address, server.UnixScheme); ok {
+		if path == "" {
+			return newUsageError("invalid listen address %q: missing socket path", address)
+		}
+		return nil
+	}
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return newUsageError("invalid listen address %q: %w", address, err)
+	}
+	if number, err := strconv.Atoi(port); err != nil || validatePort(number) != nil {
+		return newUsageError("invalid listen address %q: port must be between 1 and 65535", address)
+	}
+	return nil
+}
+
+// validateBackend checks that --http-backend names a backend supporting the limits.
+func validateBackend(backend string, limits server.Limits) error {
+	if !slices.Contains(server.Backends, backend) {
+		return newUsageError("invalid HTTP backend %q, must be one of %v", backend, server.Backends)
+	}
+	if backend == server.BackendNetHTTP && limits.MaxConnsPerIP > 0 {
+		return newUsageError("--max-conns-per-ip is not supported by the net/http backend")
+	}
+	return nil
+}
+
+// parseSocketMode parses the octal file mode of --socket-mode, e.g. 0660.
+func parseSocketMode(mode string) (fs.FileMode, error) {
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value == 0 || value > 0o777 {
+		return 0, newUsageError("invalid socket mode %q: must be octal permissions such as 0660", mode)
+	}
+	return fs.FileMode(value), nil
+}
+
 // init registers the serve command with the root command and configures its flags.
 func init() {
 	rootCmd.AddCommand(serveCmd)
 	serveCmd.Flags().IntVar(&serverPort, "port", 8080, "Port to run the server on (1-65535)")
+	serveCmd.Flags().StringVar(&listenAddress, "listen", "",
+		"Address to listen on instead of --port: host:port, a Unix domain socket as unix:///path/to/socket, "+
+			"or systemd[:NAME] for a socket passed by systemd socket activation")
+	serveCmd.Flags().StringVar(&grpcListen, "grpc-listen", "",
+		"Address to serve the gRPC API on alongside HTTP, in the forms of --listen (default: not served)")
+	serveCmd.Flags().StringVar(&socketMode, "socket-mode", fmt.Sprintf("%04o", server.DefaultSocketMode),
+		"Octal file mode of the Unix domain sockets of --listen and --grpc-listen")
+	serveCmd.Flags().DurationVar(&upstreamTimeout, "upstream-timeout", server.DefaultUpstreamTimeout,
+		"Time budget per request for Kubernetes API calls, including retries")
+	serveCmd.Flags().IntVar(&upstreamRetries, "upstream-retries", server.DefaultMaxRetries,
+		"Retries allowed per request for transient Kubernetes API errors")
+	serveCmd.Flags().StringVar(&metricsConfig.Backend, "metrics-backend", metrics.BackendPrometheus,
+		"Where request metrics are exported: prometheus (GET /metrics), statsd, otlp or none")
+	serveCmd.Flags().StringVar(&metricsConfig.StatsDAddress, "statsd-address", metrics.DefaultStatsDAddress,
+		"UDP host:port of the StatsD agent, for --metrics-backend=statsd")
+	serveCmd.Flags().StringVar(&metricsConfig.OTLPEndpoint, "otlp-endpoint", metrics.DefaultOTLPEndpoint,
+		"OTLP/HTTP metrics URL of the collector, for --metrics-backend=otlp")
+	serveCmd.Flags().DurationVar(&metricsConfig.PushInterval, "metrics-push-interval", metrics.DefaultPushInterval,
+		"How often metrics are pushed, for --metrics-backend=otlp")
+	serveCmd.Flags().StringVar(&traceConfig.Endpoint, "trace-endpoint", "",
+		"OTLP/HTTP traces URL of the collector, e.g. "+tracing.DefaultOTLPEndpoint+" (default: not traced)")
+	serveCmd.Flags().Float64Var(&traceConfig.SampleRatio, "trace-sample-ratio", tracing.DefaultSampleRatio,
+		"Fraction of the traces started by the server that are recorded, from 0 to 1; traced callers decide")
+	serveCmd.Flags().BoolVar(&enablePprof, "enable-pprof", false,
+		"Serve pprof profiles on /debug/pprof/ and expvar variables on /debug/vars; only on trusted networks")
+	serveCmd.Flags().BoolVar(&enableSwaggerUI, "enable-swagger-ui", false,
+		"Serve the Swagger UI on /docs, which the browser loads from the jsDelivr CDN")
+	serveCmd.Flags().BoolVar(&enableWriteAPI, "enable-write-api", false,
+		"Serve the endpoints creating, replacing and deleting deployments to authenticated callers")
+	serveCmd.Flags().BoolVar(&enableServiceProxy, "enable-service-proxy", false,
+		"Proxy /proxy/namespaces/NS/services/SVC[:PORT]/ to in-cluster services for authenticated callers")
+	serveCmd.Flags().StringVar(&hooksFile, "hooks-file", "",
+		"YAML file of webhooks running pipelines on POST /hooks/NAME, verified by HMAC signatures")
+	serveCmd.Flags().StringVar(&apiTokenFile, "api-token-file", "",
+		"File with static bearer tokens of API callers, one TOKEN[,NAME] per line")
+	serveCmd.Flags().BoolVar(&requireAuth, "require-auth", false,
+		"Require a bearer token or JWT on all endpoints except --auth-open-paths")
+	serveCmd.Flags().StringSliceVar(&authOpenPaths, "auth-open-paths", server.DefaultOpenPaths,
+		"Paths served without authentication with --require-auth, e.g. for probes and scrapers")
+	serveCmd.Flags().StringVar(&jwtIssuer, "jwt-issuer", "",
+		"Required issuer of JWTs; its keys are discovered via OpenID Connect unless --jwt-jwks-url is set")
+	serveCmd.Flags().StringVar(&jwtAudience, "jwt-audience", "",
+		"Audience JWTs must be issued for")
+	serveCmd.Flags().StringVar(&jwtJWKSURL, "jwt-jwks-url", "",
+		"URL of the JSON Web Key Set JWTs are verified with")
+	serveCmd.Flags().StringVar(&jwtSecretFile, "jwt-secret-file", "",
+		"File with the shared secret of HMAC-signed JWTs (HS256/384/512), at least 32 bytes")
+	serveCmd.Flags().BoolVar(&tokenReview, "token-review", false,
+		"Authenticate bearer tokens, e.g. of ServiceAccounts, and authorize requests via TokenReview and "+
+			"SubjectAccessReview")
+	serveCmd.Flags().StringSliceVar(&tokenReviewAudiences, "token-review-audiences", nil,
+		"Audiences tokens must be issued for, with --token-review (default: those of the API server)")
+	serveCmd.Flags().IntVar(&accessLogSampling, "access-log-sampling", server.DefaultAccessLogSampling,
+		"Log one in this many successful requests to /livez, /readyz, /startupz and /metrics; 1 logs all")
+	serveCmd.Flags().StringVar(&httpBackend, "http-backend", server.BackendFastHTTP,
+		"HTTP server implementation: fasthttp (HTTP/1.1) or net/http (HTTP/1.1, HTTP/2 and h2c)")
+	serveCmd.Flags().DurationVar(&serverLimits.ReadTimeout, "read-timeout", server.DefaultReadTimeout,
+		"Time allowed to read a request, including its body")
+	serveCmd.Flags().DurationVar(&serverLimits.WriteTimeout, "write-timeout", server.DefaultWriteTimeout,
+		"Time allowed to write a response, except for watch streams and report exports")
+	serveCmd.Flags().DurationVar(&serverLimits.IdleTimeout, "idle-timeout", server.DefaultIdleTimeout,
+		"Time a keep-alive connection may wait for its next request")
+	serveCmd.Flags().IntVar(&serverLimits.MaxRequestBodySize, "max-request-body-size", server.DefaultMaxRequestBodySize,
+		"Largest request body accepted, in bytes")
+	serveCmd.Flags().IntVar(&serverLimits.MaxConnsPerIP, "max-conns-per-ip", 0,
+		"Concurrent connections allowed per client IP (0 for no limit)")
+	serveCmd.Flags().IntVar(&serverLimits.Concurrency, "concurrency", server.DefaultConcurrency,
+		"Connections served concurrently, including open watch streams")
+	serveCmd.Flags().Float64Var(&serverLimits.RequestRate, "rate-limit", 0,
+		"API requests served per second across all clients (0 for no limit); probes and scrapes are not limited")
+	serveCmd.Flags().IntVar(&serverLimits.RequestBurst, "rate-limit-burst", 0,
+		"API requests allowed at once beyond --rate-limit (default: a second's worth)")
+	serveCmd.Flags().BoolVar(&enableCompression, "enable-compression", false,
+		"Compress responses with brotli or gzip for clients accepting it")
+	serveCmd.Flags().DurationVar(&shutdownGracePeriod, "shutdown-grace-period", server.DefaultShutdownGracePeriod,
+		"Time to drain in-flight requests on SIGINT or SIGTERM before exiting")
+	serveCmd.Flags().BoolVar(&serveCache, "cache", false,
+		"Serve API reads from an in-memory cache kept up to date by watches")
+	serveCmd.Flags().DurationVar(&cacheResync, "cache-resync", k8s.DefaultCacheResync,
+		"Resync period of the read cache, for --cache")
+	addCertFlags(serveCmd)
+	serveCmd.Flags().StringSliceVar(&acmeHosts, "acme-host", nil,
+		"Public host name to obtain a Let's Encrypt certificate for via ACME; repeatable, excludes --cert-dir")
+	serveCmd.Flags().StringVar(&acmeCacheDir, "acme-cache-dir", "",
+		"Directory the ACME certificates and account key are kept in across restarts, for --acme-host")
+	addClientFlags(serveCmd, &serveOpts, 30)
+	addDryRunFlag(serveCmd, &serveOpts)
 }
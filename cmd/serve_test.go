@@ -3,7 +3,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/server"
+	"github.com/Searge/k8s-controller/pkg/startup"
+	"github.com/Searge/k8s-controller/pkg/tracing"
 )
 
 // TestServeCommandDefined verifies that the serve command is properly defined
@@ -22,4 +35,397 @@ func TestServeCommandDefined(t *testing.T) {
 	if portFlag == nil {
 		t.Error("expected 'port' flag to be defined")
 	}
+
+	// Verify the metrics flags and their defaults
+	metricsFlags := map[string]string{
+		"metrics-backend":       "prometheus",
+		"statsd-address":        "127.0.0.1:8125",
+		"otlp-endpoint":         "http://localhost:4318/v1/metrics",
+		"metrics-push-interval": "15s",
+		"trace-endpoint":        "",
+		"trace-sample-ratio":    "1",
+		"cache":                 "false",
+		"cache-resync":          "10m0s",
+		"enable-write-api":      "false",
+		"enable-service-proxy":  "false",
+		"hooks-file":            "",
+		"enable-swagger-ui":     "false",
+		"api-token-file":        "",
+		"dry-run":               "none",
+		"access-log-sampling":   "10",
+		"shutdown-grace-period": "20s",
+		"listen":                "",
+		"grpc-listen":           "",
+		"http-backend":          "fasthttp",
+		"socket-mode":           "0660",
+		"read-timeout":          "10s",
+		"write-timeout":         "30s",
+		"idle-timeout":          "1m30s",
+		"max-request-body-size": "3145728",
+		"max-conns-per-ip":      "0",
+		"concurrency":           "10000",
+		"rate-limit":            "0",
+		"rate-limit-burst":      "0",
+		"enable-compression":    "false",
+		"require-auth":          "false",
+		"auth-open-paths":       "[/livez,/readyz,/startupz,/metrics]",
+		"jwt-issuer":            "",
+		"token-review":          "false",
+		"acme-host":             "[]",
+		"acme-cache-dir":        "",
+	}
+	for name, want := range metricsFlags {
+		flag := serveCmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+			continue
+		}
+		if flag.DefValue != want {
+			t.Errorf("expected '%s' default %q, got %q", name, want, flag.DefValue)
+		}
+	}
+}
+
+// TestAPIAuthenticator verifies that the authenticators are built from the token file and JWT flags,
+// and that --enable-write-api, --enable-service-proxy and --require-auth require one.
+func TestAPIAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "tokens")
+	if err := os.WriteFile(tokenFile, []byte("secret,ci\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	secretFile := filepath.Join(dir, "jwt-secret")
+	if err := os.WriteFile(secretFile, []byte("0123456789abcdef0123456789abcdef\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	shortSecretFile := filepath.Join(dir, "short-secret")
+	if err := os.WriteFile(shortSecretFile, []byte("short"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		writeAPI   bool
+		require    bool
+		tokens     string
+		issuer     string
+		secret     string
+		wantAuth   bool
+		wantErr    bool
+		wantCaller string
+	}{
+		{"nothing configured", false, false, "", "", "", false, false, ""},
+		{"write API with tokens", true, false, tokenFile, "", "", true, false, "ci"},
+		{"write API without credentials", true, false, "", "", "", false, true, ""},
+		{"required without credentials", false, true, "", "", "", false, true, ""},
+		{"missing token file", true, false, filepath.Join(dir, "missing"), "", "", false, true, ""},
+		{"JWT issuer", false, true, "", "https://accounts.example.com", "", true, false, ""},
+		{"tokens and JWT secret", false, true, tokenFile, "", secretFile, true, false, "ci"},
+		{"short JWT secret", false, true, "", "", shortSecretFile, false, true, ""},
+		{"missing JWT secret", false, true, "", "", filepath.Join(dir, "missing"), false, true, ""},
+	}
+
+	t.Run("service proxy without credentials", func(t *testing.T) {
+		enableServiceProxy = true
+		defer func() { enableServiceProxy = false }()
+		if auth, err := apiAuthenticator(nil); err == nil || auth != nil {
+			t.Errorf("apiAuthenticator() = %v, %v, want an error", auth, err)
+		}
+	})
+	t.Run("token review without client", func(t *testing.T) {
+		requireAuth, tokenReview = true, true
+		defer func() { requireAuth, tokenReview = false, false }()
+		if auth, err := apiAuthenticator(nil); err == nil || auth != nil {
+			t.Errorf("apiAuthenticator() = %v, %v, want an error", auth, err)
+		}
+	})
+	t.Run("token review", func(t *testing.T) {
+		requireAuth, tokenReview = true, true
+		defer func() { requireAuth, tokenReview = false, false }()
+		if auth, err := apiAuthenticator(k8s.NewFakeClient(zerolog.Nop())); err != nil || auth == nil {
+			t.Errorf("apiAuthenticator() = %v, %v, want an authenticator", auth, err)
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enableWriteAPI, requireAuth, apiTokenFile = tt.writeAPI, tt.require, tt.tokens
+			jwtIssuer, jwtSecretFile = tt.issuer, tt.secret
+			defer func() {
+				enableWriteAPI, requireAuth, apiTokenFile, jwtIssuer, jwtSecretFile = false, false, "", "", ""
+			}()
+
+			auth, err := apiAuthenticator(nil)
+			if (err != nil) != tt.wantErr || (auth != nil) != tt.wantAuth {
+				t.Fatalf("apiAuthenticator() = %v, %v, want authenticator %v, error %v",
+					auth, err, tt.wantAuth, tt.wantErr)
+			}
+			if tt.wantCaller == "" {
+				return
+			}
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer secret")
+			if caller, err := auth.Authenticate(ctx); caller != tt.wantCaller {
+				t.Errorf("Authenticate() = %q, %v, want %q", caller, err, tt.wantCaller)
+			}
+		})
+	}
+}
+
+// TestHookDispatcher verifies that --hooks-file is loaded into a dispatcher when there is a client.
+func TestHookDispatcher(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"restart.yaml": "steps:\n  - {name: restart, restart: {deployment: web}}\n",
+		"web.secret":   "s3cret",
+		"hooks.yaml":   "hooks:\n  - {name: web, secretFile: web.secret, pipeline: restart.yaml}\n",
+		"invalid.yaml": "hooks:\n  - {name: web, pipeline: restart.yaml}\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		file           string
+		client         *k8s.Client
+		wantDispatcher bool
+		wantErr        bool
+	}{
+		{"no hooks file", "", k8s.NewFakeClient(zerolog.Nop()), false, false},
+		{"hooks", filepath.Join(dir, "hooks.yaml"), k8s.NewFakeClient(zerolog.Nop()), true, false},
+		{"no client", filepath.Join(dir, "hooks.yaml"), nil, false, false},
+		{"invalid hooks", filepath.Join(dir, "invalid.yaml"), k8s.NewFakeClient(zerolog.Nop()), false, true},
+		{"missing hooks file", filepath.Join(dir, "missing.yaml"), nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooksFile = tt.file
+			defer func() { hooksFile = "" }()
+
+			dispatcher, err := hookDispatcher(tt.client)
+			if (err != nil) != tt.wantErr || (dispatcher != nil) != tt.wantDispatcher {
+				t.Errorf("hookDispatcher() = %v, %v, want dispatcher %v, error %v",
+					dispatcher, err, tt.wantDispatcher, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSetupTracing verifies that tracing is off without --trace-endpoint and that invalid --trace-* flags
+// are usage errors. Valid endpoints are not set up here, since they install the global tracer provider.
+func TestSetupTracing(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tracing.Config
+		wantErr bool
+	}{
+		{"not traced", tracing.Config{SampleRatio: 1}, false},
+		{"invalid endpoint", tracing.Config{Endpoint: "otel-collector:4318", SampleRatio: 1}, true},
+		{"invalid sample ratio", tracing.Config{Endpoint: tracing.DefaultOTLPEndpoint, SampleRatio: 2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(saved tracing.Config) { traceConfig = saved }(traceConfig)
+			traceConfig = tt.config
+
+			closeTracing, err := setupTracing()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setupTracing() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if exitCode(err) != exitUsage {
+					t.Errorf("expected a usage error, got %v", err)
+				}
+				return
+			}
+			closeTracing()
+		})
+	}
+}
+
+// TestValidateListen verifies the accepted --listen addresses and the conflict with --port.
+func TestValidateListen(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		portSet bool
+		wantErr bool
+	}{
+		{"unset", "", true, false},
+		{"tcp", "127.0.0.1:8080", false, false},
+		{"tcp all interfaces", ":8080", false, false},
+		{"unix socket", "unix:///var/run/kc.sock", false, false},
+		{"systemd", "systemd:", false, false},
+		{"named systemd socket", "systemd:web", false, false},
+		{"with port", "unix:///var/run/kc.sock", true, true},
+		{"missing socket path", "unix://", false, true},
+		{"missing port", "127.0.0.1", false, true},
+		{"invalid port", "127.0.0.1:70000", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateListen(tt.address, tt.portSet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateListen(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitUsage {
+				t.Errorf("expected a usage error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestValidateBackend verifies the accepted --http-backend values and the limits they support.
+func TestValidateBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		limits  server.Limits
+		wantErr bool
+	}{
+		{"fasthttp", server.BackendFastHTTP, server.Limits{MaxConnsPerIP: 5}, false},
+		{"net/http", server.BackendNetHTTP, server.Limits{}, false},
+		{"net/http with connections per IP", server.BackendNetHTTP, server.Limits{MaxConnsPerIP: 5}, true},
+		{"unknown", "hyper", server.Limits{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBackend(tt.backend, tt.limits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateBackend(%q) error = %v, wantErr %v", tt.backend, err, tt.wantErr)
+			}
+			if err != nil && exitCode(err) != exitUsage {
+				t.Errorf("expected a usage error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestParseSocketMode verifies parsing of the octal --socket-mode.
+func TestParseSocketMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    fs.FileMode
+		wantErr bool
+	}{
+		{"0660", 0o660, false},
+		{"600", 0o600, false},
+		{"0777", 0o777, false},
+		{"0", 0, true},
+		{"1777", 0, true},
+		{"0689", 0, true},
+		{"rw-rw----", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := parseSocketMode(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSocketMode(%q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSocketMode(%q) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestServerTLSConfig verifies the choice between ACME and --cert-dir certificates.
+func TestServerTLSConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		hosts     []string
+		cacheDir  string
+		certDir   string
+		wantTLS   bool
+		wantUsage bool
+	}{
+		{"plain HTTP", nil, "", "", false, false},
+		{"ACME", []string{"kc.example.com"}, t.TempDir(), "", true, false},
+		{"ACME without cache directory", []string{"kc.example.com"}, "", "", false, true},
+		{"ACME with certificate directory", []string{"kc.example.com"}, t.TempDir(), t.TempDir(), false, true},
+		{"invalid ACME host", []string{"kc.example.com:443"}, t.TempDir(), "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acmeHosts, acmeCacheDir, certDir = tt.hosts, tt.cacheDir, tt.certDir
+			defer func() { acmeHosts, acmeCacheDir, certDir = nil, "", "" }()
+
+			config, err := serverTLSConfig(context.Background())
+			var usage *usageError
+			if (config != nil) != tt.wantTLS || errors.As(err, &usage) != tt.wantUsage {
+				t.Errorf("serverTLSConfig() = %v, %v, want TLS %v, usage error %v",
+					config, err, tt.wantTLS, tt.wantUsage)
+			}
+		})
+	}
+}
+
+// TestSyncCaches verifies that --cache syncs the read cache during startup.
+func TestSyncCaches(t *testing.T) {
+	tests := []struct {
+		name       string
+		cache      bool
+		wantSynced bool
+	}{
+		{"cache disabled", false, false},
+		{"cache enabled", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveCache = tt.cache
+			defer func() { serveCache = false }()
+			client := k8s.NewFakeClient(zerolog.Nop())
+			tracker := startup.NewTracker(startup.DefaultStages...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			syncCaches(ctx, client, tracker)
+
+			if client.CacheSynced() != tt.wantSynced {
+				t.Errorf("expected cache synced %v, got %v", tt.wantSynced, client.CacheSynced())
+			}
+			if got := tracker.Status().Stages[2].State; got != startup.StateDone {
+				t.Errorf("expected caches-syncing state %s, got %s", startup.StateDone, got)
+			}
+		})
+	}
+}
+
+// TestTrackStartup verifies that serve completes all startup stages with and without a client.
+func TestTrackStartup(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *k8s.Client
+		wantState startup.State
+	}{
+		{"demo client", k8s.NewFakeClient(zerolog.Nop()), startup.StateDone},
+		{"no client", nil, startup.StateSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := startup.NewTracker(startup.DefaultStages...)
+			tracker.Complete(startup.StageConfigLoaded)
+
+			trackStartup(context.Background(), tt.client, tracker)
+
+			status := tracker.Status()
+			if !status.Started {
+				t.Fatalf("expected startup to be complete, got %+v", status)
+			}
+			if got := status.Stages[1].State; got != tt.wantState {
+				t.Errorf("expected k8s-connected state %s, got %s", tt.wantState, got)
+			}
+		})
+	}
 }
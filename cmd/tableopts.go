@@ -0,0 +1,27 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the --no-headers and --show-labels options of the deployment table.
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// labelsHeader is the column appended to the deployment table header by --show-labels.
+const labelsHeader = "\tLABELS"
+
+// writeLabelsColumn writes the labels column of a deployment row, e.g. "app=nginx,tier=web",
+// without the line break.
+func writeLabelsColumn(w io.Writer, deployment k8s.DeploymentInfo) error {
+	_, err := fmt.Fprintf(w, "\t%s", formatLabelMap(deployment.Labels, ","))
+	return err
+}
+
+func init() {
+	listDeploymentsCmd.Flags().BoolVar(&listDeploymentsOpts.noHeaders, "no-headers", false,
+		"Don't print the header row of table output")
+	listDeploymentsCmd.Flags().BoolVar(&listDeploymentsOpts.showLabels, "show-labels", false,
+		"Show the labels of each deployment as the last column of table output")
+}
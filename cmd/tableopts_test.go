@@ -0,0 +1,72 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the --no-headers and --show-labels table options.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestTableOptionsDefined verifies that the table options are registered on list deployments.
+func TestTableOptionsDefined(t *testing.T) {
+	for _, name := range []string{"no-headers", "show-labels"} {
+		if listDeploymentsCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+}
+
+// TestTableOptions verifies the rows written with and without headers and labels.
+func TestTableOptions(t *testing.T) {
+	deployment := k8s.DeploymentInfo{
+		Name:   testDeploymentName,
+		Labels: map[string]string{"tier": "web", "app": "nginx"},
+	}
+
+	tests := []struct {
+		name       string
+		noHeaders  bool
+		showLabels bool
+		leading    []string
+		wantFirst  string
+		wantLines  int
+		wantSuffix string
+	}{
+		{"default", false, false, nil, "NAME", 2, "<none>"},
+		{"no headers", true, false, nil, testDeploymentName, 1, "<none>"},
+		{"show labels", false, true, nil, "NAME", 2, "app=nginx,tier=web"},
+		{"leading column", false, true, []string{"EVENT"}, "EVENT", 2, "app=nginx,tier=web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := newTestClientOptions(testNamespaceDefault)
+			opts.noHeaders, opts.showLabels = tt.noHeaders, tt.showLabels
+			var out bytes.Buffer
+			w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+			if err := writeTableHeader(w, opts, tt.leading...); err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.leading) > 0 {
+				_, _ = w.Write([]byte("ADDED\t"))
+			}
+			if err := writeDeploymentRow(w, opts, deployment); err != nil {
+				t.Fatal(err)
+			}
+			flushTableWriter(w)
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != tt.wantLines || !strings.HasPrefix(lines[0], tt.wantFirst) ||
+				!strings.HasSuffix(lines[len(lines)-1], tt.wantSuffix) {
+				t.Errorf("unexpected table:\n%s", out.String())
+			}
+			if tt.showLabels && !strings.HasSuffix(lines[0], "LABELS") && !tt.noHeaders {
+				t.Errorf("expected a LABELS header, got %q", lines[0])
+			}
+		})
+	}
+}
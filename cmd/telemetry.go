@@ -0,0 +1,131 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file wires opt-in usage telemetry into command execution and implements the 'telemetry' command.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/telemetry"
+)
+
+// Telemetry flags, shared by all commands.
+var (
+	// telemetryEnabled aggregates command timings in the local stats file.
+	telemetryEnabled bool
+
+	// telemetryEndpoint receives anonymized command events, e.g. a platform team's collector.
+	telemetryEndpoint string
+)
+
+// telemetrySession measures the running command; nil when telemetry is disabled.
+var telemetrySession *telemetry.Session
+
+// telemetryCmd represents the telemetry command.
+// It serves as a parent command for inspecting local telemetry.
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Inspect opt-in command telemetry",
+	Long: `Inspect the command telemetry collected with --telemetry.
+
+Telemetry is off unless enabled with --telemetry (aggregate locally) or
+--telemetry-endpoint (also send each event to the given URL). Only the command
+name, its duration, whether it succeeded, the CLI version and the platform are
+recorded; arguments, flag values and cluster data never are.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		_ = cmd.Help()
+	},
+}
+
+// telemetryStatsCmd represents the telemetry stats command.
+var telemetryStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show aggregated command timings and failures",
+	Long: `Show how often each command ran, how long it took and how often it failed,
+slowest commands first.
+
+Examples:
+  kc telemetry stats`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runTelemetryStats(os.Stdout); err != nil {
+			log.Error().Err(err).Msg("Failed to show telemetry stats")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// startTelemetry begins measuring the given command if telemetry is enabled.
+func startTelemetry(cmd *cobra.Command) {
+	cfg := telemetry.Config{Endpoint: telemetryEndpoint, Version: Version}
+	if telemetryEnabled {
+		path, err := telemetry.DefaultStatsPath()
+		if err != nil {
+			log.Debug().Err(err).Msg("Local telemetry disabled")
+		}
+		cfg.StatsPath = path
+	}
+
+	telemetrySession = telemetry.Start(cfg, commandName(cmd))
+}
+
+// finishTelemetry records the outcome of the measured command. Failures are only logged at debug
+// level, since telemetry must never get in the way of the command itself.
+func finishTelemetry(success bool) {
+	if err := telemetrySession.Finish(context.Background(), success); err != nil {
+		log.Debug().Err(err).Msg("Failed to record telemetry")
+	}
+	telemetrySession = nil
+}
+
+// exit records telemetry for the running command and terminates the process with the given code.
+func exit(code int) {
+	finishTelemetry(code == 0)
+	os.Exit(code)
+}
+
+// runTelemetryStats prints the aggregated local statistics.
+func runTelemetryStats(out io.Writer) error {
+	path, err := telemetry.DefaultStatsPath()
+	if err != nil {
+		return err
+	}
+	stats, err := telemetry.LoadStats(path)
+	if err != nil {
+		return err
+	}
+	return writeTelemetryStats(out, stats)
+}
+
+// writeTelemetryStats writes statistics as an aligned table.
+func writeTelemetryStats(out io.Writer, stats []telemetry.Stats) error {
+	if len(stats) == 0 {
+		_, err := fmt.Fprintln(out, "No telemetry recorded. Enable it with --telemetry.")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintln(w, "COMMAND\tRUNS\tAVG\tMAX\tFAILURES"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, s := range stats {
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%dms\t%dms\t%d (%.0f%%)\n", s.Command, s.Count,
+			s.AverageMs(), s.MaxMs, s.Failures, s.FailureRate()*100); err != nil {
+			return fmt.Errorf("failed to write stats row: %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryStatsCmd)
+}
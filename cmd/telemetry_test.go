@@ -0,0 +1,79 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the telemetry wiring and the telemetry stats output.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/telemetry"
+)
+
+// TestTelemetryFlagsDefined verifies that the opt-in telemetry flags are global and off by default.
+func TestTelemetryFlagsDefined(t *testing.T) {
+	for _, name := range []string{"telemetry", "telemetry-endpoint"} {
+		flag := rootCmd.PersistentFlags().Lookup(name)
+		if flag == nil {
+			t.Fatalf("expected '%s' persistent flag to be defined", name)
+		}
+		if flag.DefValue != "false" && flag.DefValue != "" {
+			t.Errorf("expected '%s' to be disabled by default, got %q", name, flag.DefValue)
+		}
+	}
+}
+
+// TestStartTelemetry verifies that sessions are only created when opted in and are recorded on finish.
+func TestStartTelemetry(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	defer func() { telemetryEnabled = false }()
+
+	startTelemetry(listDeploymentsCmd)
+	if telemetrySession != nil {
+		t.Fatal("expected no session without opt-in")
+	}
+
+	telemetryEnabled = true
+	startTelemetry(listDeploymentsCmd)
+	if telemetrySession == nil {
+		t.Fatal("expected a session with --telemetry")
+	}
+	finishTelemetry(true)
+
+	path, err := telemetry.DefaultStatsPath()
+	if err != nil {
+		t.Fatalf("DefaultStatsPath() error = %v", err)
+	}
+	stats, err := telemetry.LoadStats(path)
+	if err != nil || len(stats) != 1 || stats[0].Command != "list deployments" {
+		t.Errorf("expected 'list deployments' to be recorded, got %+v (%v)", stats, err)
+	}
+}
+
+// TestWriteTelemetryStats verifies the stats table and the hint shown without data.
+func TestWriteTelemetryStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    []telemetry.Stats
+		contains []string
+	}{
+		{"empty", nil, []string{"No telemetry recorded"}},
+		{"stats", []telemetry.Stats{{Command: "drain", Count: 4, Failures: 1, TotalMs: 8000, MaxMs: 5000}},
+			[]string{"COMMAND", "drain", "2000ms", "5000ms", "1 (25%)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeTelemetryStats(&out, tt.stats); err != nil {
+				t.Fatalf("writeTelemetryStats() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'top' command which shows pod and node resource usage.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+var (
+	// topSortBy selects the sort key of the top commands.
+	topSortBy string
+
+	// topPodsOpts and topNodesOpts are the connection and timeout flags of the top commands.
+	topPodsOpts, topNodesOpts clientOptions
+)
+
+// topCmd represents the top command.
+// It groups the resource usage subcommands.
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Display resource usage of pods and nodes",
+	Long: `Display CPU and memory usage of pods and nodes.
+
+Usage is read from the metrics.k8s.io API, which requires metrics-server
+(or another metrics API provider) to be installed in the cluster.`,
+}
+
+// topPodsCmd represents the top pods command.
+var topPodsCmd = &cobra.Command{
+	Use:     "pods",
+	Aliases: []string{"pod", "po"},
+	Short:   "Display resource usage of pods",
+	Long: `Display CPU and memory usage of pods, summed over their containers.
+
+Examples:
+  kc top pods
+  kc top pods -n web --sort-by memory
+  kc top pods -l app=nginx -o json`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runTopPods(&topPodsOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to get pod metrics")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// topNodesCmd represents the top nodes command.
+var topNodesCmd = &cobra.Command{
+	Use:     "nodes",
+	Aliases: []string{"node", "no"},
+	Short:   "Display resource usage of nodes",
+	Long: `Display CPU and memory usage of nodes, also as a percentage of allocatable resources.
+
+Examples:
+  kc top nodes
+  kc top nodes --sort-by cpu`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runTopNodes(&topNodesOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to get node metrics")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runTopPods fetches, sorts and prints pod usage.
+func runTopPods(opts *clientOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+	opts.applyDefaultNamespace()
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	pods, err := client.ListPodUsage(ctx, opts.namespace, opts.labelSelector)
+	if err != nil {
+		return err
+	}
+	if err := k8s.SortPodUsage(pods, topSortBy); err != nil {
+		return err
+	}
+	return formatTopOutput(os.Stdout, pods, opts.output, func(w io.Writer) error {
+		return writePodUsageTable(w, pods, opts.namespace == "")
+	})
+}
+
+// runTopNodes fetches, sorts and prints node usage.
+func runTopNodes(opts *clientOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return fmt.Errorf("invalid output format: %w", err)
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	nodes, err := client.ListNodeUsage(ctx, opts.labelSelector)
+	if err != nil {
+		return err
+	}
+	if err := k8s.SortNodeUsage(nodes, topSortBy); err != nil {
+		return err
+	}
+	return formatTopOutput(os.Stdout, nodes, opts.output, func(w io.Writer) error {
+		return writeNodeUsageTable(w, nodes)
+	})
+}
+
+// formatTopOutput writes usage items as JSON or YAML, or as a table using writeTable.
+func formatTopOutput[T any](out io.Writer, items []T, format string, writeTable func(io.Writer) error) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case "yaml":
+		data, err := yaml.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		_, err = out.Write(data)
+		return err
+	case "table":
+		if len(items) == 0 {
+			_, err := fmt.Fprintln(out, "No metrics found.")
+			return err
+		}
+		return writeTable(out)
+	default:
+		return newUsageError("unsupported output format: %s", format)
+	}
+}
+
+// writePodUsageTable writes pod usage as an aligned table, with a namespace column if requested.
+func writePodUsageTable(out io.Writer, pods []k8s.PodUsage, withNamespace bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	prefix := func(ns string) string {
+		if withNamespace {
+			return ns + "\t"
+		}
+		return ""
+	}
+
+	if _, err := fmt.Fprintf(w, "%sNAME\tCPU(cores)\tMEMORY(bytes)\n", prefix("NAMESPACE")); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, pod := range pods {
+		if _, err := fmt.Fprintf(w, "%s%s\t%s\t%s\n", prefix(pod.Namespace), pod.Name,
+			formatMilliCPU(pod.CPUMilli), formatMemory(pod.MemoryBytes)); err != nil {
+			return fmt.Errorf("failed to write pod row: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeNodeUsageTable writes node usage as an aligned table.
+func writeNodeUsageTable(out io.Writer, nodes []k8s.NodeUsage) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer flushTableWriter(w)
+
+	if _, err := fmt.Fprintln(w, "NAME\tCPU(cores)\tCPU%\tMEMORY(bytes)\tMEMORY%"); err != nil {
+		return fmt.Errorf("failed to write table header: %w", err)
+	}
+	for _, node := range nodes {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%.0f%%\t%s\t%.0f%%\n", node.Name,
+			formatMilliCPU(node.CPUMilli), node.CPUPercent,
+			formatMemory(node.MemoryBytes), node.MemoryPercent); err != nil {
+			return fmt.Errorf("failed to write node row: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatMilliCPU formats CPU usage in millicores, e.g. "250m".
+func formatMilliCPU(milli int64) string {
+	return fmt.Sprintf("%dm", milli)
+}
+
+// formatMemory formats memory usage in mebibytes, e.g. "128Mi".
+func formatMemory(bytes int64) string {
+	return fmt.Sprintf("%dMi", bytes/(1024*1024))
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.AddCommand(topPodsCmd, topNodesCmd)
+
+	addNamespaceFlags(topPodsCmd, &topPodsOpts)
+
+	for cmd, opts := range map[*cobra.Command]*clientOptions{topPodsCmd: &topPodsOpts, topNodesCmd: &topNodesOpts} {
+		cmd.Flags().StringVarP(&opts.labelSelector, "selector", "l", "",
+			"Label selector to filter objects")
+		cmd.Flags().StringVar(&topSortBy, "sort-by", k8s.SortByName,
+			"Sort by usage (cpu|memory|name)")
+		cmd.Flags().StringVarP(&opts.output, "output", "o", "table",
+			"Output format (table|json|yaml)")
+	}
+	addClientFlags(topPodsCmd, &topPodsOpts, 30)
+	addClientFlags(topNodesCmd, &topNodesOpts, 30)
+}
@@ -0,0 +1,106 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the top command definitions and resource usage formatting.
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestTopCommandsDefined verifies that the top subcommands are registered with the expected flags.
+func TestTopCommandsDefined(t *testing.T) {
+	tests := []struct {
+		cmdName string
+		flags   []string
+	}{
+		{"pods", []string{"namespace", "selector", "sort-by", "output", "kubeconfig", "timeout"}},
+		{"nodes", []string{"selector", "sort-by", "output", "kubeconfig", "timeout"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmdName, func(t *testing.T) {
+			cmd, _, err := topCmd.Find([]string{tt.cmdName})
+			if err != nil || cmd.Name() != tt.cmdName {
+				t.Fatalf("expected top %s subcommand, got %v", tt.cmdName, err)
+			}
+			for _, name := range tt.flags {
+				if cmd.Flags().Lookup(name) == nil {
+					t.Errorf("expected '%s' flag to be defined", name)
+				}
+			}
+		})
+	}
+}
+
+// TestWritePodUsageTable verifies the namespace column and usage formatting of the pods table.
+func TestWritePodUsageTable(t *testing.T) {
+	pods := []k8s.PodUsage{{Namespace: testNamespaceDefault, Name: "web", CPUMilli: 250, MemoryBytes: 128 << 20}}
+
+	tests := []struct {
+		name          string
+		withNamespace bool
+		contains      []string
+		excludes      []string
+	}{
+		{"single namespace", false, []string{"NAME", "web", "250m", "128Mi"}, []string{"NAMESPACE"}},
+		{"all namespaces", true, []string{"NAMESPACE", testNamespaceDefault, "web"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writePodUsageTable(&out, pods, tt.withNamespace); err != nil {
+				t.Fatalf("writePodUsageTable() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(out.String(), unwanted) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unwanted, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestFormatTopOutput verifies all output formats of the top commands.
+func TestFormatTopOutput(t *testing.T) {
+	nodes := []k8s.NodeUsage{{Name: "worker-1", CPUMilli: 500, CPUPercent: 25, MemoryBytes: 1 << 30, MemoryPercent: 50}}
+	writeTable := func(w io.Writer) error { return writeNodeUsageTable(w, nodes) }
+
+	tests := []struct {
+		name     string
+		items    []k8s.NodeUsage
+		format   string
+		contains []string
+		wantErr  bool
+	}{
+		{"table", nodes, "table", []string{"CPU%", "worker-1", "500m", "25%", "1024Mi", "50%"}, false},
+		{"empty table", nil, "table", []string{"No metrics found."}, false},
+		{"json", nodes, "json", []string{`"cpu_millicores": 500`}, false},
+		{"yaml", nodes, "yaml", []string{"memorypercent: 50"}, false},
+		{"unsupported", nodes, "xml", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := formatTopOutput(&out, tt.items, tt.format, writeTable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatTopOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
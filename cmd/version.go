@@ -1,29 +1,150 @@
 // Package cmd contains the CLI commands for the k8s-controller application.
-// This file implements the 'version' command which displays the current version.
+// This file implements the 'version' command which displays the build and server versions.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/buildinfo"
+)
+
+// Build metadata of the application. These values can be overridden at build time using ldflags:
+// go build -ldflags "-X github.com/Searge/k8s-controller/cmd.Version=v1.0.0
+// -X github.com/Searge/k8s-controller/cmd.GitCommit=$(git rev-parse HEAD)
+// -X github.com/Searge/k8s-controller/cmd.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	// Version holds the current version of the application.
+	Version = "dev"
+
+	// GitCommit is the commit the binary was built from. If not set, the VCS revision
+	// recorded by the Go toolchain is used.
+	GitCommit = ""
+
+	// BuildDate is the time the binary was built, in RFC 3339 format. If not set, the time
+	// of the commit recorded by the Go toolchain is used.
+	BuildDate = ""
 )
 
-// Version holds the current version of the application.
-// This value can be overridden at build time using ldflags:
-// go build -ldflags "-X github.com/Searge/k8s-controller/cmd.Version=v1.0.0"
-var Version = "dev"
+// Flags for the version command
+var (
+	// versionServer also queries the version of the Kubernetes API server.
+	versionServer bool
+
+	// versionOpts are the kubeconfig and context flags of version --server.
+	versionOpts clientOptions
+)
+
+// versionInfo is the output of the version command.
+type versionInfo struct {
+	Client buildinfo.Info  `json:"clientVersion" yaml:"clientVersion"`
+	Server *buildinfo.Info `json:"serverVersion,omitempty" yaml:"serverVersion,omitempty"`
+}
 
 // versionCmd represents the version command.
-// It displays the current version of the k8s-controller application.
+// It displays the build metadata of k8s-controller and optionally the Kubernetes server version.
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number",
-	Long:  `Print the version number of k8s-controller`,
+	Long: `Print the version number of k8s-controller with the commit, build date,
+Go version and platform it was built for. With --server, the version and platform
+of the Kubernetes API server are printed as well.
+
+Examples:
+  kc version
+  kc version --server --context prod
+  kc version -o json`,
+	Args: cobra.NoArgs,
 	Run: func(_ *cobra.Command, _ []string) {
-		fmt.Printf("k8s-controller version %s\n", Version)
+		if err := runVersion(os.Stdout, &versionOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to print version")
+			exit(exitCode(err))
+		}
 	},
 }
 
+// runVersion prints the build metadata, and the server version with --server, in the selected output format.
+func runVersion(out io.Writer, opts *clientOptions) error {
+	if err := validateOutputFormat(opts.output); err != nil {
+		return err
+	}
+
+	info := versionInfo{Client: currentBuildInfo()}
+	if versionServer {
+		client, err := opts.newClient()
+		if err != nil {
+			return err
+		}
+		defer closeClient(client)
+
+		server, err := client.ServerVersion()
+		if err != nil {
+			return err
+		}
+		serverInfo := buildinfo.FromKubernetes(server)
+		info.Server = &serverInfo
+	}
+
+	switch opts.output {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(info)
+	}
+	return writeVersion(out, info)
+}
+
+// writeVersion prints the version information for humans.
+func writeVersion(out io.Writer, info versionInfo) error {
+	if _, err := fmt.Fprintf(out, "k8s-controller version %s\n", info.Client.Version); err != nil {
+		return err
+	}
+	w, flush := newTableWriter(out, "")
+	defer flush()
+	rows := [][2]string{
+		{"Git commit", info.Client.GitCommit},
+		{"Build date", info.Client.BuildDate},
+		{"Go version", info.Client.GoVersion},
+		{"Platform", info.Client.Platform},
+	}
+	if info.Server != nil {
+		rows = append(rows, [2]string{"Server version", info.Server.Version},
+			[2]string{"Server platform", info.Server.Platform})
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintf(w, "  %s:\t%s\n", row[0], row[1]); err != nil {
+			return fmt.Errorf("failed to write version: %w", err)
+		}
+	}
+	return nil
+}
+
+// currentBuildInfo returns the build metadata of the running binary. Values not injected via ldflags
+// are taken from the build information recorded by the Go toolchain, or reported as unknown.
+func currentBuildInfo() buildinfo.Info {
+	return buildinfo.Current(Version, GitCommit, BuildDate)
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().BoolVar(&versionServer, "server", false,
+		"Also print the version and platform of the Kubernetes API server")
+	versionCmd.Flags().StringVarP(&versionOpts.output, "output", "o", "table",
+		"Output format (table|json|yaml)")
+
+	// --server selects the server version here, so the connection flags are registered one by one.
+	completionCommands[versionCmd] = &versionOpts
+	versionCmd.Flags().StringVar(&versionOpts.kubeconfigPath, "kubeconfig", "",
+		"Path to kubeconfig file, or a list of files to merge (default: $KUBECONFIG or $HOME/.kube/config)")
+	versionCmd.Flags().StringVar(&versionOpts.contextName, "context", "",
+		"Kubernetes context to use (default: current context from kubeconfig)")
 }
@@ -1,8 +1,13 @@
 // Package cmd contains tests for the CLI commands.
-// This file tests the serve command definition, flag configuration, and validation logic.
+// This file tests the serve command definition, flag configuration, and validation logic,
+// and the version command.
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -52,3 +57,69 @@ func TestValidatePort(t *testing.T) {
 		})
 	}
 }
+
+// TestCurrentBuildInfo verifies that build metadata injected via ldflags is reported.
+func TestCurrentBuildInfo(t *testing.T) {
+	defer func() { GitCommit, BuildDate = "", "" }()
+	GitCommit, BuildDate = "abc123", "2026-01-02T03:04:05Z"
+
+	info := currentBuildInfo()
+	if info.Version != Version || info.GitCommit != "abc123" || info.BuildDate != "2026-01-02T03:04:05Z" ||
+		info.GoVersion != runtime.Version() || info.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("unexpected build info %+v", info)
+	}
+}
+
+// TestRunVersion verifies the output formats with and without the server version.
+func TestRunVersion(t *testing.T) {
+	demoMode = true
+	defer func() { demoMode, versionServer = false, false }()
+
+	tests := []struct {
+		name       string
+		server     bool
+		format     string
+		wantServer bool
+		wantErr    bool
+	}{
+		{"client table", false, "table", false, false},
+		{"server table", true, "table", true, false},
+		{"client json", false, "json", false, false},
+		{"server json", true, "json", true, false},
+		{"invalid format", false, "xml", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versionServer = tt.server
+			opts := newTestClientOptions("")
+			opts.output = tt.format
+
+			var out bytes.Buffer
+			err := runVersion(&out, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.format == "json" {
+				var info versionInfo
+				if err := json.Unmarshal(out.Bytes(), &info); err != nil {
+					t.Fatalf("invalid JSON: %v", err)
+				}
+				if info.Client.Version != Version || (info.Server != nil) != tt.wantServer ||
+					(tt.wantServer && info.Server.Version != "v1.35.0") {
+					t.Errorf("unexpected version info %+v", info)
+				}
+				return
+			}
+			if !strings.HasPrefix(out.String(), "k8s-controller version "+Version+"\n") ||
+				!strings.Contains(out.String(), "Go version:") ||
+				strings.Contains(out.String(), "v1.35.0") != tt.wantServer {
+				t.Errorf("unexpected output:\n%s", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'wait' command which blocks until an object meets a condition.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Flags of the wait command.
+var (
+	// waitFor is the condition to wait for, in kubectl syntax.
+	waitFor string
+
+	// waitTimeout bounds how long the wait command waits.
+	waitTimeout time.Duration
+
+	// waitOpts are the namespace and connection flags of the wait command.
+	waitOpts clientOptions
+)
+
+// waitCmd represents the wait command.
+var waitCmd = &cobra.Command{
+	Use:   "wait (TYPE/NAME | TYPE NAME) --for=CONDITION",
+	Short: "Wait for a condition on an object",
+	Long: `Wait until an object meets a condition, is deleted or matches a JSONPath expression.
+
+Conditions:
+  condition=TYPE[=STATUS]   Status condition, e.g. condition=Available or condition=Ready=False
+  delete                    The object no longer exists
+  jsonpath={PATH}[=VALUE]   JSONPath result equals VALUE, or is non-empty without VALUE
+
+Objects that don't exist yet are waited for. Exits with status 1 if the timeout passes first.
+
+Examples:
+  kc wait deployment/nginx --for=condition=Available --timeout=2m
+  kc wait pod web-0 -n shop --for=condition=Ready
+  kc wait deploy/nginx --for=jsonpath='{.status.readyReplicas}'=3
+  kc wait namespace/old --for=delete --timeout=5m`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeResourceArgs(&waitOpts),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := runWait(os.Stdout, &waitOpts, args); err != nil {
+			log.Error().Err(err).Msg("Wait failed")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runWait waits for the condition on the referenced object and reports when it is met.
+func runWait(out io.Writer, opts *clientOptions, args []string) error {
+	if waitFor == "" {
+		return newUsageError("--for is required")
+	}
+	condition, err := k8s.ParseWaitCondition(waitFor)
+	if err != nil {
+		return err
+	}
+	info, name, err := parseResourceArgs(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	stop := startProgress(fmt.Sprintf("Waiting for %s/%s (%s)", info.QualifiedName(), name, waitFor))
+	_, err = client.WaitForCondition(context.Background(), info.GVR, resolveNamespace(info, opts.namespace), name,
+		condition, waitTimeout)
+	stop()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s/%s condition met\n", info.QualifiedName(), name)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+
+	waitCmd.Flags().StringVar(&waitFor, "for", "",
+		"Condition to wait for: condition=TYPE[=STATUS], delete or jsonpath={PATH}[=VALUE] (required)")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 30*time.Second,
+		"How long to wait before giving up")
+	waitCmd.Flags().StringVarP(&waitOpts.namespace, "namespace", "n", "",
+		"Kubernetes namespace (default: default)")
+
+	addConnectionFlags(waitCmd, &waitOpts)
+}
@@ -0,0 +1,46 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the wait command definition.
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWaitCommandDefined verifies that the wait command is registered with a duration timeout.
+func TestWaitCommandDefined(t *testing.T) {
+	for _, name := range []string{"for", "timeout", "namespace", "kubeconfig", "context"} {
+		if waitCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined", name)
+		}
+	}
+
+	timeout, err := waitCmd.Flags().GetDuration("timeout")
+	if err != nil || timeout != 30*time.Second {
+		t.Errorf("expected a 30s duration timeout, got %v (%v)", timeout, err)
+	}
+}
+
+// TestRunWaitValidation verifies that invalid arguments fail before connecting.
+func TestRunWaitValidation(t *testing.T) {
+	defer func() { waitFor = "" }()
+
+	tests := []struct {
+		name      string
+		condition string
+		args      []string
+	}{
+		{"missing condition", "", []string{"deployment/nginx"}},
+		{"invalid condition", "ready", []string{"deployment/nginx"}},
+		{"unknown resource", "delete", []string{"widget/nginx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waitFor = tt.condition
+			if err := runWait(nil, newTestClientOptions(""), tt.args); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
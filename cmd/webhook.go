@@ -0,0 +1,168 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the 'webhook' command group and the serving certificate flags.
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/Searge/k8s-controller/pkg/certs"
+)
+
+// Serving certificate flags, shared by 'serve' and 'webhook bootstrap'.
+var (
+	// certDir is the directory holding tls.crt, tls.key and ca.crt.
+	// If empty, the server serves plain HTTP.
+	certDir string
+
+	// certMode selects how the serving certificate is provisioned (self-signed or cert-manager).
+	certMode string
+
+	// certService is the name of the Service in front of the webhook server.
+	certService string
+
+	// certNamespace is the namespace of the Service in front of the webhook server.
+	certNamespace string
+)
+
+var (
+	// webhookConfigName is the name of the webhook configurations to inject the CA bundle into.
+	webhookConfigName string
+
+	// webhookBootstrapOpts are the connection and timeout flags of webhook bootstrap.
+	webhookBootstrapOpts clientOptions
+)
+
+// webhookCmd groups commands for operating the admission webhook server.
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Manage the admission webhook server",
+	Long: `Manage the admission webhook server and its serving certificates.
+
+Run 'serve' with --cert-dir to serve the webhook endpoints over HTTPS.`,
+}
+
+// webhookBootstrapCmd provisions the serving certificate and injects its CA bundle.
+var webhookBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Provision serving certificates and inject the CA bundle",
+	Long: `Provision the webhook serving certificate and inject its CA bundle into the
+validating and mutating webhook configurations with the given name.
+
+In self-signed mode a CA and serving certificate are generated into --cert-dir
+unless a valid one already exists there. In cert-manager mode the certificate
+issued by cert-manager must already be present in --cert-dir (e.g. a mounted
+Secret) and only its ca.crt is injected. --dry-run only simulates the update of the
+webhook configurations; the certificate is still provisioned.
+
+Examples:
+  kc webhook bootstrap --webhook-config=k8s-controller --cert-dir=/tmp/certs \
+    --cert-service=k8s-controller --cert-namespace=kube-system
+  kc webhook bootstrap --webhook-config=k8s-controller --cert-dir=/certs --cert-mode=cert-manager`,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := runWebhookBootstrap(&webhookBootstrapOpts); err != nil {
+			log.Error().Err(err).Msg("Failed to bootstrap webhook")
+			exit(exitCode(err))
+		}
+	},
+}
+
+// runWebhookBootstrap ensures the serving certificate exists and injects its CA bundle.
+func runWebhookBootstrap(opts *clientOptions) error {
+	if webhookConfigName == "" {
+		return newUsageError("--webhook-config is required")
+	}
+
+	bundle, err := ensureServingCerts()
+	if err != nil {
+		return err
+	}
+
+	client, err := opts.newClient()
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+
+	updated, err := client.InjectCABundle(ctx, webhookConfigName, bundle.CA)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Injected CA bundle into %d webhook configuration(s) named %q%s\n", updated, webhookConfigName,
+		opts.dryRunSuffix())
+	return nil
+}
+
+// ensureServingCerts provisions the serving certificate in certDir according to certMode.
+func ensureServingCerts() (*certs.Bundle, error) {
+	if certDir == "" {
+		return nil, newUsageError("--cert-dir is required")
+	}
+
+	mode, err := certs.ParseMode(certMode)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := certs.Options{Service: certService, Namespace: certNamespace}
+	bundle, err := certs.Ensure(certDir, mode, opts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(bundle.CA) == 0 {
+		return nil, fmt.Errorf("no %s found in %s", certs.CAFile, certDir)
+	}
+	return bundle, nil
+}
+
+// servingTLSConfig provisions the serving certificate and returns a TLS configuration
+// that reloads it when the files are rotated. It returns nil if no certificate directory is set.
+func servingTLSConfig(ctx context.Context) (*tls.Config, error) {
+	if certDir == "" {
+		return nil, nil
+	}
+
+	if _, err := ensureServingCerts(); err != nil {
+		return nil, err
+	}
+
+	reloader, err := certs.NewReloader(certDir, log.Logger)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.Watch(ctx, certs.DefaultReloadInterval)
+
+	return reloader.TLSConfig(), nil
+}
+
+// addCertFlags registers the serving certificate flags on a command.
+func addCertFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&certDir, "cert-dir", "",
+		"Directory with tls.crt, tls.key and ca.crt for serving HTTPS")
+	cmd.Flags().StringVar(&certMode, "cert-mode", string(certs.ModeSelfSigned),
+		"How serving certificates are provisioned (self-signed, cert-manager)")
+	cmd.Flags().StringVar(&certService, "cert-service", "k8s-controller",
+		"Service name used for the DNS names of self-signed certificates")
+	cmd.Flags().StringVar(&certNamespace, "cert-namespace", "default",
+		"Service namespace used for the DNS names of self-signed certificates")
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(webhookBootstrapCmd)
+
+	webhookBootstrapCmd.Flags().StringVar(&webhookConfigName, "webhook-config", "",
+		"Name of the validating/mutating webhook configurations to inject the CA bundle into")
+	addCertFlags(webhookBootstrapCmd)
+	addClientFlags(webhookBootstrapCmd, &webhookBootstrapOpts, 30)
+	addDryRunFlag(webhookBootstrapCmd, &webhookBootstrapOpts)
+}
@@ -0,0 +1,57 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the webhook commands and serving certificate setup.
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Searge/k8s-controller/pkg/certs"
+)
+
+// TestWebhookCommandDefined verifies that the webhook bootstrap command is registered with the expected flags.
+func TestWebhookCommandDefined(t *testing.T) {
+	if webhookBootstrapCmd.Parent() != webhookCmd {
+		t.Fatal("bootstrap should be a subcommand of webhook")
+	}
+
+	flags := []string{"webhook-config", "cert-dir", "cert-mode", "cert-service", "cert-namespace", "dry-run"}
+	for _, name := range flags {
+		if webhookBootstrapCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined on webhook bootstrap", name)
+		}
+	}
+	for _, name := range []string{"cert-dir", "cert-mode"} {
+		if serveCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected '%s' flag to be defined on serve", name)
+		}
+	}
+}
+
+// TestServingTLSConfig verifies certificate provisioning for the serve command.
+func TestServingTLSConfig(t *testing.T) {
+	defer func(dir, mode string) { certDir, certMode = dir, mode }(certDir, certMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	certDir = ""
+	if cfg, err := servingTLSConfig(ctx); cfg != nil || err != nil {
+		t.Errorf("expected plain HTTP without --cert-dir, got %v, %v", cfg, err)
+	}
+
+	certDir = filepath.Join(t.TempDir(), "certs")
+	certMode = string(certs.ModeCertManager)
+	if _, err := servingTLSConfig(ctx); err == nil {
+		t.Error("expected error in cert-manager mode without an issued certificate")
+	}
+
+	certMode = string(certs.ModeSelfSigned)
+	cfg, err := servingTLSConfig(ctx)
+	if err != nil || cfg == nil {
+		t.Fatalf("servingTLSConfig() = %v, %v", cfg, err)
+	}
+	if cert, err := cfg.GetCertificate(nil); err != nil || cert == nil {
+		t.Errorf("GetCertificate() = %v, %v", cert, err)
+	}
+}
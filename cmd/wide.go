@@ -0,0 +1,61 @@
+// Package cmd contains the CLI commands for the k8s-controller application.
+// This file implements the wide table output of deployments.
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// outputWide is the output format adding detail columns to the deployment table.
+const outputWide = "wide"
+
+// wideDeploymentHeader are the columns appended to the deployment table header by the wide output.
+const wideDeploymentHeader = "\tSTRATEGY\tSELECTOR\tCONDITIONS"
+
+// writeWideDeploymentColumns writes the wide columns of a deployment row, without the line break.
+func writeWideDeploymentColumns(w io.Writer, deployment k8s.DeploymentInfo) error {
+	_, err := fmt.Fprintf(w, "\t%s\t%s\t%s", formatStrategy(deployment.Strategy),
+		valueOrNone(deployment.Selector), formatConditions(deployment.Conditions))
+	return err
+}
+
+// formatStrategy summarizes a deployment strategy, e.g. "RollingUpdate (25% surge, 0 unavailable)".
+func formatStrategy(strategy k8s.DeploymentStrategy) string {
+	if strategy.Type == "" {
+		return "<none>"
+	}
+	if strategy.MaxSurge == "" && strategy.MaxUnavailable == "" {
+		return strategy.Type
+	}
+	return fmt.Sprintf("%s (%s surge, %s unavailable)", strategy.Type,
+		valueOrNone(strategy.MaxSurge), valueOrNone(strategy.MaxUnavailable))
+}
+
+// formatConditions summarizes conditions as TYPE=STATUS pairs, adding the reason of conditions
+// that are not true, e.g. "Available=True,Progressing=False(ProgressDeadlineExceeded)".
+func formatConditions(conditions []k8s.DeploymentCondition) string {
+	if len(conditions) == 0 {
+		return "<none>"
+	}
+	parts := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		part := condition.Type + "=" + condition.Status
+		if condition.Status != "True" && condition.Reason != "" {
+			part += "(" + condition.Reason + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
+
+// valueOrNone returns value, or "<none>" if it is empty.
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
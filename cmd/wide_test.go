@@ -0,0 +1,75 @@
+// Package cmd contains tests for the CLI commands.
+// This file tests the wide table output of deployments.
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestWideDeploymentTable verifies the extra columns of the wide deployment table.
+func TestWideDeploymentTable(t *testing.T) {
+	opts := &clientOptions{namespace: testNamespaceDefault, output: outputWide}
+	if err := validateListParameters(opts); err != nil {
+		t.Fatalf("expected wide output to be accepted, got %v", err)
+	}
+
+	deployment := k8s.DeploymentInfo{
+		Name:       testDeploymentName,
+		Selector:   "app=nginx",
+		Strategy:   k8s.DeploymentStrategy{Type: "Recreate"},
+		Conditions: []k8s.DeploymentCondition{{Type: "Available", Status: "True"}},
+	}
+	var out bytes.Buffer
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	if err := writeTableHeader(w, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDeploymentRow(w, opts, deployment); err != nil {
+		t.Fatal(err)
+	}
+	flushTableWriter(w)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "STRATEGY  SELECTOR   CONDITIONS") ||
+		!strings.HasSuffix(lines[1], "Recreate  app=nginx  Available=True") {
+		t.Errorf("unexpected wide table:\n%s", out.String())
+	}
+}
+
+// TestFormatStrategy verifies the strategy summaries.
+func TestFormatStrategy(t *testing.T) {
+	tests := []struct {
+		strategy k8s.DeploymentStrategy
+		want     string
+	}{
+		{k8s.DeploymentStrategy{}, "<none>"},
+		{k8s.DeploymentStrategy{Type: "Recreate"}, "Recreate"},
+		{k8s.DeploymentStrategy{Type: "RollingUpdate", MaxSurge: "1"}, "RollingUpdate (1 surge, <none> unavailable)"},
+	}
+
+	for _, tt := range tests {
+		if got := formatStrategy(tt.strategy); got != tt.want {
+			t.Errorf("formatStrategy(%+v) = %q, want %q", tt.strategy, got, tt.want)
+		}
+	}
+}
+
+// TestFormatConditions verifies that conditions which are not true include their reason.
+func TestFormatConditions(t *testing.T) {
+	conditions := []k8s.DeploymentCondition{
+		{Type: "Available", Status: "True", Reason: "MinimumReplicasAvailable"},
+		{Type: "Progressing", Status: "False", Reason: "ProgressDeadlineExceeded"},
+	}
+	want := "Available=True,Progressing=False(ProgressDeadlineExceeded)"
+	if got := formatConditions(conditions); got != want {
+		t.Errorf("formatConditions() = %q, want %q", got, want)
+	}
+	if got := formatConditions(nil); got != "<none>" {
+		t.Errorf("expected <none> without conditions, got %q", got)
+	}
+}
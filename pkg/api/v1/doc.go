@@ -0,0 +1,14 @@
+// Package apiv1 contains the protobuf messages and the gRPC service of the cluster API, version 1, which
+// the serve command serves with --grpc-listen, and the gateway serving the same service as REST JSON
+// endpoints under /api/v2/. Other Go services consume it with the generated client:
+//
+//	conn, err := grpc.NewClient("kc:9090", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	...
+//	deployments, err := apiv1.NewClusterServiceClient(conn).ListDeployments(ctx, &apiv1.ListDeploymentsRequest{})
+//
+// The code is generated from cluster.proto with protoc-gen-go and protoc-gen-go-grpc, and the gateway with
+// protoc-gen-grpc-gateway from the HTTP mapping in gateway.yaml, so that both surfaces stay in sync.
+package apiv1
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. cluster.proto
+//go:generate protoc --grpc-gateway_out=paths=source_relative,grpc_api_configuration=gateway.yaml:. cluster.proto
@@ -0,0 +1,91 @@
+// Package authz provides a pluggable authorization hook for mutating operations.
+// Every mutating operation builds a Change describing what it is about to do and asks
+// an Authorizer for a Decision before touching the cluster, enabling integration with
+// central change-control systems.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDenied is returned (wrapped) when an Authorizer rejects a planned change.
+var ErrDenied = errors.New("change denied by authorization hook")
+
+// Change describes a planned mutating operation.
+type Change struct {
+	// Operation is the verb being performed (e.g. "patch", "delete", "scale").
+	Operation string `json:"operation"`
+
+	// Resource is the resource type being changed (e.g. "deployments").
+	Resource string `json:"resource"`
+
+	// Namespace is the namespace of the target object. Empty for cluster-scoped objects.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the name of the target object.
+	Name string `json:"name"`
+
+	// Details carries operation-specific information such as the patch body or new replica count.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Decision is the verdict returned by an Authorizer.
+type Decision struct {
+	// Allowed reports whether the change may proceed.
+	Allowed bool `json:"allowed"`
+
+	// Reason explains the decision and is shown to the user when a change is denied.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Authorizer decides whether a planned change may be executed.
+type Authorizer interface {
+	Authorize(ctx context.Context, change Change) (Decision, error)
+}
+
+// AllowAll is an Authorizer that permits every change.
+type AllowAll struct{}
+
+// Authorize always allows the change.
+func (AllowAll) Authorize(_ context.Context, _ Change) (Decision, error) {
+	return Decision{Allowed: true}, nil
+}
+
+// Check consults the authorizer about a change and converts a denial into an error.
+// A nil authorizer allows everything, so callers don't need to special-case unconfigured hooks.
+// Errors from the authorizer itself are returned as-is, meaning hooks fail closed.
+func Check(ctx context.Context, authorizer Authorizer, change Change) error {
+	if authorizer == nil {
+		return nil
+	}
+
+	decision, err := authorizer.Authorize(ctx, change)
+	if err != nil {
+		return fmt.Errorf("authorization hook failed: %w", err)
+	}
+
+	if !decision.Allowed {
+		return fmt.Errorf("%w: %s %s %s",
+			ErrDenied, change.Operation, describeTarget(change), reasonOrDefault(decision.Reason))
+	}
+
+	return nil
+}
+
+// describeTarget formats the target object of a change as resource/name or namespace/resource/name.
+func describeTarget(change Change) string {
+	if change.Namespace == "" {
+		return fmt.Sprintf("%s/%s", change.Resource, change.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", change.Namespace, change.Resource, change.Name)
+}
+
+// reasonOrDefault returns the decision reason, or a placeholder when the hook gave none.
+func reasonOrDefault(reason string) string {
+	if reason == "" {
+		return "(no reason given)"
+	}
+	return "(" + reason + ")"
+}
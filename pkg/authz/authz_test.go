@@ -0,0 +1,134 @@
+// Package authz contains tests for the authorization hook.
+// This file tests decision handling and the HTTP-backed authorizer.
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test constants to avoid string duplication
+const (
+	testOperation = "patch"
+	testResource  = "deployments"
+	testNamespace = "default"
+	testName      = "nginx"
+)
+
+// staticAuthorizer returns a fixed decision or error.
+type staticAuthorizer struct {
+	decision Decision
+	err      error
+}
+
+// Authorize returns the configured decision and error.
+func (s staticAuthorizer) Authorize(_ context.Context, _ Change) (Decision, error) {
+	return s.decision, s.err
+}
+
+// testChange returns a change used across tests.
+func testChange() Change {
+	return Change{
+		Operation: testOperation,
+		Resource:  testResource,
+		Namespace: testNamespace,
+		Name:      testName,
+	}
+}
+
+// TestCheck verifies how Check converts authorizer results into errors.
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		authorizer Authorizer
+		wantErr    bool
+		wantDenied bool
+	}{
+		{"nil authorizer allows", nil, false, false},
+		{"allow all", AllowAll{}, false, false},
+		{"explicit allow", staticAuthorizer{decision: Decision{Allowed: true}}, false, false},
+		{"deny", staticAuthorizer{decision: Decision{Reason: "change freeze"}}, true, true},
+		{"hook error fails closed", staticAuthorizer{err: errors.New("boom")}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(context.Background(), tt.authorizer, testChange())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrDenied) != tt.wantDenied {
+				t.Errorf("errors.Is(err, ErrDenied) = %v, want %v", errors.Is(err, ErrDenied), tt.wantDenied)
+			}
+		})
+	}
+}
+
+// TestCheckDeniedMessage verifies that the denial reason is surfaced to the user.
+func TestCheckDeniedMessage(t *testing.T) {
+	err := Check(context.Background(), staticAuthorizer{decision: Decision{Reason: "change freeze"}}, testChange())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "change freeze") {
+		t.Errorf("expected error to contain reason, got %q", err.Error())
+	}
+	if !strings.Contains(err.Error(), "default/deployments/nginx") {
+		t.Errorf("expected error to contain target, got %q", err.Error())
+	}
+}
+
+// TestHTTPAuthorizer verifies the HTTP authorizer against a test endpoint.
+func TestHTTPAuthorizer(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantAllowed bool
+		wantErr     bool
+	}{
+		{"allowed", http.StatusOK, `{"allowed":true}`, true, false},
+		{"denied", http.StatusOK, `{"allowed":false,"reason":"no"}`, false, false},
+		{"server error", http.StatusInternalServerError, ``, false, true},
+		{"malformed body", http.StatusOK, `not-json`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newDecisionServer(t, tt.status, tt.body)
+			defer server.Close()
+
+			authorizer := NewHTTPAuthorizer(server.URL, time.Second)
+			decision, err := authorizer.Authorize(context.Background(), testChange())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("Authorize() allowed = %v, want %v", decision.Allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+// newDecisionServer starts a test endpoint that validates the posted change and replies with a fixed response.
+func newDecisionServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var change Change
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			t.Errorf("failed to decode posted change: %v", err)
+		}
+		if change.Operation != testOperation || change.Name != testName {
+			t.Errorf("unexpected change posted: %+v", change)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+}
@@ -0,0 +1,70 @@
+// Package authz provides a pluggable authorization hook for mutating operations.
+// This file implements an Authorizer that delegates decisions to an external HTTP endpoint.
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxDecisionBytes limits how much of the endpoint's response body is read.
+const maxDecisionBytes = 64 * 1024
+
+// HTTPAuthorizer asks an external HTTP endpoint whether a change is allowed.
+// The Change is POSTed as JSON and the endpoint must answer 200 OK with a JSON Decision.
+// Any other status code, transport error or malformed body is treated as a failure,
+// which Check turns into a refusal (fail closed).
+type HTTPAuthorizer struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPAuthorizer creates an HTTPAuthorizer for the given endpoint URL.
+// The timeout bounds each authorization request.
+func NewHTTPAuthorizer(url string, timeout time.Duration) *HTTPAuthorizer {
+	return &HTTPAuthorizer{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Authorize sends the change to the endpoint and returns its decision.
+func (a *HTTPAuthorizer) Authorize(ctx context.Context, change Change) (Decision, error) {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to encode change: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to build authorization request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("authorization request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return decodeDecision(resp)
+}
+
+// decodeDecision validates the endpoint response and parses the Decision from its body.
+func decodeDecision(resp *http.Response) (Decision, error) {
+	if resp.StatusCode != http.StatusOK {
+		return Decision{}, fmt.Errorf("authorization endpoint returned status %d", resp.StatusCode)
+	}
+
+	var decision Decision
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDecisionBytes)).Decode(&decision); err != nil {
+		return Decision{}, fmt.Errorf("failed to decode authorization decision: %w", err)
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,63 @@
+// Package buildinfo describes the build of the k8s-controller binary and of the Kubernetes API server.
+// It is shared by the version command and the /version endpoint.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// unknown is reported for build metadata that is neither injected nor recorded by the Go toolchain.
+const unknown = "unknown"
+
+// Info describes the build of the k8s-controller binary or of the Kubernetes API server.
+type Info struct {
+	Version   string `json:"version" yaml:"version" doc:"Version, e.g. v1.2.0, or dev for local builds"`
+	GitCommit string `json:"gitCommit" yaml:"gitCommit" doc:"Commit the binary was built from"`
+	BuildDate string `json:"buildDate" yaml:"buildDate" doc:"Time of the build in RFC 3339 format"`
+	GoVersion string `json:"goVersion" yaml:"goVersion" doc:"Go toolchain of the build"`
+	Platform  string `json:"platform" yaml:"platform" doc:"Operating system and architecture, e.g. linux/amd64"`
+}
+
+// Current returns the build metadata of the running binary, given the values injected via ldflags.
+// Values not injected are taken from the build information recorded by the Go toolchain, or
+// reported as unknown.
+func Current(appVersion, gitCommit, buildDate string) Info {
+	info := Info{
+		Version:   appVersion,
+		GitCommit: gitCommit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.GitCommit == "":
+				info.GitCommit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	if info.GitCommit == "" {
+		info.GitCommit = unknown
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = unknown
+	}
+	return info
+}
+
+// FromKubernetes converts the version reported by a Kubernetes API server.
+func FromKubernetes(server *version.Info) Info {
+	return Info{
+		Version:   server.GitVersion,
+		GitCommit: server.GitCommit,
+		BuildDate: server.BuildDate,
+		GoVersion: server.GoVersion,
+		Platform:  server.Platform,
+	}
+}
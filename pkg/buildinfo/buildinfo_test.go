@@ -0,0 +1,49 @@
+// Package buildinfo contains tests for the build metadata.
+// This file tests reading the build of the binary and converting the Kubernetes version.
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
+)
+
+// TestCurrent verifies that injected build metadata is reported and missing values are filled in.
+func TestCurrent(t *testing.T) {
+	tests := []struct {
+		name      string
+		gitCommit string
+		buildDate string
+	}{
+		{"injected", "abc123", "2026-01-02T03:04:05Z"},
+		{"not injected", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Current("v1.2.0", tt.gitCommit, tt.buildDate)
+			if info.Version != "v1.2.0" || info.GoVersion != runtime.Version() ||
+				info.Platform != runtime.GOOS+"/"+runtime.GOARCH {
+				t.Errorf("unexpected build info %+v", info)
+			}
+			if info.GitCommit == "" || info.BuildDate == "" {
+				t.Errorf("expected the commit and build date filled in, got %+v", info)
+			}
+			if tt.gitCommit != "" && (info.GitCommit != tt.gitCommit || info.BuildDate != tt.buildDate) {
+				t.Errorf("expected the injected commit and build date, got %+v", info)
+			}
+		})
+	}
+}
+
+// TestFromKubernetes verifies the conversion of the Kubernetes version.
+func TestFromKubernetes(t *testing.T) {
+	info := FromKubernetes(&version.Info{GitVersion: "v1.35.0", GitCommit: "def456", BuildDate: "2026-01-01",
+		GoVersion: "go1.25.5", Platform: "linux/arm64"})
+	want := Info{Version: "v1.35.0", GitCommit: "def456", BuildDate: "2026-01-01", GoVersion: "go1.25.5",
+		Platform: "linux/arm64"}
+	if info != want {
+		t.Errorf("FromKubernetes() = %+v, want %+v", info, want)
+	}
+}
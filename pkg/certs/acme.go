@@ -0,0 +1,38 @@
+// Package certs manages the serving certificates of the webhook server.
+// This file implements certificates obtained and renewed via ACME, e.g. from Let's Encrypt.
+package certs
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ACMETLSConfig returns a TLS configuration obtaining certificates for hosts from Let's Encrypt on
+// the first handshake naming them, and renewing them before they expire. Certificates and the ACME
+// account key are stored in cacheDir, so that restarts do not request new certificates and run into
+// the rate limits of Let's Encrypt. Domain ownership is proven with the tls-alpn-01 challenge, which
+// is answered by the configuration itself: the server must be reachable on port 443 of each host.
+func ACMETLSConfig(hosts []string, cacheDir string) (*tls.Config, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("no ACME hosts given")
+	}
+	for _, host := range hosts {
+		if host == "" || strings.ContainsAny(host, ":/ ") {
+			return nil, fmt.Errorf("invalid ACME host %q, expected a DNS name", host)
+		}
+	}
+	if cacheDir == "" {
+		return nil, errors.New("no ACME cache directory given")
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+	return manager.TLSConfig(), nil
+}
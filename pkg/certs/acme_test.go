@@ -0,0 +1,51 @@
+// Package certs contains tests for serving certificate management.
+// This file tests certificates obtained via ACME.
+package certs
+
+import (
+	"crypto/tls"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/acme"
+)
+
+// TestACMETLSConfig verifies the validation of the ACME options and that only the given hosts are served.
+func TestACMETLSConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    []string
+		cacheDir string
+		wantErr  string
+	}{
+		{"valid", []string{"kc.example.com", "api.example.com"}, t.TempDir(), ""},
+		{"no hosts", nil, t.TempDir(), "no ACME hosts"},
+		{"host with port", []string{"kc.example.com:443"}, t.TempDir(), "invalid ACME host"},
+		{"URL as host", []string{"https://kc.example.com"}, t.TempDir(), "invalid ACME host"},
+		{"no cache directory", []string{"kc.example.com"}, "", "no ACME cache directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ACMETLSConfig(tt.hosts, tt.cacheDir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ACMETLSConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ACMETLSConfig() error = %v", err)
+			}
+			if !slices.Contains(config.NextProtos, acme.ALPNProto) {
+				t.Errorf("expected the %s protocol for tls-alpn-01 challenges, got %v",
+					acme.ALPNProto, config.NextProtos)
+			}
+			// Hosts not given are rejected before contacting the ACME server.
+			if _, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.example.com"}); err == nil {
+				t.Error("expected no certificate for a host not given")
+			}
+		})
+	}
+}
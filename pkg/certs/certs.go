@@ -0,0 +1,177 @@
+// Package certs manages the serving certificates of the webhook server.
+// It generates self-signed certificate bundles and reloads rotated certificates
+// from disk. Their CA bundle is injected into webhook configurations by k8s.Client.
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File names inside a certificate directory. They match the keys of the
+// kubernetes.io/tls Secrets written by cert-manager, so a mounted Secret can be used as is.
+const (
+	CertFile = "tls.crt"
+	KeyFile  = "tls.key"
+	CAFile   = "ca.crt"
+)
+
+// DefaultValidity is the lifetime of generated serving certificates.
+const DefaultValidity = 365 * 24 * time.Hour
+
+// Options describes the serving certificate to generate.
+type Options struct {
+	// Service and Namespace name the Kubernetes Service in front of the webhook server.
+	// They are used to derive the in-cluster DNS names of the certificate.
+	Service   string
+	Namespace string
+
+	// DNSNames are additional DNS names for the certificate.
+	DNSNames []string
+
+	// Validity is the certificate lifetime. Zero uses DefaultValidity.
+	Validity time.Duration
+}
+
+// Bundle holds PEM-encoded serving certificate, key and the CA certificate that signed them.
+type Bundle struct {
+	Cert []byte
+	Key  []byte
+	CA   []byte
+}
+
+// dnsNames returns the certificate DNS names for the options.
+func (o Options) dnsNames() []string {
+	names := append([]string{}, o.DNSNames...)
+	if o.Service != "" && o.Namespace != "" {
+		names = append(names,
+			o.Service,
+			fmt.Sprintf("%s.%s", o.Service, o.Namespace),
+			fmt.Sprintf("%s.%s.svc", o.Service, o.Namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", o.Service, o.Namespace),
+		)
+	}
+	return names
+}
+
+// Generate creates a new self-signed CA and a serving certificate signed by it.
+func Generate(opts Options, now time.Time) (*Bundle, error) {
+	names := opts.dnsNames()
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no DNS names for certificate: set a service and namespace or DNS names")
+	}
+	validity := opts.Validity
+	if validity == 0 {
+		validity = DefaultValidity
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               pkix.Name{CommonName: "k8s-controller-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serving key: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano() + 1),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create serving certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode serving key: %w", err)
+	}
+
+	return &Bundle{
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		CA:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+	}, nil
+}
+
+// WriteFiles writes the bundle into dir. The key is written last, so a reloader
+// watching the directory never pairs a new key with an old certificate for long.
+func (b *Bundle) WriteFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+		mode os.FileMode
+	}{
+		{CAFile, b.CA, 0o644},
+		{CertFile, b.Cert, 0o644},
+		{KeyFile, b.Key, 0o600},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.name), f.data, f.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// LoadBundle reads a bundle from dir. The CA file is optional.
+func LoadBundle(dir string) (*Bundle, error) {
+	cert, err := os.ReadFile(filepath.Join(dir, CertFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	key, err := os.ReadFile(filepath.Join(dir, KeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	ca, err := os.ReadFile(filepath.Join(dir, CAFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	return &Bundle{Cert: cert, Key: key, CA: ca}, nil
+}
+
+// Expiry returns the NotAfter time of the first certificate in a PEM block.
+func Expiry(certPEM []byte) (time.Time, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, fmt.Errorf("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert.NotAfter, nil
+}
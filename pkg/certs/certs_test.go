@@ -0,0 +1,95 @@
+// Package certs contains tests for serving certificate management.
+// This file tests certificate generation and bundle files.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+// Test constants
+const (
+	testService   = "k8s-controller"
+	testNamespace = "kube-system"
+)
+
+// testOptions returns certificate options for the test service.
+func testOptions() Options {
+	return Options{Service: testService, Namespace: testNamespace}
+}
+
+// TestGenerate verifies that the serving certificate is signed by the CA and valid for the service names.
+func TestGenerate(t *testing.T) {
+	now := time.Now()
+	bundle, err := Generate(testOptions(), now)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if _, err := tls.X509KeyPair(bundle.Cert, bundle.Key); err != nil {
+		t.Fatalf("certificate and key do not match: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(bundle.CA) {
+		t.Fatal("failed to parse CA certificate")
+	}
+	block, _ := pem.Decode(bundle.Cert)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	for _, name := range []string{"k8s-controller.kube-system.svc", "k8s-controller.kube-system.svc.cluster.local"} {
+		opts := x509.VerifyOptions{DNSName: name, Roots: roots, CurrentTime: now}
+		if _, err := cert.Verify(opts); err != nil {
+			t.Errorf("certificate not valid for %s: %v", name, err)
+		}
+	}
+
+	notAfter, err := Expiry(bundle.Cert)
+	if err != nil || notAfter.Sub(now) < DefaultValidity-time.Minute {
+		t.Errorf("Expiry() = %v, %v; want about %v from now", notAfter, err, DefaultValidity)
+	}
+}
+
+// TestGenerateRequiresNames verifies that a certificate without DNS names is rejected.
+func TestGenerateRequiresNames(t *testing.T) {
+	if _, err := Generate(Options{Service: testService}, time.Now()); err == nil {
+		t.Error("expected error without namespace or DNS names")
+	}
+	if _, err := Generate(Options{DNSNames: []string{"localhost"}}, time.Now()); err != nil {
+		t.Errorf("Generate() with DNS names error = %v", err)
+	}
+}
+
+// TestBundleFiles verifies that a written bundle can be loaded back.
+func TestBundleFiles(t *testing.T) {
+	dir := t.TempDir()
+	bundle, err := Generate(testOptions(), time.Now())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if err := bundle.WriteFiles(dir); err != nil {
+		t.Fatalf("WriteFiles() error = %v", err)
+	}
+	loaded, err := LoadBundle(dir)
+	if err != nil {
+		t.Fatalf("LoadBundle() error = %v", err)
+	}
+	if string(loaded.Cert) != string(bundle.Cert) || string(loaded.Key) != string(bundle.Key) ||
+		string(loaded.CA) != string(bundle.CA) {
+		t.Error("loaded bundle differs from written bundle")
+	}
+
+	if _, err := LoadBundle(t.TempDir()); err == nil {
+		t.Error("expected error for empty directory")
+	}
+	if _, err := Expiry([]byte("not a certificate")); err == nil {
+		t.Error("expected error for invalid PEM")
+	}
+}
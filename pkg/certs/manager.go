@@ -0,0 +1,81 @@
+// Package certs manages the serving certificates of the webhook server.
+// This file implements the certificate provisioning modes.
+package certs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// Mode selects how serving certificates are provisioned.
+type Mode string
+
+const (
+	// ModeSelfSigned generates a self-signed CA and serving certificate when none
+	// exists in the certificate directory or the existing one is about to expire.
+	ModeSelfSigned Mode = "self-signed"
+
+	// ModeCertManager expects cert-manager to issue the certificate into a Secret
+	// mounted at the certificate directory. Nothing is generated locally.
+	ModeCertManager Mode = "cert-manager"
+)
+
+// DefaultRenewBefore is how long before expiry a self-signed certificate is regenerated.
+const DefaultRenewBefore = 30 * 24 * time.Hour
+
+// ParseMode converts a mode name into a Mode.
+func ParseMode(name string) (Mode, error) {
+	switch Mode(name) {
+	case ModeSelfSigned, ModeCertManager:
+		return Mode(name), nil
+	default:
+		return "", fmt.Errorf("unsupported certificate mode '%s', must be one of: %s, %s",
+			name, ModeSelfSigned, ModeCertManager)
+	}
+}
+
+// Ensure makes sure dir holds a usable serving certificate and returns it.
+// In self-signed mode a missing or expiring certificate is (re)generated.
+// In cert-manager mode the certificate must already exist.
+func Ensure(dir string, mode Mode, opts Options, now time.Time) (*Bundle, error) {
+	bundle, err := LoadBundle(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	switch mode {
+	case ModeCertManager:
+		if err != nil {
+			return nil, fmt.Errorf("certificate not found in %s, has cert-manager issued it yet: %w", dir, err)
+		}
+		return bundle, nil
+	case ModeSelfSigned:
+		if err == nil && !expiresSoon(bundle.Cert, now) {
+			return bundle, nil
+		}
+		bundle, err = Generate(opts, now)
+		if err != nil {
+			return nil, err
+		}
+		if err := bundle.WriteFiles(dir); err != nil {
+			return nil, err
+		}
+		return bundle, nil
+	default:
+		return nil, fmt.Errorf("unsupported certificate mode '%s'", mode)
+	}
+}
+
+// expiresSoon reports whether the certificate is unreadable or expires within DefaultRenewBefore.
+func expiresSoon(certPEM []byte, now time.Time) bool {
+	notAfter, err := Expiry(certPEM)
+	return err != nil || notAfter.Sub(now) < DefaultRenewBefore
+}
+
+// Paths returns the certificate and key file paths inside dir.
+func Paths(dir string) (string, string) {
+	return filepath.Join(dir, CertFile), filepath.Join(dir, KeyFile)
+}
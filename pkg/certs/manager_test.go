@@ -0,0 +1,82 @@
+// Package certs contains tests for serving certificate management.
+// This file tests the certificate provisioning modes.
+package certs
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseMode verifies mode name validation.
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Mode
+		wantErr bool
+	}{
+		{"self-signed", ModeSelfSigned, false},
+		{"cert-manager", ModeCertManager, false},
+		{"acme", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMode(tt.name)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseMode(%q) = %q, %v", tt.name, got, err)
+			}
+		})
+	}
+}
+
+// TestEnsureSelfSigned verifies generation, reuse and renewal of self-signed certificates.
+func TestEnsureSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	first, err := Ensure(dir, ModeSelfSigned, testOptions(), now)
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+
+	second, err := Ensure(dir, ModeSelfSigned, testOptions(), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if string(second.Cert) != string(first.Cert) {
+		t.Error("expected a valid certificate to be reused")
+	}
+
+	renewAt := now.Add(DefaultValidity - DefaultRenewBefore + time.Hour)
+	renewed, err := Ensure(dir, ModeSelfSigned, testOptions(), renewAt)
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if string(renewed.Cert) == string(first.Cert) {
+		t.Error("expected an expiring certificate to be regenerated")
+	}
+}
+
+// TestEnsureCertManager verifies that cert-manager mode never generates certificates.
+func TestEnsureCertManager(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Ensure(dir, ModeCertManager, testOptions(), time.Now()); err == nil {
+		t.Fatal("expected error when cert-manager has not issued a certificate")
+	}
+
+	issued, err := Generate(testOptions(), time.Now())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := issued.WriteFiles(dir); err != nil {
+		t.Fatalf("WriteFiles() error = %v", err)
+	}
+
+	bundle, err := Ensure(dir, ModeCertManager, testOptions(), time.Now())
+	if err != nil {
+		t.Fatalf("Ensure() error = %v", err)
+	}
+	if string(bundle.Cert) != string(issued.Cert) {
+		t.Error("expected the issued certificate to be used")
+	}
+}
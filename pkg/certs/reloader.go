@@ -0,0 +1,111 @@
+// Package certs manages the serving certificates of the webhook server.
+// This file implements hot reloading of rotated certificates from disk.
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultReloadInterval is how often the certificate files are checked for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// Reloader serves the current certificate of a directory and picks up rotated files.
+// Files are polled rather than watched with inotify, because Secret volumes are
+// updated through symlink swaps that per-file watches do not observe reliably.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   zerolog.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// NewReloader creates a Reloader for the certificate in dir and loads it.
+func NewReloader(dir string, logger zerolog.Logger) (*Reloader, error) {
+	certFile, keyFile := Paths(dir)
+	r := &Reloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate files and swaps in the new certificate if they changed.
+// On error the previously loaded certificate stays in use. It reports whether a new
+// certificate was loaded.
+func (r *Reloader) Reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	r.mu.RLock()
+	unchanged := bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert, r.certPEM, r.keyPEM = &cert, certPEM, keyPEM
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Watch reloads the certificate every interval until ctx is cancelled.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				// Mid-rotation the certificate and key may not match yet; retry on the next tick.
+				r.logger.Warn().Err(err).Msg("Failed to reload serving certificate, keeping the current one")
+				continue
+			}
+			if reloaded {
+				r.logger.Info().Str("cert", r.certFile).Msg("Reloaded rotated serving certificate")
+			}
+		}
+	}
+}
+
+// GetCertificate returns the current certificate. It is meant for tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// TLSConfig returns a server TLS configuration that always serves the current certificate.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
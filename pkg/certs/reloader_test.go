@@ -0,0 +1,103 @@
+// Package certs contains tests for serving certificate management.
+// This file tests hot reloading of rotated certificates.
+package certs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// writeTestBundle generates a bundle into dir and returns it.
+func writeTestBundle(t *testing.T, dir string) *Bundle {
+	t.Helper()
+	bundle, err := Generate(testOptions(), time.Now())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := bundle.WriteFiles(dir); err != nil {
+		t.Fatalf("WriteFiles() error = %v", err)
+	}
+	return bundle
+}
+
+// servedLeaf returns the DER bytes of the certificate currently served by the reloader.
+func servedLeaf(t *testing.T, r *Reloader) []byte {
+	t.Helper()
+	cert, err := r.TLSConfig().GetCertificate(nil)
+	if err != nil || cert == nil {
+		t.Fatalf("GetCertificate() = %v, %v", cert, err)
+	}
+	return cert.Certificate[0]
+}
+
+// TestReloaderRotation verifies that rotated files are picked up and broken ones are ignored.
+func TestReloaderRotation(t *testing.T) {
+	dir := t.TempDir()
+	writeTestBundle(t, dir)
+
+	r, err := NewReloader(dir, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	original := servedLeaf(t, r)
+
+	if reloaded, err := r.Reload(); err != nil || reloaded {
+		t.Errorf("Reload() without changes = %v, %v; want false, nil", reloaded, err)
+	}
+
+	writeTestBundle(t, dir)
+	if reloaded, err := r.Reload(); err != nil || !reloaded {
+		t.Fatalf("Reload() after rotation = %v, %v; want true, nil", reloaded, err)
+	}
+	rotated := servedLeaf(t, r)
+	if string(rotated) == string(original) {
+		t.Error("expected the rotated certificate to be served")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, KeyFile), []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("failed to corrupt key: %v", err)
+	}
+	if _, err := r.Reload(); err == nil {
+		t.Error("expected error for mismatched key")
+	}
+	if string(servedLeaf(t, r)) != string(rotated) {
+		t.Error("expected the previous certificate to stay in use after a failed reload")
+	}
+}
+
+// TestReloaderWatch verifies that Watch picks up rotated files in the background.
+func TestReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestBundle(t, dir)
+
+	r, err := NewReloader(dir, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	original := servedLeaf(t, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	writeTestBundle(t, dir)
+	deadline := time.Now().Add(2 * time.Second)
+	for string(servedLeaf(t, r)) == string(original) {
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate was not picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestNewReloaderMissingFiles verifies that a missing certificate is an error.
+func TestNewReloaderMissingFiles(t *testing.T) {
+	if _, err := NewReloader(t.TempDir(), zerolog.Nop()); err == nil {
+		t.Error("expected error for missing certificate files")
+	}
+}
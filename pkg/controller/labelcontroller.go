@@ -0,0 +1,370 @@
+// Package controller provides the reconciliation building blocks of the k8s-controller application.
+// This file implements the opt-in controller that keeps the recommended app.kubernetes.io/* labels
+// consistent across Deployments, their pod templates and Services, per namespace policy.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Reconcile actions recorded by the label controller.
+const (
+	ActionLabelDrift  = "label-drift"
+	ActionFixedLabels = "fixed-labels"
+)
+
+// DefaultResync is the default informer resync period.
+const DefaultResync = 10 * time.Minute
+
+// LabelControllerOptions configures the label controller.
+type LabelControllerOptions struct {
+	// DefaultPolicy applies to namespaces without the label policy label. Empty means off.
+	DefaultPolicy LabelPolicy
+
+	// Resync is the informer resync period. Zero uses DefaultResync.
+	Resync time.Duration
+
+	// DryRun simulates the label updates: client dry runs only log them, server dry runs submit them
+	// without persisting.
+	DryRun k8s.DryRun
+}
+
+// LabelController reconciles the recommended labels of Deployments and their Services.
+type LabelController struct {
+	clientset     kubernetes.Interface
+	recorder      *Recorder
+	logger        zerolog.Logger
+	defaultPolicy LabelPolicy
+	dryRun        k8s.DryRun
+
+	factory     informers.SharedInformerFactory
+	deployments appslisters.DeploymentLister
+	services    corelisters.ServiceLister
+	namespaces  corelisters.NamespaceLister
+	synced      []cache.InformerSynced
+	queue       workqueue.TypedRateLimitingInterface[string]
+}
+
+// NewLabelController creates a LabelController and registers its informers.
+func NewLabelController(
+	clientset kubernetes.Interface, recorder *Recorder, logger zerolog.Logger, opts LabelControllerOptions,
+) (*LabelController, error) {
+	if opts.DefaultPolicy == "" {
+		opts.DefaultPolicy = LabelPolicyOff
+	}
+	if opts.Resync == 0 {
+		opts.Resync = DefaultResync
+	}
+
+	factory := informers.NewSharedInformerFactory(clientset, opts.Resync)
+	deployments := factory.Apps().V1().Deployments()
+	services := factory.Core().V1().Services()
+	namespaces := factory.Core().V1().Namespaces()
+
+	c := &LabelController{
+		clientset:     clientset,
+		recorder:      recorder,
+		logger:        logger.With().Str("component", "label-controller").Logger(),
+		defaultPolicy: opts.DefaultPolicy,
+		dryRun:        opts.DryRun,
+		factory:       factory,
+		deployments:   deployments.Lister(),
+		services:      services.Lister(),
+		namespaces:    namespaces.Lister(),
+		synced: []cache.InformerSynced{
+			deployments.Informer().HasSynced, services.Informer().HasSynced, namespaces.Informer().HasSynced,
+		},
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "labels"},
+		),
+	}
+
+	if err := c.registerHandlers(deployments.Informer(), services.Informer(), namespaces.Informer()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// registerHandlers enqueues Deployments on their own changes and on changes of
+// Services and Namespaces that may affect them.
+func (c *LabelController) registerHandlers(deployments, services, namespaces cache.SharedIndexInformer) error {
+	handlers := []struct {
+		informer cache.SharedIndexInformer
+		enqueue  func(obj any)
+	}{
+		{deployments, c.enqueueDeployment},
+		{services, func(obj any) { c.enqueueNamespace(namespaceOf(obj)) }},
+		{namespaces, func(obj any) { c.enqueueNamespace(nameOf(obj)) }},
+	}
+	for _, h := range handlers {
+		enqueue := h.enqueue
+		if _, err := h.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    enqueue,
+			UpdateFunc: func(_, obj any) { enqueue(obj) },
+		}); err != nil {
+			return fmt.Errorf("failed to register event handler: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run starts the informers, waits for their caches and processes the queue with the
+// given number of workers until ctx is cancelled.
+func (c *LabelController) Run(ctx context.Context, workers int) error {
+	defer c.queue.ShutDown()
+
+	c.factory.Start(ctx.Done())
+	c.logger.Info().Msg("Waiting for informer caches to sync")
+	if !cache.WaitForCacheSync(ctx.Done(), c.synced...) {
+		return fmt.Errorf("failed to sync informer caches")
+	}
+
+	c.logger.Info().Int("workers", workers).Str("default_policy", string(c.defaultPolicy)).
+		Msg("Label controller started")
+	for range workers {
+		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+	}
+
+	<-ctx.Done()
+	c.logger.Info().Msg("Label controller stopped")
+	return nil
+}
+
+// runWorker processes queue items until the queue is shut down.
+func (c *LabelController) runWorker(ctx context.Context) {
+	for c.processNextItem(ctx) {
+	}
+}
+
+// processNextItem reconciles one queued Deployment, requeueing it with backoff on error.
+func (c *LabelController) processNextItem(ctx context.Context) bool {
+	key, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	if err := c.reconcile(ctx, key); err != nil {
+		c.logger.Error().Err(err).Str("deployment", key).Msg("Failed to reconcile labels, requeueing")
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// reconcile checks the labels of one Deployment and reports or fixes drift per namespace policy.
+func (c *LabelController) reconcile(ctx context.Context, key string) error {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return fmt.Errorf("invalid key %q: %w", key, err)
+	}
+
+	deployment, err := c.deployments.Deployments(ns).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get deployment %s: %w", key, err)
+	}
+
+	policy := c.policyFor(ns)
+	if policy == LabelPolicyOff {
+		return nil
+	}
+
+	services, err := c.services.Services(ns).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list services in %s: %w", ns, err)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	drifts := CheckLabels(deployment, services)
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	if policy == LabelPolicyReport {
+		return c.report(ctx, deployment, drifts)
+	}
+	return c.fix(ctx, deployment, drifts)
+}
+
+// policyFor returns the label policy of a namespace.
+func (c *LabelController) policyFor(ns string) LabelPolicy {
+	namespace, err := c.namespaces.Get(ns)
+	if err != nil {
+		return c.defaultPolicy
+	}
+
+	value, ok := namespace.Labels[LabelPolicyLabel]
+	if !ok {
+		return c.defaultPolicy
+	}
+	policy, err := ParseLabelPolicy(value)
+	if err != nil {
+		c.logger.Warn().Err(err).Str("namespace", ns).Msg("Invalid namespace label policy, using default")
+		return c.defaultPolicy
+	}
+	return policy
+}
+
+// report logs and records label drift without changing any labels. The same drift is
+// recorded only once, so the annotation written by the recorder does not cause a loop.
+func (c *LabelController) report(ctx context.Context, d *appsv1.Deployment, drifts []LabelDrift) error {
+	ref := ObjectRef{Kind: KindDeployment, Namespace: d.Namespace, Name: d.Name}
+	if alreadyRecorded(d, ActionLabelDrift, drifts) {
+		return nil
+	}
+
+	for _, drift := range drifts {
+		c.logger.Warn().Str("deployment", d.Namespace+"/"+d.Name).Str("drift", drift.String()).
+			Msg("Label drift detected")
+	}
+	return c.recorder.Record(ctx, ref, ActionLabelDrift, drifts)
+}
+
+// fix updates every drifted object and records the decision on the Deployment.
+func (c *LabelController) fix(ctx context.Context, d *appsv1.Deployment, drifts []LabelDrift) error {
+	for _, drift := range drifts {
+		if err := c.updateLabels(ctx, drift); err != nil {
+			return err
+		}
+		c.logger.Info().Str("deployment", d.Namespace+"/"+d.Name).Str("fixed", drift.String()).
+			Msg("Fixed label drift")
+	}
+
+	ref := ObjectRef{Kind: KindDeployment, Namespace: d.Namespace, Name: d.Name}
+	return c.recorder.Record(ctx, ref, ActionFixedLabels, drifts)
+}
+
+// updateLabels sets the drifted labels on the object, retrying if it was modified concurrently.
+// Updating a pod template changes the template hash and therefore rolls out new pods.
+func (c *LabelController) updateLabels(ctx context.Context, drift LabelDrift) error {
+	ref := drift.Ref
+	var err error
+	switch ref.Kind {
+	case KindDeployment:
+		deployments := c.clientset.AppsV1().Deployments(ref.Namespace)
+		_, _, err = k8s.UpdateWithRetry(ctx,
+			func(ctx context.Context) (*appsv1.Deployment, error) {
+				return deployments.Get(ctx, ref.Name, metav1.GetOptions{})
+			},
+			func(d *appsv1.Deployment) (bool, error) {
+				if drift.PodTemplate {
+					return mergeLabels(&d.Spec.Template.Labels, drift.Labels), nil
+				}
+				return mergeLabels(&d.Labels, drift.Labels), nil
+			},
+			func(ctx context.Context, d *appsv1.Deployment) (*appsv1.Deployment, error) {
+				if c.dryRun == k8s.DryRunClient {
+					return d, nil
+				}
+				return deployments.Update(ctx, d, updateOptions(c.dryRun))
+			})
+	case KindService:
+		services := c.clientset.CoreV1().Services(ref.Namespace)
+		_, _, err = k8s.UpdateWithRetry(ctx,
+			func(ctx context.Context) (*corev1.Service, error) {
+				return services.Get(ctx, ref.Name, metav1.GetOptions{})
+			},
+			func(svc *corev1.Service) (bool, error) {
+				return mergeLabels(&svc.Labels, drift.Labels), nil
+			},
+			func(ctx context.Context, svc *corev1.Service) (*corev1.Service, error) {
+				if c.dryRun == k8s.DryRunClient {
+					return svc, nil
+				}
+				return services.Update(ctx, svc, updateOptions(c.dryRun))
+			})
+	default:
+		err = fmt.Errorf("unsupported kind %q", ref.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update labels of %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	return nil
+}
+
+// mergeLabels sets the given labels in *set, allocating it if needed, and reports whether any value changed.
+func mergeLabels(set *map[string]string, labels map[string]string) bool {
+	changed := false
+	for key, value := range labels {
+		if current, ok := (*set)[key]; ok && current == value {
+			continue
+		}
+		if *set == nil {
+			*set = make(map[string]string, len(labels))
+		}
+		(*set)[key] = value
+		changed = true
+	}
+	return changed
+}
+
+// alreadyRecorded reports whether the Deployment's last reconcile summary has the same action and observed state.
+func alreadyRecorded(d *appsv1.Deployment, action string, observed any) bool {
+	summary, err := DecodeReconcileSummary(d.Annotations[LastReconcileAnnotation])
+	return err == nil && summary.Action == action && summary.Hash == HashObject(observed)
+}
+
+// enqueueDeployment adds a Deployment to the queue.
+func (c *LabelController) enqueueDeployment(obj any) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		c.logger.Warn().Err(err).Msg("Failed to get deployment key")
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueNamespace adds all Deployments of a namespace to the queue.
+func (c *LabelController) enqueueNamespace(ns string) {
+	if ns == "" {
+		return
+	}
+	deployments, err := c.deployments.Deployments(ns).List(labels.Everything())
+	if err != nil {
+		c.logger.Warn().Err(err).Str("namespace", ns).Msg("Failed to list deployments")
+		return
+	}
+	for _, d := range deployments {
+		c.enqueueDeployment(d)
+	}
+}
+
+// namespaceOf returns the namespace of a Service event object.
+func namespaceOf(obj any) string {
+	if svc, ok := obj.(*corev1.Service); ok {
+		return svc.Namespace
+	}
+	return ""
+}
+
+// nameOf returns the name of a Namespace event object.
+func nameOf(obj any) string {
+	if ns, ok := obj.(*corev1.Namespace); ok {
+		return ns.Name
+	}
+	return ""
+}
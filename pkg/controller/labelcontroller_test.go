@@ -0,0 +1,195 @@
+// Package controller contains tests for the reconciliation building blocks.
+// This file tests the label controller against a fake clientset.
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// testKey is the queue key of the test deployment.
+const testKey = testNamespace + "/" + testDeployment
+
+// newPolicyNamespace returns the test namespace with the given label policy, or none if empty.
+func newPolicyNamespace(policy LabelPolicy) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}
+	if policy != "" {
+		ns.Labels = map[string]string{LabelPolicyLabel: string(policy)}
+	}
+	return ns
+}
+
+// startLabelController creates a label controller over the objects and waits for its caches.
+func startLabelController(
+	t *testing.T, opts LabelControllerOptions, objects ...runtime.Object,
+) (*LabelController, *fake.Clientset) {
+	t.Helper()
+	clientset := fake.NewClientset(objects...)
+	recorder := NewRecorder(clientset, zerolog.Nop(), true)
+	recorder.SetDryRun(opts.DryRun)
+
+	c, err := NewLabelController(clientset, recorder, zerolog.Nop(), opts)
+	if err != nil {
+		t.Fatalf("NewLabelController() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	c.factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.synced...) {
+		t.Fatal("failed to sync caches")
+	}
+	return c, clientset
+}
+
+// TestLabelControllerPolicies verifies what each policy does to a drifted deployment and its service.
+func TestLabelControllerPolicies(t *testing.T) {
+	tests := []struct {
+		name            string
+		namespacePolicy LabelPolicy
+		defaultPolicy   LabelPolicy
+		wantFixed       bool
+		wantAction      string
+	}{
+		{"off by default", "", "", false, ""},
+		{"namespace opts in to enforce", LabelPolicyEnforce, "", true, ActionFixedLabels},
+		{"namespace opts in to report", LabelPolicyReport, "", false, ActionLabelDrift},
+		{"namespace opts out of default", LabelPolicyOff, LabelPolicyEnforce, false, ""},
+		{"default applies without label", "", LabelPolicyReport, false, ActionLabelDrift},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newLabelledDeployment(nil, nil, "nginx:1.27")
+			service := newSelectingService("web", map[string]string{"app": testDeployment}, nil)
+			c, clientset := startLabelController(t, LabelControllerOptions{DefaultPolicy: tt.defaultPolicy},
+				newPolicyNamespace(tt.namespacePolicy), deployment, service)
+			ctx := context.Background()
+
+			if err := c.reconcile(ctx, testKey); err != nil {
+				t.Fatalf("reconcile() error = %v", err)
+			}
+
+			got, _ := clientset.AppsV1().Deployments(testNamespace).Get(ctx, testDeployment, metav1.GetOptions{})
+			svc, _ := clientset.CoreV1().Services(testNamespace).Get(ctx, "web", metav1.GetOptions{})
+			fixed := got.Labels[LabelName] == testDeployment &&
+				got.Spec.Template.Labels[LabelVersion] == "1.27" &&
+				svc.Labels[LabelInstance] == testDeployment
+			if fixed != tt.wantFixed {
+				t.Errorf("expected fixed=%v, got deployment labels %v, template labels %v, service labels %v",
+					tt.wantFixed, got.Labels, got.Spec.Template.Labels, svc.Labels)
+			}
+
+			summary, err := DecodeReconcileSummary(got.Annotations[LastReconcileAnnotation])
+			if tt.wantAction == "" {
+				if err == nil {
+					t.Errorf("expected no recorded decision, got %+v", summary)
+				}
+				return
+			}
+			if err != nil || summary.Action != tt.wantAction {
+				t.Errorf("expected recorded action %q, got %+v (%v)", tt.wantAction, summary, err)
+			}
+		})
+	}
+}
+
+// TestLabelControllerDryRun verifies that dry runs send no label updates or annotations in client mode,
+// and send them with dryRun=All in server mode.
+func TestLabelControllerDryRun(t *testing.T) {
+	for _, mode := range []k8s.DryRun{k8s.DryRunClient, k8s.DryRunServer} {
+		t.Run(string(mode), func(t *testing.T) {
+			service := newSelectingService("web", map[string]string{"app": testDeployment}, nil)
+			c, clientset := startLabelController(t,
+				LabelControllerOptions{DefaultPolicy: LabelPolicyEnforce, DryRun: mode},
+				newPolicyNamespace(""), newLabelledDeployment(nil, nil, "nginx:1.27"), service)
+
+			if err := c.reconcile(context.Background(), testKey); err != nil {
+				t.Fatalf("reconcile() error = %v", err)
+			}
+			writes := 0
+			for _, action := range clientset.Actions() {
+				var dryRun []string
+				switch action := action.(type) {
+				case ktesting.UpdateActionImpl:
+					dryRun = action.UpdateOptions.DryRun
+				case ktesting.PatchActionImpl:
+					dryRun = action.PatchOptions.DryRun
+				default:
+					continue
+				}
+				writes++
+				if mode == k8s.DryRunClient || len(dryRun) != 1 || dryRun[0] != metav1.DryRunAll {
+					t.Errorf("unexpected %s of %s in %s dry run: dryRun=%v",
+						action.GetVerb(), action.GetResource().Resource, mode, dryRun)
+				}
+			}
+			if mode == k8s.DryRunServer && writes == 0 {
+				t.Error("expected the label updates to be submitted in a server dry run")
+			}
+		})
+	}
+}
+
+// TestLabelControllerReportOnce verifies that unchanged drift is not recorded again.
+func TestLabelControllerReportOnce(t *testing.T) {
+	deployment := newLabelledDeployment(nil, nil, "nginx:1.27")
+	drifts := CheckLabels(deployment, nil)
+	deployment.Annotations = map[string]string{
+		LastReconcileAnnotation: ReconcileSummary{Action: ActionLabelDrift, Hash: HashObject(drifts)}.Encode(),
+	}
+	c, clientset := startLabelController(t, LabelControllerOptions{DefaultPolicy: LabelPolicyReport},
+		newPolicyNamespace(""), deployment)
+
+	if err := c.reconcile(context.Background(), testKey); err != nil {
+		t.Fatalf("reconcile() error = %v", err)
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Errorf("expected no patch for already recorded drift, got %v", action)
+		}
+	}
+}
+
+// TestLabelControllerRun verifies that a running controller fixes drift from informer events.
+func TestLabelControllerRun(t *testing.T) {
+	c, clientset := startLabelController(t, LabelControllerOptions{DefaultPolicy: LabelPolicyEnforce},
+		newPolicyNamespace(""), newLabelledDeployment(nil, nil, "nginx:1.27"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := c.Run(ctx, 1); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		got, err := clientset.AppsV1().Deployments(testNamespace).Get(ctx, testDeployment, metav1.GetOptions{})
+		if err == nil && got.Labels[LabelName] == testDeployment {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("controller did not fix the deployment labels")
+}
+
+// TestLabelControllerMissingDeployment verifies that deleted deployments are ignored.
+func TestLabelControllerMissingDeployment(t *testing.T) {
+	c, _ := startLabelController(t, LabelControllerOptions{DefaultPolicy: LabelPolicyEnforce}, newPolicyNamespace(""))
+	if err := c.reconcile(context.Background(), testKey); err != nil {
+		t.Errorf("reconcile() error = %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// Package controller provides the reconciliation building blocks of the k8s-controller application.
+// This file computes the recommended app.kubernetes.io/* labels of a Deployment and the drift of
+// the Deployment, its pod template and its Services from them.
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Recommended labels, see https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/.
+const (
+	LabelName     = "app.kubernetes.io/name"
+	LabelInstance = "app.kubernetes.io/instance"
+	LabelVersion  = "app.kubernetes.io/version"
+)
+
+// recommendedLabelPrefix is the prefix shared by all recommended labels.
+const recommendedLabelPrefix = "app.kubernetes.io/"
+
+// LabelPolicyLabel is the namespace label selecting the label policy for Deployments in that namespace.
+const LabelPolicyLabel = "k8s-controller.searge.dev/label-policy"
+
+// LabelPolicy selects what the label controller does about label drift.
+type LabelPolicy string
+
+// Label policies.
+const (
+	// LabelPolicyOff ignores the namespace.
+	LabelPolicyOff LabelPolicy = "off"
+
+	// LabelPolicyReport logs and records drift without changing objects.
+	LabelPolicyReport LabelPolicy = "report"
+
+	// LabelPolicyEnforce fixes drift by patching the labels.
+	LabelPolicyEnforce LabelPolicy = "enforce"
+)
+
+// ParseLabelPolicy converts a policy name into a LabelPolicy.
+func ParseLabelPolicy(name string) (LabelPolicy, error) {
+	switch LabelPolicy(name) {
+	case LabelPolicyOff, LabelPolicyReport, LabelPolicyEnforce:
+		return LabelPolicy(name), nil
+	default:
+		return "", fmt.Errorf("unsupported label policy '%s', must be one of: off, report, enforce", name)
+	}
+}
+
+// LabelDrift lists the labels that must be set on one object to match the recommended labels.
+type LabelDrift struct {
+	// Ref identifies the drifted object.
+	Ref ObjectRef `json:"ref"`
+
+	// PodTemplate is true when the drift is in the Deployment's pod template rather than its metadata.
+	PodTemplate bool `json:"podTemplate,omitempty"`
+
+	// Labels maps label keys to the values they must be set to.
+	Labels map[string]string `json:"labels"`
+}
+
+// String returns a compact description of the drift for logs.
+func (d LabelDrift) String() string {
+	keys := make([]string, 0, len(d.Labels))
+	for key := range d.Labels {
+		keys = append(keys, key+"="+d.Labels[key])
+	}
+	sort.Strings(keys)
+
+	target := d.Ref.Kind + "/" + d.Ref.Name
+	if d.PodTemplate {
+		target += " pod template"
+	}
+	return fmt.Sprintf("%s: %s", target, strings.Join(keys, ","))
+}
+
+// DesiredLabels returns the recommended labels a Deployment and its related objects should carry.
+// All app.kubernetes.io/* labels are propagated. Values in the Deployment's selector win, since the
+// selector is immutable; then the Deployment's own labels. Missing name and instance default to the
+// Deployment name and a missing version to the image tag of the first container.
+func DesiredLabels(d *appsv1.Deployment) map[string]string {
+	desired := make(map[string]string)
+	for key, value := range d.Labels {
+		if strings.HasPrefix(key, recommendedLabelPrefix) {
+			desired[key] = value
+		}
+	}
+	if d.Spec.Selector != nil {
+		for key, value := range d.Spec.Selector.MatchLabels {
+			if strings.HasPrefix(key, recommendedLabelPrefix) {
+				desired[key] = value
+			}
+		}
+	}
+
+	setDefault(desired, LabelName, d.Name)
+	setDefault(desired, LabelInstance, d.Name)
+	if containers := d.Spec.Template.Spec.Containers; len(containers) > 0 {
+		setDefault(desired, LabelVersion, imageTag(containers[0].Image))
+	}
+	return desired
+}
+
+// CheckLabels returns the label drift of a Deployment, its pod template and the given Services.
+// Only Services whose selector matches the Deployment's pod template are considered.
+func CheckLabels(d *appsv1.Deployment, services []*corev1.Service) []LabelDrift {
+	desired := DesiredLabels(d)
+	ref := ObjectRef{Kind: KindDeployment, Namespace: d.Namespace, Name: d.Name}
+
+	var drifts []LabelDrift
+	if missing := missingLabels(d.Labels, desired); len(missing) > 0 {
+		drifts = append(drifts, LabelDrift{Ref: ref, Labels: missing})
+	}
+	if missing := missingLabels(d.Spec.Template.Labels, desired); len(missing) > 0 {
+		drifts = append(drifts, LabelDrift{Ref: ref, PodTemplate: true, Labels: missing})
+	}
+
+	podLabels := labels.Set(d.Spec.Template.Labels)
+	for _, svc := range services {
+		if svc.Namespace != d.Namespace || len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		if !labels.SelectorFromSet(svc.Spec.Selector).Matches(podLabels) {
+			continue
+		}
+		if missing := missingLabels(svc.Labels, desired); len(missing) > 0 {
+			drifts = append(drifts, LabelDrift{
+				Ref:    ObjectRef{Kind: KindService, Namespace: svc.Namespace, Name: svc.Name},
+				Labels: missing,
+			})
+		}
+	}
+	return drifts
+}
+
+// missingLabels returns the desired labels that are absent from or different in have.
+func missingLabels(have, desired map[string]string) map[string]string {
+	missing := make(map[string]string)
+	for key, value := range desired {
+		if have[key] != value {
+			missing[key] = value
+		}
+	}
+	return missing
+}
+
+// setDefault sets a label if it is not set yet and the value is not empty.
+func setDefault(set map[string]string, key, value string) {
+	if _, ok := set[key]; !ok && value != "" {
+		set[key] = value
+	}
+}
+
+// imageTag returns the tag of an image reference, or "" for untagged and digest references.
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, found := strings.Cut(name, ":")
+	if !found || tag == "latest" {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,138 @@
+// Package controller contains tests for the reconciliation building blocks.
+// This file tests the computation of recommended labels and label drift.
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newLabelledDeployment returns a deployment selecting app=nginx and running the given image.
+func newLabelledDeployment(deploymentLabels, templateLabels map[string]string, image string) *appsv1.Deployment {
+	selector := map[string]string{"app": testDeployment}
+	podLabels := map[string]string{"app": testDeployment}
+	for key, value := range templateLabels {
+		podLabels[key] = value
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: testDeployment, Namespace: testNamespace, Labels: deploymentLabels},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+			},
+		},
+	}
+}
+
+// newSelectingService returns a service selecting the given pod labels.
+func newSelectingService(name string, selector, serviceLabels map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: serviceLabels},
+		Spec:       corev1.ServiceSpec{Selector: selector},
+	}
+}
+
+// TestDesiredLabels verifies defaults and precedence of the recommended labels.
+func TestDesiredLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		image    string
+		expected map[string]string
+	}{
+		{
+			name:  "defaults from name and image tag",
+			image: "nginx:1.27",
+			expected: map[string]string{
+				LabelName: testDeployment, LabelInstance: testDeployment, LabelVersion: "1.27",
+			},
+		},
+		{
+			name:   "existing labels are kept and propagated",
+			labels: map[string]string{LabelName: "web", "app.kubernetes.io/part-of": "shop", "team": "a"},
+			image:  "registry.example.com:5000/web@sha256:abc",
+			expected: map[string]string{
+				LabelName: "web", LabelInstance: testDeployment, "app.kubernetes.io/part-of": "shop",
+			},
+		},
+		{
+			name:     "latest tag is not a version",
+			image:    "nginx:latest",
+			expected: map[string]string{LabelName: testDeployment, LabelInstance: testDeployment},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DesiredLabels(newLabelledDeployment(tt.labels, nil, tt.image))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("DesiredLabels() = %v, want %v", got, tt.expected)
+			}
+			for key, value := range tt.expected {
+				if got[key] != value {
+					t.Errorf("label %s = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
+
+// TestDesiredLabelsSelectorWins verifies that immutable selector values take precedence.
+func TestDesiredLabelsSelectorWins(t *testing.T) {
+	d := newLabelledDeployment(map[string]string{LabelName: "other"}, nil, "nginx")
+	d.Spec.Selector.MatchLabels[LabelName] = "web"
+
+	if got := DesiredLabels(d)[LabelName]; got != "web" {
+		t.Errorf("expected selector value 'web', got %q", got)
+	}
+}
+
+// TestCheckLabels verifies drift detection on the deployment, its pod template and services.
+func TestCheckLabels(t *testing.T) {
+	desired := map[string]string{LabelName: testDeployment, LabelInstance: testDeployment, LabelVersion: "1.27"}
+	podSelector := map[string]string{"app": testDeployment}
+
+	consistent := newLabelledDeployment(desired, desired, "nginx:1.27")
+	service := newSelectingService("web", podSelector, desired)
+	if drifts := CheckLabels(consistent, []*corev1.Service{service}); len(drifts) != 0 {
+		t.Errorf("expected no drift, got %v", drifts)
+	}
+
+	drifted := newLabelledDeployment(nil, map[string]string{LabelVersion: "1.26"}, "nginx:1.27")
+	services := []*corev1.Service{
+		newSelectingService("web", podSelector, nil),
+		newSelectingService("unrelated", map[string]string{"app": "redis"}, nil),
+		newSelectingService("headless", nil, nil),
+	}
+	drifts := CheckLabels(drifted, services)
+
+	if len(drifts) != 3 {
+		t.Fatalf("expected drift on deployment, pod template and one service, got %v", drifts)
+	}
+	if drifts[0].PodTemplate || len(drifts[0].Labels) != 3 {
+		t.Errorf("unexpected deployment drift %v", drifts[0])
+	}
+	if !drifts[1].PodTemplate || drifts[1].Labels[LabelVersion] != "1.27" {
+		t.Errorf("unexpected pod template drift %v", drifts[1])
+	}
+	if drifts[2].Ref.Kind != KindService || drifts[2].Ref.Name != "web" {
+		t.Errorf("unexpected service drift %v", drifts[2])
+	}
+}
+
+// TestParseLabelPolicy verifies policy name validation.
+func TestParseLabelPolicy(t *testing.T) {
+	for _, name := range []string{"off", "report", "enforce"} {
+		if _, err := ParseLabelPolicy(name); err != nil {
+			t.Errorf("ParseLabelPolicy(%q) error = %v", name, err)
+		}
+	}
+	if _, err := ParseLabelPolicy("fix"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
@@ -0,0 +1,195 @@
+// Package controller provides the reconciliation building blocks of the k8s-controller application.
+// This file implements dual logging of reconcile decisions: every decision is logged and can
+// optionally be written as a compact summary annotation onto the managed object itself.
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// LastReconcileAnnotation is the annotation holding the last reconcile summary on managed objects.
+const LastReconcileAnnotation = "k8s-controller.searge.dev/last-reconcile"
+
+// hashLength is the number of hex characters kept from the object hash.
+const hashLength = 12
+
+// fieldManager identifies this controller in managedFields of objects it writes.
+const fieldManager = "k8s-controller"
+
+// Supported kinds for annotation recording.
+const (
+	KindDeployment = "Deployment"
+	KindService    = "Service"
+	KindConfigMap  = "ConfigMap"
+)
+
+// ObjectRef identifies a managed object.
+type ObjectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ReconcileSummary is the compact record of what the controller last did to an object.
+type ReconcileSummary struct {
+	// Timestamp is when the reconcile decision was made (UTC, second precision).
+	Timestamp time.Time `json:"ts"`
+
+	// Action is a short verb describing the decision (e.g. "noop", "fixed-labels").
+	Action string `json:"action"`
+
+	// Hash identifies the observed object state the decision was based on.
+	Hash string `json:"hash"`
+}
+
+// Encode returns the compact JSON form of the summary used as annotation value.
+func (s ReconcileSummary) Encode() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		// Marshalling a struct of plain fields cannot fail.
+		return ""
+	}
+	return string(data)
+}
+
+// DecodeReconcileSummary parses an annotation value produced by Encode.
+func DecodeReconcileSummary(value string) (ReconcileSummary, error) {
+	var summary ReconcileSummary
+	if err := json.Unmarshal([]byte(value), &summary); err != nil {
+		return ReconcileSummary{}, fmt.Errorf("invalid reconcile summary: %w", err)
+	}
+	return summary, nil
+}
+
+// HashObject returns a short, stable hash of an object's JSON representation.
+func HashObject(obj any) string {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// Recorder logs reconcile decisions and optionally annotates the managed objects with them.
+type Recorder struct {
+	clientset kubernetes.Interface
+	logger    zerolog.Logger
+	annotate  bool
+	dryRun    k8s.DryRun
+	now       func() time.Time
+}
+
+// NewRecorder creates a Recorder. When annotate is false, decisions are only logged.
+func NewRecorder(clientset kubernetes.Interface, logger zerolog.Logger, annotate bool) *Recorder {
+	return &Recorder{
+		clientset: clientset,
+		logger:    logger.With().Str("component", "reconcile-recorder").Logger(),
+		annotate:  annotate,
+		now:       time.Now,
+	}
+}
+
+// SetDryRun sets the dry run mode of the annotation patches. Client dry runs only log the decisions.
+func (r *Recorder) SetDryRun(mode k8s.DryRun) {
+	r.dryRun = mode
+}
+
+// Record logs a reconcile decision about an object and, if enabled, writes it as an annotation.
+// The observed argument is the object state the decision was based on; it is hashed, not stored.
+func (r *Recorder) Record(ctx context.Context, ref ObjectRef, action string, observed any) error {
+	summary := ReconcileSummary{
+		Timestamp: r.now().UTC().Truncate(time.Second),
+		Action:    action,
+		Hash:      HashObject(observed),
+	}
+
+	r.logger.Info().
+		Str("kind", ref.Kind).
+		Str("namespace", ref.Namespace).
+		Str("name", ref.Name).
+		Str("action", summary.Action).
+		Str("hash", summary.Hash).
+		Msg("Reconcile decision")
+
+	if !r.annotate || r.dryRun == k8s.DryRunClient {
+		return nil
+	}
+	return r.writeAnnotation(ctx, ref, summary)
+}
+
+// writeAnnotation merge-patches the summary annotation onto the referenced object.
+func (r *Recorder) writeAnnotation(ctx context.Context, ref ObjectRef, summary ReconcileSummary) error {
+	patch, err := annotationPatch(LastReconcileAnnotation, summary.Encode())
+	if err != nil {
+		return err
+	}
+
+	if err := r.patch(ctx, ref, patch); err != nil {
+		return fmt.Errorf("failed to annotate %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+	return nil
+}
+
+// patch applies a merge patch to the referenced object using the typed client for its kind.
+func (r *Recorder) patch(ctx context.Context, ref ObjectRef, patch []byte) error {
+	var err error
+	switch ref.Kind {
+	case KindDeployment:
+		_, err = r.clientset.AppsV1().Deployments(ref.Namespace).
+			Patch(ctx, ref.Name, types.MergePatchType, patch, patchOptions(r.dryRun))
+	case KindService:
+		_, err = r.clientset.CoreV1().Services(ref.Namespace).
+			Patch(ctx, ref.Name, types.MergePatchType, patch, patchOptions(r.dryRun))
+	case KindConfigMap:
+		_, err = r.clientset.CoreV1().ConfigMaps(ref.Namespace).
+			Patch(ctx, ref.Name, types.MergePatchType, patch, patchOptions(r.dryRun))
+	default:
+		err = fmt.Errorf("unsupported kind %q", ref.Kind)
+	}
+	return err
+}
+
+// annotationPatch builds a JSON merge patch setting a single annotation.
+func annotationPatch(key, value string) ([]byte, error) {
+	patch := map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{key: value},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build annotation patch: %w", err)
+	}
+	return data, nil
+}
+
+// patchOptions returns the options used for every patch issued by the controller in a dry run mode.
+func patchOptions(mode k8s.DryRun) metav1.PatchOptions {
+	return metav1.PatchOptions{FieldManager: fieldManager, DryRun: dryRunOption(mode)}
+}
+
+// updateOptions returns the options used for every update issued by the controller in a dry run mode.
+func updateOptions(mode k8s.DryRun) metav1.UpdateOptions {
+	return metav1.UpdateOptions{FieldManager: fieldManager, DryRun: dryRunOption(mode)}
+}
+
+// dryRunOption returns the dryRun option of the requests of a dry run mode: All in server dry runs.
+func dryRunOption(mode k8s.DryRun) []string {
+	if mode == k8s.DryRunServer {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
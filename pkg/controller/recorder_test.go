@@ -0,0 +1,117 @@
+// Package controller contains tests for the reconciliation building blocks.
+// This file tests the reconcile decision recorder and its annotation format.
+package controller
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// Test constants to avoid string duplication
+const (
+	testNamespace  = "default"
+	testDeployment = "nginx"
+	testAction     = "fixed-labels"
+)
+
+// newTestDeployment returns a minimal deployment for recorder tests.
+func newTestDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: testDeployment, Namespace: testNamespace},
+	}
+}
+
+// TestReconcileSummaryRoundTrip verifies that summaries survive encoding and decoding.
+func TestReconcileSummaryRoundTrip(t *testing.T) {
+	summary := ReconcileSummary{
+		Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Action:    testAction,
+		Hash:      "abc123",
+	}
+
+	decoded, err := DecodeReconcileSummary(summary.Encode())
+	if err != nil {
+		t.Fatalf("DecodeReconcileSummary() error = %v", err)
+	}
+	if decoded != summary {
+		t.Errorf("round trip mismatch: got %+v, want %+v", decoded, summary)
+	}
+
+	if _, err := DecodeReconcileSummary("not-json"); err == nil {
+		t.Error("expected error for malformed summary")
+	}
+}
+
+// TestHashObject verifies that hashes are short, stable and content dependent.
+func TestHashObject(t *testing.T) {
+	first := HashObject(map[string]string{"a": "1"})
+	second := HashObject(map[string]string{"a": "1"})
+	other := HashObject(map[string]string{"a": "2"})
+
+	if len(first) != hashLength {
+		t.Errorf("expected hash length %d, got %d", hashLength, len(first))
+	}
+	if first != second {
+		t.Error("expected identical objects to hash identically")
+	}
+	if first == other {
+		t.Error("expected different objects to hash differently")
+	}
+}
+
+// TestRecorderRecord verifies logging and optional annotation of reconcile decisions.
+func TestRecorderRecord(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotate       bool
+		wantAnnotation bool
+	}{
+		{"log only", false, false},
+		{"log and annotate", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuf bytes.Buffer
+			clientset := fake.NewSimpleClientset(newTestDeployment())
+			recorder := NewRecorder(clientset, zerolog.New(&logBuf), tt.annotate)
+
+			ref := ObjectRef{Kind: KindDeployment, Namespace: testNamespace, Name: testDeployment}
+			if err := recorder.Record(context.Background(), ref, testAction, newTestDeployment()); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+
+			if !strings.Contains(logBuf.String(), testAction) {
+				t.Errorf("expected decision to be logged, got %q", logBuf.String())
+			}
+
+			deployment, err := clientset.AppsV1().Deployments(testNamespace).
+				Get(context.Background(), testDeployment, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get deployment: %v", err)
+			}
+			_, found := deployment.Annotations[LastReconcileAnnotation]
+			if found != tt.wantAnnotation {
+				t.Errorf("annotation present = %v, want %v", found, tt.wantAnnotation)
+			}
+		})
+	}
+}
+
+// TestRecorderUnsupportedKind verifies that annotating unknown kinds fails.
+func TestRecorderUnsupportedKind(t *testing.T) {
+	recorder := NewRecorder(fake.NewSimpleClientset(), zerolog.Nop(), true)
+
+	ref := ObjectRef{Kind: "Widget", Namespace: testNamespace, Name: "w"}
+	if err := recorder.Record(context.Background(), ref, testAction, nil); err == nil {
+		t.Error("expected error for unsupported kind")
+	}
+}
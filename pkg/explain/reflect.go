@@ -0,0 +1,83 @@
+// Package explain documents the fields of resources and API types, similar to kubectl explain.
+// This file derives schemas from Go types, for documenting the HTTP API.
+package explain
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DocTag is the struct tag holding the description of a field, e.g. `doc:"Number of ready replicas"`.
+const DocTag = "doc"
+
+// timeType is documented as an RFC 3339 string, matching its JSON encoding.
+var timeType = reflect.TypeOf(time.Time{})
+
+// FromType derives a schema from the JSON encoding of a Go type.
+// Field names follow json tags and descriptions are read from doc tags.
+func FromType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addStructFields(s, t)
+		return s
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string", Format: "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: FromType(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: FromType(t.Elem())}
+	}
+	return &Schema{Type: scalarType(t.Kind())}
+}
+
+// addStructFields adds the JSON-encoded fields of a struct type to s.
+// Embedded structs without a json name are inlined, as encoding/json does.
+func addStructFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(s, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := FromType(field.Type)
+		property.Description = field.Tag.Get(DocTag)
+		s.Properties[name] = property
+	}
+}
+
+// scalarType maps a Go kind to its OpenAPI type.
+func scalarType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	default:
+		return ""
+	}
+}
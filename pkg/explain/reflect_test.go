@@ -0,0 +1,65 @@
+// Package explain contains tests for field documentation.
+// This file tests schemas derived from Go types.
+package explain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testEmbedded is inlined into testResponse, as encoding/json does.
+type testEmbedded struct {
+	Region string `json:"region" doc:"Region of the cluster"`
+}
+
+// testResponse covers the field kinds of the HTTP API types.
+type testResponse struct {
+	testEmbedded
+	Name     string            `json:"name" doc:"Name of the object"`
+	Count    int32             `json:"count,omitempty"`
+	Ready    bool              `json:"ready"`
+	Created  time.Time         `json:"created"`
+	Items    []string          `json:"items"`
+	Labels   map[string]string `json:"labels"`
+	Nested   *struct{ ID int } `json:"nested"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+// TestFromType verifies field names, types and descriptions derived from a struct.
+func TestFromType(t *testing.T) {
+	s := FromType(reflect.TypeOf(testResponse{}))
+
+	tests := []struct {
+		path     []string
+		wantType string
+		wantDoc  string
+	}{
+		{[]string{"region"}, "string", "Region of the cluster"},
+		{[]string{"name"}, "string", "Name of the object"},
+		{[]string{"count"}, "integer", ""},
+		{[]string{"ready"}, "boolean", ""},
+		{[]string{"created"}, "string", ""},
+		{[]string{"items"}, "[]string", ""},
+		{[]string{"labels"}, "map[string]string", ""},
+		{[]string{"nested", "ID"}, "integer", ""},
+	}
+
+	for _, tt := range tests {
+		field, err := s.Lookup(tt.path)
+		if err != nil {
+			t.Fatalf("Lookup(%v) error = %v", tt.path, err)
+		}
+		if field.TypeName() != tt.wantType || field.Description != tt.wantDoc {
+			t.Errorf("%v: got <%s> %q, want <%s> %q", tt.path, field.TypeName(), field.Description,
+				tt.wantType, tt.wantDoc)
+		}
+	}
+
+	for _, name := range []string{"Ignored", "internal", "testEmbedded"} {
+		if _, ok := s.Properties[name]; ok {
+			t.Errorf("expected field %s to be omitted", name)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package explain documents the fields of resources and API types, similar to kubectl explain.
+// This file renders field documentation in the kubectl explain layout.
+package explain
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Document is the documentation of a field of a resource or API type.
+type Document struct {
+	// Kind names the resource or API type, e.g. "ManagedApp".
+	Kind string
+
+	// Version is the API version of the kind, e.g. "example.com/v1".
+	Version string
+
+	// Path is the field path below the kind; empty for the kind itself.
+	Path []string
+
+	// Schema is the schema of the field at Path.
+	Schema *Schema
+}
+
+// Render writes the documentation of a field and its direct children.
+// With recursive set, all nested fields are listed with their types instead of the children's descriptions.
+func Render(out io.Writer, doc Document, recursive bool) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "KIND:     %s\nVERSION:  %s\n\n", doc.Kind, doc.Version)
+	if len(doc.Path) > 0 {
+		fmt.Fprintf(&b, "FIELD: %s <%s>\n\n", doc.Path[len(doc.Path)-1], doc.Schema.TypeName())
+	}
+	b.WriteString("DESCRIPTION:\n")
+	writeIndented(&b, descriptionOrDefault(doc.Schema.Description), 4)
+
+	fields := doc.Schema.element()
+	if len(fields.Properties) > 0 {
+		b.WriteString("\nFIELDS:\n")
+		if recursive {
+			writeFieldTree(&b, fields, 2)
+		} else {
+			writeFieldList(&b, fields)
+		}
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// writeFieldList writes each field of s with its type, whether it is required, and its description.
+func writeFieldList(b *strings.Builder, s *Schema) {
+	for i, name := range s.FieldNames() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		field := s.Properties[name]
+		fmt.Fprintf(b, "  %s\t<%s>%s\n", name, field.TypeName(), requiredMarker(s, name))
+		writeIndented(b, descriptionOrDefault(field.Description), 4)
+	}
+}
+
+// writeFieldTree writes the names and types of all fields below s, indented by depth.
+func writeFieldTree(b *strings.Builder, s *Schema, indent int) {
+	for _, name := range s.FieldNames() {
+		field := s.Properties[name]
+		fmt.Fprintf(b, "%s%s\t<%s>%s\n", strings.Repeat(" ", indent), name, field.TypeName(),
+			requiredMarker(s, name))
+		writeFieldTree(b, field.element(), indent+2)
+	}
+}
+
+// requiredMarker returns the kubectl-style marker for a required field of s.
+func requiredMarker(s *Schema, name string) string {
+	if s.IsRequired(name) {
+		return " -required-"
+	}
+	return ""
+}
+
+// descriptionOrDefault returns the description, or a placeholder when it is empty.
+func descriptionOrDefault(description string) string {
+	if strings.TrimSpace(description) == "" {
+		return "<empty>"
+	}
+	return description
+}
+
+// writeIndented writes text with every line indented by the given number of spaces.
+func writeIndented(b *strings.Builder, text string, indent int) {
+	prefix := strings.Repeat(" ", indent)
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		b.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+	}
+}
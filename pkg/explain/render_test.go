@@ -0,0 +1,76 @@
+// Package explain contains tests for field documentation.
+// This file tests rendering in the kubectl explain layout.
+package explain
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestRender verifies the layout of a field with its direct children.
+func TestRender(t *testing.T) {
+	spec, err := ParseSchema(testOpenAPISchema).Lookup([]string{"spec"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	doc := Document{Kind: "ManagedApp", Version: "example.com/v1", Path: []string{"spec"}, Schema: spec}
+	if err := Render(&out, doc, false); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := `KIND:     ManagedApp
+VERSION:  example.com/v1
+
+FIELD: spec <Object>
+
+DESCRIPTION:
+    <empty>
+
+FIELDS:
+  image	<string> -required-
+    Container image.
+
+  labels	<map[string]string>
+    <empty>
+
+  ports	<[]Object>
+    <empty>
+
+  strategy	<Object>
+    How updates are rolled out.
+`
+	if out.String() != want {
+		t.Errorf("Render() got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+// TestRenderRecursive verifies that nested fields are listed with their types.
+func TestRenderRecursive(t *testing.T) {
+	var out bytes.Buffer
+	doc := Document{Kind: "ManagedApp", Version: "example.com/v1", Schema: ParseSchema(testOpenAPISchema)}
+	if err := Render(&out, doc, true); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := `KIND:     ManagedApp
+VERSION:  example.com/v1
+
+DESCRIPTION:
+    ManagedApp deploys an application.
+
+FIELDS:
+  spec	<Object>
+    image	<string> -required-
+    labels	<map[string]string>
+    ports	<[]Object>
+      port	<integer>
+    strategy	<Object>
+      maxSurge	<IntOrString>
+      type	<string>
+`
+	if out.String() != want {
+		t.Errorf("Render() got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,168 @@
+// Package explain documents the fields of resources and API types, similar to kubectl explain.
+// This file defines the schema model, its conversion from and to OpenAPI v3 and field path lookups.
+package explain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of an OpenAPI v3 schema needed to document a field.
+type Schema struct {
+	// Type is the OpenAPI type, e.g. "object", "array", "string" or "integer".
+	Type string
+
+	// Format refines the type, e.g. "date-time" or "int32".
+	Format string
+
+	// Description documents the field.
+	Description string
+
+	// Properties are the fields of an object.
+	Properties map[string]*Schema
+
+	// Required lists the properties that must be set.
+	Required []string
+
+	// Items is the schema of array elements.
+	Items *Schema
+
+	// AdditionalProperties is the schema of map values.
+	AdditionalProperties *Schema
+
+	// IntOrString marks fields accepting either an integer or a string.
+	IntOrString bool
+}
+
+// ParseSchema converts an OpenAPI v3 schema, e.g. a CRD's openAPIV3Schema, into a Schema.
+func ParseSchema(raw map[string]any) *Schema {
+	s := &Schema{}
+	s.Type, _ = raw["type"].(string)
+	s.Format, _ = raw["format"].(string)
+	s.Description, _ = raw["description"].(string)
+	s.IntOrString, _ = raw["x-kubernetes-int-or-string"].(bool)
+
+	if properties, ok := raw["properties"].(map[string]any); ok {
+		s.Properties = make(map[string]*Schema, len(properties))
+		for name, property := range properties {
+			if propertyMap, ok := property.(map[string]any); ok {
+				s.Properties[name] = ParseSchema(propertyMap)
+			}
+		}
+	}
+	if required, ok := raw["required"].([]any); ok {
+		for _, name := range required {
+			if str, ok := name.(string); ok {
+				s.Required = append(s.Required, str)
+			}
+		}
+	}
+	if items, ok := raw["items"].(map[string]any); ok {
+		s.Items = ParseSchema(items)
+	}
+	if additional, ok := raw["additionalProperties"].(map[string]any); ok {
+		s.AdditionalProperties = ParseSchema(additional)
+	}
+	return s
+}
+
+// OpenAPI converts the schema back into an OpenAPI v3 schema, in the form ParseSchema accepts, e.g. to
+// publish the HTTP API types in an OpenAPI document.
+func (s *Schema) OpenAPI() map[string]any {
+	raw := map[string]any{}
+	for key, value := range map[string]string{"type": s.Type, "format": s.Format, "description": s.Description} {
+		if value != "" {
+			raw[key] = value
+		}
+	}
+	if s.IntOrString {
+		raw["x-kubernetes-int-or-string"] = true
+	}
+	if s.Properties != nil {
+		properties := make(map[string]any, len(s.Properties))
+		for name, property := range s.Properties {
+			properties[name] = property.OpenAPI()
+		}
+		raw["properties"] = properties
+	}
+	if len(s.Required) > 0 {
+		required := make([]any, len(s.Required))
+		for i, name := range s.Required {
+			required[i] = name
+		}
+		raw["required"] = required
+	}
+	if s.Items != nil {
+		raw["items"] = s.Items.OpenAPI()
+	}
+	if s.AdditionalProperties != nil {
+		raw["additionalProperties"] = s.AdditionalProperties.OpenAPI()
+	}
+	return raw
+}
+
+// TypeName returns the kubectl-style type of the schema, e.g. "Object", "[]string" or "map[string]string".
+func (s *Schema) TypeName() string {
+	switch {
+	case s.IntOrString:
+		return "IntOrString"
+	case s.Type == "array" && s.Items != nil:
+		return "[]" + s.Items.TypeName()
+	case s.AdditionalProperties != nil && len(s.Properties) == 0:
+		return "map[string]" + s.AdditionalProperties.TypeName()
+	case s.Type == "object" || s.Type == "" || len(s.Properties) > 0:
+		return "Object"
+	default:
+		return s.Type
+	}
+}
+
+// IsRequired reports whether the named property is required.
+func (s *Schema) IsRequired(name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldNames returns the property names in alphabetical order.
+func (s *Schema) FieldNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the schema of the field at path, descending into array items and map values on the way.
+func (s *Schema) Lookup(path []string) (*Schema, error) {
+	current := s
+	for i, name := range path {
+		current = current.element()
+		field, ok := current.Properties[name]
+		if !ok {
+			return nil, fmt.Errorf("field %q does not exist", strings.Join(path[:i+1], "."))
+		}
+		current = field
+	}
+	return current, nil
+}
+
+// element returns the schema of the fields reachable through s: the element schema of arrays and maps,
+// or s itself.
+func (s *Schema) element() *Schema {
+	for {
+		switch {
+		case s.Items != nil:
+			s = s.Items
+		case s.AdditionalProperties != nil && len(s.Properties) == 0:
+			s = s.AdditionalProperties
+		default:
+			return s
+		}
+	}
+}
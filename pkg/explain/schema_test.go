@@ -0,0 +1,128 @@
+// Package explain contains tests for field documentation.
+// This file tests schema parsing and field lookups.
+package explain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testOpenAPISchema is a CRD-style openAPIV3Schema with nested objects, arrays and maps.
+var testOpenAPISchema = map[string]any{
+	"type":        "object",
+	"description": "ManagedApp deploys an application.",
+	"properties": map[string]any{
+		"spec": map[string]any{
+			"type":     "object",
+			"required": []any{"image"},
+			"properties": map[string]any{
+				"image": map[string]any{"type": "string", "description": "Container image."},
+				"strategy": map[string]any{
+					"type":        "object",
+					"description": "How updates are rolled out.",
+					"properties": map[string]any{
+						"type":     map[string]any{"type": "string"},
+						"maxSurge": map[string]any{"x-kubernetes-int-or-string": true},
+					},
+				},
+				"ports": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"port": map[string]any{"type": "integer"}},
+					},
+				},
+				"labels": map[string]any{
+					"type":                 "object",
+					"additionalProperties": map[string]any{"type": "string"},
+				},
+			},
+		},
+	},
+}
+
+// TestParseSchemaTypeNames verifies kubectl-style type names of parsed fields.
+func TestParseSchemaTypeNames(t *testing.T) {
+	root := ParseSchema(testOpenAPISchema)
+
+	tests := []struct {
+		path []string
+		want string
+	}{
+		{nil, "Object"},
+		{[]string{"spec", "image"}, "string"},
+		{[]string{"spec", "strategy", "maxSurge"}, "IntOrString"},
+		{[]string{"spec", "ports"}, "[]Object"},
+		{[]string{"spec", "labels"}, "map[string]string"},
+	}
+
+	for _, tt := range tests {
+		field, err := root.Lookup(tt.path)
+		if err != nil {
+			t.Fatalf("Lookup(%v) error = %v", tt.path, err)
+		}
+		if got := field.TypeName(); got != tt.want {
+			t.Errorf("TypeName(%v) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestLookup verifies descending into objects and array items, and errors for unknown fields.
+func TestLookup(t *testing.T) {
+	root := ParseSchema(testOpenAPISchema)
+
+	tests := []struct {
+		name    string
+		path    []string
+		want    string
+		wantErr bool
+	}{
+		{"nested object", []string{"spec", "strategy"}, "How updates are rolled out.", false},
+		{"array items", []string{"spec", "ports", "port"}, "", false},
+		{"unknown field", []string{"spec", "replicas"}, "", true},
+		{"below scalar", []string{"spec", "image", "tag"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, err := root.Lookup(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && field.Description != tt.want {
+				t.Errorf("Lookup() description = %q, want %q", field.Description, tt.want)
+			}
+		})
+	}
+}
+
+// TestOpenAPI verifies that schemas convert back into the OpenAPI v3 schemas they were parsed from.
+func TestOpenAPI(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]any
+	}{
+		{"nested", testOpenAPISchema},
+		{"scalar", map[string]any{"type": "string", "format": "date-time", "description": "Creation time."}},
+		{"empty", map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSchema(tt.schema).OpenAPI(); !reflect.DeepEqual(got, tt.schema) {
+				t.Errorf("OpenAPI() = %v, want %v", got, tt.schema)
+			}
+		})
+	}
+}
+
+// TestIsRequired verifies required field detection.
+func TestIsRequired(t *testing.T) {
+	spec, err := ParseSchema(testOpenAPISchema).Lookup([]string{"spec"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !spec.IsRequired("image") || spec.IsRequired("strategy") {
+		t.Errorf("unexpected required fields %v", spec.Required)
+	}
+}
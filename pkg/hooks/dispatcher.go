@@ -0,0 +1,111 @@
+// Package hooks triggers pipelines from the webhooks of external systems, such as GitHub or a CI system.
+// This file implements the dispatcher, which runs the pipelines of triggered hooks in the background.
+package hooks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/pipeline"
+)
+
+// Results of Trigger.
+const (
+	// StatusStarted reports that the pipeline of the hook started running.
+	StatusStarted = "started"
+
+	// StatusQueued reports that the pipeline of the hook is running, and runs once more afterwards.
+	StatusQueued = "queued"
+)
+
+// Dispatcher runs the pipelines of triggered hooks. The pipeline of a hook runs at most once at a time;
+// triggers while it runs are coalesced into a single rerun, so the last trigger is always acted upon.
+type Dispatcher struct {
+	hooks  map[string]*hookRuns
+	runner *pipeline.Runner
+	logger zerolog.Logger
+
+	// running tracks the background runs, see Wait.
+	running sync.WaitGroup
+}
+
+// hookRuns is the run state of a hook.
+type hookRuns struct {
+	hook *Hook
+
+	mu      sync.Mutex
+	running bool
+	pending bool
+}
+
+// NewDispatcher creates a Dispatcher running the pipelines of hooks with runner.
+func NewDispatcher(hooks []*Hook, runner *pipeline.Runner, logger zerolog.Logger) *Dispatcher {
+	d := &Dispatcher{
+		hooks:  make(map[string]*hookRuns, len(hooks)),
+		runner: runner,
+		logger: logger.With().Str("component", "hooks").Logger(),
+	}
+	for _, hook := range hooks {
+		d.hooks[hook.Name] = &hookRuns{hook: hook}
+	}
+	return d
+}
+
+// Hook returns the hook named name.
+func (d *Dispatcher) Hook(name string) (*Hook, bool) {
+	runs, ok := d.hooks[name]
+	if !ok {
+		return nil, false
+	}
+	return runs.hook, true
+}
+
+// Trigger runs the pipeline of the hook named name in the background until it ends or ctx is done, and
+// returns StatusStarted, or StatusQueued if it is already running. It must name a hook of Hook.
+func (d *Dispatcher) Trigger(ctx context.Context, name string) string {
+	runs := d.hooks[name]
+	runs.mu.Lock()
+	defer runs.mu.Unlock()
+	if runs.running {
+		runs.pending = true
+		return StatusQueued
+	}
+
+	runs.running = true
+	d.running.Add(1)
+	go func() {
+		defer d.running.Done()
+		d.run(ctx, runs)
+	}()
+	return StatusStarted
+}
+
+// run runs the pipeline of a hook, and reruns it while it was triggered again during the run.
+func (d *Dispatcher) run(ctx context.Context, runs *hookRuns) {
+	logger := d.logger.With().Str("hook", runs.hook.Name).Str("pipeline", runs.hook.pipeline.Name).Logger()
+	for {
+		logger.Info().Msg("Running pipeline of hook")
+		report := d.runner.Run(ctx, runs.hook.pipeline)
+		if report.Succeeded {
+			logger.Info().Msg("Pipeline of hook succeeded")
+		} else {
+			logger.Error().Msg("Pipeline of hook failed")
+		}
+
+		runs.mu.Lock()
+		if !runs.pending || ctx.Err() != nil {
+			runs.running, runs.pending = false, false
+			runs.mu.Unlock()
+			return
+		}
+		runs.pending = false
+		runs.mu.Unlock()
+	}
+}
+
+// Wait waits until the pipelines running in the background have ended.
+func (d *Dispatcher) Wait() {
+	d.running.Wait()
+}
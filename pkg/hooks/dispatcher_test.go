@@ -0,0 +1,98 @@
+// Package hooks contains tests for the webhook receiver.
+// This file tests running the pipelines of triggered hooks.
+package hooks
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/pipeline"
+)
+
+// blockingCluster counts deployment restarts, each of which waits for a value on release.
+// Other operations are not implemented.
+type blockingCluster struct {
+	pipeline.Cluster
+	restarts atomic.Int32
+	started  chan struct{}
+	release  chan struct{}
+}
+
+// RestartDeployment counts the restart once released.
+func (c *blockingCluster) RestartDeployment(ctx context.Context, _, _ string) error {
+	c.started <- struct{}{}
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	c.restarts.Add(1)
+	return nil
+}
+
+// TestDispatcherTrigger verifies that triggers during a run are coalesced into a single rerun.
+func TestDispatcherTrigger(t *testing.T) {
+	p, err := pipeline.Parse([]byte(testPipeline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := &blockingCluster{started: make(chan struct{}), release: make(chan struct{})}
+	d := NewDispatcher([]*Hook{{Name: "deploy-web", pipeline: p}},
+		pipeline.NewRunner(cluster, pipeline.Options{}, zerolog.Nop()), zerolog.Nop())
+
+	if _, ok := d.Hook("unknown"); ok {
+		t.Error("expected no hook named unknown")
+	}
+	if hook, ok := d.Hook("deploy-web"); !ok || hook.Name != "deploy-web" {
+		t.Errorf("Hook(deploy-web) = %v, %v", hook, ok)
+	}
+
+	if got := d.Trigger(context.Background(), "deploy-web"); got != StatusStarted {
+		t.Errorf("expected the first trigger to start, got %s", got)
+	}
+	<-cluster.started
+	for range 3 {
+		if got := d.Trigger(context.Background(), "deploy-web"); got != StatusQueued {
+			t.Errorf("expected triggers during the run to be queued, got %s", got)
+		}
+	}
+	cluster.release <- struct{}{}
+	<-cluster.started
+	cluster.release <- struct{}{}
+	d.Wait()
+
+	if got := cluster.restarts.Load(); got != 2 {
+		t.Errorf("expected 2 runs, got %d", got)
+	}
+	if got := d.Trigger(context.Background(), "deploy-web"); got != StatusStarted {
+		t.Errorf("expected a trigger after the runs to start, got %s", got)
+	}
+	<-cluster.started
+	cluster.release <- struct{}{}
+	d.Wait()
+}
+
+// TestDispatcherCancel verifies that queued reruns are dropped once the context is done.
+func TestDispatcherCancel(t *testing.T) {
+	p, err := pipeline.Parse([]byte(testPipeline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := &blockingCluster{started: make(chan struct{}), release: make(chan struct{})}
+	d := NewDispatcher([]*Hook{{Name: "deploy-web", pipeline: p}},
+		pipeline.NewRunner(cluster, pipeline.Options{}, zerolog.Nop()), zerolog.Nop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Trigger(ctx, "deploy-web")
+	<-cluster.started
+	d.Trigger(ctx, "deploy-web")
+	cancel()
+	d.Wait()
+
+	if got := cluster.restarts.Load(); got != 0 {
+		t.Errorf("expected no completed runs, got %d", got)
+	}
+}
@@ -0,0 +1,230 @@
+// Package hooks triggers pipelines from the webhooks of external systems, such as GitHub or a CI system.
+// This file defines the hooks file format, loads it and verifies the signatures of deliveries.
+package hooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/Searge/k8s-controller/pkg/pipeline"
+)
+
+// Defaults of the headers of deliveries, as sent by GitHub.
+const (
+	DefaultSignatureHeader = "X-Hub-Signature-256"
+	DefaultEventHeader     = "X-GitHub-Event"
+)
+
+// signaturePrefix is the optional prefix of signatures naming their algorithm, e.g. "sha256=3f2a...".
+const signaturePrefix = "sha256="
+
+// Errors of Verify.
+var (
+	ErrMissingSignature = errors.New("missing signature")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrReplayedDelivery = errors.New("replayed delivery")
+)
+
+// File is the format of a hooks file.
+type File struct {
+	Hooks []Hook `yaml:"hooks"`
+}
+
+// Hook is a webhook that runs a pipeline when a delivery signed with its secret is received.
+type Hook struct {
+	// Name identifies the hook in its path, /hooks/{name}; it must be a DNS label, e.g. "deploy-web".
+	Name string `yaml:"name"`
+
+	// SecretFile holds the HMAC key the deliveries are signed with, relative to the hooks file unless
+	// absolute; SecretEnv names an environment variable holding it instead.
+	SecretFile string `yaml:"secretFile"`
+	SecretEnv  string `yaml:"secretEnv"`
+
+	// SignatureHeader carries the hex-encoded HMAC-SHA256 of the body, optionally prefixed with "sha256=".
+	// Empty uses DefaultSignatureHeader.
+	SignatureHeader string `yaml:"signatureHeader"`
+
+	// TimestampHeader, if set, carries the Unix time at which a delivery was sent, which is then signed
+	// with the body as "{timestamp}.{body}"; deliveries sent more than Tolerance before or after they are
+	// received are rejected. Empty signs the body alone, as GitHub does.
+	TimestampHeader string `yaml:"timestampHeader"`
+
+	// Tolerance is how far the timestamp of a delivery may be off, and how long the signatures of
+	// deliveries are remembered to reject them when they are received again. Zero uses DefaultTolerance.
+	Tolerance time.Duration `yaml:"tolerance"`
+
+	// Events, if set, are the values of EventHeader whose deliveries run the pipeline, e.g. "push";
+	// others are acknowledged and ignored. Empty EventHeader uses DefaultEventHeader.
+	EventHeader string   `yaml:"eventHeader"`
+	Events      []string `yaml:"events"`
+
+	// Pipeline is the pipeline file to run, relative to the hooks file unless absolute.
+	Pipeline string `yaml:"pipeline"`
+
+	// secret is the HMAC key read from SecretFile or SecretEnv.
+	secret []byte
+
+	// pipeline is the pipeline loaded from Pipeline.
+	pipeline *pipeline.Pipeline
+
+	// replays holds the signatures of the deliveries received within Tolerance.
+	replays replayCache
+
+	// now returns the current time; nil uses time.Now.
+	now func() time.Time
+}
+
+// Load reads the hooks file at path, with the secrets and pipelines of its hooks, and validates them.
+func Load(path string) ([]*Hook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var file File
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse hooks file %s: %w", path, err)
+	}
+	if len(file.Hooks) == 0 {
+		return nil, fmt.Errorf("hooks file %s has no hooks", path)
+	}
+
+	dir := filepath.Dir(path)
+	hooks := make([]*Hook, 0, len(file.Hooks))
+	for i := range file.Hooks {
+		hook := &file.Hooks[i]
+		if slices.ContainsFunc(hooks, func(h *Hook) bool { return h.Name == hook.Name }) {
+			return nil, fmt.Errorf("duplicate hook name %q", hook.Name)
+		}
+		if err := hook.load(dir); err != nil {
+			return nil, fmt.Errorf("hook %q: %w", hook.Name, err)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// load validates the hook and loads its secret and pipeline, resolving relative paths against dir.
+func (h *Hook) load(dir string) error {
+	if errs := validation.IsDNS1123Label(h.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid name: %s", strings.Join(errs, ", "))
+	}
+	if h.SignatureHeader == "" {
+		h.SignatureHeader = DefaultSignatureHeader
+	}
+	if h.EventHeader == "" {
+		h.EventHeader = DefaultEventHeader
+	}
+	switch {
+	case h.Tolerance < 0:
+		return errors.New("tolerance must not be negative")
+	case h.Tolerance == 0:
+		h.Tolerance = DefaultTolerance
+	}
+
+	switch {
+	case (h.SecretFile == "") == (h.SecretEnv == ""):
+		return errors.New("needs exactly one of secretFile and secretEnv")
+	case h.SecretFile != "":
+		secret, err := os.ReadFile(resolve(dir, h.SecretFile))
+		if err != nil {
+			return fmt.Errorf("failed to read secret: %w", err)
+		}
+		h.secret = bytes.TrimSpace(secret)
+	default:
+		h.secret = []byte(strings.TrimSpace(os.Getenv(h.SecretEnv)))
+	}
+	if len(h.secret) == 0 {
+		return errors.New("secret is empty")
+	}
+
+	if h.Pipeline == "" {
+		return errors.New("needs a pipeline")
+	}
+	p, err := pipeline.Load(resolve(dir, h.Pipeline))
+	if err != nil {
+		return err
+	}
+	h.pipeline = p
+	return nil
+}
+
+// resolve returns path, relative to dir unless absolute.
+func resolve(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Verify checks that signature, the value of the SignatureHeader of a delivery, is the HMAC-SHA256 of
+// body with the secret of the hook, preceded by timestamp, the value of its TimestampHeader, if the hook
+// has one. A delivery is rejected with ErrReplayedDelivery if its timestamp is not within Tolerance of
+// the current time, or if it was verified before within Tolerance, e.g. when it is sent again by a
+// third party having captured it. The timestamp thus bounds replays of hooks having a TimestampHeader;
+// without one, a delivery can be replayed once it is forgotten, after Tolerance.
+func (h *Hook) Verify(signature, timestamp string, body []byte) error {
+	if signature == "" {
+		return fmt.Errorf("%w in header %s", ErrMissingSignature, h.SignatureHeader)
+	}
+	if h.TimestampHeader != "" && timestamp == "" {
+		return fmt.Errorf("%w: no timestamp in header %s", ErrMissingSignature, h.TimestampHeader)
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return fmt.Errorf("%w: not hex-encoded", ErrInvalidSignature)
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	if h.TimestampHeader != "" {
+		mac.Write([]byte(timestamp + "."))
+	}
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	now := time.Now()
+	if h.now != nil {
+		now = h.now()
+	}
+	if h.TimestampHeader != "" {
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: timestamp %q is not a Unix time", ErrInvalidSignature, timestamp)
+		}
+		if sent := time.Unix(seconds, 0); sent.Before(now.Add(-h.Tolerance)) || sent.After(now.Add(h.Tolerance)) {
+			return fmt.Errorf("%w: timestamp %s is not within %s of the current time", ErrReplayedDelivery,
+				sent.UTC().Format(time.RFC3339), h.Tolerance)
+		}
+	}
+	if !h.replays.add(hex.EncodeToString(got), now, h.Tolerance) {
+		return fmt.Errorf("%w: the delivery was already received", ErrReplayedDelivery)
+	}
+	return nil
+}
+
+// Accepts reports whether a delivery of event, the value of its EventHeader, runs the pipeline.
+func (h *Hook) Accepts(event string) bool {
+	return len(h.Events) == 0 || slices.Contains(h.Events, event)
+}
+
+// PipelineName returns the name of the pipeline the hook runs.
+func (h *Hook) PipelineName() string {
+	return h.pipeline.Name
+}
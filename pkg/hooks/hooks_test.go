@@ -0,0 +1,243 @@
+// Package hooks contains tests for the webhook receiver.
+// This file tests loading hooks files and verifying the signatures of deliveries.
+package hooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPipeline is a pipeline restarting deployment web.
+const testPipeline = "steps:\n  - {name: restart, restart: {deployment: web}}\n"
+
+// writeFile writes data to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sign returns the signature of body with secret, as GitHub sends it.
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// TestLoad verifies that hooks are loaded with their secrets and pipelines, resolved against the hooks file.
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "restart-web.yaml", testPipeline)
+	writeFile(t, dir, "web.secret", "s3cret\n")
+	t.Setenv("KC_TEST_HOOK_SECRET", "from-env")
+	path := writeFile(t, dir, "hooks.yaml", `hooks:
+  - name: deploy-web
+    secretFile: web.secret
+    events: [push]
+    pipeline: restart-web.yaml
+  - name: ci
+    secretEnv: KC_TEST_HOOK_SECRET
+    signatureHeader: X-Signature
+    timestampHeader: X-Signature-Timestamp
+    tolerance: 1m
+    pipeline: restart-web.yaml
+`)
+
+	hooks, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(hooks) != 2 {
+		t.Fatalf("expected 2 hooks, got %d", len(hooks))
+	}
+	web, ci := hooks[0], hooks[1]
+	if string(web.secret) != "s3cret" || web.SignatureHeader != DefaultSignatureHeader ||
+		web.EventHeader != DefaultEventHeader || web.TimestampHeader != "" || web.Tolerance != DefaultTolerance ||
+		web.PipelineName() != "restart-web" {
+		t.Errorf("unexpected hook %+v", web)
+	}
+	if string(ci.secret) != "from-env" || ci.SignatureHeader != "X-Signature" ||
+		ci.TimestampHeader != "X-Signature-Timestamp" || ci.Tolerance != time.Minute {
+		t.Errorf("unexpected hook %+v", ci)
+	}
+}
+
+// TestLoadInvalid verifies that incomplete or inconsistent hooks files are rejected.
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "restart-web.yaml", testPipeline)
+	writeFile(t, dir, "invalid.yaml", "steps: []\n")
+	writeFile(t, dir, "web.secret", "s3cret")
+	writeFile(t, dir, "empty.secret", "\n")
+
+	tests := []struct {
+		name    string
+		hooks   string
+		wantErr string
+	}{
+		{"no hooks", "hooks: []", "has no hooks"},
+		{"invalid name", "hooks:\n  - {name: Deploy_Web, secretFile: web.secret, pipeline: restart-web.yaml}",
+			"invalid name"},
+		{"duplicate name", "hooks:\n  - {name: web, secretFile: web.secret, pipeline: restart-web.yaml}\n" +
+			"  - {name: web, secretFile: web.secret, pipeline: restart-web.yaml}", "duplicate hook name"},
+		{"no secret", "hooks:\n  - {name: web, pipeline: restart-web.yaml}", "exactly one of secretFile"},
+		{"two secrets", "hooks:\n  - {name: web, secretFile: web.secret, secretEnv: X, pipeline: restart-web.yaml}",
+			"exactly one of secretFile"},
+		{"missing secret file", "hooks:\n  - {name: web, secretFile: missing, pipeline: restart-web.yaml}",
+			"failed to read secret"},
+		{"empty secret", "hooks:\n  - {name: web, secretFile: empty.secret, pipeline: restart-web.yaml}",
+			"secret is empty"},
+		{"no pipeline", "hooks:\n  - {name: web, secretFile: web.secret}", "needs a pipeline"},
+		{"invalid pipeline", "hooks:\n  - {name: web, secretFile: web.secret, pipeline: invalid.yaml}",
+			"no steps"},
+		{"negative tolerance",
+			"hooks:\n  - {name: web, secretFile: web.secret, tolerance: -1m, pipeline: restart-web.yaml}",
+			"tolerance must not be negative"},
+		{"unknown field", "hooks:\n  - {name: web, secret: x, pipeline: restart-web.yaml}", "field secret not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFile(t, dir, "hooks.yaml", tt.hooks))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestVerify verifies that only deliveries signed with the secret of the hook are accepted.
+func TestVerify(t *testing.T) {
+	body := `{"ref":"refs/heads/main"}`
+
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   error
+	}{
+		{"valid", sign("s3cret", body), nil},
+		{"valid without prefix", strings.TrimPrefix(sign("s3cret", body), "sha256="), nil},
+		{"missing", "", ErrMissingSignature},
+		{"other secret", sign("guess", body), ErrInvalidSignature},
+		{"other body", sign("s3cret", body+" "), ErrInvalidSignature},
+		{"not hex", "sha256=zz", ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := &Hook{SignatureHeader: DefaultSignatureHeader, Tolerance: DefaultTolerance,
+				secret: []byte("s3cret")}
+			if err := hook.Verify(tt.signature, "", []byte(body)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestVerifyReplay verifies that replayed deliveries are rejected: deliveries received again within the
+// tolerance of the hook, and, for hooks signing a timestamp, deliveries sent outside of it.
+func TestVerifyReplay(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := `{"ref":"refs/heads/main"}`
+	stamp := func(offset time.Duration) string { return strconv.FormatInt(now.Add(offset).Unix(), 10) }
+	signAt := func(timestamp string) string { return sign("s3cret", timestamp+"."+body) }
+
+	// delivery is a delivery received after the given time, with its timestamp if the hook signs one.
+	type delivery struct {
+		after     time.Duration
+		signature string
+		timestamp string
+		wantErr   error
+	}
+	tests := []struct {
+		name            string
+		timestampHeader string
+		deliveries      []delivery
+	}{
+		{"replayed within tolerance", "", []delivery{
+			{0, sign("s3cret", body), "", nil},
+			{time.Minute, sign("s3cret", body), "", ErrReplayedDelivery},
+		}},
+		{"received again after tolerance", "", []delivery{
+			{0, sign("s3cret", body), "", nil},
+			{DefaultTolerance, sign("s3cret", body), "", nil},
+		}},
+		{"timestamped", "X-Timestamp", []delivery{
+			{0, signAt(stamp(-time.Minute)), stamp(-time.Minute), nil},
+			{time.Second, signAt(stamp(time.Minute)), stamp(time.Minute), nil},
+		}},
+		{"timestamped replay", "X-Timestamp", []delivery{
+			{0, signAt(stamp(0)), stamp(0), nil},
+			{time.Minute, signAt(stamp(0)), stamp(0), ErrReplayedDelivery},
+			{DefaultTolerance + time.Second, signAt(stamp(0)), stamp(0), ErrReplayedDelivery},
+		}},
+		{"expired timestamp", "X-Timestamp", []delivery{
+			{0, signAt(stamp(-DefaultTolerance - time.Second)), stamp(-DefaultTolerance - time.Second),
+				ErrReplayedDelivery},
+		}},
+		{"future timestamp", "X-Timestamp", []delivery{
+			{0, signAt(stamp(DefaultTolerance + time.Second)), stamp(DefaultTolerance + time.Second),
+				ErrReplayedDelivery},
+		}},
+		{"timestamp not signed", "X-Timestamp", []delivery{
+			{0, sign("s3cret", body), stamp(0), ErrInvalidSignature},
+		}},
+		{"other timestamp", "X-Timestamp", []delivery{
+			{0, signAt(stamp(-DefaultTolerance * 2)), stamp(0), ErrInvalidSignature},
+		}},
+		{"missing timestamp", "X-Timestamp", []delivery{
+			{0, signAt(""), "", ErrMissingSignature},
+		}},
+		{"invalid timestamp", "X-Timestamp", []delivery{
+			{0, signAt("yesterday"), "yesterday", ErrInvalidSignature},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received time.Time
+			hook := &Hook{SignatureHeader: DefaultSignatureHeader, TimestampHeader: tt.timestampHeader,
+				Tolerance: DefaultTolerance, secret: []byte("s3cret"), now: func() time.Time { return received }}
+			for i, d := range tt.deliveries {
+				received = now.Add(d.after)
+				if err := hook.Verify(d.signature, d.timestamp, []byte(body)); !errors.Is(err, d.wantErr) {
+					t.Errorf("delivery %d: Verify() error = %v, want %v", i, err, d.wantErr)
+				}
+			}
+		})
+	}
+}
+
+// TestAccepts verifies the event filter of hooks.
+func TestAccepts(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []string
+		event  string
+		want   bool
+	}{
+		{"no filter", nil, "ping", true},
+		{"listed event", []string{"push", "release"}, "release", true},
+		{"other event", []string{"push"}, "ping", false},
+		{"no event", []string{"push"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Hook{Events: tt.events}).Accepts(tt.event); got != tt.want {
+				t.Errorf("Accepts(%q) = %v, want %v", tt.event, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package hooks triggers pipelines from the webhooks of external systems, such as GitHub or a CI system.
+// This file implements remembering recent deliveries, to reject deliveries received twice.
+package hooks
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTolerance is the Tolerance of hooks that do not set one.
+const DefaultTolerance = 5 * time.Minute
+
+// replayCache remembers the signatures of verified deliveries for a while. Only deliveries signed with
+// the secret of the hook are added, so its size is bounded by the deliveries legitimately sent within
+// the tolerance of the hook.
+type replayCache struct {
+	mu sync.Mutex
+	// expiries are the times at which signatures are forgotten.
+	expiries map[string]time.Time
+}
+
+// add remembers signature until ttl after now and reports whether it was new, i.e. not remembered yet.
+// Signatures remembered for longer than ttl are forgotten.
+func (c *replayCache) add(signature string, now time.Time, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for seen, expiry := range c.expiries {
+		if !now.Before(expiry) {
+			delete(c.expiries, seen)
+		}
+	}
+	if _, ok := c.expiries[signature]; ok {
+		return false
+	}
+	if c.expiries == nil {
+		c.expiries = make(map[string]time.Time)
+	}
+	c.expiries[signature] = now.Add(ttl)
+	return true
+}
@@ -0,0 +1,175 @@
+// Package informers manages client-go shared informer factories for the k8s-controller application.
+// This file implements the Manager, which scopes informers to namespaces and owns their lifecycle.
+package informers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultResync is the resync period of the informers when none is set.
+const DefaultResync = 10 * time.Minute
+
+// Options configures a Manager.
+type Options struct {
+	// Namespaces limits the informers to these namespaces. If empty, all namespaces are watched.
+	Namespaces []string
+
+	// Resync is the resync period of the informers. Zero uses DefaultResync.
+	Resync time.Duration
+
+	// StripManagedFields drops the managed fields of cached objects to save memory.
+	StripManagedFields bool
+}
+
+// ResourceFunc selects a resource from a factory, e.g.
+// func(f informers.SharedInformerFactory) cache.SharedIndexInformer { return f.Apps().V1().Deployments().Informer() }.
+type ResourceFunc func(factory informers.SharedInformerFactory) cache.SharedIndexInformer
+
+// Manager owns one shared informer factory per watched namespace. Subsystems register the
+// resources they need, and the Manager starts the informers, waits for their caches to sync
+// and stops them when the context passed to Start is cancelled.
+type Manager struct {
+	namespaces []string
+	factories  []informers.SharedInformerFactory
+	logger     zerolog.Logger
+
+	mu        sync.Mutex
+	resources []*Resource
+	ctx       context.Context
+}
+
+// New creates a Manager. No informers run until resources are registered and Start is called.
+func New(clientset kubernetes.Interface, opts Options, logger zerolog.Logger) *Manager {
+	if opts.Resync == 0 {
+		opts.Resync = DefaultResync
+	}
+	namespaces := opts.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	namespaces = dedupe(namespaces)
+
+	m := &Manager{namespaces: namespaces, logger: logger.With().Str("component", "informers").Logger()}
+	for _, ns := range namespaces {
+		factoryOpts := []informers.SharedInformerOption{informers.WithNamespace(ns)}
+		if opts.StripManagedFields {
+			factoryOpts = append(factoryOpts, informers.WithTransform(stripManagedFields))
+		}
+		m.factories = append(m.factories, informers.NewSharedInformerFactoryWithOptions(clientset, opts.Resync,
+			factoryOpts...))
+	}
+	return m
+}
+
+// Namespaces returns the watched namespaces; a single "" means all namespaces.
+func (m *Manager) Namespaces() []string {
+	return m.namespaces
+}
+
+// Register registers a resource with the factory of every watched namespace. Registering the same
+// resource twice shares the informers. Resources registered after Start are started right away.
+func (m *Manager) Register(get ResourceFunc) *Resource {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	resource := &Resource{}
+	for _, factory := range m.factories {
+		resource.informers = append(resource.informers, get(factory))
+	}
+	m.resources = append(m.resources, resource)
+	if m.ctx != nil {
+		m.startFactories(m.ctx)
+	}
+	return resource
+}
+
+// Start starts the informers of all registered resources. They stop, and their factories
+// shut down, when ctx is cancelled. Start does not wait for the caches; see WaitForSync.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx != nil {
+		return fmt.Errorf("informers already started")
+	}
+	m.ctx = ctx
+	m.logger.Info().Strs("namespaces", m.namespaces).Int("resources", len(m.resources)).Msg("Starting informers")
+	m.startFactories(ctx)
+
+	go func() {
+		<-ctx.Done()
+		for _, factory := range m.factories {
+			factory.Shutdown()
+		}
+		m.logger.Info().Msg("Informers stopped")
+	}()
+	return nil
+}
+
+// startFactories starts the informers that are not running yet. The caller holds m.mu.
+func (m *Manager) startFactories(ctx context.Context) {
+	for _, factory := range m.factories {
+		factory.Start(ctx.Done())
+	}
+}
+
+// WaitForSync blocks until the caches of all registered resources have synced.
+// It fails if ctx is cancelled first.
+func (m *Manager) WaitForSync(ctx context.Context) error {
+	m.mu.Lock()
+	var synced []cache.InformerSynced
+	for _, resource := range m.resources {
+		synced = append(synced, resource.HasSynced)
+	}
+	m.mu.Unlock()
+
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return fmt.Errorf("failed to sync informer caches: %w", ctx.Err())
+	}
+	m.logger.Info().Int("resources", len(synced)).Msg("Informer caches synced")
+	return nil
+}
+
+// Run starts the informers and waits for their caches to sync.
+func (m *Manager) Run(ctx context.Context) error {
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+	return m.WaitForSync(ctx)
+}
+
+// stripManagedFields drops the managed fields of cached objects, which are rarely read
+// and make up a large share of their memory.
+func stripManagedFields(obj any) (any, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
+
+// dedupe returns the sorted distinct namespaces. Watching all namespaces supersedes the others.
+func dedupe(namespaces []string) []string {
+	seen := make(map[string]bool, len(namespaces))
+	var result []string
+	for _, ns := range namespaces {
+		if ns == metav1.NamespaceAll {
+			return []string{metav1.NamespaceAll}
+		}
+		if !seen[ns] {
+			seen[ns] = true
+			result = append(result, ns)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,137 @@
+// Package informers contains tests for the shared informer manager.
+// This file tests namespace scoping and the lifecycle of the Manager.
+package informers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// deployments selects the deployments of a factory.
+func deployments(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+	return f.Apps().V1().Deployments().Informer()
+}
+
+// testDeployment returns a deployment with managed fields and the given labels.
+func testDeployment(ns, name string, labels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Namespace:     ns,
+		Name:          name,
+		Labels:        labels,
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+	}}
+}
+
+// TestNewNamespaces verifies the namespace scoping of a Manager.
+func TestNewNamespaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       string
+	}{
+		{"all namespaces", nil, ""},
+		{"sorted and deduplicated", []string{"web", "api", "web"}, "api,web"},
+		{"all supersedes others", []string{"web", ""}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(fake.NewSimpleClientset(), Options{Namespaces: tt.namespaces}, zerolog.Nop())
+			if got := strings.Join(m.Namespaces(), ","); got != tt.want {
+				t.Errorf("expected namespaces %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestManagerRun verifies that registered resources sync, late registrations start and
+// managed fields are stripped.
+func TestManagerRun(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		testDeployment("web", "frontend", nil),
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "frontend"}},
+	)
+	m := New(clientset, Options{StripManagedFields: true}, zerolog.Nop())
+	resource := m.Register(deployments)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := m.Start(ctx); err == nil {
+		t.Error("expected an error when starting twice")
+	}
+	if !resource.HasSynced() {
+		t.Error("expected the resource to be synced")
+	}
+
+	obj, exists, err := resource.Get("web", "frontend")
+	if err != nil || !exists {
+		t.Fatalf("expected the deployment in the cache, got exists=%v err=%v", exists, err)
+	}
+	if fields := obj.(*appsv1.Deployment).ManagedFields; fields != nil {
+		t.Errorf("expected managed fields to be stripped, got %v", fields)
+	}
+
+	services := m.Register(func(f informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Services().Informer()
+	})
+	if err := m.WaitForSync(ctx); err != nil {
+		t.Fatalf("WaitForSync() after a late registration error = %v", err)
+	}
+	if _, exists, _ := services.Get("web", "frontend"); !exists {
+		t.Error("expected the late registered resource to be started")
+	}
+}
+
+// TestManagerWaitForSyncCancelled verifies that WaitForSync fails when its context ends first.
+func TestManagerWaitForSyncCancelled(t *testing.T) {
+	m := New(fake.NewSimpleClientset(), Options{}, zerolog.Nop())
+	m.Register(deployments)
+
+	// The informers are never started, so they cannot sync.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.WaitForSync(ctx); err == nil {
+		t.Error("expected WaitForSync() to fail")
+	}
+}
+
+// TestManagerNamespaceScope verifies that only the watched namespaces are cached.
+func TestManagerNamespaceScope(t *testing.T) {
+	objects := []runtime.Object{
+		testDeployment("web", "frontend", nil),
+		testDeployment("api", "backend", nil),
+		testDeployment("other", "ignored", nil),
+	}
+	m := New(fake.NewSimpleClientset(objects...), Options{Namespaces: []string{"web", "api"}}, zerolog.Nop())
+	resource := m.Register(deployments)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := len(resource.Informers()); got != 2 {
+		t.Errorf("expected one informer per namespace, got %d", got)
+	}
+	if _, exists, _ := resource.Get("other", "ignored"); exists {
+		t.Error("expected deployments of unwatched namespaces not to be cached")
+	}
+	if _, exists, _ := resource.Get("api", "backend"); !exists {
+		t.Error("expected deployments of watched namespaces to be cached")
+	}
+}
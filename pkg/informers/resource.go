@@ -0,0 +1,79 @@
+// Package informers manages client-go shared informer factories for the k8s-controller application.
+// This file implements Resource, the view of one resource across the informers of all watched namespaces.
+package informers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Resource is a registered resource, backed by one informer per watched namespace.
+type Resource struct {
+	informers []cache.SharedIndexInformer
+}
+
+// Informers returns the informers of the resource, one per watched namespace.
+func (r *Resource) Informers() []cache.SharedIndexInformer {
+	return r.informers
+}
+
+// HasSynced reports whether the caches of all namespaces have synced.
+func (r *Resource) HasSynced() bool {
+	for _, informer := range r.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// AddEventHandler registers a handler with the informers of all namespaces.
+func (r *Resource) AddEventHandler(handler cache.ResourceEventHandler) error {
+	for _, informer := range r.informers {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return fmt.Errorf("failed to register event handler: %w", err)
+		}
+	}
+	return nil
+}
+
+// List returns the cached objects matching the selector, in namespace ns or in all watched
+// namespaces if ns is empty. Objects are shared with the cache and must not be modified.
+func (r *Resource) List(ns string, selector labels.Selector) ([]any, error) {
+	var objects []any
+	appendFn := func(obj any) { objects = append(objects, obj) }
+	for _, informer := range r.informers {
+		var err error
+		if ns == metav1.NamespaceAll {
+			err = cache.ListAll(informer.GetIndexer(), selector, appendFn)
+		} else {
+			err = cache.ListAllByNamespace(informer.GetIndexer(), ns, selector, appendFn)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cached objects: %w", err)
+		}
+	}
+	return objects, nil
+}
+
+// Get returns the cached object with the given namespace and name, and whether it exists.
+// Cluster-scoped objects have an empty namespace.
+func (r *Resource) Get(ns, name string) (any, bool, error) {
+	key := name
+	if ns != "" {
+		key = ns + "/" + name
+	}
+	for _, informer := range r.informers {
+		obj, exists, err := informer.GetIndexer().GetByKey(key)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get cached object %s: %w", key, err)
+		}
+		if exists {
+			return obj, true, nil
+		}
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,113 @@
+// Package informers contains tests for the shared informer manager.
+// This file tests listing, getting and watching a Resource across namespaces.
+package informers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// TestResourceList verifies listing by namespace and label selector across namespace informers.
+func TestResourceList(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		testDeployment("web", "frontend", map[string]string{"tier": "web"}),
+		testDeployment("web", "worker", nil),
+		testDeployment("api", "backend", map[string]string{"tier": "web"}),
+	)
+	m := New(clientset, Options{Namespaces: []string{"web", "api"}}, zerolog.Nop())
+	resource := m.Register(deployments)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		ns       string
+		selector string
+		want     string
+	}{
+		{"all namespaces", "", "", "api/backend,web/frontend,web/worker"},
+		{"namespace", "web", "", "web/frontend,web/worker"},
+		{"selector", "", "tier=web", "api/backend,web/frontend"},
+		{"namespace and selector", "api", "tier=web", "api/backend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := labels.Parse(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			objects, err := resource.List(tt.ns, selector)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var keys []string
+			for _, obj := range objects {
+				d := obj.(*appsv1.Deployment)
+				keys = append(keys, d.Namespace+"/"+d.Name)
+			}
+			sort.Strings(keys)
+			if got := strings.Join(keys, ","); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestResourceAddEventHandler verifies that handlers receive events of all watched namespaces.
+func TestResourceAddEventHandler(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	m := New(clientset, Options{Namespaces: []string{"web", "api"}}, zerolog.Nop())
+	resource := m.Register(deployments)
+
+	var mu sync.Mutex
+	var added []string
+	if err := resource.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			mu.Lock()
+			defer mu.Unlock()
+			added = append(added, obj.(*appsv1.Deployment).Name)
+		},
+	}); err != nil {
+		t.Fatalf("AddEventHandler() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, d := range []*appsv1.Deployment{testDeployment("web", "a", nil), testDeployment("api", "b", nil)} {
+		if _, err := clientset.AppsV1().Deployments(d.Namespace).Create(ctx, d, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		count := len(added)
+		mu.Unlock()
+		if count == 2 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 add events, got %d", count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,148 @@
+// Package journal records the cluster change stream to disk for post-incident review.
+// Informer events are appended to rotating NDJSON files together with resourceVersion
+// checkpoints, and can later be queried by time range, kind and namespace.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event types recorded in the journal.
+const (
+	EventAdded    = "ADDED"
+	EventModified = "MODIFIED"
+	EventDeleted  = "DELETED"
+
+	// EventSynced marks objects found changed when recording (re)started; the change
+	// happened while no recorder was running, so its exact time is unknown.
+	EventSynced = "SYNCED"
+)
+
+// filePrefix and fileSuffix delimit journal file names; the timestamp in between sorts chronologically.
+const (
+	filePrefix     = "journal-"
+	fileSuffix     = ".ndjson"
+	fileTimeLayout = "20060102T150405.000000000Z"
+)
+
+// maxLineBytes bounds a single journal line, which may hold a full object.
+const maxLineBytes = 16 << 20
+
+// Entry is a single journaled change.
+type Entry struct {
+	Time            time.Time      `json:"time"`
+	Type            string         `json:"type"`
+	Kind            string         `json:"kind"`
+	Namespace       string         `json:"namespace,omitempty"`
+	Name            string         `json:"name"`
+	ResourceVersion string         `json:"resourceVersion"`
+	Object          map[string]any `json:"object,omitempty"`
+}
+
+// Filter selects journal entries. Zero values match everything.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	Kind      string
+	Namespace string
+}
+
+// Matches reports whether an entry passes the filter. Kinds are compared case-insensitively.
+func (f Filter) Matches(e *Entry) bool {
+	switch {
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && e.Time.After(f.Until):
+		return false
+	case f.Kind != "" && !strings.EqualFold(f.Kind, e.Kind):
+		return false
+	case f.Namespace != "" && f.Namespace != e.Namespace:
+		return false
+	default:
+		return true
+	}
+}
+
+// Query reads the journal files in dir oldest first and calls emit for each entry matching the filter.
+// Files that ended before Since are skipped without being read.
+func Query(dir string, filter Filter, emit func(Entry) error) error {
+	files, err := journalFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for i, file := range files {
+		// A file only holds entries up to the start of the next one.
+		if i+1 < len(files) && !filter.Since.IsZero() && fileStart(files[i+1]).Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && fileStart(file).After(filter.Until) {
+			break
+		}
+		if err := queryFile(file, filter, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryFile emits the matching entries of a single journal file.
+func queryFile(path string, filter Filter, emit func(Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open journal file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash can leave a truncated last line; it is not worth failing the whole query.
+			continue
+		}
+		if !filter.Matches(&entry) {
+			continue
+		}
+		if err := emit(entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read journal file %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// journalFiles returns the journal files in dir, oldest first.
+func journalFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal files: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// fileName returns the journal file name for a file started at the given time.
+func fileName(start time.Time) string {
+	return filePrefix + start.UTC().Format(fileTimeLayout) + fileSuffix
+}
+
+// fileStart parses the start time from a journal file path. Unparsable names yield the zero time.
+func fileStart(path string) time.Time {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), filePrefix), fileSuffix)
+	start, err := time.Parse(fileTimeLayout, name)
+	if err != nil {
+		return time.Time{}
+	}
+	return start
+}
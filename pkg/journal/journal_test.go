@@ -0,0 +1,89 @@
+// Package journal contains tests for the event journal.
+// This file tests entry filtering and querying across journal files.
+package journal
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testStart is the reference time of test entries.
+var testStart = time.Date(2026, 10, 15, 14, 0, 0, 0, time.UTC)
+
+// testEntry builds a modification entry i minutes after testStart with resourceVersion 100+i.
+func testEntry(i int, kind, name string) Entry {
+	return Entry{
+		Time:            testStart.Add(time.Duration(i) * time.Minute),
+		Type:            EventModified,
+		Kind:            kind,
+		Namespace:       "shop",
+		Name:            name,
+		ResourceVersion: strconv.Itoa(100 + i),
+	}
+}
+
+// TestFilterMatches verifies each filter criterion.
+func TestFilterMatches(t *testing.T) {
+	entry := testEntry(5, "Deployment", "web")
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty filter", Filter{}, true},
+		{"inside range", Filter{Since: testStart, Until: testStart.Add(10 * time.Minute)}, true},
+		{"before since", Filter{Since: testStart.Add(6 * time.Minute)}, false},
+		{"after until", Filter{Until: testStart.Add(4 * time.Minute)}, false},
+		{"kind case-insensitive", Filter{Kind: "deployment"}, true},
+		{"other kind", Filter{Kind: "Pod"}, false},
+		{"other namespace", Filter{Namespace: "default"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(&entry); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQuery verifies time range queries spanning several files.
+func TestQuery(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWriter(t, dir, WriterOptions{MaxFileBytes: 400})
+	for i := range 30 {
+		kind := "Deployment"
+		if i%2 == 1 {
+			kind = "Pod"
+		}
+		w.now = func() time.Time { return testStart.Add(time.Duration(i) * time.Minute) }
+		if err := w.Write(testEntry(i, kind, "web")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var got []string
+	filter := Filter{Since: testStart.Add(14 * time.Minute), Until: testStart.Add(20 * time.Minute), Kind: "pod"}
+	if err := Query(dir, filter, func(e Entry) error {
+		got = append(got, e.ResourceVersion)
+		return nil
+	}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	want := []string{"115", "117", "119"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: expected %s, got %s", i, want[i], got[i])
+		}
+	}
+}
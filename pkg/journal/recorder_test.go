@@ -0,0 +1,108 @@
+// Package journal contains tests for the event journal.
+// This file tests recording informer events and resuming from checkpoints.
+package journal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// newConfigMap builds an unstructured ConfigMap with the given resourceVersion.
+func newConfigMap(name, resourceVersion string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace("shop")
+	u.SetName(name)
+	u.SetResourceVersion(resourceVersion)
+	return u
+}
+
+// runRecorder records events of the fake client while fn runs and returns the journaled entries.
+func runRecorder(t *testing.T, dir string, client *dynamicfake.FakeDynamicClient, fn func()) []Entry {
+	t.Helper()
+	configMaps, err := k8s.LookupResource("configmaps")
+	if err != nil {
+		t.Fatalf("LookupResource() error = %v", err)
+	}
+	writer, err := NewWriter(dir, WriterOptions{})
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	recorder := NewRecorder(client, []k8s.ResourceInfo{configMaps}, writer, zerolog.Nop(),
+		RecorderOptions{IncludeObjects: true, SyncInterval: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- recorder.Run(ctx) }()
+
+	// Give the informer time to list and start watching before changing objects.
+	time.Sleep(100 * time.Millisecond)
+	fn()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var entries []Entry
+	if err := Query(dir, Filter{}, func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	return entries
+}
+
+// TestRecorder verifies journaling of changes and skipping unchanged objects after a restart.
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newConfigMap("existing", "10"))
+	configMaps, _ := k8s.LookupResource("configmaps")
+	resource := client.Resource(configMaps.GVR).Namespace("shop")
+	ctx := context.Background()
+
+	entries := runRecorder(t, dir, client, func() {
+		if _, err := resource.Create(ctx, newConfigMap("created", "11"), metav1.CreateOptions{}); err != nil {
+			t.Errorf("Create() error = %v", err)
+		}
+		if _, err := resource.Update(ctx, newConfigMap("existing", "12"), metav1.UpdateOptions{}); err != nil {
+			t.Errorf("Update() error = %v", err)
+		}
+		if err := resource.Delete(ctx, "created", metav1.DeleteOptions{}); err != nil {
+			t.Errorf("Delete() error = %v", err)
+		}
+	})
+
+	wantTypes := []string{EventSynced, EventAdded, EventModified, EventDeleted}
+	if len(entries) != len(wantTypes) {
+		t.Fatalf("expected %d entries, got %+v", len(wantTypes), entries)
+	}
+	for i, want := range wantTypes {
+		if entries[i].Type != want || entries[i].Kind != "ConfigMap" {
+			t.Errorf("entry %d: expected %s ConfigMap, got %s %s", i, want, entries[i].Type, entries[i].Kind)
+		}
+	}
+	if entries[0].Object == nil {
+		t.Error("expected object to be included")
+	}
+
+	// After a restart, the unchanged object is covered by the checkpoint and not journaled again.
+	restarted := runRecorder(t, dir, client, func() {})
+	if len(restarted) != len(entries) {
+		t.Errorf("expected no new entries after restart, got %+v", restarted[len(entries):])
+	}
+}
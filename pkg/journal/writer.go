@@ -0,0 +1,181 @@
+// Package journal records the cluster change stream to disk for post-incident review.
+// This file implements the rotating NDJSON writer and its resourceVersion checkpoints.
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Rotation defaults.
+const (
+	DefaultMaxFileBytes = 64 << 20
+	DefaultMaxFiles     = 10
+)
+
+// checkpointFile stores the last journaled resourceVersion per kind.
+const checkpointFile = "checkpoint.json"
+
+// WriterOptions configures file rotation.
+type WriterOptions struct {
+	// MaxFileBytes is the size after which a new file is started. Zero uses DefaultMaxFileBytes.
+	MaxFileBytes int64
+
+	// MaxFiles is the number of files kept; older files are deleted. Zero uses DefaultMaxFiles.
+	MaxFiles int
+}
+
+// Writer appends entries to rotating journal files in a directory.
+// It is safe for concurrent use.
+type Writer struct {
+	dir  string
+	opts WriterOptions
+	now  func() time.Time
+
+	mu          sync.Mutex
+	file        *os.File
+	size        int64
+	checkpoints map[string]string
+}
+
+// NewWriter creates a Writer for dir, creating the directory and loading existing checkpoints.
+func NewWriter(dir string, opts WriterOptions) (*Writer, error) {
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = DefaultMaxFileBytes
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultMaxFiles
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	checkpoints := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(dir, checkpointFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &checkpoints); err != nil {
+			return nil, fmt.Errorf("failed to parse journal checkpoint: %w", err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("failed to read journal checkpoint: %w", err)
+	}
+
+	return &Writer{dir: dir, opts: opts, now: time.Now, checkpoints: checkpoints}, nil
+}
+
+// Checkpoint returns the last journaled resourceVersion of a kind, or "" if none was recorded.
+func (w *Writer) Checkpoint(kind string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.checkpoints[kind]
+}
+
+// Write appends an entry, starting a new file first if the current one is full.
+func (w *Writer) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil || w.size+int64(len(line)) > w.opts.MaxFileBytes {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	if newerThan(entry.ResourceVersion, w.checkpoints[entry.Kind]) {
+		w.checkpoints[entry.Kind] = entry.ResourceVersion
+	}
+	return nil
+}
+
+// Sync flushes the current file to stable storage and persists the checkpoints.
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sync()
+}
+
+// Close syncs and closes the current file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.sync()
+	if w.file != nil {
+		if closeErr := w.file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close journal file: %w", closeErr)
+		}
+		w.file = nil
+	}
+	return err
+}
+
+// sync flushes the current file and atomically replaces the checkpoint file. Callers hold w.mu.
+func (w *Writer) sync() error {
+	if w.file != nil {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync journal file: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(w.checkpoints)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal checkpoint: %w", err)
+	}
+	tmp := filepath.Join(w.dir, checkpointFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("failed to write journal checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(w.dir, checkpointFile)); err != nil {
+		return fmt.Errorf("failed to replace journal checkpoint: %w", err)
+	}
+	return nil
+}
+
+// rotate closes the current file, starts a new one and deletes the oldest files beyond MaxFiles.
+// Callers hold w.mu.
+func (w *Writer) rotate() error {
+	if w.file != nil {
+		if err := w.sync(); err != nil {
+			return err
+		}
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("failed to close journal file: %w", err)
+		}
+		w.file = nil
+	}
+
+	file, err := os.OpenFile(filepath.Join(w.dir, fileName(w.now())), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create journal file: %w", err)
+	}
+	w.file, w.size = file, 0
+
+	files, err := journalFiles(w.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > w.opts.MaxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			return fmt.Errorf("failed to delete old journal file: %w", err)
+		}
+		files = files[1:]
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package journal contains tests for the event journal.
+// This file tests file rotation and checkpoint persistence of the writer.
+package journal
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestWriter creates a writer in a temporary directory whose clock advances one second per file.
+func newTestWriter(t *testing.T, dir string, opts WriterOptions) *Writer {
+	t.Helper()
+	w, err := NewWriter(dir, opts)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	clock := testStart
+	w.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return w
+}
+
+// TestWriterRotation verifies that full files are rotated and old files are deleted.
+func TestWriterRotation(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWriter(t, dir, WriterOptions{MaxFileBytes: 200, MaxFiles: 3})
+
+	for i := range 20 {
+		if err := w.Write(testEntry(i, "Deployment", "web")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	files, err := journalFiles(dir)
+	if err != nil {
+		t.Fatalf("journalFiles() error = %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files to be kept, got %d", len(files))
+	}
+
+	var entries []Entry
+	if err := Query(dir, Filter{}, func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(entries) == 0 || entries[len(entries)-1].ResourceVersion != "119" {
+		t.Errorf("expected newest entries to be kept, got %+v", entries)
+	}
+}
+
+// TestWriterCheckpoints verifies that checkpoints only advance and survive a restart.
+func TestWriterCheckpoints(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWriter(t, dir, WriterOptions{})
+
+	for _, i := range []int{5, 9, 7} {
+		if err := w.Write(testEntry(i, "Pod", "web")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if got := w.Checkpoint("Pod"); got != "109" {
+		t.Errorf("expected checkpoint 109, got %q", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := newTestWriter(t, dir, WriterOptions{})
+	if got := reopened.Checkpoint("Pod"); got != "109" {
+		t.Errorf("expected persisted checkpoint 109, got %q", got)
+	}
+	if got := reopened.Checkpoint("Deployment"); got != "" {
+		t.Errorf("expected no checkpoint for unrecorded kind, got %q", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements RBAC self-checks via SelfSubjectAccessReview and SelfSubjectRulesReview,
+// and checks of other users via SubjectAccessReview.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessCheck describes an action the current user wants to perform.
+type AccessCheck struct {
+	// Verb is the API verb, e.g. "get", "list" or "delete".
+	Verb string
+
+	// Resource is a resource name or alias, optionally qualified with its group ("widgets.example.com")
+	// and a subresource ("pods/log"). "*" means all resources.
+	Resource string
+
+	// Namespace is the namespace of the action. Empty means all namespaces or a cluster-scoped resource.
+	Namespace string
+
+	// Name optionally restricts the check to a single object.
+	Name string
+}
+
+// AccessResult is the outcome of an access check.
+type AccessResult struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// PermissionRule is a rule the current user is granted.
+type PermissionRule struct {
+	Verbs           []string `json:"verbs"`
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+// CanI asks the API server whether the current user may perform the given action.
+func (c *Client) CanI(ctx context.Context, check AccessCheck) (AccessResult, error) {
+	attrs := resourceAttributes(check)
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attrs},
+	}
+
+	result, err := c.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return AccessResult{}, fmt.Errorf("failed to review access: %w", err)
+	}
+
+	c.logger.Debug().
+		Str("verb", attrs.Verb).
+		Str("group", attrs.Group).
+		Str("resource", attrs.Resource).
+		Str("namespace", attrs.Namespace).
+		Bool("allowed", result.Status.Allowed).
+		Msg("Access reviewed")
+
+	reason := result.Status.Reason
+	if reason == "" {
+		reason = result.Status.EvaluationError
+	}
+	return AccessResult{Allowed: result.Status.Allowed && !result.Status.Denied, Reason: reason}, nil
+}
+
+// ReviewSubjectAccess asks the API server whether a user, e.g. one authenticated with ReviewToken,
+// may request a non-resource URL with a verb, e.g. "get" on "/api/v1/deployments". Non-resource URLs
+// are granted with the nonResourceURLs of ClusterRoles.
+func (c *Client) ReviewSubjectAccess(ctx context.Context, user Identity, verb, path string) (AccessResult, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, values := range user.Extra {
+		extra[key] = values
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:                  user.Username,
+			UID:                   user.UID,
+			Groups:                user.Groups,
+			Extra:                 extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: path, Verb: verb},
+		},
+	}
+
+	result, err := c.clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return AccessResult{}, fmt.Errorf("failed to review access: %w", err)
+	}
+
+	c.logger.Debug().
+		Str("user", user.Username).
+		Str("verb", verb).
+		Str("path", path).
+		Bool("allowed", result.Status.Allowed).
+		Msg("Subject access reviewed")
+
+	reason := result.Status.Reason
+	if reason == "" {
+		reason = result.Status.EvaluationError
+	}
+	return AccessResult{Allowed: result.Status.Allowed && !result.Status.Denied, Reason: reason}, nil
+}
+
+// ListPermissions returns the rules the current user is granted in a namespace.
+// incomplete reports that the API server could not evaluate all rules, e.g. because
+// an external authorizer is in use; the returned rules are then a lower bound.
+func (c *Client) ListPermissions(ctx context.Context, ns string) ([]PermissionRule, bool, error) {
+	if ns == "" {
+		ns = "default"
+	}
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: ns},
+	}
+
+	result, err := c.clientset.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to review rules: %w", err)
+	}
+
+	rules := make([]PermissionRule, 0, len(result.Status.ResourceRules)+len(result.Status.NonResourceRules))
+	for _, rule := range result.Status.ResourceRules {
+		rules = append(rules, PermissionRule{
+			Verbs:         rule.Verbs,
+			APIGroups:     rule.APIGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+		})
+	}
+	for _, rule := range result.Status.NonResourceRules {
+		rules = append(rules, PermissionRule{Verbs: rule.Verbs, NonResourceURLs: rule.NonResourceURLs})
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return strings.Join(rules[i].Resources, ",") < strings.Join(rules[j].Resources, ",")
+	})
+	return rules, result.Status.Incomplete, nil
+}
+
+// resourceAttributes resolves the resource of an access check into review attributes.
+// Known resources and aliases are resolved to their group; others may be qualified as "resource.group".
+func resourceAttributes(check AccessCheck) *authorizationv1.ResourceAttributes {
+	resource, subresource, _ := strings.Cut(check.Resource, "/")
+	attrs := &authorizationv1.ResourceAttributes{
+		Verb:        check.Verb,
+		Namespace:   check.Namespace,
+		Name:        check.Name,
+		Subresource: subresource,
+	}
+
+	if info, err := LookupResource(resource); err == nil {
+		attrs.Group, attrs.Version, attrs.Resource = info.GVR.Group, info.GVR.Version, info.GVR.Resource
+		if !info.Namespaced {
+			attrs.Namespace = ""
+		}
+		return attrs
+	}
+
+	attrs.Resource, attrs.Group, _ = strings.Cut(resource, ".")
+	return attrs
+}
@@ -0,0 +1,158 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests RBAC self-checks.
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestResourceAttributes verifies resolution of resource names, aliases, groups and subresources.
+func TestResourceAttributes(t *testing.T) {
+	tests := []struct {
+		name            string
+		check           AccessCheck
+		wantGroup       string
+		wantResource    string
+		wantSubresource string
+		wantNamespace   string
+	}{
+		{"alias", AccessCheck{Verb: "get", Resource: "deploy", Namespace: testNamespaceDefault},
+			"apps", "deployments", "", testNamespaceDefault},
+		{"subresource", AccessCheck{Verb: "get", Resource: "pods/log", Namespace: testNamespaceDefault},
+			"", "pods", "log", testNamespaceDefault},
+		{"cluster-scoped drops namespace", AccessCheck{Verb: "list", Resource: "nodes", Namespace: "shop"},
+			"", "nodes", "", ""},
+		{"qualified custom resource", AccessCheck{Verb: "create", Resource: "widgets.example.com"},
+			"example.com", "widgets", "", ""},
+		{"wildcard", AccessCheck{Verb: "*", Resource: "*"}, "", "*", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := resourceAttributes(tt.check)
+			if attrs.Group != tt.wantGroup || attrs.Resource != tt.wantResource ||
+				attrs.Subresource != tt.wantSubresource || attrs.Namespace != tt.wantNamespace {
+				t.Errorf("unexpected attributes %+v", attrs)
+			}
+		})
+	}
+}
+
+// TestCanI verifies that the review outcome and reason are reported.
+func TestCanI(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+	client.clientset.(*fake.Clientset).PrependReactor("create", "selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attrs := review.Spec.ResourceAttributes
+			review.Status.Allowed = attrs.Verb == "get" && attrs.Resource == "pods"
+			if !review.Status.Allowed {
+				review.Status.Reason = "no RBAC policy matched"
+			}
+			return true, review, nil
+		})
+
+	tests := []struct {
+		verb, resource string
+		want           bool
+	}{
+		{"get", "pods", true},
+		{"delete", "pods", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verb, func(t *testing.T) {
+			result, err := client.CanI(context.Background(), AccessCheck{Verb: tt.verb, Resource: tt.resource})
+			if err != nil {
+				t.Fatalf("CanI() error = %v", err)
+			}
+			if result.Allowed != tt.want {
+				t.Errorf("CanI() allowed = %v, want %v", result.Allowed, tt.want)
+			}
+			if !tt.want && result.Reason == "" {
+				t.Error("expected a reason for a denied action")
+			}
+		})
+	}
+}
+
+// TestReviewSubjectAccess verifies that the user and non-resource URL are sent in the SubjectAccessReview.
+func TestReviewSubjectAccess(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+	client.clientset.(*fake.Clientset).PrependReactor("create", "subjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			attrs := review.Spec.NonResourceAttributes
+			review.Status.Allowed = review.Spec.User == testUsername && len(review.Spec.Groups) == 1 &&
+				len(review.Spec.Extra["scopes"]) == 1 && attrs.Verb == "get" && attrs.Path == "/api/v1/pods"
+			if !review.Status.Allowed {
+				review.Status.Reason = "no RBAC policy matched"
+			}
+			return true, review, nil
+		})
+	user := Identity{Username: testUsername, Groups: []string{"system:serviceaccounts"},
+		Extra: map[string][]string{"scopes": {"read"}}}
+
+	tests := []struct {
+		verb, path string
+		want       bool
+	}{
+		{"get", "/api/v1/pods", true},
+		{"delete", "/api/v1/pods", false},
+		{"get", "/api/v1/nodes", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verb+" "+tt.path, func(t *testing.T) {
+			result, err := client.ReviewSubjectAccess(context.Background(), user, tt.verb, tt.path)
+			if err != nil {
+				t.Fatalf("ReviewSubjectAccess() error = %v", err)
+			}
+			if result.Allowed != tt.want || (!tt.want && result.Reason == "") {
+				t.Errorf("ReviewSubjectAccess() = %+v, want allowed %v with a reason if denied", result, tt.want)
+			}
+		})
+	}
+}
+
+// TestListPermissions verifies conversion of resource and non-resource rules.
+func TestListPermissions(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+	var requested string
+	client.clientset.(*fake.Clientset).PrependReactor("create", "selfsubjectrulesreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview)
+			requested = review.Spec.Namespace
+			review.Status = authorizationv1.SubjectRulesReviewStatus{
+				ResourceRules: []authorizationv1.ResourceRule{
+					{Verbs: []string{"get", "list"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}},
+				},
+				NonResourceRules: []authorizationv1.NonResourceRule{
+					{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+				},
+				Incomplete: true,
+			}
+			return true, review, nil
+		})
+
+	rules, incomplete, err := client.ListPermissions(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListPermissions() error = %v", err)
+	}
+	if requested != testNamespaceDefault {
+		t.Errorf("expected review in the default namespace, got %q", requested)
+	}
+	if len(rules) != 2 || !incomplete {
+		t.Fatalf("expected 2 rules and incomplete result, got %+v (%v)", rules, incomplete)
+	}
+	if rules[0].NonResourceURLs[0] != "/healthz" || rules[1].Resources[0] != "deployments" {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+}
@@ -0,0 +1,78 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements server-side apply of manifests.
+package k8s
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// AppliedObject identifies an object written by Apply.
+type AppliedObject struct {
+	Kind      string `json:"kind" yaml:"kind"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+
+	// Created reports that the object did not exist before the apply.
+	Created bool `json:"created" yaml:"created"`
+}
+
+// Apply server-side applies each manifest in order, taking ownership of conflicting fields.
+// Namespaced objects without a namespace are applied in ns, which defaults to "default".
+// Each change is submitted to the authorization hook before its API call is made;
+// the objects applied before an error are returned along with it.
+func (c *Client) Apply(ctx context.Context, objects []*unstructured.Unstructured,
+	ns string) ([]AppliedObject, error) {
+	applied := make([]AppliedObject, 0, len(objects))
+	for _, obj := range objects {
+		result, err := c.applyObject(ctx, obj.DeepCopy(), ns)
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, result)
+	}
+	return applied, nil
+}
+
+// applyObject server-side applies a single manifest.
+func (c *Client) applyObject(ctx context.Context, obj *unstructured.Unstructured, ns string) (AppliedObject, error) {
+	info, err := LookupResource(obj.GetKind())
+	if err != nil {
+		return AppliedObject{}, err
+	}
+	setObjectNamespace(obj, info, ns)
+
+	result := AppliedObject{Kind: info.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "apply",
+		Resource:  info.GVR.Resource,
+		Namespace: result.Namespace,
+		Name:      result.Name,
+	}); err != nil {
+		return AppliedObject{}, err
+	}
+
+	if _, err := c.GetUnstructured(ctx, info.GVR, result.Namespace, result.Name); apierrors.IsNotFound(err) {
+		result.Created = true
+	} else if err != nil {
+		return AppliedObject{}, err
+	}
+
+	if _, err := c.applyUnstructured(ctx, info.GVR, obj); err != nil {
+		return AppliedObject{}, err
+	}
+	return result, nil
+}
+
+// setObjectNamespace clears the namespace of cluster-scoped objects and defaults it for namespaced ones.
+func setObjectNamespace(obj *unstructured.Unstructured, info ResourceInfo, ns string) {
+	if !info.Namespaced {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(defaultNamespace(ns))
+	}
+}
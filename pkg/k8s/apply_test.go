@@ -0,0 +1,62 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests server-side apply of manifests.
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestApply verifies that manifests create new objects and update existing ones.
+func TestApply(t *testing.T) {
+	client := newDiffTestClient()
+	objects, err := DecodeManifests([]byte(testDiffManifests))
+	if err != nil {
+		t.Fatalf("DecodeManifests() error = %v", err)
+	}
+
+	applied, err := client.Apply(context.Background(), objects[:2], "")
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := []AppliedObject{
+		{Kind: "ConfigMap", Namespace: testNamespaceDefault, Name: "settings"},
+		{Kind: "ConfigMap", Namespace: testNamespaceDefault, Name: "flags", Created: true},
+	}
+	if len(applied) != len(want) || applied[0] != want[0] || applied[1] != want[1] {
+		t.Errorf("Apply() = %+v, want %+v", applied, want)
+	}
+
+	configMaps := client.dynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).
+		Namespace(testNamespaceDefault)
+	for name, want := range map[string][]string{"settings": {"mode", "fast"}, "flags": {"beta", "true"}} {
+		cm, err := configMaps.Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected configmap %q, got %v", name, err)
+		}
+		if got, _, _ := unstructured.NestedString(cm.Object, "data", want[0]); got != want[1] {
+			t.Errorf("expected %s=%s in configmap %q, got %q", want[0], want[1], name, got)
+		}
+	}
+}
+
+// TestApplyDenied verifies that the authorization hook stops an apply before any change.
+func TestApplyDenied(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+	client.SetAuthorizer(denyAuthorizer{})
+	objects, _ := DecodeManifests([]byte(testDiffManifests))
+
+	applied, err := client.Apply(context.Background(), objects, "web")
+	if err == nil || len(applied) != 0 {
+		t.Fatalf("expected the apply to be denied, got %+v (%v)", applied, err)
+	}
+	if _, err := client.dynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("web").
+		Get(context.Background(), "settings", metav1.GetOptions{}); err == nil {
+		t.Error("expected no object to be created")
+	}
+}
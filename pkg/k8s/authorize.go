@@ -0,0 +1,37 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file integrates the pluggable authorization hook with mutating client operations.
+package k8s
+
+import (
+	"context"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// authorize consults the configured authorization hook before a mutating operation.
+// It must be called by every method that changes cluster state, before the API call is made.
+func (c *Client) authorize(ctx context.Context, change authz.Change) error {
+	if err := authz.Check(ctx, c.authorizer, change); err != nil {
+		c.logger.Warn().
+			Err(err).
+			Str("operation", change.Operation).
+			Str("resource", change.Resource).
+			Str("namespace", change.Namespace).
+			Str("name", change.Name).
+			Msg("Mutating operation rejected by authorization hook")
+		return err
+	}
+
+	c.logger.Debug().
+		Str("operation", change.Operation).
+		Str("resource", change.Resource).
+		Str("name", change.Name).
+		Msg("Mutating operation authorized")
+	return nil
+}
+
+// SetAuthorizer replaces the authorization hook consulted before mutating operations.
+// Passing nil disables authorization checks.
+func (c *Client) SetAuthorizer(authorizer authz.Authorizer) {
+	c.authorizer = authorizer
+}
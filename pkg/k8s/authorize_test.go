@@ -0,0 +1,52 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests the integration of the authorization hook with the client.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// denyAuthorizer rejects every change.
+type denyAuthorizer struct{}
+
+// Authorize always denies the change.
+func (denyAuthorizer) Authorize(_ context.Context, _ authz.Change) (authz.Decision, error) {
+	return authz.Decision{Allowed: false, Reason: "denied for test"}, nil
+}
+
+// TestClientAuthorize verifies that the client consults the configured hook.
+func TestClientAuthorize(t *testing.T) {
+	tests := []struct {
+		name       string
+		authorizer authz.Authorizer
+		wantDenied bool
+	}{
+		{"no hook configured", nil, false},
+		{"allow all hook", authz.AllowAll{}, false},
+		{"deny hook", denyAuthorizer{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := setupTestClient(zerolog.New(os.Stderr), nil, false)
+			client.SetAuthorizer(tt.authorizer)
+
+			err := client.authorize(context.Background(), authz.Change{
+				Operation: "delete",
+				Resource:  "deployments",
+				Namespace: testNamespaceDefault,
+				Name:      testDeploymentNginx,
+			})
+			if errors.Is(err, authz.ErrDenied) != tt.wantDenied {
+				t.Errorf("authorize() error = %v, wantDenied %v", err, tt.wantDenied)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements client-side rate limiting and a circuit breaker for API server requests.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/rest"
+)
+
+// Circuit breaker defaults.
+const (
+	// DefaultBreakerThreshold is the number of consecutive failed requests that opens the circuit.
+	DefaultBreakerThreshold = 5
+
+	// DefaultBreakerCooldown is how long an open circuit rejects requests before letting one through.
+	DefaultBreakerCooldown = 10 * time.Second
+)
+
+// ErrCircuitOpen is returned for requests rejected by an open circuit breaker,
+// without contacting the API server.
+var ErrCircuitOpen = errors.New("circuit breaker open after repeated API server failures")
+
+// CircuitBreaker stops requests to an API server that keeps failing. After Threshold consecutive
+// server errors (5xx) or connection failures the circuit opens and requests fail fast with
+// ErrCircuitOpen. After the cooldown one probe request is let through: if it succeeds the circuit
+// closes, otherwise it opens again.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	logger    zerolog.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. A cooldown of zero uses DefaultBreakerCooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration, logger zerolog.Logger) *CircuitBreaker {
+	if cooldown == 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, logger: logger, now: time.Now}
+}
+
+// Open reports whether the circuit is open, i.e. requests are being rejected.
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold
+}
+
+// allow reports whether a request may be sent. While the circuit is open, only a single
+// probe is allowed once the cooldown has passed.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record updates the circuit with the outcome of a request.
+func (b *CircuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := b.failures >= b.threshold
+	b.probing = false
+
+	if !failed {
+		if wasOpen {
+			b.logger.Info().Msg("API server recovered, circuit breaker closed")
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		if !wasOpen {
+			b.logger.Warn().Int("failures", b.failures).Dur("cooldown", b.cooldown).
+				Msg("API server keeps failing, circuit breaker opened")
+		}
+		b.openedAt = b.now()
+	}
+}
+
+// abandon ends the probe of a request the caller gave up on, so that the next request probes again. The
+// circuit is left as it was, since the request said nothing about the API server.
+func (b *CircuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Wrap returns a round tripper that sends requests through the circuit breaker.
+func (b *CircuitBreaker) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return &breakerRoundTripper{breaker: b, next: rt}
+}
+
+// breakerRoundTripper rejects requests while the circuit is open and records the outcome of the others.
+type breakerRoundTripper struct {
+	breaker *CircuitBreaker
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *breakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !rt.breaker.allow() {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, ErrCircuitOpen)
+	}
+	resp, err := rt.next.RoundTrip(req)
+	switch {
+	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		// The caller gave up; this says nothing about the API server.
+		rt.breaker.abandon()
+	case err != nil:
+		rt.breaker.record(true)
+	default:
+		rt.breaker.record(resp.StatusCode >= http.StatusInternalServerError)
+	}
+	return resp, err
+}
+
+// applyRateLimits sets the client-side rate limit of the REST config and wraps its transport in
+// a circuit breaker. Zero QPS and burst keep the client-go defaults; a zero threshold disables the breaker.
+func applyRateLimits(restConfig *rest.Config, config ClientConfig, logger zerolog.Logger) {
+	if config.QPS > 0 {
+		restConfig.QPS = config.QPS
+	}
+	if config.Burst > 0 {
+		restConfig.Burst = config.Burst
+	}
+	if config.BreakerThreshold <= 0 {
+		return
+	}
+	breaker := NewCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown, logger)
+	restConfig.Wrap(breaker.Wrap)
+	logger.Debug().Float32("qps", restConfig.QPS).Int("burst", restConfig.Burst).
+		Int("breaker_threshold", config.BreakerThreshold).Msg("Configured API rate limits")
+}
@@ -0,0 +1,192 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests client-side rate limiting and the circuit breaker.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/rest"
+)
+
+// stubRoundTripper answers requests with a fixed status code or error and counts them.
+type stubRoundTripper struct {
+	status int
+	err    error
+	calls  int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (s *stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &http.Response{StatusCode: s.status, Body: http.NoBody, Request: req}, nil
+}
+
+// TestCircuitBreaker verifies opening after repeated failures, failing fast, probing after the
+// cooldown and closing again on success.
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	breaker := NewCircuitBreaker(3, time.Minute, zerolog.Nop())
+	breaker.now = func() time.Time { return now }
+	stub := &stubRoundTripper{status: http.StatusServiceUnavailable}
+	rt := breaker.Wrap(stub)
+	send := func() error {
+		req := httptest.NewRequest(http.MethodGet, "https://api/apis/apps/v1/deployments", nil)
+		_, err := rt.RoundTrip(req)
+		return err
+	}
+
+	for range 3 {
+		if err := send(); err != nil {
+			t.Fatalf("expected server errors to be passed through, got %v", err)
+		}
+	}
+	if !breaker.Open() {
+		t.Fatal("expected the circuit to open after 3 server errors")
+	}
+	if err := send(); !errors.Is(err, ErrCircuitOpen) || stub.calls != 3 {
+		t.Fatalf("expected a fast failure without a request, got %v after %d calls", err, stub.calls)
+	}
+
+	// After the cooldown a failing probe opens the circuit again.
+	now = now.Add(time.Minute)
+	if err := send(); err != nil || stub.calls != 4 {
+		t.Fatalf("expected a probe request, got %v after %d calls", err, stub.calls)
+	}
+	if err := send(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the failed probe to reopen the circuit, got %v", err)
+	}
+
+	// A successful probe closes it.
+	now = now.Add(time.Minute)
+	stub.status = http.StatusOK
+	if err := send(); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if breaker.Open() {
+		t.Error("expected the circuit to close after a successful probe")
+	}
+}
+
+// TestCircuitBreakerCancelledProbe verifies that a probe cancelled by its caller leaves the circuit open
+// and lets the next request probe again.
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	breaker := NewCircuitBreaker(1, time.Minute, zerolog.Nop())
+	breaker.now = func() time.Time { return now }
+	stub := &stubRoundTripper{status: http.StatusServiceUnavailable}
+	rt := breaker.Wrap(stub)
+	send := func() error {
+		req := httptest.NewRequest(http.MethodGet, "https://api/apis/apps/v1/deployments", nil)
+		_, err := rt.RoundTrip(req)
+		return err
+	}
+
+	_ = send()
+	now = now.Add(time.Minute)
+	stub.err = context.Canceled
+	if err := send(); !errors.Is(err, context.Canceled) || stub.calls != 2 {
+		t.Fatalf("expected the cancelled probe to be sent, got %v after %d calls", err, stub.calls)
+	}
+	if !breaker.Open() {
+		t.Fatal("expected the circuit to stay open after a cancelled probe")
+	}
+
+	stub.err = nil
+	if err := send(); err != nil || stub.calls != 3 {
+		t.Fatalf("expected another probe, got %v after %d calls", err, stub.calls)
+	}
+	if !breaker.Open() {
+		t.Error("expected the failed probe to keep the circuit open")
+	}
+}
+
+// TestCircuitBreakerFailures verifies which outcomes count as API server failures.
+func TestCircuitBreakerFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		stub     *stubRoundTripper
+		wantOpen bool
+	}{
+		{"server error", &stubRoundTripper{status: http.StatusInternalServerError}, true},
+		{"connection error", &stubRoundTripper{err: errors.New("connection refused")}, true},
+		{"client error", &stubRoundTripper{status: http.StatusNotFound}, false},
+		{"cancelled by caller", &stubRoundTripper{err: context.Canceled}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := NewCircuitBreaker(1, 0, zerolog.Nop())
+			req := httptest.NewRequest(http.MethodGet, "https://api/api/v1/pods", nil)
+			_, _ = breaker.Wrap(tt.stub).RoundTrip(req)
+			if breaker.Open() != tt.wantOpen {
+				t.Errorf("expected open %v, got %v", tt.wantOpen, breaker.Open())
+			}
+		})
+	}
+}
+
+// TestApplyRateLimits verifies that QPS, burst and the circuit breaker are applied to the REST config.
+func TestApplyRateLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    ClientConfig
+		wantQPS   float32
+		wantBurst int
+		wantWrap  bool
+	}{
+		{"defaults", ClientConfig{}, 0, 0, false},
+		{"limits and breaker", ClientConfig{QPS: 50, Burst: 100, BreakerThreshold: 5}, 50, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restConfig := &rest.Config{}
+			applyRateLimits(restConfig, tt.config, zerolog.Nop())
+			if restConfig.QPS != tt.wantQPS || restConfig.Burst != tt.wantBurst {
+				t.Errorf("expected QPS %v and burst %d, got %v and %d", tt.wantQPS, tt.wantBurst,
+					restConfig.QPS, restConfig.Burst)
+			}
+			if (restConfig.WrapTransport != nil) != tt.wantWrap {
+				t.Errorf("expected a wrapped transport: %v", tt.wantWrap)
+			}
+		})
+	}
+}
+
+// TestCreateClientCircuitBreaker verifies that clients created with a breaker threshold fail fast
+// once the API server keeps failing.
+func TestCreateClientCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	client, err := CreateClient(ClientConfig{Server: srv.URL, Token: "token", BreakerThreshold: 2}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("CreateClient() error = %v", err)
+	}
+	for range 2 {
+		_, _ = client.ListDeployments(context.Background(), ListDeploymentsOptions{})
+	}
+	_, err = client.ListDeployments(context.Background(), ListDeploymentsOptions{})
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(ClassifyError(err), ErrUnreachable) {
+		t.Errorf("expected an unreachable circuit open error, got %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected 2 requests to reach the server, got %d", got)
+	}
+}
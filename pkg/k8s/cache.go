@@ -0,0 +1,215 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements the optional informer-backed read cache serving list and get requests.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/Searge/k8s-controller/pkg/informers"
+)
+
+// DefaultCacheResync is the resync period of the read cache's informers.
+const DefaultCacheResync = informers.DefaultResync
+
+// cacheResources selects the informers of the resources held by the read cache, by plural resource name.
+var cacheResources = map[string]informers.ResourceFunc{
+	"deployments": func(f clientinformers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Apps().V1().Deployments().Informer()
+	},
+	"pods": func(f clientinformers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Pods().Informer()
+	},
+	"services": func(f clientinformers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Services().Informer()
+	},
+	"nodes": func(f clientinformers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Nodes().Informer()
+	},
+	"namespaces": func(f clientinformers.SharedInformerFactory) cache.SharedIndexInformer {
+		return f.Core().V1().Namespaces().Informer()
+	},
+}
+
+// readCache holds the registered resources of the synced read cache, by plural resource name.
+type readCache struct {
+	resources map[string]*cachedResource
+}
+
+// cachedResource is a resource of the read cache and the time it last changed.
+type cachedResource struct {
+	*informers.Resource
+
+	// updated is when the last object was added, changed or deleted, in Unix nanoseconds.
+	updated atomic.Int64
+}
+
+// StartCache starts an informer-backed read cache and blocks until it has synced.
+// Afterwards deployments, pods, services, nodes and namespaces are listed from memory, kept up to
+// date by watches, instead of calling the API server. The informers stop when ctx is cancelled.
+// A resync of zero uses DefaultCacheResync.
+func (c *Client) StartCache(ctx context.Context, resync time.Duration) error {
+	if c.CacheSynced() {
+		return fmt.Errorf("read cache already started")
+	}
+
+	manager := informers.New(c.clientset, informers.Options{Resync: resync, StripManagedFields: true}, c.logger)
+	rc := &readCache{resources: make(map[string]*cachedResource, len(cacheResources))}
+	for name, get := range cacheResources {
+		resource := &cachedResource{Resource: manager.Register(get)}
+		if err := resource.AddEventHandler(resource.handler()); err != nil {
+			return fmt.Errorf("failed to start read cache: %w", err)
+		}
+		rc.resources[name] = resource
+	}
+
+	c.logger.Info().Int("resources", len(rc.resources)).Msg("Starting read cache")
+	if err := manager.Run(ctx); err != nil {
+		return fmt.Errorf("failed to sync read cache: %w", err)
+	}
+	// Until the cache is published, reads keep going to the API server.
+	c.cache.Store(rc)
+	c.logger.Info().Msg("Read cache synced")
+	return nil
+}
+
+// handler returns the event handler recording the changes of the resource. Resyncs replay the
+// cached objects unchanged, so updates keeping the resource version are not changes.
+func (r *cachedResource) handler() cache.ResourceEventHandler {
+	touch := func() { r.updated.Store(time.Now().UnixNano()) }
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(any) { touch() },
+		UpdateFunc: func(oldObj, newObj any) {
+			oldMeta, oldOK := oldObj.(metav1.Object)
+			newMeta, newOK := newObj.(metav1.Object)
+			if !oldOK || !newOK || oldMeta.GetResourceVersion() != newMeta.GetResourceVersion() {
+				touch()
+			}
+		},
+		DeleteFunc: func(any) { touch() },
+	}
+}
+
+// CacheUpdated returns when the read cache last saw an object of a resource, by its plural name, e.g.
+// "pods", being added, changed or deleted, which is when it synced for resources that did not change
+// since. It reports false if lists of the resource are not served from the read cache.
+func (c *Client) CacheUpdated(resource string) (time.Time, bool) {
+	rc := c.cache.Load()
+	if rc == nil || rc.resources[resource] == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, rc.resources[resource].updated.Load()), true
+}
+
+// CacheSynced reports whether reads are served from the read cache.
+func (c *Client) CacheSynced() bool {
+	return c.cache.Load() != nil
+}
+
+// cachedObjects lists the objects of a resource in namespace ns, or in all namespaces if ns is empty,
+// from the read cache. It reports false if the cache is not synced.
+func cachedObjects[T any](c *Client, resource, ns string) ([]T, bool, error) {
+	rc := c.cache.Load()
+	if rc == nil {
+		return nil, false, nil
+	}
+	cached, err := rc.resources[resource].List(ns, labels.Everything())
+	if err != nil {
+		return nil, true, err
+	}
+	// The copies share their fields with the cache, which the conversions to infos only read.
+	objects := make([]T, len(cached))
+	for i, obj := range cached {
+		objects[i] = *obj.(*T)
+	}
+	c.logger.Debug().Int("count", len(objects)).Msgf("Listed %s from read cache", resource)
+	return objects, true, nil
+}
+
+// cachedDeployments lists deployments from the read cache, ordered by namespace and name like
+// the API server. It reports false if the request cannot be served from the cache.
+func (c *Client) cachedDeployments(opts ListDeploymentsOptions) ([]DeploymentInfo, bool, error) {
+	rc := c.cache.Load()
+	if rc == nil || opts.FieldSelector != "" {
+		return nil, false, nil
+	}
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to list deployments: invalid label selector: %w", err)
+	}
+
+	cached, err := rc.resources["deployments"].List(opts.Namespace, selector)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	deployments := make([]appsv1.Deployment, len(cached))
+	for i, obj := range cached {
+		deployments[i] = *obj.(*appsv1.Deployment)
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		if deployments[i].Namespace != deployments[j].Namespace {
+			return deployments[i].Namespace < deployments[j].Namespace
+		}
+		return deployments[i].Name < deployments[j].Name
+	})
+	c.logger.Debug().Int("count", len(deployments)).Msg("Listed deployments from read cache")
+	return c.convertToDeploymentInfo(deployments), true, nil
+}
+
+// CachedDeploymentsVersion returns the version of the deployments ListDeployments returns for opts
+// from the read cache, derived from their resource versions: it changes whenever one of them is
+// added, changed or deleted. It reports false if the request is not served from the read cache.
+func (c *Client) CachedDeploymentsVersion(opts ListDeploymentsOptions) (string, bool) {
+	rc := c.cache.Load()
+	if rc == nil || opts.FieldSelector != "" {
+		return "", false
+	}
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return "", false
+	}
+	cached, err := rc.resources["deployments"].List(opts.Namespace, selector)
+	if err != nil {
+		return "", false
+	}
+
+	keys := make([]string, len(cached))
+	for i, obj := range cached {
+		deployment := obj.(*appsv1.Deployment)
+		keys[i] = deployment.Namespace + "/" + deployment.Name + "@" + deployment.ResourceVersion
+	}
+	sort.Strings(keys)
+	hash := fnv.New64a()
+	for _, key := range keys {
+		_, _ = hash.Write([]byte(key + "\n"))
+	}
+	return strconv.FormatUint(hash.Sum64(), 36), true
+}
+
+// cachedDeployment gets a deployment from the read cache. It reports false if the cache is not synced.
+func (c *Client) cachedDeployment(ns, name string) (*appsv1.Deployment, bool, error) {
+	rc := c.cache.Load()
+	if rc == nil {
+		return nil, false, nil
+	}
+	obj, exists, err := rc.resources["deployments"].Get(ns, name)
+	if err != nil {
+		return nil, true, err
+	}
+	if !exists {
+		return nil, true, apierrors.NewNotFound(appsv1.Resource("deployments"), name)
+	}
+	return obj.(*appsv1.Deployment), true, nil
+}
@@ -0,0 +1,264 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests the informer-backed read cache.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// TestStartCache verifies that reads are served from the cache once it has synced,
+// including label selectors, missing objects and objects created afterwards.
+func TestStartCache(t *testing.T) {
+	nginx := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx})
+	nginx.Labels = map[string]string{"app": "nginx"}
+	redis := createTestDeployment(testDeploymentRedis, testNamespaceKube, 1, []string{testImageRedis})
+	client := setupTestClient(zerolog.Nop(), []runtime.Object{nginx, redis}, false)
+	clientset := client.clientset.(*fake.Clientset)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if client.CacheSynced() {
+		t.Fatal("expected the cache not to be synced before StartCache")
+	}
+	if err := client.StartCache(ctx, 0); err != nil {
+		t.Fatalf("StartCache() error = %v", err)
+	}
+	if err := client.StartCache(ctx, 0); err == nil {
+		t.Error("expected an error when starting the cache twice")
+	}
+
+	// Count list calls from now on: cached reads must not reach the API server.
+	apiLists := 0
+	clientset.PrependReactor("list", "deployments", func(ktesting.Action) (bool, runtime.Object, error) {
+		apiLists++
+		return false, nil, nil
+	})
+
+	tests := []struct {
+		name      string
+		opts      ListDeploymentsOptions
+		wantNames []string
+	}{
+		{"all namespaces", ListDeploymentsOptions{}, []string{testDeploymentNginx, testDeploymentRedis}},
+		{"namespace", ListDeploymentsOptions{Namespace: testNamespaceKube}, []string{testDeploymentRedis}},
+		{"label selector", ListDeploymentsOptions{LabelSelector: "app=nginx"}, []string{testDeploymentNginx}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployments, err := client.ListDeployments(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("ListDeployments() error = %v", err)
+			}
+			var names []string
+			for _, d := range deployments {
+				names = append(names, d.Name)
+			}
+			if len(names) != len(tt.wantNames) || (len(names) > 0 && names[0] != tt.wantNames[0]) {
+				t.Errorf("expected deployments %v, got %v", tt.wantNames, names)
+			}
+		})
+	}
+	if apiLists != 0 {
+		t.Errorf("expected no API list calls, got %d", apiLists)
+	}
+
+	_, err := client.GetDeployment(ctx, testNamespaceDefault, "missing")
+	if !errors.Is(ClassifyError(err), ErrNotFound) {
+		t.Errorf("expected a not found error for a missing deployment, got %v", err)
+	}
+
+	// Objects created afterwards are picked up by the watch.
+	created := createTestDeployment("created", testNamespaceDefault, 1, []string{testImageBusybox})
+	if _, err := clientset.AppsV1().Deployments(testNamespaceDefault).Create(ctx, created,
+		metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := client.GetDeployment(ctx, testNamespaceDefault, "created"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("created deployment did not appear in the cache")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestStartCacheResources verifies that pods, services, nodes and namespaces are listed from the cache
+// once it has synced, and that the cache reports when a resource last changed.
+func TestStartCacheResources(t *testing.T) {
+	client := setupTestClient(zerolog.Nop(), []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: testNamespaceDefault}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "dns-1", Namespace: testNamespaceKube}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespaceDefault}},
+	}, false)
+	clientset := client.clientset.(*fake.Clientset)
+	if _, ok := client.CacheUpdated("pods"); ok {
+		t.Error("expected no update time before the cache has synced")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.StartCache(ctx, 0); err != nil {
+		t.Fatalf("StartCache() error = %v", err)
+	}
+	apiLists := 0
+	clientset.PrependReactor("list", "*", func(ktesting.Action) (bool, runtime.Object, error) {
+		apiLists++
+		return false, nil, nil
+	})
+
+	tests := []struct {
+		name      string
+		list      func() (int, error)
+		wantCount int
+	}{
+		{"pods", func() (int, error) { pods, err := client.ListPods(ctx, ""); return len(pods), err }, 2},
+		{"namespace pods", func() (int, error) {
+			pods, err := client.ListPods(ctx, testNamespaceKube)
+			return len(pods), err
+		}, 1},
+		{"services", func() (int, error) {
+			services, err := client.ListServices(ctx, testNamespaceDefault)
+			return len(services), err
+		}, 1},
+		{"nodes", func() (int, error) { nodes, err := client.ListNodes(ctx); return len(nodes), err }, 1},
+		{"namespaces", func() (int, error) {
+			namespaces, err := client.ListNamespaces(ctx)
+			return len(namespaces), err
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := tt.list()
+			if err != nil || count != tt.wantCount {
+				t.Errorf("expected %d objects, got %d, %v", tt.wantCount, count, err)
+			}
+		})
+	}
+	if apiLists != 0 {
+		t.Errorf("expected no API list calls, got %d", apiLists)
+	}
+
+	synced, ok := client.CacheUpdated("pods")
+	if !ok || synced.IsZero() {
+		t.Fatalf("expected the sync time of pods, got %v, %v", synced, ok)
+	}
+	if _, ok := client.CacheUpdated("secrets"); ok {
+		t.Error("expected no update time of a resource outside the cache")
+	}
+	created := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: testNamespaceDefault}}
+	if _, err := clientset.CoreV1().Pods(testNamespaceDefault).Create(ctx, created,
+		metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if updated, _ := client.CacheUpdated("pods"); updated.After(synced) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the update time to change with a created pod")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestStartCacheFieldSelector verifies that requests with field selectors still go to the API server.
+func TestStartCacheFieldSelector(t *testing.T) {
+	client := setupTestClient(zerolog.Nop(), nil, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.StartCache(ctx, 0); err != nil {
+		t.Fatalf("StartCache() error = %v", err)
+	}
+
+	if _, cached, _ := client.cachedDeployments(ListDeploymentsOptions{FieldSelector: "metadata.name=web"}); cached {
+		t.Error("expected field selector requests not to be served from the cache")
+	}
+	if _, cached, _ := client.cachedDeployments(ListDeploymentsOptions{}); !cached {
+		t.Error("expected plain requests to be served from the cache")
+	}
+}
+
+// TestStartCacheCancelled verifies that StartCache fails when its context ends before the cache syncs.
+func TestStartCacheCancelled(t *testing.T) {
+	client := setupTestClient(zerolog.Nop(), nil, false)
+	client.clientset.(*fake.Clientset).PrependReactor("list", "deployments",
+		func(ktesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("unavailable")
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.StartCache(ctx, 0); err == nil {
+		t.Fatal("expected StartCache() to fail")
+	}
+	if client.CacheSynced() {
+		t.Error("expected reads to keep going to the API server")
+	}
+}
+
+// TestCachedDeploymentsVersion verifies that the version of a cached list changes with the
+// deployments it contains, and is only reported for requests served from the cache.
+func TestCachedDeploymentsVersion(t *testing.T) {
+	nginx := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx})
+	nginx.ResourceVersion = "1"
+	redis := createTestDeployment(testDeploymentRedis, testNamespaceKube, 1, []string{testImageRedis})
+	redis.ResourceVersion = "2"
+	client := setupTestClient(zerolog.Nop(), []runtime.Object{nginx, redis}, false)
+
+	if _, ok := client.CachedDeploymentsVersion(ListDeploymentsOptions{}); ok {
+		t.Error("expected no version before the cache has synced")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.StartCache(ctx, 0); err != nil {
+		t.Fatalf("StartCache() error = %v", err)
+	}
+	if _, ok := client.CachedDeploymentsVersion(ListDeploymentsOptions{FieldSelector: "metadata.name=web"}); ok {
+		t.Error("expected no version for field selector requests")
+	}
+
+	all, ok := client.CachedDeploymentsVersion(ListDeploymentsOptions{})
+	if !ok {
+		t.Fatal("expected a version for a cached list")
+	}
+	if again, _ := client.CachedDeploymentsVersion(ListDeploymentsOptions{}); again != all {
+		t.Errorf("expected a stable version, got %q and %q", all, again)
+	}
+	kube, _ := client.CachedDeploymentsVersion(ListDeploymentsOptions{Namespace: testNamespaceKube})
+	if kube == all {
+		t.Error("expected lists of different deployments to have different versions")
+	}
+
+	changed := redis.DeepCopy()
+	changed.ResourceVersion = "3"
+	if _, err := client.clientset.AppsV1().Deployments(testNamespaceKube).Update(ctx, changed,
+		metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if version, _ := client.CachedDeploymentsVersion(ListDeploymentsOptions{}); version != all {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the version to change with a deployment")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
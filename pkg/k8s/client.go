@@ -5,51 +5,159 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/openapi"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
 )
 
 // Client wraps the Kubernetes clientset with additional functionality.
 // It provides structured logging and connection management for k8s operations.
 type Client struct {
-	clientset kubernetes.Interface
-	config    *rest.Config
-	logger    zerolog.Logger
+	clientset  kubernetes.Interface
+	dynamic    dynamic.Interface
+	metrics    metricsclient.Interface
+	config     *rest.Config
+	logger     zerolog.Logger
+	authorizer authz.Authorizer
+
+	// dryRun selects whether mutating operations are only simulated; empty means they are not.
+	dryRun DryRun
+
+	// newExecutor creates remote command executors; nil means the SPDY executor.
+	newExecutor executorFactory
+
+	// newDialer creates port-forward dialers; nil means the SPDY dialer.
+	newDialer dialerFactory
+
+	// openAPIClient lists and downloads OpenAPI v3 documents; nil means the discovery client's.
+	openAPIClient openapi.Client
+
+	// openAPICacheDir is where downloaded OpenAPI documents are cached; empty disables the disk cache.
+	openAPICacheDir string
+
+	// openAPI holds the OpenAPI documents fetched so far.
+	openAPI openAPIDocuments
+
+	// cache serves deployment reads once StartCache has synced it; nil means reads go to the API server.
+	cache atomic.Pointer[readCache]
+
+	// contact records when the API server last answered; nil for clients without an API server.
+	contact *contactRecorder
 }
 
 // ClientConfig holds configuration options for creating a Kubernetes client.
 type ClientConfig struct {
-	// KubeconfigPath specifies the path to the kubeconfig file.
+	// KubeconfigPath specifies the path to the kubeconfig file, or a list of files separated like
+	// $KUBECONFIG (":" on Unix), which are merged as kubectl does.
 	// If empty, the default locations will be checked.
 	KubeconfigPath string
 
 	// Context specifies which context to use from the kubeconfig.
 	// If empty, the current context will be used.
 	Context string
+
+	// Demo backs the client with a seeded in-memory fake cluster instead of a real API server.
+	Demo bool
+
+	// DemoFixture is a YAML/JSON file with the objects to seed the demo cluster with.
+	// If empty, a built-in set of demo objects is used. Only used when Demo is set.
+	DemoFixture string
+
+	// Authorizer is consulted before every mutating operation.
+	// If nil, all mutating operations are allowed.
+	Authorizer authz.Authorizer
+
+	// DryRun simulates mutating operations instead of performing them, like kubectl's --dry-run.
+	// Authorization is still checked. Empty means DryRunNone.
+	DryRun DryRun
+
+	// ImpersonateUser is the user to act as, like kubectl's --as. Empty disables impersonation.
+	ImpersonateUser string
+
+	// ImpersonateGroups are the groups to act as. They require ImpersonateUser.
+	ImpersonateGroups []string
+
+	// ImpersonateUID is the UID to act as. It requires ImpersonateUser.
+	ImpersonateUID string
+
+	// Server overrides the API server URL of the kubeconfig, like kubectl's --server.
+	Server string
+
+	// CertificateAuthority is a CA bundle file used to verify the API server certificate.
+	CertificateAuthority string
+
+	// Token is a bearer token used instead of the credentials of the kubeconfig.
+	Token string
+
+	// ExecCommand runs an exec credential plugin, e.g. "aws" or "gke-gcloud-auth-plugin",
+	// instead of using the credentials of the kubeconfig. Exec plugins configured in the
+	// kubeconfig itself are supported as well.
+	ExecCommand string
+
+	// ExecArgs are the arguments passed to ExecCommand.
+	ExecArgs []string
+
+	// ExecAPIVersion is the credential API version of the plugin; defaults to DefaultExecAPIVersion.
+	ExecAPIVersion string
+
+	// ProxyURL is an HTTP(S) or SOCKS5 proxy for reaching the API server, e.g. "socks5://127.0.0.1:1080"
+	// for an SSH tunnel. If empty, the kubeconfig's proxy-url or the proxy environment variables apply.
+	ProxyURL string
+
+	// QPS and Burst limit the rate of API requests of the client. Zero keeps the client-go
+	// defaults of 5 requests per second with bursts of 10.
+	QPS   float32
+	Burst int
+
+	// BreakerThreshold is the number of consecutive server errors or connection failures after
+	// which requests fail fast for BreakerCooldown. Zero disables the circuit breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// UserAgent identifies the client in audit logs and API server metrics, see UserAgent.
+	// If empty, client-go's default user agent is used.
+	UserAgent string
 }
 
 // DeploymentInfo represents essential information about a Kubernetes deployment.
 // This struct contains only the fields needed for listing operations.
 type DeploymentInfo struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
+	Name      string `json:"name" doc:"Name of the deployment"`
+	Namespace string `json:"namespace" doc:"Namespace of the deployment"`
 	Replicas  struct {
 		Desired   int32 `json:"desired"`
 		Available int32 `json:"available"`
 		Ready     int32 `json:"ready"`
 		Updated   int32 `json:"updated"`
-	} `json:"replicas"`
-	Age       time.Duration `json:"age"`
-	Images    []string      `json:"images"`
-	CreatedAt time.Time     `json:"created_at"`
+	} `json:"replicas" doc:"Desired replicas from the spec; available, ready and updated replicas from the status"`
+	Age       time.Duration `json:"age" doc:"Time since the deployment was created, in nanoseconds"`
+	Images    []string      `json:"images" doc:"Distinct container images of the pod template, sorted"`
+	CreatedAt time.Time     `json:"created_at" doc:"Creation time of the deployment"`
+
+	Labels      map[string]string  `json:"labels,omitempty" doc:"Labels of the deployment"`
+	Annotations map[string]string  `json:"annotations,omitempty" doc:"Annotations of the deployment"`
+	Selector    string             `json:"selector" doc:"Label selector of the deployment's pods, e.g. app=nginx"`
+	Strategy    DeploymentStrategy `json:"strategy" doc:"Strategy used to replace old pods with new ones"`
+
+	Generation         int64 `json:"generation" doc:"Generation of the spec, incremented on each change"`
+	ObservedGeneration int64 `json:"observed_generation" doc:"Generation most recently acted on by the controller"`
+
+	Conditions []DeploymentCondition `json:"conditions,omitempty" doc:"Current conditions, e.g. Available"`
 }
 
 // ListDeploymentsOptions holds options for listing deployments.
@@ -65,6 +173,11 @@ type ListDeploymentsOptions struct {
 	// FieldSelector allows filtering deployments by fields.
 	// Uses the standard Kubernetes field selector syntax.
 	FieldSelector string
+
+	// Limit is the number of deployments requested per API call; values below 1 use DefaultPageSize,
+	// since lists are always paged. All pages are fetched, following continue tokens, so it does not
+	// cap the result.
+	Limit int64
 }
 
 // LoadKubeconfig loads the Kubernetes configuration from various sources.
@@ -73,34 +186,31 @@ type ListDeploymentsOptions struct {
 func LoadKubeconfig(config ClientConfig, logger zerolog.Logger) (*rest.Config, error) {
 	logger.Debug().Msg("Loading Kubernetes configuration")
 
-	// Try in-cluster config first (for pods running inside K8s)
-	if inClusterConfig, err := rest.InClusterConfig(); err == nil {
-		logger.Info().Msg("Using in-cluster Kubernetes configuration")
-		return inClusterConfig, nil
+	if err := config.validateCredentials(); err != nil {
+		return nil, err
+	}
+	if _, err := parseProxyURL(config.ProxyURL); err != nil {
+		return nil, err
 	}
 
-	// Use client-go's built-in loading rules
-	// This automatically handles:
-	// - KUBECONFIG env variable (with : separator for multiple files)
-	// - ~/.kube/config fallback
-	// - Merging multiple configs (kubectl behavior)
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	// Try in-cluster config first (for pods running inside K8s), unless the connection is given explicitly
+	if !config.hasExplicitConnection() {
+		if inClusterConfig, err := rest.InClusterConfig(); err == nil {
+			logger.Info().Msg("Using in-cluster Kubernetes configuration")
+			applyImpersonation(inClusterConfig, config, logger)
+			applyProxy(inClusterConfig, config, logger)
+			return inClusterConfig, nil
+		}
+	}
 
-	// Only override if explicit --kubeconfig flag provided
+	loadingRules := kubeconfigLoadingRules(config.KubeconfigPath)
 	if config.KubeconfigPath != "" {
-		loadingRules.ExplicitPath = config.KubeconfigPath
 		logger.Debug().Str("path", config.KubeconfigPath).Msg("Using explicit kubeconfig path")
 	}
 
-	configOverrides := &clientcmd.ConfigOverrides{}
-	if config.Context != "" {
-		configOverrides.CurrentContext = config.Context
-		logger.Debug().Str("context", config.Context).Msg("Using specified context")
-	}
-
 	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		loadingRules,
-		configOverrides,
+		config.configOverrides(logger),
 	)
 
 	restConfig, err := kubeConfig.ClientConfig()
@@ -108,19 +218,73 @@ func LoadKubeconfig(config ClientConfig, logger zerolog.Logger) (*rest.Config, e
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
-	// Log current context
-	if rawConfig, err := kubeConfig.RawConfig(); err == nil {
-		logEvent := logger.Info().Str("context", rawConfig.CurrentContext)
+	logCurrentContext(kubeConfig, logger)
+	clearConflictingCredentials(restConfig, config)
+	applyImpersonation(restConfig, config, logger)
+	applyProxy(restConfig, config, logger)
+	return restConfig, nil
+}
 
-		// Safely access context details to avoid nil pointer dereference
-		if ctx, ok := rawConfig.Contexts[rawConfig.CurrentContext]; ok {
-			logEvent = logEvent.Str("cluster", ctx.Cluster)
-		}
+// kubeconfigLoadingRules returns client-go's loading rules, which read the files listed in $KUBECONFIG
+// or ~/.kube/config and merge them as kubectl does: the first file to set a value wins, and
+// the maps of clusters, users and contexts are combined. A single path replaces the defaults;
+// a path list replaces $KUBECONFIG and its files are merged the same way.
+func kubeconfigLoadingRules(path string) *clientcmd.ClientConfigLoadingRules {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if paths := filepath.SplitList(path); len(paths) > 1 {
+		loadingRules.Precedence = paths
+	} else {
+		loadingRules.ExplicitPath = path
+	}
+	return loadingRules
+}
 
-		logEvent.Msg("Loaded Kubernetes configuration")
+// logCurrentContext logs the context and cluster the kubeconfig resolved to.
+func logCurrentContext(kubeConfig clientcmd.ClientConfig, logger zerolog.Logger) {
+	rawConfig, err := kubeConfig.RawConfig()
+	if err != nil {
+		return
 	}
+	logEvent := logger.Info().Str("context", rawConfig.CurrentContext)
 
-	return restConfig, nil
+	// Safely access context details to avoid nil pointer dereference
+	if ctx, ok := rawConfig.Contexts[rawConfig.CurrentContext]; ok {
+		logEvent = logEvent.Str("cluster", ctx.Cluster)
+	}
+
+	logEvent.Msg("Loaded Kubernetes configuration")
+}
+
+// applyImpersonation makes requests act as the configured user, groups and UID.
+// It leaves the impersonation set in the kubeconfig in place when no user is configured.
+func applyImpersonation(restConfig *rest.Config, config ClientConfig, logger zerolog.Logger) {
+	if config.ImpersonateUser == "" {
+		return
+	}
+	restConfig.Impersonate = rest.ImpersonationConfig{
+		UserName: config.ImpersonateUser,
+		Groups:   config.ImpersonateGroups,
+		UID:      config.ImpersonateUID,
+	}
+	logger.Info().Str("user", config.ImpersonateUser).Strs("groups", config.ImpersonateGroups).
+		Msg("Impersonating user")
+}
+
+// UserAgent returns the user agent of the tool, "k8s-controller/VERSION (COMMAND)", which lets
+// cluster audit logs and API server metrics attribute requests to the command that sent them.
+func UserAgent(version, command string) string {
+	if command == "" {
+		return "k8s-controller/" + version
+	}
+	return fmt.Sprintf("k8s-controller/%s (%s)", version, command)
+}
+
+// configureTransport applies the user agent, rate limits and circuit breaker to the REST config.
+func configureTransport(restConfig *rest.Config, config ClientConfig, logger zerolog.Logger) {
+	if config.UserAgent != "" {
+		restConfig.UserAgent = config.UserAgent
+	}
+	applyRateLimits(restConfig, config, logger)
 }
 
 // CreateClient creates a new Kubernetes client with the provided configuration.
@@ -128,10 +292,25 @@ func LoadKubeconfig(config ClientConfig, logger zerolog.Logger) (*rest.Config, e
 func CreateClient(config ClientConfig, logger zerolog.Logger) (*Client, error) {
 	logger.Debug().Msg("Creating Kubernetes client")
 
+	if config.Demo {
+		client, err := NewDemoClient(config.DemoFixture, logger)
+		if err != nil {
+			return nil, err
+		}
+		client.authorizer = config.Authorizer
+		client.SetDryRun(config.DryRun)
+		return client, nil
+	}
+
 	restConfig, err := LoadKubeconfig(config, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
+	configureTransport(restConfig, config, logger)
+	contact := &contactRecorder{}
+	restConfig.Wrap(contact.wrap)
+	// The global provider records nothing until tracing.Setup installs one; its tracers then follow it.
+	restConfig.Wrap(apiTracer{provider: otel.GetTracerProvider(), propagator: otel.GetTextMapPropagator()}.wrap)
 
 	// Create the clientset
 	clientset, err := kubernetes.NewForConfig(restConfig)
@@ -139,16 +318,49 @@ func CreateClient(config ClientConfig, logger zerolog.Logger) (*Client, error) {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
 
+	// Create the dynamic client used for operations on arbitrary resource types
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	// Create the metrics client used for resource usage queries
+	metricsClient, err := metricsclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client: %w", err)
+	}
+
 	client := &Client{
-		clientset: clientset,
-		config:    restConfig,
-		logger:    logger.With().Str("component", "k8s-client").Logger(),
+		clientset:  clientset,
+		dynamic:    dynamicClient,
+		metrics:    metricsClient,
+		config:     restConfig,
+		logger:     logger.With().Str("component", "k8s-client").Logger(),
+		authorizer: config.Authorizer,
+		contact:    contact,
 	}
+	client.SetDryRun(config.DryRun)
 
 	client.logger.Info().Msg("Kubernetes client created successfully")
 	return client, nil
 }
 
+// Ping checks that the Kubernetes API server answers, with the request of TestConnection but
+// without its logging, for frequent checks such as readiness probes.
+func (c *Client) Ping(ctx context.Context) error {
+	if _, err := c.listOneNamespace(ctx); err != nil {
+		return fmt.Errorf("kubernetes API unreachable: %w", err)
+	}
+	return nil
+}
+
+// listOneNamespace lists at most one namespace, the cheapest request verifying the connection.
+func (c *Client) listOneNamespace(ctx context.Context) (*corev1.NamespaceList, error) {
+	return c.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{
+		Limit: 1, // We only need to verify connection, not get all namespaces
+	})
+}
+
 // TestConnection verifies that the client can connect to the Kubernetes API server.
 // It performs a simple API call to list namespaces with a timeout.
 func (c *Client) TestConnection(ctx context.Context) error {
@@ -163,9 +375,7 @@ func (c *Client) TestConnection(ctx context.Context) error {
 	}
 
 	// Try to list namespaces as a connection test
-	namespaces, err := c.clientset.CoreV1().Namespaces().List(testCtx, metav1.ListOptions{
-		Limit: 1, // We only need to verify connection, not get all namespaces
-	})
+	namespaces, err := c.listOneNamespace(testCtx)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("Failed to connect to Kubernetes API")
 		return fmt.Errorf("failed to connect to Kubernetes API: %w", err)
@@ -190,19 +400,21 @@ func (c *Client) TestConnection(ctx context.Context) error {
 
 // ListDeployments retrieves deployments from the Kubernetes cluster based on the provided options.
 // It returns a slice of DeploymentInfo structs containing essential deployment information.
+// Once the read cache has synced, requests without a field selector are served from it.
 func (c *Client) ListDeployments(ctx context.Context, opts ListDeploymentsOptions) ([]DeploymentInfo, error) {
 	c.logger.Debug().
 		Str("namespace", opts.Namespace).
 		Str("label_selector", opts.LabelSelector).
 		Msg("Listing deployments")
 
-	deploymentList, err := c.fetchDeploymentList(ctx, opts)
+	deployments, cached, err := c.cachedDeployments(opts)
+	if !cached {
+		deployments, err = c.fetchDeployments(ctx, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	deployments := c.convertToDeploymentInfo(deploymentList.Items)
-
 	c.logger.Info().
 		Int("count", len(deployments)).
 		Str("namespace", opts.Namespace).
@@ -211,30 +423,85 @@ func (c *Client) ListDeployments(ctx context.Context, opts ListDeploymentsOption
 	return deployments, nil
 }
 
-// fetchDeploymentList retrieves the raw deployment list from Kubernetes API.
-func (c *Client) fetchDeploymentList(ctx context.Context, opts ListDeploymentsOptions) (*appsv1.DeploymentList, error) {
+// GetDeployment returns information about a single deployment.
+func (c *Client) GetDeployment(ctx context.Context, ns, name string) (DeploymentInfo, error) {
+	deployment, cached, err := c.cachedDeployment(ns, name)
+	if !cached {
+		deployment, err = c.clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return DeploymentInfo{}, fmt.Errorf("failed to get deployment %q: %w", name, err)
+	}
+	return c.createDeploymentInfo(*deployment, time.Now()), nil
+}
+
+// ListDeploymentPages lists deployments like ListDeployments, but calls fn with each page of
+// opts.Limit deployments as soon as it arrives instead of collecting them, so that callers can
+// render large lists progressively. Deployments served from the read cache form a single page.
+// fn is not called for empty pages; an error returned by fn stops the listing and is returned.
+func (c *Client) ListDeploymentPages(ctx context.Context, opts ListDeploymentsOptions,
+	fn func(page []DeploymentInfo) error) error {
+	deployments, cached, err := c.cachedDeployments(opts)
+	switch {
+	case !cached:
+		return c.fetchDeploymentPages(ctx, opts, fn)
+	case err != nil:
+		return err
+	case len(deployments) == 0:
+		return nil
+	default:
+		return fn(deployments)
+	}
+}
+
+// fetchDeployments lists deployments page by page and returns all of them.
+func (c *Client) fetchDeployments(ctx context.Context, opts ListDeploymentsOptions) ([]DeploymentInfo, error) {
+	var deployments []DeploymentInfo
+	err := c.fetchDeploymentPages(ctx, opts, func(page []DeploymentInfo) error {
+		deployments = append(deployments, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().Int("count", len(deployments)).Msg("Fetched all deployment pages")
+	return deployments, nil
+}
+
+// fetchDeploymentPages lists deployments page by page, converting each page and passing it to fn
+// before fetching the next, so the raw objects of only one page are held in memory at a time.
+func (c *Client) fetchDeploymentPages(ctx context.Context, opts ListDeploymentsOptions,
+	fn func(page []DeploymentInfo) error) error {
 	listOpts := metav1.ListOptions{
 		LabelSelector: opts.LabelSelector,
 		FieldSelector: opts.FieldSelector,
+		Limit:         opts.Limit,
 	}
 
-	var deploymentList *appsv1.DeploymentList
-	var err error
-
 	if opts.Namespace == "" {
 		c.logger.Debug().Msg("Listing deployments from all namespaces")
-		deploymentList, err = c.clientset.AppsV1().Deployments("").List(ctx, listOpts)
 	} else {
 		c.logger.Debug().Str("namespace", opts.Namespace).Msg("Listing deployments from namespace")
-		deploymentList, err = c.clientset.AppsV1().Deployments(opts.Namespace).List(ctx, listOpts)
 	}
 
-	if err != nil {
-		c.logger.Error().Err(err).Msg("Failed to list deployments")
-		return nil, fmt.Errorf("failed to list deployments: %w", err)
-	}
-
-	return deploymentList, nil
+	deployments := c.clientset.AppsV1().Deployments(opts.Namespace)
+	now := time.Now()
+	return ListPages(listOpts, func(listOpts metav1.ListOptions) (string, error) {
+		list, err := deployments.List(ctx, listOpts)
+		if err != nil {
+			c.logger.Error().Err(err).Msg("Failed to list deployments")
+			return "", fmt.Errorf("failed to list deployments: %w", err)
+		}
+		if len(list.Items) == 0 {
+			return list.Continue, nil
+		}
+		page := make([]DeploymentInfo, 0, len(list.Items))
+		for i := range list.Items {
+			page = append(page, c.createDeploymentInfo(list.Items[i], now))
+		}
+		return list.Continue, fn(page)
+	})
 }
 
 // convertToDeploymentInfo converts Kubernetes deployment objects to DeploymentInfo structs.
@@ -267,6 +534,7 @@ func (c *Client) createDeploymentInfo(deployment appsv1.Deployment, now time.Tim
 	info.Replicas.Available = deployment.Status.AvailableReplicas
 	info.Replicas.Ready = deployment.Status.ReadyReplicas
 	info.Replicas.Updated = deployment.Status.UpdatedReplicas
+	addDeploymentDetails(&info, &deployment)
 
 	return info
 }
@@ -308,6 +576,12 @@ func (c *Client) GetClientset() kubernetes.Interface {
 	return c.clientset
 }
 
+// GetDynamicClient returns the underlying dynamic client.
+// This allows operations on arbitrary resource types, including custom resources.
+func (c *Client) GetDynamicClient() dynamic.Interface {
+	return c.dynamic
+}
+
 // GetConfig returns the underlying REST config.
 // This can be useful for creating other types of clients.
 func (c *Client) GetConfig() *rest.Config {
@@ -0,0 +1,91 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file records client-go REST client metrics in the application's metrics backend.
+package k8s
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	clientmetrics "k8s.io/client-go/tools/metrics"
+
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// REST client metric names.
+const (
+	metricRESTRequestsTotal      = "kc_rest_client_requests_total"
+	metricRESTRequestDuration    = "kc_rest_client_request_duration_seconds"
+	metricRESTRateLimiterLatency = "kc_rest_client_rate_limiter_duration_seconds"
+	metricRESTRetriesTotal       = "kc_rest_client_retries_total"
+)
+
+var (
+	// registerClientMetrics installs the adapters with client-go, which only accepts one registration.
+	registerClientMetrics sync.Once
+
+	// clientMetricsBackend is the backend the adapters record to; nil discards updates.
+	clientMetricsBackend atomic.Pointer[metrics.Backend]
+)
+
+// RegisterClientMetrics records the requests of all Kubernetes clients in backend: request counts
+// by verb, host and status code, request latencies and client-side rate limiter delays by verb and
+// host, and retries. Later calls replace the backend; a nil backend stops recording.
+func RegisterClientMetrics(backend metrics.Backend) {
+	if backend == nil {
+		clientMetricsBackend.Store(nil)
+	} else {
+		clientMetricsBackend.Store(&backend)
+	}
+	registerClientMetrics.Do(func() {
+		clientmetrics.Register(clientmetrics.RegisterOpts{
+			RequestLatency:     latencyMetric{name: metricRESTRequestDuration},
+			RateLimiterLatency: latencyMetric{name: metricRESTRateLimiterLatency},
+			RequestResult:      resultMetric{},
+			RequestRetry:       retryMetric{},
+		})
+	})
+}
+
+// currentClientMetrics returns the registered backend, or nil if recording is disabled.
+func currentClientMetrics() metrics.Backend {
+	if backend := clientMetricsBackend.Load(); backend != nil {
+		return *backend
+	}
+	return nil
+}
+
+// latencyMetric records client-go latencies as a histogram in seconds. Only the host of the
+// request URL is used as a label, since paths would create unbounded series.
+type latencyMetric struct {
+	name string
+}
+
+// Observe implements clientmetrics.LatencyMetric.
+func (m latencyMetric) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	if backend := currentClientMetrics(); backend != nil {
+		backend.Observe(m.name, metrics.Labels{"verb": verb, "host": u.Host}, latency.Seconds())
+	}
+}
+
+// resultMetric counts client-go requests by verb, host and status code.
+type resultMetric struct{}
+
+// Increment implements clientmetrics.ResultMetric.
+func (resultMetric) Increment(_ context.Context, code, method, host string) {
+	if backend := currentClientMetrics(); backend != nil {
+		backend.Counter(metricRESTRequestsTotal, metrics.Labels{"verb": method, "host": host, "code": code}, 1)
+	}
+}
+
+// retryMetric counts client-go request retries by verb, host and status code.
+type retryMetric struct{}
+
+// IncrementRetry implements clientmetrics.RetryMetric.
+func (retryMetric) IncrementRetry(_ context.Context, code, method, host string) {
+	if backend := currentClientMetrics(); backend != nil {
+		backend.Counter(metricRESTRetriesTotal, metrics.Labels{"verb": method, "host": host, "code": code}, 1)
+	}
+}
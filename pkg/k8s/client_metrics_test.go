@@ -0,0 +1,73 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests recording client-go REST client metrics.
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// TestRegisterClientMetrics verifies that API requests of a client are counted and timed by verb,
+// host and status code.
+func TestRegisterClientMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"DeploymentList","apiVersion":"apps/v1","items":[]}`))
+	}))
+	defer srv.Close()
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	registry := metrics.NewRegistry()
+	RegisterClientMetrics(registry)
+	defer RegisterClientMetrics(nil)
+
+	client, err := CreateClient(ClientConfig{Server: srv.URL, Token: "token"}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("CreateClient() error = %v", err)
+	}
+	if _, err := client.ListDeployments(context.Background(), ListDeploymentsOptions{}); err != nil {
+		t.Fatalf("ListDeployments() error = %v", err)
+	}
+
+	host := srv.Listener.Addr().String()
+	snapshot := registry.Snapshot()
+	var requests float64
+	for _, point := range snapshot.Counters {
+		if point.Name == metricRESTRequestsTotal && point.Labels["verb"] == http.MethodGet &&
+			point.Labels["host"] == host && point.Labels["code"] == "200" {
+			requests += point.Value
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 counted request to %s, got %v in %+v", host, requests, snapshot.Counters)
+	}
+	var timed bool
+	for _, point := range snapshot.Histograms {
+		timed = timed || (point.Name == metricRESTRequestDuration && point.Labels["host"] == host)
+	}
+	if !timed {
+		t.Errorf("expected a request duration for %s, got %+v", host, snapshot.Histograms)
+	}
+}
+
+// TestRegisterClientMetricsDisabled verifies that a nil backend stops recording.
+func TestRegisterClientMetricsDisabled(t *testing.T) {
+	registry := metrics.NewRegistry()
+	RegisterClientMetrics(registry)
+	RegisterClientMetrics(nil)
+
+	latencyMetric{name: metricRESTRequestDuration}.Observe(context.Background(), http.MethodGet, url.URL{}, 0)
+	resultMetric{}.Increment(context.Background(), "200", http.MethodGet, "api")
+	retryMetric{}.IncrementRetry(context.Background(), "500", http.MethodGet, "api")
+	if snapshot := registry.Snapshot(); len(snapshot.Counters)+len(snapshot.Histograms) != 0 {
+		t.Errorf("expected no recorded series, got %+v", snapshot)
+	}
+}
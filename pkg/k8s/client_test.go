@@ -5,6 +5,8 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,7 +18,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ktesting "k8s.io/client-go/testing"
 )
@@ -53,6 +57,152 @@ func TestLoadKubeconfigFileNotFound(t *testing.T) {
 	}
 }
 
+// testKubeconfig is a minimal kubeconfig with a single context.
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: admin
+current-context: test
+`
+
+// TestLoadKubeconfigImpersonation tests that impersonation settings are applied to the REST config.
+func TestLoadKubeconfigImpersonation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  ClientConfig
+		want    rest.ImpersonationConfig
+		wantErr bool
+	}{
+		{"no impersonation", ClientConfig{}, rest.ImpersonationConfig{}, false},
+		{"user and groups", ClientConfig{ImpersonateUser: "jane", ImpersonateGroups: []string{"dev", "ops"},
+			ImpersonateUID: "42"}, rest.ImpersonationConfig{UserName: "jane", Groups: []string{"dev", "ops"},
+			UID: "42"}, false},
+		{"groups without user", ClientConfig{ImpersonateGroups: []string{"dev"}}, rest.ImpersonationConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.KubeconfigPath = path
+			restConfig, err := LoadKubeconfig(tt.config, zerolog.Nop())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKubeconfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if fmt.Sprint(restConfig.Impersonate) != fmt.Sprint(tt.want) {
+				t.Errorf("expected impersonation %+v, got %+v", tt.want, restConfig.Impersonate)
+			}
+		})
+	}
+}
+
+// TestLoadKubeconfigMergesPathList tests that split kubeconfigs listed in $KUBECONFIG or --kubeconfig
+// are merged, with the first file winning conflicting values as in kubectl.
+func TestLoadKubeconfigMergesPathList(t *testing.T) {
+	dir := t.TempDir()
+	contextFile := filepath.Join(dir, "context")
+	clusterFile := filepath.Join(dir, "cluster")
+	files := map[string]string{
+		contextFile: `apiVersion: v1
+kind: Config
+contexts:
+- name: split
+  context:
+    cluster: test
+    user: admin
+current-context: split
+`,
+		clusterFile: testKubeconfig,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pathList := strings.Join([]string{contextFile, clusterFile, filepath.Join(dir, "missing")},
+		string(filepath.ListSeparator))
+
+	tests := []struct {
+		name      string
+		env       string
+		path      string
+		wantCtxts string
+	}{
+		{"KUBECONFIG list", pathList, "", "split,test"},
+		{"--kubeconfig list", "", pathList, "split,test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", tt.env)
+			restConfig, err := LoadKubeconfig(ClientConfig{KubeconfigPath: tt.path}, zerolog.Nop())
+			if err != nil {
+				t.Fatalf("LoadKubeconfig() error = %v", err)
+			}
+			// The current context of the first file uses the cluster and user of the second.
+			if restConfig.Host != "https://127.0.0.1:6443" || restConfig.BearerToken != "secret" {
+				t.Errorf("expected the merged cluster and user, got host %q", restConfig.Host)
+			}
+
+			contexts, err := KubeconfigContexts(tt.path)
+			if err != nil {
+				t.Fatalf("KubeconfigContexts() error = %v", err)
+			}
+			if strings.Join(contexts, ",") != tt.wantCtxts {
+				t.Errorf("expected contexts %s, got %v", tt.wantCtxts, contexts)
+			}
+		})
+	}
+}
+
+// TestUserAgent tests that the user agent names the version and command and is sent with requests.
+func TestUserAgent(t *testing.T) {
+	if got := UserAgent("v1.2.0", "list deployments"); got != "k8s-controller/v1.2.0 (list deployments)" {
+		t.Errorf("unexpected user agent %q", got)
+	}
+	if got := UserAgent("dev", ""); got != "k8s-controller/dev" {
+		t.Errorf("unexpected user agent without command %q", got)
+	}
+
+	agents := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents <- r.UserAgent()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"DeploymentList","apiVersion":"apps/v1","items":[]}`))
+	}))
+	defer srv.Close()
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	client, err := CreateClient(ClientConfig{Server: srv.URL, Token: "token", UserAgent: UserAgent("v1.2.0", "get")},
+		zerolog.Nop())
+	if err != nil {
+		t.Fatalf("CreateClient() error = %v", err)
+	}
+	if _, err := client.ListDeployments(context.Background(), ListDeploymentsOptions{}); err != nil {
+		t.Fatalf("ListDeployments() error = %v", err)
+	}
+	if got := <-agents; got != "k8s-controller/v1.2.0 (get)" {
+		t.Errorf("expected the user agent to be sent, got %q", got)
+	}
+}
+
 // TestCreateClientWithInvalidConfig tests client creation with invalid configuration.
 func TestCreateClientWithInvalidConfig(t *testing.T) {
 	logger := zerolog.New(os.Stderr)
@@ -251,6 +401,7 @@ func setupTestClient(logger zerolog.Logger, deployments []runtime.Object, simula
 
 	return &Client{
 		clientset: fakeClientset,
+		dynamic:   dynamicfake.NewSimpleDynamicClient(scheme.Scheme, deployments...),
 		config:    &rest.Config{Host: fakeServerURL},
 		logger:    logger,
 	}
@@ -0,0 +1,168 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements a set of clients for several clusters with concurrent fan-out operations.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// ClusterSet manages clients for several clusters, named by their kubeconfig context,
+// and runs operations on all of them concurrently.
+type ClusterSet struct {
+	clients map[string]*Client
+
+	// contexts are the cluster names in sorted order.
+	contexts []string
+	logger   zerolog.Logger
+}
+
+// ClusterError is the failure of an operation on one cluster of a ClusterSet.
+type ClusterError struct {
+	// Context is the name of the cluster.
+	Context string
+
+	// Err is the original error.
+	Err error
+}
+
+// Error returns the original message prefixed with the cluster name.
+func (e *ClusterError) Error() string {
+	return fmt.Sprintf("context %s: %v", e.Context, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *ClusterError) Unwrap() error {
+	return e.Err
+}
+
+// ClusterFunc is an operation run on one cluster of a ClusterSet.
+type ClusterFunc func(ctx context.Context, name string, client *Client) error
+
+// ClusterDeploymentInfo is a deployment together with the cluster it was listed from.
+type ClusterDeploymentInfo struct {
+	Context        string `json:"context" yaml:"context"`
+	DeploymentInfo `yaml:",inline"`
+}
+
+// NewClusterSet creates a client for each configuration, named by its Context.
+// The configurations may point to different kubeconfig files, but their contexts must be unique.
+func NewClusterSet(configs []ClientConfig, logger zerolog.Logger) (*ClusterSet, error) {
+	clients := make(map[string]*Client, len(configs))
+	set := &ClusterSet{clients: clients, logger: logger}
+	for _, config := range configs {
+		if _, ok := clients[config.Context]; ok {
+			_ = set.Close()
+			return nil, fmt.Errorf("duplicate context %q in cluster set", config.Context)
+		}
+		client, err := CreateClient(config, logger.With().Str("context", config.Context).Logger())
+		if err != nil {
+			_ = set.Close()
+			return nil, fmt.Errorf("context %s: %w", config.Context, err)
+		}
+		clients[config.Context] = client
+	}
+	return newClusterSet(clients, logger), nil
+}
+
+// newClusterSet creates a cluster set from existing clients, keyed by context.
+func newClusterSet(clients map[string]*Client, logger zerolog.Logger) *ClusterSet {
+	contexts := make([]string, 0, len(clients))
+	for name := range clients {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return &ClusterSet{clients: clients, contexts: contexts, logger: logger}
+}
+
+// Contexts returns the names of the clusters in sorted order.
+func (s *ClusterSet) Contexts() []string {
+	return s.contexts
+}
+
+// Client returns the client of a cluster, or nil if the set has no such cluster.
+func (s *ClusterSet) Client(name string) *Client {
+	return s.clients[name]
+}
+
+// ForEach runs fn for every cluster concurrently and waits for all of them.
+// A failure on one cluster does not stop the others; the failures are returned joined,
+// each as a ClusterError, in context order.
+func (s *ClusterSet) ForEach(ctx context.Context, fn ClusterFunc) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(s.contexts))
+
+	for i, name := range s.contexts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx, name, s.clients[name]); err != nil {
+				s.logger.Debug().Err(err).Str("context", name).Msg("Cluster operation failed")
+				errs[i] = &ClusterError{Context: name, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// ListDeployments lists deployments in all clusters concurrently, ordered by context.
+// Deployments of the reachable clusters are returned even if others fail.
+func (s *ClusterSet) ListDeployments(
+	ctx context.Context, opts ListDeploymentsOptions,
+) ([]ClusterDeploymentInfo, error) {
+	results := make(map[string][]DeploymentInfo, len(s.contexts))
+	var mu sync.Mutex
+
+	err := s.ForEach(ctx, func(ctx context.Context, name string, client *Client) error {
+		deployments, err := client.ListDeployments(ctx, opts)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		results[name] = deployments
+		mu.Unlock()
+		return nil
+	})
+
+	var all []ClusterDeploymentInfo
+	for _, name := range s.contexts {
+		for _, deployment := range results[name] {
+			all = append(all, ClusterDeploymentInfo{Context: name, DeploymentInfo: deployment})
+		}
+	}
+	return all, err
+}
+
+// Close closes the clients of all clusters.
+func (s *ClusterSet) Close() error {
+	var errs []error
+	for _, client := range s.clients {
+		errs = append(errs, client.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// ClusterErrors returns the per-cluster failures contained in an error returned by a ClusterSet.
+func ClusterErrors(err error) []*ClusterError {
+	if err == nil {
+		return nil
+	}
+	var clusterErrs []*ClusterError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			clusterErrs = append(clusterErrs, ClusterErrors(e)...)
+		}
+		return clusterErrs
+	}
+	var clusterErr *ClusterError
+	if errors.As(err, &clusterErr) {
+		clusterErrs = append(clusterErrs, clusterErr)
+	}
+	return clusterErrs
+}
@@ -0,0 +1,101 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests the multi-cluster client set and its fan-out listing.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestNewClusterSet verifies that clients are created per context and duplicate contexts are rejected.
+func TestNewClusterSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := NewClusterSet([]ClientConfig{
+		{KubeconfigPath: path, Context: "test"},
+		{Context: "demo", Demo: true},
+	}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewClusterSet() error = %v", err)
+	}
+	defer func() { _ = set.Close() }()
+	if strings.Join(set.Contexts(), ",") != "demo,test" {
+		t.Errorf("expected contexts [demo test], got %v", set.Contexts())
+	}
+	if set.Client("test") == nil || set.Client("missing") != nil {
+		t.Error("expected a client for context test only")
+	}
+
+	_, err = NewClusterSet([]ClientConfig{{Context: "a", Demo: true}, {Context: "a", Demo: true}}, zerolog.Nop())
+	if err == nil {
+		t.Error("expected an error for duplicate contexts")
+	}
+}
+
+// TestClusterSetListDeployments verifies that deployments are merged in context order
+// and that a failing cluster does not hide the others.
+func TestClusterSetListDeployments(t *testing.T) {
+	logger := zerolog.Nop()
+	prod := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx})
+	dev := createTestDeployment(testDeploymentRedis, testNamespaceDefault, 1, []string{testImageRedis})
+	set := newClusterSet(map[string]*Client{
+		"prod":   setupTestClient(logger, []runtime.Object{prod}, false),
+		"dev":    setupTestClient(logger, []runtime.Object{dev}, false),
+		"broken": setupTestClient(logger, nil, true),
+	}, logger)
+
+	deployments, err := set.ListDeployments(context.Background(), ListDeploymentsOptions{})
+
+	var got []string
+	for _, d := range deployments {
+		got = append(got, d.Context+"/"+d.Name)
+	}
+	want := "dev/" + testDeploymentRedis + ",prod/" + testDeploymentNginx
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected deployments %s, got %v", want, got)
+	}
+
+	failures := ClusterErrors(err)
+	if len(failures) != 1 || failures[0].Context != "broken" {
+		t.Fatalf("expected one failure for context broken, got %v", err)
+	}
+	var clusterErr *ClusterError
+	if !errors.As(err, &clusterErr) || !strings.Contains(err.Error(), "context broken") {
+		t.Errorf("expected a ClusterError naming the context, got %v", err)
+	}
+}
+
+// TestClusterErrors verifies extracting per-cluster failures from joined errors.
+func TestClusterErrors(t *testing.T) {
+	a := &ClusterError{Context: "a", Err: errors.New("down")}
+	b := &ClusterError{Context: "b", Err: errors.New("denied")}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"single", a, 1},
+		{"joined", errors.Join(a, b), 2},
+		{"other error", errors.New("boom"), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClusterErrors(tt.err); len(got) != tt.want {
+				t.Errorf("ClusterErrors() returned %d errors, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file records when the API server last answered the requests of a client.
+package k8s
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// contactRecorder records when the API server last answered a request without a server error.
+type contactRecorder struct {
+	// last is the time of the last answer, in Unix nanoseconds; zero until the first one.
+	last atomic.Int64
+}
+
+// wrap returns a round tripper that records the answers to the requests it sends.
+func (r *contactRecorder) wrap(rt http.RoundTripper) http.RoundTripper {
+	return &contactRoundTripper{recorder: r, next: rt}
+}
+
+// contactRoundTripper records the requests answered by the API server. Client errors such as 404
+// are answers too; server errors and failed connections are not.
+type contactRoundTripper struct {
+	recorder *contactRecorder
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *contactRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		rt.recorder.last.Store(time.Now().UnixNano())
+	}
+	return resp, err
+}
+
+// LastContact returns when the API server last answered a request of the client, including the
+// watches of the read cache, without a server error. It reports false until the API server has
+// answered, and for clients without one, such as the demo client.
+func (c *Client) LastContact() (time.Time, bool) {
+	if c.contact == nil {
+		return time.Time{}, false
+	}
+	last := c.contact.last.Load()
+	if last == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, last), true
+}
@@ -0,0 +1,50 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests recording the last contact with the API server.
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// TestLastContact verifies that answers of the API server are recorded, including client errors,
+// but not server errors.
+func TestLastContact(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	client, err := CreateClient(ClientConfig{Server: srv.URL, Token: "token"}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("CreateClient() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_ = client.Ping(ctx)
+	if last, ok := client.LastContact(); ok {
+		t.Errorf("expected no contact after a server error, got %v", last)
+	}
+
+	before := time.Now()
+	status.Store(http.StatusNotFound)
+	_ = client.Ping(ctx)
+	if last, ok := client.LastContact(); !ok || last.Before(before) {
+		t.Errorf("expected a contact after %v, got %v, %v", before, last, ok)
+	}
+
+	if _, ok := NewFakeClient(zerolog.Nop()).LastContact(); ok {
+		t.Error("expected no contact of a client without an API server")
+	}
+}
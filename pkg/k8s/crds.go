@@ -0,0 +1,93 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file looks up custom resource definitions and their OpenAPI schemas.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// crdGVR identifies CustomResourceDefinitions.
+var crdGVR = schema.GroupVersionResource{
+	Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions",
+}
+
+// CustomResourceSchema is the OpenAPI v3 schema of one version of a custom resource.
+type CustomResourceSchema struct {
+	Group   string
+	Version string
+	Kind    string
+
+	// Schema is the version's openAPIV3Schema; nil if the CRD does not define one.
+	Schema map[string]any
+}
+
+// GetCustomResourceSchema finds the CRD whose kind, plural, singular or short name is name,
+// case-insensitively, and returns the schema of its storage version.
+// A fully qualified name such as "managedapps.example.com" is matched against the CRD name.
+func (c *Client) GetCustomResourceSchema(ctx context.Context, name string) (CustomResourceSchema, error) {
+	list, err := c.dynamic.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return CustomResourceSchema{}, fmt.Errorf("failed to list custom resource definitions: %w", err)
+	}
+
+	for i := range list.Items {
+		crd := &list.Items[i]
+		if !crdMatches(crd, name) {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		version, openAPISchema := storageVersionSchema(crd)
+		return CustomResourceSchema{Group: group, Version: version, Kind: kind, Schema: openAPISchema}, nil
+	}
+	return CustomResourceSchema{}, fmt.Errorf("no custom resource definition found for %q", name)
+}
+
+// crdMatches reports whether name refers to the CRD.
+func crdMatches(crd *unstructured.Unstructured, name string) bool {
+	if strings.EqualFold(crd.GetName(), name) {
+		return true
+	}
+	names, _, _ := unstructured.NestedMap(crd.Object, "spec", "names")
+	candidates, _, _ := unstructured.NestedStringSlice(names, "shortNames")
+	for _, field := range []string{"kind", "plural", "singular"} {
+		if value, ok := names[field].(string); ok {
+			candidates = append(candidates, value)
+		}
+	}
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// storageVersionSchema returns the storage version of a CRD and its schema.
+// If no version is marked for storage, the first version is used.
+func storageVersionSchema(crd *unstructured.Unstructured) (string, map[string]any) {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	var chosen map[string]any
+	for _, v := range versions {
+		version, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		if chosen == nil || version["storage"] == true {
+			chosen = version
+		}
+	}
+	if chosen == nil {
+		return "", nil
+	}
+
+	name, _ := chosen["name"].(string)
+	openAPISchema, _, _ := unstructured.NestedMap(chosen, "schema", "openAPIV3Schema")
+	return name, openAPISchema
+}
@@ -0,0 +1,60 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests custom resource definition lookups.
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newTestCRD returns a ManagedApp CRD with an unserved v1alpha1 and a stored v1 version.
+func newTestCRD() *unstructured.Unstructured {
+	version := func(name string, storage bool, description string) map[string]any {
+		return map[string]any{
+			"name": name, "served": true, "storage": storage,
+			"schema": map[string]any{"openAPIV3Schema": map[string]any{
+				"type": "object", "description": description,
+			}},
+		}
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": "managedapps.example.com"},
+		"spec": map[string]any{
+			"group": "example.com",
+			"names": map[string]any{
+				"kind": "ManagedApp", "plural": "managedapps", "singular": "managedapp",
+				"shortNames": []any{"mapp"},
+			},
+			"versions": []any{version("v1alpha1", false, "old"), version("v1", true, "current")},
+		},
+	}}
+}
+
+// TestGetCustomResourceSchema verifies matching by CRD names and selection of the storage version.
+func TestGetCustomResourceSchema(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), newTestCRD())
+
+	for _, name := range []string{"ManagedApp", "managedapps", "managedapp", "MAPP", "managedapps.example.com"} {
+		t.Run(name, func(t *testing.T) {
+			crd, err := client.GetCustomResourceSchema(context.Background(), name)
+			if err != nil {
+				t.Fatalf("GetCustomResourceSchema() error = %v", err)
+			}
+			if crd.Group != "example.com" || crd.Version != "v1" || crd.Kind != "ManagedApp" {
+				t.Errorf("unexpected CRD %+v", crd)
+			}
+			if crd.Schema["description"] != "current" {
+				t.Errorf("expected the storage version schema, got %v", crd.Schema)
+			}
+		})
+	}
+
+	if _, err := client.GetCustomResourceSchema(context.Background(), "widget"); err == nil {
+		t.Error("expected an error for an unknown resource")
+	}
+}
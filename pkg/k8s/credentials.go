@@ -0,0 +1,99 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements explicit server, bearer token and exec credential plugin settings,
+// which override the corresponding kubeconfig contents.
+package k8s
+
+import (
+	"errors"
+
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	// Register the OIDC auth provider, so kubeconfigs using it refresh their tokens.
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+)
+
+// DefaultExecAPIVersion is the client.authentication.k8s.io version used for exec plugins
+// configured with ExecCommand, which current plugins such as aws and gke-gcloud-auth-plugin support.
+const DefaultExecAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// validateCredentials rejects combinations of credential settings that conflict.
+func (c ClientConfig) validateCredentials() error {
+	if c.ImpersonateUser == "" && (len(c.ImpersonateGroups) > 0 || c.ImpersonateUID != "") {
+		return errors.New("impersonating groups or a UID requires impersonating a user with --as")
+	}
+	if c.Token != "" && c.ExecCommand != "" {
+		return errors.New("a bearer token and an exec credential plugin cannot be used together")
+	}
+	if c.ExecCommand == "" && (len(c.ExecArgs) > 0 || c.ExecAPIVersion != "") {
+		return errors.New("exec plugin arguments and API version require an exec command")
+	}
+	return nil
+}
+
+// hasExplicitConnection reports whether the server or credentials are given explicitly,
+// in which case the in-cluster configuration is not used.
+func (c ClientConfig) hasExplicitConnection() bool {
+	return c.Server != "" || c.Token != "" || c.ExecCommand != ""
+}
+
+// configOverrides returns the kubeconfig overrides for the explicit context, server and credentials.
+// The server and CA apply even without a kubeconfig file, as they do for kubectl.
+func (c ClientConfig) configOverrides(logger zerolog.Logger) *clientcmd.ConfigOverrides {
+	overrides := &clientcmd.ConfigOverrides{}
+	if c.Context != "" {
+		overrides.CurrentContext = c.Context
+		logger.Debug().Str("context", c.Context).Msg("Using specified context")
+	}
+	if c.Server != "" {
+		overrides.ClusterInfo.Server = c.Server
+		logger.Debug().Str("server", c.Server).Msg("Using specified API server")
+	}
+	if c.CertificateAuthority != "" {
+		overrides.ClusterInfo.CertificateAuthority = c.CertificateAuthority
+	}
+	if c.Token != "" {
+		overrides.AuthInfo.Token = c.Token
+		logger.Debug().Msg("Using bearer token credentials")
+	}
+	if c.ExecCommand != "" {
+		overrides.AuthInfo.Exec = c.execConfig()
+		logger.Debug().Str("command", c.ExecCommand).Msg("Using exec credential plugin")
+	}
+	return overrides
+}
+
+// execConfig describes the exec credential plugin. client-go runs the plugin for the first request,
+// caches its credentials and runs it again when they expire or are rejected by the API server.
+func (c ClientConfig) execConfig() *clientcmdapi.ExecConfig {
+	apiVersion := c.ExecAPIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultExecAPIVersion
+	}
+	return &clientcmdapi.ExecConfig{
+		Command:         c.ExecCommand,
+		Args:            c.ExecArgs,
+		APIVersion:      apiVersion,
+		InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+	}
+}
+
+// clearConflictingCredentials drops the kubeconfig's own credentials when explicit ones replace them,
+// since client-go would otherwise combine a bearer token with an exec or auth provider.
+func clearConflictingCredentials(restConfig *rest.Config, config ClientConfig) {
+	switch {
+	case config.Token != "":
+		restConfig.BearerTokenFile = ""
+		restConfig.ExecProvider, restConfig.AuthProvider = nil, nil
+	case config.ExecCommand != "":
+		restConfig.BearerToken, restConfig.BearerTokenFile = "", ""
+		restConfig.AuthProvider = nil
+	default:
+		return
+	}
+	restConfig.Username, restConfig.Password = "", ""
+	restConfig.CertFile, restConfig.KeyFile = "", ""
+	restConfig.CertData, restConfig.KeyData = nil, nil
+}
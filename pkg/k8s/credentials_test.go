@@ -0,0 +1,95 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests the explicit server, bearer token and exec credential plugin settings.
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// TestLoadKubeconfigCredentials tests that explicit settings override the kubeconfig's server and credentials.
+func TestLoadKubeconfigCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		config     ClientConfig
+		wantHost   string
+		wantToken  string
+		wantExec   string
+		wantExecAV string
+		wantErr    bool
+	}{
+		{"kubeconfig", ClientConfig{}, "https://127.0.0.1:6443", "secret", "", "", false},
+		{"server and token", ClientConfig{Server: "https://10.0.0.1:443", Token: "override"},
+			"https://10.0.0.1:443", "override", "", "", false},
+		{"exec plugin", ClientConfig{ExecCommand: "aws", ExecArgs: []string{"eks", "get-token"}},
+			"https://127.0.0.1:6443", "", "aws eks get-token", DefaultExecAPIVersion, false},
+		{"exec api version", ClientConfig{ExecCommand: "plugin", ExecAPIVersion: "client.authentication.k8s.io/v1"},
+			"https://127.0.0.1:6443", "", "plugin", "client.authentication.k8s.io/v1", false},
+		{"token and exec", ClientConfig{Token: "t", ExecCommand: "aws"}, "", "", "", "", true},
+		{"exec args without command", ClientConfig{ExecArgs: []string{"x"}}, "", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.KubeconfigPath = path
+			restConfig, err := LoadKubeconfig(tt.config, zerolog.Nop())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKubeconfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if restConfig.Host != tt.wantHost {
+				t.Errorf("expected host %q, got %q", tt.wantHost, restConfig.Host)
+			}
+			if restConfig.BearerToken != tt.wantToken {
+				t.Errorf("expected bearer token %q, got %q", tt.wantToken, restConfig.BearerToken)
+			}
+			var exec, execAV string
+			if restConfig.ExecProvider != nil {
+				provider := restConfig.ExecProvider
+				exec = strings.Join(append([]string{provider.Command}, provider.Args...), " ")
+				execAV = provider.APIVersion
+			}
+			if exec != tt.wantExec || execAV != tt.wantExecAV {
+				t.Errorf("expected exec %q (%s), got %q (%s)", tt.wantExec, tt.wantExecAV, exec, execAV)
+			}
+		})
+	}
+}
+
+// TestLoadKubeconfigServerWithoutFile tests that --server and --token suffice without any kubeconfig file.
+func TestLoadKubeconfigServerWithoutFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "missing"))
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, []byte("ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := ClientConfig{
+		Server:               "https://10.0.0.1:443",
+		Token:                "token",
+		CertificateAuthority: caFile,
+	}
+	restConfig, err := LoadKubeconfig(config, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("LoadKubeconfig() error = %v", err)
+	}
+	if restConfig.Host != config.Server || restConfig.BearerToken != config.Token {
+		t.Errorf("expected host %q and token %q, got %q and %q", config.Server, config.Token,
+			restConfig.Host, restConfig.BearerToken)
+	}
+	if restConfig.CAFile != config.CertificateAuthority {
+		t.Errorf("expected CA file %q, got %q", config.CertificateAuthority, restConfig.CAFile)
+	}
+}
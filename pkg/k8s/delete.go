@@ -0,0 +1,46 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements generic deletion of arbitrary resources via the dynamic client.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// Delete deletes the named object of the given resource, letting the garbage collector
+// remove its dependents in the background. The namespace is ignored for cluster-scoped resources.
+// In client dry runs, the object is only checked to exist.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) Delete(ctx context.Context, gvr schema.GroupVersionResource, ns, name string) error {
+	change := authz.Change{
+		Operation: "delete",
+		Resource:  gvr.Resource,
+		Namespace: ns,
+		Name:      name,
+	}
+	if err := c.authorize(ctx, change); err != nil {
+		return err
+	}
+
+	var err error
+	resource := c.dynamic.Resource(gvr).Namespace(ns)
+	if c.skipMutation(change) {
+		_, err = resource.Get(ctx, name, metav1.GetOptions{})
+	} else {
+		propagation := metav1.DeletePropagationBackground
+		err = resource.Delete(ctx, name,
+			metav1.DeleteOptions{PropagationPolicy: &propagation, DryRun: c.dryRunOption()})
+	}
+	if err != nil {
+		c.logger.Error().Err(err).Str("name", name).Msg("Failed to delete resource")
+		return fmt.Errorf("failed to delete %s %q: %w", gvr.Resource, name, err)
+	}
+
+	c.logger.Info().Str("resource", gvr.Resource).Str("namespace", ns).Str("name", name).Msg("Resource deleted")
+	return nil
+}
@@ -0,0 +1,56 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests generic deletion via the dynamic client.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// TestDelete verifies that objects are deleted and that the authorization hook is honored.
+func TestDelete(t *testing.T) {
+	deployments, err := LookupResource("deployments")
+	if err != nil {
+		t.Fatalf("LookupResource() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		authorizer authz.Authorizer
+		target     string
+		wantErr    bool
+		wantDenied bool
+	}{
+		{"deleted", nil, testDeploymentNginx, false, false},
+		{"missing object", nil, "missing", true, false},
+		{"denied", denyAuthorizer{}, testDeploymentNginx, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 1, []string{testImageNginx})
+			client := NewFakeClient(zerolog.Nop(), deployment)
+			client.SetAuthorizer(tt.authorizer)
+
+			err := client.Delete(context.Background(), deployments.GVR, testNamespaceDefault, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Delete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, authz.ErrDenied) != tt.wantDenied {
+				t.Errorf("Delete() error = %v, wantDenied %v", err, tt.wantDenied)
+			}
+
+			_, getErr := client.dynamic.Resource(deployments.GVR).Namespace(testNamespaceDefault).
+				Get(context.Background(), testDeploymentNginx, metav1.GetOptions{})
+			if deleted := getErr != nil; deleted != (tt.name == "deleted") {
+				t.Errorf("unexpected deployment presence after delete, get error = %v", getErr)
+			}
+		})
+	}
+}
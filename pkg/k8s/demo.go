@@ -0,0 +1,373 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements the demo mode, which backs the client with a seeded in-memory fake cluster.
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/openapi/openapitest"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// DemoHost is the API server host reported by demo-mode clients.
+const DemoHost = "demo://in-memory"
+
+// NewFakeClient creates a Client backed by in-memory fake clientsets seeded with the given objects.
+// It is used by demo mode and is convenient for tests outside this package.
+// Metrics objects (PodMetrics, NodeMetrics) seed the fake metrics API instead.
+// The OpenAPI documents of the core, apps and batch groups are served from client-go's test data.
+func NewFakeClient(logger zerolog.Logger, objects ...runtime.Object) *Client {
+	var core, metrics []runtime.Object
+	for _, obj := range objects {
+		if isMetricsObject(obj) {
+			metrics = append(metrics, obj)
+		} else {
+			core = append(core, obj)
+		}
+	}
+
+	// CustomResourceDefinitions are not part of the client-go scheme, so their list kind is registered explicitly.
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme,
+		map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"}, core...)
+	dynamicClient.PrependReactor("patch", "*", fakeApplyReactor(dynamicClient.Tracker()))
+	dynamicClient.PrependReactor("*", "*", fakeDryRunReactor(dynamicClient.Tracker()))
+
+	clientset := fake.NewSimpleClientset(core...)
+	clientset.PrependReactor("*", "*", fakeDryRunReactor(clientset.Tracker()))
+	clientset.Resources = knownAPIResources()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = demoServerVersion()
+
+	return &Client{
+		clientset:     clientset,
+		dynamic:       dynamicClient,
+		metrics:       newFakeMetricsClient(metrics),
+		openAPIClient: openapitest.NewEmbeddedFileClient(),
+		config:        &rest.Config{Host: DemoHost},
+		logger:        logger.With().Str("component", "k8s-client").Bool("demo", true).Logger(),
+	}
+}
+
+// demoServerVersion returns the version reported by the demo cluster's API server.
+func demoServerVersion() *version.Info {
+	return &version.Info{
+		Major:      "1",
+		Minor:      "35",
+		GitVersion: "v1.35.0",
+		GoVersion:  "go1.25.5",
+		Compiler:   "gc",
+		Platform:   "linux/amd64",
+	}
+}
+
+// newFakeMetricsClient creates a fake metrics clientset seeded with PodMetrics and NodeMetrics objects.
+// The objects are added under the "pods" and "nodes" resources the metrics API serves them as,
+// since the fake tracker would otherwise guess resource names the generated client never queries.
+func newFakeMetricsClient(objects []runtime.Object) *metricsfake.Clientset {
+	client := metricsfake.NewSimpleClientset()
+	for _, obj := range objects {
+		var err error
+		switch m := obj.(type) {
+		case *metricsv1beta1.PodMetrics:
+			err = client.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("pods"), m, m.Namespace)
+		case *metricsv1beta1.NodeMetrics:
+			err = client.Tracker().Create(metricsv1beta1.SchemeGroupVersion.WithResource("nodes"), m, "")
+		}
+		if err != nil {
+			panic(fmt.Sprintf("failed to seed fake metrics: %v", err))
+		}
+	}
+	return client
+}
+
+// fakeApplyReactor emulates server-side apply, which the fake dynamic client does not support.
+// The applied configuration is merged into the existing object, or creates it, and dry runs store nothing.
+// Unlike the API server, fields removed from the configuration are kept and lists are replaced.
+func fakeApplyReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch, ok := action.(k8stesting.PatchActionImpl)
+		if !ok || patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		var applied map[string]any
+		if err := json.Unmarshal(patch.GetPatch(), &applied); err != nil {
+			return true, nil, apierrors.NewBadRequest(fmt.Sprintf("invalid apply configuration: %v", err))
+		}
+
+		gvr, ns := patch.GetResource(), patch.GetNamespace()
+		existing, err := tracker.Get(gvr, ns, patch.GetName())
+		if err != nil && !apierrors.IsNotFound(err) {
+			return true, nil, err
+		}
+		obj := &unstructured.Unstructured{Object: applied}
+		if existing != nil {
+			live, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+			if err != nil {
+				return true, nil, err
+			}
+			obj.Object = mergeApplied(live, applied)
+		}
+
+		switch {
+		case len(patch.PatchOptions.DryRun) > 0:
+			return true, obj, nil
+		case existing != nil:
+			return true, obj, tracker.Update(gvr, obj, ns)
+		default:
+			return true, obj, tracker.Create(gvr, obj, ns)
+		}
+	}
+}
+
+// fakeDryRunReactor makes the fake clientsets honor server dry runs: creates, updates, patches,
+// deletes and evictions with the dryRun option are checked against the tracker and answered
+// without changing it. Dry-run server-side applies are left to fakeApplyReactor.
+func fakeDryRunReactor(tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gvr, ns := action.GetResource(), action.GetNamespace()
+		switch a := action.(type) {
+		case k8stesting.CreateActionImpl:
+			if eviction, ok := a.GetObject().(*policyv1.Eviction); ok && a.GetSubresource() == "eviction" {
+				if eviction.DeleteOptions == nil || len(eviction.DeleteOptions.DryRun) == 0 {
+					return false, nil, nil
+				}
+				_, err := tracker.Get(gvr, ns, eviction.Name)
+				return true, nil, err
+			}
+			if len(a.CreateOptions.DryRun) == 0 || a.GetSubresource() != "" {
+				return false, nil, nil
+			}
+			obj, err := meta.Accessor(a.GetObject())
+			if err != nil {
+				return true, nil, err
+			}
+			if _, err := tracker.Get(gvr, ns, obj.GetName()); err == nil {
+				return true, nil, apierrors.NewAlreadyExists(gvr.GroupResource(), obj.GetName())
+			}
+			return true, a.GetObject(), nil
+		case k8stesting.UpdateActionImpl:
+			if len(a.UpdateOptions.DryRun) == 0 || a.GetSubresource() != "" {
+				return false, nil, nil
+			}
+			obj, err := meta.Accessor(a.GetObject())
+			if err != nil {
+				return true, nil, err
+			}
+			_, err = tracker.Get(gvr, ns, obj.GetName())
+			return true, a.GetObject(), err
+		case k8stesting.PatchActionImpl:
+			if len(a.PatchOptions.DryRun) == 0 || a.GetPatchType() == types.ApplyPatchType {
+				return false, nil, nil
+			}
+			existing, err := tracker.Get(gvr, ns, a.GetName())
+			if err != nil {
+				return true, nil, err
+			}
+			patched, err := patchTrackedObject(existing, a.GetPatchType(), a.GetPatch())
+			return true, patched, err
+		case k8stesting.DeleteActionImpl:
+			if len(a.DeleteOptions.DryRun) == 0 {
+				return false, nil, nil
+			}
+			_, err := tracker.Get(gvr, ns, a.GetName())
+			return true, nil, err
+		}
+		return false, nil, nil
+	}
+}
+
+// patchTrackedObject applies a patch to a copy of an object of a fake tracker, see patchObject.
+// The result has the type of the tracked object, as the typed fake clients expect.
+func patchTrackedObject(obj runtime.Object, patchType types.PatchType, data []byte) (runtime.Object, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		u = &unstructured.Unstructured{Object: content}
+		if kinds, _, err := scheme.Scheme.ObjectKinds(obj); err == nil {
+			u.SetGroupVersionKind(kinds[0])
+		}
+	}
+
+	patched, err := patchObject(u, patchType, data)
+	if err != nil || ok {
+		return patched, err
+	}
+	typed := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	return typed, runtime.DefaultUnstructuredConverter.FromUnstructured(patched.Object, typed)
+}
+
+// mergeApplied merges an applied configuration into a live object: maps are merged recursively,
+// all other values are replaced.
+func mergeApplied(live, applied map[string]any) map[string]any {
+	for key, value := range applied {
+		liveMap, liveIsMap := live[key].(map[string]any)
+		appliedMap, appliedIsMap := value.(map[string]any)
+		if liveIsMap && appliedIsMap {
+			live[key] = mergeApplied(liveMap, appliedMap)
+		} else {
+			live[key] = value
+		}
+	}
+	return live
+}
+
+// NewDemoClient creates a demo-mode Client seeded from a fixture file.
+// If fixturePath is empty, a built-in set of demo objects is used.
+func NewDemoClient(fixturePath string, logger zerolog.Logger) (*Client, error) {
+	objects := DefaultDemoObjects(time.Now())
+	if fixturePath != "" {
+		var err error
+		if objects, err = LoadFixtureFile(fixturePath); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Warn().
+		Str("fixture", fixturePath).
+		Int("objects", len(objects)).
+		Msg("Demo mode enabled, using in-memory fake cluster")
+	return NewFakeClient(logger, objects...), nil
+}
+
+// LoadFixtureFile reads Kubernetes objects from a multi-document YAML or JSON file.
+func LoadFixtureFile(path string) ([]runtime.Object, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture file: %w", err)
+	}
+
+	objects, err := DecodeObjects(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fixture file %s: %w", path, err)
+	}
+	return objects, nil
+}
+
+// DecodeObjects decodes all typed Kubernetes objects from a multi-document YAML or JSON stream.
+// Empty documents are skipped; documents of unknown kinds cause an error.
+func DecodeObjects(data []byte) ([]runtime.Object, error) {
+	reader := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	decoder := scheme.Codecs.UniversalDeserializer()
+
+	var objects []runtime.Object
+	for {
+		var raw runtime.RawExtension
+		if err := reader.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+		if len(bytes.TrimSpace(raw.Raw)) == 0 || string(bytes.TrimSpace(raw.Raw)) == "null" {
+			continue
+		}
+
+		obj, _, err := decoder.Decode(raw.Raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object: %w", err)
+		}
+		objects = append(objects, obj)
+	}
+}
+
+// DefaultDemoObjects returns the built-in demo cluster contents, with creation times relative to now.
+func DefaultDemoObjects(now time.Time) []runtime.Object {
+	return []runtime.Object{
+		demoNamespace("default"),
+		demoNamespace("kube-system"),
+		demoNamespace("shop"),
+		demoDeployment("coredns", "kube-system", 2, "registry.k8s.io/coredns/coredns:v1.11.1",
+			now.Add(-30*24*time.Hour)),
+		demoDeployment("frontend", "shop", 3, "nginx:1.27", now.Add(-72*time.Hour)),
+		demoDeployment("cart", "shop", 2, "redis:7.2", now.Add(-48*time.Hour)),
+		demoDeployment("checkout", "shop", 1, "ghcr.io/example/checkout:2.4.0", now.Add(-90*time.Minute)),
+		demoDeployment("hello", "default", 1, "busybox:1.36", now.Add(-5*time.Minute)),
+		demoScalingEvent("checkout", "shop", 1, now.Add(-90*time.Minute)),
+		demoScalingEvent("hello", "default", 1, now.Add(-5*time.Minute)),
+	}
+}
+
+// demoScalingEvent builds the event the deployment controller records when scaling up a deployment.
+func demoScalingEvent(deployment, ns string, replicas int32, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: deployment + ".scaled", Namespace: ns},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Deployment", APIVersion: "apps/v1", Namespace: ns, Name: deployment,
+		},
+		Type:           corev1.EventTypeNormal,
+		Reason:         "ScalingReplicaSet",
+		Message:        fmt.Sprintf("Scaled up replica set %s to %d", deployment, replicas),
+		Source:         corev1.EventSource{Component: "deployment-controller"},
+		FirstTimestamp: metav1.NewTime(at),
+		LastTimestamp:  metav1.NewTime(at),
+		Count:          1,
+	}
+}
+
+// demoNamespace builds a demo namespace.
+func demoNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+// demoDeployment builds a healthy demo deployment running a single container image.
+func demoDeployment(name, ns string, replicas int32, image string, created time.Time) *appsv1.Deployment {
+	labels := map[string]string{"app": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         ns,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(created),
+			Generation:        1,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           replicas,
+			ReadyReplicas:      replicas,
+			AvailableReplicas:  replicas,
+			UpdatedReplicas:    replicas,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+			},
+		},
+	}
+}
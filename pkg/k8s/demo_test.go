@@ -0,0 +1,123 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests demo mode and fixture loading.
+package k8s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// testFixture is a two-document fixture with a namespace and a deployment.
+const testFixture = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: demo
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+---
+`
+
+// TestDecodeObjects verifies multi-document decoding, including error cases.
+func TestDecodeObjects(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantCount int
+		wantErr   bool
+	}{
+		{"multi document", testFixture, 2, false},
+		{"empty", "", 0, false},
+		{"json document", `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"x"}}`, 1, false},
+		{"unknown kind", "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n", 0, true},
+		{"malformed", "apiVersion: [", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := DecodeObjects([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeObjects() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(objects) != tt.wantCount {
+				t.Errorf("expected %d objects, got %d", tt.wantCount, len(objects))
+			}
+		})
+	}
+}
+
+// TestNewDemoClient verifies that demo clients serve built-in or fixture objects.
+func TestNewDemoClient(t *testing.T) {
+	fixturePath := filepath.Join(t.TempDir(), "fixture.yaml")
+	if err := os.WriteFile(fixturePath, []byte(testFixture), 0o600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		fixture   string
+		wantCount int
+		wantErr   bool
+	}{
+		{"built-in objects", "", countDeployments(DefaultDemoObjects(time.Now())), false},
+		{"fixture file", fixturePath, 1, false},
+		{"missing fixture", "/nonexistent/fixture.yaml", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := CreateClient(ClientConfig{Demo: true, DemoFixture: tt.fixture}, zerolog.Nop())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			deployments, err := client.ListDeployments(context.Background(), ListDeploymentsOptions{})
+			if err != nil {
+				t.Fatalf("ListDeployments() error = %v", err)
+			}
+			if len(deployments) != tt.wantCount {
+				t.Errorf("expected %d deployments, got %d", tt.wantCount, len(deployments))
+			}
+			if client.GetConfig().Host != DemoHost {
+				t.Errorf("expected demo host, got %s", client.GetConfig().Host)
+			}
+		})
+	}
+}
+
+// countDeployments counts deployment objects in a slice of objects.
+func countDeployments(objects []runtime.Object) int {
+	count := 0
+	for _, obj := range objects {
+		if _, ok := obj.(*appsv1.Deployment); ok {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,73 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file extracts the strategy, selector, conditions and metadata of deployments.
+package k8s
+
+import (
+	"maps"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentStrategy describes how a deployment replaces old pods with new ones.
+type DeploymentStrategy struct {
+	Type string `json:"type" doc:"Strategy type: RollingUpdate or Recreate"`
+
+	// MaxSurge and MaxUnavailable are set for RollingUpdate, as a count or a percentage.
+	MaxSurge       string `json:"max_surge,omitempty" doc:"Pods that may be created above the desired replicas"`
+	MaxUnavailable string `json:"max_unavailable,omitempty" doc:"Pods that may be unavailable during the update"`
+}
+
+// DeploymentCondition is a condition of a deployment, e.g. Available or Progressing.
+type DeploymentCondition struct {
+	Type    string `json:"type" doc:"Condition type, e.g. Available or Progressing"`
+	Status  string `json:"status" doc:"True, False or Unknown"`
+	Reason  string `json:"reason,omitempty" doc:"Machine-readable reason for the last transition"`
+	Message string `json:"message,omitempty" doc:"Human-readable details of the last transition"`
+
+	LastTransition time.Time `json:"last_transition" doc:"Time the condition last changed status"`
+}
+
+// addDeploymentDetails copies the metadata, strategy, selector, generations and conditions of a
+// deployment into info.
+func addDeploymentDetails(info *DeploymentInfo, deployment *appsv1.Deployment) {
+	// Deployments may be shared with the read cache, so their maps are copied.
+	info.Labels = maps.Clone(deployment.Labels)
+	info.Annotations = maps.Clone(deployment.Annotations)
+	info.Generation = deployment.Generation
+	info.ObservedGeneration = deployment.Status.ObservedGeneration
+	if deployment.Spec.Selector != nil {
+		info.Selector = metav1.FormatLabelSelector(deployment.Spec.Selector)
+	}
+
+	info.Strategy.Type = string(deployment.Spec.Strategy.Type)
+	if rolling := deployment.Spec.Strategy.RollingUpdate; rolling != nil {
+		if rolling.MaxSurge != nil {
+			info.Strategy.MaxSurge = rolling.MaxSurge.String()
+		}
+		if rolling.MaxUnavailable != nil {
+			info.Strategy.MaxUnavailable = rolling.MaxUnavailable.String()
+		}
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		info.Conditions = append(info.Conditions, DeploymentCondition{
+			Type:           string(condition.Type),
+			Status:         string(condition.Status),
+			Reason:         condition.Reason,
+			Message:        condition.Message,
+			LastTransition: condition.LastTransitionTime.Time,
+		})
+	}
+}
+
+// Condition returns the condition of the given type, e.g. "Available", and whether it is present.
+func (d DeploymentInfo) Condition(conditionType string) (DeploymentCondition, bool) {
+	for _, condition := range d.Conditions {
+		if condition.Type == conditionType {
+			return condition, true
+		}
+	}
+	return DeploymentCondition{}, false
+}
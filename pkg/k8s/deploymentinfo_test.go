@@ -0,0 +1,69 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests extracting the strategy, selector, conditions and metadata of deployments.
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// TestAddDeploymentDetails verifies the details copied from a deployment.
+func TestAddDeploymentDetails(t *testing.T) {
+	surge, unavailable := intstr.FromString("25%"), intstr.FromInt32(0)
+	transition := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{"owner": "team-a"},
+			Generation:  4,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Strategy: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &surge, MaxUnavailable: &unavailable},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 3,
+			Conditions: []appsv1.DeploymentCondition{{
+				Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse,
+				Reason: "ProgressDeadlineExceeded", LastTransitionTime: metav1.NewTime(transition),
+			}},
+		},
+	}
+
+	var info DeploymentInfo
+	addDeploymentDetails(&info, deployment)
+
+	if info.Selector != "app=web" || info.Labels["app"] != "web" || info.Annotations["owner"] != "team-a" {
+		t.Errorf("unexpected selector or metadata: %+v", info)
+	}
+	want := DeploymentStrategy{Type: "RollingUpdate", MaxSurge: "25%", MaxUnavailable: "0"}
+	if info.Strategy != want {
+		t.Errorf("expected strategy %+v, got %+v", want, info.Strategy)
+	}
+	if info.Generation != 4 || info.ObservedGeneration != 3 {
+		t.Errorf("expected generation 4 observed 3, got %d and %d", info.Generation, info.ObservedGeneration)
+	}
+
+	progressing, ok := info.Condition("Progressing")
+	if !ok || progressing.Status != "False" || progressing.Reason != "ProgressDeadlineExceeded" ||
+		!progressing.LastTransition.Equal(transition) {
+		t.Errorf("unexpected Progressing condition: %+v", progressing)
+	}
+	if _, ok := info.Condition("Available"); ok {
+		t.Error("expected no Available condition")
+	}
+
+	// The labels are copied, so callers cannot modify the deployment through them.
+	info.Labels["app"] = "changed"
+	if deployment.Labels["app"] != "web" {
+		t.Error("expected the deployment's labels to be copied")
+	}
+}
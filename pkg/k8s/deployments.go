@@ -0,0 +1,141 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements creating, replacing and deleting deployments.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// DecodeDeployment decodes a single apps/v1 Deployment manifest in YAML or JSON.
+// Unknown fields are rejected, so that typos are not silently dropped.
+func DecodeDeployment(data []byte) (*appsv1.Deployment, error) {
+	objects, err := DecodeManifests(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) != 1 {
+		return nil, fmt.Errorf("expected a single deployment manifest, got %d", len(objects))
+	}
+	obj := objects[0]
+	if obj.GetAPIVersion() != appsv1.SchemeGroupVersion.String() || obj.GetKind() != "Deployment" {
+		return nil, fmt.Errorf("expected an apps/v1 Deployment, got %s %s", obj.GetAPIVersion(), obj.GetKind())
+	}
+
+	var deployment appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(obj.Object, &deployment,
+		true); err != nil {
+		return nil, fmt.Errorf("invalid deployment: %w", err)
+	}
+	return &deployment, nil
+}
+
+// CreateDeployment creates a deployment in its namespace and returns it as stored by the API server.
+// In client dry runs, the deployment is returned without sending it.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) CreateDeployment(ctx context.Context, deployment *appsv1.Deployment) (DeploymentInfo, error) {
+	change := authz.Change{
+		Operation: "create",
+		Resource:  "deployments",
+		Namespace: deployment.Namespace,
+		Name:      deployment.Name,
+	}
+	if err := c.authorize(ctx, change); err != nil {
+		return DeploymentInfo{}, err
+	}
+
+	created := deployment
+	if !c.skipMutation(change) {
+		var err error
+		created, err = c.clientset.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment,
+			metav1.CreateOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+		if err != nil {
+			return DeploymentInfo{}, fmt.Errorf("failed to create deployment %q: %w", deployment.Name, err)
+		}
+	}
+
+	c.logger.Info().Str("namespace", deployment.Namespace).Str("name", deployment.Name).Msg("Deployment created")
+	return c.createDeploymentInfo(*created, time.Now()), nil
+}
+
+// ReplaceDeployment replaces the labels, annotations and spec of an existing deployment with those of
+// deployment. If deployment carries a resource version, the replacement fails with a conflict when the
+// live deployment was modified since; otherwise it is applied to the latest version with UpdateWithRetry.
+// In client dry runs, the replaced deployment is returned without writing it.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) ReplaceDeployment(ctx context.Context, deployment *appsv1.Deployment) (DeploymentInfo, error) {
+	change := authz.Change{
+		Operation: "replace",
+		Resource:  "deployments",
+		Namespace: deployment.Namespace,
+		Name:      deployment.Name,
+	}
+	if err := c.authorize(ctx, change); err != nil {
+		return DeploymentInfo{}, err
+	}
+
+	skip := c.skipMutation(change)
+	deployments := c.clientset.AppsV1().Deployments(deployment.Namespace)
+	get := func(ctx context.Context) (*appsv1.Deployment, error) {
+		return deployments.Get(ctx, deployment.Name, metav1.GetOptions{})
+	}
+	update := func(ctx context.Context, obj *appsv1.Deployment) (*appsv1.Deployment, error) {
+		if skip {
+			return obj, nil
+		}
+		return deployments.Update(ctx, obj, metav1.UpdateOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+	}
+
+	var replaced *appsv1.Deployment
+	var err error
+	if deployment.ResourceVersion != "" && !skip {
+		// The API server rejects the update with a conflict if the deployment was modified since.
+		replaced, err = update(ctx, deployment)
+	} else {
+		replaced, _, err = UpdateWithRetry(ctx, get, func(live *appsv1.Deployment) (bool, error) {
+			live.Labels = deployment.Labels
+			live.Annotations = deployment.Annotations
+			live.Spec = deployment.Spec
+			return true, nil
+		}, update)
+	}
+	if err != nil {
+		return DeploymentInfo{}, fmt.Errorf("failed to replace deployment %q: %w", deployment.Name, err)
+	}
+
+	c.logger.Info().Str("namespace", deployment.Namespace).Str("name", deployment.Name).Msg("Deployment replaced")
+	return c.createDeploymentInfo(*replaced, time.Now()), nil
+}
+
+// DeleteDeployment deletes a deployment, letting the garbage collector remove its replica sets and pods
+// in the background. In client dry runs, the deployment is only checked to exist.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) DeleteDeployment(ctx context.Context, ns, name string) error {
+	change := authz.Change{Operation: "delete", Resource: "deployments", Namespace: ns, Name: name}
+	if err := c.authorize(ctx, change); err != nil {
+		return err
+	}
+
+	var err error
+	deployments := c.clientset.AppsV1().Deployments(ns)
+	if c.skipMutation(change) {
+		_, err = deployments.Get(ctx, name, metav1.GetOptions{})
+	} else {
+		propagation := metav1.DeletePropagationBackground
+		err = deployments.Delete(ctx, name,
+			metav1.DeleteOptions{PropagationPolicy: &propagation, DryRun: c.dryRunOption()})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete deployment %q: %w", name, err)
+	}
+
+	c.logger.Info().Str("namespace", ns).Str("name", name).Msg("Deployment deleted")
+	return nil
+}
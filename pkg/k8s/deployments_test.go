@@ -0,0 +1,154 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests creating, replacing and deleting deployments.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// testDeploymentManifest is a minimal deployment manifest of the web deployment.
+const testDeploymentManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 2
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web}
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+`
+
+// TestDecodeDeployment verifies that exactly one valid apps/v1 Deployment is accepted in YAML or JSON.
+func TestDecodeDeployment(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"yaml", testDeploymentManifest, ""},
+		{"json", `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"replicas":1}}`, ""},
+		{"several manifests", testDeploymentManifest + "---\n" + testDeploymentManifest, "single deployment"},
+		{"other kind", "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n", "apps/v1 Deployment"},
+		{"unknown field", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replica: 2\n",
+			"invalid deployment"},
+		{"malformed", "{", "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment, err := DecodeDeployment([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("DecodeDeployment() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || deployment.Name != "web" || deployment.Spec.Replicas == nil {
+				t.Errorf("DecodeDeployment() = %+v, %v", deployment, err)
+			}
+		})
+	}
+}
+
+// TestCreateDeployment verifies creation, duplicate detection, client dry runs and the authorization hook.
+func TestCreateDeployment(t *testing.T) {
+	deployment, err := DecodeDeployment([]byte(testDeploymentManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	dryRun := NewFakeClient(zerolog.Nop())
+	dryRun.SetDryRun(DryRunClient)
+	if info, err := dryRun.CreateDeployment(ctx, deployment); err != nil || info.Name != "web" {
+		t.Errorf("dry run CreateDeployment() = %+v, %v", info, err)
+	}
+	if _, err := dryRun.GetDeployment(ctx, testNamespaceDefault, "web"); err == nil {
+		t.Error("expected the dry run not to create the deployment")
+	}
+
+	client := NewFakeClient(zerolog.Nop())
+	info, err := client.CreateDeployment(ctx, deployment)
+	if err != nil || info.Name != "web" || info.Namespace != testNamespaceDefault || info.Replicas.Desired != 2 {
+		t.Fatalf("CreateDeployment() = %+v, %v", info, err)
+	}
+	if _, err := client.CreateDeployment(ctx, deployment); !apierrors.IsAlreadyExists(err) {
+		t.Errorf("expected an already exists error, got %v", err)
+	}
+
+	client.SetAuthorizer(denyAuthorizer{})
+	if _, err := client.CreateDeployment(ctx, deployment); !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("expected denial, got %v", err)
+	}
+}
+
+// TestReplaceDeployment verifies that the spec is replaced and missing deployments are reported.
+func TestReplaceDeployment(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), demoDeployment("web", testNamespaceDefault, 1, "nginx:1.26", time.Now()))
+	ctx := context.Background()
+
+	deployment, err := DecodeDeployment([]byte(testDeploymentManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.ReplaceDeployment(ctx, deployment)
+	if err != nil || info.Replicas.Desired != 2 || len(info.Images) != 1 || info.Images[0] != "nginx:1.27" {
+		t.Errorf("ReplaceDeployment() = %+v, %v, want 2 replicas of nginx:1.27", info, err)
+	}
+
+	missing := deployment.DeepCopy()
+	missing.Name = "missing"
+	if _, err := client.ReplaceDeployment(ctx, missing); !apierrors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	client.SetAuthorizer(denyAuthorizer{})
+	if _, err := client.ReplaceDeployment(ctx, &appsv1.Deployment{}); !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("expected denial, got %v", err)
+	}
+}
+
+// TestDeleteDeployment verifies deletion, client dry runs and the authorization hook.
+func TestDeleteDeployment(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), demoDeployment("web", testNamespaceDefault, 1, "nginx:1.26", time.Now()))
+	ctx := context.Background()
+
+	client.SetAuthorizer(denyAuthorizer{})
+	if err := client.DeleteDeployment(ctx, testNamespaceDefault, "web"); !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("expected denial, got %v", err)
+	}
+	client.SetAuthorizer(nil)
+
+	client.SetDryRun(DryRunClient)
+	if err := client.DeleteDeployment(ctx, testNamespaceDefault, "web"); err != nil {
+		t.Errorf("dry run DeleteDeployment() error = %v", err)
+	}
+	if err := client.DeleteDeployment(ctx, testNamespaceDefault, "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected dry run to report a missing deployment, got %v", err)
+	}
+	client.SetDryRun(DryRunNone)
+
+	if err := client.DeleteDeployment(ctx, testNamespaceDefault, "web"); err != nil {
+		t.Fatalf("DeleteDeployment() error = %v", err)
+	}
+	if _, err := client.GetDeployment(ctx, testNamespaceDefault, "web"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the deployment to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements diffing of manifests against live objects using server-side dry-run apply.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Searge/k8s-controller/pkg/redact"
+)
+
+// secretMask replaces Secret values in diffs unless secrets are shown.
+const secretMask = "***"
+
+// redactedChangeNote ends the merged side of a diff in which only redacted values change, which would
+// otherwise render as no change.
+const redactedChangeNote = "# redacted values change\n"
+
+// ObjectDiff is the difference between a live object and the result of applying its manifest.
+type ObjectDiff struct {
+	Kind      string `json:"kind" yaml:"kind"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+
+	// Created reports that the object does not exist yet and would be created.
+	Created bool `json:"created" yaml:"created"`
+
+	// Diff is the unified diff from the live to the merged object; empty when nothing would change.
+	Diff string `json:"diff,omitempty" yaml:"diff,omitempty"`
+}
+
+// Changed reports whether applying the manifest would change the object.
+func (d ObjectDiff) Changed() bool {
+	return d.Diff != ""
+}
+
+// DiffOptions configures Diff.
+type DiffOptions struct {
+	// Namespace is used for namespaced objects whose manifest has no namespace; defaults to "default".
+	Namespace string
+
+	// ShowSecrets disables masking of Secret data in diffs.
+	ShowSecrets bool
+
+	// Redactor masks sensitive values in both sides of diffs, e.g. of env vars. Nil shows them.
+	Redactor *redact.Redactor
+}
+
+// DecodeManifests decodes all objects from a multi-document YAML or JSON stream.
+// Empty documents are skipped; every object must have a kind and a name.
+func DecodeManifests(data []byte) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	var objects []*unstructured.Unstructured
+	for {
+		var raw map[string]any
+		if err := reader.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("manifest %d: kind and metadata.name are required", len(objects)+1)
+		}
+		objects = append(objects, obj)
+	}
+}
+
+// Diff computes, for each manifest, the difference between the live object and the object
+// the API server would store after a server-side apply. The apply is a dry run, so nothing is changed.
+func (c *Client) Diff(ctx context.Context, objects []*unstructured.Unstructured,
+	opts DiffOptions) ([]ObjectDiff, error) {
+	diffs := make([]ObjectDiff, 0, len(objects))
+	for _, obj := range objects {
+		diff, err := c.diffObject(ctx, obj.DeepCopy(), opts)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// diffObject dry-run applies a single manifest and diffs the result against the live object.
+func (c *Client) diffObject(ctx context.Context, obj *unstructured.Unstructured, opts DiffOptions) (ObjectDiff, error) {
+	info, err := LookupResource(obj.GetKind())
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+	setObjectNamespace(obj, info, opts.Namespace)
+
+	result := ObjectDiff{Kind: info.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	resource := c.dynamic.Resource(info.GVR).Namespace(obj.GetNamespace())
+
+	live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		live, result.Created = nil, true
+	} else if err != nil {
+		return ObjectDiff{}, fmt.Errorf("failed to get %s %q: %w", info.GVR.Resource, obj.GetName(), err)
+	}
+
+	merged, err := resource.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+		FieldManager: fieldManager,
+		DryRun:       []string{metav1.DryRunAll},
+		Force:        true,
+	})
+	if err != nil {
+		return ObjectDiff{}, fmt.Errorf("failed to dry-run apply %s %q: %w", info.GVR.Resource, obj.GetName(), err)
+	}
+
+	if result.Diff, err = unifiedDiff(result, live, merged, opts); err != nil {
+		return ObjectDiff{}, err
+	}
+	return result, nil
+}
+
+// defaultNamespace returns ns, or "default" when it is empty.
+func defaultNamespace(ns string) string {
+	if ns == "" {
+		return "default"
+	}
+	return ns
+}
+
+// unifiedDiff renders the unified diff between the live and merged objects as YAML, with Secret data and
+// the values matched by the redactor of opts masked. A nil live object is rendered as an empty document.
+func unifiedDiff(result ObjectDiff, live, merged *unstructured.Unstructured, opts DiffOptions) (string, error) {
+	var before, after map[string]any
+	if live != nil {
+		before = comparableObject(live)
+	}
+	after = comparableObject(merged)
+	if result.Kind == "Secret" && !opts.ShowSecrets {
+		maskSecretData(before, after)
+	}
+	changed := !reflect.DeepEqual(before, after)
+
+	from, err := marshalDiffSide(opts.Redactor.Object(before))
+	if err != nil {
+		return "", err
+	}
+	to, err := marshalDiffSide(opts.Redactor.Object(after))
+	if err != nil {
+		return "", err
+	}
+	if changed && from == to {
+		to += redactedChangeNote
+	}
+
+	path := strings.ToLower(result.Kind) + "/" + result.Name
+	if result.Namespace != "" {
+		path = result.Namespace + "/" + path
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: "live/" + path,
+		ToFile:   "merged/" + path,
+		Context:  3,
+	})
+}
+
+// marshalDiffSide renders one side of a diff as YAML; nil renders as an empty string.
+func marshalDiffSide(obj map[string]any) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to render object: %w", err)
+	}
+	return string(data), nil
+}
+
+// comparableObject returns a copy of the object without server-managed metadata that changes on every write.
+func comparableObject(obj *unstructured.Unstructured) map[string]any {
+	clean := obj.DeepCopy()
+	for _, field := range serverMetadataFields {
+		unstructured.RemoveNestedField(clean.Object, "metadata", field)
+	}
+	return clean.Object
+}
+
+// maskSecretData replaces the values of Secret data and stringData in both sides of a diff.
+// Values that differ are masked differently, so the diff still shows which keys change.
+func maskSecretData(before, after map[string]any) {
+	for _, field := range []string{"data", "stringData"} {
+		beforeData, _ := before[field].(map[string]any)
+		afterData, _ := after[field].(map[string]any)
+		for key, value := range afterData {
+			previous, existed := beforeData[key]
+			switch {
+			case existed && fmt.Sprint(previous) != fmt.Sprint(value):
+				beforeData[key], afterData[key] = secretMask+" (before)", secretMask+" (after)"
+			case existed:
+				beforeData[key], afterData[key] = secretMask, secretMask
+			default:
+				afterData[key] = secretMask
+			}
+		}
+		for key := range beforeData {
+			if _, kept := afterData[key]; !kept {
+				beforeData[key] = secretMask
+			}
+		}
+	}
+}
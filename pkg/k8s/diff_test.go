@@ -0,0 +1,204 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests diffing of manifests against live objects.
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Searge/k8s-controller/pkg/redact"
+)
+
+const testDiffManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: flags
+data:
+  beta: "true"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+stringData:
+  password: hunter3
+---
+`
+
+// newDiffTestClient returns a fake client with live versions of the objects in testDiffManifests.
+func newDiffTestClient() *Client {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: testNamespaceDefault}
+	}
+	return NewFakeClient(zerolog.Nop(),
+		&corev1.ConfigMap{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: meta("settings"), Data: map[string]string{"mode": "slow"}},
+		&corev1.Secret{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: meta("creds"), StringData: map[string]string{"password": "hunter2"}})
+}
+
+// TestDecodeManifests verifies decoding of multi-document manifests and rejection of incomplete objects.
+func TestDecodeManifests(t *testing.T) {
+	objects, err := DecodeManifests([]byte(testDiffManifests))
+	if err != nil {
+		t.Fatalf("DecodeManifests() error = %v", err)
+	}
+	if len(objects) != 3 || objects[2].GetKind() != "Secret" {
+		t.Errorf("expected 3 objects ending with a Secret, got %d", len(objects))
+	}
+
+	if _, err := DecodeManifests([]byte("apiVersion: v1\nkind: ConfigMap\n")); err == nil {
+		t.Error("expected an error for a manifest without a name")
+	}
+}
+
+// TestDiff verifies diffs of changed, new and unchanged objects, and that the apply is a dry run.
+func TestDiff(t *testing.T) {
+	client := newDiffTestClient()
+	objects, err := DecodeManifests([]byte(testDiffManifests))
+	if err != nil {
+		t.Fatalf("DecodeManifests() error = %v", err)
+	}
+
+	diffs, err := client.Diff(context.Background(), objects, DiffOptions{ShowSecrets: true})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 diffs, got %d", len(diffs))
+	}
+
+	settings, flags, creds := diffs[0], diffs[1], diffs[2]
+	if settings.Created || !strings.Contains(settings.Diff, "-  mode: slow") ||
+		!strings.Contains(settings.Diff, "+  mode: fast") {
+		t.Errorf("unexpected diff for changed object:\n%s", settings.Diff)
+	}
+	if !strings.Contains(settings.Diff, "--- live/default/configmap/settings") {
+		t.Errorf("expected live/merged headers, got:\n%s", settings.Diff)
+	}
+	if !flags.Created || flags.Namespace != testNamespaceDefault || !strings.Contains(flags.Diff, "+  beta: \"true\"") {
+		t.Errorf("unexpected diff for new object: %+v", flags)
+	}
+	if !strings.Contains(creds.Diff, "+  password: hunter3") {
+		t.Errorf("expected secret values with ShowSecrets, got:\n%s", creds.Diff)
+	}
+
+	if _, err := client.clientset.CoreV1().ConfigMaps(testNamespaceDefault).
+		Get(context.Background(), "flags", metav1.GetOptions{}); err == nil {
+		t.Error("expected the dry run not to create objects")
+	}
+	live, err := client.dynamic.Resource(objects[0].GroupVersionKind().GroupVersion().WithResource("configmaps")).
+		Namespace(testNamespaceDefault).Get(context.Background(), "settings", metav1.GetOptions{})
+	if err != nil || live.Object["data"].(map[string]any)["mode"] != "slow" {
+		t.Errorf("expected the dry run not to change objects, got %v (%v)", live, err)
+	}
+}
+
+// TestDiffUnchanged verifies that applying the live state produces no diff.
+func TestDiffUnchanged(t *testing.T) {
+	objects, err := DecodeManifests([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n" +
+		"data:\n  mode: slow\n"))
+	if err != nil {
+		t.Fatalf("DecodeManifests() error = %v", err)
+	}
+
+	diffs, err := newDiffTestClient().Diff(context.Background(), objects, DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if diffs[0].Changed() {
+		t.Errorf("expected no change, got:\n%s", diffs[0].Diff)
+	}
+}
+
+// TestUnifiedDiffRedaction verifies that env values matched by the redactor are masked in both sides of
+// diffs, and that a change of masked values alone is still reported as a change.
+func TestUnifiedDiffRedaction(t *testing.T) {
+	redactor, err := redact.New(redact.DefaultPatterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment := func(image, password string) *unstructured.Unstructured {
+		container := map[string]any{"name": "web", "image": image, "env": []any{
+			map[string]any{"name": "DB_PASSWORD", "value": password},
+			map[string]any{"name": "LOG_LEVEL", "value": "info"},
+		}}
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]any{"name": "web"},
+			"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+				"containers": []any{container}}}},
+		}}
+	}
+	result := ObjectDiff{Kind: "Deployment", Name: "web"}
+
+	tests := []struct {
+		name        string
+		live        *unstructured.Unstructured
+		redactor    *redact.Redactor
+		want        []string
+		wantChanged bool
+	}{
+		{"other change", deployment("nginx:1.26", "hunter2"), redactor,
+			[]string{"-        image: nginx:1.26", "+        image: nginx:1.27", "value: " + redact.Mask}, true},
+		{"created", nil, redactor, []string{"+          value: " + redact.Mask}, true},
+		{"redacted change only", deployment("nginx:1.27", "hunter2"), redactor,
+			[]string{"+" + strings.TrimSuffix(redactedChangeNote, "\n")}, true},
+		{"unchanged", deployment("nginx:1.27", "hunter3"), redactor, nil, false},
+		{"no redaction", deployment("nginx:1.27", "hunter2"), nil,
+			[]string{"-          value: hunter2", "+          value: hunter3"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := unifiedDiff(result, tt.live, deployment("nginx:1.27", "hunter3"),
+				DiffOptions{Redactor: tt.redactor})
+			if err != nil {
+				t.Fatalf("unifiedDiff() error = %v", err)
+			}
+			if (diff != "") != tt.wantChanged {
+				t.Errorf("expected changed %v, got:\n%s", tt.wantChanged, diff)
+			}
+			if tt.redactor != nil && (strings.Contains(diff, "hunter2") || strings.Contains(diff, "hunter3")) {
+				t.Errorf("expected the password to be masked, got:\n%s", diff)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(diff, want) {
+					t.Errorf("expected %q in the diff, got:\n%s", want, diff)
+				}
+			}
+		})
+	}
+}
+
+// TestMaskSecretData verifies that secret values are masked while changed keys stay visible.
+func TestMaskSecretData(t *testing.T) {
+	before := map[string]any{"data": map[string]any{"same": "YQ==", "changed": "Yg==", "removed": "Yw=="}}
+	after := map[string]any{"data": map[string]any{"same": "YQ==", "changed": "ZA==", "added": "ZQ=="}}
+	maskSecretData(before, after)
+
+	wantBefore := map[string]string{"same": secretMask, "changed": secretMask + " (before)", "removed": secretMask}
+	wantAfter := map[string]string{"same": secretMask, "changed": secretMask + " (after)", "added": secretMask}
+	for key, want := range wantBefore {
+		if got := before["data"].(map[string]any)[key]; got != want {
+			t.Errorf("before[%s] = %v, want %q", key, got, want)
+		}
+	}
+	for key, want := range wantAfter {
+		if got := after["data"].(map[string]any)[key]; got != want {
+			t.Errorf("after[%s] = %v, want %q", key, got, want)
+		}
+	}
+}
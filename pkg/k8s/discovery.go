@@ -0,0 +1,159 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file lists the API groups, versions and resources served by the cluster via the discovery API.
+package k8s
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// CoreAPIGroup is the name used to select the core API group, which is unnamed in the API.
+const CoreAPIGroup = "core"
+
+// APIResourceInfo describes a resource served by the cluster.
+type APIResourceInfo struct {
+	Name       string   `json:"name" yaml:"name"`
+	ShortNames []string `json:"shortNames,omitempty" yaml:"shortNames,omitempty"`
+
+	// APIVersion is the group/version the resource is served at, e.g. "apps/v1" or "v1".
+	APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+	Namespaced bool     `json:"namespaced" yaml:"namespaced"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Verbs      []string `json:"verbs" yaml:"verbs"`
+}
+
+// Group returns the API group of the resource; the core group is empty.
+func (r APIResourceInfo) Group() string {
+	group, _, found := strings.Cut(r.APIVersion, "/")
+	if !found {
+		return ""
+	}
+	return group
+}
+
+// APIResourcesOptions filters the resources returned by APIResources.
+type APIResourcesOptions struct {
+	// APIGroup limits the result to one API group, e.g. "apps"; CoreAPIGroup selects the core ("v1") group.
+	APIGroup string
+
+	// Namespaced, if set, limits the result to namespaced (true) or cluster-scoped (false) resources.
+	Namespaced *bool
+
+	// Verbs limits the result to resources supporting all of the given verbs, e.g. "list" and "watch".
+	Verbs []string
+}
+
+// ServerVersion returns the version and platform of the Kubernetes API server.
+func (c *Client) ServerVersion() (*version.Info, error) {
+	info, err := c.clientset.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+	return info, nil
+}
+
+// APIVersions returns the group/versions served by the cluster, e.g. "apps/v1" and "v1", sorted.
+func (c *Client) APIVersions() ([]string, error) {
+	groups, err := c.clientset.Discovery().ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+	var versions []string
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			versions = append(versions, version.GroupVersion)
+		}
+	}
+	sort.Strings(versions)
+	return slices.Compact(versions), nil
+}
+
+// APIResources returns the resources served by the cluster at the preferred version of each group,
+// sorted by group, core group first, and name. Subresources such as "deployments/scale" are omitted.
+// If some groups cannot be discovered, e.g. because an aggregated API server is down,
+// the resources of the other groups are returned and the failures are logged.
+func (c *Client) APIResources(opts APIResourcesOptions) ([]APIResourceInfo, error) {
+	lists, err := discovery.ServerPreferredResources(c.clientset.Discovery())
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("failed to discover API resources: %w", err)
+		}
+		c.logger.Warn().Err(err).Msg("Some API groups could not be discovered")
+	}
+
+	var resources []APIResourceInfo
+	for _, list := range lists {
+		for _, resource := range list.APIResources {
+			info := APIResourceInfo{
+				Name:       resource.Name,
+				ShortNames: resource.ShortNames,
+				APIVersion: list.GroupVersion,
+				Namespaced: resource.Namespaced,
+				Kind:       resource.Kind,
+				Verbs:      resource.Verbs,
+			}
+			if !strings.Contains(resource.Name, "/") && opts.matches(info) {
+				resources = append(resources, info)
+			}
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if gi, gj := resources[i].Group(), resources[j].Group(); gi != gj {
+			return gi < gj
+		}
+		return resources[i].Name < resources[j].Name
+	})
+	return resources, nil
+}
+
+// matches reports whether a resource passes the filters.
+func (opts APIResourcesOptions) matches(info APIResourceInfo) bool {
+	group := info.Group()
+	if group == "" {
+		group = CoreAPIGroup
+	}
+	if opts.APIGroup != "" && group != opts.APIGroup {
+		return false
+	}
+	if opts.Namespaced != nil && info.Namespaced != *opts.Namespaced {
+		return false
+	}
+	for _, verb := range opts.Verbs {
+		if !slices.Contains(info.Verbs, verb) {
+			return false
+		}
+	}
+	return true
+}
+
+// knownAPIResources returns discovery documents for the built-in resources known to the client,
+// as served by the demo cluster.
+func knownAPIResources() []*metav1.APIResourceList {
+	verbs := metav1.Verbs{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}
+	var lists []*metav1.APIResourceList
+	byVersion := map[string]*metav1.APIResourceList{}
+	for _, entry := range knownResources {
+		groupVersion := entry.info.GVR.GroupVersion().String()
+		list := byVersion[groupVersion]
+		if list == nil {
+			list = &metav1.APIResourceList{GroupVersion: groupVersion}
+			byVersion[groupVersion] = list
+			lists = append(lists, list)
+		}
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:         entry.aliases[0],
+			SingularName: entry.aliases[1],
+			ShortNames:   entry.aliases[2:],
+			Namespaced:   entry.info.Namespaced,
+			Kind:         entry.info.Kind,
+			Verbs:        verbs,
+		})
+	}
+	return lists
+}
@@ -0,0 +1,104 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests listing API versions and resources via the discovery API.
+package k8s
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// TestAPIVersions verifies that the group/versions of the demo cluster are listed sorted.
+func TestAPIVersions(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+
+	versions, err := client.APIVersions()
+	if err != nil {
+		t.Fatalf("APIVersions() error = %v", err)
+	}
+	want := "apps/v1,batch/v1,networking.k8s.io/v1,v1"
+	if got := strings.Join(versions, ","); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+// TestAPIResources verifies listing and filtering resources.
+func TestAPIResources(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+	clusterScoped := false
+
+	tests := []struct {
+		name string
+		opts APIResourcesOptions
+		want string
+	}{
+		{"api group", APIResourcesOptions{APIGroup: "batch"}, "cronjobs,jobs"},
+		{"core group", APIResourcesOptions{APIGroup: CoreAPIGroup, Namespaced: &clusterScoped}, "namespaces,nodes"},
+		{"verbs", APIResourcesOptions{APIGroup: "apps", Verbs: []string{"list", "watch"}},
+			"daemonsets,deployments,replicasets,statefulsets"},
+		{"unsupported verb", APIResourcesOptions{Verbs: []string{"proxy"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources, err := client.APIResources(tt.opts)
+			if err != nil {
+				t.Fatalf("APIResources() error = %v", err)
+			}
+			var names []string
+			for _, resource := range resources {
+				names = append(names, resource.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	resources, _ := client.APIResources(APIResourcesOptions{})
+	if first := resources[0]; first.Group() != "" || first.Name != "configmaps" ||
+		strings.Join(first.ShortNames, ",") != "cm" {
+		t.Errorf("expected the core group first, starting with configmaps, got %+v", first)
+	}
+}
+
+// TestAPIResourcesError verifies that discovery failures are reported.
+func TestAPIResourcesError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).PrependReactor("get", "group",
+		func(ktesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+	client := &Client{clientset: clientset, logger: zerolog.Nop()}
+
+	if _, err := client.APIResources(APIResourcesOptions{}); err == nil {
+		t.Error("expected APIResources() to fail")
+	}
+	if _, err := client.APIVersions(); err == nil {
+		t.Error("expected APIVersions() to fail")
+	}
+}
+
+// TestServerVersion verifies the version reported by the demo cluster and discovery failures.
+func TestServerVersion(t *testing.T) {
+	info, err := NewFakeClient(zerolog.Nop()).ServerVersion()
+	if err != nil || info.GitVersion != "v1.35.0" || info.Platform != "linux/amd64" {
+		t.Errorf("ServerVersion() = %+v, %v, want v1.35.0 on linux/amd64", info, err)
+	}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).PrependReactor("get", "version",
+		func(ktesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+	client := &Client{clientset: clientset, logger: zerolog.Nop()}
+	if _, err := client.ServerVersion(); err == nil {
+		t.Error("expected ServerVersion() to fail")
+	}
+}
@@ -0,0 +1,74 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements dry runs of mutating operations, like kubectl's --dry-run.
+package k8s
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// DryRun selects whether and how mutating operations are only simulated.
+type DryRun string
+
+// Dry run modes, matching kubectl's --dry-run values.
+const (
+	// DryRunNone performs mutating operations. The zero value is DryRunNone as well.
+	DryRunNone DryRun = "none"
+
+	// DryRunClient does not send mutating requests. Reads, e.g. of the object to update, still happen.
+	DryRunClient DryRun = "client"
+
+	// DryRunServer sends mutating requests with dryRun=All, so the API server validates and admits
+	// the change without persisting it.
+	DryRunServer DryRun = "server"
+)
+
+// ParseDryRun converts a --dry-run value into a dry run mode.
+func ParseDryRun(value string) (DryRun, error) {
+	switch mode := DryRun(value); mode {
+	case DryRunNone, DryRunClient, DryRunServer:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid dry run mode '%s', must be one of: none, client, server", value)
+	}
+}
+
+// Enabled reports whether mutating operations are only simulated.
+func (d DryRun) Enabled() bool {
+	return d == DryRunClient || d == DryRunServer
+}
+
+// SetDryRun sets the dry run mode of the client's mutating operations.
+// While a dry run is enabled, the client's log entries carry a dry_run field.
+func (c *Client) SetDryRun(mode DryRun) {
+	if mode.Enabled() && !c.dryRun.Enabled() {
+		c.logger = c.logger.With().Str("dry_run", string(mode)).Logger()
+	}
+	c.dryRun = mode
+}
+
+// skipMutation reports whether the request making the authorized change must not be sent,
+// in client dry runs. The skipped change is logged instead.
+func (c *Client) skipMutation(change authz.Change) bool {
+	if c.dryRun != DryRunClient {
+		return false
+	}
+	c.logger.Info().
+		Str("operation", change.Operation).
+		Str("resource", change.Resource).
+		Str("namespace", change.Namespace).
+		Str("name", change.Name).
+		Msg("Dry run, request not sent")
+	return true
+}
+
+// dryRunOption returns the dryRun option of mutating requests: All in server dry runs, none otherwise.
+func (c *Client) dryRunOption() []string {
+	if c.dryRun == DryRunServer {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests dry runs of mutating operations.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// TestParseDryRun verifies the accepted --dry-run values.
+func TestParseDryRun(t *testing.T) {
+	tests := []struct {
+		value       string
+		want        DryRun
+		wantEnabled bool
+		wantErr     bool
+	}{
+		{"none", DryRunNone, false, false},
+		{"client", DryRunClient, true, false},
+		{"server", DryRunServer, true, false},
+		{"", "", false, true},
+		{"true", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDryRun(tt.value)
+			if (err != nil) != tt.wantErr || got != tt.want || got.Enabled() != tt.wantEnabled {
+				t.Errorf("ParseDryRun(%q) = %q, %v, want %q (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestDryRunLeavesClusterUnchanged verifies that mutating operations succeed in both dry run
+// modes without changing the cluster.
+func TestDryRunLeavesClusterUnchanged(t *testing.T) {
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	manifest := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "settings"},
+		"data":       map[string]any{"mode": "fast"},
+	}}
+
+	validating, _ := testWebhookConfigs()
+
+	for _, mode := range []DryRun{DryRunClient, DryRunServer} {
+		t.Run(string(mode), func(t *testing.T) {
+			client := NewFakeClient(zerolog.Nop(), newTestNode(false), newTestPodOnNode("web-1", "ReplicaSet"),
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespaceDefault}},
+				createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx}),
+				validating.DeepCopyObject())
+			client.SetDryRun(mode)
+			ctx := context.Background()
+
+			if err := client.ScaleDeployment(ctx, testNamespaceDefault, testDeploymentNginx, 1); err != nil {
+				t.Errorf("ScaleDeployment() error = %v", err)
+			}
+			if err := client.RestartDeployment(ctx, testNamespaceDefault, testDeploymentNginx); err != nil {
+				t.Errorf("RestartDeployment() error = %v", err)
+			}
+			patched, err := client.Patch(ctx, deploymentsGVR, testNamespaceDefault, testDeploymentNginx,
+				types.StrategicMergePatchType, []byte(`{"metadata":{"labels":{"tier":"web"}}}`))
+			if err != nil || patched.GetLabels()["tier"] != "web" {
+				t.Errorf("Patch() = %v, %v, want the patched object", patched, err)
+			}
+			if err := client.CreateNamespace(ctx, "staging", nil); err != nil {
+				t.Errorf("CreateNamespace() error = %v", err)
+			}
+			if status, err := client.DeleteNamespace(ctx, testNamespaceDefault); err != nil || status.Deleted {
+				t.Errorf("DeleteNamespace() = %+v, %v, want a pending deletion", status, err)
+			}
+			if changed, err := client.CordonNode(ctx, testNodeName); err != nil || !changed {
+				t.Errorf("CordonNode() = %v, %v, want a change", changed, err)
+			}
+			result, err := client.DrainNode(ctx, testNodeName, DrainOptions{GracePeriodSeconds: -1})
+			if err != nil || len(result.Evicted) != 1 {
+				t.Errorf("DrainNode() = %+v, %v, want 1 pod evicted", result, err)
+			}
+			if applied, err := client.Apply(ctx, []*unstructured.Unstructured{manifest}, ""); err != nil ||
+				len(applied) != 1 || !applied[0].Created {
+				t.Errorf("Apply() = %+v, %v, want a created object", applied, err)
+			}
+			if err := client.Delete(ctx, deploymentsGVR, testNamespaceDefault, testDeploymentNginx); err != nil {
+				t.Errorf("Delete() error = %v", err)
+			}
+			if updated, err := client.InjectCABundle(ctx, testWebhookConfig, testCABundle); err != nil || updated != 1 {
+				t.Errorf("InjectCABundle() = %d, %v, want 1 updated configuration", updated, err)
+			}
+
+			deployment, err := client.clientset.AppsV1().Deployments(testNamespaceDefault).
+				Get(ctx, testDeploymentNginx, metav1.GetOptions{})
+			if err != nil || *deployment.Spec.Replicas != 3 || deployment.Labels["tier"] != "" ||
+				deployment.Spec.Template.Annotations[restartedAtAnnotation] != "" {
+				t.Errorf("expected the deployment unchanged, got %+v (%v)", deployment, err)
+			}
+			if _, err := client.clientset.CoreV1().Namespaces().Get(ctx, "staging", metav1.GetOptions{}); err == nil {
+				t.Error("expected namespace staging not to be created")
+			}
+			node, err := client.clientset.CoreV1().Nodes().Get(ctx, testNodeName, metav1.GetOptions{})
+			if err != nil || node.Spec.Unschedulable {
+				t.Errorf("expected the node schedulable, got %+v (%v)", node, err)
+			}
+			if _, err := client.clientset.CoreV1().Pods(testNamespaceDefault).
+				Get(ctx, "web-1", metav1.GetOptions{}); err != nil {
+				t.Errorf("expected the pod not to be evicted, got %v", err)
+			}
+			config, err := client.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().
+				Get(ctx, testWebhookConfig, metav1.GetOptions{})
+			if err != nil || len(config.Webhooks[0].ClientConfig.CABundle) != 0 {
+				t.Errorf("expected the webhook configuration unchanged, got %+v (%v)", config, err)
+			}
+		})
+	}
+}
+
+// TestDryRunReportsErrors verifies that dry runs still fail for missing objects and denied changes.
+func TestDryRunReportsErrors(t *testing.T) {
+	for _, mode := range []DryRun{DryRunClient, DryRunServer} {
+		t.Run(string(mode), func(t *testing.T) {
+			client := NewFakeClient(zerolog.Nop())
+			client.SetDryRun(mode)
+			ctx := context.Background()
+
+			if err := client.ScaleDeployment(ctx, testNamespaceDefault, "missing", 1); err == nil {
+				t.Error("expected an error scaling a missing deployment")
+			}
+			if _, err := client.DeleteNamespace(ctx, "missing"); err == nil {
+				t.Error("expected an error deleting a missing namespace")
+			}
+
+			client.SetAuthorizer(denyAuthorizer{})
+			if err := client.CreateNamespace(ctx, "staging", nil); !errors.Is(err, authz.ErrDenied) {
+				t.Errorf("expected a denied error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestPatchObject verifies local patching of the patch types.
+func TestPatchObject(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "web", "labels": map[string]any{"app": "web"}},
+	}}
+	widget := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]any{"name": "a"},
+	}}
+
+	tests := []struct {
+		name      string
+		obj       *unstructured.Unstructured
+		patchType types.PatchType
+		patch     string
+		wantLabel string
+		wantErr   bool
+	}{
+		{"strategic", deployment, types.StrategicMergePatchType, `{"metadata":{"labels":{"app":"api"}}}`, "api", false},
+		{"merge", widget, types.MergePatchType, `{"metadata":{"labels":{"app":"api"}}}`, "api", false},
+		{"json", deployment, types.JSONPatchType, `[{"op":"replace","path":"/metadata/labels/app","value":"api"}]`,
+			"api", false},
+		{"strategic custom resource", widget, types.StrategicMergePatchType, `{"metadata":{}}`, "", true},
+		{"invalid json patch", deployment, types.JSONPatchType, `{}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patchObject(tt.obj, tt.patchType, []byte(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("patchObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.GetLabels()["app"] != tt.wantLabel {
+				t.Errorf("expected label app=%s, got %v", tt.wantLabel, got.GetLabels())
+			}
+		})
+	}
+	if deployment.GetLabels()["app"] != "web" {
+		t.Error("expected the original object unchanged")
+	}
+}
@@ -0,0 +1,102 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file classifies Kubernetes API errors into typed categories with remediation hints.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Error categories returned by ClassifyError. Callers match them with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrTimeout      = errors.New("timed out")
+	ErrUnreachable  = errors.New("api server unreachable")
+)
+
+// Remediation hints of the error categories.
+var errorHints = map[error]string{
+	ErrNotFound:     "check the name and namespace, e.g. with 'kc list deployments -n NAMESPACE'",
+	ErrForbidden:    "check your RBAC permissions, e.g. with 'kc auth can-i VERB RESOURCE'",
+	ErrUnauthorized: "the credentials were rejected, refresh them or check the kubeconfig user and --context",
+	ErrTimeout:      "the API server did not respond in time, retry or raise --timeout",
+	ErrUnreachable:  "is the cluster running and accessible? check the kubeconfig server and --context",
+}
+
+// APIError is a Kubernetes API error classified into one of the error categories.
+// It matches both its category and the original error with errors.Is and errors.As.
+type APIError struct {
+	// Kind is the category, e.g. ErrNotFound.
+	Kind error
+
+	// Err is the original error.
+	Err error
+}
+
+// Error returns the message of the original error.
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the category and the original error.
+func (e *APIError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Hint returns a user-facing suggestion for resolving the error.
+func (e *APIError) Hint() string {
+	return errorHints[e.Kind]
+}
+
+// ClassifyError wraps err in an APIError if it falls into one of the error categories.
+// Other errors, nil and errors that are already classified are returned unchanged.
+func ClassifyError(err error) error {
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) {
+		return err
+	}
+	if kind := errorKind(err); kind != nil {
+		return &APIError{Kind: kind, Err: err}
+	}
+	return err
+}
+
+// errorKind returns the category of an error, or nil if it has none.
+func errorKind(err error) error {
+	switch {
+	case apierrors.IsNotFound(err):
+		return ErrNotFound
+	case apierrors.IsForbidden(err):
+		return ErrForbidden
+	case apierrors.IsUnauthorized(err):
+		return ErrUnauthorized
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, ErrCircuitOpen), isDialError(err):
+		return ErrUnreachable
+	default:
+		return nil
+	}
+}
+
+// isDialError reports whether err is a failure to connect, e.g. an unknown host or an unreachable network.
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	return errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+// ErrorHint returns the remediation hint of a classified error, or "" if it has none.
+func ErrorHint(err error) string {
+	var apiErr *APIError
+	if errors.As(ClassifyError(err), &apiErr) {
+		return apiErr.Hint()
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests the classification of Kubernetes API errors.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestClassifyError verifies that API errors are wrapped into their category and others are left alone.
+func TestClassifyError(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+	}{
+		{"not found", apierrors.NewNotFound(deployments, "web"), ErrNotFound},
+		{"wrapped not found", fmt.Errorf("failed to get: %w", apierrors.NewNotFound(deployments, "web")),
+			ErrNotFound},
+		{"forbidden", apierrors.NewForbidden(deployments, "web", errors.New("no RBAC")), ErrForbidden},
+		{"unauthorized", apierrors.NewUnauthorized("bad token"), ErrUnauthorized},
+		{"server timeout", apierrors.NewServerTimeout(deployments, "list", 1), ErrTimeout},
+		{"deadline", fmt.Errorf("list: %w", context.DeadlineExceeded), ErrTimeout},
+		{"connection refused", fmt.Errorf("get: %w", refused), ErrUnreachable},
+		{"unknown host", fmt.Errorf("get: %w", &net.DNSError{Err: "no such host", Name: "api.example.com"}),
+			ErrUnreachable},
+		{"network unreachable", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("network is unreachable")},
+			ErrUnreachable},
+		{"read error", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}, nil},
+		{"circuit open", fmt.Errorf("get: %w", ErrCircuitOpen), ErrUnreachable},
+		{"other", errors.New("boom"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if !errors.Is(got, tt.err) {
+				t.Errorf("expected the original error to be kept, got %v", got)
+			}
+			var apiErr *APIError
+			isAPIErr := errors.As(got, &apiErr)
+			if tt.wantKind == nil {
+				if isAPIErr {
+					t.Errorf("expected no classification, got %v", apiErr.Kind)
+				}
+				return
+			}
+			if !isAPIErr || !errors.Is(got, tt.wantKind) {
+				t.Fatalf("expected category %v, got %v", tt.wantKind, got)
+			}
+			if got.Error() != tt.err.Error() || apiErr.Hint() == "" || ErrorHint(tt.err) != apiErr.Hint() {
+				t.Errorf("unexpected message %q or hint %q", got.Error(), apiErr.Hint())
+			}
+			if ClassifyError(got) != got {
+				t.Error("expected a classified error to be returned unchanged")
+			}
+		})
+	}
+
+	if ClassifyError(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+}
@@ -0,0 +1,110 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements retrieval of events related to a specific object.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// ObjectReference identifies a Kubernetes object by kind, namespace and name.
+type ObjectReference struct {
+	// Kind is the object kind, e.g. "Deployment".
+	Kind string
+
+	// Namespace is empty for cluster-scoped objects.
+	Namespace string
+
+	// Name is the object name.
+	Name string
+}
+
+// EventInfo represents essential information about a Kubernetes event.
+type EventInfo struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	Source    string    `json:"source,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// GetEventsForObject returns the events whose involved object is ref, oldest first.
+// Events of cluster-scoped objects are recorded in the "default" namespace.
+func (c *Client) GetEventsForObject(ctx context.Context, ref ObjectReference) ([]EventInfo, error) {
+	selector := fields.Set{
+		"involvedObject.kind": ref.Kind,
+		"involvedObject.name": ref.Name,
+	}
+	eventNamespace := ref.Namespace
+	if eventNamespace == "" {
+		eventNamespace = metav1.NamespaceDefault
+	} else {
+		selector["involvedObject.namespace"] = ref.Namespace
+	}
+
+	list, err := c.clientset.CoreV1().Events(eventNamespace).List(ctx, metav1.ListOptions{
+		FieldSelector: selector.AsSelector().String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events for %s %q: %w", ref.Kind, ref.Name, err)
+	}
+
+	events := make([]EventInfo, 0, len(list.Items))
+	for i := range list.Items {
+		event := &list.Items[i]
+		// The selector is applied server-side; re-checking keeps results correct
+		// against API servers (and fakes) that ignore field selectors.
+		if event.InvolvedObject.Kind != ref.Kind || event.InvolvedObject.Name != ref.Name {
+			continue
+		}
+		events = append(events, newEventInfo(event))
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+	return events, nil
+}
+
+// newEventInfo converts a Kubernetes event into an EventInfo.
+// Events created through the events.k8s.io API only carry EventTime and Series,
+// so those are used when the legacy timestamps and count are unset.
+func newEventInfo(event *corev1.Event) EventInfo {
+	info := EventInfo{
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Count:     event.Count,
+		Source:    event.Source.Component,
+		FirstSeen: event.FirstTimestamp.Time,
+		LastSeen:  event.LastTimestamp.Time,
+	}
+	if info.Source == "" {
+		info.Source = event.ReportingController
+	}
+	if info.FirstSeen.IsZero() {
+		info.FirstSeen = event.EventTime.Time
+	}
+	if info.LastSeen.IsZero() {
+		info.LastSeen = event.EventTime.Time
+		if event.Series != nil {
+			info.LastSeen = event.Series.LastObservedTime.Time
+		}
+	}
+	if info.LastSeen.IsZero() {
+		info.LastSeen = event.CreationTimestamp.Time
+	}
+	if info.Count == 0 {
+		info.Count = 1
+		if event.Series != nil {
+			info.Count = event.Series.Count
+		}
+	}
+	return info
+}
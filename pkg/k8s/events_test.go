@@ -0,0 +1,98 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests retrieval of events related to a specific object.
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestEvent builds an event for the given involved object.
+func newTestEvent(name, ns, kind, object, reason string, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: ns},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: ns, Name: object},
+		Type:           corev1.EventTypeNormal,
+		Reason:         reason,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+		Count:          2,
+	}
+}
+
+// TestGetEventsForObject verifies filtering by involved object and ordering by last occurrence.
+func TestGetEventsForObject(t *testing.T) {
+	now := time.Now()
+	client := NewFakeClient(zerolog.Nop(),
+		newTestEvent("e1", testNamespaceDefault, "Deployment", "web", "ScalingReplicaSet", now.Add(-time.Minute)),
+		newTestEvent("e2", testNamespaceDefault, "Deployment", "web", "Created", now.Add(-time.Hour)),
+		newTestEvent("e3", testNamespaceDefault, "Deployment", "api", "ScalingReplicaSet", now),
+		newTestEvent("e4", testNamespaceDefault, "Pod", "web", "Pulled", now),
+	)
+
+	events, err := client.GetEventsForObject(context.Background(),
+		ObjectReference{Kind: "Deployment", Namespace: testNamespaceDefault, Name: "web"})
+	if err != nil {
+		t.Fatalf("GetEventsForObject() error = %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(events), events)
+	}
+	if events[0].Reason != "Created" || events[1].Reason != "ScalingReplicaSet" {
+		t.Errorf("expected events oldest first, got %s, %s", events[0].Reason, events[1].Reason)
+	}
+	if events[0].Count != 2 {
+		t.Errorf("expected count 2, got %d", events[0].Count)
+	}
+}
+
+// TestNewEventInfo verifies the fallbacks for events created through the events.k8s.io API.
+func TestNewEventInfo(t *testing.T) {
+	observed := time.Now().Truncate(time.Second)
+	event := &corev1.Event{
+		EventTime:           metav1.NewMicroTime(observed.Add(-time.Hour)),
+		ReportingController: "example.com/controller",
+		Series:              &corev1.EventSeries{Count: 5, LastObservedTime: metav1.NewMicroTime(observed)},
+	}
+
+	info := newEventInfo(event)
+
+	if info.Count != 5 {
+		t.Errorf("expected series count 5, got %d", info.Count)
+	}
+	if !info.LastSeen.Equal(observed) {
+		t.Errorf("expected last seen %v, got %v", observed, info.LastSeen)
+	}
+	if !info.FirstSeen.Equal(observed.Add(-time.Hour)) {
+		t.Errorf("expected first seen from event time, got %v", info.FirstSeen)
+	}
+	if info.Source != "example.com/controller" {
+		t.Errorf("expected reporting controller as source, got %q", info.Source)
+	}
+
+	if single := newEventInfo(&corev1.Event{}); single.Count != 1 {
+		t.Errorf("expected count 1 for an event without count or series, got %d", single.Count)
+	}
+}
+
+// TestGetDeployment verifies retrieval of a single deployment.
+func TestGetDeployment(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), DefaultDemoObjects(time.Now())...)
+
+	info, err := client.GetDeployment(context.Background(), "shop", "frontend")
+	if err != nil {
+		t.Fatalf("GetDeployment() error = %v", err)
+	}
+	if info.Name != "frontend" || info.Replicas.Desired != 3 {
+		t.Errorf("unexpected deployment info %+v", info)
+	}
+
+	if _, err := client.GetDeployment(context.Background(), "shop", "missing"); err == nil {
+		t.Error("expected error for missing deployment")
+	}
+}
@@ -0,0 +1,119 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements command execution inside pod containers using the SPDY executor.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// executorFactory creates a remote command executor for a request URL.
+// It matches remotecommand.NewSPDYExecutor and can be replaced in tests.
+type executorFactory func(config *rest.Config, method string, u *url.URL) (remotecommand.Executor, error)
+
+// ExecOptions holds options for executing a command in a pod.
+type ExecOptions struct {
+	// Container selects the container to execute in. May be empty for single-container pods.
+	Container string
+
+	// Command is the command and its arguments.
+	Command []string
+
+	// Stdin, if set, is streamed to the command's standard input.
+	Stdin io.Reader
+
+	// Stdout and Stderr receive the command's output. With TTY, stderr is merged into stdout.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// TTY allocates a pseudo-terminal for the command.
+	TTY bool
+
+	// TerminalSizeQueue delivers terminal resize events when TTY is set. May be nil.
+	TerminalSizeQueue remotecommand.TerminalSizeQueue
+}
+
+// ExecInPod executes a command in a pod container and streams its input and output.
+// It blocks until the command exits or ctx is cancelled. A non-zero exit status is returned as an error.
+func (c *Client) ExecInPod(ctx context.Context, ns, pod string, opts ExecOptions) error {
+	if len(opts.Command) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "exec",
+		Resource:  "pods",
+		Namespace: ns,
+		Name:      pod,
+		Details:   map[string]string{"container": opts.Container, "command": strings.Join(opts.Command, " ")},
+	}); err != nil {
+		return err
+	}
+
+	execURL, err := buildExecURL(c.config.Host, ns, pod, opts)
+	if err != nil {
+		return err
+	}
+
+	newExecutor := c.newExecutor
+	if newExecutor == nil {
+		newExecutor = remotecommand.NewSPDYExecutor
+	}
+	executor, err := newExecutor(c.config, "POST", execURL)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	c.logger.Debug().Str("pod", pod).Strs("command", opts.Command).Msg("Executing command in pod")
+	if err := executor.StreamWithContext(ctx, streamOptions(opts)); err != nil {
+		return fmt.Errorf("command in pod %q failed: %w", pod, err)
+	}
+	return nil
+}
+
+// buildExecURL builds the URL of the pods/exec subresource for the given options.
+func buildExecURL(host, ns, pod string, opts ExecOptions) (*url.URL, error) {
+	base, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API server host %q: %w", host, err)
+	}
+
+	params, err := scheme.ParameterCodec.EncodeParameters(&corev1.PodExecOptions{
+		Container: opts.Container,
+		Command:   opts.Command,
+		Stdin:     opts.Stdin != nil,
+		Stdout:    opts.Stdout != nil,
+		Stderr:    opts.Stderr != nil && !opts.TTY,
+		TTY:       opts.TTY,
+	}, corev1.SchemeGroupVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode exec options: %w", err)
+	}
+
+	return base.JoinPath("api", "v1", "namespaces", ns, "pods", pod, "exec").
+		ResolveReference(&url.URL{RawQuery: params.Encode()}), nil
+}
+
+// streamOptions converts ExecOptions into executor stream options.
+func streamOptions(opts ExecOptions) remotecommand.StreamOptions {
+	stream := remotecommand.StreamOptions{
+		Stdin:             opts.Stdin,
+		Stdout:            opts.Stdout,
+		Tty:               opts.TTY,
+		TerminalSizeQueue: opts.TerminalSizeQueue,
+	}
+	if !opts.TTY {
+		stream.Stderr = opts.Stderr
+	}
+	return stream
+}
@@ -0,0 +1,94 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests command execution in pods with a fake executor.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// fakeExecutor records the request URL and writes fixed output.
+type fakeExecutor struct {
+	output string
+}
+
+// Stream is not used; StreamWithContext is the supported entry point.
+func (f *fakeExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return f.StreamWithContext(context.Background(), opts)
+}
+
+// StreamWithContext writes the configured output to stdout.
+func (f *fakeExecutor) StreamWithContext(_ context.Context, opts remotecommand.StreamOptions) error {
+	_, err := opts.Stdout.Write([]byte(f.output))
+	return err
+}
+
+// TestBuildExecURL verifies the exec subresource URL and its query parameters.
+func TestBuildExecURL(t *testing.T) {
+	u, err := buildExecURL(fakeServerURL, testNamespaceDefault, "web-1", ExecOptions{
+		Container: "app",
+		Command:   []string{"ls", "-la"},
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	})
+	if err != nil {
+		t.Fatalf("buildExecURL() error = %v", err)
+	}
+
+	if u.Path != "/api/v1/namespaces/default/pods/web-1/exec" {
+		t.Errorf("unexpected path %s", u.Path)
+	}
+	query := u.Query()
+	if got := query["command"]; len(got) != 2 || got[0] != "ls" || got[1] != "-la" {
+		t.Errorf("unexpected command parameters %v", got)
+	}
+	if query.Get("container") != "app" || query.Get("stdout") != "true" || query.Get("stdin") != "" {
+		t.Errorf("unexpected query %s", u.RawQuery)
+	}
+}
+
+// TestExecInPod verifies execution through the executor and the authorization hook.
+func TestExecInPod(t *testing.T) {
+	var requested *url.URL
+	client := setupTestClient(zerolog.New(os.Stderr), nil, false)
+	client.newExecutor = func(_ *rest.Config, method string, u *url.URL) (remotecommand.Executor, error) {
+		if method != "POST" {
+			t.Errorf("expected POST, got %s", method)
+		}
+		requested = u
+		return &fakeExecutor{output: "hello\n"}, nil
+	}
+
+	var out bytes.Buffer
+	opts := ExecOptions{Command: []string{"echo", "hello"}, Stdout: &out}
+	if err := client.ExecInPod(context.Background(), testNamespaceDefault, "web-1", opts); err != nil {
+		t.Fatalf("ExecInPod() error = %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("expected output %q, got %q", "hello\n", out.String())
+	}
+	if requested == nil || !strings.HasSuffix(requested.Path, "/pods/web-1/exec") {
+		t.Errorf("unexpected exec URL %v", requested)
+	}
+
+	if err := client.ExecInPod(context.Background(), testNamespaceDefault, "web-1", ExecOptions{}); err == nil {
+		t.Error("expected error for empty command")
+	}
+
+	client.SetAuthorizer(denyAuthorizer{})
+	err := client.ExecInPod(context.Background(), testNamespaceDefault, "web-1", opts)
+	if !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("expected ErrDenied, got %v", err)
+	}
+}
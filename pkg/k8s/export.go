@@ -0,0 +1,58 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements exporting live objects as clean, re-applyable manifests.
+package k8s
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// serverMetadataFields are metadata fields populated by the API server that change on every write.
+var serverMetadataFields = []string{
+	"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink",
+}
+
+// serverAnnotations are annotations written by clients and controllers rather than by the object's author.
+var serverAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+}
+
+// ExportResource fetches the named object and returns it as a manifest that can be applied again.
+// See CleanForExport for the fields that are removed.
+func (c *Client) ExportResource(ctx context.Context, gvr schema.GroupVersionResource,
+	ns, name string) (*unstructured.Unstructured, error) {
+	obj, err := c.GetUnstructured(ctx, gvr, ns, name)
+	if err != nil {
+		return nil, err
+	}
+	return CleanForExport(obj), nil
+}
+
+// CleanForExport returns a copy of the object without status, server-populated metadata
+// (uid, resourceVersion, generation, creationTimestamp, managedFields) and client bookkeeping annotations.
+func CleanForExport(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	clean := obj.DeepCopy()
+	unstructured.RemoveNestedField(clean.Object, "status")
+	for _, field := range serverMetadataFields {
+		unstructured.RemoveNestedField(clean.Object, "metadata", field)
+	}
+	// Typed objects serialize an unset pod template creationTimestamp as null.
+	if ts, found, _ := unstructured.NestedFieldNoCopy(clean.Object, "spec", "template", "metadata",
+		"creationTimestamp"); found && ts == nil {
+		unstructured.RemoveNestedField(clean.Object, "spec", "template", "metadata", "creationTimestamp")
+	}
+
+	annotations := clean.GetAnnotations()
+	for _, key := range serverAnnotations {
+		delete(annotations, key)
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(clean.Object, "metadata", "annotations")
+	} else {
+		clean.SetAnnotations(annotations)
+	}
+	return clean
+}
@@ -0,0 +1,93 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests exporting live objects as clean manifests.
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestCleanForExport verifies that server-populated fields and bookkeeping annotations are removed.
+func TestCleanForExport(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":              testDeploymentNginx,
+			"namespace":         testNamespaceDefault,
+			"uid":               "1234",
+			"resourceVersion":   "42",
+			"generation":        int64(3),
+			"creationTimestamp": "2026-01-01T00:00:00Z",
+			"managedFields":     []any{map[string]any{"manager": "kubectl"}},
+			"labels":            map[string]any{"app": "nginx"},
+			"annotations": map[string]any{
+				"deployment.kubernetes.io/revision":                "3",
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+		},
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"template": map[string]any{"metadata": map[string]any{"creationTimestamp": nil}},
+		},
+		"status": map[string]any{"readyReplicas": int64(2)},
+	}}
+
+	clean := CleanForExport(obj)
+
+	for _, path := range [][]string{
+		{"status"}, {"metadata", "uid"}, {"metadata", "resourceVersion"}, {"metadata", "generation"},
+		{"metadata", "creationTimestamp"}, {"metadata", "managedFields"}, {"metadata", "annotations"},
+		{"spec", "template", "metadata", "creationTimestamp"},
+	} {
+		if _, found, _ := unstructured.NestedFieldNoCopy(clean.Object, path...); found {
+			t.Errorf("expected %v to be removed", path)
+		}
+	}
+	if clean.GetLabels()["app"] != "nginx" || clean.GetName() != testDeploymentNginx {
+		t.Errorf("expected name and labels to be kept, got %v", clean.Object["metadata"])
+	}
+	if replicas, _, _ := unstructured.NestedInt64(clean.Object, "spec", "replicas"); replicas != 2 {
+		t.Errorf("expected spec to be kept, got %v", clean.Object["spec"])
+	}
+	if _, found := obj.Object["status"]; !found {
+		t.Error("expected the original object to be unchanged")
+	}
+}
+
+// TestCleanForExportKeepsAnnotations verifies that user annotations survive cleaning.
+func TestCleanForExportKeepsAnnotations(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAnnotations(map[string]string{
+		"team":                              "web",
+		"deployment.kubernetes.io/revision": "1",
+	})
+
+	annotations := CleanForExport(obj).GetAnnotations()
+	if len(annotations) != 1 || annotations["team"] != "web" {
+		t.Errorf("expected only the user annotation, got %v", annotations)
+	}
+}
+
+// TestExportResource verifies that exported objects come from the cluster without status.
+func TestExportResource(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(),
+		createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx}))
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	obj, err := client.ExportResource(context.Background(), gvr, testNamespaceDefault, testDeploymentNginx)
+	if err != nil {
+		t.Fatalf("ExportResource() error = %v", err)
+	}
+	if obj.GetName() != testDeploymentNginx || obj.Object["status"] != nil {
+		t.Errorf("unexpected export %v", obj.Object)
+	}
+
+	if _, err := client.ExportResource(context.Background(), gvr, testNamespaceDefault, "missing"); err == nil {
+		t.Error("expected an error for a missing object")
+	}
+}
@@ -0,0 +1,153 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements resolution of the identity the client authenticates as.
+package k8s
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Sources an Identity can be resolved from.
+const (
+	IdentitySourceSelfSubjectReview = "SelfSubjectReview"
+	IdentitySourceTokenReview       = "TokenReview"
+	IdentitySourceCertificate       = "ClientCertificate"
+)
+
+// ErrTokenNotAuthenticated is returned, wrapped, for bearer tokens the API server does not authenticate.
+var ErrTokenNotAuthenticated = errors.New("token not authenticated")
+
+// Identity is the user the client is authenticated as.
+type Identity struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+
+	// Server is the API server the identity was resolved against.
+	Server string `json:"server"`
+
+	// Source is how the identity was resolved, one of the IdentitySource constants.
+	Source string `json:"source"`
+}
+
+// WhoAmI resolves the identity the client is authenticated as. It asks the API server with a
+// SelfSubjectReview (Kubernetes 1.28+). Older servers fall back to a TokenReview of the
+// configured bearer token, or to the subject of the configured client certificate.
+func (c *Client) WhoAmI(ctx context.Context) (Identity, error) {
+	identity, err := c.selfSubjectReview(ctx)
+	if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+		c.logger.Debug().Err(err).Msg("SelfSubjectReview not available, falling back")
+		identity, err = c.identityFromCredentials(ctx)
+	}
+	if err != nil {
+		return Identity{}, err
+	}
+
+	identity.Server = c.config.Host
+	c.logger.Debug().Str("username", identity.Username).Str("source", identity.Source).Msg("Identity resolved")
+	return identity, nil
+}
+
+// selfSubjectReview asks the API server who the client is authenticated as.
+func (c *Client) selfSubjectReview(ctx context.Context) (Identity, error) {
+	review, err := c.clientset.AuthenticationV1().SelfSubjectReviews().
+		Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to review self subject: %w", err)
+	}
+	return identityFromUserInfo(review.Status.UserInfo, IdentitySourceSelfSubjectReview), nil
+}
+
+// identityFromCredentials resolves the identity from the bearer token or client certificate of the config.
+func (c *Client) identityFromCredentials(ctx context.Context) (Identity, error) {
+	token, err := bearerToken(c.config.BearerToken, c.config.BearerTokenFile)
+	if err != nil {
+		return Identity{}, err
+	}
+	if token != "" {
+		return c.ReviewToken(ctx, token, nil)
+	}
+
+	certData := c.config.CertData
+	if len(certData) == 0 && c.config.CertFile != "" {
+		if certData, err = os.ReadFile(c.config.CertFile); err != nil {
+			return Identity{}, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+	}
+	if len(certData) > 0 {
+		return identityFromCertificate(certData)
+	}
+	return Identity{}, errors.New("unable to determine identity: server does not support SelfSubjectReview " +
+		"and no bearer token or client certificate is configured")
+}
+
+// ReviewToken asks the API server who a bearer token authenticates as, e.g. to authenticate the
+// ServiceAccount tokens of other clients. If audiences are given, the token must be issued for one
+// of them. It returns ErrTokenNotAuthenticated, wrapped, if the token is invalid.
+func (c *Client) ReviewToken(ctx context.Context, token string, audiences []string) (Identity, error) {
+	review, err := c.clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: audiences},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return Identity{}, fmt.Errorf("%w: %s", ErrTokenNotAuthenticated, review.Status.Error)
+		}
+		return Identity{}, ErrTokenNotAuthenticated
+	}
+	return identityFromUserInfo(review.Status.User, IdentitySourceTokenReview), nil
+}
+
+// bearerToken returns the configured bearer token, reading it from tokenFile if no token is set.
+func bearerToken(token, tokenFile string) (string, error) {
+	if token != "" || tokenFile == "" {
+		return token, nil
+	}
+	data, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bearer token file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// identityFromCertificate derives the identity from a PEM client certificate the way the
+// API server does: the common name is the username and the organizations are the groups.
+func identityFromCertificate(data []byte) (Identity, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Identity{}, errors.New("failed to parse client certificate: no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+
+	groups := append([]string(nil), cert.Subject.Organization...)
+	sort.Strings(groups)
+	return Identity{Username: cert.Subject.CommonName, Groups: groups, Source: IdentitySourceCertificate}, nil
+}
+
+// identityFromUserInfo converts authentication user info into an Identity.
+func identityFromUserInfo(info authenticationv1.UserInfo, source string) Identity {
+	var extra map[string][]string
+	if len(info.Extra) > 0 {
+		extra = make(map[string][]string, len(info.Extra))
+		for key, values := range info.Extra {
+			extra[key] = values
+		}
+	}
+	return Identity{Username: info.Username, UID: info.UID, Groups: info.Groups, Extra: extra, Source: source}
+}
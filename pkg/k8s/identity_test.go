@@ -0,0 +1,151 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests identity resolution.
+package k8s
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Identity test constants.
+const (
+	testUsername = "jane"
+	testToken    = "s3cr3t"
+)
+
+// testClientCertificate creates a PEM client certificate for the given user and groups.
+func testClientCertificate(t *testing.T, user string, groups ...string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: user, Organization: groups},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// newIdentityTestClient creates a client whose server supports SelfSubjectReview only if selfSubjectReview is
+// set, and whose TokenReviews authenticate testToken as testUsername.
+func newIdentityTestClient(selfSubjectReview bool) *Client {
+	client := NewFakeClient(zerolog.Nop())
+	clientset := client.clientset.(*fake.Clientset)
+	clientset.PrependReactor("create", "selfsubjectreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if !selfSubjectReview {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "selfsubjectreviews"}, "")
+		}
+		return true, &authenticationv1.SelfSubjectReview{Status: authenticationv1.SelfSubjectReviewStatus{
+			UserInfo: authenticationv1.UserInfo{Username: testUsername, Groups: []string{"system:authenticated"}},
+		}}, nil
+	})
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == testToken {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: testUsername}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+// TestWhoAmI verifies SelfSubjectReview and the token and certificate fallbacks.
+func TestWhoAmI(t *testing.T) {
+	tests := []struct {
+		name              string
+		selfSubjectReview bool
+		token             string
+		cert              []byte
+		wantUser          string
+		wantSource        string
+		wantGroups        []string
+		wantErr           bool
+	}{
+		{"self subject review", true, "", nil, testUsername, IdentitySourceSelfSubjectReview,
+			[]string{"system:authenticated"}, false},
+		{"token review", false, testToken, nil, testUsername, IdentitySourceTokenReview, nil, false},
+		{"rejected token", false, "wrong", nil, "", "", nil, true},
+		{"client certificate", false, "", testClientCertificate(t, "admin", "system:masters", "devs"),
+			"admin", IdentitySourceCertificate, []string{"devs", "system:masters"}, false},
+		{"no credentials", false, "", nil, "", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newIdentityTestClient(tt.selfSubjectReview)
+			client.config.BearerToken = tt.token
+			client.config.CertData = tt.cert
+
+			identity, err := client.WhoAmI(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WhoAmI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if identity.Username != tt.wantUser || identity.Source != tt.wantSource ||
+				!slices.Equal(identity.Groups, tt.wantGroups) {
+				t.Errorf("unexpected identity %+v", identity)
+			}
+			if identity.Server != DemoHost {
+				t.Errorf("expected server %s, got %s", DemoHost, identity.Server)
+			}
+		})
+	}
+}
+
+// TestIdentityFromCertificateInvalid verifies that non-certificate data is rejected.
+func TestIdentityFromCertificateInvalid(t *testing.T) {
+	if _, err := identityFromCertificate([]byte("not a certificate")); err == nil {
+		t.Error("expected an error for invalid certificate data")
+	}
+}
+
+// TestReviewToken verifies that tokens of other clients are reviewed with the requested audiences.
+func TestReviewToken(t *testing.T) {
+	client := newIdentityTestClient(true)
+	var audiences []string
+	client.clientset.(*fake.Clientset).PrependReactor("create", "tokenreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			audiences = action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview).Spec.Audiences
+			return false, nil, nil
+		})
+
+	identity, err := client.ReviewToken(context.Background(), testToken, []string{"k8s-controller"})
+	if err != nil || identity.Username != testUsername || identity.Source != IdentitySourceTokenReview {
+		t.Errorf("ReviewToken() = %+v, %v, want %s", identity, err, testUsername)
+	}
+	if !slices.Equal(audiences, []string{"k8s-controller"}) {
+		t.Errorf("expected the audiences in the review, got %v", audiences)
+	}
+
+	if _, err := client.ReviewToken(context.Background(), "guess", nil); !errors.Is(err, ErrTokenNotAuthenticated) {
+		t.Errorf("expected ErrTokenNotAuthenticated for an invalid token, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements enumerating the contexts of the kubeconfig and switching between them.
+package k8s
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ContextInfo describes a context of the kubeconfig.
+type ContextInfo struct {
+	Name      string `json:"name" yaml:"name"`
+	Cluster   string `json:"cluster" yaml:"cluster"`
+	User      string `json:"user" yaml:"user"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Current is set for the current context of the kubeconfig.
+	Current bool `json:"current" yaml:"current"`
+}
+
+// loadKubeconfigFile loads and merges the kubeconfig at path, or the files in $KUBECONFIG
+// or ~/.kube/config if path is empty.
+func loadKubeconfigFile(path string) (*clientcmdapi.Config, error) {
+	rawConfig, err := kubeconfigLoadingRules(path).Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return rawConfig, nil
+}
+
+// KubeconfigPath returns the kubeconfig used for path: path itself if it is set, otherwise
+// $KUBECONFIG or ~/.kube/config.
+func KubeconfigPath(path string) string {
+	if path != "" {
+		return path
+	}
+	if env := os.Getenv(clientcmd.RecommendedConfigPathEnvVar); env != "" {
+		return env
+	}
+	return clientcmd.RecommendedHomeFile
+}
+
+// ListContexts returns the contexts of the kubeconfig at path, sorted by name.
+// An empty path uses $KUBECONFIG or ~/.kube/config, and path lists are merged.
+func ListContexts(path string) ([]ContextInfo, error) {
+	rawConfig, err := loadKubeconfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	contexts := make([]ContextInfo, 0, len(rawConfig.Contexts))
+	for name, context := range rawConfig.Contexts {
+		contexts = append(contexts, ContextInfo{
+			Name:      name,
+			Cluster:   context.Cluster,
+			User:      context.AuthInfo,
+			Namespace: context.Namespace,
+			Current:   name == rawConfig.CurrentContext,
+		})
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+	return contexts, nil
+}
+
+// KubeconfigContexts returns the sorted context names of the kubeconfig at path,
+// or of the files in $KUBECONFIG or ~/.kube/config if path is empty. Path lists are merged.
+func KubeconfigContexts(path string) ([]string, error) {
+	contexts, err := ListContexts(path)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(contexts))
+	for i, context := range contexts {
+		names[i] = context.Name
+	}
+	return names, nil
+}
+
+// CurrentContext returns the current context of the kubeconfig at path, or "" if none is set.
+func CurrentContext(path string) (string, error) {
+	rawConfig, err := loadKubeconfigFile(path)
+	if err != nil {
+		return "", err
+	}
+	return rawConfig.CurrentContext, nil
+}
+
+// ContextNamespace returns the namespace set by the named context of the kubeconfig at path,
+// or by the current context if name is empty. It returns "" if the context sets none.
+func ContextNamespace(path, name string) (string, error) {
+	rawConfig, err := loadKubeconfigFile(path)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = rawConfig.CurrentContext
+	}
+	if context, ok := rawConfig.Contexts[name]; ok {
+		return context.Namespace, nil
+	}
+	return "", nil
+}
+
+// SwitchContext makes name the current context of the kubeconfig at path. Like kubectl,
+// it writes current-context to the file that sets it, or to the first file of a path list.
+func SwitchContext(path, name string) error {
+	loadingRules := kubeconfigLoadingRules(path)
+	rawConfig, err := loadingRules.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if _, ok := rawConfig.Contexts[name]; !ok {
+		return fmt.Errorf("context %q not found in kubeconfig", name)
+	}
+
+	rawConfig.CurrentContext = name
+	if err := clientcmd.ModifyConfig(loadingRules, *rawConfig, false); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return nil
+}
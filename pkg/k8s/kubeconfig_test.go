@@ -0,0 +1,142 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests enumerating and switching the contexts of the kubeconfig.
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// testStagingContext adds a second context with a namespace to testKubeconfig.
+const testStagingContext = `contexts:
+- name: staging
+  context:
+    cluster: test
+    user: admin
+    namespace: web
+`
+
+// writeTestKubeconfig writes testKubeconfig with an additional staging context and returns its path.
+func writeTestKubeconfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	kubeconfig := strings.Replace(testKubeconfig, "contexts:\n", testStagingContext, 1)
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestKubeconfigPath verifies that an explicit path takes precedence over $KUBECONFIG and the default.
+func TestKubeconfigPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		env  string
+		want string
+	}{
+		{"explicit", "/tmp/a", "/tmp/b", "/tmp/a"},
+		{"environment", "", "/tmp/b", "/tmp/b"},
+		{"default", "", "", clientcmd.RecommendedHomeFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", tt.env)
+			if got := KubeconfigPath(tt.path); got != tt.want {
+				t.Errorf("KubeconfigPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestListContexts verifies that contexts are returned sorted, with the current one marked.
+func TestListContexts(t *testing.T) {
+	contexts, err := ListContexts(writeTestKubeconfig(t))
+	if err != nil {
+		t.Fatalf("ListContexts() error = %v", err)
+	}
+	want := []ContextInfo{
+		{Name: "staging", Cluster: "test", User: "admin", Namespace: "web"},
+		{Name: "test", Cluster: "test", User: "admin", Current: true},
+	}
+	if len(contexts) != len(want) {
+		t.Fatalf("expected %d contexts, got %+v", len(want), contexts)
+	}
+	for i := range want {
+		if contexts[i] != want[i] {
+			t.Errorf("context %d: expected %+v, got %+v", i, want[i], contexts[i])
+		}
+	}
+}
+
+// TestContextNamespace verifies reading the namespace of the current and of a named context.
+func TestContextNamespace(t *testing.T) {
+	path := writeTestKubeconfig(t)
+	tests := []struct {
+		context string
+		want    string
+	}{
+		{"", ""},
+		{"staging", "web"},
+		{"missing", ""},
+	}
+
+	for _, tt := range tests {
+		ns, err := ContextNamespace(path, tt.context)
+		if err != nil {
+			t.Fatalf("ContextNamespace(%q) error = %v", tt.context, err)
+		}
+		if ns != tt.want {
+			t.Errorf("ContextNamespace(%q) = %q, want %q", tt.context, ns, tt.want)
+		}
+	}
+}
+
+// TestKubeconfigContexts verifies that the context names are returned sorted.
+func TestKubeconfigContexts(t *testing.T) {
+	contexts, err := KubeconfigContexts(writeTestKubeconfig(t))
+	if err != nil {
+		t.Fatalf("KubeconfigContexts() error = %v", err)
+	}
+	if strings.Join(contexts, ",") != "staging,test" {
+		t.Errorf("expected contexts [staging test], got %v", contexts)
+	}
+
+	if _, err := KubeconfigContexts(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing kubeconfig")
+	}
+}
+
+// TestSwitchContext verifies that the current context is written back and unknown contexts are rejected.
+func TestSwitchContext(t *testing.T) {
+	path := writeTestKubeconfig(t)
+
+	if err := SwitchContext(path, "staging"); err != nil {
+		t.Fatalf("SwitchContext() error = %v", err)
+	}
+	current, err := CurrentContext(path)
+	if err != nil {
+		t.Fatalf("CurrentContext() error = %v", err)
+	}
+	if current != "staging" {
+		t.Errorf("expected current context staging, got %q", current)
+	}
+
+	// The rest of the kubeconfig is preserved.
+	contexts, err := KubeconfigContexts(path)
+	if err != nil || strings.Join(contexts, ",") != "staging,test" {
+		t.Errorf("expected contexts [staging test] after switching, got %v (%v)", contexts, err)
+	}
+
+	if err := SwitchContext(path, "missing"); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+	if current, _ := CurrentContext(path); current != "staging" {
+		t.Errorf("expected current context to stay staging, got %q", current)
+	}
+}
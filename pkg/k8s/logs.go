@@ -0,0 +1,114 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements pod log retrieval and streaming.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogOptions holds options for retrieving pod logs.
+type LogOptions struct {
+	// Container selects the container to read logs from.
+	// May be empty for single-container pods.
+	Container string
+
+	// TailLines limits the output to the last N lines. Negative values return all lines.
+	TailLines int64
+
+	// Since returns only logs newer than the given duration. Zero returns all logs.
+	Since time.Duration
+
+	// Follow keeps the stream open and delivers new log lines as they are written.
+	Follow bool
+
+	// Previous returns logs of the previous terminated container instance.
+	Previous bool
+}
+
+// toPodLogOptions converts LogOptions into the API request options.
+func (o LogOptions) toPodLogOptions() *corev1.PodLogOptions {
+	podOpts := &corev1.PodLogOptions{
+		Container: o.Container,
+		Follow:    o.Follow,
+		Previous:  o.Previous,
+	}
+	if o.TailLines >= 0 {
+		tail := o.TailLines
+		podOpts.TailLines = &tail
+	}
+	if o.Since > 0 {
+		seconds := int64(o.Since.Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		podOpts.SinceSeconds = &seconds
+	}
+	return podOpts
+}
+
+// GetPodLogs returns the logs of a pod container as a string.
+// The Follow option is ignored; use StreamPodLogs to follow logs.
+func (c *Client) GetPodLogs(ctx context.Context, ns, pod string, opts LogOptions) (string, error) {
+	opts.Follow = false
+
+	data, err := c.clientset.CoreV1().Pods(ns).GetLogs(pod, opts.toPodLogOptions()).DoRaw(ctx)
+	if err != nil {
+		c.logger.Error().Err(err).Str("pod", pod).Msg("Failed to get pod logs")
+		return "", fmt.Errorf("failed to get logs of pod %q: %w", pod, err)
+	}
+	return string(data), nil
+}
+
+// StreamPodLogs copies the logs of a pod container to w.
+// With Follow set, it blocks until the container terminates or ctx is cancelled.
+func (c *Client) StreamPodLogs(ctx context.Context, ns, pod string, opts LogOptions, w io.Writer) error {
+	c.logger.Debug().
+		Str("namespace", ns).
+		Str("pod", pod).
+		Str("container", opts.Container).
+		Bool("follow", opts.Follow).
+		Msg("Streaming pod logs")
+
+	stream, err := c.clientset.CoreV1().Pods(ns).GetLogs(pod, opts.toPodLogOptions()).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open log stream of pod %q: %w", pod, err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	if _, err := io.Copy(w, stream); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read log stream of pod %q: %w", pod, err)
+	}
+	return nil
+}
+
+// ListPodsForDeployment returns the names of the pods selected by a deployment's label selector, sorted.
+func (c *Client) ListPodsForDeployment(ctx context.Context, ns, name string) ([]string, error) {
+	deployment, err := c.clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment %q: %w", name, err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %q: %w", name, err)
+	}
+
+	pods, err := c.clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of deployment %q: %w", name, err)
+	}
+
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
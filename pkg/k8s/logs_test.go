@@ -0,0 +1,91 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests pod log retrieval and pod resolution for deployments.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fakeLogs is the log content returned by the fake clientset for every pod.
+const fakeLogs = "fake logs"
+
+// newTestPod creates a pod with the given labels for testing purposes.
+func newTestPod(name, namespace string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+}
+
+// TestToPodLogOptions verifies conversion of log options into API options.
+func TestToPodLogOptions(t *testing.T) {
+	all := LogOptions{TailLines: -1}.toPodLogOptions()
+	if all.TailLines != nil || all.SinceSeconds != nil {
+		t.Errorf("expected no tail or since limits, got %+v", all)
+	}
+
+	limited := LogOptions{Container: "app", TailLines: 10, Since: 5 * time.Minute, Previous: true}.toPodLogOptions()
+	if limited.TailLines == nil || *limited.TailLines != 10 {
+		t.Errorf("expected tail 10, got %v", limited.TailLines)
+	}
+	if limited.SinceSeconds == nil || *limited.SinceSeconds != 300 {
+		t.Errorf("expected since 300s, got %v", limited.SinceSeconds)
+	}
+	if limited.Container != "app" || !limited.Previous {
+		t.Errorf("expected container and previous to be set, got %+v", limited)
+	}
+}
+
+// TestGetAndStreamPodLogs verifies log retrieval through both APIs.
+func TestGetAndStreamPodLogs(t *testing.T) {
+	pod := newTestPod("web-1", testNamespaceDefault, nil)
+	client := setupTestClient(zerolog.New(os.Stderr), []runtime.Object{pod}, false)
+	ctx := context.Background()
+
+	logs, err := client.GetPodLogs(ctx, testNamespaceDefault, "web-1", LogOptions{TailLines: -1})
+	if err != nil {
+		t.Fatalf("GetPodLogs() error = %v", err)
+	}
+	if logs != fakeLogs {
+		t.Errorf("expected %q, got %q", fakeLogs, logs)
+	}
+
+	var buf bytes.Buffer
+	if err := client.StreamPodLogs(ctx, testNamespaceDefault, "web-1", LogOptions{TailLines: -1}, &buf); err != nil {
+		t.Fatalf("StreamPodLogs() error = %v", err)
+	}
+	if buf.String() != fakeLogs {
+		t.Errorf("expected %q, got %q", fakeLogs, buf.String())
+	}
+}
+
+// TestListPodsForDeployment verifies that pods are resolved by the deployment selector.
+func TestListPodsForDeployment(t *testing.T) {
+	deployment := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 2, []string{testImageNginx})
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}}
+	objects := []runtime.Object{
+		deployment,
+		newTestPod("nginx-b", testNamespaceDefault, map[string]string{"app": "nginx"}),
+		newTestPod("nginx-a", testNamespaceDefault, map[string]string{"app": "nginx"}),
+		newTestPod("redis-a", testNamespaceDefault, map[string]string{"app": "redis"}),
+	}
+	client := setupTestClient(zerolog.New(os.Stderr), objects, false)
+
+	pods, err := client.ListPodsForDeployment(context.Background(), testNamespaceDefault, testDeploymentNginx)
+	if err != nil {
+		t.Fatalf("ListPodsForDeployment() error = %v", err)
+	}
+	if len(pods) != 2 || pods[0] != "nginx-a" || pods[1] != "nginx-b" {
+		t.Errorf("expected [nginx-a nginx-b], got %v", pods)
+	}
+
+	if _, err := client.ListPodsForDeployment(context.Background(), testNamespaceDefault, "missing"); err == nil {
+		t.Error("expected error for missing deployment")
+	}
+}
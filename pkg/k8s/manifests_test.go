@@ -0,0 +1,53 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests reading manifests from files and directories.
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadManifests verifies that files are read directly and directories in file name order.
+func TestReadManifests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: second\n",
+		"a.json":    `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"first"}}`,
+		"notes.txt": "not a manifest",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		wantNames []string
+		wantErr   bool
+	}{
+		{"directory", dir, []string{"first", "second"}, false},
+		{"file", filepath.Join(dir, "b.yaml"), []string{"second"}, false},
+		{"invalid file", filepath.Join(dir, "notes.txt"), nil, true},
+		{"missing", filepath.Join(dir, "missing.yaml"), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := ReadManifests(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadManifests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, obj := range objects {
+				names = append(names, obj.GetName())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("got objects %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements pod and node resource usage retrieval via the metrics.k8s.io API.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// Sort keys for resource usage.
+const (
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+	SortByName   = "name"
+)
+
+// PodUsage represents the current CPU and memory usage of a pod, summed over its containers.
+type PodUsage struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	CPUMilli    int64  `json:"cpu_millicores"`
+	MemoryBytes int64  `json:"memory_bytes"`
+}
+
+// NodeUsage represents the current CPU and memory usage of a node.
+// Percentages are relative to the node's allocatable resources and zero if unknown.
+type NodeUsage struct {
+	Name          string  `json:"name"`
+	CPUMilli      int64   `json:"cpu_millicores"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryBytes   int64   `json:"memory_bytes"`
+	MemoryPercent float64 `json:"memory_percent"`
+}
+
+// ListPodUsage returns the resource usage of pods in a namespace (all namespaces if empty)
+// matching the label selector.
+func (c *Client) ListPodUsage(ctx context.Context, ns, selector string) ([]PodUsage, error) {
+	list, err := c.metrics.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+
+	usage := make([]PodUsage, 0, len(list.Items))
+	for _, pod := range list.Items {
+		info := PodUsage{Namespace: pod.Namespace, Name: pod.Name}
+		for _, container := range pod.Containers {
+			info.CPUMilli += container.Usage.Cpu().MilliValue()
+			info.MemoryBytes += container.Usage.Memory().Value()
+		}
+		usage = append(usage, info)
+	}
+	return usage, nil
+}
+
+// ListNodeUsage returns the resource usage of nodes matching the label selector.
+func (c *Client) ListNodeUsage(ctx context.Context, selector string) ([]NodeUsage, error) {
+	opts := metav1.ListOptions{LabelSelector: selector}
+	list, err := c.metrics.MetricsV1beta1().NodeMetricses().List(ctx, opts)
+	if err != nil {
+		return nil, metricsError(err)
+	}
+
+	nodes, err := c.clientset.CoreV1().Nodes().List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	allocatable := make(map[string]corev1.ResourceList, len(nodes.Items))
+	for _, node := range nodes.Items {
+		allocatable[node.Name] = node.Status.Allocatable
+	}
+
+	usage := make([]NodeUsage, 0, len(list.Items))
+	for _, node := range list.Items {
+		info := NodeUsage{
+			Name:        node.Name,
+			CPUMilli:    node.Usage.Cpu().MilliValue(),
+			MemoryBytes: node.Usage.Memory().Value(),
+		}
+		if resources, ok := allocatable[node.Name]; ok {
+			info.CPUPercent = percent(info.CPUMilli, resources.Cpu().MilliValue())
+			info.MemoryPercent = percent(info.MemoryBytes, resources.Memory().Value())
+		}
+		usage = append(usage, info)
+	}
+	return usage, nil
+}
+
+// SortPodUsage sorts pods by the given key; CPU and memory sort descending, name ascending.
+func SortPodUsage(pods []PodUsage, by string) error {
+	less, err := usageLess(by,
+		func(i int) (int64, int64, string) {
+			return pods[i].CPUMilli, pods[i].MemoryBytes, pods[i].Namespace + "/" + pods[i].Name
+		})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(pods, less)
+	return nil
+}
+
+// SortNodeUsage sorts nodes by the given key; CPU and memory sort descending, name ascending.
+func SortNodeUsage(nodes []NodeUsage, by string) error {
+	less, err := usageLess(by,
+		func(i int) (int64, int64, string) {
+			return nodes[i].CPUMilli, nodes[i].MemoryBytes, nodes[i].Name
+		})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(nodes, less)
+	return nil
+}
+
+// usageLess builds a sort.SliceStable less function for the given sort key.
+// The key function returns CPU, memory and name of the element at index i.
+func usageLess(by string, key func(i int) (int64, int64, string)) (func(i, j int) bool, error) {
+	switch by {
+	case SortByCPU:
+		return func(i, j int) bool {
+			ci, _, _ := key(i)
+			cj, _, _ := key(j)
+			return ci > cj
+		}, nil
+	case SortByMemory:
+		return func(i, j int) bool {
+			_, mi, _ := key(i)
+			_, mj, _ := key(j)
+			return mi > mj
+		}, nil
+	case SortByName, "":
+		return func(i, j int) bool {
+			_, _, ni := key(i)
+			_, _, nj := key(j)
+			return ni < nj
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sort key '%s', must be one of: cpu, memory, name", by)
+	}
+}
+
+// percent returns used as a percentage of total, or zero if total is unknown.
+func percent(used, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(used) * 100 / float64(total)
+}
+
+// metricsError wraps metrics API errors, explaining the common case of a missing metrics-server.
+func metricsError(err error) error {
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("metrics API not available - is metrics-server installed? %w", err)
+	}
+	return fmt.Errorf("failed to query metrics API: %w", err)
+}
+
+// isMetricsObject reports whether an object belongs to the metrics.k8s.io API.
+func isMetricsObject(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *metricsv1beta1.PodMetrics, *metricsv1beta1.NodeMetrics:
+		return true
+	default:
+		return false
+	}
+}
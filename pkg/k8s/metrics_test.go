@@ -0,0 +1,140 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests pod and node resource usage retrieval and sorting.
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// usage builds a resource list with the given CPU and memory quantities.
+func usage(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+// newTestPodMetrics builds pod metrics with one container per usage entry.
+func newTestPodMetrics(name, ns string, labels map[string]string,
+	containers ...corev1.ResourceList) *metricsv1beta1.PodMetrics {
+	metrics := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+	}
+	for i, container := range containers {
+		metrics.Containers = append(metrics.Containers, metricsv1beta1.ContainerMetrics{
+			Name:  string(rune('a' + i)),
+			Usage: container,
+		})
+	}
+	return metrics
+}
+
+// TestListPodUsage verifies summing container usage and filtering by namespace and selector.
+func TestListPodUsage(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(),
+		newTestPodMetrics("web", testNamespaceDefault, map[string]string{"app": "web"},
+			usage("100m", "64Mi"), usage("50m", "32Mi")),
+		newTestPodMetrics("api", testNamespaceDefault, map[string]string{"app": "api"}, usage("300m", "16Mi")),
+		newTestPodMetrics("dns", "kube-system", nil, usage("5m", "8Mi")),
+	)
+
+	tests := []struct {
+		name     string
+		ns       string
+		selector string
+		want     int
+	}{
+		{"all namespaces", "", "", 3},
+		{"single namespace", testNamespaceDefault, "", 2},
+		{"selector", "", "app=web", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods, err := client.ListPodUsage(context.Background(), tt.ns, tt.selector)
+			if err != nil {
+				t.Fatalf("ListPodUsage() error = %v", err)
+			}
+			if len(pods) != tt.want {
+				t.Fatalf("expected %d pods, got %d: %+v", tt.want, len(pods), pods)
+			}
+		})
+	}
+
+	pods, err := client.ListPodUsage(context.Background(), "", "app=web")
+	if err != nil {
+		t.Fatalf("ListPodUsage() error = %v", err)
+	}
+	if pods[0].CPUMilli != 150 || pods[0].MemoryBytes != 96*1024*1024 {
+		t.Errorf("expected summed usage 150m/96Mi, got %dm/%d", pods[0].CPUMilli, pods[0].MemoryBytes)
+	}
+}
+
+// TestListNodeUsage verifies usage percentages relative to node allocatable resources.
+func TestListNodeUsage(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(),
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+			Status:     corev1.NodeStatus{Allocatable: usage("2", "4Gi")},
+		},
+		&metricsv1beta1.NodeMetrics{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}, Usage: usage("500m", "1Gi")},
+		&metricsv1beta1.NodeMetrics{ObjectMeta: metav1.ObjectMeta{Name: "gone"}, Usage: usage("100m", "1Gi")},
+	)
+
+	nodes, err := client.ListNodeUsage(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListNodeUsage() error = %v", err)
+	}
+	if err := SortNodeUsage(nodes, SortByName); err != nil {
+		t.Fatalf("SortNodeUsage() error = %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(nodes))
+	}
+	if nodes[0].Name != "gone" || nodes[0].CPUPercent != 0 {
+		t.Errorf("expected zero percent for node without allocatable, got %+v", nodes[0])
+	}
+	if nodes[1].CPUPercent != 25 || nodes[1].MemoryPercent != 25 {
+		t.Errorf("expected 25%% CPU and memory, got %+v", nodes[1])
+	}
+}
+
+// TestSortPodUsage verifies all supported sort keys.
+func TestSortPodUsage(t *testing.T) {
+	tests := []struct {
+		by      string
+		want    []string
+		wantErr bool
+	}{
+		{SortByCPU, []string{"b", "c", "a"}, false},
+		{SortByMemory, []string{"c", "a", "b"}, false},
+		{SortByName, []string{"a", "b", "c"}, false},
+		{"disk", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			pods := []PodUsage{
+				{Name: "c", CPUMilli: 200, MemoryBytes: 300},
+				{Name: "a", CPUMilli: 100, MemoryBytes: 200},
+				{Name: "b", CPUMilli: 300, MemoryBytes: 100},
+			}
+			err := SortPodUsage(pods, tt.by)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SortPodUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for i, name := range tt.want {
+				if pods[i].Name != name {
+					t.Errorf("position %d: expected %s, got %s", i, name, pods[i].Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements listing, creating and deleting namespaces, including detection of stuck finalizers.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// NamespaceStuckAfter is how long a namespace may stay terminating with finalizers
+// before it is considered stuck, even without a failure condition.
+const NamespaceStuckAfter = 5 * time.Minute
+
+// namespaceBlockingConditions are the namespace conditions reporting why deletion cannot complete.
+var namespaceBlockingConditions = []corev1.NamespaceConditionType{
+	corev1.NamespaceDeletionDiscoveryFailure,
+	corev1.NamespaceDeletionGVParsingFailure,
+	corev1.NamespaceDeletionContentFailure,
+	corev1.NamespaceContentRemaining,
+	corev1.NamespaceFinalizersRemaining,
+}
+
+// NamespaceInfo represents the phase and labels of a namespace.
+type NamespaceInfo struct {
+	Name string `json:"name" yaml:"name"`
+
+	// Status is the phase of the namespace, Active or Terminating.
+	Status    string            `json:"status" yaml:"status"`
+	Labels    map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Age       time.Duration     `json:"age" yaml:"age"`
+	CreatedAt time.Time         `json:"created_at" yaml:"created_at"`
+}
+
+// NamespaceTermination describes the deletion progress of a namespace.
+type NamespaceTermination struct {
+	Name string `json:"name"`
+
+	// Deleted reports that the namespace no longer exists.
+	Deleted bool `json:"deleted"`
+
+	// Terminating is the time since deletion was requested.
+	Terminating time.Duration `json:"terminating"`
+
+	// Finalizers lists the object and spec finalizers still pending.
+	Finalizers []string `json:"finalizers,omitempty"`
+
+	// Blockers holds the messages of conditions reporting why deletion cannot complete.
+	Blockers []string `json:"blockers,omitempty"`
+
+	// Stuck reports that deletion is blocked by finalizers and unlikely to finish on its own.
+	Stuck bool `json:"stuck"`
+}
+
+// String summarizes the termination status for humans.
+func (t NamespaceTermination) String() string {
+	if t.Deleted {
+		return fmt.Sprintf("namespace %q deleted", t.Name)
+	}
+	summary := fmt.Sprintf("namespace %q terminating for %s", t.Name, t.Terminating.Round(time.Second))
+	if len(t.Finalizers) > 0 {
+		summary += fmt.Sprintf(", pending finalizers: %s", strings.Join(t.Finalizers, ", "))
+	}
+	if len(t.Blockers) > 0 {
+		summary += fmt.Sprintf("; %s", strings.Join(t.Blockers, "; "))
+	}
+	return summary
+}
+
+// ListNamespaces returns the namespaces of the cluster sorted by name. Once the read cache has synced,
+// they are listed from it.
+func (c *Client) ListNamespaces(ctx context.Context) ([]NamespaceInfo, error) {
+	namespaces, cached, err := cachedObjects[corev1.Namespace](c, "namespaces", metav1.NamespaceAll)
+	if !cached {
+		namespaces, err = List[corev1.Namespace](ctx, c.clientset.CoreV1().Namespaces().List, metav1.ListOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	now := time.Now()
+	infos := make([]NamespaceInfo, 0, len(namespaces))
+	for i := range namespaces {
+		namespace := &namespaces[i]
+		infos = append(infos, NamespaceInfo{
+			Name:      namespace.Name,
+			Status:    string(namespace.Status.Phase),
+			Labels:    namespace.Labels,
+			Age:       now.Sub(namespace.CreationTimestamp.Time),
+			CreatedAt: namespace.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// CreateNamespace creates a namespace with the given labels.
+// In client dry runs, nothing is sent to the API server.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) CreateNamespace(ctx context.Context, name string, labels map[string]string) error {
+	change := authz.Change{Operation: "create", Resource: "namespaces", Name: name}
+	if err := c.authorize(ctx, change); err != nil {
+		return err
+	}
+	if c.skipMutation(change) {
+		return nil
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	if _, err := c.clientset.CoreV1().Namespaces().Create(ctx, ns,
+		metav1.CreateOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()}); err != nil {
+		c.logger.Error().Err(err).Str("name", name).Msg("Failed to create namespace")
+		return fmt.Errorf("failed to create namespace %q: %w", name, err)
+	}
+
+	c.logger.Info().Str("name", name).Msg("Namespace created")
+	return nil
+}
+
+// DeleteNamespace requests deletion of a namespace and returns its termination status right after.
+// In dry runs, the namespace is left as is and the returned status is empty.
+// In client dry runs, the namespace is only checked to exist.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) DeleteNamespace(ctx context.Context, name string) (NamespaceTermination, error) {
+	change := authz.Change{Operation: "delete", Resource: "namespaces", Name: name}
+	if err := c.authorize(ctx, change); err != nil {
+		return NamespaceTermination{}, err
+	}
+
+	var err error
+	namespaces := c.clientset.CoreV1().Namespaces()
+	if c.skipMutation(change) {
+		_, err = namespaces.Get(ctx, name, metav1.GetOptions{})
+	} else {
+		err = namespaces.Delete(ctx, name, metav1.DeleteOptions{DryRun: c.dryRunOption()})
+	}
+	if err != nil {
+		c.logger.Error().Err(err).Str("name", name).Msg("Failed to delete namespace")
+		return NamespaceTermination{}, fmt.Errorf("failed to delete namespace %q: %w", name, err)
+	}
+
+	c.logger.Info().Str("name", name).Msg("Namespace deletion requested")
+	if c.dryRun.Enabled() {
+		return NamespaceTermination{Name: name}, nil
+	}
+	return c.GetNamespaceTermination(ctx, name)
+}
+
+// GetNamespaceTermination returns the deletion progress of a namespace.
+// A namespace that does not exist is reported as deleted.
+func (c *Client) GetNamespaceTermination(ctx context.Context, name string) (NamespaceTermination, error) {
+	ns, err := c.clientset.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return NamespaceTermination{Name: name, Deleted: true}, nil
+	}
+	if err != nil {
+		return NamespaceTermination{}, fmt.Errorf("failed to get namespace %q: %w", name, err)
+	}
+	return diagnoseNamespaceTermination(ns, time.Now()), nil
+}
+
+// WaitForNamespaceDeletion polls a namespace until it is gone or the context ends.
+// On timeout the returned error includes the pending finalizers and blocking conditions.
+func (c *Client) WaitForNamespaceDeletion(ctx context.Context, name string,
+	interval time.Duration) (NamespaceTermination, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := c.GetNamespaceTermination(ctx, name)
+		if err != nil {
+			return status, err
+		}
+		if status.Deleted {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, fmt.Errorf("timed out waiting for deletion, %s: %w", status, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// diagnoseNamespaceTermination inspects finalizers and conditions of a namespace being deleted.
+func diagnoseNamespaceTermination(ns *corev1.Namespace, now time.Time) NamespaceTermination {
+	status := NamespaceTermination{Name: ns.Name}
+	if ns.DeletionTimestamp == nil {
+		return status
+	}
+	status.Terminating = now.Sub(ns.DeletionTimestamp.Time)
+
+	status.Finalizers = append(status.Finalizers, ns.Finalizers...)
+	for _, finalizer := range ns.Spec.Finalizers {
+		status.Finalizers = append(status.Finalizers, string(finalizer))
+	}
+
+	for _, condition := range ns.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue && isBlockingCondition(condition.Type) {
+			status.Blockers = append(status.Blockers, condition.Message)
+		}
+	}
+
+	status.Stuck = len(status.Finalizers) > 0 &&
+		(len(status.Blockers) > 0 || status.Terminating >= NamespaceStuckAfter)
+	return status
+}
+
+// isBlockingCondition reports whether a namespace condition type explains a blocked deletion.
+func isBlockingCondition(conditionType corev1.NamespaceConditionType) bool {
+	for _, blocking := range namespaceBlockingConditions {
+		if conditionType == blocking {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests listing, creating and deleting namespaces and stuck finalizer detection.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// testNamespaceStaging is the namespace created and deleted by these tests.
+const testNamespaceStaging = "staging"
+
+// terminatingNamespace builds a namespace deleted at the given time with a pending spec finalizer.
+func terminatingNamespace(deletedAt time.Time, conditions ...corev1.NamespaceCondition) *corev1.Namespace {
+	deletion := metav1.NewTime(deletedAt)
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testNamespaceStaging, DeletionTimestamp: &deletion},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating, Conditions: conditions},
+	}
+}
+
+// TestListNamespaces verifies that namespaces are listed by name with their phase and labels.
+func TestListNamespaces(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(),
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: testNamespaceDefault, Labels: map[string]string{"team": "web"}},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		terminatingNamespace(time.Now()))
+
+	namespaces, err := client.ListNamespaces(context.Background())
+	if err != nil || len(namespaces) != 2 {
+		t.Fatalf("ListNamespaces() = %+v, %v, want 2 namespaces", namespaces, err)
+	}
+	if got := namespaces[0]; got.Name != testNamespaceDefault || got.Status != "Active" || got.Labels["team"] != "web" {
+		t.Errorf("unexpected namespace %+v", got)
+	}
+	if got := namespaces[1]; got.Name != testNamespaceStaging || got.Status != "Terminating" {
+		t.Errorf("unexpected namespace %+v", got)
+	}
+}
+
+// TestCreateAndDeleteNamespace verifies the namespace lifecycle against the fake clientset.
+func TestCreateAndDeleteNamespace(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+	ctx := context.Background()
+
+	if err := client.CreateNamespace(ctx, testNamespaceStaging, map[string]string{"team": "a"}); err != nil {
+		t.Fatalf("CreateNamespace() error = %v", err)
+	}
+	ns, err := client.clientset.CoreV1().Namespaces().Get(ctx, testNamespaceStaging, metav1.GetOptions{})
+	if err != nil || ns.Labels["team"] != "a" {
+		t.Fatalf("expected labeled namespace, got %v (%v)", ns, err)
+	}
+	if err := client.CreateNamespace(ctx, testNamespaceStaging, nil); err == nil {
+		t.Error("expected error creating an existing namespace")
+	}
+
+	status, err := client.DeleteNamespace(ctx, testNamespaceStaging)
+	if err != nil {
+		t.Fatalf("DeleteNamespace() error = %v", err)
+	}
+	if !status.Deleted {
+		t.Errorf("expected namespace to be reported deleted, got %+v", status)
+	}
+	if _, err := client.DeleteNamespace(ctx, testNamespaceStaging); err == nil {
+		t.Error("expected error deleting a missing namespace")
+	}
+}
+
+// TestNamespaceOperationsDenied verifies that namespace changes are subject to the authorization hook.
+func TestNamespaceOperationsDenied(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), demoNamespace(testNamespaceStaging))
+	client.SetAuthorizer(denyAuthorizer{})
+	ctx := context.Background()
+
+	if err := client.CreateNamespace(ctx, "other", nil); !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("CreateNamespace() error = %v, want ErrDenied", err)
+	}
+	if _, err := client.DeleteNamespace(ctx, testNamespaceStaging); !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("DeleteNamespace() error = %v, want ErrDenied", err)
+	}
+}
+
+// TestDiagnoseNamespaceTermination verifies stuck detection from finalizers, conditions and age.
+func TestDiagnoseNamespaceTermination(t *testing.T) {
+	now := time.Now()
+	blocked := corev1.NamespaceCondition{
+		Type:    corev1.NamespaceFinalizersRemaining,
+		Status:  corev1.ConditionTrue,
+		Message: "Some content in the namespace has finalizers remaining: example.com/protect in 1 resource instances",
+	}
+	resolved := corev1.NamespaceCondition{Type: corev1.NamespaceContentRemaining, Status: corev1.ConditionFalse}
+
+	tests := []struct {
+		name      string
+		ns        *corev1.Namespace
+		wantStuck bool
+		blockers  int
+	}{
+		{"active", demoNamespace(testNamespaceStaging), false, 0},
+		{"recently deleted", terminatingNamespace(now.Add(-time.Minute), resolved), false, 0},
+		{"blocking condition", terminatingNamespace(now.Add(-time.Minute), blocked), true, 1},
+		{"terminating too long", terminatingNamespace(now.Add(-NamespaceStuckAfter)), true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := diagnoseNamespaceTermination(tt.ns, now)
+			if status.Stuck != tt.wantStuck {
+				t.Errorf("expected stuck=%v, got %+v", tt.wantStuck, status)
+			}
+			if len(status.Blockers) != tt.blockers {
+				t.Errorf("expected %d blockers, got %v", tt.blockers, status.Blockers)
+			}
+		})
+	}
+}
+
+// TestWaitForNamespaceDeletion verifies that a timed out wait reports the pending finalizers.
+func TestWaitForNamespaceDeletion(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), terminatingNamespace(time.Now()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := client.WaitForNamespaceDeletion(ctx, testNamespaceStaging, 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), string(corev1.FinalizerKubernetes)) {
+		t.Errorf("expected error to list pending finalizers, got %v", err)
+	}
+
+	status, err := client.WaitForNamespaceDeletion(context.Background(), "missing", time.Millisecond)
+	if err != nil || !status.Deleted {
+		t.Errorf("expected missing namespace to be deleted, got %+v (%v)", status, err)
+	}
+}
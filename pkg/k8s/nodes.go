@@ -0,0 +1,330 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements listing nodes and node maintenance: cordon, uncordon and drain via the eviction API.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// DefaultEvictionRetryInterval is how long drain waits before retrying an eviction blocked by a PodDisruptionBudget.
+const DefaultEvictionRetryInterval = 5 * time.Second
+
+// nodeRoleLabelPrefix prefixes the labels naming the roles of a node, e.g. node-role.kubernetes.io/control-plane.
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// mirrorPodAnnotation marks static pods mirrored by the kubelet; they cannot be evicted through the API.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// NodeInfo represents the readiness, roles and kubelet version of a node.
+type NodeInfo struct {
+	Name string `json:"name" yaml:"name"`
+
+	// Status is Ready, NotReady or Unknown, followed by ",SchedulingDisabled" if the node is cordoned.
+	Status     string        `json:"status" yaml:"status"`
+	Roles      []string      `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Version    string        `json:"version" yaml:"version"`
+	InternalIP string        `json:"internal_ip,omitempty" yaml:"internal_ip,omitempty"`
+	Age        time.Duration `json:"age" yaml:"age"`
+	CreatedAt  time.Time     `json:"created_at" yaml:"created_at"`
+}
+
+// DrainOptions configures a node drain.
+type DrainOptions struct {
+	// GracePeriodSeconds overrides the termination grace period of evicted pods. Negative uses the pod's own.
+	GracePeriodSeconds int
+
+	// IgnoreDaemonSets skips DaemonSet-managed pods instead of refusing to drain.
+	IgnoreDaemonSets bool
+
+	// Force evicts pods not managed by a controller, which will not be recreated elsewhere.
+	Force bool
+
+	// RetryInterval is the delay between eviction attempts blocked by a PodDisruptionBudget.
+	RetryInterval time.Duration
+
+	// OnEvicted is called after each pod is gone, if set.
+	OnEvicted func(pod *corev1.Pod)
+}
+
+// DrainResult reports what a drain did.
+type DrainResult struct {
+	Evicted []string `json:"evicted"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// ListNodes returns the nodes of the cluster sorted by name. Once the read cache has synced, they are
+// listed from it.
+func (c *Client) ListNodes(ctx context.Context) ([]NodeInfo, error) {
+	nodes, cached, err := cachedObjects[corev1.Node](c, "nodes", metav1.NamespaceAll)
+	if !cached {
+		nodes, err = List[corev1.Node](ctx, c.clientset.CoreV1().Nodes().List, metav1.ListOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	now := time.Now()
+	infos := make([]NodeInfo, 0, len(nodes))
+	for i := range nodes {
+		node := &nodes[i]
+		info := NodeInfo{
+			Name:      node.Name,
+			Status:    nodeStatus(node),
+			Roles:     nodeRoles(node),
+			Version:   node.Status.NodeInfo.KubeletVersion,
+			Age:       now.Sub(node.CreationTimestamp.Time),
+			CreatedAt: node.CreationTimestamp.Time,
+		}
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				info.InternalIP = address.Address
+				break
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// nodeStatus returns the status of a node as kubectl shows it, from its Ready condition and cordon state.
+func nodeStatus(node *corev1.Node) string {
+	status := "Unknown"
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue:
+			status = "Ready"
+		case corev1.ConditionFalse:
+			status = "NotReady"
+		}
+	}
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// nodeRoles returns the sorted roles of a node from its node-role.kubernetes.io labels.
+func nodeRoles(node *corev1.Node) []string {
+	var roles []string
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, nodeRoleLabelPrefix); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// CordonNode marks a node unschedulable. It reports whether the node was changed.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) CordonNode(ctx context.Context, name string) (bool, error) {
+	return c.setUnschedulable(ctx, "cordon", name, true)
+}
+
+// UncordonNode marks a node schedulable again. It reports whether the node was changed.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) UncordonNode(ctx context.Context, name string) (bool, error) {
+	return c.setUnschedulable(ctx, "uncordon", name, false)
+}
+
+// setUnschedulable patches spec.unschedulable of a node unless it already has the desired value.
+func (c *Client) setUnschedulable(ctx context.Context, operation, name string, unschedulable bool) (bool, error) {
+	if err := c.authorize(ctx, authz.Change{Operation: operation, Resource: "nodes", Name: name}); err != nil {
+		return false, err
+	}
+	return c.patchUnschedulable(ctx, name, unschedulable)
+}
+
+// patchUnschedulable sets spec.unschedulable of a node without consulting the authorization hook.
+// The update is retried if the node is modified concurrently, e.g. by the kubelet updating its status.
+// In client dry runs, the node is only read, but the result still reports whether it would change.
+func (c *Client) patchUnschedulable(ctx context.Context, name string, unschedulable bool) (bool, error) {
+	nodes := c.clientset.CoreV1().Nodes()
+	skip := c.skipMutation(authz.Change{Operation: "update", Resource: "nodes", Name: name})
+	_, changed, err := UpdateWithRetry(ctx,
+		func(ctx context.Context) (*corev1.Node, error) {
+			return nodes.Get(ctx, name, metav1.GetOptions{})
+		},
+		func(node *corev1.Node) (bool, error) {
+			if node.Spec.Unschedulable == unschedulable {
+				return false, nil
+			}
+			node.Spec.Unschedulable = unschedulable
+			return true, nil
+		},
+		func(ctx context.Context, node *corev1.Node) (*corev1.Node, error) {
+			if skip {
+				return node, nil
+			}
+			return nodes.Update(ctx, node, metav1.UpdateOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+		})
+	if err != nil {
+		return false, fmt.Errorf("failed to update node %q: %w", name, err)
+	}
+
+	if changed {
+		c.logger.Info().Str("node", name).Bool("unschedulable", unschedulable).Msg("Node schedulability changed")
+	}
+	return changed, nil
+}
+
+// DrainNode cordons a node and evicts its pods, respecting PodDisruptionBudgets.
+// Evictions rejected by a budget are retried until the context ends. Mirror pods are always skipped,
+// DaemonSet pods are skipped with IgnoreDaemonSets, and unmanaged pods are only evicted with Force.
+// In dry runs, the result lists the pods that would be evicted; server dry runs still submit
+// the evictions for admission, including PodDisruptionBudget checks, but do not wait for the pods.
+// The drain is submitted to the authorization hook once, before the node is cordoned.
+func (c *Client) DrainNode(ctx context.Context, name string, opts DrainOptions) (DrainResult, error) {
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "drain",
+		Resource:  "nodes",
+		Name:      name,
+		Details:   map[string]string{"gracePeriodSeconds": strconv.Itoa(opts.GracePeriodSeconds)},
+	}); err != nil {
+		return DrainResult{}, err
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = DefaultEvictionRetryInterval
+	}
+
+	if _, err := c.patchUnschedulable(ctx, name, true); err != nil {
+		return DrainResult{}, err
+	}
+
+	pods, result, err := c.podsToEvict(ctx, name, opts)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if err := c.evictPod(ctx, pod, opts); err != nil {
+			return result, err
+		}
+		result.Evicted = append(result.Evicted, pod.Namespace+"/"+pod.Name)
+		if opts.OnEvicted != nil {
+			opts.OnEvicted(pod)
+		}
+	}
+	return result, nil
+}
+
+// podsToEvict lists the pods of a node and sorts out those that must not be evicted.
+// It fails without evicting anything if a pod blocks the drain under the given options.
+func (c *Client) podsToEvict(ctx context.Context, node string, opts DrainOptions) ([]corev1.Pod, DrainResult, error) {
+	list, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node).String(),
+	})
+	if err != nil {
+		return nil, DrainResult{}, fmt.Errorf("failed to list pods on node %q: %w", node, err)
+	}
+
+	var result DrainResult
+	var pods []corev1.Pod
+	var blocking []string
+	for _, pod := range list.Items {
+		// Re-check the node in case the field selector was not applied server-side.
+		if pod.Spec.NodeName != node {
+			continue
+		}
+		key := pod.Namespace + "/" + pod.Name
+		owner := metav1.GetControllerOf(&pod)
+		switch {
+		case pod.Annotations[mirrorPodAnnotation] != "":
+			result.Skipped = append(result.Skipped, key)
+		case owner != nil && owner.Kind == "DaemonSet":
+			if !opts.IgnoreDaemonSets {
+				blocking = append(blocking, key+" (DaemonSet-managed, use --ignore-daemonsets)")
+				continue
+			}
+			result.Skipped = append(result.Skipped, key)
+		case owner == nil && !isFinished(&pod) && !opts.Force:
+			blocking = append(blocking, key+" (not managed by a controller, use --force)")
+		default:
+			pods = append(pods, pod)
+		}
+	}
+
+	if len(blocking) > 0 {
+		return nil, result, fmt.Errorf("cannot drain node %q: %s", node, strings.Join(blocking, ", "))
+	}
+	return pods, result, nil
+}
+
+// evictPod evicts a pod, retrying while a PodDisruptionBudget disallows it, and waits for it to be gone.
+func (c *Client) evictPod(ctx context.Context, pod *corev1.Pod, opts DrainOptions) error {
+	if c.skipMutation(authz.Change{Operation: "evict", Resource: "pods", Namespace: pod.Namespace, Name: pod.Name}) {
+		return nil
+	}
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &metav1.DeleteOptions{DryRun: c.dryRunOption()},
+	}
+	if opts.GracePeriodSeconds >= 0 {
+		grace := int64(opts.GracePeriodSeconds)
+		eviction.DeleteOptions.GracePeriodSeconds = &grace
+	}
+
+	for {
+		err := c.clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case (err == nil || apierrors.IsNotFound(err)) && c.dryRun.Enabled():
+			return nil
+		case err == nil || apierrors.IsNotFound(err):
+			return c.waitForPodDeletion(ctx, pod, opts.RetryInterval)
+		case apierrors.IsTooManyRequests(err):
+			c.logger.Info().Str("pod", pod.Name).Str("namespace", pod.Namespace).
+				Msg("Eviction blocked by PodDisruptionBudget, retrying")
+		default:
+			return fmt.Errorf("failed to evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up evicting pod %s/%s blocked by a PodDisruptionBudget: %w",
+				pod.Namespace, pod.Name, ctx.Err())
+		case <-time.After(opts.RetryInterval):
+		}
+	}
+}
+
+// waitForPodDeletion polls until the pod is gone or replaced by a pod with the same name.
+func (c *Client) waitForPodDeletion(ctx context.Context, pod *corev1.Pod, interval time.Duration) error {
+	for {
+		current, err := c.clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for pod %s/%s to terminate: %w", pod.Namespace, pod.Name, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// isFinished reports whether a pod has succeeded or failed.
+func isFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
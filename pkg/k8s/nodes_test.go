@@ -0,0 +1,239 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests listing nodes and node cordon, uncordon and drain.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// testNodeName is the node cordoned and drained by these tests.
+const testNodeName = "worker-1"
+
+// newTestNode builds the test node.
+func newTestNode(unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: testNodeName},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+	}
+}
+
+// newTestPodOnNode builds a running pod on the test node, controlled by an owner of the given kind if set.
+func newTestPodOnNode(name, ownerKind string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespaceDefault, UID: types.UID("uid-" + name)},
+		Spec:       corev1.PodSpec{NodeName: testNodeName},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if ownerKind != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: "owner", Controller: &controller}}
+	}
+	return pod
+}
+
+// newDrainTestClient creates a client whose evictions delete the pod, after rejecting
+// the first blockedEvictions attempts as a PodDisruptionBudget would.
+func newDrainTestClient(t *testing.T, blockedEvictions int, objects ...runtime.Object) *Client {
+	t.Helper()
+	client := NewFakeClient(zerolog.Nop(), objects...)
+	clientset := client.clientset.(*fake.Clientset)
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if blockedEvictions > 0 {
+			blockedEvictions--
+			return true, nil, apierrors.NewTooManyRequests("disruption budget exceeded", 1)
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, clientset.Tracker().Delete(gvr, action.GetNamespace(), eviction.Name)
+	})
+	return client
+}
+
+// TestListNodes verifies that nodes are listed by name with their status, roles and internal address.
+func TestListNodes(t *testing.T) {
+	control := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "control-1", Labels: map[string]string{
+			nodeRoleLabelPrefix + "control-plane": "", "kubernetes.io/os": "linux",
+		}},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "control-1"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.10"},
+			},
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.35.0"},
+		},
+	}
+	client := NewFakeClient(zerolog.Nop(), newTestNode(true), control)
+
+	nodes, err := client.ListNodes(context.Background())
+	if err != nil || len(nodes) != 2 {
+		t.Fatalf("ListNodes() = %+v, %v, want 2 nodes", nodes, err)
+	}
+	if got := nodes[0]; got.Name != "control-1" || got.Status != "Ready" || len(got.Roles) != 1 ||
+		got.Roles[0] != "control-plane" || got.Version != "v1.35.0" || got.InternalIP != "10.0.0.10" {
+		t.Errorf("unexpected control plane node %+v", got)
+	}
+	if got := nodes[1]; got.Name != testNodeName || got.Status != "Unknown,SchedulingDisabled" || got.Roles != nil {
+		t.Errorf("unexpected worker node %+v", got)
+	}
+}
+
+// TestNodeStatus verifies the status derived from the Ready condition.
+func TestNodeStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		ready         corev1.ConditionStatus
+		unschedulable bool
+		want          string
+	}{
+		{"ready", corev1.ConditionTrue, false, "Ready"},
+		{"not ready", corev1.ConditionFalse, false, "NotReady"},
+		{"unknown", corev1.ConditionUnknown, false, "Unknown"},
+		{"cordoned", corev1.ConditionTrue, true, "Ready,SchedulingDisabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newTestNode(tt.unschedulable)
+			node.Status.Conditions = []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: tt.ready},
+			}
+			if got := nodeStatus(node); got != tt.want {
+				t.Errorf("nodeStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCordonUncordonNode verifies toggling schedulability and reporting whether anything changed.
+func TestCordonUncordonNode(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), newTestNode(false))
+	ctx := context.Background()
+
+	steps := []struct {
+		name        string
+		op          func(context.Context, string) (bool, error)
+		wantChanged bool
+		wantCordon  bool
+	}{
+		{"cordon", client.CordonNode, true, true},
+		{"cordon again", client.CordonNode, false, true},
+		{"uncordon", client.UncordonNode, true, false},
+		{"uncordon again", client.UncordonNode, false, false},
+	}
+
+	for _, step := range steps {
+		changed, err := step.op(ctx, testNodeName)
+		if err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+		if changed != step.wantChanged {
+			t.Errorf("%s: changed = %v, want %v", step.name, changed, step.wantChanged)
+		}
+		node, _ := client.clientset.CoreV1().Nodes().Get(ctx, testNodeName, metav1.GetOptions{})
+		if node.Spec.Unschedulable != step.wantCordon {
+			t.Errorf("%s: unschedulable = %v, want %v", step.name, node.Spec.Unschedulable, step.wantCordon)
+		}
+	}
+
+	if _, err := client.CordonNode(ctx, "missing"); err == nil {
+		t.Error("expected error cordoning a missing node")
+	}
+	client.SetAuthorizer(denyAuthorizer{})
+	if _, err := client.CordonNode(ctx, testNodeName); !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("expected denial, got %v", err)
+	}
+}
+
+// TestDrainNode verifies pod classification, PDB retries and the resulting evictions.
+func TestDrainNode(t *testing.T) {
+	mirror := newTestPodOnNode("static", "Node")
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+	other := newTestPodOnNode("elsewhere", "ReplicaSet")
+	other.Spec.NodeName = "worker-2"
+
+	tests := []struct {
+		name        string
+		pods        []runtime.Object
+		opts        DrainOptions
+		blocked     int
+		wantEvicted int
+		wantSkipped int
+		wantErr     string
+	}{
+		{"replicaset pods", []runtime.Object{newTestPodOnNode("web", "ReplicaSet"), other, mirror},
+			DrainOptions{}, 0, 1, 1, ""},
+		{"pdb retried", []runtime.Object{newTestPodOnNode("web", "ReplicaSet")}, DrainOptions{}, 2, 1, 0, ""},
+		{"daemonset refused", []runtime.Object{newTestPodOnNode("agent", "DaemonSet")},
+			DrainOptions{}, 0, 0, 0, "--ignore-daemonsets"},
+		{"daemonset ignored", []runtime.Object{newTestPodOnNode("agent", "DaemonSet")},
+			DrainOptions{IgnoreDaemonSets: true}, 0, 0, 1, ""},
+		{"unmanaged refused", []runtime.Object{newTestPodOnNode("bare", "")}, DrainOptions{}, 0, 0, 0, "--force"},
+		{"unmanaged forced", []runtime.Object{newTestPodOnNode("bare", "")},
+			DrainOptions{Force: true, GracePeriodSeconds: 5}, 0, 1, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newDrainTestClient(t, tt.blocked, append(tt.pods, newTestNode(false))...)
+			tt.opts.RetryInterval = time.Millisecond
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := client.DrainNode(ctx, testNodeName, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DrainNode() error = %v", err)
+			}
+			if len(result.Evicted) != tt.wantEvicted || len(result.Skipped) != tt.wantSkipped {
+				t.Errorf("expected %d evicted and %d skipped, got %+v", tt.wantEvicted, tt.wantSkipped, result)
+			}
+
+			node, _ := client.clientset.CoreV1().Nodes().Get(ctx, testNodeName, metav1.GetOptions{})
+			if !node.Spec.Unschedulable {
+				t.Error("expected drained node to be cordoned")
+			}
+		})
+	}
+}
+
+// TestDrainNodeBudgetTimeout verifies that a permanently blocked eviction gives up when the context ends.
+func TestDrainNodeBudgetTimeout(t *testing.T) {
+	client := newDrainTestClient(t, 1000, newTestNode(false), newTestPodOnNode("web", "ReplicaSet"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.DrainNode(ctx, testNodeName, DrainOptions{RetryInterval: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
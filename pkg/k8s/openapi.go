@@ -0,0 +1,292 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file downloads and caches the OpenAPI v3 schemas served by the cluster.
+package k8s
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// openAPIContentType is the format OpenAPI documents are requested in.
+const openAPIContentType = "application/json"
+
+// schemaRefPrefix prefixes references to component schemas within a document.
+const schemaRefPrefix = "#/components/schemas/"
+
+// ErrKindNotFound is returned by KindSchema for kinds the document does not define.
+var ErrKindNotFound = errors.New("kind not found in OpenAPI document")
+
+// OpenAPIDocument is the OpenAPI v3 document of one API group/version.
+type OpenAPIDocument struct {
+	// GroupVersion is the API group/version the document describes, e.g. "apps/v1" or "v1".
+	GroupVersion string
+
+	// Schemas are the component schemas keyed by definition name, e.g. "io.k8s.api.apps.v1.Deployment".
+	Schemas map[string]map[string]any
+}
+
+// openAPICacheEntry is an OpenAPI document stored in the cache directory.
+type openAPICacheEntry struct {
+	// ServerRelativeURL identifies the document version; it changes whenever the document does.
+	ServerRelativeURL string `json:"serverRelativeURL"`
+
+	Document json.RawMessage `json:"document"`
+}
+
+// openAPIDocuments memoizes the OpenAPI documents fetched by a client.
+type openAPIDocuments struct {
+	mu        sync.Mutex
+	documents map[string]*OpenAPIDocument
+}
+
+// SetOpenAPICacheDir enables caching downloaded OpenAPI documents in dir, below a directory per
+// API server, so they are only downloaded again when the server reports a change and can be
+// loaded offline with LoadCachedOpenAPIDocument. Passing "" disables the disk cache.
+func (c *Client) SetOpenAPICacheDir(dir string) {
+	c.openAPICacheDir = dir
+}
+
+// DefaultOpenAPICacheDir returns the default OpenAPI cache directory below the user's cache directory.
+func DefaultOpenAPICacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "k8s-controller", "openapi"), nil
+}
+
+// OpenAPIDocument returns the OpenAPI v3 document of an API group/version such as "apps/v1" or "v1".
+// Documents are kept in memory for the lifetime of the client and, if a cache directory is set,
+// reused from disk as long as the server reports the same version.
+func (c *Client) OpenAPIDocument(groupVersion string) (*OpenAPIDocument, error) {
+	c.openAPI.mu.Lock()
+	defer c.openAPI.mu.Unlock()
+	if doc, ok := c.openAPI.documents[groupVersion]; ok {
+		return doc, nil
+	}
+
+	raw, err := c.fetchOpenAPIDocument(groupVersion)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseOpenAPIDocument(groupVersion, raw)
+	if err != nil {
+		return nil, err
+	}
+	if c.openAPI.documents == nil {
+		c.openAPI.documents = map[string]*OpenAPIDocument{}
+	}
+	c.openAPI.documents[groupVersion] = doc
+	return doc, nil
+}
+
+// fetchOpenAPIDocument returns the raw OpenAPI document of a group/version from the disk cache,
+// if it is current, or from the API server.
+func (c *Client) fetchOpenAPIDocument(groupVersion string) ([]byte, error) {
+	client := c.openAPIClient
+	if client == nil {
+		client = c.clientset.Discovery().OpenAPIV3()
+	}
+	paths, err := client.Paths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OpenAPI documents: %w", err)
+	}
+	gv, ok := paths[openAPIPath(groupVersion)]
+	if !ok {
+		return nil, fmt.Errorf("no OpenAPI v3 document for API version %q", groupVersion)
+	}
+
+	cacheFile := ""
+	if c.openAPICacheDir != "" {
+		cacheFile = openAPICacheFile(c.openAPICacheDir, c.config.Host, groupVersion)
+		entry, err := readOpenAPICache(cacheFile)
+		if err == nil && entry.ServerRelativeURL == gv.ServerRelativeURL() {
+			c.logger.Debug().Str("file", cacheFile).Msg("Using cached OpenAPI document")
+			return entry.Document, nil
+		}
+	}
+
+	raw, err := gv.Schema(openAPIContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download OpenAPI document for %q: %w", groupVersion, err)
+	}
+	if cacheFile != "" {
+		entry := openAPICacheEntry{ServerRelativeURL: gv.ServerRelativeURL(), Document: raw}
+		if err := writeOpenAPICache(cacheFile, entry); err != nil {
+			c.logger.Warn().Err(err).Str("file", cacheFile).Msg("Failed to cache OpenAPI document")
+		}
+	}
+	return raw, nil
+}
+
+// LoadCachedOpenAPIDocument loads the OpenAPI document of a group/version cached for the API server
+// at host, without contacting it. host is the server URL of the client that downloaded the document.
+func LoadCachedOpenAPIDocument(dir, host, groupVersion string) (*OpenAPIDocument, error) {
+	entry, err := readOpenAPICache(openAPICacheFile(dir, host, groupVersion))
+	if err != nil {
+		return nil, err
+	}
+	return parseOpenAPIDocument(groupVersion, entry.Document)
+}
+
+// openAPIPath returns the discovery path of a group/version: "api/v1" for the core group,
+// "apis/GROUP/VERSION" otherwise.
+func openAPIPath(groupVersion string) string {
+	if !strings.Contains(groupVersion, "/") {
+		return "api/" + groupVersion
+	}
+	return "apis/" + groupVersion
+}
+
+// openAPICacheFile returns the cache file of a group/version, e.g. DIR/127.0.0.1_6443/apis__apps__v1.json.
+func openAPICacheFile(dir, host, groupVersion string) string {
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	hostDir := strings.NewReplacer(":", "_", "/", "_").Replace(host)
+	name := strings.ReplaceAll(openAPIPath(groupVersion), "/", "__") + ".json"
+	return filepath.Join(dir, hostDir, name)
+}
+
+// readOpenAPICache reads a cached OpenAPI document.
+func readOpenAPICache(file string) (openAPICacheEntry, error) {
+	var entry openAPICacheEntry
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return entry, fmt.Errorf("failed to read cached OpenAPI document: %w", err)
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, fmt.Errorf("failed to parse cached OpenAPI document %s: %w", file, err)
+	}
+	return entry, nil
+}
+
+// writeOpenAPICache stores an OpenAPI document in the cache, replacing the file atomically.
+func writeOpenAPICache(file string, entry openAPICacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return fmt.Errorf("failed to create OpenAPI cache directory: %w", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write OpenAPI cache: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to replace OpenAPI cache: %w", err)
+	}
+	return nil
+}
+
+// parseOpenAPIDocument extracts the component schemas of a raw OpenAPI v3 document.
+func parseOpenAPIDocument(groupVersion string, raw []byte) (*OpenAPIDocument, error) {
+	var document struct {
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document for %q: %w", groupVersion, err)
+	}
+	return &OpenAPIDocument{GroupVersion: groupVersion, Schemas: document.Components.Schemas}, nil
+}
+
+// KindSchema returns the schema of a kind of the document's group/version, with references to
+// other schemas resolved, so it can be used for validation and field documentation.
+// Recursive types are resolved up to their first repetition.
+func (d *OpenAPIDocument) KindSchema(kind string) (map[string]any, error) {
+	gv, err := schema.ParseGroupVersion(d.GroupVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API version %q: %w", d.GroupVersion, err)
+	}
+	for name, s := range d.Schemas {
+		if definesKind(s, gv.WithKind(kind)) {
+			return d.resolve(s, map[string]bool{name: true}), nil
+		}
+	}
+	return nil, fmt.Errorf("%s %s: %w", d.GroupVersion, kind, ErrKindNotFound)
+}
+
+// definesKind reports whether a schema is the definition of the given kind.
+func definesKind(s map[string]any, gvk schema.GroupVersionKind) bool {
+	kinds, _ := s["x-kubernetes-group-version-kind"].([]any)
+	for _, k := range kinds {
+		m, _ := k.(map[string]any)
+		if m["group"] == gvk.Group && m["version"] == gvk.Version && m["kind"] == gvk.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns a copy of s with "$ref" and single-element "allOf" references replaced by the
+// referenced schemas. References to schemas in seen, i.e. cycles, are kept as they are.
+func (d *OpenAPIDocument) resolve(s map[string]any, seen map[string]bool) map[string]any {
+	if ref, ok := s["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, schemaRefPrefix)
+		target, found := d.Schemas[name]
+		if !found || seen[name] {
+			return s
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		return d.resolve(target, seen)
+	}
+
+	// Fields are wrapped in allOf to add a description to a referenced type; the field's own
+	// keywords take precedence over the referenced ones.
+	resolved := make(map[string]any, len(s))
+	allOf, _ := s["allOf"].([]any)
+	inner, merged := map[string]any(nil), false
+	if len(allOf) == 1 {
+		inner, merged = allOf[0].(map[string]any)
+	}
+	if merged {
+		maps.Copy(resolved, d.resolve(inner, seen))
+	}
+	for key, value := range s {
+		switch key {
+		case "allOf":
+			if !merged {
+				resolved[key] = value
+			}
+		case "properties":
+			resolved[key] = d.resolveProperties(value, seen)
+		case "items", "additionalProperties":
+			if m, ok := value.(map[string]any); ok {
+				value = d.resolve(m, seen)
+			}
+			resolved[key] = value
+		default:
+			resolved[key] = value
+		}
+	}
+	return resolved
+}
+
+// resolveProperties resolves the schemas of the properties of an object schema.
+func (d *OpenAPIDocument) resolveProperties(value any, seen map[string]bool) any {
+	properties, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	resolved := make(map[string]any, len(properties))
+	for name, property := range properties {
+		if m, ok := property.(map[string]any); ok {
+			property = d.resolve(m, seen)
+		}
+		resolved[name] = property
+	}
+	return resolved
+}
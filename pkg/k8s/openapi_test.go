@@ -0,0 +1,124 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests downloading, caching and resolving OpenAPI v3 documents.
+package k8s
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/openapi"
+)
+
+// countingOpenAPIClient wraps an OpenAPI client and counts document downloads.
+type countingOpenAPIClient struct {
+	openapi.Client
+	downloads int
+}
+
+// Paths implements openapi.Client, wrapping each group/version to count its downloads.
+func (c *countingOpenAPIClient) Paths() (map[string]openapi.GroupVersion, error) {
+	paths, err := c.Client.Paths()
+	for path, gv := range paths {
+		paths[path] = countingGroupVersion{GroupVersion: gv, client: c}
+	}
+	return paths, err
+}
+
+// countingGroupVersion counts the downloads of one group/version.
+type countingGroupVersion struct {
+	openapi.GroupVersion
+	client *countingOpenAPIClient
+}
+
+// Schema implements openapi.GroupVersion.
+func (g countingGroupVersion) Schema(contentType string) ([]byte, error) {
+	g.client.downloads++
+	return g.GroupVersion.Schema(contentType)
+}
+
+// TestOpenAPIDocument verifies fetching documents, memoizing them and caching them on disk.
+func TestOpenAPIDocument(t *testing.T) {
+	dir := t.TempDir()
+	client := NewFakeClient(zerolog.Nop())
+	counting := &countingOpenAPIClient{Client: client.openAPIClient}
+	client.openAPIClient = counting
+	client.SetOpenAPICacheDir(dir)
+
+	for range 2 {
+		doc, err := client.OpenAPIDocument("apps/v1")
+		if err != nil {
+			t.Fatalf("OpenAPIDocument() error = %v", err)
+		}
+		if _, ok := doc.Schemas["io.k8s.api.apps.v1.Deployment"]; !ok {
+			t.Fatal("expected the Deployment schema in the apps/v1 document")
+		}
+	}
+	if counting.downloads != 1 {
+		t.Errorf("expected the document to be downloaded once, got %d", counting.downloads)
+	}
+
+	// A new client reuses the disk cache while the server reports the same document version.
+	fresh := NewFakeClient(zerolog.Nop())
+	fresh.openAPIClient = counting
+	fresh.SetOpenAPICacheDir(dir)
+	if _, err := fresh.OpenAPIDocument("apps/v1"); err != nil || counting.downloads != 1 {
+		t.Errorf("expected the cached document to be used, got %v after %d downloads", err, counting.downloads)
+	}
+
+	doc, err := LoadCachedOpenAPIDocument(dir, DemoHost, "apps/v1")
+	if err != nil || len(doc.Schemas) == 0 {
+		t.Fatalf("expected the cached document to load offline, got %v", err)
+	}
+	if _, err := LoadCachedOpenAPIDocument(dir, DemoHost, "batch/v1"); err == nil {
+		t.Error("expected an error for a document that was never downloaded")
+	}
+	if _, err := client.OpenAPIDocument("example.com/v1"); err == nil {
+		t.Error("expected an error for an unknown API version")
+	}
+}
+
+// TestOpenAPICacheFile verifies the cache layout per API server and group/version.
+func TestOpenAPICacheFile(t *testing.T) {
+	tests := []struct {
+		host, groupVersion, want string
+	}{
+		{"https://127.0.0.1:6443", "apps/v1", filepath.Join("cache", "127.0.0.1_6443", "apis__apps__v1.json")},
+		{"https://api.example.com", "v1", filepath.Join("cache", "api.example.com", "api__v1.json")},
+	}
+
+	for _, tt := range tests {
+		if got := openAPICacheFile("cache", tt.host, tt.groupVersion); got != tt.want {
+			t.Errorf("openAPICacheFile(%q, %q) = %s, want %s", tt.host, tt.groupVersion, got, tt.want)
+		}
+	}
+}
+
+// TestKindSchema verifies finding kinds and resolving references to other schemas.
+func TestKindSchema(t *testing.T) {
+	doc, err := NewFakeClient(zerolog.Nop()).OpenAPIDocument("apps/v1")
+	if err != nil {
+		t.Fatalf("OpenAPIDocument() error = %v", err)
+	}
+
+	deployment, err := doc.KindSchema("Deployment")
+	if err != nil {
+		t.Fatalf("KindSchema() error = %v", err)
+	}
+	spec, _ := deployment["properties"].(map[string]any)["spec"].(map[string]any)
+	if spec["description"] != "Specification of the desired behavior of the Deployment." {
+		t.Errorf("expected the field's own description to be kept, got %v", spec["description"])
+	}
+	replicas, ok := spec["properties"].(map[string]any)["replicas"].(map[string]any)
+	if !ok || replicas["type"] != "integer" {
+		t.Errorf("expected spec.replicas to be resolved to an integer, got %v", replicas)
+	}
+	if _, hasAllOf := spec["allOf"]; hasAllOf {
+		t.Error("expected the allOf reference to be replaced")
+	}
+
+	if _, err := doc.KindSchema("Pod"); !errors.Is(err, ErrKindNotFound) {
+		t.Errorf("expected ErrKindNotFound for a kind of another group, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements chunked listing with continue tokens, and generic typed listing on top of it.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultPageSize is the number of objects requested per list call when no limit is set.
+// It keeps single responses small enough to avoid API timeouts on large clusters.
+const DefaultPageSize int64 = 500
+
+// ListObject is a list returned by a client-go List call, e.g. *appsv1.DeploymentList
+// or *unstructured.UnstructuredList.
+type ListObject interface {
+	runtime.Object
+	GetContinue() string
+}
+
+// ListFunc fetches the page of a list selected by opts. The List methods of typed and dynamic
+// clients are ListFuncs, e.g. clientset.AppsV1().Deployments(ns).List.
+type ListFunc[L ListObject] func(ctx context.Context, opts metav1.ListOptions) (L, error)
+
+// ListPages calls list with successive continue tokens until the last page has been fetched.
+// opts.Limit is the page size; if it is not positive, DefaultPageSize is used, so paging cannot be
+// turned off.
+// list fetches the page selected by its options and returns the page's continue token.
+func ListPages(opts metav1.ListOptions, list func(opts metav1.ListOptions) (string, error)) error {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageSize
+	}
+	for {
+		next, err := list(opts)
+		if err != nil {
+			return err
+		}
+		if opts.Continue = next; opts.Continue == "" {
+			return nil
+		}
+	}
+}
+
+// ForEach fetches all pages of a list with ListPages and calls fn with each object of type T,
+// e.g. appsv1.Deployment for a DeploymentList. Pages are processed one at a time, so only the
+// objects of the current page are held in memory; fn must not retain the pointer it is passed.
+func ForEach[T any, L ListObject](ctx context.Context, list ListFunc[L], opts metav1.ListOptions,
+	fn func(obj *T) error) error {
+	return ListPages(opts, func(opts metav1.ListOptions) (string, error) {
+		page, err := list(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		err = meta.EachListItem(page, func(item runtime.Object) error {
+			obj, ok := any(item).(*T)
+			if !ok {
+				var want T
+				return fmt.Errorf("unexpected list item %T, expected %T", item, &want)
+			}
+			return fn(obj)
+		})
+		if err != nil {
+			return "", err
+		}
+		return page.GetContinue(), nil
+	})
+}
+
+// List fetches all pages of a list and returns its objects, e.g.
+//
+//	pods, err := k8s.List[corev1.Pod](ctx, clientset.CoreV1().Pods(ns).List, metav1.ListOptions{})
+func List[T any, L ListObject](ctx context.Context, list ListFunc[L], opts metav1.ListOptions) ([]T, error) {
+	var items []T
+	err := ForEach(ctx, list, opts, func(obj *T) error {
+		items = append(items, *obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
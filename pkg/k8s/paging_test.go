@@ -0,0 +1,183 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests chunked listing with continue tokens and generic typed listing.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestListPages verifies page size defaulting, continue token propagation and error handling.
+func TestListPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int64
+		wantLimit int64
+	}{
+		{"default page size", 0, DefaultPageSize},
+		{"explicit page size", 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokens []string
+			err := ListPages(metav1.ListOptions{Limit: tt.limit}, func(opts metav1.ListOptions) (string, error) {
+				if opts.Limit != tt.wantLimit {
+					t.Errorf("expected limit %d, got %d", tt.wantLimit, opts.Limit)
+				}
+				tokens = append(tokens, opts.Continue)
+				if len(tokens) == 3 {
+					return "", nil
+				}
+				return "page" + strconv.Itoa(len(tokens)+1), nil
+			})
+			if err != nil {
+				t.Fatalf("ListPages() error = %v", err)
+			}
+			if len(tokens) != 3 || tokens[0] != "" || tokens[1] != "page2" || tokens[2] != "page3" {
+				t.Errorf("unexpected continue tokens %q", tokens)
+			}
+		})
+	}
+
+	failure := errors.New("expired")
+	if err := ListPages(metav1.ListOptions{}, func(metav1.ListOptions) (string, error) {
+		return "", failure
+	}); !errors.Is(err, failure) {
+		t.Errorf("expected the list error, got %v", err)
+	}
+}
+
+// newPagedDeploymentClient returns a fake client serving five deployments in pages of the
+// requested limit, and the limits it was called with.
+func newPagedDeploymentClient() (*Client, *[]int64) {
+	client := NewFakeClient(zerolog.Nop())
+	var limits []int64
+	client.clientset.(*fake.Clientset).PrependReactor("list", "deployments",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			opts := action.(k8stesting.ListActionImpl).ListOptions
+			limits = append(limits, opts.Limit)
+			start, _ := strconv.Atoi(opts.Continue)
+
+			list := &appsv1.DeploymentList{}
+			for i := start; i < start+int(opts.Limit) && i < 5; i++ {
+				list.Items = append(list.Items, *createTestDeployment("app-"+strconv.Itoa(i),
+					testNamespaceDefault, 1, []string{testImageNginx}))
+			}
+			if next := start + int(opts.Limit); next < 5 {
+				list.Continue = strconv.Itoa(next)
+			}
+			return true, list, nil
+		})
+	return client, &limits
+}
+
+// TestListDeploymentsPaging verifies that ListDeployments follows continue tokens across all pages.
+func TestListDeploymentsPaging(t *testing.T) {
+	client, limits := newPagedDeploymentClient()
+	deployments, err := client.ListDeployments(context.Background(), ListDeploymentsOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListDeployments() error = %v", err)
+	}
+	if len(deployments) != 5 || deployments[4].Name != "app-4" {
+		t.Errorf("expected all 5 deployments, got %d", len(deployments))
+	}
+	if len(*limits) != 3 || (*limits)[0] != 2 {
+		t.Errorf("expected 3 calls with limit 2, got %v", *limits)
+	}
+}
+
+// TestListDeploymentPages verifies that pages are passed on as they arrive and that
+// callback errors stop the listing.
+func TestListDeploymentPages(t *testing.T) {
+	client, _ := newPagedDeploymentClient()
+	var sizes []int
+	err := client.ListDeploymentPages(context.Background(), ListDeploymentsOptions{Limit: 2},
+		func(page []DeploymentInfo) error {
+			sizes = append(sizes, len(page))
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ListDeploymentPages() error = %v", err)
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[2] != 1 {
+		t.Errorf("expected pages of 2, 2 and 1 deployments, got %v", sizes)
+	}
+
+	stop := errors.New("stop")
+	client, limits := newPagedDeploymentClient()
+	err = client.ListDeploymentPages(context.Background(), ListDeploymentsOptions{Limit: 2},
+		func([]DeploymentInfo) error { return stop })
+	if !errors.Is(err, stop) || len(*limits) != 1 {
+		t.Errorf("expected to stop after the first page, got %v after %d calls", err, len(*limits))
+	}
+}
+
+// TestList verifies that List collects the typed items of all pages and reports list errors.
+func TestList(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).ListOptions
+		if opts.Continue == "" {
+			return true, &appsv1.DeploymentList{
+				ListMeta: metav1.ListMeta{Continue: "next"},
+				Items:    []appsv1.Deployment{{ObjectMeta: metav1.ObjectMeta{Name: "first"}}},
+			}, nil
+		}
+		return true, &appsv1.DeploymentList{
+			Items: []appsv1.Deployment{{ObjectMeta: metav1.ObjectMeta{Name: "second"}}},
+		}, nil
+	})
+
+	ctx := context.Background()
+	deployments, err := List[appsv1.Deployment](ctx, clientset.AppsV1().Deployments("").List, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(deployments) != 2 || deployments[0].Name != "first" || deployments[1].Name != "second" {
+		t.Errorf("expected both pages, got %+v", deployments)
+	}
+
+	failure := errors.New("forbidden")
+	clientset.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, failure
+	})
+	_, err = List[corev1.Pod](ctx, clientset.CoreV1().Pods("").List, metav1.ListOptions{})
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the list error, got %v", err)
+	}
+}
+
+// TestForEachItemType verifies that ForEach rejects lists whose items are not of the requested type
+// and stops at the first callback error.
+func TestForEachItemType(t *testing.T) {
+	clientset := fake.NewSimpleClientset(createTestDeployment("a", testNamespaceDefault, 1, nil),
+		createTestDeployment("b", testNamespaceDefault, 1, nil))
+	ctx := context.Background()
+	list := clientset.AppsV1().Deployments(testNamespaceDefault).List
+
+	err := ForEach(ctx, list, metav1.ListOptions{}, func(*corev1.Pod) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "unexpected list item") {
+		t.Errorf("expected an item type error, got %v", err)
+	}
+
+	stop, calls := errors.New("stop"), 0
+	err = ForEach(ctx, list, metav1.ListOptions{}, func(*appsv1.Deployment) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("expected to stop after the first item, got %v after %d calls", err, calls)
+	}
+}
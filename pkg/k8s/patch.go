@@ -0,0 +1,126 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements generic patching of arbitrary resources via the dynamic client.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// User-facing patch type names, matching kubectl's --type flag.
+const (
+	PatchTypeStrategic = "strategic"
+	PatchTypeMerge     = "merge"
+	PatchTypeJSON      = "json"
+)
+
+// fieldManager identifies this tool in managedFields of objects it writes.
+const fieldManager = "k8s-controller"
+
+// ParsePatchType converts a user-facing patch type name into the API patch type.
+func ParsePatchType(name string) (types.PatchType, error) {
+	switch name {
+	case PatchTypeStrategic:
+		return types.StrategicMergePatchType, nil
+	case PatchTypeMerge:
+		return types.MergePatchType, nil
+	case PatchTypeJSON:
+		return types.JSONPatchType, nil
+	default:
+		return "", fmt.Errorf("unsupported patch type '%s', must be one of: strategic, merge, json", name)
+	}
+}
+
+// Patch applies a patch to the named object of the given resource and returns the patched object.
+// The namespace is ignored for cluster-scoped resources when empty. In client dry runs, the patch is
+// applied to the fetched object locally, which supports strategic merge patches of built-in types only.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) Patch(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+	patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	c.logger.Debug().
+		Str("resource", gvr.String()).
+		Str("namespace", ns).
+		Str("name", name).
+		Str("patch_type", string(patchType)).
+		Msg("Patching resource")
+
+	change := authz.Change{
+		Operation: "patch",
+		Resource:  gvr.Resource,
+		Namespace: ns,
+		Name:      name,
+		Details:   map[string]string{"type": string(patchType), "patch": string(data)},
+	}
+	if err := c.authorize(ctx, change); err != nil {
+		return nil, err
+	}
+
+	var patched *unstructured.Unstructured
+	var err error
+	resource := c.dynamic.Resource(gvr).Namespace(ns)
+	if c.skipMutation(change) {
+		if patched, err = resource.Get(ctx, name, metav1.GetOptions{}); err == nil {
+			patched, err = patchObject(patched, patchType, data)
+		}
+	} else {
+		patched, err = resource.Patch(ctx, name, patchType, data,
+			metav1.PatchOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+	}
+	if err != nil {
+		c.logger.Error().Err(err).Str("name", name).Msg("Failed to patch resource")
+		return nil, fmt.Errorf("failed to patch %s %q: %w", gvr.Resource, name, err)
+	}
+
+	c.logger.Info().Str("resource", gvr.Resource).Str("name", name).Msg("Resource patched")
+	return patched, nil
+}
+
+// patchObject applies a patch to a copy of obj locally, the way the API server would.
+// Strategic merge patches need the Go type of the object, so they only work for built-in types.
+func patchObject(obj *unstructured.Unstructured, patchType types.PatchType,
+	data []byte) (*unstructured.Unstructured, error) {
+	original, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch patchType {
+	case types.JSONPatchType:
+		var patch jsonpatch.Patch
+		if patch, err = jsonpatch.DecodePatch(data); err == nil {
+			patched, err = patch.Apply(original)
+		}
+	case types.MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, data)
+	case types.StrategicMergePatchType:
+		var typed runtime.Object
+		if typed, err = scheme.Scheme.New(obj.GroupVersionKind()); err != nil {
+			return nil, fmt.Errorf("strategic merge patches of %s are not supported, use a merge patch: %w",
+				obj.GetKind(), err)
+		}
+		patched, err = strategicpatch.StrategicMergePatch(original, data, typed)
+	default:
+		return nil, fmt.Errorf("unsupported patch type %q", patchType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	result := &unstructured.Unstructured{}
+	if err := result.UnmarshalJSON(patched); err != nil {
+		return nil, fmt.Errorf("invalid patch result: %w", err)
+	}
+	return result, nil
+}
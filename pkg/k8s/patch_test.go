@@ -0,0 +1,87 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests generic patching via the dynamic client.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// TestParsePatchType verifies conversion of user-facing patch type names.
+func TestParsePatchType(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    types.PatchType
+		wantErr bool
+	}{
+		{PatchTypeStrategic, types.StrategicMergePatchType, false},
+		{PatchTypeMerge, types.MergePatchType, false},
+		{PatchTypeJSON, types.JSONPatchType, false},
+		{"apply", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePatchType(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePatchType(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePatchType(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPatch verifies that patches are applied and that the authorization hook is honored.
+func TestPatch(t *testing.T) {
+	deploymentsGVR, err := LookupResource("deployments")
+	if err != nil {
+		t.Fatalf("LookupResource() error = %v", err)
+	}
+	patch := []byte(`{"metadata":{"labels":{"tier":"web"}}}`)
+
+	t.Run("merge patch applied", func(t *testing.T) {
+		deployment := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 1, []string{testImageNginx})
+		client := setupTestClient(zerolog.New(os.Stderr), []runtime.Object{deployment}, false)
+
+		patched, err := client.Patch(context.Background(), deploymentsGVR.GVR, testNamespaceDefault,
+			testDeploymentNginx, types.MergePatchType, patch)
+		if err != nil {
+			t.Fatalf("Patch() error = %v", err)
+		}
+		if patched.GetLabels()["tier"] != "web" {
+			t.Errorf("expected label tier=web, got %v", patched.GetLabels())
+		}
+	})
+
+	t.Run("denied by hook", func(t *testing.T) {
+		deployment := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 1, []string{testImageNginx})
+		client := setupTestClient(zerolog.New(os.Stderr), []runtime.Object{deployment}, false)
+		client.SetAuthorizer(denyAuthorizer{})
+
+		_, err := client.Patch(context.Background(), deploymentsGVR.GVR, testNamespaceDefault,
+			testDeploymentNginx, types.MergePatchType, patch)
+		if !errors.Is(err, authz.ErrDenied) {
+			t.Errorf("expected ErrDenied, got %v", err)
+		}
+	})
+
+	t.Run("object not found", func(t *testing.T) {
+		client := setupTestClient(zerolog.New(os.Stderr), nil, false)
+
+		_, err := client.Patch(context.Background(), deploymentsGVR.GVR, testNamespaceDefault,
+			"missing", types.MergePatchType, patch)
+		if err == nil {
+			t.Error("expected error for missing object")
+		}
+	})
+}
@@ -0,0 +1,213 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements port forwarding to pods and the resolution of backing pods
+// for deployments and services.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// dialerFactory creates the upgrading dialer for a port-forward request URL.
+// It can be replaced in tests.
+type dialerFactory func(config *rest.Config, u *url.URL) (httpstream.Dialer, error)
+
+// PortForwardOptions holds options for forwarding local ports to a pod.
+type PortForwardOptions struct {
+	// Addresses are the local addresses to listen on. Defaults to localhost.
+	Addresses []string
+
+	// Out and ErrOut receive status messages of the forwarder. May be nil.
+	Out    io.Writer
+	ErrOut io.Writer
+
+	// Ready, if set, is closed once all local listeners are ready.
+	Ready chan struct{}
+}
+
+// PortForward forwards local ports to a pod. Each port is given as "LOCAL:REMOTE",
+// "PORT" (same local and remote port) or ":REMOTE" (random local port).
+// It blocks until ctx is cancelled or the connection to the pod is lost.
+func (c *Client) PortForward(ctx context.Context, ns, pod string, ports []string, opts PortForwardOptions) error {
+	if len(ports) == 0 {
+		return fmt.Errorf("no ports specified")
+	}
+
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "port-forward",
+		Resource:  "pods",
+		Namespace: ns,
+		Name:      pod,
+		Details:   map[string]string{"ports": strings.Join(ports, ",")},
+	}); err != nil {
+		return err
+	}
+
+	base, err := url.Parse(c.config.Host)
+	if err != nil {
+		return fmt.Errorf("invalid API server host %q: %w", c.config.Host, err)
+	}
+
+	newDialer := c.newDialer
+	if newDialer == nil {
+		newDialer = newSPDYDialer
+	}
+	dialer, err := newDialer(c.config, base.JoinPath("api", "v1", "namespaces", ns, "pods", pod, "portforward"))
+	if err != nil {
+		return fmt.Errorf("failed to create port-forward dialer: %w", err)
+	}
+
+	addresses := opts.Addresses
+	if len(addresses) == 0 {
+		addresses = []string{"localhost"}
+	}
+	ready := opts.Ready
+	if ready == nil {
+		ready = make(chan struct{})
+	}
+
+	stop := make(chan struct{})
+	defer context.AfterFunc(ctx, func() { close(stop) })()
+
+	forwarder, err := portforward.NewOnAddresses(dialer, addresses, ports, stop, ready, opts.Out, opts.ErrOut)
+	if err != nil {
+		return fmt.Errorf("failed to set up port forwarding: %w", err)
+	}
+
+	c.logger.Debug().Str("pod", pod).Strs("ports", ports).Msg("Forwarding ports to pod")
+	if err := forwarder.ForwardPorts(); err != nil {
+		return fmt.Errorf("port forwarding to pod %q failed: %w", pod, err)
+	}
+	return nil
+}
+
+// newSPDYDialer creates an SPDY upgrading dialer for the given URL.
+func newSPDYDialer(config *rest.Config, u *url.URL) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, u), nil
+}
+
+// RunningPodForDeployment returns a running pod selected by the deployment's label selector.
+func (c *Client) RunningPodForDeployment(ctx context.Context, ns, name string) (*corev1.Pod, error) {
+	deployment, err := c.clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment %q: %w", name, err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %q: %w", name, err)
+	}
+	return c.firstRunningPod(ctx, ns, selector, "deployment/"+name)
+}
+
+// RunningPodForService returns the service and a running pod selected by its selector.
+func (c *Client) RunningPodForService(ctx context.Context, ns, name string) (*corev1.Service, *corev1.Pod, error) {
+	service, err := c.clientset.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get service %q: %w", name, err)
+	}
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil, fmt.Errorf("service %q has no selector", name)
+	}
+
+	pod, err := c.firstRunningPod(ctx, ns, labels.SelectorFromSet(service.Spec.Selector), "service/"+name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return service, pod, nil
+}
+
+// firstRunningPod returns the running pod with the lowest name matching the selector.
+// Pods that are being deleted are skipped.
+func (c *Client) firstRunningPod(
+	ctx context.Context, ns string, selector labels.Selector, owner string,
+) (*corev1.Pod, error) {
+	pods, err := c.clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of %s: %w", owner, err)
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no running pods", owner)
+}
+
+// TranslateServicePorts maps port specs addressing service ports to the matching
+// target ports of the pod. "8080:80" becomes "8080:<targetPort of service port 80>".
+// Named target ports are resolved against the pod's container ports.
+func TranslateServicePorts(service *corev1.Service, pod *corev1.Pod, ports []string) ([]string, error) {
+	translated := make([]string, 0, len(ports))
+	for _, spec := range ports {
+		local, remote, found := strings.Cut(spec, ":")
+		if !found {
+			local, remote = spec, spec
+		}
+
+		port, err := strconv.ParseInt(remote, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", remote, err)
+		}
+
+		target, err := serviceTargetPort(service, pod, int32(port))
+		if err != nil {
+			return nil, err
+		}
+		translated = append(translated, fmt.Sprintf("%s:%d", local, target))
+	}
+	return translated, nil
+}
+
+// serviceTargetPort returns the pod port that backs the given service port.
+func serviceTargetPort(service *corev1.Service, pod *corev1.Pod, port int32) (int32, error) {
+	for _, sp := range service.Spec.Ports {
+		if sp.Port != port {
+			continue
+		}
+		switch {
+		case sp.TargetPort.StrVal != "":
+			return namedContainerPort(pod, sp.TargetPort.StrVal)
+		case sp.TargetPort.IntVal != 0:
+			return sp.TargetPort.IntVal, nil
+		default:
+			return sp.Port, nil
+		}
+	}
+	return 0, fmt.Errorf("service %q does not expose port %d", service.Name, port)
+}
+
+// namedContainerPort looks up a named container port of the pod.
+func namedContainerPort(pod *corev1.Pod, name string) (int32, error) {
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == name {
+				return p.ContainerPort, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %q has no container port named %q", pod.Name, name)
+}
@@ -0,0 +1,153 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests port forwarding and the resolution of backing pods.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/rest"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// errDialFailed is returned by failingDialer.
+var errDialFailed = errors.New("dial failed")
+
+// failingDialer is an httpstream.Dialer whose connections always fail.
+type failingDialer struct{}
+
+// Dial always returns errDialFailed.
+func (failingDialer) Dial(_ ...string) (httpstream.Connection, string, error) {
+	return nil, "", errDialFailed
+}
+
+// newPortForwardObjects returns a deployment and a service selecting two pods, one of them running.
+func newPortForwardObjects() (*appsv1.Deployment, *corev1.Service, []*corev1.Pod) {
+	podLabels := map[string]string{"app": "web"}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault},
+		Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: podLabels}},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault},
+		Spec: corev1.ServiceSpec{
+			Selector: podLabels,
+			Ports: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromString("http")},
+				{Port: 443, TargetPort: intstr.FromInt32(8443)},
+				{Port: 9090},
+			},
+		},
+	}
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespaceDefault, Labels: podLabels},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "app",
+				Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			}}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	return deployment, service, []*corev1.Pod{pod("web-a", corev1.PodPending), pod("web-b", corev1.PodRunning)}
+}
+
+// TestRunningPodResolution verifies that deployments and services resolve to a running pod.
+func TestRunningPodResolution(t *testing.T) {
+	deployment, service, pods := newPortForwardObjects()
+	client := NewFakeClient(zerolog.Nop(), deployment, service, pods[0], pods[1])
+	ctx := context.Background()
+
+	pod, err := client.RunningPodForDeployment(ctx, testNamespaceDefault, "web")
+	if err != nil || pod.Name != "web-b" {
+		t.Errorf("RunningPodForDeployment() = %v, %v; want web-b", pod, err)
+	}
+
+	svc, pod, err := client.RunningPodForService(ctx, testNamespaceDefault, "web")
+	if err != nil || svc.Name != "web" || pod.Name != "web-b" {
+		t.Errorf("RunningPodForService() = %v, %v, %v; want web-b", svc, pod, err)
+	}
+
+	if _, err := client.RunningPodForDeployment(ctx, testNamespaceDefault, "missing"); err == nil {
+		t.Error("expected error for missing deployment")
+	}
+
+	idle := NewFakeClient(zerolog.Nop(), deployment, pods[0])
+	if _, err := idle.RunningPodForDeployment(ctx, testNamespaceDefault, "web"); err == nil {
+		t.Error("expected error when no pod is running")
+	}
+}
+
+// TestTranslateServicePorts verifies mapping of service ports to pod target ports.
+func TestTranslateServicePorts(t *testing.T) {
+	_, service, pods := newPortForwardObjects()
+
+	tests := []struct {
+		name    string
+		ports   []string
+		want    string
+		wantErr bool
+	}{
+		{"named target port", []string{"8080:80"}, "8080:8080", false},
+		{"numeric target port", []string{"443"}, "443:8443", false},
+		{"target port defaults to port", []string{":9090"}, ":9090", false},
+		{"unknown service port", []string{"8080:81"}, "", true},
+		{"invalid port", []string{"8080:http"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TranslateServicePorts(service, pods[1], tt.ports)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TranslateServicePorts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Join(got, ",") != tt.want {
+				t.Errorf("TranslateServicePorts() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPortForward verifies the port-forward URL, dialer errors and the authorization hook.
+func TestPortForward(t *testing.T) {
+	var requested *url.URL
+	client := setupTestClient(zerolog.Nop(), nil, false)
+	client.newDialer = func(_ *rest.Config, u *url.URL) (httpstream.Dialer, error) {
+		requested = u
+		return failingDialer{}, nil
+	}
+	ctx := context.Background()
+
+	err := client.PortForward(ctx, testNamespaceDefault, "web-b", []string{"8080:80"}, PortForwardOptions{})
+	if err == nil || !strings.Contains(err.Error(), errDialFailed.Error()) {
+		t.Errorf("expected dial error, got %v", err)
+	}
+	if requested == nil || !strings.HasSuffix(requested.Path, "/namespaces/default/pods/web-b/portforward") {
+		t.Errorf("unexpected port-forward URL %v", requested)
+	}
+
+	if err := client.PortForward(ctx, testNamespaceDefault, "web-b", nil, PortForwardOptions{}); err == nil {
+		t.Error("expected error for empty port list")
+	}
+
+	err = client.PortForward(ctx, testNamespaceDefault, "web-b", []string{"notaport"}, PortForwardOptions{})
+	if err == nil || strings.Contains(err.Error(), errDialFailed.Error()) {
+		t.Errorf("expected port parsing error, got %v", err)
+	}
+
+	client.SetAuthorizer(denyAuthorizer{})
+	err = client.PortForward(ctx, testNamespaceDefault, "web-b", []string{"8080:80"}, PortForwardOptions{})
+	if !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("expected ErrDenied, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements the safety preflight run before restarting or scaling down a deployment.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Preflight finding severities.
+const (
+	SeverityOK      = "ok"
+	SeverityWarning = "warning"
+	SeverityBlocker = "blocker"
+)
+
+// Operations checked by the preflight.
+const (
+	OperationRestart = "restart"
+	OperationScale   = "scale"
+)
+
+// PreflightFinding is the outcome of one preflight check.
+type PreflightFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// PreflightReport lists the findings of a preflight for one operation on a deployment.
+type PreflightReport struct {
+	Operation string             `json:"operation"`
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	Current   int32              `json:"current"`
+	Target    int32              `json:"target"`
+	Findings  []PreflightFinding `json:"findings"`
+}
+
+// Safe reports whether no finding blocks the operation.
+func (r PreflightReport) Safe() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityBlocker {
+			return false
+		}
+	}
+	return true
+}
+
+// add records a finding.
+func (r *PreflightReport) add(check, severity, format string, args ...any) {
+	r.Findings = append(r.Findings, PreflightFinding{
+		Check: check, Severity: severity, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// PreflightDeployment checks whether restarting a deployment, or scaling it to target replicas, is safe.
+// It checks current availability, PodDisruptionBudgets covering the deployment's pods and
+// HorizontalPodAutoscalers targeting it. target is ignored for restarts.
+func (c *Client) PreflightDeployment(ctx context.Context, ns, name, operation string,
+	target int32) (PreflightReport, error) {
+	deployment, err := c.clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return PreflightReport{}, fmt.Errorf("failed to get deployment %q: %w", name, err)
+	}
+
+	report := PreflightReport{Operation: operation, Namespace: ns, Name: name, Current: desiredReplicas(deployment)}
+	report.Target = report.Current
+	if operation == OperationScale {
+		report.Target = target
+	}
+
+	checkAvailability(&report, deployment)
+	if err := c.checkDisruptionBudgets(ctx, &report, deployment); err != nil {
+		return PreflightReport{}, err
+	}
+	if err := c.checkAutoscalers(ctx, &report); err != nil {
+		return PreflightReport{}, err
+	}
+	return report, nil
+}
+
+// desiredReplicas returns the desired replica count of a deployment, which defaults to 1.
+func desiredReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
+
+// checkAvailability blocks restarts of degraded or Recreate deployments and warns about scaling to zero.
+func checkAvailability(report *PreflightReport, deployment *appsv1.Deployment) {
+	available := deployment.Status.AvailableReplicas
+	switch {
+	case available < report.Current:
+		report.add("availability", SeverityBlocker, "only %d/%d replicas are available", available, report.Current)
+	default:
+		report.add("availability", SeverityOK, "%d/%d replicas are available", available, report.Current)
+	}
+
+	if report.Operation == OperationRestart && deployment.Spec.Strategy.Type == appsv1.RecreateDeploymentStrategyType {
+		report.add("strategy", SeverityBlocker, "Recreate strategy stops all pods before starting new ones")
+	}
+	if report.Operation == OperationScale && report.Target == 0 {
+		report.add("replicas", SeverityWarning, "scaling to zero stops all pods")
+	}
+}
+
+// checkDisruptionBudgets compares the PodDisruptionBudgets selecting the deployment's pods
+// with the availability the operation leaves.
+func (c *Client) checkDisruptionBudgets(ctx context.Context, report *PreflightReport,
+	deployment *appsv1.Deployment) error {
+	pdbs, err := c.clientset.PolicyV1().PodDisruptionBudgets(report.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pod disruption budgets: %w", err)
+	}
+
+	podLabels := labels.Set(deployment.Spec.Template.Labels)
+	matched := false
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(podLabels) {
+			continue
+		}
+		matched = true
+		checkDisruptionBudget(report, pdb)
+	}
+	if !matched {
+		report.add("pdb", SeverityOK, "no PodDisruptionBudget covers the pods")
+	}
+	return nil
+}
+
+// checkDisruptionBudget checks one PodDisruptionBudget covering the deployment's pods.
+// Scaling below minAvailable makes the budget unsatisfiable and blocks node drains;
+// restarting while no disruption is allowed leaves no headroom for the rollout.
+func checkDisruptionBudget(report *PreflightReport, pdb *policyv1.PodDisruptionBudget) {
+	if report.Operation == OperationRestart {
+		if pdb.Status.DisruptionsAllowed == 0 {
+			report.add("pdb", SeverityBlocker, "%s allows no disruptions right now", pdb.Name)
+		} else {
+			report.add("pdb", SeverityOK, "%s allows %d disruptions", pdb.Name, pdb.Status.DisruptionsAllowed)
+		}
+		return
+	}
+
+	if pdb.Spec.MinAvailable == nil {
+		report.add("pdb", SeverityOK, "%s limits unavailable pods only", pdb.Name)
+		return
+	}
+	minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, int(report.Target), true)
+	if err != nil {
+		report.add("pdb", SeverityWarning, "%s has an invalid minAvailable: %v", pdb.Name, err)
+		return
+	}
+	if int(report.Target) < minAvailable {
+		report.add("pdb", SeverityBlocker, "%s requires %d available pods, %d would remain",
+			pdb.Name, minAvailable, report.Target)
+		return
+	}
+	report.add("pdb", SeverityOK, "%s requires %d available pods", pdb.Name, minAvailable)
+}
+
+// checkAutoscalers reports HorizontalPodAutoscalers targeting the deployment. A manual scale
+// of an autoscaled deployment is overridden by the autoscaler, so it is blocked.
+func (c *Client) checkAutoscalers(ctx context.Context, report *PreflightReport) error {
+	hpas, err := c.clientset.AutoscalingV2().HorizontalPodAutoscalers(report.Namespace).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list horizontal pod autoscalers: %w", err)
+	}
+
+	for _, hpa := range hpas.Items {
+		if !targetsDeployment(&hpa, report.Name) {
+			continue
+		}
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		if report.Operation == OperationScale {
+			report.add("hpa", SeverityBlocker, "%s manages replicas (%d-%d) and will override a manual scale",
+				hpa.Name, minReplicas, hpa.Spec.MaxReplicas)
+		} else {
+			report.add("hpa", SeverityOK, "%s manages replicas (%d-%d)", hpa.Name, minReplicas, hpa.Spec.MaxReplicas)
+		}
+		return nil
+	}
+	report.add("hpa", SeverityOK, "no HorizontalPodAutoscaler targets the deployment")
+	return nil
+}
+
+// targetsDeployment reports whether an autoscaler scales the named deployment.
+func targetsDeployment(hpa *autoscalingv2.HorizontalPodAutoscaler, name string) bool {
+	ref := hpa.Spec.ScaleTargetRef
+	return ref.Kind == "Deployment" && ref.Name == name
+}
@@ -0,0 +1,111 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests the restart and scale-down preflight.
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// newPreflightDeployment builds the test deployment with 3 desired and the given available replicas.
+func newPreflightDeployment(available int32) *appsv1.Deployment {
+	deployment := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx})
+	deployment.Spec.Template.Labels = map[string]string{"app": testDeploymentNginx}
+	deployment.Status.AvailableReplicas = available
+	return deployment
+}
+
+// newTestPDB builds a PodDisruptionBudget for the test deployment's pods.
+func newTestPDB(minAvailable, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+	value := intstr.FromInt32(minAvailable)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-pdb", Namespace: testNamespaceDefault},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &value,
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": testDeploymentNginx}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+	}
+}
+
+// newTestHPA builds an autoscaler targeting the test deployment.
+func newTestHPA() *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-hpa", Namespace: testNamespaceDefault},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: testDeploymentNginx},
+			MaxReplicas:    10,
+		},
+	}
+}
+
+// TestPreflightDeployment verifies the blocking and passing checks of restarts and scale-downs.
+func TestPreflightDeployment(t *testing.T) {
+	tests := []struct {
+		name       string
+		objects    []runtime.Object
+		operation  string
+		target     int32
+		wantSafe   bool
+		wantChecks []string
+	}{
+		{"healthy restart", []runtime.Object{newPreflightDeployment(3)}, OperationRestart, 0, true, nil},
+		{"degraded restart", []runtime.Object{newPreflightDeployment(1)}, OperationRestart, 0, false,
+			[]string{"availability"}},
+		{"restart without disruption budget headroom", []runtime.Object{newPreflightDeployment(3), newTestPDB(3, 0)},
+			OperationRestart, 0, false, []string{"pdb"}},
+		{"scale within budget", []runtime.Object{newPreflightDeployment(3), newTestPDB(2, 1)},
+			OperationScale, 2, true, nil},
+		{"scale below budget", []runtime.Object{newPreflightDeployment(3), newTestPDB(2, 1)},
+			OperationScale, 1, false, []string{"pdb"}},
+		{"scale autoscaled", []runtime.Object{newPreflightDeployment(3), newTestHPA()},
+			OperationScale, 2, false, []string{"hpa"}},
+		{"restart autoscaled", []runtime.Object{newPreflightDeployment(3), newTestHPA()},
+			OperationRestart, 0, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewFakeClient(zerolog.Nop(), tt.objects...)
+			report, err := client.PreflightDeployment(context.Background(), testNamespaceDefault,
+				testDeploymentNginx, tt.operation, tt.target)
+			if err != nil {
+				t.Fatalf("PreflightDeployment() error = %v", err)
+			}
+			if report.Safe() != tt.wantSafe {
+				t.Errorf("Safe() = %v, want %v; findings %+v", report.Safe(), tt.wantSafe, report.Findings)
+			}
+
+			var blocked []string
+			for _, f := range report.Findings {
+				if f.Severity == SeverityBlocker {
+					blocked = append(blocked, f.Check)
+				}
+			}
+			if len(blocked) != len(tt.wantChecks) || (len(blocked) > 0 && blocked[0] != tt.wantChecks[0]) {
+				t.Errorf("expected blockers %v, got %v", tt.wantChecks, blocked)
+			}
+		})
+	}
+}
+
+// TestPreflightRecreateStrategy verifies that restarting a Recreate deployment is blocked.
+func TestPreflightRecreateStrategy(t *testing.T) {
+	deployment := newPreflightDeployment(3)
+	deployment.Spec.Strategy.Type = appsv1.RecreateDeploymentStrategyType
+	client := NewFakeClient(zerolog.Nop(), deployment)
+
+	report, err := client.PreflightDeployment(context.Background(), testNamespaceDefault, testDeploymentNginx,
+		OperationRestart, 0)
+	if err != nil || report.Safe() {
+		t.Errorf("expected the restart to be blocked, got %+v (%v)", report.Findings, err)
+	}
+}
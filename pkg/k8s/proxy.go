@@ -0,0 +1,43 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements routing API server connections through an HTTP or SOCKS proxy.
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/rest"
+)
+
+// proxySchemes are the proxy URL schemes supported by the HTTP transport.
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// parseProxyURL parses and checks a proxy URL, e.g. "http://proxy.corp:3128" or "socks5://127.0.0.1:1080".
+// It returns nil for an empty URL.
+func parseProxyURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if !proxySchemes[proxyURL.Scheme] || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: expected http, https, socks5 or socks5h://HOST:PORT", raw)
+	}
+	return proxyURL, nil
+}
+
+// applyProxy routes all API requests, including exec, attach and port-forward streams, through the
+// configured proxy. It leaves the proxy-url of the kubeconfig and the proxy environment variables
+// in effect when no proxy is configured.
+func applyProxy(restConfig *rest.Config, config ClientConfig, logger zerolog.Logger) {
+	proxyURL, err := parseProxyURL(config.ProxyURL)
+	if err != nil || proxyURL == nil {
+		return
+	}
+	restConfig.Proxy = http.ProxyURL(proxyURL)
+	logger.Info().Str("proxy", proxyURL.Redacted()).Msg("Connecting through proxy")
+}
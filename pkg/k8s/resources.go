@@ -0,0 +1,93 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file maps user-facing resource names and aliases to API group/version/resource identifiers.
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceInfo describes a resource type known to the client.
+type ResourceInfo struct {
+	// GVR is the group/version/resource used for API calls.
+	GVR schema.GroupVersionResource
+
+	// Kind is the object kind (e.g. "Deployment").
+	Kind string
+
+	// Namespaced reports whether objects of this resource live in a namespace.
+	Namespaced bool
+}
+
+// QualifiedName returns the kubectl-style resource name used in messages, e.g. "deployment.apps".
+func (r ResourceInfo) QualifiedName() string {
+	name := strings.ToLower(r.Kind)
+	if r.GVR.Group == "" {
+		return name
+	}
+	return name + "." + r.GVR.Group
+}
+
+// resourceEntry pairs a resource with its accepted aliases.
+// The first alias of each entry is the canonical plural resource name.
+type resourceEntry struct {
+	info    ResourceInfo
+	aliases []string
+}
+
+// newResourceEntry builds a resourceEntry; aliases[0] is used as the plural resource name.
+func newResourceEntry(group, version, kind string, namespaced bool, aliases ...string) resourceEntry {
+	return resourceEntry{
+		info: ResourceInfo{
+			GVR:        schema.GroupVersionResource{Group: group, Version: version, Resource: aliases[0]},
+			Kind:       kind,
+			Namespaced: namespaced,
+		},
+		aliases: aliases,
+	}
+}
+
+// knownResources lists built-in resources together with their accepted aliases.
+var knownResources = []resourceEntry{
+	newResourceEntry("apps", "v1", "Deployment", true, "deployments", "deployment", "deploy"),
+	newResourceEntry("apps", "v1", "StatefulSet", true, "statefulsets", "statefulset", "sts"),
+	newResourceEntry("apps", "v1", "DaemonSet", true, "daemonsets", "daemonset", "ds"),
+	newResourceEntry("apps", "v1", "ReplicaSet", true, "replicasets", "replicaset", "rs"),
+	newResourceEntry("", "v1", "Pod", true, "pods", "pod", "po"),
+	newResourceEntry("", "v1", "Service", true, "services", "service", "svc"),
+	newResourceEntry("", "v1", "ConfigMap", true, "configmaps", "configmap", "cm"),
+	newResourceEntry("", "v1", "Secret", true, "secrets", "secret"),
+	newResourceEntry("", "v1", "ServiceAccount", true, "serviceaccounts", "serviceaccount", "sa"),
+	newResourceEntry("", "v1", "PersistentVolumeClaim", true,
+		"persistentvolumeclaims", "persistentvolumeclaim", "pvc"),
+	newResourceEntry("", "v1", "Namespace", false, "namespaces", "namespace", "ns"),
+	newResourceEntry("", "v1", "Node", false, "nodes", "node", "no"),
+	newResourceEntry("batch", "v1", "Job", true, "jobs", "job"),
+	newResourceEntry("batch", "v1", "CronJob", true, "cronjobs", "cronjob", "cj"),
+	newResourceEntry("networking.k8s.io", "v1", "Ingress", true, "ingresses", "ingress", "ing"),
+}
+
+// LookupResource resolves a resource name or alias (e.g. "deploy", "svc", "pods") to its ResourceInfo.
+// Names may be qualified with their API group, e.g. "deployment.apps". Lookups are case-insensitive.
+func LookupResource(name string) (ResourceInfo, error) {
+	lower := strings.ToLower(name)
+	for _, entry := range knownResources {
+		for _, alias := range entry.aliases {
+			if alias == lower || (entry.info.GVR.Group != "" && alias+"."+entry.info.GVR.Group == lower) {
+				return entry.info, nil
+			}
+		}
+	}
+	return ResourceInfo{}, fmt.Errorf("unknown resource type %q", name)
+}
+
+// ParseResourceRef splits a "type/name" reference (e.g. "deployment/nginx") into its parts.
+func ParseResourceRef(ref string) (resource, name string, err error) {
+	resource, name, found := strings.Cut(ref, "/")
+	if !found || resource == "" || name == "" {
+		return "", "", fmt.Errorf("invalid resource reference %q, expected TYPE/NAME", ref)
+	}
+	return resource, name, nil
+}
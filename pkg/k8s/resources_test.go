@@ -0,0 +1,71 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests resource alias resolution and resource reference parsing.
+package k8s
+
+import (
+	"testing"
+)
+
+// TestLookupResource verifies alias resolution for built-in resources.
+func TestLookupResource(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantResource  string
+		wantQualified string
+		wantErr       bool
+	}{
+		{"plural", "deployments", "deployments", "deployment.apps", false},
+		{"singular", "deployment", "deployments", "deployment.apps", false},
+		{"short alias", "deploy", "deployments", "deployment.apps", false},
+		{"group qualified", "deployment.apps", "deployments", "deployment.apps", false},
+		{"core group", "svc", "services", "service", false},
+		{"case insensitive", "Pods", "pods", "pod", false},
+		{"cluster scoped", "ns", "namespaces", "namespace", false},
+		{"unknown", "widgets", "", "", true},
+		{"wrong group", "deployment.batch", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := LookupResource(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupResource(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if info.GVR.Resource != tt.wantResource {
+				t.Errorf("expected resource %q, got %q", tt.wantResource, info.GVR.Resource)
+			}
+			if !tt.wantErr && info.QualifiedName() != tt.wantQualified {
+				t.Errorf("expected qualified name %q, got %q", tt.wantQualified, info.QualifiedName())
+			}
+		})
+	}
+}
+
+// TestParseResourceRef verifies parsing of TYPE/NAME references.
+func TestParseResourceRef(t *testing.T) {
+	tests := []struct {
+		name         string
+		ref          string
+		wantResource string
+		wantName     string
+		wantErr      bool
+	}{
+		{"valid", "deployment/nginx", "deployment", "nginx", false},
+		{"missing name", "deployment/", "", "", true},
+		{"missing slash", "nginx", "", "", true},
+		{"missing type", "/nginx", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, name, err := ParseResourceRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResourceRef(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if resource != tt.wantResource || name != tt.wantName {
+				t.Errorf("ParseResourceRef(%q) = %q, %q", tt.ref, resource, name)
+			}
+		})
+	}
+}
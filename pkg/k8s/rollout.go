@@ -0,0 +1,76 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements scaling and rolling restarts of deployments.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// restartedAtAnnotation is the pod template annotation kubectl sets to trigger a rolling restart.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// ScaleDeployment sets the desired replica count of a deployment.
+// In client dry runs, the deployment is only checked to exist.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) ScaleDeployment(ctx context.Context, ns, name string, replicas int32) error {
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "scale",
+		Resource:  "deployments",
+		Namespace: ns,
+		Name:      name,
+		Details:   map[string]string{"replicas": fmt.Sprint(replicas)},
+	}); err != nil {
+		return err
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	if _, err := c.patchDeployment(ctx, ns, name, types.MergePatchType, patch); err != nil {
+		return fmt.Errorf("failed to scale deployment %q: %w", name, err)
+	}
+
+	c.logger.Info().Str("namespace", ns).Str("name", name).Int32("replicas", replicas).Msg("Deployment scaled")
+	return nil
+}
+
+// RestartDeployment triggers a rolling restart of a deployment, the way 'kubectl rollout restart' does,
+// by stamping its pod template with the current time. In client dry runs, the deployment is only checked to exist.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) RestartDeployment(ctx context.Context, ns, name string) error {
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "restart",
+		Resource:  "deployments",
+		Namespace: ns,
+		Name:      name,
+	}); err != nil {
+		return err
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339)))
+	if _, err := c.patchDeployment(ctx, ns, name, types.StrategicMergePatchType, patch); err != nil {
+		return fmt.Errorf("failed to restart deployment %q: %w", name, err)
+	}
+
+	c.logger.Info().Str("namespace", ns).Str("name", name).Msg("Deployment restarted")
+	return nil
+}
+
+// patchDeployment patches a deployment in the client's dry run mode. In client dry runs,
+// the deployment is fetched instead, so a missing deployment is still reported.
+func (c *Client) patchDeployment(ctx context.Context, ns, name string, patchType types.PatchType,
+	data []byte) (*appsv1.Deployment, error) {
+	deployments := c.clientset.AppsV1().Deployments(ns)
+	if c.skipMutation(authz.Change{Operation: "patch", Resource: "deployments", Namespace: ns, Name: name}) {
+		return deployments.Get(ctx, name, metav1.GetOptions{})
+	}
+	return deployments.Patch(ctx, name, patchType, data,
+		metav1.PatchOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+}
@@ -0,0 +1,57 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests scaling and restarting deployments.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// TestScaleDeployment verifies that replicas are patched and the authorization hook is honored.
+func TestScaleDeployment(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(),
+		createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx}))
+	ctx := context.Background()
+
+	if err := client.ScaleDeployment(ctx, testNamespaceDefault, testDeploymentNginx, 1); err != nil {
+		t.Fatalf("ScaleDeployment() error = %v", err)
+	}
+	deployment, err := client.clientset.AppsV1().Deployments(testNamespaceDefault).
+		Get(ctx, testDeploymentNginx, metav1.GetOptions{})
+	if err != nil || *deployment.Spec.Replicas != 1 {
+		t.Errorf("expected 1 replica, got %v (%v)", deployment.Spec.Replicas, err)
+	}
+
+	client.SetAuthorizer(denyAuthorizer{})
+	err = client.ScaleDeployment(ctx, testNamespaceDefault, testDeploymentNginx, 0)
+	if !errors.Is(err, authz.ErrDenied) {
+		t.Errorf("expected a denied error, got %v", err)
+	}
+}
+
+// TestRestartDeployment verifies that the pod template is stamped with the restart time.
+func TestRestartDeployment(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(),
+		createTestDeployment(testDeploymentNginx, testNamespaceDefault, 3, []string{testImageNginx}))
+	ctx := context.Background()
+
+	if err := client.RestartDeployment(ctx, testNamespaceDefault, testDeploymentNginx); err != nil {
+		t.Fatalf("RestartDeployment() error = %v", err)
+	}
+	deployment, err := client.clientset.AppsV1().Deployments(testNamespaceDefault).
+		Get(ctx, testDeploymentNginx, metav1.GetOptions{})
+	if err != nil || deployment.Spec.Template.Annotations[restartedAtAnnotation] == "" {
+		t.Errorf("expected %s annotation, got %v (%v)", restartedAtAnnotation,
+			deployment.Spec.Template.Annotations, err)
+	}
+
+	if err := client.RestartDeployment(ctx, testNamespaceDefault, "missing"); err == nil {
+		t.Error("expected an error for a missing deployment")
+	}
+}
@@ -0,0 +1,153 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements proxying HTTP requests to in-cluster services through the API server.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// ServiceProxyRequest is an HTTP request to an in-cluster service, proxied through the services/proxy
+// subresource of the API server like kubectl proxy does.
+type ServiceProxyRequest struct {
+	// Method is the HTTP method, e.g. "GET".
+	Method string
+
+	// Namespace is the namespace of the service.
+	Namespace string
+
+	// Service names the service as the services/proxy subresource does: "name", "name:port" or
+	// "scheme:name:port", where port is a port name or number, e.g. "https:web:8443".
+	Service string
+
+	// Path is the path requested from the service, e.g. "metrics" or "/api/items/".
+	Path string
+
+	// RawQuery is the encoded query of the request, without "?".
+	RawQuery string
+
+	// Header holds the request headers. Hop-by-hop, authorization and impersonation headers are not
+	// forwarded, since the request is authenticated with the credentials of the client.
+	Header http.Header
+
+	// Body is the request body, if any.
+	Body []byte
+}
+
+// hopByHopHeaders are the headers describing a connection rather than the request or response,
+// which proxies must not forward (RFC 9110, section 7.6.1).
+var hopByHopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Proxy-Connection",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// ProxyService sends req to an in-cluster service through the API server, with the credentials of the
+// client, and returns the response of the service. The caller closes its body, which is read within ctx.
+// Redirects are returned rather than followed. Upgrades, e.g. to WebSocket, are not supported.
+func (c *Client) ProxyService(ctx context.Context, req ServiceProxyRequest) (*http.Response, error) {
+	if errs := validation.IsDNS1123Label(req.Namespace); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid namespace %q: %s", req.Namespace, strings.Join(errs, ", "))
+	}
+	if req.Service == "" || req.Service == "." || req.Service == ".." {
+		return nil, fmt.Errorf("invalid service %q", req.Service)
+	}
+	if c.config.Host == DemoHost {
+		return nil, fmt.Errorf("proxying to services is not supported in demo mode")
+	}
+
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "proxy",
+		Resource:  "services",
+		Namespace: req.Namespace,
+		Name:      req.Service,
+		Details:   map[string]string{"method": req.Method, "path": req.Path},
+	}); err != nil {
+		return nil, err
+	}
+
+	proxyURL, err := buildServiceProxyURL(c.config.Host, req.Namespace, req.Service, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	proxyURL.RawQuery = req.RawQuery
+
+	httpClient, err := rest.HTTPClientFor(c.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, proxyURL.String(), bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy request: %w", err)
+	}
+	httpReq.Header = proxiedHeader(req.Header)
+
+	c.logger.Debug().Str("method", req.Method).Str("namespace", req.Namespace).Str("service", req.Service).
+		Str("path", req.Path).Msg("Proxying request to service")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to proxy request to service %s/%s: %w", req.Namespace, req.Service, err)
+	}
+	removeHopByHopHeaders(resp.Header)
+	return resp, nil
+}
+
+// buildServiceProxyURL builds the URL of the services/proxy subresource for a path on the service.
+// The path is cleaned as a rooted path, so that it cannot escape the subresource, keeping a trailing slash.
+func buildServiceProxyURL(host, ns, service, servicePath string) (*url.URL, error) {
+	base, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API server host %q: %w", host, err)
+	}
+
+	cleaned := path.Clean("/" + servicePath)
+	if strings.HasSuffix(servicePath, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return base.JoinPath("api/v1/namespaces", ns, "services", service, "proxy", cleaned), nil
+}
+
+// proxiedHeader returns the headers of a request to forward to the API server: all but the hop-by-hop
+// headers and the ones selecting the credentials, which are the client's.
+func proxiedHeader(header http.Header) http.Header {
+	proxied := header.Clone()
+	if proxied == nil {
+		proxied = http.Header{}
+	}
+	removeHopByHopHeaders(proxied)
+	proxied.Del("Authorization")
+	proxied.Del("Host")
+	proxied.Del("Content-Length")
+	for name := range proxied {
+		if strings.HasPrefix(name, "Impersonate-") {
+			proxied.Del(name)
+		}
+	}
+	return proxied
+}
+
+// removeHopByHopHeaders removes the hop-by-hop headers from header, including the ones listed in its
+// Connection header.
+func removeHopByHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for name := range strings.SplitSeq(value, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
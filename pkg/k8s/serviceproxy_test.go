@@ -0,0 +1,138 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests proxying requests to in-cluster services through the API server.
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/rest"
+)
+
+// TestProxyService verifies that requests are sent to the services/proxy subresource with the credentials
+// of the client rather than the caller's, and that responses, including redirects, are returned as is.
+func TestProxyService(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Path", r.URL.EscapedPath())
+		w.Header().Set("X-Query", r.URL.RawQuery)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Authorization", r.Header.Get("Authorization"))
+		w.Header().Set("X-Impersonate", r.Header.Get("Impersonate-User"))
+		w.Header().Set("X-Custom", r.Header.Get("X-Custom"))
+		w.Header().Set("Keep-Alive", "timeout=5")
+		if r.URL.Path == "/api/v1/namespaces/shop/services/cart/proxy/old" {
+			w.Header().Set("Location", "/api/v1/namespaces/shop/services/cart/proxy/new")
+			w.WriteHeader(http.StatusFound)
+		}
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	client := &Client{config: &rest.Config{Host: srv.URL, BearerToken: "controller"}, logger: zerolog.Nop()}
+
+	tests := []struct {
+		name       string
+		req        ServiceProxyRequest
+		wantStatus int
+		wantPath   string
+		wantBody   string
+	}{
+		{"get with port", ServiceProxyRequest{Method: http.MethodGet, Namespace: "shop", Service: "cart:8080",
+			Path: "metrics", RawQuery: "format=text"}, http.StatusOK,
+			"/api/v1/namespaces/shop/services/cart:8080/proxy/metrics", ""},
+		{"post with body", ServiceProxyRequest{Method: http.MethodPost, Namespace: "shop", Service: "https:cart:web",
+			Path: "/api/items/", Body: []byte(`{"sku":"42"}`)}, http.StatusOK,
+			"/api/v1/namespaces/shop/services/https:cart:web/proxy/api/items/", `{"sku":"42"}`},
+		{"root", ServiceProxyRequest{Method: http.MethodGet, Namespace: "shop", Service: "cart"}, http.StatusOK,
+			"/api/v1/namespaces/shop/services/cart/proxy/", ""},
+		{"path traversal", ServiceProxyRequest{Method: http.MethodGet, Namespace: "shop", Service: "cart",
+			Path: "../../../secrets/db"}, http.StatusOK, "/api/v1/namespaces/shop/services/cart/proxy/secrets/db", ""},
+		{"redirect", ServiceProxyRequest{Method: http.MethodGet, Namespace: "shop", Service: "cart", Path: "old"},
+			http.StatusFound, "/api/v1/namespaces/shop/services/cart/proxy/old", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			tt.req.Header = http.Header{
+				"Authorization":    {"Bearer caller"},
+				"Impersonate-User": {"admin"},
+				"X-Custom":         {"kept"},
+				"Connection":       {"X-Custom"},
+			}
+			if tt.name != "redirect" {
+				tt.req.Header.Del("Connection")
+			}
+
+			resp, err := client.ProxyService(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("ProxyService() error = %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if got := resp.Header.Get("X-Path"); got != tt.wantPath {
+				t.Errorf("expected path %q, got %q", tt.wantPath, got)
+			}
+			if got := resp.Header.Get("X-Method"); got != tt.req.Method {
+				t.Errorf("expected method %q, got %q", tt.req.Method, got)
+			}
+			if got := resp.Header.Get("X-Query"); got != tt.req.RawQuery {
+				t.Errorf("expected query %q, got %q", tt.req.RawQuery, got)
+			}
+			if got := resp.Header.Get("X-Authorization"); got != "Bearer controller" {
+				t.Errorf("expected the client's credentials, got %q", got)
+			}
+			if got := resp.Header.Get("X-Impersonate"); got != "" {
+				t.Errorf("expected no impersonation, got %q", got)
+			}
+			// Headers listed in Connection are hop-by-hop.
+			if got, want := resp.Header.Get("X-Custom"), "kept"; (got == want) == (tt.name == "redirect") {
+				t.Errorf("unexpected X-Custom header %q", got)
+			}
+			if got := resp.Header.Get("Keep-Alive"); got != "" {
+				t.Errorf("expected no hop-by-hop headers in the response, got Keep-Alive %q", got)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, body)
+			}
+		})
+	}
+}
+
+// TestProxyServiceInvalid verifies that invalid targets and demo clients are rejected before any request.
+func TestProxyServiceInvalid(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop())
+
+	tests := []struct {
+		name    string
+		req     ServiceProxyRequest
+		wantErr string
+	}{
+		{"invalid namespace", ServiceProxyRequest{Method: http.MethodGet, Namespace: "..", Service: "cart"},
+			`invalid namespace ".."`},
+		{"invalid service", ServiceProxyRequest{Method: http.MethodGet, Namespace: "shop", Service: ".."},
+			`invalid service ".."`},
+		{"demo mode", ServiceProxyRequest{Method: http.MethodGet, Namespace: "shop", Service: "cart"},
+			"proxying to services is not supported in demo mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ProxyService(context.Background(), tt.req)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
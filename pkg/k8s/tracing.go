@@ -0,0 +1,95 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file records spans of the requests of a client to the API server.
+package k8s
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of the client.
+const tracerName = "github.com/Searge/k8s-controller/pkg/k8s"
+
+// apiTracer records a client span for each request to the API server, as a child of the span in the
+// context of the request, e.g. the span of the HTTP request the server is answering.
+type apiTracer struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// wrap returns a round tripper that records the requests it sends.
+func (t apiTracer) wrap(rt http.RoundTripper) http.RoundTripper {
+	return &tracingRoundTripper{tracer: t.provider.Tracer(tracerName), propagator: t.propagator, next: rt}
+}
+
+// tracingRoundTripper records the requests to the API server and passes their trace context on in the
+// traceparent header, so that API server tracing continues the trace. The span ends when the response
+// headers arrive, so it covers the start of watches and streams rather than their whole duration.
+type tracingRoundTripper struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	next       http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, namespace := apiRequestResource(req.URL.Path)
+	attributes := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Host),
+		attribute.String("url.path", req.URL.Path),
+	}
+	if namespace != "" {
+		attributes = append(attributes, attribute.String("k8s.namespace.name", namespace))
+	}
+	ctx, span := rt.tracer.Start(req.Context(), req.Method+" "+resource,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	defer span.End()
+
+	// A round tripper must not modify the request it was given.
+	req = req.Clone(ctx)
+	rt.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// apiRequestResource returns the resource of an API server path, with its subresource, e.g.
+// "deployments/scale" for /apis/apps/v1/namespaces/default/deployments/web/scale, and the namespace of
+// namespaced resources. Paths outside the resource APIs, e.g. /version, are returned as they are, so the
+// span names stay few: object names are never part of them.
+func apiRequestResource(path string) (resource, namespace string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var rest []string
+	switch {
+	case parts[0] == "api" && len(parts) > 2:
+		rest = parts[2:]
+	case parts[0] == "apis" && len(parts) > 3:
+		rest = parts[3:]
+	default:
+		return path, ""
+	}
+
+	if rest[0] == "namespaces" && len(rest) > 2 {
+		namespace, rest = rest[1], rest[2:]
+	}
+	resource = rest[0]
+	if len(rest) > 2 {
+		resource += "/" + rest[2]
+	}
+	return resource, namespace
+}
@@ -0,0 +1,136 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests the spans of the requests to the API server.
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestTracingRoundTripper verifies that requests to the API server are recorded as children of the span of
+// their context and carry its trace context to the API server.
+func TestTracingRoundTripper(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantError bool
+	}{
+		{"success", http.StatusOK, false},
+		{"not found", http.StatusNotFound, true},
+		{"server error", http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var traceparent string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				traceparent = r.Header.Get("traceparent")
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			client := &http.Client{Transport: apiTracer{provider: provider, propagator: propagation.TraceContext{}}.
+				wrap(http.DefaultTransport)}
+
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "GET /api/v1/deployments")
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+				srv.URL+"/apis/apps/v1/namespaces/shop/deployments/web", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			_ = resp.Body.Close()
+			parent.End()
+
+			if req.Header.Get("traceparent") != "" {
+				t.Error("expected the request of the caller to be left unchanged")
+			}
+			spans := recorder.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("expected the client and parent spans, got %d spans", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != "GET deployments" || span.SpanKind() != trace.SpanKindClient {
+				t.Errorf("unexpected span %s of kind %s", span.Name(), span.SpanKind())
+			}
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("expected the span to be a child of the request span")
+			}
+			want := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+			if traceparent != want {
+				t.Errorf("expected traceparent %s sent to the API server, got %s", want, traceparent)
+			}
+			if (span.Status().Code == codes.Error) != tt.wantError {
+				t.Errorf("unexpected span status %v", span.Status())
+			}
+		})
+	}
+}
+
+// TestTracingRoundTripperFailure verifies that failed connections are recorded as errors.
+func TestTracingRoundTripperFailure(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := &http.Client{Transport: apiTracer{provider: provider, propagator: propagation.TraceContext{}}.
+		wrap(http.DefaultTransport)}
+
+	if _, err := client.Get(srv.URL + "/version"); err == nil {
+		t.Fatal("expected the request to a closed server to fail")
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "GET /version" || spans[0].Status().Code != codes.Error {
+		t.Fatalf("expected a failed span, got %v", spans)
+	}
+	if len(spans[0].Events()) != 1 {
+		t.Errorf("expected the error recorded as event, got %v", spans[0].Events())
+	}
+}
+
+// TestAPIRequestResource verifies the resources and namespaces of API server paths.
+func TestAPIRequestResource(t *testing.T) {
+	tests := []struct {
+		path          string
+		wantResource  string
+		wantNamespace string
+	}{
+		{"/api/v1/pods", "pods", ""},
+		{"/api/v1/namespaces", "namespaces", ""},
+		{"/api/v1/namespaces/shop", "namespaces", ""},
+		{"/api/v1/namespaces/shop/pods", "pods", "shop"},
+		{"/api/v1/namespaces/shop/pods/web-1/log", "pods/log", "shop"},
+		{"/apis/apps/v1/namespaces/shop/deployments/web", "deployments", "shop"},
+		{"/apis/apps/v1/namespaces/shop/deployments/web/scale", "deployments/scale", "shop"},
+		{"/apis/apps/v1/deployments", "deployments", ""},
+		{"/api/v1/nodes/node-1", "nodes", ""},
+		{"/api/v1/namespaces/shop/services/web:80/proxy/healthz", "services/proxy", "shop"},
+		{"/apis/apps/v1", "/apis/apps/v1", ""},
+		{"/version", "/version", ""},
+		{"/", "/", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resource, namespace := apiRequestResource(tt.path)
+			if resource != tt.wantResource || namespace != tt.wantNamespace {
+				t.Errorf("apiRequestResource(%q) = %q, %q, want %q, %q",
+					tt.path, resource, namespace, tt.wantResource, tt.wantNamespace)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements reading and applying objects of arbitrary resources, including custom resources.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// GetUnstructured fetches the named object of the given resource.
+// The namespace is ignored for cluster-scoped resources.
+func (c *Client) GetUnstructured(ctx context.Context, gvr schema.GroupVersionResource,
+	ns, name string) (*unstructured.Unstructured, error) {
+	obj, err := c.dynamic.Resource(gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %q: %w", gvr.Resource, name, err)
+	}
+	return obj, nil
+}
+
+// ListUnstructured lists the objects of the given resource in ns, or in all namespaces if ns is empty,
+// filtered by the label and field selectors of opts. The list is fetched in pages of opts.Limit objects,
+// see List, and all pages are returned.
+func (c *Client) ListUnstructured(ctx context.Context, gvr schema.GroupVersionResource, ns string,
+	opts metav1.ListOptions) ([]unstructured.Unstructured, error) {
+	items, err := List[unstructured.Unstructured](ctx, c.dynamic.Resource(gvr).Namespace(ns).List, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+	}
+	return items, nil
+}
+
+// ListNames returns the sorted names of the objects of the given resource in ns, or in all namespaces
+// if ns is empty, e.g. for shell completion.
+func (c *Client) ListNames(ctx context.Context, gvr schema.GroupVersionResource, ns string) ([]string, error) {
+	items, err := c.ListUnstructured(ctx, gvr, ns, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.GetName())
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// ApplyUnstructured server-side applies obj as an object of the given resource, taking ownership of
+// conflicting fields, and returns the object as stored by the API server. Unlike Apply, the resource
+// is not looked up from the object's kind, so any resource the server serves can be applied;
+// the object's namespace is used as is.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) ApplyUnstructured(ctx context.Context, gvr schema.GroupVersionResource,
+	obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err := c.authorize(ctx, authz.Change{
+		Operation: "apply",
+		Resource:  gvr.Resource,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}); err != nil {
+		return nil, err
+	}
+	return c.applyUnstructured(ctx, gvr, obj)
+}
+
+// applyUnstructured server-side applies obj without consulting the authorization hook.
+// In client dry runs, obj is merged into the live object locally instead.
+func (c *Client) applyUnstructured(ctx context.Context, gvr schema.GroupVersionResource,
+	obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	resource := c.dynamic.Resource(gvr).Namespace(obj.GetNamespace())
+	if c.skipMutation(authz.Change{
+		Operation: "apply",
+		Resource:  gvr.Resource,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}) {
+		return mergeLive(ctx, resource, obj)
+	}
+
+	applied, err := resource.Apply(ctx, obj.GetName(), obj,
+		metav1.ApplyOptions{FieldManager: fieldManager, Force: true, DryRun: c.dryRunOption()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s %q: %w", gvr.Resource, obj.GetName(), err)
+	}
+	c.logger.Info().Str("resource", gvr.Resource).Str("namespace", obj.GetNamespace()).
+		Str("name", obj.GetName()).Msg("Resource applied")
+	return applied, nil
+}
+
+// mergeLive returns obj merged into the live object, or obj itself if there is none,
+// approximating the result of applying obj.
+func mergeLive(ctx context.Context, resource dynamic.ResourceInterface,
+	obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return obj.DeepCopy(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %q: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return &unstructured.Unstructured{Object: mergeApplied(live.Object, obj.DeepCopy().Object)}, nil
+}
@@ -0,0 +1,166 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests reading and applying objects of arbitrary resources.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// widgetGVR is a custom resource unknown to the client-go scheme.
+var widgetGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+// newWidget returns a widget custom resource with the given labels.
+func newWidget(ns, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"spec":       map[string]any{"size": int64(1)},
+	}}
+	obj.SetNamespace(ns)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+// newWidgetTestClient returns a fake client serving the widgets custom resource.
+func newWidgetTestClient(objects ...runtime.Object) *Client {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme,
+		map[schema.GroupVersionResource]string{widgetGVR: "WidgetList"}, objects...)
+	dynamicClient.PrependReactor("patch", "*", fakeApplyReactor(dynamicClient.Tracker()))
+	return &Client{
+		clientset: fake.NewSimpleClientset(),
+		dynamic:   dynamicClient,
+		config:    &rest.Config{Host: fakeServerURL},
+		logger:    zerolog.Nop(),
+	}
+}
+
+// TestGetUnstructured verifies fetching custom resources and reporting missing ones.
+func TestGetUnstructured(t *testing.T) {
+	client := newWidgetTestClient(newWidget("web", "small", nil))
+
+	obj, err := client.GetUnstructured(context.Background(), widgetGVR, "web", "small")
+	if err != nil {
+		t.Fatalf("GetUnstructured() error = %v", err)
+	}
+	if size, _, _ := unstructured.NestedInt64(obj.Object, "spec", "size"); obj.GetKind() != "Widget" || size != 1 {
+		t.Errorf("expected the widget with size 1, got %v", obj.Object)
+	}
+
+	_, err = client.GetUnstructured(context.Background(), widgetGVR, "web", "missing")
+	if err == nil || !strings.Contains(err.Error(), `widgets "missing"`) {
+		t.Errorf("expected a not found error for the missing widget, got %v", err)
+	}
+}
+
+// TestListUnstructured verifies listing custom resources by namespace and label selector.
+func TestListUnstructured(t *testing.T) {
+	client := newWidgetTestClient(
+		newWidget("web", "small", map[string]string{"tier": "web"}),
+		newWidget("web", "large", nil),
+		newWidget("api", "medium", map[string]string{"tier": "web"}),
+	)
+
+	tests := []struct {
+		name     string
+		ns       string
+		selector string
+		want     string
+	}{
+		{"all namespaces", "", "", "api/medium,web/large,web/small"},
+		{"namespace", "web", "", "web/large,web/small"},
+		{"label selector", "", "tier=web", "api/medium,web/small"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := client.ListUnstructured(context.Background(), widgetGVR, tt.ns,
+				metav1.ListOptions{LabelSelector: tt.selector})
+			if err != nil {
+				t.Fatalf("ListUnstructured() error = %v", err)
+			}
+			var keys []string
+			for _, item := range items {
+				keys = append(keys, item.GetNamespace()+"/"+item.GetName())
+			}
+			sort.Strings(keys)
+			if got := strings.Join(keys, ","); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestListNames verifies that names are returned sorted and without duplicates across namespaces.
+func TestListNames(t *testing.T) {
+	client := newWidgetTestClient(
+		newWidget("web", "small", nil),
+		newWidget("web", "large", nil),
+		newWidget("api", "small", nil),
+	)
+
+	tests := []struct {
+		ns   string
+		want string
+	}{
+		{"", "large,small"},
+		{"web", "large,small"},
+		{"api", "small"},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		names, err := client.ListNames(context.Background(), widgetGVR, tt.ns)
+		if err != nil {
+			t.Fatalf("ListNames(%q) error = %v", tt.ns, err)
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("ListNames(%q) = %s, want %s", tt.ns, got, tt.want)
+		}
+	}
+}
+
+// TestApplyUnstructured verifies applying custom resources and the authorization hook.
+func TestApplyUnstructured(t *testing.T) {
+	client := newWidgetTestClient(newWidget("web", "small", nil))
+
+	update := newWidget("web", "small", nil)
+	_ = unstructured.SetNestedField(update.Object, int64(3), "spec", "size")
+	applied, err := client.ApplyUnstructured(context.Background(), widgetGVR, update)
+	if err != nil {
+		t.Fatalf("ApplyUnstructured() error = %v", err)
+	}
+	// The fake apply decodes numbers as JSON does, so they are compared as text.
+	if size, _, _ := unstructured.NestedFieldNoCopy(applied.Object, "spec", "size"); fmt.Sprint(size) != "3" {
+		t.Errorf("expected the applied size 3, got %v", size)
+	}
+	if _, err := client.ApplyUnstructured(context.Background(), widgetGVR, newWidget("web", "new", nil)); err != nil {
+		t.Fatalf("ApplyUnstructured() of a new object error = %v", err)
+	}
+	if _, err := client.GetUnstructured(context.Background(), widgetGVR, "web", "new"); err != nil {
+		t.Errorf("expected the new widget to be created, got %v", err)
+	}
+
+	client.SetAuthorizer(denyAuthorizer{})
+	_, err = client.ApplyUnstructured(context.Background(), widgetGVR, newWidget("web", "denied", nil))
+	if err == nil {
+		t.Error("expected the apply to be denied")
+	}
+	if _, err := client.GetUnstructured(context.Background(), widgetGVR, "web", "denied"); err == nil {
+		t.Error("expected no object to be created")
+	}
+}
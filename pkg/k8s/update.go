@@ -0,0 +1,110 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements get-mutate-update loops that retry on resource version conflicts.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// DefaultUpdateBackoff is the backoff between attempts of a conflicting update.
+var DefaultUpdateBackoff = retry.DefaultRetry
+
+// MutateFunc changes an object in place and reports whether it changed anything.
+// Returning false skips the update.
+type MutateFunc[T any] func(obj T) (bool, error)
+
+// UpdateWithRetry fetches an object, mutates it and writes it back. If the update fails
+// because the object was modified concurrently, the object is fetched again and the
+// mutation is reapplied, with backoff, so concurrent writers don't cause spurious failures.
+// It returns the last object seen and whether an update was made.
+func UpdateWithRetry[T any](
+	ctx context.Context,
+	get func(context.Context) (T, error),
+	mutate MutateFunc[T],
+	update func(context.Context, T) (T, error),
+) (T, bool, error) {
+	var obj T
+	var changed bool
+	attempt := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		current, err := get(ctx)
+		if err != nil {
+			return err
+		}
+		obj, changed = current, false
+
+		ok, err := mutate(current)
+		if err != nil || !ok {
+			return err
+		}
+		updated, err := update(ctx, current)
+		if err != nil {
+			return err
+		}
+		obj, changed = updated, true
+		return nil
+	}
+
+	// OnError drops context errors, which it treats as an interrupted backoff, so the
+	// error of the last attempt is kept to avoid reporting a canceled update as a success.
+	var lastErr error
+	err := retry.OnError(DefaultUpdateBackoff, apierrors.IsConflict, func() error {
+		lastErr = attempt()
+		return lastErr
+	})
+	if err == nil {
+		err = lastErr
+	}
+	return obj, changed, err
+}
+
+// Update applies mutate to the named object of the given resource with UpdateWithRetry.
+// The namespace is ignored for cluster-scoped resources.
+// In client dry runs, the mutated object is returned without writing it.
+// The change is submitted to the authorization hook before the API call is made.
+func (c *Client) Update(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+	mutate MutateFunc[*unstructured.Unstructured]) (*unstructured.Unstructured, bool, error) {
+	change := authz.Change{
+		Operation: "update",
+		Resource:  gvr.Resource,
+		Namespace: ns,
+		Name:      name,
+	}
+	if err := c.authorize(ctx, change); err != nil {
+		return nil, false, err
+	}
+
+	skip := c.skipMutation(change)
+	resource := c.dynamic.Resource(gvr).Namespace(ns)
+	obj, changed, err := UpdateWithRetry(ctx,
+		func(ctx context.Context) (*unstructured.Unstructured, error) {
+			return resource.Get(ctx, name, metav1.GetOptions{})
+		},
+		mutate,
+		func(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			if skip {
+				return obj, nil
+			}
+			return resource.Update(ctx, obj, metav1.UpdateOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+		})
+	if err != nil {
+		c.logger.Error().Err(err).Str("name", name).Msg("Failed to update resource")
+		return nil, false, fmt.Errorf("failed to update %s %q: %w", gvr.Resource, name, err)
+	}
+
+	if changed {
+		c.logger.Info().Str("resource", gvr.Resource).Str("namespace", ns).Str("name", name).Msg("Resource updated")
+	}
+	return obj, changed, nil
+}
@@ -0,0 +1,129 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests conflict-retrying updates.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// testUpdateLabel is the label set by the update tests.
+const testUpdateLabel = "tier"
+
+// TestUpdateWithRetry verifies that conflicts are retried and other errors are returned.
+func TestUpdateWithRetry(t *testing.T) {
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "things"}, "a", errors.New("modified"))
+
+	tests := []struct {
+		name        string
+		failures    []error
+		noop        bool
+		wantGets    int
+		wantChanged bool
+		wantErr     bool
+	}{
+		{"first attempt", nil, false, 1, true, false},
+		{"conflicts then success", []error{conflict, conflict}, false, 3, true, false},
+		{"other error", []error{errors.New("boom")}, false, 1, false, true},
+		{"no change", nil, true, 1, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gets, updates := 0, 0
+			obj, changed, err := UpdateWithRetry(context.Background(),
+				func(context.Context) (int, error) {
+					gets++
+					return gets, nil
+				},
+				func(int) (bool, error) { return !tt.noop, nil },
+				func(_ context.Context, obj int) (int, error) {
+					updates++
+					if updates <= len(tt.failures) {
+						return 0, tt.failures[updates-1]
+					}
+					return obj * 10, nil
+				})
+			if (err != nil) != tt.wantErr || changed != tt.wantChanged {
+				t.Fatalf("UpdateWithRetry() changed = %v, error = %v", changed, err)
+			}
+			if gets != tt.wantGets {
+				t.Errorf("expected %d gets, got %d", tt.wantGets, gets)
+			}
+			if tt.wantChanged && obj != gets*10 {
+				t.Errorf("expected the object of the last update, got %d", obj)
+			}
+		})
+	}
+}
+
+// TestUpdateWithRetryCanceled verifies that a canceled context stops the loop.
+func TestUpdateWithRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := UpdateWithRetry(ctx,
+		func(context.Context) (int, error) { return 0, nil },
+		func(int) (bool, error) { return true, nil },
+		func(_ context.Context, obj int) (int, error) { return obj, nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// TestClientUpdate verifies dynamic updates and that the authorization hook is honored.
+func TestClientUpdate(t *testing.T) {
+	deployments, err := LookupResource("deployments")
+	if err != nil {
+		t.Fatalf("LookupResource() error = %v", err)
+	}
+	setTier := func(obj *unstructured.Unstructured) (bool, error) {
+		labels := obj.GetLabels()
+		if labels[testUpdateLabel] == "web" {
+			return false, nil
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[testUpdateLabel] = "web"
+		obj.SetLabels(labels)
+		return true, nil
+	}
+
+	tests := []struct {
+		name        string
+		authorizer  authz.Authorizer
+		target      string
+		wantChanged bool
+		wantErr     bool
+	}{
+		{"updated", nil, testDeploymentNginx, true, false},
+		{"missing object", nil, "missing", false, true},
+		{"denied", denyAuthorizer{}, testDeploymentNginx, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 1, []string{testImageNginx})
+			client := NewFakeClient(zerolog.Nop(), deployment)
+			client.SetAuthorizer(tt.authorizer)
+
+			obj, changed, err := client.Update(context.Background(), deployments.GVR, testNamespaceDefault,
+				tt.target, setTier)
+			if (err != nil) != tt.wantErr || changed != tt.wantChanged {
+				t.Fatalf("Update() changed = %v, error = %v", changed, err)
+			}
+			if tt.wantChanged && obj.GetLabels()[testUpdateLabel] != "web" {
+				t.Errorf("expected label %s=web, got %v", testUpdateLabel, obj.GetLabels())
+			}
+		})
+	}
+}
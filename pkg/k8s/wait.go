@@ -0,0 +1,214 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements waiting for objects to reach a condition, be deleted or match a JSONPath.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// WaitPollInterval is how often WaitForCondition checks the object.
+const WaitPollInterval = time.Second
+
+// Kinds of WaitCondition.
+const (
+	WaitForConditionType = "condition"
+	WaitForDelete        = "delete"
+	WaitForJSONPath      = "jsonpath"
+)
+
+// WaitCondition is what WaitForCondition waits for.
+type WaitCondition struct {
+	// Kind is one of WaitForConditionType, WaitForDelete and WaitForJSONPath.
+	Kind string
+
+	// Condition is the status condition type, e.g. "Ready" or "Available".
+	Condition string
+
+	// Status is the expected condition status, "True" unless given.
+	Status string
+
+	// JSONPath is the JSONPath template, e.g. "{.status.readyReplicas}".
+	JSONPath string
+
+	// Value is the expected JSONPath result. Empty means any non-empty result.
+	Value string
+}
+
+// ParseWaitCondition parses a kubectl-style --for value: "delete", "condition=Ready",
+// "condition=Ready=False", "jsonpath={.status.phase}=Running" or "jsonpath={.status.loadBalancer.ingress}".
+func ParseWaitCondition(spec string) (WaitCondition, error) {
+	if strings.EqualFold(spec, WaitForDelete) {
+		return WaitCondition{Kind: WaitForDelete}, nil
+	}
+
+	kind, rest, _ := strings.Cut(spec, "=")
+	switch strings.ToLower(kind) {
+	case WaitForConditionType:
+		condition, status, _ := strings.Cut(rest, "=")
+		if condition == "" {
+			return WaitCondition{}, fmt.Errorf("missing condition type in %q", spec)
+		}
+		if status == "" {
+			status = string(metav1.ConditionTrue)
+		}
+		return WaitCondition{Kind: WaitForConditionType, Condition: condition, Status: status}, nil
+	case WaitForJSONPath:
+		return parseJSONPathCondition(spec, rest)
+	default:
+		return WaitCondition{}, fmt.Errorf("unsupported condition %q, must be delete, condition=TYPE[=STATUS] "+
+			"or jsonpath={PATH}[=VALUE]", spec)
+	}
+}
+
+// parseJSONPathCondition parses the "{PATH}[=VALUE]" part of a jsonpath condition.
+func parseJSONPathCondition(spec, rest string) (WaitCondition, error) {
+	end := strings.LastIndex(rest, "}")
+	if !strings.HasPrefix(rest, "{") || end < 0 {
+		return WaitCondition{}, fmt.Errorf("invalid JSONPath in %q, expected jsonpath={PATH}[=VALUE]", spec)
+	}
+	path, value := rest[:end+1], strings.TrimPrefix(rest[end+1:], "=")
+	if err := jsonpath.New("wait").Parse(path); err != nil {
+		return WaitCondition{}, fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+	return WaitCondition{Kind: WaitForJSONPath, JSONPath: path, Value: value}, nil
+}
+
+// String returns the condition in the syntax accepted by ParseWaitCondition.
+func (w WaitCondition) String() string {
+	switch w.Kind {
+	case WaitForDelete:
+		return WaitForDelete
+	case WaitForJSONPath:
+		if w.Value == "" {
+			return WaitForJSONPath + "=" + w.JSONPath
+		}
+		return WaitForJSONPath + "=" + w.JSONPath + "=" + w.Value
+	default:
+		return WaitForConditionType + "=" + w.Condition + "=" + w.Status
+	}
+}
+
+// WaitForCondition waits until the named object meets the condition or the timeout passes.
+// Objects that don't exist yet are waited for, except when waiting for deletion, which they satisfy.
+// It returns the last observed object, which is nil once it is deleted.
+func (c *Client) WaitForCondition(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+	condition WaitCondition, timeout time.Duration) (*unstructured.Unstructured, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return c.waitForCondition(ctx, gvr, ns, name, condition, WaitPollInterval)
+}
+
+// CheckCondition reports whether the named object meets the condition now, without waiting.
+// An object that doesn't exist only meets a deletion condition.
+func (c *Client) CheckCondition(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+	condition WaitCondition) (bool, error) {
+	obj, err := c.dynamic.Resource(gvr).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		obj, err = nil, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s %q: %w", gvr.Resource, name, err)
+	}
+	return conditionMet(obj, condition)
+}
+
+// waitForCondition polls the named object at the given interval until it meets the condition.
+func (c *Client) waitForCondition(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+	condition WaitCondition, interval time.Duration) (*unstructured.Unstructured, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	resource := c.dynamic.Resource(gvr).Namespace(ns)
+	for {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			obj, err = nil, nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, waitTimeoutError(condition, gvr, name, ctx.Err())
+			}
+			return nil, fmt.Errorf("failed to get %s %q: %w", gvr.Resource, name, err)
+		}
+
+		met, err := conditionMet(obj, condition)
+		if err != nil {
+			return obj, err
+		}
+		if met {
+			c.logger.Debug().Str("resource", gvr.Resource).Str("name", name).
+				Str("condition", condition.String()).Msg("Condition met")
+			return obj, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return obj, waitTimeoutError(condition, gvr, name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitTimeoutError reports that the wait for a condition ended before it was met.
+func waitTimeoutError(condition WaitCondition, gvr schema.GroupVersionResource, name string, err error) error {
+	return fmt.Errorf("timed out waiting for %s on %s %q: %w", condition, gvr.Resource, name, err)
+}
+
+// conditionMet reports whether an object, nil if it does not exist, meets the condition.
+func conditionMet(obj *unstructured.Unstructured, condition WaitCondition) (bool, error) {
+	if condition.Kind == WaitForDelete {
+		return obj == nil, nil
+	}
+	if obj == nil {
+		return false, nil
+	}
+	if condition.Kind == WaitForJSONPath {
+		return jsonPathMatches(obj, condition)
+	}
+
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return false, fmt.Errorf("failed to read status conditions: %w", err)
+	}
+	for _, item := range conditions {
+		cond, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		condType, _ := cond["type"].(string)
+		status, _ := cond["status"].(string)
+		if strings.EqualFold(condType, condition.Condition) {
+			return strings.EqualFold(status, condition.Status), nil
+		}
+	}
+	return false, nil
+}
+
+// jsonPathMatches evaluates the condition's JSONPath against the object and compares the result.
+// Missing fields don't match, since they may appear later.
+func jsonPathMatches(obj *unstructured.Unstructured, condition WaitCondition) (bool, error) {
+	parser := jsonpath.New("wait").AllowMissingKeys(true)
+	if err := parser.Parse(condition.JSONPath); err != nil {
+		return false, fmt.Errorf("invalid JSONPath %q: %w", condition.JSONPath, err)
+	}
+
+	var out bytes.Buffer
+	if err := parser.Execute(&out, obj.Object); err != nil {
+		return false, fmt.Errorf("failed to evaluate JSONPath %q: %w", condition.JSONPath, err)
+	}
+	result := strings.TrimSpace(out.String())
+	if condition.Value == "" {
+		return result != "", nil
+	}
+	return result == condition.Value, nil
+}
@@ -0,0 +1,154 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests waiting for conditions, deletion and JSONPath results.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// testWaitInterval is the poll interval used by the wait tests.
+const testWaitInterval = 10 * time.Millisecond
+
+// TestParseWaitCondition verifies parsing of --for values.
+func TestParseWaitCondition(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    WaitCondition
+		wantErr bool
+	}{
+		{"delete", WaitCondition{Kind: WaitForDelete}, false},
+		{"condition=Available", WaitCondition{Kind: WaitForConditionType, Condition: "Available", Status: "True"},
+			false},
+		{"condition=Ready=False", WaitCondition{Kind: WaitForConditionType, Condition: "Ready", Status: "False"},
+			false},
+		{"jsonpath={.status.phase}=Running", WaitCondition{Kind: WaitForJSONPath, JSONPath: "{.status.phase}",
+			Value: "Running"}, false},
+		{"jsonpath={.status.loadBalancer.ingress}", WaitCondition{Kind: WaitForJSONPath,
+			JSONPath: "{.status.loadBalancer.ingress}"}, false},
+		{"condition=", WaitCondition{}, true},
+		{"jsonpath=.status.phase", WaitCondition{}, true},
+		{"ready", WaitCondition{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseWaitCondition(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWaitCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWaitCondition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newAvailableDeployment builds the test deployment with the Available condition set to status.
+func newAvailableDeployment(status corev1.ConditionStatus) *appsv1.Deployment {
+	deployment := createTestDeployment(testDeploymentNginx, testNamespaceDefault, 2, []string{testImageNginx})
+	deployment.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: status}}
+	return deployment
+}
+
+// TestWaitForCondition verifies that met conditions return and unmet ones time out.
+func TestWaitForCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  corev1.ConditionStatus
+		spec    string
+		target  string
+		wantErr bool
+	}{
+		{"available", corev1.ConditionTrue, "condition=Available", testDeploymentNginx, false},
+		{"case-insensitive", corev1.ConditionTrue, "condition=available", testDeploymentNginx, false},
+		{"not available", corev1.ConditionFalse, "condition=Available", testDeploymentNginx, true},
+		{"expected false", corev1.ConditionFalse, "condition=Available=False", testDeploymentNginx, false},
+		{"jsonpath", corev1.ConditionTrue, "jsonpath={.status.readyReplicas}=2", testDeploymentNginx, false},
+		{"jsonpath mismatch", corev1.ConditionTrue, "jsonpath={.status.readyReplicas}=3", testDeploymentNginx,
+			true},
+		{"missing object deleted", corev1.ConditionTrue, "delete", "missing", false},
+		{"missing object not available", corev1.ConditionTrue, "condition=Available", "missing", true},
+	}
+
+	deployments, _ := LookupResource("deployments")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewFakeClient(zerolog.Nop(), newAvailableDeployment(tt.status))
+			condition, err := ParseWaitCondition(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseWaitCondition() error = %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err = client.waitForCondition(ctx, deployments.GVR, testNamespaceDefault, tt.target, condition,
+				testWaitInterval)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("waitForCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected a timeout, got %v", err)
+			}
+		})
+	}
+}
+
+// TestWaitForDeletion verifies that a wait for deletion returns once the object is deleted.
+func TestWaitForDeletion(t *testing.T) {
+	client := NewFakeClient(zerolog.Nop(), newAvailableDeployment(corev1.ConditionTrue))
+	deployments, _ := LookupResource("deployments")
+	resource := client.dynamic.Resource(deployments.GVR).Namespace(testNamespaceDefault)
+
+	go func() {
+		time.Sleep(3 * testWaitInterval)
+		_ = resource.Delete(context.Background(), testDeploymentNginx, metav1.DeleteOptions{})
+	}()
+
+	obj, err := client.WaitForCondition(context.Background(), deployments.GVR, testNamespaceDefault,
+		testDeploymentNginx, WaitCondition{Kind: WaitForDelete}, time.Second)
+	if err != nil {
+		t.Fatalf("WaitForCondition() error = %v", err)
+	}
+	if obj != nil {
+		t.Errorf("expected no object after deletion, got %v", obj)
+	}
+}
+
+// TestCheckCondition verifies that conditions are checked once against the current object.
+func TestCheckCondition(t *testing.T) {
+	tests := []struct {
+		spec   string
+		target string
+		want   bool
+	}{
+		{"condition=Available", testDeploymentNginx, true},
+		{"jsonpath={.status.readyReplicas}=3", testDeploymentNginx, false},
+		{"delete", testDeploymentNginx, false},
+		{"delete", "missing", true},
+		{"condition=Available", "missing", false},
+	}
+
+	client := NewFakeClient(zerolog.Nop(), newAvailableDeployment(corev1.ConditionTrue))
+	deployments, _ := LookupResource("deployments")
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.target, func(t *testing.T) {
+			condition, _ := ParseWaitCondition(tt.spec)
+			got, err := client.CheckCondition(context.Background(), deployments.GVR, testNamespaceDefault,
+				tt.target, condition)
+			if err != nil {
+				t.Fatalf("CheckCondition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
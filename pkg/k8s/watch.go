@@ -0,0 +1,229 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements long-running watches that resume from bookmarks and relist when their
+// resource version has expired.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Defaults of WatchOptions.
+const (
+	// DefaultWatchTimeout is how long a single watch request stays open before it is reopened
+	// from the last resource version.
+	DefaultWatchTimeout = 5 * time.Minute
+
+	// DefaultWatchRetryDelay is the delay before a failed list or watch request is retried.
+	DefaultWatchRetryDelay = time.Second
+)
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// Namespace limits the watch to one namespace. If empty, all namespaces are watched.
+	Namespace string
+
+	// LabelSelector and FieldSelector filter the watched objects.
+	LabelSelector string
+	FieldSelector string
+
+	// ResourceVersion resumes an earlier watch. If empty, the current objects are listed first
+	// and delivered as Added events.
+	ResourceVersion string
+
+	// SkipExisting suppresses the Added events of the initial list, so only changes are delivered.
+	// Objects listed again after the resource version expired are still delivered.
+	SkipExisting bool
+
+	// Timeout is how long a single watch request stays open. The server closes the request after it
+	// and a client-side deadline slightly later catches connections that died without being closed,
+	// so a watch never stalls for longer. Zero uses DefaultWatchTimeout.
+	Timeout time.Duration
+
+	// RetryDelay is the delay before a failed request is retried. Zero uses DefaultWatchRetryDelay.
+	RetryDelay time.Duration
+}
+
+// WatchHandler handles an event of a Watch. Returning an error stops the watch.
+type WatchHandler func(event watch.Event) error
+
+// DeploymentEvent is a deployment change delivered by WatchDeployments.
+type DeploymentEvent struct {
+	// Type is watch.Added, watch.Modified or watch.Deleted.
+	Type watch.EventType `json:"type"`
+
+	// Deployment is the deployment after the change, or its last state before it was deleted.
+	Deployment DeploymentInfo `json:"object"`
+
+	// ResourceVersion is the resource version of the deployment after the change. A watch resumed
+	// from it, see WatchOptions.ResourceVersion, delivers the changes made since.
+	ResourceVersion string `json:"resourceVersion,omitempty" yaml:"resourceVersion,omitempty"`
+}
+
+// handlerError marks errors returned by a WatchHandler, which stop the watch instead of being retried.
+type handlerError struct {
+	err error
+}
+
+// Error returns the handler's error message.
+func (e *handlerError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the handler's error.
+func (e *handlerError) Unwrap() error {
+	return e.err
+}
+
+// Watch delivers the Added, Modified and Deleted events of the given resource to handle until ctx
+// is cancelled or handle fails. Bookmarks are requested to keep the tracked resource version
+// current; they are not delivered. Closed watches are reopened from the last resource version,
+// and when that version has expired (410 Gone) the objects are listed again and delivered as
+// Added events, so handle must tolerate objects it has already seen. Objects deleted while no
+// watch was open are not reported. Failed requests are retried; Watch returns nil once ctx is
+// cancelled and the handler's error otherwise.
+func (c *Client) Watch(ctx context.Context, gvr schema.GroupVersionResource, opts WatchOptions,
+	handle WatchHandler) error {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultWatchTimeout
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultWatchRetryDelay
+	}
+	logger := c.logger.With().Str("resource", gvr.Resource).Str("namespace", opts.Namespace).Logger()
+
+	version, relist := opts.ResourceVersion, opts.ResourceVersion == ""
+	deliver := !opts.SkipExisting
+	for {
+		var err error
+		if relist {
+			if version, err = c.relist(ctx, gvr, opts, deliver, handle); err == nil {
+				relist, deliver = false, true
+				version, err = c.watchFrom(ctx, gvr, opts, version, handle)
+			}
+		} else {
+			version, err = c.watchFrom(ctx, gvr, opts, version, handle)
+		}
+
+		var handlerErr *handlerError
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.As(err, &handlerErr):
+			return handlerErr.err
+		case apierrors.IsResourceExpired(err) || apierrors.IsGone(err):
+			logger.Info().Str("resourceVersion", version).Msg("Watch resource version expired, relisting")
+			relist = true
+			continue
+		case err != nil:
+			logger.Warn().Err(err).Dur("retry_in", opts.RetryDelay).Msg("Watch failed, retrying")
+		default:
+			logger.Debug().Str("resourceVersion", version).Msg("Watch closed, reopening")
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(opts.RetryDelay):
+		}
+	}
+}
+
+// relist lists the watched objects page by page, delivers them as Added events if deliver is set
+// and returns the resource version of the list to watch from.
+func (c *Client) relist(ctx context.Context, gvr schema.GroupVersionResource, opts WatchOptions,
+	deliver bool, handle WatchHandler) (string, error) {
+	resource := c.dynamic.Resource(gvr).Namespace(opts.Namespace)
+	version := ""
+	err := ListPages(metav1.ListOptions{LabelSelector: opts.LabelSelector, FieldSelector: opts.FieldSelector},
+		func(listOpts metav1.ListOptions) (string, error) {
+			page, err := resource.List(ctx, listOpts)
+			if err != nil {
+				return "", fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+			}
+			version = page.GetResourceVersion()
+			for i := 0; deliver && i < len(page.Items); i++ {
+				if err := handle(watch.Event{Type: watch.Added, Object: &page.Items[i]}); err != nil {
+					return "", &handlerError{err: err}
+				}
+			}
+			return page.GetContinue(), nil
+		})
+	return version, err
+}
+
+// watchFrom opens a watch at the given resource version and delivers its events until the watch
+// closes, fails or its deadline passes. It returns the resource version of the last event.
+func (c *Client) watchFrom(ctx context.Context, gvr schema.GroupVersionResource, opts WatchOptions,
+	version string, handle WatchHandler) (string, error) {
+	timeoutSeconds := int64(opts.Timeout.Seconds())
+	watchCtx, cancel := context.WithTimeout(ctx, opts.Timeout+opts.Timeout/10)
+	defer cancel()
+
+	watcher, err := c.dynamic.Resource(gvr).Namespace(opts.Namespace).Watch(watchCtx, metav1.ListOptions{
+		LabelSelector:       opts.LabelSelector,
+		FieldSelector:       opts.FieldSelector,
+		ResourceVersion:     version,
+		AllowWatchBookmarks: true,
+		TimeoutSeconds:      &timeoutSeconds,
+	})
+	if err != nil {
+		return version, fmt.Errorf("failed to watch %s: %w", gvr.Resource, err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-watchCtx.Done():
+			return version, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return version, nil
+			}
+			if event.Type == watch.Error {
+				return version, fmt.Errorf("watch of %s failed: %w", gvr.Resource, apierrors.FromObject(event.Object))
+			}
+			if accessor, err := meta.Accessor(event.Object); err == nil && accessor.GetResourceVersion() != "" {
+				version = accessor.GetResourceVersion()
+			}
+			if event.Type == watch.Bookmark {
+				continue
+			}
+			if err := handle(event); err != nil {
+				return version, &handlerError{err: err}
+			}
+		}
+	}
+}
+
+// WatchDeployments watches deployments with Watch and delivers their changes as DeploymentInfo.
+func (c *Client) WatchDeployments(ctx context.Context, opts WatchOptions,
+	handle func(event DeploymentEvent) error) error {
+	return c.Watch(ctx, appsv1.SchemeGroupVersion.WithResource("deployments"), opts, func(event watch.Event) error {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected watch object %T", event.Object)
+		}
+		var deployment appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+			return fmt.Errorf("failed to convert deployment %q: %w", obj.GetName(), err)
+		}
+		return handle(DeploymentEvent{
+			Type:            event.Type,
+			Deployment:      c.createDeploymentInfo(deployment, time.Now()),
+			ResourceVersion: obj.GetResourceVersion(),
+		})
+	})
+}
@@ -0,0 +1,142 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests resuming watches from bookmarks and relisting after expired resource versions.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// scriptedWatches makes the widget client's watch calls return the given event sequences in turn,
+// recording the resource version each watch was opened at.
+func scriptedWatches(client *Client, scripts ...[]watch.Event) *[]string {
+	var versions []string
+	client.dynamic.(*dynamicfake.FakeDynamicClient).PrependWatchReactor("widgets",
+		func(action k8stesting.Action) (bool, watch.Interface, error) {
+			versions = append(versions, action.(k8stesting.WatchActionImpl).WatchRestrictions.ResourceVersion)
+			watcher := watch.NewFake()
+			if len(versions) <= len(scripts) {
+				go func(events []watch.Event) {
+					for _, event := range events {
+						watcher.Action(event.Type, event.Object)
+					}
+					watcher.Stop()
+				}(scripts[len(versions)-1])
+			}
+			return true, watcher, nil
+		})
+	return &versions
+}
+
+// widgetAt returns a widget with the given resource version.
+func widgetAt(name, version string) runtime.Object {
+	obj := newWidget("web", name, nil)
+	obj.SetResourceVersion(version)
+	return obj
+}
+
+// watchNames runs a watch until want events were delivered and returns their types and names.
+func watchNames(t *testing.T, client *Client, opts WatchOptions, want int) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []string
+	opts.RetryDelay = time.Millisecond
+	err := client.Watch(ctx, widgetGVR, opts, func(event watch.Event) error {
+		obj, _ := event.Object.(metav1.Object)
+		got = append(got, string(event.Type)+" "+obj.GetName())
+		if len(got) == want {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if len(got) != want {
+		t.Fatalf("expected %d events before the timeout, got %q", want, got)
+	}
+	return got
+}
+
+// TestWatchResumesFromBookmarks verifies that closed watches are reopened from the resource
+// version of the last event or bookmark, without listing again.
+func TestWatchResumesFromBookmarks(t *testing.T) {
+	client := newWidgetTestClient()
+	versions := scriptedWatches(client,
+		[]watch.Event{{Type: watch.Added, Object: widgetAt("a", "11")}},
+		[]watch.Event{{Type: watch.Bookmark, Object: widgetAt("", "15")}},
+		[]watch.Event{{Type: watch.Modified, Object: widgetAt("a", "16")}},
+	)
+
+	got := watchNames(t, client, WatchOptions{Namespace: "web", ResourceVersion: "10"}, 2)
+	if got[0] != "ADDED a" || got[1] != "MODIFIED a" {
+		t.Errorf("expected the added and modified events without the bookmark, got %q", got)
+	}
+	if want := []string{"10", "11", "15"}; len(*versions) != 3 || (*versions)[1] != want[1] ||
+		(*versions)[2] != want[2] {
+		t.Errorf("expected watches at versions %q, got %q", want, *versions)
+	}
+}
+
+// TestWatchRelistsWhenExpired verifies that the objects are listed again after a 410 Gone error.
+func TestWatchRelistsWhenExpired(t *testing.T) {
+	client := newWidgetTestClient(newWidget("web", "existing", nil))
+	expired := apierrors.NewResourceExpired("too old resource version: 10 (20)")
+	scriptedWatches(client,
+		[]watch.Event{{Type: watch.Error, Object: &expired.ErrStatus}},
+		[]watch.Event{{Type: watch.Deleted, Object: widgetAt("existing", "21")}},
+	)
+
+	got := watchNames(t, client, WatchOptions{Namespace: "web", ResourceVersion: "10"}, 2)
+	if got[0] != "ADDED existing" || got[1] != "DELETED existing" {
+		t.Errorf("expected the relisted object followed by the watch event, got %q", got)
+	}
+}
+
+// TestWatchHandlerError verifies that handler errors stop the watch and are returned.
+func TestWatchHandlerError(t *testing.T) {
+	client := newWidgetTestClient(newWidget("web", "existing", nil))
+	failure := errors.New("handler failed")
+
+	err := client.Watch(context.Background(), widgetGVR, WatchOptions{Namespace: "web"},
+		func(watch.Event) error { return failure })
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the handler error, got %v", err)
+	}
+}
+
+// TestWatchDeployments verifies that watched deployments are converted into DeploymentInfo.
+func TestWatchDeployments(t *testing.T) {
+	deployment := createTestDeployment("web", testNamespaceDefault, 2, []string{testImageNginx})
+	deployment.ResourceVersion = "7"
+	client := NewFakeClient(zerolog.Nop(), deployment)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []DeploymentEvent
+	opts := WatchOptions{Namespace: testNamespaceDefault}
+	err := client.WatchDeployments(ctx, opts, func(event DeploymentEvent) error {
+		got = append(got, event)
+		cancel()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WatchDeployments() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Type != watch.Added || got[0].Deployment.Name != "web" ||
+		got[0].Deployment.Replicas.Desired != 2 || len(got[0].Deployment.Images) != 1 || got[0].ResourceVersion != "7" {
+		t.Errorf("expected the existing deployment as ADDED, got %+v", got)
+	}
+}
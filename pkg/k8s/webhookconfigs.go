@@ -0,0 +1,118 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements injection of the CA bundle into webhook configurations.
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// injectFunc sets caBundle on one kind of webhook configuration.
+type injectFunc func(ctx context.Context, name string, caBundle []byte) (bool, error)
+
+// InjectCABundle sets caBundle on every webhook of the validating and mutating
+// webhook configurations with the given name. Configurations that don't exist are
+// skipped, but at least one must exist. It returns the number of updated configurations.
+// In client dry runs, the configurations are only read.
+// Each update is submitted to the authorization hook before the API call is made.
+func (c *Client) InjectCABundle(ctx context.Context, name string, caBundle []byte) (int, error) {
+	if len(caBundle) == 0 {
+		return 0, fmt.Errorf("empty CA bundle")
+	}
+
+	updated := 0
+	for _, inject := range []injectFunc{c.injectValidating, c.injectMutating} {
+		ok, err := inject(ctx, name, caBundle)
+		if err != nil {
+			return updated, err
+		}
+		if ok {
+			updated++
+		}
+	}
+
+	if updated == 0 {
+		return 0, fmt.Errorf("no validating or mutating webhook configuration named %q found", name)
+	}
+	return updated, nil
+}
+
+// injectValidating sets caBundle on a validating webhook configuration.
+// It reports false if the configuration does not exist.
+func (c *Client) injectValidating(ctx context.Context, name string, caBundle []byte) (bool, error) {
+	change := authz.Change{Operation: "update", Resource: "validatingwebhookconfigurations", Name: name}
+	if err := c.authorize(ctx, change); err != nil {
+		return false, err
+	}
+
+	skip := c.skipMutation(change)
+	configs := c.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	_, _, err := UpdateWithRetry(ctx,
+		func(ctx context.Context) (*admissionregistrationv1.ValidatingWebhookConfiguration, error) {
+			return configs.Get(ctx, name, metav1.GetOptions{})
+		},
+		func(config *admissionregistrationv1.ValidatingWebhookConfiguration) (bool, error) {
+			for i := range config.Webhooks {
+				config.Webhooks[i].ClientConfig.CABundle = caBundle
+			}
+			return true, nil
+		},
+		func(ctx context.Context, config *admissionregistrationv1.ValidatingWebhookConfiguration,
+		) (*admissionregistrationv1.ValidatingWebhookConfiguration, error) {
+			if skip {
+				return config, nil
+			}
+			return configs.Update(ctx, config,
+				metav1.UpdateOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+		})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update validating webhook configuration %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// injectMutating sets caBundle on a mutating webhook configuration.
+// It reports false if the configuration does not exist.
+func (c *Client) injectMutating(ctx context.Context, name string, caBundle []byte) (bool, error) {
+	change := authz.Change{Operation: "update", Resource: "mutatingwebhookconfigurations", Name: name}
+	if err := c.authorize(ctx, change); err != nil {
+		return false, err
+	}
+
+	skip := c.skipMutation(change)
+	configs := c.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations()
+	_, _, err := UpdateWithRetry(ctx,
+		func(ctx context.Context) (*admissionregistrationv1.MutatingWebhookConfiguration, error) {
+			return configs.Get(ctx, name, metav1.GetOptions{})
+		},
+		func(config *admissionregistrationv1.MutatingWebhookConfiguration) (bool, error) {
+			for i := range config.Webhooks {
+				config.Webhooks[i].ClientConfig.CABundle = caBundle
+			}
+			return true, nil
+		},
+		func(ctx context.Context, config *admissionregistrationv1.MutatingWebhookConfiguration,
+		) (*admissionregistrationv1.MutatingWebhookConfiguration, error) {
+			if skip {
+				return config, nil
+			}
+			return configs.Update(ctx, config,
+				metav1.UpdateOptions{FieldManager: fieldManager, DryRun: c.dryRunOption()})
+		})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update mutating webhook configuration %q: %w", name, err)
+	}
+	return true, nil
+}
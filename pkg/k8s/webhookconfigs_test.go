@@ -0,0 +1,104 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests CA bundle injection into webhook configurations.
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// testWebhookConfig is the name of the webhook configurations in tests.
+const testWebhookConfig = "k8s-controller"
+
+// testCABundle is the CA bundle injected in tests.
+var testCABundle = []byte("-----BEGIN CERTIFICATE-----\ntest\n-----END CERTIFICATE-----\n")
+
+// testWebhookConfigs returns a validating and a mutating webhook configuration without CA bundle.
+func testWebhookConfigs() (validating, mutating runtime.Object) {
+	validating = &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: testWebhookConfig},
+		Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "a.example.com"}, {Name: "b.example.com"}},
+	}
+	mutating = &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: testWebhookConfig},
+		Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "c.example.com"}},
+	}
+	return validating, mutating
+}
+
+// TestInjectCABundle verifies injection into validating and mutating webhook configurations.
+func TestInjectCABundle(t *testing.T) {
+	validating, mutating := testWebhookConfigs()
+	tests := []struct {
+		name        string
+		objects     []runtime.Object
+		wantUpdated int
+		wantErr     bool
+	}{
+		{"both configurations", []runtime.Object{validating, mutating}, 2, false},
+		{"validating only", []runtime.Object{validating}, 1, false},
+		{"none", nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := setupTestClient(zerolog.Nop(), tt.objects, false)
+			ctx := context.Background()
+
+			updated, err := client.InjectCABundle(ctx, testWebhookConfig, testCABundle)
+			if (err != nil) != tt.wantErr || updated != tt.wantUpdated {
+				t.Fatalf("InjectCABundle() = %d, %v; want %d", updated, err, tt.wantUpdated)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := client.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().
+				Get(ctx, testWebhookConfig, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get validating configuration: %v", err)
+			}
+			for _, webhook := range got.Webhooks {
+				if string(webhook.ClientConfig.CABundle) != string(testCABundle) {
+					t.Errorf("webhook %s has caBundle %q", webhook.Name, webhook.ClientConfig.CABundle)
+				}
+			}
+		})
+	}
+}
+
+// TestInjectCABundleDenied verifies that a denied change leaves the webhook configurations unchanged.
+func TestInjectCABundleDenied(t *testing.T) {
+	validating, mutating := testWebhookConfigs()
+	client := setupTestClient(zerolog.Nop(), []runtime.Object{validating, mutating}, false)
+	client.SetAuthorizer(denyAuthorizer{})
+	ctx := context.Background()
+
+	if _, err := client.InjectCABundle(ctx, testWebhookConfig, testCABundle); !errors.Is(err, authz.ErrDenied) {
+		t.Fatalf("expected the injection to be denied, got %v", err)
+	}
+	got, err := client.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().
+		Get(ctx, testWebhookConfig, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get validating configuration: %v", err)
+	}
+	if len(got.Webhooks[0].ClientConfig.CABundle) != 0 {
+		t.Errorf("expected no CA bundle after the denied change, got %q", got.Webhooks[0].ClientConfig.CABundle)
+	}
+}
+
+// TestInjectEmptyCABundle verifies that an empty bundle is rejected.
+func TestInjectEmptyCABundle(t *testing.T) {
+	if _, err := setupTestClient(zerolog.Nop(), nil, false).InjectCABundle(context.Background(),
+		testWebhookConfig, nil); err == nil {
+		t.Error("expected error for empty CA bundle")
+	}
+}
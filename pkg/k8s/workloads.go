@@ -0,0 +1,278 @@
+// Package k8s provides Kubernetes client functionality for the k8s-controller application.
+// This file implements listing statefulsets, daemonsets, services and pods with their status summaries.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatefulSetInfo represents the replica status of a statefulset.
+type StatefulSetInfo struct {
+	Name      string        `json:"name" yaml:"name"`
+	Namespace string        `json:"namespace" yaml:"namespace"`
+	Desired   int32         `json:"desired" yaml:"desired"`
+	Ready     int32         `json:"ready" yaml:"ready"`
+	Age       time.Duration `json:"age" yaml:"age"`
+	CreatedAt time.Time     `json:"created_at" yaml:"created_at"`
+}
+
+// DaemonSetInfo represents the rollout status of a daemonset across its nodes.
+type DaemonSetInfo struct {
+	Name         string        `json:"name" yaml:"name"`
+	Namespace    string        `json:"namespace" yaml:"namespace"`
+	Desired      int32         `json:"desired" yaml:"desired"`
+	Current      int32         `json:"current" yaml:"current"`
+	Ready        int32         `json:"ready" yaml:"ready"`
+	UpToDate     int32         `json:"up_to_date" yaml:"up_to_date"`
+	Available    int32         `json:"available" yaml:"available"`
+	NodeSelector string        `json:"node_selector,omitempty" yaml:"node_selector,omitempty"`
+	Age          time.Duration `json:"age" yaml:"age"`
+	CreatedAt    time.Time     `json:"created_at" yaml:"created_at"`
+}
+
+// ServiceInfo represents the type, addresses and ports of a service.
+type ServiceInfo struct {
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Type      string `json:"type" yaml:"type"`
+	ClusterIP string `json:"cluster_ip" yaml:"cluster_ip"`
+
+	// ExternalIP lists the external addresses, or is "<pending>" while a load balancer is provisioned.
+	ExternalIP string `json:"external_ip,omitempty" yaml:"external_ip,omitempty"`
+
+	// Ports lists the ports as kubectl does, e.g. "80/TCP" or "443:30443/TCP" with a node port.
+	Ports     []string      `json:"ports" yaml:"ports"`
+	Age       time.Duration `json:"age" yaml:"age"`
+	CreatedAt time.Time     `json:"created_at" yaml:"created_at"`
+}
+
+// PodInfo represents the readiness and status of a pod.
+type PodInfo struct {
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Ready and Containers count the ready and all regular containers of the pod.
+	Ready      int `json:"ready" yaml:"ready"`
+	Containers int `json:"containers" yaml:"containers"`
+
+	// Status is the phase of the pod, or a more specific reason as kubectl shows it,
+	// e.g. CrashLoopBackOff or Terminating.
+	Status    string        `json:"status" yaml:"status"`
+	Restarts  int32         `json:"restarts" yaml:"restarts"`
+	Node      string        `json:"node,omitempty" yaml:"node,omitempty"`
+	Age       time.Duration `json:"age" yaml:"age"`
+	CreatedAt time.Time     `json:"created_at" yaml:"created_at"`
+}
+
+// ListStatefulSets returns the statefulsets of a namespace, or of all namespaces if ns is empty, sorted by
+// namespace and name.
+func (c *Client) ListStatefulSets(ctx context.Context, ns string) ([]StatefulSetInfo, error) {
+	statefulSets, err := List[appsv1.StatefulSet](ctx, c.clientset.AppsV1().StatefulSets(ns).List,
+		metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	now := time.Now()
+	infos := make([]StatefulSetInfo, 0, len(statefulSets))
+	for _, sts := range statefulSets {
+		info := StatefulSetInfo{
+			Name:      sts.Name,
+			Namespace: sts.Namespace,
+			Desired:   1,
+			Ready:     sts.Status.ReadyReplicas,
+			Age:       now.Sub(sts.CreationTimestamp.Time),
+			CreatedAt: sts.CreationTimestamp.Time,
+		}
+		if sts.Spec.Replicas != nil {
+			info.Desired = *sts.Spec.Replicas
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return objectLess(infos[i].Namespace, infos[i].Name, infos[j].Namespace, infos[j].Name)
+	})
+	return infos, nil
+}
+
+// ListDaemonSets returns the daemonsets of a namespace, or of all namespaces if ns is empty, sorted by
+// namespace and name.
+func (c *Client) ListDaemonSets(ctx context.Context, ns string) ([]DaemonSetInfo, error) {
+	daemonSets, err := List[appsv1.DaemonSet](ctx, c.clientset.AppsV1().DaemonSets(ns).List, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+
+	now := time.Now()
+	infos := make([]DaemonSetInfo, 0, len(daemonSets))
+	for _, ds := range daemonSets {
+		infos = append(infos, DaemonSetInfo{
+			Name:         ds.Name,
+			Namespace:    ds.Namespace,
+			Desired:      ds.Status.DesiredNumberScheduled,
+			Current:      ds.Status.CurrentNumberScheduled,
+			Ready:        ds.Status.NumberReady,
+			UpToDate:     ds.Status.UpdatedNumberScheduled,
+			Available:    ds.Status.NumberAvailable,
+			NodeSelector: formatSelectorMap(ds.Spec.Template.Spec.NodeSelector),
+			Age:          now.Sub(ds.CreationTimestamp.Time),
+			CreatedAt:    ds.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return objectLess(infos[i].Namespace, infos[i].Name, infos[j].Namespace, infos[j].Name)
+	})
+	return infos, nil
+}
+
+// ListServices returns the services of a namespace, or of all namespaces if ns is empty, sorted by
+// namespace and name. Once the read cache has synced, they are listed from it.
+func (c *Client) ListServices(ctx context.Context, ns string) ([]ServiceInfo, error) {
+	services, cached, err := cachedObjects[corev1.Service](c, "services", ns)
+	if !cached {
+		services, err = List[corev1.Service](ctx, c.clientset.CoreV1().Services(ns).List, metav1.ListOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	now := time.Now()
+	infos := make([]ServiceInfo, 0, len(services))
+	for i := range services {
+		svc := &services[i]
+		infos = append(infos, ServiceInfo{
+			Name:       svc.Name,
+			Namespace:  svc.Namespace,
+			Type:       string(svc.Spec.Type),
+			ClusterIP:  svc.Spec.ClusterIP,
+			ExternalIP: serviceExternalIP(svc),
+			Ports:      servicePorts(svc),
+			Age:        now.Sub(svc.CreationTimestamp.Time),
+			CreatedAt:  svc.CreationTimestamp.Time,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return objectLess(infos[i].Namespace, infos[i].Name, infos[j].Namespace, infos[j].Name)
+	})
+	return infos, nil
+}
+
+// ListPods returns the pods of a namespace, or of all namespaces if ns is empty, sorted by namespace and name.
+// Once the read cache has synced, they are listed from it.
+func (c *Client) ListPods(ctx context.Context, ns string) ([]PodInfo, error) {
+	pods, cached, err := cachedObjects[corev1.Pod](c, "pods", ns)
+	if !cached {
+		pods, err = List[corev1.Pod](ctx, c.clientset.CoreV1().Pods(ns).List, metav1.ListOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	now := time.Now()
+	infos := make([]PodInfo, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		info := PodInfo{
+			Name:       pod.Name,
+			Namespace:  pod.Namespace,
+			Containers: len(pod.Spec.Containers),
+			Status:     podStatus(pod),
+			Node:       pod.Spec.NodeName,
+			Age:        now.Sub(pod.CreationTimestamp.Time),
+			CreatedAt:  pod.CreationTimestamp.Time,
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Ready {
+				info.Ready++
+			}
+			info.Restarts += status.RestartCount
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return objectLess(infos[i].Namespace, infos[i].Name, infos[j].Namespace, infos[j].Name)
+	})
+	return infos, nil
+}
+
+// podStatus returns the status of a pod as kubectl shows it: the reason of the pod or of its first
+// waiting or failed container if any, Terminating while it is being deleted, or else its phase.
+func podStatus(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+			return status.State.Waiting.Reason
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 &&
+			status.State.Terminated.Reason != "":
+			return status.State.Terminated.Reason
+		}
+	}
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+	return string(pod.Status.Phase)
+}
+
+// serviceExternalIP returns the external addresses of a service, "<pending>" for a load balancer
+// without ingress yet, or "" if the service has none.
+func serviceExternalIP(svc *corev1.Service) string {
+	addresses := append([]string(nil), svc.Spec.ExternalIPs...)
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			addresses = append(addresses, ingress.IP)
+		} else if ingress.Hostname != "" {
+			addresses = append(addresses, ingress.Hostname)
+		}
+	}
+	if len(addresses) == 0 && svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		return "<pending>"
+	}
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return svc.Spec.ExternalName
+	}
+	return strings.Join(addresses, ",")
+}
+
+// servicePorts formats the ports of a service, e.g. "80/TCP" or "443:30443/TCP" with a node port.
+func servicePorts(svc *corev1.Service) []string {
+	ports := make([]string, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		value := strconv.Itoa(int(port.Port))
+		if port.NodePort != 0 {
+			value += ":" + strconv.Itoa(int(port.NodePort))
+		}
+		ports = append(ports, value+"/"+string(port.Protocol))
+	}
+	return ports
+}
+
+// formatSelectorMap formats a node selector as sorted KEY=VALUE pairs joined by commas.
+func formatSelectorMap(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for key, value := range selector {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// objectLess orders objects by namespace, then by name.
+func objectLess(nsA, nameA, nsB, nameB string) bool {
+	if nsA != nsB {
+		return nsA < nsB
+	}
+	return nameA < nameB
+}
@@ -0,0 +1,133 @@
+// Package k8s contains tests for the Kubernetes client functionality.
+// This file tests listing statefulsets, daemonsets, services and pods.
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestListWorkloads verifies that the per-kind listers filter by namespace and summarize the status.
+func TestListWorkloads(t *testing.T) {
+	replicas := int32(3)
+	client := NewFakeClient(zerolog.Nop(),
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: testNamespaceDefault},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+		},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "other"}},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: testNamespaceDefault},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+			}}},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 1},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespaceDefault},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort, ClusterIP: "10.0.0.1",
+				Ports: []corev1.ServicePort{
+					{Port: 80, Protocol: corev1.ProtocolTCP},
+					{Port: 443, NodePort: 30443, Protocol: corev1.ProtocolTCP},
+				}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: testNamespaceDefault},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Ready: true, RestartCount: 2},
+			}},
+		},
+		newTestPodOnNode("web-1", "ReplicaSet"))
+	ctx := context.Background()
+
+	statefulSets, err := client.ListStatefulSets(ctx, testNamespaceDefault)
+	if err != nil || len(statefulSets) != 1 || statefulSets[0].Desired != 3 || statefulSets[0].Ready != 2 {
+		t.Errorf("ListStatefulSets() = %+v, %v, want db with 2/3 ready", statefulSets, err)
+	}
+	if all, err := client.ListStatefulSets(ctx, ""); err != nil || len(all) != 2 || all[0].Name != "db" {
+		t.Errorf("ListStatefulSets(all) = %+v, %v, want db and cache sorted by namespace", all, err)
+	}
+
+	daemonSets, err := client.ListDaemonSets(ctx, testNamespaceDefault)
+	if err != nil || len(daemonSets) != 1 || daemonSets[0].Desired != 2 ||
+		daemonSets[0].NodeSelector != "kubernetes.io/os=linux" {
+		t.Errorf("ListDaemonSets() = %+v, %v", daemonSets, err)
+	}
+
+	services, err := client.ListServices(ctx, testNamespaceDefault)
+	if err != nil || len(services) != 1 || services[0].Type != "NodePort" ||
+		len(services[0].Ports) != 2 || services[0].Ports[1] != "443:30443/TCP" {
+		t.Errorf("ListServices() = %+v, %v", services, err)
+	}
+
+	pods, err := client.ListPods(ctx, testNamespaceDefault)
+	if err != nil || len(pods) != 2 {
+		t.Fatalf("ListPods() = %+v, %v, want 2 pods", pods, err)
+	}
+	if pods[0].Name != "web-1" || pods[1].Ready != 1 || pods[1].Containers != 1 || pods[1].Restarts != 2 ||
+		pods[1].Status != "Running" {
+		t.Errorf("unexpected pods %+v", pods)
+	}
+}
+
+// TestPodStatus verifies that pod status reasons take precedence over the phase, as in kubectl.
+func TestPodStatus(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{"phase", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}}, "Pending"},
+		{"pod reason", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}, "Evicted"},
+		{"waiting container", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}}}}, "CrashLoopBackOff"},
+		{"failed init container", corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending,
+			InitContainerStatuses: []corev1.ContainerStatus{{State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}}}}}}, "Error"},
+		{"terminating", corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning}}, "Terminating"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podStatus(&tt.pod); got != tt.want {
+				t.Errorf("podStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestServiceExternalIP verifies the external addresses of the service types.
+func TestServiceExternalIP(t *testing.T) {
+	tests := []struct {
+		name string
+		svc  corev1.Service
+		want string
+	}{
+		{"cluster IP", corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}}, ""},
+		{"pending load balancer", corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}},
+			"<pending>"},
+		{"load balancer", corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+				{IP: "203.0.113.10"}, {Hostname: "lb.example.com"}}}}}, "203.0.113.10,lb.example.com"},
+		{"external name", corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName,
+			ExternalName: "db.example.com"}}, "db.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceExternalIP(&tt.svc); got != tt.want {
+				t.Errorf("serviceExternalIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
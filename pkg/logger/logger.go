@@ -3,42 +3,84 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+
+	"github.com/Searge/k8s-controller/pkg/redact"
 )
 
+// output is where logs are written; nil means stderr.
+var output io.Writer
+
+// levels are the supported log levels by name.
+var levels = map[string]zerolog.Level{
+	"debug":   zerolog.DebugLevel,
+	"info":    zerolog.InfoLevel,
+	"warn":    zerolog.WarnLevel,
+	"warning": zerolog.WarnLevel,
+	"error":   zerolog.ErrorLevel,
+	"fatal":   zerolog.FatalLevel,
+	"panic":   zerolog.PanicLevel,
+}
+
+// SetOutput replaces stderr as the destination of logs, e.g. with a writer that coordinates logs
+// with a progress indicator. It takes effect with the next Init or EnableRedaction; nil restores stderr.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// writer returns the destination of logs.
+func writer() io.Writer {
+	if output == nil {
+		return os.Stderr
+	}
+	return output
+}
+
 // Init initializes the global logger with the specified level.
 // Supported levels: debug, info, warn/warning, error, fatal, panic.
 // If an invalid level is provided, defaults to info level.
-// The logger is configured to use console output for better readability.
+// The logger is configured to use console output for better readability. Logs are written to
+// stderr, so that they do not mix with command output on stdout, e.g. JSON piped to jq.
 func Init(level string) {
 	// Configure zerolog to use console writer for better readability
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: writer()})
 
 	// Set log level
-	switch strings.ToLower(level) {
-	case "debug":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	case "info":
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	case "warn", "warning":
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
-	case "error":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-	case "fatal":
-		zerolog.SetGlobalLevel(zerolog.FatalLevel)
-	case "panic":
-		zerolog.SetGlobalLevel(zerolog.PanicLevel)
-	default:
+	if err := SetLevel(level); err != nil {
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	}
 
 	log.Debug().Str("level", level).Msg("Logger initialized")
 }
 
+// SetLevel changes the level of all loggers, e.g. to debug a running server without restarting it.
+// It accepts the levels of Init, but fails on other levels instead of falling back to info.
+func SetLevel(level string) error {
+	parsed, ok := levels[strings.ToLower(level)]
+	if !ok {
+		return fmt.Errorf("unsupported log level %q, must be one of: debug, info, warn, error, fatal, panic", level)
+	}
+	zerolog.SetGlobalLevel(parsed)
+	return nil
+}
+
+// Level returns the name of the current level of all loggers, e.g. "info".
+func Level() string {
+	return zerolog.GlobalLevel().String()
+}
+
+// EnableRedaction masks sensitive fields of everything logged from now on, using the given redactor.
+// Loggers derived from the global logger before the call are not affected.
+func EnableRedaction(redactor *redact.Redactor) {
+	log.Logger = log.Output(redact.NewWriter(zerolog.ConsoleWriter{Out: writer()}, redactor))
+}
+
 // GetLogger returns the configured logger instance.
 // This logger inherits the global configuration set by Init().
 // It's safe to call this function multiple times and from multiple goroutines.
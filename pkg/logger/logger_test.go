@@ -3,6 +3,9 @@ package logger
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rs/zerolog"
@@ -47,6 +50,34 @@ func TestInit(t *testing.T) {
 	}
 }
 
+// TestSetLevel verifies that SetLevel changes the global log level and rejects unknown levels.
+func TestSetLevel(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	tests := []struct {
+		level     string
+		wantLevel string
+		wantErr   bool
+	}{
+		{"debug", "debug", false},
+		{"WARNING", "warn", false},
+		{"error", "error", false},
+		{"verbose", "error", true},
+		{"", "error", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			err := SetLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetLevel(%q) error = %v, wantErr %v", tt.level, err, tt.wantErr)
+			}
+			if got := Level(); got != tt.wantLevel {
+				t.Errorf("Level() = %q, want %q", got, tt.wantLevel)
+			}
+		})
+	}
+}
+
 // TestGetLogger verifies that GetLogger returns a valid logger instance
 // and that the returned logger can be used for logging without panicking.
 func TestGetLogger(t *testing.T) {
@@ -66,6 +97,41 @@ func TestGetLogger(t *testing.T) {
 	logger.Info().Msg("test message")
 }
 
+// TestInitWritesToStderr verifies that logs go to stderr, so that they do not mix with command output.
+func TestInitWritesToStderr(t *testing.T) {
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(original *os.File) { os.Stderr = original }(os.Stderr)
+	os.Stderr = stderr
+
+	Init("info")
+	log.Info().Msg("to stderr")
+
+	data, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "to stderr") {
+		t.Errorf("expected the log line on stderr, got %q", data)
+	}
+}
+
+// TestSetOutput verifies that logs go to the configured output instead of stderr.
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+
+	Init("info")
+	log.Info().Msg("to the output")
+
+	if !strings.Contains(buf.String(), "to the output") {
+		t.Errorf("expected the log line in the output, got %q", buf.String())
+	}
+}
+
 // BenchmarkInit measures the performance of the Init function.
 // This helps ensure that logger initialization doesn't become a bottleneck.
 func BenchmarkInit(b *testing.B) {
@@ -0,0 +1,105 @@
+// Package metrics records application counters, gauges and histograms and exports them to a configurable backend.
+// This file defines the backend abstraction and selects a backend from configuration.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Backend names accepted by Config.Backend.
+const (
+	BackendPrometheus = "prometheus"
+	BackendStatsD     = "statsd"
+	BackendOTLP       = "otlp"
+	BackendNone       = "none"
+)
+
+// Defaults for push-based backends.
+const (
+	DefaultStatsDAddress = "127.0.0.1:8125"
+	DefaultOTLPEndpoint  = "http://localhost:4318/v1/metrics"
+	DefaultPushInterval  = 15 * time.Second
+)
+
+// Labels are the dimensions of a metric series, e.g. {"method": "GET", "code": "200"}.
+type Labels map[string]string
+
+// Backend receives metric updates. Implementations are safe for concurrent use
+// and do not retain labels, so callers may reuse them after a call returns.
+type Backend interface {
+	// Counter adds delta to the counter series identified by name and labels.
+	Counter(name string, labels Labels, delta float64)
+
+	// Gauge adds delta, which may be negative, to the gauge series identified by name and labels, e.g. 1 when
+	// a request starts and -1 when it ends.
+	Gauge(name string, labels Labels, delta float64)
+
+	// Observe records a value, e.g. a duration in seconds, in the histogram identified by name and labels.
+	// Histograms named *_bytes have SizeBuckets, the others DefaultBuckets.
+	Observe(name string, labels Labels, value float64)
+
+	// Close flushes pending data and releases resources.
+	Close() error
+}
+
+// Exposer is implemented by backends that are scraped, such as Prometheus, rather than pushing.
+type Exposer interface {
+	// WritePrometheus writes all series in the Prometheus text exposition format.
+	WritePrometheus(w io.Writer) error
+}
+
+// Config selects and configures a metrics backend.
+type Config struct {
+	// Backend is one of BackendPrometheus, BackendStatsD, BackendOTLP or BackendNone.
+	Backend string
+
+	// StatsDAddress is the UDP host:port of the StatsD agent.
+	StatsDAddress string
+
+	// OTLPEndpoint is the OTLP/HTTP metrics URL of the collector.
+	OTLPEndpoint string
+
+	// PushInterval is how often the OTLP backend pushes; zero uses DefaultPushInterval.
+	PushInterval time.Duration
+}
+
+// New creates the backend selected by the configuration.
+func New(cfg Config, logger zerolog.Logger) (Backend, error) {
+	switch cfg.Backend {
+	case BackendPrometheus:
+		return NewRegistry(), nil
+	case BackendStatsD:
+		return NewStatsD(cfg.StatsDAddress)
+	case BackendOTLP:
+		// A nil *OTLPExporter must not be returned as a non-nil Backend.
+		exporter, err := NewOTLPExporter(cfg.OTLPEndpoint, cfg.PushInterval, logger)
+		if err != nil {
+			return nil, err
+		}
+		return exporter, nil
+	case BackendNone:
+		return Nop{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported metrics backend '%s', must be one of: prometheus, statsd, otlp, none",
+			cfg.Backend)
+	}
+}
+
+// Nop is a backend that discards all updates.
+type Nop struct{}
+
+// Counter discards the update.
+func (Nop) Counter(string, Labels, float64) {}
+
+// Gauge discards the update.
+func (Nop) Gauge(string, Labels, float64) {}
+
+// Observe discards the update.
+func (Nop) Observe(string, Labels, float64) {}
+
+// Close does nothing.
+func (Nop) Close() error { return nil }
@@ -0,0 +1,42 @@
+// Package metrics contains tests for metrics recording and export.
+// This file tests backend selection.
+package metrics
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// TestNew verifies that each configured backend is created, and unknown backends are rejected.
+func TestNew(t *testing.T) {
+	tests := []struct {
+		backend     string
+		wantExposer bool
+		wantErr     bool
+	}{
+		{BackendPrometheus, true, false},
+		{BackendStatsD, false, false},
+		{BackendOTLP, false, false},
+		{BackendNone, false, false},
+		{"graphite", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			backend, err := New(Config{Backend: tt.backend, OTLPEndpoint: "http://127.0.0.1:1/v1/metrics"},
+				zerolog.Nop())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, ok := backend.(Exposer); ok != tt.wantExposer {
+				t.Errorf("expected exposer %v, got %v", tt.wantExposer, ok)
+			}
+			backend.Counter("requests", nil, 1)
+			_ = backend.Close()
+		})
+	}
+}
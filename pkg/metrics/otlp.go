@@ -0,0 +1,177 @@
+// Package metrics records application counters, gauges and histograms and exports them to a configurable backend.
+// This file implements the OTLP backend, which periodically pushes aggregated series to a collector.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// otlpServiceName is reported as the service.name resource attribute.
+const otlpServiceName = "k8s-controller"
+
+// otlpScope is the instrumentation scope of the series.
+const otlpScope = "github.com/Searge/k8s-controller/pkg/metrics"
+
+// OTLPExporter aggregates series with the OpenTelemetry SDK and pushes them as cumulative OTLP/HTTP
+// to a collector at a fixed interval, and once more on Close. Gauges are changed by deltas, which
+// makes them up-down counters, exported as non-monotonic sums.
+type OTLPExporter struct {
+	provider *sdkmetric.MeterProvider
+	reader   *sdkmetric.ManualReader
+	exporter sdkmetric.Exporter
+	meter    metric.Meter
+	endpoint string
+	logger   zerolog.Logger
+
+	mu         sync.Mutex
+	counters   map[string]metric.Float64Counter
+	gauges     map[string]metric.Float64UpDownCounter
+	histograms map[string]metric.Float64Histogram
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewOTLPExporter creates an OTLP backend pushing to the OTLP/HTTP metrics endpoint and starts pushing.
+func NewOTLPExporter(endpoint string, interval time.Duration, logger zerolog.Logger) (*OTLPExporter, error) {
+	if endpoint == "" {
+		endpoint = DefaultOTLPEndpoint
+	}
+	if interval <= 0 {
+		interval = DefaultPushInterval
+	}
+
+	exporter, err := otlpmetrichttp.New(context.Background(), otlpmetrichttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP metrics exporter: %w", err)
+	}
+	reader := sdkmetric.NewManualReader(
+		sdkmetric.WithTemporalitySelector(exporter.Temporality),
+		sdkmetric.WithAggregationSelector(exporter.Aggregation),
+	)
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(resource.NewSchemaless(attribute.String("service.name", otlpServiceName))),
+	)
+
+	e := &OTLPExporter{
+		provider:   provider,
+		reader:     reader,
+		exporter:   exporter,
+		meter:      provider.Meter(otlpScope),
+		endpoint:   endpoint,
+		logger:     logger.With().Str("component", "metrics").Logger(),
+		counters:   map[string]metric.Float64Counter{},
+		gauges:     map[string]metric.Float64UpDownCounter{},
+		histograms: map[string]metric.Float64Histogram{},
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go e.run(interval)
+	return e, nil
+}
+
+// Counter adds delta to a counter series.
+func (e *OTLPExporter) Counter(name string, labels Labels, delta float64) {
+	counter := otlpInstrument(e, e.counters, name, func() (metric.Float64Counter, error) {
+		return e.meter.Float64Counter(name)
+	})
+	counter.Add(context.Background(), delta, otlpAttributes(labels))
+}
+
+// Gauge adds delta to a gauge series.
+func (e *OTLPExporter) Gauge(name string, labels Labels, delta float64) {
+	gauge := otlpInstrument(e, e.gauges, name, func() (metric.Float64UpDownCounter, error) {
+		return e.meter.Float64UpDownCounter(name)
+	})
+	gauge.Add(context.Background(), delta, otlpAttributes(labels))
+}
+
+// Observe records a value in a histogram series, with the buckets of the Prometheus backend.
+func (e *OTLPExporter) Observe(name string, labels Labels, value float64) {
+	histogram := otlpInstrument(e, e.histograms, name, func() (metric.Float64Histogram, error) {
+		return e.meter.Float64Histogram(name, metric.WithExplicitBucketBoundaries(histogramBuckets(name)...))
+	})
+	histogram.Record(context.Background(), value, otlpAttributes(labels))
+}
+
+// run pushes at every interval until Close is called.
+func (e *OTLPExporter) run(interval time.Duration) {
+	defer close(e.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			if err := e.Push(context.Background()); err != nil {
+				e.logger.Warn().Err(err).Msg("Failed to push metrics")
+			}
+		}
+	}
+}
+
+// Close stops periodic pushing, pushes the final state and shuts the exporter down.
+func (e *OTLPExporter) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+		ctx := context.Background()
+		err = errors.Join(e.Push(ctx), e.exporter.Shutdown(ctx), e.provider.Shutdown(ctx))
+	})
+	return err
+}
+
+// Push sends the current state of all series to the collector.
+func (e *OTLPExporter) Push(ctx context.Context) error {
+	var data metricdata.ResourceMetrics
+	if err := e.reader.Collect(ctx, &data); err != nil {
+		return fmt.Errorf("failed to collect metrics: %w", err)
+	}
+	if err := e.exporter.Export(ctx, &data); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", e.endpoint, err)
+	}
+	return nil
+}
+
+// otlpInstrument returns the instrument of a series name from cache, creating it on first use. The SDK
+// returns a working instrument along with errors about invalid names, which are logged once.
+func otlpInstrument[T any](e *OTLPExporter, cache map[string]T, name string, create func() (T, error)) T {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	instrument, ok := cache[name]
+	if !ok {
+		var err error
+		if instrument, err = create(); err != nil {
+			e.logger.Warn().Err(err).Str("metric", name).Msg("Invalid metric")
+		}
+		cache[name] = instrument
+	}
+	return instrument
+}
+
+// otlpAttributes converts labels into OTLP string attributes.
+func otlpAttributes(labels Labels) metric.MeasurementOption {
+	attributes := make([]attribute.KeyValue, 0, len(labels))
+	for key, value := range labels {
+		attributes = append(attributes, attribute.String(key, value))
+	}
+	return metric.WithAttributes(attributes...)
+}
@@ -0,0 +1,85 @@
+// Package metrics contains tests for metrics recording and export.
+// This file tests the OTLP backend.
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestOTLPExporterPushesOnClose verifies that the series are pushed on Close as OTLP counters, non-monotonic
+// sums and histograms with the buckets of the Prometheus backend.
+func TestOTLPExporterPushesOnClose(t *testing.T) {
+	requests := make(chan *collectorpb.ExportMetricsServiceRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := &collectorpb.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		requests <- request
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL+"/v1/metrics", time.Hour, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewOTLPExporter() error = %v", err)
+	}
+	exporter.Counter("requests", Labels{"code": "200"}, 2)
+	exporter.Gauge("in_flight", nil, 3)
+	exporter.Observe("duration", nil, 0.2)
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	metrics := map[string]int{}
+	request := <-requests
+	for _, metric := range request.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metrics[metric.Name]++
+		switch metric.Name {
+		case "requests":
+			sum := metric.GetSum()
+			if sum == nil || !sum.IsMonotonic || sum.DataPoints[0].GetAsDouble() != 2 {
+				t.Errorf("unexpected counter %v", metric)
+			}
+		case "in_flight":
+			sum := metric.GetSum()
+			if sum == nil || sum.IsMonotonic || sum.DataPoints[0].GetAsDouble() != 3 {
+				t.Errorf("unexpected gauge %v", metric)
+			}
+		case "duration":
+			histogram := metric.GetHistogram()
+			if histogram == nil || histogram.DataPoints[0].Count != 1 ||
+				len(histogram.DataPoints[0].ExplicitBounds) != len(DefaultBuckets) {
+				t.Errorf("unexpected histogram %v", metric)
+			}
+		}
+	}
+	if len(metrics) != 3 {
+		t.Errorf("expected requests, in_flight and duration, got %v", metrics)
+	}
+}
+
+// TestOTLPExporterPushError verifies that collector errors are reported.
+func TestOTLPExporterPushError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL+"/v1/metrics", time.Hour, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewOTLPExporter() error = %v", err)
+	}
+	exporter.Counter("requests", nil, 1)
+	if err := exporter.Close(); err == nil {
+		t.Error("expected an error for a rejected push")
+	}
+}
@@ -0,0 +1,258 @@
+// Package metrics records application counters, gauges and histograms and exports them to a configurable backend.
+// This file implements the in-memory registry and its Prometheus text exposition.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram upper bounds, suited to request durations in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// SizeBuckets are the histogram upper bounds of sizes in bytes, used by histograms named *_bytes.
+var SizeBuckets = []float64{100, 1000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000}
+
+// CounterPoint is the current value of a counter series.
+type CounterPoint struct {
+	Name   string
+	Labels Labels
+	Value  float64
+}
+
+// GaugePoint is the current value of a gauge series.
+type GaugePoint struct {
+	Name   string
+	Labels Labels
+	Value  float64
+}
+
+// HistogramPoint is the current state of a histogram series.
+type HistogramPoint struct {
+	Name   string
+	Labels Labels
+
+	// Bounds are the bucket upper bounds; BucketCounts has one more entry, for values above the last bound.
+	Bounds       []float64
+	BucketCounts []uint64
+	Count        uint64
+	Sum          float64
+}
+
+// Snapshot is a consistent copy of all series, sorted by name and labels.
+type Snapshot struct {
+	Counters   []CounterPoint
+	Gauges     []GaugePoint
+	Histograms []HistogramPoint
+}
+
+// Registry aggregates counters, gauges and histograms in memory. It is the Prometheus backend.
+type Registry struct {
+	mu         sync.Mutex
+	counters   map[string]*CounterPoint
+	gauges     map[string]*GaugePoint
+	histograms map[string]*HistogramPoint
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{counters: map[string]*CounterPoint{}, gauges: map[string]*GaugePoint{},
+		histograms: map[string]*HistogramPoint{}}
+}
+
+// Counter adds delta to a counter series.
+func (r *Registry) Counter(name string, labels Labels, delta float64) {
+	key := seriesKey(name, labels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	point, ok := r.counters[key]
+	if !ok {
+		point = &CounterPoint{Name: name, Labels: copyLabels(labels)}
+		r.counters[key] = point
+	}
+	point.Value += delta
+}
+
+// Gauge adds delta to a gauge series.
+func (r *Registry) Gauge(name string, labels Labels, delta float64) {
+	key := seriesKey(name, labels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	point, ok := r.gauges[key]
+	if !ok {
+		point = &GaugePoint{Name: name, Labels: copyLabels(labels)}
+		r.gauges[key] = point
+	}
+	point.Value += delta
+}
+
+// Observe records a value in a histogram series, with SizeBuckets if its name ends in _bytes and
+// DefaultBuckets otherwise.
+func (r *Registry) Observe(name string, labels Labels, value float64) {
+	key := seriesKey(name, labels)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	point, ok := r.histograms[key]
+	if !ok {
+		bounds := histogramBuckets(name)
+		point = &HistogramPoint{Name: name, Labels: copyLabels(labels), Bounds: bounds,
+			BucketCounts: make([]uint64, len(bounds)+1)}
+		r.histograms[key] = point
+	}
+	point.BucketCounts[sort.SearchFloat64s(point.Bounds, value)]++
+	point.Count++
+	point.Sum += value
+}
+
+// histogramBuckets returns the bucket upper bounds of a histogram: SizeBuckets if its name ends in _bytes
+// and DefaultBuckets otherwise.
+func histogramBuckets(name string) []float64 {
+	if strings.HasSuffix(name, "_bytes") {
+		return SizeBuckets
+	}
+	return DefaultBuckets
+}
+
+// Close does nothing; the registry holds no resources.
+func (r *Registry) Close() error {
+	return nil
+}
+
+// Snapshot returns a copy of all series.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var s Snapshot
+	for _, point := range r.counters {
+		s.Counters = append(s.Counters, *point)
+	}
+	for _, point := range r.gauges {
+		s.Gauges = append(s.Gauges, *point)
+	}
+	for _, point := range r.histograms {
+		pointCopy := *point
+		pointCopy.BucketCounts = append([]uint64(nil), point.BucketCounts...)
+		s.Histograms = append(s.Histograms, pointCopy)
+	}
+
+	// Series are grouped by name first, so each metric family is contiguous.
+	sort.Slice(s.Counters, func(i, j int) bool {
+		return seriesLess(s.Counters[i].Name, s.Counters[i].Labels, s.Counters[j].Name, s.Counters[j].Labels)
+	})
+	sort.Slice(s.Gauges, func(i, j int) bool {
+		return seriesLess(s.Gauges[i].Name, s.Gauges[i].Labels, s.Gauges[j].Name, s.Gauges[j].Labels)
+	})
+	sort.Slice(s.Histograms, func(i, j int) bool {
+		a, b := s.Histograms[i], s.Histograms[j]
+		return seriesLess(a.Name, a.Labels, b.Name, b.Labels)
+	})
+	return s
+}
+
+// seriesLess orders series by name, then by labels.
+func seriesLess(nameA string, labelsA Labels, nameB string, labelsB Labels) bool {
+	if nameA != nameB {
+		return nameA < nameB
+	}
+	return formatLabels(labelsA, "") < formatLabels(labelsB, "")
+}
+
+// WritePrometheus writes all series in the Prometheus text exposition format.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	snapshot := r.Snapshot()
+	out := bufio.NewWriter(w)
+
+	lastName := ""
+	for _, point := range snapshot.Counters {
+		if point.Name != lastName {
+			fmt.Fprintf(out, "# TYPE %s counter\n", point.Name)
+			lastName = point.Name
+		}
+		fmt.Fprintf(out, "%s%s %s\n", point.Name, formatLabels(point.Labels, ""), formatFloat(point.Value))
+	}
+	for _, point := range snapshot.Gauges {
+		if point.Name != lastName {
+			fmt.Fprintf(out, "# TYPE %s gauge\n", point.Name)
+			lastName = point.Name
+		}
+		fmt.Fprintf(out, "%s%s %s\n", point.Name, formatLabels(point.Labels, ""), formatFloat(point.Value))
+	}
+	for _, point := range snapshot.Histograms {
+		if point.Name != lastName {
+			fmt.Fprintf(out, "# TYPE %s histogram\n", point.Name)
+			lastName = point.Name
+		}
+		writeHistogram(out, point)
+	}
+	return out.Flush()
+}
+
+// writeHistogram writes the cumulative buckets, sum and count of a histogram series.
+func writeHistogram(out io.Writer, point HistogramPoint) {
+	var cumulative uint64
+	for i, bound := range point.Bounds {
+		cumulative += point.BucketCounts[i]
+		fmt.Fprintf(out, "%s_bucket%s %d\n", point.Name, formatLabels(point.Labels, formatFloat(bound)), cumulative)
+	}
+	fmt.Fprintf(out, "%s_bucket%s %d\n", point.Name, formatLabels(point.Labels, "+Inf"), point.Count)
+	fmt.Fprintf(out, "%s_sum%s %s\n", point.Name, formatLabels(point.Labels, ""), formatFloat(point.Sum))
+	fmt.Fprintf(out, "%s_count%s %d\n", point.Name, formatLabels(point.Labels, ""), point.Count)
+}
+
+// formatLabels renders labels as {k="v",...} in key order, with an optional le label for buckets.
+func formatLabels(labels Labels, le string) string {
+	pairs := make([]string, 0, len(labels)+1)
+	for _, key := range sortedKeys(labels) {
+		pairs = append(pairs, key+`="`+escapeLabelValue(labels[key])+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escapeLabelValue escapes backslashes, quotes and newlines in a label value.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat renders a sample value in the shortest exact form.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// seriesKey identifies a series by its name and sorted labels.
+func seriesKey(name string, labels Labels) string {
+	return name + formatLabels(labels, "")
+}
+
+// copyLabels copies labels, so callers may reuse their map.
+func copyLabels(labels Labels) Labels {
+	labelsCopy := make(Labels, len(labels))
+	for key, value := range labels {
+		labelsCopy[key] = value
+	}
+	return labelsCopy
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+// Package metrics contains tests for metrics recording and export.
+// This file tests the in-memory registry and its Prometheus exposition.
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestRegistryPrometheus verifies aggregation and the Prometheus text format of counters, gauges and histograms.
+func TestRegistryPrometheus(t *testing.T) {
+	r := NewRegistry()
+	labels := Labels{"route": "/health", "code": "200"}
+	r.Counter("requests_total", labels, 1)
+	r.Counter("requests_total", labels, 2)
+	r.Counter("requests_total", Labels{"route": `say "hi"`, "code": "500"}, 1)
+	r.Counter("requests", nil, 4)
+	r.Gauge("in_flight", Labels{"route": "/health"}, 1)
+	r.Gauge("in_flight", Labels{"route": "/health"}, 1)
+	r.Gauge("in_flight", Labels{"route": "/health"}, -1)
+	r.Observe("duration_seconds", nil, 0.003)
+	r.Observe("duration_seconds", nil, 0.01)
+	r.Observe("duration_seconds", nil, 20)
+	labels["code"] = "404"
+
+	var out bytes.Buffer
+	if err := r.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+
+	want := `# TYPE requests counter
+requests 4
+# TYPE requests_total counter
+requests_total{code="200",route="/health"} 3
+requests_total{code="500",route="say \"hi\""} 1
+# TYPE in_flight gauge
+in_flight{route="/health"} 1
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.005"} 1
+duration_seconds_bucket{le="0.01"} 2
+duration_seconds_bucket{le="0.025"} 2
+duration_seconds_bucket{le="0.05"} 2
+duration_seconds_bucket{le="0.1"} 2
+duration_seconds_bucket{le="0.25"} 2
+duration_seconds_bucket{le="0.5"} 2
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="2.5"} 2
+duration_seconds_bucket{le="5"} 2
+duration_seconds_bucket{le="10"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 20.013
+duration_seconds_count 3
+`
+	if out.String() != want {
+		t.Errorf("WritePrometheus() got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+// TestRegistrySizeBuckets verifies that histograms of sizes in bytes have SizeBuckets.
+func TestRegistrySizeBuckets(t *testing.T) {
+	r := NewRegistry()
+	r.Observe("response_size_bytes", nil, 5000)
+	r.Observe("duration_seconds", nil, 5000)
+
+	histograms := r.Snapshot().Histograms
+	if got := histograms[1]; got.Name != "response_size_bytes" || len(got.Bounds) != len(SizeBuckets) ||
+		got.BucketCounts[2] != 1 {
+		t.Errorf("expected the size in the 10000 bucket of SizeBuckets, got %+v", got)
+	}
+	if got := histograms[0]; len(got.Bounds) != len(DefaultBuckets) || got.BucketCounts[len(DefaultBuckets)] != 1 {
+		t.Errorf("expected the duration above DefaultBuckets, got %+v", got)
+	}
+}
+
+// TestRegistrySnapshotIsCopy verifies that snapshots are not changed by later updates.
+func TestRegistrySnapshotIsCopy(t *testing.T) {
+	r := NewRegistry()
+	r.Observe("latency", nil, 1)
+	snapshot := r.Snapshot()
+	r.Observe("latency", nil, 1)
+
+	if snapshot.Histograms[0].Count != 1 || snapshot.Histograms[0].BucketCounts[7] != 1 {
+		t.Errorf("expected the snapshot to keep a single observation, got %+v", snapshot.Histograms[0])
+	}
+}
@@ -0,0 +1,70 @@
+// Package metrics records application counters, gauges and histograms and exports them to a configurable backend.
+// This file implements the StatsD backend, which pushes every update to a StatsD agent over UDP.
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// StatsD sends each update as a StatsD line to an agent. Labels are sent as DogStatsD tags
+// (name:1|c|#method:GET), which Datadog agents and Telegraf understand.
+// Sending is fire-and-forget: UDP write errors are ignored, as is usual for StatsD.
+type StatsD struct {
+	conn net.Conn
+}
+
+// NewStatsD creates a StatsD backend sending to the UDP host:port address.
+func NewStatsD(address string) (*StatsD, error) {
+	if address == "" {
+		address = DefaultStatsDAddress
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open StatsD connection to %s: %w", address, err)
+	}
+	return &StatsD{conn: conn}, nil
+}
+
+// Counter sends a counter increment.
+func (s *StatsD) Counter(name string, labels Labels, delta float64) {
+	s.send(name, formatFloat(delta), "c", labels)
+}
+
+// Gauge sends a gauge change. Positive changes are signed, since an unsigned value would set the gauge.
+func (s *StatsD) Gauge(name string, labels Labels, delta float64) {
+	value := formatFloat(delta)
+	if delta >= 0 {
+		value = "+" + value
+	}
+	s.send(name, value, "g", labels)
+}
+
+// Observe sends a histogram sample.
+func (s *StatsD) Observe(name string, labels Labels, value float64) {
+	s.send(name, formatFloat(value), "h", labels)
+}
+
+// Close closes the UDP connection.
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+// send writes a single StatsD line.
+func (s *StatsD) send(name, value, metricType string, labels Labels) {
+	_, _ = s.conn.Write([]byte(statsDLine(name, value, metricType, labels)))
+}
+
+// statsDLine formats a StatsD line with labels as sorted DogStatsD tags.
+func statsDLine(name, value, metricType string, labels Labels) string {
+	line := name + ":" + value + "|" + metricType
+	if len(labels) == 0 {
+		return line
+	}
+	tags := make([]string, 0, len(labels))
+	for _, key := range sortedKeys(labels) {
+		tags = append(tags, key+":"+labels[key])
+	}
+	return line + "|#" + strings.Join(tags, ",")
+}
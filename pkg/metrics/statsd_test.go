@@ -0,0 +1,64 @@
+// Package metrics contains tests for metrics recording and export.
+// This file tests the StatsD backend.
+package metrics
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// TestStatsDLine verifies the StatsD line format with DogStatsD tags.
+func TestStatsDLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels Labels
+		want   string
+	}{
+		{"without labels", nil, "requests:1|c"},
+		{"sorted tags", Labels{"route": "/health", "code": "200"}, "requests:1|c|#code:200,route:/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statsDLine("requests", "1", "c", tt.labels); got != tt.want {
+				t.Errorf("statsDLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStatsDSends verifies that counters, gauge changes and histogram samples are sent as UDP packets.
+func TestStatsDSends(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	backend, err := NewStatsD(listener.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("NewStatsD() error = %v", err)
+	}
+	defer func() { _ = backend.Close() }()
+
+	backend.Counter("requests", Labels{"code": "200"}, 1)
+	backend.Observe("duration", nil, 0.25)
+	backend.Gauge("in_flight", nil, 1)
+	backend.Gauge("in_flight", nil, -1)
+
+	buf := make([]byte, 512)
+	for _, want := range []string{"requests:1|c|#code:200", "duration:0.25|h", "in_flight:+1|g",
+		"in_flight:-1|g"} {
+		if err := listener.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		n, _, err := listener.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("failed to read packet: %v", err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Errorf("got packet %q, want %q", got, want)
+		}
+	}
+}
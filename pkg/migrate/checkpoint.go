@@ -0,0 +1,108 @@
+// Package migrate implements bulk, rate-limited changes across many cluster objects.
+// This file implements the checkpoint file that makes a label migration resumable.
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+)
+
+// Failure is an object a migration failed to change.
+type Failure struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Error     string `json:"error"`
+}
+
+// Report summarizes a label migration, accumulated across resumed runs.
+type Report struct {
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Migrated map[string]int `json:"migrated"`
+	Failed   []Failure      `json:"failed,omitempty"`
+	Started  time.Time      `json:"started"`
+	Finished time.Time      `json:"finished,omitempty"`
+}
+
+// Total returns the number of migrated objects across all kinds.
+func (r Report) Total() int {
+	total := 0
+	for _, n := range r.Migrated {
+		total += n
+	}
+	return total
+}
+
+// Checkpoint is the persisted state of a label migration.
+// Kinds without failures are marked complete and skipped when the migration is resumed.
+type Checkpoint struct {
+	Report
+	CompletedKinds []string `json:"completedKinds,omitempty"`
+}
+
+// completed reports whether all objects of kind were migrated by a previous run.
+func (c *Checkpoint) completed(kind string) bool {
+	return slices.Contains(c.CompletedKinds, kind)
+}
+
+// clearFailures drops the recorded failures of kind, before its objects are retried.
+func (c *Checkpoint) clearFailures(kind string) {
+	c.Failed = slices.DeleteFunc(c.Failed, func(f Failure) bool { return f.Kind == kind })
+}
+
+// LoadCheckpoint reads the checkpoint at path. A missing file starts a new migration from change.
+// An existing checkpoint for a different change is rejected, so a file is never resumed by mistake.
+func LoadCheckpoint(path string, change LabelChange, now time.Time) (*Checkpoint, error) {
+	fresh := &Checkpoint{Report: Report{
+		From: change.From(), To: change.To(), Migrated: map[string]int{}, Started: now,
+	}}
+	if path == "" {
+		return fresh, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse migration checkpoint: %w", err)
+	}
+	if checkpoint.From != fresh.From || checkpoint.To != fresh.To {
+		return nil, fmt.Errorf("checkpoint %s is for migrating %s to %s, not %s to %s",
+			path, checkpoint.From, checkpoint.To, fresh.From, fresh.To)
+	}
+	if checkpoint.Migrated == nil {
+		checkpoint.Migrated = map[string]int{}
+	}
+	return &checkpoint, nil
+}
+
+// Save atomically replaces the checkpoint at path. An empty path saves nothing.
+func (c *Checkpoint) Save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode migration checkpoint: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("failed to write migration checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace migration checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package migrate contains tests for bulk cluster changes.
+// This file tests loading and saving migration checkpoints.
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCheckpointRoundTrip verifies that a saved checkpoint is resumed and rejected for other changes.
+func TestCheckpointRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	change, _ := ParseLabelChange(testFrom, testTo)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	checkpoint, err := LoadCheckpoint(path, change, now)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	checkpoint.Migrated["deployment.apps"] = 3
+	checkpoint.CompletedKinds = []string{"deployment.apps"}
+	if err := checkpoint.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	resumed, err := LoadCheckpoint(path, change, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	if resumed.Total() != 3 || !resumed.completed("deployment.apps") || !resumed.Started.Equal(now) {
+		t.Errorf("unexpected resumed checkpoint %+v", resumed)
+	}
+
+	other, _ := ParseLabelChange(testFrom, "team=other")
+	if _, err := LoadCheckpoint(path, other, now); err == nil {
+		t.Error("expected a checkpoint for a different change to be rejected")
+	}
+}
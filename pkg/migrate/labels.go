@@ -0,0 +1,212 @@
+// Package migrate implements bulk, rate-limited changes across many cluster objects.
+// This file implements the label migration, which relabels matching objects with throttling.
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// DefaultRate is the default number of objects changed per second.
+const DefaultRate = 10.0
+
+// LabelChange replaces one label with another. The keys may differ, which renames the label.
+type LabelChange struct {
+	FromKey, FromValue string
+	ToKey, ToValue     string
+}
+
+// ParseLabelChange parses a change from two "key=value" labels.
+func ParseLabelChange(from, to string) (LabelChange, error) {
+	fromKey, fromValue, err := parseLabel(from)
+	if err != nil {
+		return LabelChange{}, fmt.Errorf("invalid --from label: %w", err)
+	}
+	toKey, toValue, err := parseLabel(to)
+	if err != nil {
+		return LabelChange{}, fmt.Errorf("invalid --to label: %w", err)
+	}
+	if fromKey == toKey && fromValue == toValue {
+		return LabelChange{}, fmt.Errorf("--from and --to are the same label %s", from)
+	}
+	return LabelChange{FromKey: fromKey, FromValue: fromValue, ToKey: toKey, ToValue: toValue}, nil
+}
+
+// parseLabel splits a "key=value" label.
+func parseLabel(label string) (string, string, error) {
+	key, value, ok := strings.Cut(label, "=")
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("expected key=value, got %q", label)
+	}
+	return key, value, nil
+}
+
+// From returns the label being replaced as "key=value", which is also its label selector.
+func (c LabelChange) From() string { return c.FromKey + "=" + c.FromValue }
+
+// To returns the replacement label as "key=value".
+func (c LabelChange) To() string { return c.ToKey + "=" + c.ToValue }
+
+// patch returns the JSON merge patch applying the change, removing the old key if it is renamed.
+func (c LabelChange) patch() ([]byte, error) {
+	labels := map[string]any{c.ToKey: c.ToValue}
+	if c.FromKey != c.ToKey {
+		labels[c.FromKey] = nil
+	}
+	return json.Marshal(map[string]any{"metadata": map[string]any{"labels": labels}})
+}
+
+// ParseRate parses a rate such as "10/s", "300/m" or "2" (per second) into objects per second.
+func ParseRate(rate string) (float64, error) {
+	count, unit, _ := strings.Cut(rate, "/")
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate %q, expected a positive number like 10/s", rate)
+	}
+	switch unit {
+	case "", "s":
+		return n, nil
+	case "m":
+		return n / time.Minute.Seconds(), nil
+	case "h":
+		return n / time.Hour.Seconds(), nil
+	default:
+		return 0, fmt.Errorf("invalid rate unit %q in %q, must be one of: s, m, h", unit, rate)
+	}
+}
+
+// Progress describes one object processed by a migration.
+type Progress struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Done      int
+	Total     int
+	Err       error
+}
+
+// Options configures a label migration.
+type Options struct {
+	// Kinds are the resources to relabel.
+	Kinds []k8s.ResourceInfo
+
+	// Namespace limits the migration to one namespace. Empty means cluster-wide.
+	Namespace string
+
+	// Rate is the maximum number of objects changed per second. Zero uses DefaultRate.
+	Rate float64
+
+	// CheckpointPath is the checkpoint file to resume from and update. Empty disables resuming.
+	CheckpointPath string
+
+	// DryRun lists the matching objects without changing them. The report then counts
+	// the objects that would be migrated, and the checkpoint is neither read nor written.
+	DryRun bool
+
+	// OnProgress is called after each object, if set.
+	OnProgress func(Progress)
+}
+
+// MigrateLabels replaces a label on all matching objects of the given kinds. Failed objects are
+// recorded and skipped, so one bad object does not stop a large migration; they are retried when
+// the migration is resumed from its checkpoint. The returned report covers resumed runs as well.
+func MigrateLabels(ctx context.Context, client *k8s.Client, change LabelChange, opts Options) (Report, error) {
+	if opts.DryRun {
+		opts.CheckpointPath = ""
+	}
+	checkpoint, err := LoadCheckpoint(opts.CheckpointPath, change, time.Now())
+	if err != nil {
+		return Report{}, err
+	}
+	if opts.Rate <= 0 {
+		opts.Rate = DefaultRate
+	}
+	limiter := flowcontrol.NewTokenBucketRateLimiter(float32(opts.Rate), 1)
+	defer limiter.Stop()
+
+	for _, kind := range opts.Kinds {
+		if checkpoint.completed(kind.QualifiedName()) {
+			continue
+		}
+		if err := migrateKind(ctx, client, change, kind, opts, limiter, checkpoint); err != nil {
+			return checkpoint.Report, err
+		}
+	}
+
+	checkpoint.Finished = time.Now()
+	return checkpoint.Report, checkpoint.Save(opts.CheckpointPath)
+}
+
+// migrateKind relabels all matching objects of one kind, saving the checkpoint after each object.
+func migrateKind(ctx context.Context, client *k8s.Client, change LabelChange, kind k8s.ResourceInfo,
+	opts Options, limiter flowcontrol.RateLimiter, checkpoint *Checkpoint) error {
+	name := kind.QualifiedName()
+	list, err := client.GetDynamicClient().Resource(kind.GVR).Namespace(opts.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: change.From()})
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", kind.GVR.Resource, err)
+	}
+	data, err := change.patch()
+	if err != nil {
+		return fmt.Errorf("failed to build label patch: %w", err)
+	}
+
+	checkpoint.clearFailures(name)
+	for i, obj := range list.Items {
+		progress := Progress{Kind: name, Namespace: obj.GetNamespace(), Name: obj.GetName(), Done: i + 1,
+			Total: len(list.Items)}
+		if opts.DryRun {
+			checkpoint.Migrated[name]++
+		} else {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			progress.Err = patchObject(ctx, client, kind, progress, data, checkpoint)
+			if err := checkpoint.Save(opts.CheckpointPath); err != nil {
+				return err
+			}
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+
+	if !opts.DryRun && !hasFailures(checkpoint, name) {
+		checkpoint.CompletedKinds = append(checkpoint.CompletedKinds, name)
+	}
+	return nil
+}
+
+// patchObject applies the label patch to one object and records the outcome in the checkpoint.
+func patchObject(ctx context.Context, client *k8s.Client, kind k8s.ResourceInfo, progress Progress, data []byte,
+	checkpoint *Checkpoint) error {
+	_, err := client.Patch(ctx, kind.GVR, progress.Namespace, progress.Name, types.MergePatchType, data)
+	if err != nil {
+		checkpoint.Failed = append(checkpoint.Failed, Failure{
+			Kind: progress.Kind, Namespace: progress.Namespace, Name: progress.Name, Error: err.Error(),
+		})
+		return err
+	}
+	checkpoint.Migrated[progress.Kind]++
+	return nil
+}
+
+// hasFailures reports whether the checkpoint records failures for kind.
+func hasFailures(checkpoint *Checkpoint, kind string) bool {
+	for _, f := range checkpoint.Failed {
+		if f.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+// Package migrate contains tests for bulk cluster changes.
+// This file tests label change parsing, rate parsing and the label migration.
+package migrate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Migration test constants.
+const (
+	testNamespace = "shop"
+	testFrom      = "team=old"
+	testTo        = "team=new"
+	testDenied    = "locked"
+)
+
+// denyNamed rejects changes to objects with the given name.
+type denyNamed string
+
+// Authorize denies changes to the named object.
+func (d denyNamed) Authorize(_ context.Context, change authz.Change) (authz.Decision, error) {
+	return authz.Decision{Allowed: change.Name != string(d), Reason: "object is locked"}, nil
+}
+
+// labeledObjects returns deployments and a service labeled team=old, plus an unrelated deployment.
+func labeledObjects() []runtime.Object {
+	old := map[string]string{"team": "old"}
+	return []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: testNamespace, Labels: old}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: testDenied, Namespace: testNamespace, Labels: old}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: testNamespace,
+			Labels: map[string]string{"team": "other"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: testNamespace, Labels: old}},
+	}
+}
+
+// lookupKinds resolves resource names for the migration options.
+func lookupKinds(t *testing.T, names ...string) []k8s.ResourceInfo {
+	t.Helper()
+	var kinds []k8s.ResourceInfo
+	for _, name := range names {
+		info, err := k8s.LookupResource(name)
+		if err != nil {
+			t.Fatalf("LookupResource(%q) error = %v", name, err)
+		}
+		kinds = append(kinds, info)
+	}
+	return kinds
+}
+
+// TestParseLabelChange verifies parsing of --from and --to labels.
+func TestParseLabelChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		from, to  string
+		wantPatch string
+		wantErr   bool
+	}{
+		{"new value", testFrom, testTo, `{"metadata":{"labels":{"team":"new"}}}`, false},
+		{"renamed key", testFrom, "owner=old", `{"metadata":{"labels":{"owner":"old","team":null}}}`, false},
+		{"missing value", "team", testTo, "", true},
+		{"same label", testFrom, testFrom, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, err := ParseLabelChange(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabelChange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			patch, err := change.patch()
+			if err != nil || string(patch) != tt.wantPatch {
+				t.Errorf("patch() = %s, %v; want %s", patch, err, tt.wantPatch)
+			}
+		})
+	}
+}
+
+// TestParseRate verifies rate parsing in objects per second.
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate    string
+		want    float64
+		wantErr bool
+	}{
+		{"10/s", 10, false},
+		{"120/m", 2, false},
+		{"5", 5, false},
+		{"0/s", 0, true},
+		{"10/d", 0, true},
+		{"fast", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.rate)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRate(%q) = %v, %v; want %v", tt.rate, got, err, tt.want)
+		}
+	}
+}
+
+// TestMigrateLabels verifies relabeling, failure recording and resuming from the checkpoint.
+func TestMigrateLabels(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), labeledObjects()...)
+	client.SetAuthorizer(denyNamed(testDenied))
+	change, _ := ParseLabelChange(testFrom, testTo)
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+	opts := Options{Kinds: lookupKinds(t, "deployments", "services"), Rate: 1000, CheckpointPath: checkpointPath}
+
+	var progress []Progress
+	opts.OnProgress = func(p Progress) { progress = append(progress, p) }
+	report, err := MigrateLabels(context.Background(), client, change, opts)
+	if err != nil {
+		t.Fatalf("MigrateLabels() error = %v", err)
+	}
+	if report.Total() != 2 || len(report.Failed) != 1 || report.Failed[0].Name != testDenied {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(progress) != 3 || progress[0].Total != 2 {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+
+	deployments := lookupKinds(t, "deployments")[0]
+	got, err := client.GetDynamicClient().Resource(deployments.GVR).Namespace(testNamespace).
+		Get(context.Background(), "frontend", metav1.GetOptions{})
+	if err != nil || got.GetLabels()["team"] != "new" {
+		t.Errorf("expected frontend to be relabeled, got %v (%v)", got.GetLabels(), err)
+	}
+
+	// Resuming retries only the failed deployment; services are complete.
+	client.SetAuthorizer(nil)
+	progress = nil
+	report, err = MigrateLabels(context.Background(), client, change, opts)
+	if err != nil {
+		t.Fatalf("MigrateLabels() error = %v", err)
+	}
+	if report.Total() != 3 || len(report.Failed) != 0 || len(progress) != 1 {
+		t.Errorf("unexpected resumed report %+v, progress %+v", report, progress)
+	}
+}
+
+// TestMigrateLabelsDryRun verifies that a dry run counts matches without changing objects.
+func TestMigrateLabelsDryRun(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), labeledObjects()...)
+	change, _ := ParseLabelChange(testFrom, testTo)
+
+	report, err := MigrateLabels(context.Background(), client, change,
+		Options{Kinds: lookupKinds(t, "deployments"), DryRun: true})
+	if err != nil || report.Total() != 2 {
+		t.Fatalf("MigrateLabels() = %+v, %v", report, err)
+	}
+
+	deployments := lookupKinds(t, "deployments")[0]
+	list, err := client.GetDynamicClient().Resource(deployments.GVR).Namespace(testNamespace).
+		List(context.Background(), metav1.ListOptions{LabelSelector: testFrom})
+	if err != nil || len(list.Items) != 2 {
+		t.Errorf("expected dry run to leave objects unchanged, got %d (%v)", len(list.Items), err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package pipeline runs declarative sequences of cluster operations loaded from a YAML file.
+// This file implements the step actions on top of the k8s client.
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// NotifyData is the data available to notify message templates, e.g. "{{.Pipeline}} {{.Status}}".
+type NotifyData struct {
+	Pipeline string
+	Step     string
+
+	// Status is StatusSucceeded while no step has failed the run, StatusFailed afterwards.
+	Status string
+
+	// Failed lists the steps that failed the run so far.
+	Failed []string
+
+	// RollingBack is set for notify steps of the rollback sequence.
+	RollingBack bool
+}
+
+// notifyPayload is the JSON body posted by notify steps. The text field suits Slack-compatible webhooks.
+type notifyPayload struct {
+	Text     string `json:"text"`
+	Pipeline string `json:"pipeline"`
+	Step     string `json:"step"`
+	Status   string `json:"status"`
+}
+
+// apply server-side applies the action's manifests.
+func (r *Runner) apply(ctx context.Context, p *Pipeline, action *ApplyAction) error {
+	objects, err := r.manifests(p, action)
+	if err != nil {
+		return err
+	}
+	applied, err := r.cluster.Apply(ctx, objects, r.namespace(p, ""))
+	if err != nil {
+		return err
+	}
+	r.logger.Info().Int("objects", len(applied)).Msg("Manifests applied")
+	return nil
+}
+
+// manifests decodes the inline manifest or reads the manifest file, resolved against the pipeline file.
+func (r *Runner) manifests(p *Pipeline, action *ApplyAction) ([]*unstructured.Unstructured, error) {
+	if action.Manifest != "" {
+		return k8s.DecodeManifests([]byte(action.Manifest))
+	}
+	path := action.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+	return k8s.ReadManifests(path)
+}
+
+// wait waits until the object meets the action's condition or the timeout passes.
+func (r *Runner) wait(ctx context.Context, p *Pipeline, action *WaitAction, timeout time.Duration) error {
+	info, name, condition, err := action.parse()
+	if err != nil {
+		return err
+	}
+	_, err = r.cluster.WaitForCondition(ctx, info.GVR, r.resourceNamespace(p, info, action.Namespace), name,
+		condition, timeout)
+	return err
+}
+
+// verify checks that the object meets the action's condition now.
+func (r *Runner) verify(ctx context.Context, p *Pipeline, action *VerifyAction) error {
+	info, name, condition, err := (*WaitAction)(action).parse()
+	if err != nil {
+		return err
+	}
+	met, err := r.cluster.CheckCondition(ctx, info.GVR, r.resourceNamespace(p, info, action.Namespace), name,
+		condition)
+	if err != nil {
+		return err
+	}
+	if !met {
+		return fmt.Errorf("%s does not meet %s", action.Resource, condition)
+	}
+	return nil
+}
+
+// resourceNamespace returns the namespace of a namespaced resource, or "" for cluster-scoped ones.
+func (r *Runner) resourceNamespace(p *Pipeline, info k8s.ResourceInfo, own string) string {
+	if !info.Namespaced {
+		return ""
+	}
+	return r.namespace(p, own)
+}
+
+// scale sets the deployment's replica count. Scale-downs run the preflight first and
+// are refused when it finds blockers, unless the action forces them.
+func (r *Runner) scale(ctx context.Context, p *Pipeline, action *ScaleAction) error {
+	ns, replicas := r.namespace(p, action.Namespace), *action.Replicas
+	current, err := r.cluster.GetDeployment(ctx, ns, action.Deployment)
+	if err != nil {
+		return err
+	}
+
+	if replicas < current.Replicas.Desired {
+		report, err := r.cluster.PreflightDeployment(ctx, ns, action.Deployment, k8s.OperationScale, replicas)
+		if err != nil {
+			return err
+		}
+		for _, finding := range report.Findings {
+			r.logger.Warn().Str("check", finding.Check).Str("severity", finding.Severity).Msg(finding.Message)
+		}
+		if !report.Safe() && !action.Force {
+			return fmt.Errorf("refusing unsafe scale-down of deployment %s/%s, set force to proceed anyway",
+				ns, action.Deployment)
+		}
+	}
+	return r.cluster.ScaleDeployment(ctx, ns, action.Deployment, replicas)
+}
+
+// notify renders the message and posts it to the webhook.
+func (r *Runner) notify(ctx context.Context, p *Pipeline, step Step, report *Report) error {
+	data := NotifyData{
+		Pipeline:    p.Name,
+		Step:        step.Name,
+		Status:      StatusSucceeded,
+		Failed:      report.failedSteps(),
+		RollingBack: report.rollingBack,
+	}
+	if len(data.Failed) > 0 {
+		data.Status = StatusFailed
+	}
+
+	text, err := renderMessage(step.Notify.Message, data)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(notifyPayload{Text: text, Pipeline: p.Name, Step: step.Name, Status: data.Status})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, step.Notify.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// parseMessage parses a notify message template, which may use join to format lists.
+func parseMessage(message string) (*template.Template, error) {
+	tmpl, err := template.New("message").Funcs(template.FuncMap{"join": strings.Join}).Parse(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderMessage executes a notify message template.
+func renderMessage(message string, data NotifyData) (string, error) {
+	tmpl, err := parseMessage(message)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render message: %w", err)
+	}
+	return out.String(), nil
+}
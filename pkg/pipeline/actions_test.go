@@ -0,0 +1,111 @@
+// Package pipeline contains tests for declarative pipelines.
+// This file tests the step actions.
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestApplyManifests verifies that manifest files resolve against the pipeline file and inline manifests decode.
+func TestApplyManifests(t *testing.T) {
+	dir := t.TempDir()
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n"
+	if err := os.WriteFile(filepath.Join(dir, "settings.yaml"), []byte(fmt.Sprintf(manifest, "settings")),
+		0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &fakeCluster{}
+	p := &Pipeline{Name: "test", Namespace: "web", dir: dir, Steps: []Step{
+		{Name: "file", Apply: &ApplyAction{File: "settings.yaml"}},
+		{Name: "inline", Apply: &ApplyAction{Manifest: fmt.Sprintf(manifest, "flags")}},
+	}}
+
+	report := NewRunner(cluster, Options{}, zerolog.Nop()).Run(context.Background(), p)
+	if !report.Succeeded {
+		t.Fatalf("expected the run to succeed, got %+v", report)
+	}
+	if got := fmt.Sprint(cluster.calls); got != "[apply web/settings apply web/flags]" {
+		t.Errorf("unexpected calls %s", got)
+	}
+}
+
+// TestScalePreflight verifies that unsafe scale-downs are refused unless forced and scale-ups skip the preflight.
+func TestScalePreflight(t *testing.T) {
+	blocked := k8s.PreflightReport{Findings: []k8s.PreflightFinding{{Check: "pdb", Severity: k8s.SeverityBlocker}}}
+	tests := []struct {
+		name      string
+		replicas  int32
+		force     bool
+		preflight k8s.PreflightReport
+		wantCalls string
+		wantErr   bool
+	}{
+		{"scale up", 5, false, blocked, "[scale default/web 5]", false},
+		{"safe scale down", 1, false, k8s.PreflightReport{}, "[preflight default/web 1 scale default/web 1]", false},
+		{"unsafe scale down", 1, false, blocked, "[preflight default/web 1]", true},
+		{"forced scale down", 1, true, blocked, "[preflight default/web 1 scale default/web 1]", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &fakeCluster{replicas: 3, preflight: tt.preflight}
+			p := &Pipeline{Name: "test"}
+			err := NewRunner(cluster, Options{}, zerolog.Nop()).scale(context.Background(), p,
+				&ScaleAction{Deployment: "web", Replicas: &tt.replicas, Force: tt.force})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scale() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := fmt.Sprint(cluster.calls); got != tt.wantCalls {
+				t.Errorf("expected calls %s, got %s", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+// TestNotify verifies the rendered message and payload posted to the webhook, and webhook errors.
+func TestNotify(t *testing.T) {
+	var payload notifyPayload
+	status := http.StatusOK
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer webhook.Close()
+
+	step := Step{Name: "announce", Notify: &NotifyAction{
+		URL:     webhook.URL,
+		Message: "{{.Pipeline}} {{.Status}}{{if .Failed}} at {{join .Failed \", \"}}{{end}}",
+	}}
+	report := &Report{Steps: []StepResult{
+		{Name: "apply", Status: StatusFailed},
+		{Name: "ignored", Status: StatusFailed, Ignored: true},
+	}}
+	runner := NewRunner(&fakeCluster{}, Options{}, zerolog.Nop())
+
+	if err := runner.notify(context.Background(), &Pipeline{Name: "release"}, step, report); err != nil {
+		t.Fatalf("notify() error = %v", err)
+	}
+	want := notifyPayload{Text: "release failed at apply", Pipeline: "release", Step: "announce", Status: "failed"}
+	if payload != want {
+		t.Errorf("expected payload %+v, got %+v", want, payload)
+	}
+
+	status = http.StatusInternalServerError
+	if err := runner.notify(context.Background(), &Pipeline{Name: "release"}, step, report); err == nil {
+		t.Error("expected an error for a failing webhook")
+	}
+}
@@ -0,0 +1,277 @@
+// Package pipeline runs declarative sequences of cluster operations loaded from a YAML file.
+// This file defines the pipeline file format and loads and validates pipeline files.
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Values of Step.When.
+const (
+	// WhenOnSuccess runs a step only while no earlier step has failed. It is the default.
+	WhenOnSuccess = "on-success"
+
+	// WhenOnFailure runs a step only after an earlier step has failed.
+	WhenOnFailure = "on-failure"
+
+	// WhenAlways runs a step regardless of earlier failures.
+	WhenAlways = "always"
+)
+
+// Pipeline is a named sequence of steps, with rollback steps that run if the sequence fails.
+type Pipeline struct {
+	// Name identifies the pipeline in reports and notifications; defaults to the file name.
+	Name string `yaml:"name"`
+
+	// Namespace is used by steps that don't set their own.
+	Namespace string `yaml:"namespace"`
+
+	// Steps run in order.
+	Steps []Step `yaml:"steps"`
+
+	// Rollback runs in order after a step of Steps failed.
+	Rollback []Step `yaml:"rollback"`
+
+	// dir is the directory of the pipeline file, which relative apply paths are resolved against.
+	dir string
+}
+
+// Step is a single operation of a pipeline. Exactly one of the action fields must be set.
+type Step struct {
+	Name string `yaml:"name"`
+
+	// When is WhenOnSuccess, WhenOnFailure or WhenAlways.
+	When string `yaml:"when"`
+
+	// Retries is how often a failed step is retried before it counts as failed.
+	Retries int `yaml:"retries"`
+
+	// RetryDelay is the pause between attempts; zero uses DefaultRetryDelay.
+	RetryDelay time.Duration `yaml:"retryDelay"`
+
+	// Timeout bounds each attempt; zero uses the runner's step timeout.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// ContinueOnError records a failure of the step without failing the pipeline.
+	ContinueOnError bool `yaml:"continueOnError"`
+
+	Apply   *ApplyAction   `yaml:"apply"`
+	Wait    *WaitAction    `yaml:"wait"`
+	Scale   *ScaleAction   `yaml:"scale"`
+	Restart *RestartAction `yaml:"restart"`
+	Verify  *VerifyAction  `yaml:"verify"`
+	Notify  *NotifyAction  `yaml:"notify"`
+}
+
+// ApplyAction server-side applies manifests from a file or directory, or given inline.
+type ApplyAction struct {
+	// File is a manifest file or a directory of .yaml, .yml and .json files,
+	// relative to the pipeline file unless absolute.
+	File string `yaml:"file"`
+
+	// Manifest is an inline multi-document manifest.
+	Manifest string `yaml:"manifest"`
+}
+
+// WaitAction waits until an object meets a condition, as the wait command does.
+type WaitAction struct {
+	// Resource is a TYPE/NAME reference, e.g. "deployment/web".
+	Resource  string `yaml:"resource"`
+	Namespace string `yaml:"namespace"`
+
+	// For is the condition in the syntax of the wait command's --for flag.
+	For string `yaml:"for"`
+}
+
+// ScaleAction sets the replica count of a deployment, as the scale command does.
+type ScaleAction struct {
+	Deployment string `yaml:"deployment"`
+	Namespace  string `yaml:"namespace"`
+	Replicas   *int32 `yaml:"replicas"`
+
+	// Force scales down even if the preflight finds it unsafe.
+	Force bool `yaml:"force"`
+}
+
+// RestartAction triggers a rolling restart of a deployment, as 'rollout restart' does.
+type RestartAction struct {
+	Deployment string `yaml:"deployment"`
+	Namespace  string `yaml:"namespace"`
+}
+
+// VerifyAction checks once that an object meets a condition and fails the step if it doesn't.
+type VerifyAction WaitAction
+
+// NotifyAction posts a message to a webhook, e.g. a Slack incoming webhook.
+type NotifyAction struct {
+	URL string `yaml:"url"`
+
+	// Message is a text/template rendered with NotifyData.
+	Message string `yaml:"message"`
+}
+
+// Load reads and validates the pipeline file at path.
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline: %w", err)
+	}
+
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline %s: %w", path, err)
+	}
+	p.dir = filepath.Dir(path)
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
+
+// Parse decodes and validates a pipeline. Unknown fields are rejected, so typos don't go unnoticed.
+func Parse(data []byte) (*Pipeline, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var p Pipeline
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse pipeline: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks that the pipeline has steps and that every step is complete and uniquely named.
+func (p *Pipeline) Validate() error {
+	if len(p.Steps) == 0 {
+		return errors.New("pipeline has no steps")
+	}
+
+	names := make(map[string]bool)
+	for _, step := range append(append([]Step{}, p.Steps...), p.Rollback...) {
+		if step.Name == "" {
+			return errors.New("every step needs a name")
+		}
+		if names[step.Name] {
+			return fmt.Errorf("duplicate step name %q", step.Name)
+		}
+		names[step.Name] = true
+
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// Action returns the name of the step's action, or "" if none or several are set.
+func (s Step) Action() string {
+	actions := map[string]bool{
+		"apply": s.Apply != nil, "wait": s.Wait != nil, "scale": s.Scale != nil, "restart": s.Restart != nil,
+		"verify": s.Verify != nil, "notify": s.Notify != nil,
+	}
+	action := ""
+	for name, set := range actions {
+		if set {
+			if action != "" {
+				return ""
+			}
+			action = name
+		}
+	}
+	return action
+}
+
+// validate checks the step's options and its action.
+func (s Step) validate() error {
+	switch s.When {
+	case "", WhenOnSuccess, WhenOnFailure, WhenAlways:
+	default:
+		return fmt.Errorf("unsupported when %q, must be one of: on-success, on-failure, always", s.When)
+	}
+	if s.Retries < 0 || s.RetryDelay < 0 || s.Timeout < 0 {
+		return errors.New("retries, retryDelay and timeout must not be negative")
+	}
+
+	switch s.Action() {
+	case "apply":
+		if (s.Apply.File == "") == (s.Apply.Manifest == "") {
+			return errors.New("apply needs exactly one of file and manifest")
+		}
+	case "wait":
+		_, _, _, err := s.Wait.parse()
+		return err
+	case "verify":
+		_, _, _, err := (*WaitAction)(s.Verify).parse()
+		return err
+	case "scale":
+		return s.Scale.validate()
+	case "restart":
+		if s.Restart.Deployment == "" {
+			return errors.New("restart needs a deployment")
+		}
+	case "notify":
+		return s.Notify.validate()
+	default:
+		return errors.New("exactly one of apply, wait, scale, restart, verify and notify must be set")
+	}
+	return nil
+}
+
+// parse resolves the action's resource reference and condition.
+func (a *WaitAction) parse() (k8s.ResourceInfo, string, k8s.WaitCondition, error) {
+	resource, name, err := k8s.ParseResourceRef(a.Resource)
+	if err != nil {
+		return k8s.ResourceInfo{}, "", k8s.WaitCondition{}, err
+	}
+	info, err := k8s.LookupResource(resource)
+	if err != nil {
+		return k8s.ResourceInfo{}, "", k8s.WaitCondition{}, err
+	}
+	if a.For == "" {
+		return k8s.ResourceInfo{}, "", k8s.WaitCondition{}, errors.New("missing condition in for")
+	}
+	condition, err := k8s.ParseWaitCondition(a.For)
+	if err != nil {
+		return k8s.ResourceInfo{}, "", k8s.WaitCondition{}, err
+	}
+	return info, name, condition, nil
+}
+
+// validate checks that the scale action names a deployment and a replica count.
+func (a *ScaleAction) validate() error {
+	if a.Deployment == "" {
+		return errors.New("scale needs a deployment")
+	}
+	if a.Replicas == nil || *a.Replicas < 0 {
+		return errors.New("scale needs a non-negative replicas count")
+	}
+	return nil
+}
+
+// validate checks the webhook URL and the message template.
+func (a *NotifyAction) validate() error {
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("notify needs an http or https url, got %q", a.URL)
+	}
+	if a.Message == "" {
+		return errors.New("notify needs a message")
+	}
+	_, err = parseMessage(a.Message)
+	return err
+}
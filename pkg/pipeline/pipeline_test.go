@@ -0,0 +1,125 @@
+// Package pipeline contains tests for declarative pipelines.
+// This file tests loading and validation of pipeline files.
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPipeline is a valid pipeline using every action.
+const testPipeline = `name: release
+namespace: web
+steps:
+  - name: apply
+    apply:
+      file: manifests
+  - name: rollout
+    wait:
+      resource: deployment/web
+      for: condition=Available
+    timeout: 2m
+    retries: 2
+    retryDelay: 10s
+  - name: scale up
+    scale:
+      deployment: web
+      replicas: 3
+  - name: restart
+    restart:
+      deployment: web
+  - name: check
+    verify:
+      resource: deployment/web
+      for: jsonpath={.status.readyReplicas}=3
+  - name: announce
+    when: always
+    notify:
+      url: https://hooks.example.com/release
+      message: "{{.Pipeline}} {{.Status}}"
+rollback:
+  - name: scale down
+    scale:
+      deployment: web
+      replicas: 0
+      force: true
+`
+
+// TestParse verifies decoding of a complete pipeline.
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(testPipeline))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.Name != "release" || len(p.Steps) != 6 || len(p.Rollback) != 1 {
+		t.Fatalf("unexpected pipeline %+v", p)
+	}
+
+	rollout := p.Steps[1]
+	if rollout.Action() != "wait" || rollout.Timeout != 2*time.Minute || rollout.RetryDelay != 10*time.Second {
+		t.Errorf("unexpected wait step %+v", rollout)
+	}
+	if scale := p.Rollback[0].Scale; scale == nil || *scale.Replicas != 0 || !scale.Force {
+		t.Errorf("unexpected rollback step %+v", p.Rollback[0])
+	}
+}
+
+// TestParseInvalid verifies that incomplete or inconsistent pipelines are rejected.
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   string
+		wantErr string
+	}{
+		{"no steps", "", "no steps"},
+		{"unnamed step", "  - apply: {manifest: x}", "needs a name"},
+		{"duplicate name", "  - {name: a, apply: {file: x}}\n  - {name: a, apply: {file: y}}", "duplicate"},
+		{"no action", "  - name: a", "exactly one of"},
+		{"two actions", "  - {name: a, apply: {file: x}, scale: {deployment: web, replicas: 1}}", "exactly one of"},
+		{"apply without source", "  - {name: a, apply: {}}", "exactly one of file and manifest"},
+		{"bad when", "  - {name: a, when: sometimes, apply: {file: x}}", "unsupported when"},
+		{"negative retries", "  - {name: a, retries: -1, apply: {file: x}}", "must not be negative"},
+		{"bad resource", "  - {name: a, wait: {resource: web, for: delete}}", "TYPE/NAME"},
+		{"unknown resource", "  - {name: a, verify: {resource: widget/web, for: delete}}", "unknown resource"},
+		{"bad condition", "  - {name: a, wait: {resource: deploy/web, for: ready}}", "unsupported condition"},
+		{"missing condition", "  - {name: a, wait: {resource: deploy/web}}", "missing condition"},
+		{"missing replicas", "  - {name: a, scale: {deployment: web}}", "replicas"},
+		{"restart without deployment", "  - {name: a, restart: {namespace: web}}", "restart needs a deployment"},
+		{"bad url", "  - {name: a, notify: {url: 'ftp://x', message: hi}}", "http or https"},
+		{"bad template", "  - {name: a, notify: {url: 'http://x', message: '{{.Nope'}}", "invalid message template"},
+		{"unknown field", "  - {name: a, retry: 2, apply: {file: x}}", "field retry not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte("steps:\n" + tt.steps + "\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestLoad verifies that the name defaults to the file name and relative paths resolve against its directory.
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deploy-web.yaml")
+	if err := os.WriteFile(path, []byte("steps:\n  - {name: a, apply: {file: x}}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if p.Name != "deploy-web" || p.dir != dir {
+		t.Errorf("expected name deploy-web in %s, got %q in %s", dir, p.Name, p.dir)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,258 @@
+// Package pipeline runs declarative sequences of cluster operations loaded from a YAML file.
+// This file implements the runner, which executes steps with conditions, retries and rollback.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Defaults for pipeline runs.
+const (
+	DefaultStepTimeout = 5 * time.Minute
+	DefaultRetryDelay  = 5 * time.Second
+	notifyTimeout      = 10 * time.Second
+)
+
+// Outcomes of a step.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// Cluster is the subset of the k8s client used by pipeline steps.
+// It is satisfied by *k8s.Client.
+type Cluster interface {
+	Apply(ctx context.Context, objects []*unstructured.Unstructured, ns string) ([]k8s.AppliedObject, error)
+	WaitForCondition(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+		condition k8s.WaitCondition, timeout time.Duration) (*unstructured.Unstructured, error)
+	CheckCondition(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+		condition k8s.WaitCondition) (bool, error)
+	GetDeployment(ctx context.Context, ns, name string) (k8s.DeploymentInfo, error)
+	PreflightDeployment(ctx context.Context, ns, name, operation string, target int32) (k8s.PreflightReport, error)
+	ScaleDeployment(ctx context.Context, ns, name string, replicas int32) error
+	RestartDeployment(ctx context.Context, ns, name string) error
+}
+
+// Options configures a pipeline run.
+type Options struct {
+	// Namespace overrides the pipeline's namespace for steps that don't set their own.
+	Namespace string
+
+	// StepTimeout bounds each attempt of steps without their own timeout.
+	StepTimeout time.Duration
+
+	// DryRun is the dry run mode of the cluster, which apply, scale and restart steps follow. In dry runs,
+	// wait, verify and notify steps are skipped: they would observe or announce changes that were not made.
+	DryRun k8s.DryRun
+}
+
+// StepResult records the outcome of a single step.
+type StepResult struct {
+	Name     string        `json:"name" yaml:"name"`
+	Action   string        `json:"action" yaml:"action"`
+	Status   string        `json:"status" yaml:"status"`
+	Attempts int           `json:"attempts" yaml:"attempts"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// Ignored reports that the step failed but had ContinueOnError set.
+	Ignored bool `json:"ignored,omitempty" yaml:"ignored,omitempty"`
+}
+
+// Report is the outcome of a pipeline run.
+type Report struct {
+	Pipeline  string       `json:"pipeline" yaml:"pipeline"`
+	Succeeded bool         `json:"succeeded" yaml:"succeeded"`
+	Steps     []StepResult `json:"steps" yaml:"steps"`
+	Rollback  []StepResult `json:"rollback,omitempty" yaml:"rollback,omitempty"`
+
+	// rollingBack is set while the rollback steps run.
+	rollingBack bool
+}
+
+// failedSteps returns the names of the steps that failed the run.
+func (r Report) failedSteps() []string {
+	var names []string
+	for _, step := range r.Steps {
+		if step.Status == StatusFailed && !step.Ignored {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+// Runner executes pipelines against a cluster.
+type Runner struct {
+	cluster Cluster
+	opts    Options
+	client  *http.Client
+	logger  zerolog.Logger
+}
+
+// NewRunner creates a Runner, filling unset options with defaults.
+func NewRunner(cluster Cluster, opts Options, logger zerolog.Logger) *Runner {
+	if opts.StepTimeout <= 0 {
+		opts.StepTimeout = DefaultStepTimeout
+	}
+	return &Runner{
+		cluster: cluster,
+		opts:    opts,
+		client:  &http.Client{Timeout: notifyTimeout},
+		logger:  logger.With().Str("component", "pipeline").Logger(),
+	}
+}
+
+// Run executes the pipeline's steps in order. Once a step fails, only steps that run on failure
+// or always are executed. If the run failed, the rollback steps are executed afterwards.
+func (r *Runner) Run(ctx context.Context, p *Pipeline) Report {
+	report := &Report{Pipeline: p.Name}
+	r.runSteps(ctx, p, p.Steps, report, &report.Steps)
+	report.Succeeded = len(report.failedSteps()) == 0
+
+	if !report.Succeeded && len(p.Rollback) > 0 {
+		r.logger.Warn().Str("pipeline", p.Name).Msg("Pipeline failed, running rollback steps")
+		report.rollingBack = true
+		r.runSteps(ctx, p, p.Rollback, report, &report.Rollback)
+	}
+	return *report
+}
+
+// runSteps executes a sequence of steps, appending their results to results as they finish,
+// and skips steps whose condition doesn't hold. Failures are tracked within the sequence,
+// so rollback steps are not skipped because of the failure that triggered them.
+func (r *Runner) runSteps(ctx context.Context, p *Pipeline, steps []Step, report *Report, results *[]StepResult) {
+	failed := false
+	for _, step := range steps {
+		if !shouldRun(step.When, failed) {
+			*results = append(*results, StepResult{Name: step.Name, Action: step.Action(), Status: StatusSkipped})
+			continue
+		}
+		if r.skippedInDryRun(step) {
+			r.logger.Info().Str("step", step.Name).Str("dry_run", string(r.opts.DryRun)).
+				Msg("Skipping step in dry run")
+			*results = append(*results, StepResult{Name: step.Name, Action: step.Action(), Status: StatusSkipped})
+			continue
+		}
+
+		result := r.runStep(ctx, p, step, report)
+		if result.Status == StatusFailed && !result.Ignored {
+			failed = true
+		}
+		*results = append(*results, result)
+	}
+}
+
+// shouldRun reports whether a step with the given condition runs after the earlier steps' outcome.
+func shouldRun(when string, failed bool) bool {
+	switch when {
+	case WhenAlways:
+		return true
+	case WhenOnFailure:
+		return failed
+	default:
+		return !failed
+	}
+}
+
+// skippedInDryRun reports whether the step is skipped in the dry run of the runner: only the steps
+// changing the cluster run, with the dry run mode of the cluster.
+func (r *Runner) skippedInDryRun(step Step) bool {
+	if r.opts.DryRun == "" || r.opts.DryRun == k8s.DryRunNone {
+		return false
+	}
+	return step.Apply == nil && step.Scale == nil && step.Restart == nil
+}
+
+// runStep executes a step, retrying failed attempts, and records its outcome.
+func (r *Runner) runStep(ctx context.Context, p *Pipeline, step Step, report *Report) StepResult {
+	result := StepResult{Name: step.Name, Action: step.Action()}
+	start := time.Now()
+	logger := r.logger.With().Str("step", step.Name).Str("action", result.Action).Logger()
+
+	var err error
+	for result.Attempts = 1; ; result.Attempts++ {
+		logger.Info().Int("attempt", result.Attempts).Msg("Running step")
+		if err = r.attempt(ctx, p, step, report); err == nil || result.Attempts > step.Retries {
+			break
+		}
+		logger.Warn().Err(err).Msg("Step failed, retrying")
+		if !sleep(ctx, retryDelay(step)) {
+			break
+		}
+	}
+
+	result.Duration = time.Since(start)
+	result.Status = StatusSucceeded
+	if err != nil {
+		result.Status, result.Error, result.Ignored = StatusFailed, err.Error(), step.ContinueOnError
+		logger.Error().Err(err).Bool("ignored", result.Ignored).Msg("Step failed")
+	}
+	return result
+}
+
+// attempt executes a step once within its timeout.
+func (r *Runner) attempt(ctx context.Context, p *Pipeline, step Step, report *Report) error {
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = r.opts.StepTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case step.Apply != nil:
+		return r.apply(ctx, p, step.Apply)
+	case step.Wait != nil:
+		return r.wait(ctx, p, step.Wait, timeout)
+	case step.Scale != nil:
+		return r.scale(ctx, p, step.Scale)
+	case step.Restart != nil:
+		return r.cluster.RestartDeployment(ctx, r.namespace(p, step.Restart.Namespace), step.Restart.Deployment)
+	case step.Verify != nil:
+		return r.verify(ctx, p, step.Verify)
+	case step.Notify != nil:
+		return r.notify(ctx, p, step, report)
+	default:
+		return fmt.Errorf("step %q has no action", step.Name)
+	}
+}
+
+// retryDelay returns the pause between attempts of a step.
+func retryDelay(step Step) time.Duration {
+	if step.RetryDelay > 0 {
+		return step.RetryDelay
+	}
+	return DefaultRetryDelay
+}
+
+// sleep pauses for d and reports whether the context is still active afterwards.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// namespace returns the namespace for an action: its own, the runner's override, the pipeline's, or "default".
+func (r *Runner) namespace(p *Pipeline, own string) string {
+	for _, ns := range []string{own, r.opts.Namespace, p.Namespace} {
+		if ns != "" {
+			return ns
+		}
+	}
+	return "default"
+}
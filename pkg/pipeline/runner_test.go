@@ -0,0 +1,257 @@
+// Package pipeline contains tests for declarative pipelines.
+// This file tests step conditions, retries and rollback of the runner.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// fakeCluster records the operations of a pipeline run and fails them on request.
+type fakeCluster struct {
+	calls    []string
+	replicas int32
+
+	// checkFailures is how many CheckCondition calls report an unmet condition before it is met.
+	checkFailures int
+	preflight     k8s.PreflightReport
+	scaleErr      error
+}
+
+// Apply records the applied objects.
+func (f *fakeCluster) Apply(_ context.Context, objects []*unstructured.Unstructured,
+	ns string) ([]k8s.AppliedObject, error) {
+	applied := make([]k8s.AppliedObject, 0, len(objects))
+	for _, obj := range objects {
+		f.calls = append(f.calls, fmt.Sprintf("apply %s/%s", ns, obj.GetName()))
+		applied = append(applied, k8s.AppliedObject{Kind: obj.GetKind(), Namespace: ns, Name: obj.GetName()})
+	}
+	return applied, nil
+}
+
+// WaitForCondition records the wait and returns immediately.
+func (f *fakeCluster) WaitForCondition(_ context.Context, gvr schema.GroupVersionResource, ns, name string,
+	condition k8s.WaitCondition, _ time.Duration) (*unstructured.Unstructured, error) {
+	f.calls = append(f.calls, fmt.Sprintf("wait %s %s/%s %s", gvr.Resource, ns, name, condition))
+	return nil, nil
+}
+
+// CheckCondition records the check and reports it unmet until checkFailures is used up.
+func (f *fakeCluster) CheckCondition(_ context.Context, gvr schema.GroupVersionResource, ns, name string,
+	condition k8s.WaitCondition) (bool, error) {
+	f.calls = append(f.calls, fmt.Sprintf("verify %s %s/%s %s", gvr.Resource, ns, name, condition))
+	if f.checkFailures > 0 {
+		f.checkFailures--
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetDeployment returns a deployment with the current replica count.
+func (f *fakeCluster) GetDeployment(_ context.Context, ns, name string) (k8s.DeploymentInfo, error) {
+	info := k8s.DeploymentInfo{Name: name, Namespace: ns}
+	info.Replicas.Desired = f.replicas
+	return info, nil
+}
+
+// PreflightDeployment records the preflight and returns the configured report.
+func (f *fakeCluster) PreflightDeployment(_ context.Context, ns, name, _ string,
+	target int32) (k8s.PreflightReport, error) {
+	f.calls = append(f.calls, fmt.Sprintf("preflight %s/%s %d", ns, name, target))
+	return f.preflight, nil
+}
+
+// ScaleDeployment records the new replica count.
+func (f *fakeCluster) ScaleDeployment(_ context.Context, ns, name string, replicas int32) error {
+	if f.scaleErr != nil {
+		return f.scaleErr
+	}
+	f.calls = append(f.calls, fmt.Sprintf("scale %s/%s %d", ns, name, replicas))
+	f.replicas = replicas
+	return nil
+}
+
+// RestartDeployment records the restart.
+func (f *fakeCluster) RestartDeployment(_ context.Context, ns, name string) error {
+	f.calls = append(f.calls, fmt.Sprintf("restart %s/%s", ns, name))
+	return nil
+}
+
+// verifyStep returns a step verifying that deployment/web is available.
+func verifyStep(name, when string) Step {
+	return Step{Name: name, When: when, RetryDelay: time.Millisecond,
+		Verify: &VerifyAction{Resource: "deployment/web", For: "condition=Available"}}
+}
+
+// statuses returns the status of each result.
+func statuses(results []StepResult) []string {
+	var out []string
+	for _, result := range results {
+		out = append(out, result.Status)
+	}
+	return out
+}
+
+// TestRunConditions verifies which steps run after a failure and that rollback follows it.
+func TestRunConditions(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		continueOnErr bool
+		wantSteps     string
+		wantRollback  string
+		wantSucceeded bool
+	}{
+		{"success", 0, false, "[succeeded succeeded skipped succeeded]", "[]", true},
+		{"failure", 1, false, "[failed skipped succeeded succeeded]", "[succeeded]", false},
+		{"ignored failure", 1, true, "[failed succeeded skipped succeeded]", "[]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := verifyStep("first", "")
+			first.ContinueOnError = tt.continueOnErr
+			p := &Pipeline{
+				Name: "test",
+				Steps: []Step{first, verifyStep("second", WhenOnSuccess), verifyStep("on failure", WhenOnFailure),
+					verifyStep("always", WhenAlways)},
+				Rollback: []Step{verifyStep("undo", "")},
+			}
+
+			report := NewRunner(&fakeCluster{checkFailures: tt.failures}, Options{}, zerolog.Nop()).
+				Run(context.Background(), p)
+			if got := fmt.Sprint(statuses(report.Steps)); got != tt.wantSteps {
+				t.Errorf("expected steps %s, got %s", tt.wantSteps, got)
+			}
+			if got := fmt.Sprint(statuses(report.Rollback)); got != tt.wantRollback {
+				t.Errorf("expected rollback %s, got %s", tt.wantRollback, got)
+			}
+			if report.Succeeded != tt.wantSucceeded {
+				t.Errorf("expected succeeded %v, got %v", tt.wantSucceeded, report.Succeeded)
+			}
+		})
+	}
+}
+
+// TestRunRetries verifies that failed attempts are retried up to the configured count.
+func TestRunRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		wantStatus   string
+		wantAttempts int
+	}{
+		{"recovers", 2, StatusSucceeded, 3},
+		{"retries exhausted", 1, StatusFailed, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := verifyStep("flaky", "")
+			step.Retries = tt.retries
+			report := NewRunner(&fakeCluster{checkFailures: 2}, Options{}, zerolog.Nop()).
+				Run(context.Background(), &Pipeline{Name: "test", Steps: []Step{step}})
+
+			result := report.Steps[0]
+			if result.Status != tt.wantStatus || result.Attempts != tt.wantAttempts {
+				t.Errorf("expected %s after %d attempts, got %+v", tt.wantStatus, tt.wantAttempts, result)
+			}
+		})
+	}
+}
+
+// TestRunCancelledDuringRetry verifies that a cancelled run stops retrying.
+func TestRunCancelledDuringRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	step := verifyStep("flaky", "")
+	step.Retries, step.RetryDelay = 5, time.Hour
+	report := NewRunner(&fakeCluster{checkFailures: 10}, Options{}, zerolog.Nop()).
+		Run(ctx, &Pipeline{Name: "test", Steps: []Step{step}})
+
+	if result := report.Steps[0]; result.Status != StatusFailed || result.Attempts != 1 {
+		t.Errorf("expected a single failed attempt, got %+v", result)
+	}
+}
+
+// TestRunNamespaces verifies the namespace precedence of step, runner override and pipeline.
+func TestRunNamespaces(t *testing.T) {
+	cluster := &fakeCluster{}
+	p := &Pipeline{Name: "test", Namespace: "pipeline", Steps: []Step{
+		{Name: "own", Wait: &WaitAction{Resource: "deploy/web", Namespace: "own", For: "delete"}},
+		{Name: "override", Wait: &WaitAction{Resource: "deploy/web", For: "delete"}},
+		{Name: "cluster-scoped", Wait: &WaitAction{Resource: "node/worker", Namespace: "own", For: "delete"}},
+		{Name: "restart", Restart: &RestartAction{Deployment: "web"}},
+	}}
+
+	NewRunner(cluster, Options{Namespace: "override"}, zerolog.Nop()).Run(context.Background(), p)
+	want := "[wait deployments own/web delete wait deployments override/web delete wait nodes /worker delete " +
+		"restart override/web]"
+	if got := fmt.Sprint(cluster.calls); got != want {
+		t.Errorf("expected calls %s, got %s", want, got)
+	}
+}
+
+// TestRunStepError verifies that action errors are reported in the step result.
+func TestRunStepError(t *testing.T) {
+	replicas := int32(2)
+	cluster := &fakeCluster{scaleErr: errors.New("denied")}
+	report := NewRunner(cluster, Options{}, zerolog.Nop()).Run(context.Background(), &Pipeline{
+		Name: "test", Steps: []Step{{Name: "scale", Scale: &ScaleAction{Deployment: "web", Replicas: &replicas}}},
+	})
+
+	if result := report.Steps[0]; result.Status != StatusFailed || result.Error != "denied" {
+		t.Errorf("expected the scale error, got %+v", result)
+	}
+}
+
+// TestRunDryRun verifies that dry runs run only the steps changing the cluster, which follow its dry run
+// mode, and skip the steps observing or announcing the changes.
+func TestRunDryRun(t *testing.T) {
+	replicas := int32(0)
+	p := &Pipeline{Name: "test", Namespace: "web", Steps: []Step{
+		{Name: "apply", Apply: &ApplyAction{Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: web}\n"}},
+		{Name: "rollout", Wait: &WaitAction{Resource: "deployment/web", For: "condition=Available"}},
+		verifyStep("verify", ""),
+		{Name: "restart", Restart: &RestartAction{Deployment: "web"}},
+		{Name: "scale", Scale: &ScaleAction{Deployment: "web", Replicas: &replicas, Force: true}},
+		{Name: "announce", When: WhenAlways, Notify: &NotifyAction{URL: "http://127.0.0.1:1", Message: "done"}},
+	}}
+
+	tests := []struct {
+		dryRun       k8s.DryRun
+		wantCalls    string
+		wantStatuses string
+	}{
+		{k8s.DryRunNone, "[apply web/web wait deployments web/web condition=Available=True " +
+			"verify deployments web/web condition=Available=True restart web/web scale web/web 0]",
+			"[succeeded succeeded succeeded succeeded succeeded failed]"},
+		{k8s.DryRunClient, "[apply web/web restart web/web scale web/web 0]",
+			"[succeeded skipped skipped succeeded succeeded skipped]"},
+		{k8s.DryRunServer, "[apply web/web restart web/web scale web/web 0]",
+			"[succeeded skipped skipped succeeded succeeded skipped]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dryRun), func(t *testing.T) {
+			cluster := &fakeCluster{}
+			report := NewRunner(cluster, Options{DryRun: tt.dryRun}, zerolog.Nop()).Run(context.Background(), p)
+			if got := fmt.Sprint(cluster.calls); got != tt.wantCalls {
+				t.Errorf("expected calls %s, got %s", tt.wantCalls, got)
+			}
+			if got := fmt.Sprint(statuses(report.Steps)); got != tt.wantStatuses {
+				t.Errorf("expected steps %s, got %s", tt.wantStatuses, got)
+			}
+		})
+	}
+}
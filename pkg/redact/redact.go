@@ -0,0 +1,125 @@
+// Package redact masks sensitive values, such as passwords and tokens in environment variables,
+// before they are printed, logged or stored.
+// This file implements the key patterns and redaction of env vars and unstructured objects.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Mask replaces redacted values.
+const Mask = "<redacted>"
+
+// DefaultPatterns are the key patterns redacted unless configured otherwise.
+var DefaultPatterns = []string{"PASSWORD", "TOKEN", "KEY"}
+
+// Redactor masks values whose keys match any of its patterns.
+// A nil Redactor redacts nothing, which is how redaction is disabled.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// New creates a Redactor from regular expressions matched case-insensitively anywhere in a key.
+func New(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Matches reports whether values of the given key are redacted.
+func (r *Redactor) Matches(key string) bool {
+	if r == nil {
+		return false
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns value, or Mask if the key matches. Empty values are kept, since they reveal nothing.
+func (r *Redactor) Value(key, value string) string {
+	if value == "" || !r.Matches(key) {
+		return value
+	}
+	return Mask
+}
+
+// EnvVars returns a copy of env with the values of matching variables masked.
+// Values taken from Secrets or ConfigMaps are references, not values, and are kept.
+func (r *Redactor) EnvVars(env []corev1.EnvVar) []corev1.EnvVar {
+	if r == nil || env == nil {
+		return env
+	}
+	redacted := make([]corev1.EnvVar, len(env))
+	for i, v := range env {
+		v.Value = r.Value(v.Name, v.Value)
+		redacted[i] = v
+	}
+	return redacted
+}
+
+// Object returns a deep copy of an unstructured object with the values of matching env vars masked,
+// wherever an "env" list appears, e.g. in the containers of pods and pod templates.
+func (r *Redactor) Object(obj map[string]any) map[string]any {
+	if r == nil || obj == nil {
+		return obj
+	}
+	return r.walk(obj).(map[string]any)
+}
+
+// walk deep-copies a JSON-like value, masking env var values on the way.
+func (r *Redactor) walk(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, item := range v {
+			if list, ok := item.([]any); ok && key == "env" {
+				copied[key] = r.envList(list)
+			} else {
+				copied[key] = r.walk(item)
+			}
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, item := range v {
+			copied[i] = r.walk(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// envList deep-copies an unstructured env list, masking the values of matching variables.
+func (r *Redactor) envList(list []any) []any {
+	copied := r.walk(list).([]any)
+	for _, item := range copied {
+		env, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := env["name"].(string)
+		if value, ok := env["value"].(string); ok {
+			env["value"] = r.Value(name, value)
+		}
+	}
+	return copied
+}
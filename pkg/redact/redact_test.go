@@ -0,0 +1,119 @@
+// Package redact contains tests for masking sensitive values.
+// This file tests key patterns and redaction of env vars and unstructured objects.
+package redact
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Redaction test constants.
+const (
+	testPassword = "hunter2"
+	testLogLevel = "debug"
+)
+
+// newDefaultRedactor creates a Redactor with the default patterns.
+func newDefaultRedactor(t *testing.T) *Redactor {
+	t.Helper()
+	r, err := New(DefaultPatterns)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return r
+}
+
+// TestMatches verifies case-insensitive matching of the default patterns.
+func TestMatches(t *testing.T) {
+	r := newDefaultRedactor(t)
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"DB_PASSWORD", true},
+		{"github_token", true},
+		{"AWS_SECRET_ACCESS_KEY", true},
+		{"LOG_LEVEL", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := r.Matches(tt.key); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	var disabled *Redactor
+	if disabled.Matches("DB_PASSWORD") {
+		t.Error("expected a nil Redactor to match nothing")
+	}
+}
+
+// TestNewInvalidPattern verifies that invalid regular expressions are rejected.
+func TestNewInvalidPattern(t *testing.T) {
+	if _, err := New([]string{"("}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+// TestEnvVars verifies that matching values are masked in a copy and references are kept.
+func TestEnvVars(t *testing.T) {
+	env := []corev1.EnvVar{
+		{Name: "DB_PASSWORD", Value: testPassword},
+		{Name: "LOG_LEVEL", Value: testLogLevel},
+		{Name: "API_TOKEN", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{Key: "token"},
+		}},
+	}
+
+	redacted := newDefaultRedactor(t).EnvVars(env)
+	if redacted[0].Value != Mask || redacted[1].Value != testLogLevel || redacted[2].ValueFrom == nil {
+		t.Errorf("unexpected redacted env %+v", redacted)
+	}
+	if env[0].Value != testPassword {
+		t.Error("expected the original env to be unchanged")
+	}
+
+	var disabled *Redactor
+	if disabled.EnvVars(env)[0].Value != testPassword {
+		t.Error("expected a nil Redactor to keep values")
+	}
+}
+
+// containerEnv returns the env list of the first container of a Deployment-shaped object.
+func containerEnv(obj map[string]any) []any {
+	podSpec := obj["spec"].(map[string]any)["template"].(map[string]any)["spec"].(map[string]any)
+	container := podSpec["containers"].([]any)[0].(map[string]any)
+	return container["env"].([]any)
+}
+
+// TestObject verifies that env values are masked wherever they appear in an unstructured object.
+func TestObject(t *testing.T) {
+	obj := map[string]any{
+		"kind": "Deployment",
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+			"containers": []any{map[string]any{
+				"name": "app",
+				"env": []any{
+					map[string]any{"name": "DB_PASSWORD", "value": testPassword},
+					map[string]any{"name": "LOG_LEVEL", "value": testLogLevel},
+				},
+			}},
+		}}},
+	}
+
+	redacted := newDefaultRedactor(t).Object(obj)
+	env := containerEnv(redacted)
+	if got := env[0].(map[string]any)["value"]; got != Mask {
+		t.Errorf("expected password to be masked, got %v", got)
+	}
+	if got := env[1].(map[string]any)["value"]; got != testLogLevel {
+		t.Errorf("expected log level to be kept, got %v", got)
+	}
+
+	original := containerEnv(obj)
+	if got := original[0].(map[string]any)["value"]; got != testPassword {
+		t.Errorf("expected the original object to be unchanged, got %v", got)
+	}
+}
@@ -0,0 +1,60 @@
+// Package redact masks sensitive values, such as passwords and tokens in environment variables,
+// before they are printed, logged or stored.
+// This file implements a writer that redacts structured log lines.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Writer redacts JSON log lines, such as those written by zerolog, before passing them on.
+// String fields whose names match are masked, and so are env lists within fields.
+// Lines that are not JSON objects are passed on unchanged.
+type Writer struct {
+	out      io.Writer
+	redactor *Redactor
+}
+
+// NewWriter creates a Writer that writes redacted lines to out.
+func NewWriter(out io.Writer, redactor *Redactor) *Writer {
+	return &Writer{out: out, redactor: redactor}
+}
+
+// Write redacts p, which must hold whole lines, and writes it to the underlying writer.
+// It reports len(p) on success, even if the redacted output differs in length.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.redactor == nil {
+		return w.out.Write(p)
+	}
+	if _, err := w.out.Write(w.redactLine(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redactLine returns the redacted form of a JSON log line, or the line itself if it is not a JSON object.
+func (w *Writer) redactLine(line []byte) []byte {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return line
+	}
+
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			fields[key] = w.redactor.Value(key, s)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(w.redactor.Object(fields)); err != nil {
+		return line
+	}
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		buf.Truncate(buf.Len() - 1)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,47 @@
+// Package redact contains tests for masking sensitive values.
+// This file tests redaction of structured log lines.
+package redact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// TestWriter verifies that sensitive log fields are masked and other lines pass through.
+func TestWriter(t *testing.T) {
+	var out bytes.Buffer
+	logger := zerolog.New(NewWriter(&out, newDefaultRedactor(t)))
+
+	logger.Info().Str("api_token", testPassword).Str("namespace", "shop").Msg("Connected")
+	logger.Info().Any("env", []map[string]string{{"name": "DB_PASSWORD", "value": testPassword}}).Msg("Env")
+
+	if strings.Contains(out.String(), testPassword) {
+		t.Errorf("expected secrets to be masked, got:\n%s", out.String())
+	}
+	for _, want := range []string{`"namespace":"shop"`, `"message":"Connected"`, Mask} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if _, err := NewWriter(&out, newDefaultRedactor(t)).Write([]byte("plain text\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if out.String() != "plain text\n" {
+		t.Errorf("expected non-JSON lines to pass through, got %q", out.String())
+	}
+}
+
+// TestWriterDisabled verifies that a nil Redactor writes lines unchanged.
+func TestWriterDisabled(t *testing.T) {
+	var out bytes.Buffer
+	logger := zerolog.New(NewWriter(&out, nil))
+	logger.Info().Str("api_token", testPassword).Msg("Connected")
+	if !strings.Contains(out.String(), testPassword) {
+		t.Errorf("expected value to be kept, got:\n%s", out.String())
+	}
+}
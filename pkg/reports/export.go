@@ -0,0 +1,95 @@
+// Package reports provides tabular cluster reports that can be streamed row by row.
+// This file implements the CSV and JSON-lines encoders used to export reports.
+package reports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Supported export formats.
+const (
+	FormatCSV       = "csv"
+	FormatJSONLines = "jsonl"
+)
+
+// Exporter encodes report rows into an output stream.
+type Exporter interface {
+	// WriteRow encodes a single row.
+	WriteRow(row Row) error
+}
+
+// ContentType returns the MIME type of the given export format.
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatJSONLines:
+		return "application/x-ndjson", nil
+	default:
+		return "", unsupportedFormatError(format)
+	}
+}
+
+// NewExporter creates an Exporter for the given format writing to w.
+// The CSV exporter writes the header row immediately.
+func NewExporter(format string, w io.Writer, columns []string) (Exporter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVExporter(w, columns)
+	case FormatJSONLines:
+		return &jsonLinesExporter{encoder: json.NewEncoder(w), columns: columns}, nil
+	default:
+		return nil, unsupportedFormatError(format)
+	}
+}
+
+// unsupportedFormatError reports an unknown export format.
+func unsupportedFormatError(format string) error {
+	return fmt.Errorf("unsupported export format '%s', must be one of: csv, jsonl", format)
+}
+
+// csvExporter writes rows as RFC 4180 CSV.
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+// newCSVExporter creates a csvExporter and writes the header row.
+func newCSVExporter(w io.Writer, columns []string) (*csvExporter, error) {
+	e := &csvExporter{writer: csv.NewWriter(w)}
+	if err := e.WriteRow(columns); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// WriteRow writes a CSV record and pushes it to the underlying writer.
+func (e *csvExporter) WriteRow(row Row) error {
+	if err := e.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonLinesExporter writes each row as a JSON object on its own line.
+type jsonLinesExporter struct {
+	encoder *json.Encoder
+	columns []string
+}
+
+// WriteRow writes a row as a JSON object keyed by column name.
+func (e *jsonLinesExporter) WriteRow(row Row) error {
+	object := make(map[string]string, len(e.columns))
+	for i, column := range e.columns {
+		if i < len(row) {
+			object[column] = row[i]
+		}
+	}
+	if err := e.encoder.Encode(object); err != nil {
+		return fmt.Errorf("failed to write JSON line: %w", err)
+	}
+	return nil
+}
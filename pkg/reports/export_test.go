@@ -0,0 +1,63 @@
+// Package reports contains tests for the cluster reports.
+// This file tests the CSV and JSON-lines exporters.
+package reports
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestExporters tests the encoded output of each export format.
+func TestExporters(t *testing.T) {
+	columns := []string{"namespace", "name"}
+	rows := []Row{{"shop", "web"}, {"default", "hello, world"}}
+
+	tests := []struct {
+		format          string
+		wantErr         bool
+		wantContentType string
+		expected        string
+	}{
+		{
+			format:          FormatCSV,
+			wantContentType: "text/csv; charset=utf-8",
+			expected:        "namespace,name\nshop,web\ndefault,\"hello, world\"\n",
+		},
+		{
+			format:          FormatJSONLines,
+			wantContentType: "application/x-ndjson",
+			expected: `{"name":"web","namespace":"shop"}` + "\n" +
+				`{"name":"hello, world","namespace":"default"}` + "\n",
+		},
+		{format: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			contentType, err := ContentType(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ContentType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if contentType != tt.wantContentType {
+				t.Errorf("ContentType() = %q, want %q", contentType, tt.wantContentType)
+			}
+
+			var buf bytes.Buffer
+			exporter, err := NewExporter(tt.format, &buf, columns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewExporter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for _, row := range rows {
+				if err := exporter.WriteRow(row); err != nil {
+					t.Fatalf("WriteRow() error = %v", err)
+				}
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("output = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+// Package reports provides tabular cluster reports that can be streamed row by row.
+// This file implements the garbage report, which finds leftover objects that are safe to clean up.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultFinishedPodAge is how long a succeeded or failed pod is kept before it is reported as garbage.
+const DefaultFinishedPodAge = 24 * time.Hour
+
+const (
+	// revisionAnnotation holds the rollout revision of a deployment's ReplicaSet.
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+
+	// defaultRevisionHistoryLimit is the API default of Deployment.spec.revisionHistoryLimit.
+	defaultRevisionHistoryLimit = 10
+
+	// rootCAConfigMap is published into every namespace by the control plane.
+	rootCAConfigMap = "kube-root-ca.crt"
+)
+
+// Garbage is an object found by the garbage report.
+type Garbage struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	Created   time.Time `json:"created"`
+}
+
+// GarbageOptions configures the garbage search.
+type GarbageOptions struct {
+	// Namespace limits the search to one namespace. Empty means all namespaces.
+	Namespace string
+
+	// FinishedPodAge is the minimum age of succeeded or failed pods to report.
+	FinishedPodAge time.Duration
+}
+
+// GarbageReport lists inactive ReplicaSets beyond their deployment's revision history,
+// finished pods older than FinishedPodAge, and ConfigMaps not referenced by any pod spec.
+type GarbageReport struct {
+	FinishedPodAge time.Duration
+}
+
+// Name returns the report identifier.
+func (GarbageReport) Name() string {
+	return "garbage"
+}
+
+// Columns returns the report columns.
+func (GarbageReport) Columns() []string {
+	return []string{"kind", "namespace", "name", "reason", "created"}
+}
+
+// Stream emits one row per garbage object. Unlike the inventory reports, candidates are
+// collected before the first row is emitted, since references must be resolved across objects.
+func (r GarbageReport) Stream(ctx context.Context, clientset kubernetes.Interface, emit EmitFunc) error {
+	items, err := FindGarbage(ctx, clientset, GarbageOptions{FinishedPodAge: r.FinishedPodAge}, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		row := Row{item.Kind, item.Namespace, item.Name, item.Reason, item.Created.UTC().Format(time.RFC3339)}
+		if err := emit(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clusterSnapshot holds the objects the garbage search needs.
+type clusterSnapshot struct {
+	deployments []appsv1.Deployment
+	replicaSets []appsv1.ReplicaSet
+	pods        []corev1.Pod
+	configMaps  []corev1.ConfigMap
+}
+
+// FindGarbage searches the cluster for garbage objects, sorted by kind, namespace and name.
+func FindGarbage(ctx context.Context, clientset kubernetes.Interface, opts GarbageOptions,
+	now time.Time) ([]Garbage, error) {
+	snapshot, err := takeSnapshot(ctx, clientset, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Garbage
+	items = append(items, garbageReplicaSets(snapshot.replicaSets, snapshot.deployments)...)
+	items = append(items, garbagePods(snapshot.pods, now.Add(-opts.FinishedPodAge))...)
+	items = append(items, garbageConfigMaps(snapshot.configMaps, snapshot.pods, snapshot.deployments)...)
+
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return items, nil
+}
+
+// takeSnapshot lists deployments, ReplicaSets, pods and ConfigMaps page by page.
+func takeSnapshot(ctx context.Context, clientset kubernetes.Interface, ns string) (*clusterSnapshot, error) {
+	s := &clusterSnapshot{}
+	listers := []struct {
+		resource string
+		list     func(metav1.ListOptions) (string, error)
+	}{
+		{"deployments", func(opts metav1.ListOptions) (string, error) {
+			list, err := clientset.AppsV1().Deployments(ns).List(ctx, opts)
+			if err != nil {
+				return "", err
+			}
+			s.deployments = append(s.deployments, list.Items...)
+			return list.Continue, nil
+		}},
+		{"replicasets", func(opts metav1.ListOptions) (string, error) {
+			list, err := clientset.AppsV1().ReplicaSets(ns).List(ctx, opts)
+			if err != nil {
+				return "", err
+			}
+			s.replicaSets = append(s.replicaSets, list.Items...)
+			return list.Continue, nil
+		}},
+		{"pods", func(opts metav1.ListOptions) (string, error) {
+			list, err := clientset.CoreV1().Pods(ns).List(ctx, opts)
+			if err != nil {
+				return "", err
+			}
+			s.pods = append(s.pods, list.Items...)
+			return list.Continue, nil
+		}},
+		{"configmaps", func(opts metav1.ListOptions) (string, error) {
+			list, err := clientset.CoreV1().ConfigMaps(ns).List(ctx, opts)
+			if err != nil {
+				return "", err
+			}
+			s.configMaps = append(s.configMaps, list.Items...)
+			return list.Continue, nil
+		}},
+	}
+
+	for _, lister := range listers {
+		if err := forEachPage(lister.resource, lister.list); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// forEachPage calls list with successive continue tokens until the last page is reached.
+func forEachPage(resource string, list func(metav1.ListOptions) (string, error)) error {
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		next, err := list(opts)
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", resource, err)
+		}
+		if opts.Continue = next; opts.Continue == "" {
+			return nil
+		}
+	}
+}
+
+// garbageReplicaSets reports ReplicaSets whose owning deployment is gone, unowned ReplicaSets
+// scaled to zero, and inactive ReplicaSets beyond their deployment's revisionHistoryLimit.
+func garbageReplicaSets(replicaSets []appsv1.ReplicaSet, deployments []appsv1.Deployment) []Garbage {
+	byKey := make(map[string]*appsv1.Deployment, len(deployments))
+	for i := range deployments {
+		byKey[deployments[i].Namespace+"/"+deployments[i].Name] = &deployments[i]
+	}
+
+	var items []Garbage
+	inactive := make(map[*appsv1.Deployment][]*appsv1.ReplicaSet)
+	for i := range replicaSets {
+		rs := &replicaSets[i]
+		owner := metav1.GetControllerOf(rs)
+		switch {
+		case owner == nil:
+			if !isInactive(rs) {
+				continue
+			}
+			items = append(items, newGarbage("ReplicaSet", &rs.ObjectMeta, "unowned and scaled to zero"))
+		case owner.Kind != "Deployment":
+			continue
+		case byKey[rs.Namespace+"/"+owner.Name] == nil:
+			items = append(items, newGarbage("ReplicaSet", &rs.ObjectMeta,
+				fmt.Sprintf("owner deployment %s no longer exists", owner.Name)))
+		case isInactive(rs):
+			deployment := byKey[rs.Namespace+"/"+owner.Name]
+			inactive[deployment] = append(inactive[deployment], rs)
+		}
+	}
+
+	for deployment, old := range inactive {
+		items = append(items, beyondHistoryLimit(deployment, old)...)
+	}
+	return items
+}
+
+// beyondHistoryLimit reports the oldest inactive ReplicaSets exceeding the deployment's revision history.
+func beyondHistoryLimit(deployment *appsv1.Deployment, inactive []*appsv1.ReplicaSet) []Garbage {
+	limit := int32(defaultRevisionHistoryLimit)
+	if deployment.Spec.RevisionHistoryLimit != nil {
+		limit = *deployment.Spec.RevisionHistoryLimit
+	}
+	if int32(len(inactive)) <= limit {
+		return nil
+	}
+
+	sort.Slice(inactive, func(i, j int) bool {
+		return revision(inactive[i]) > revision(inactive[j])
+	})
+	reason := fmt.Sprintf("beyond revisionHistoryLimit %d of deployment %s", limit, deployment.Name)
+	items := make([]Garbage, 0, int32(len(inactive))-limit)
+	for _, rs := range inactive[limit:] {
+		items = append(items, newGarbage("ReplicaSet", &rs.ObjectMeta, reason))
+	}
+	return items
+}
+
+// isInactive reports whether a ReplicaSet is scaled to zero and has no pods left.
+func isInactive(rs *appsv1.ReplicaSet) bool {
+	return rs.Spec.Replicas != nil && *rs.Spec.Replicas == 0 && rs.Status.Replicas == 0
+}
+
+// revision returns the rollout revision of a ReplicaSet, or 0 if it is missing or invalid.
+func revision(rs *appsv1.ReplicaSet) int64 {
+	value, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// garbagePods reports succeeded and failed pods that finished before the cutoff.
+func garbagePods(pods []corev1.Pod, cutoff time.Time) []Garbage {
+	var items []Garbage
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+		if finishedAt(pod).After(cutoff) {
+			continue
+		}
+		items = append(items, newGarbage("Pod", &pod.ObjectMeta,
+			fmt.Sprintf("%s since %s", strings.ToLower(string(pod.Status.Phase)),
+				finishedAt(pod).UTC().Format(time.RFC3339))))
+	}
+	return items
+}
+
+// finishedAt returns when the last container of a pod terminated, falling back to its creation time.
+func finishedAt(pod *corev1.Pod) time.Time {
+	finished := pod.CreationTimestamp.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(finished) {
+			finished = terminated.FinishedAt.Time
+		}
+	}
+	return finished
+}
+
+// garbageConfigMaps reports ConfigMaps not referenced by any pod or deployment pod template.
+// The control plane's root CA bundle and ConfigMaps in kube-* namespaces are never reported,
+// since they are consumed by system components rather than pod specs.
+func garbageConfigMaps(configMaps []corev1.ConfigMap, pods []corev1.Pod,
+	deployments []appsv1.Deployment) []Garbage {
+	referenced := make(map[string]bool)
+	for i := range pods {
+		addConfigMapRefs(pods[i].Namespace, &pods[i].Spec, referenced)
+	}
+	for i := range deployments {
+		addConfigMapRefs(deployments[i].Namespace, &deployments[i].Spec.Template.Spec, referenced)
+	}
+
+	var items []Garbage
+	for i := range configMaps {
+		cm := &configMaps[i]
+		if cm.Name == rootCAConfigMap || strings.HasPrefix(cm.Namespace, "kube-") {
+			continue
+		}
+		if !referenced[cm.Namespace+"/"+cm.Name] {
+			items = append(items, newGarbage("ConfigMap", &cm.ObjectMeta, "not referenced by any pod spec"))
+		}
+	}
+	return items
+}
+
+// addConfigMapRefs records the ConfigMaps a pod spec uses via volumes, env and envFrom.
+func addConfigMapRefs(ns string, spec *corev1.PodSpec, referenced map[string]bool) {
+	add := func(name string) {
+		referenced[ns+"/"+name] = true
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			add(volume.ConfigMap.Name)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					add(source.ConfigMap.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, from := range container.EnvFrom {
+			if from.ConfigMapRef != nil {
+				add(from.ConfigMapRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+				add(env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+		}
+	}
+}
+
+// newGarbage builds a Garbage entry for an object.
+func newGarbage(kind string, meta *metav1.ObjectMeta, reason string) Garbage {
+	return Garbage{
+		Kind:      kind,
+		Namespace: meta.Namespace,
+		Name:      meta.Name,
+		Reason:    reason,
+		Created:   meta.CreationTimestamp.Time,
+	}
+}
@@ -0,0 +1,131 @@
+// Package reports contains tests for the cluster reports.
+// This file tests detection of garbage ReplicaSets, pods and ConfigMaps.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testGarbageNamespace is the namespace of all garbage test objects.
+const testGarbageNamespace = "shop"
+
+// newGarbageDeployment builds a deployment with the given revision history limit using a ConfigMap.
+func newGarbageDeployment(name string, historyLimit int32, configMap string) *appsv1.Deployment {
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testGarbageNamespace},
+		Spec:       appsv1.DeploymentSpec{RevisionHistoryLimit: &historyLimit},
+	}
+	d.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "config", VolumeSource: corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}},
+	}}}
+	return d
+}
+
+// newGarbageReplicaSet builds a ReplicaSet of the given revision, owned by a deployment if owner is set.
+func newGarbageReplicaSet(owner string, rev int, replicas int32) *appsv1.ReplicaSet {
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-rev%d", owner, rev),
+			Namespace:   testGarbageNamespace,
+			Annotations: map[string]string{revisionAnnotation: fmt.Sprint(rev)},
+		},
+		Spec:   appsv1.ReplicaSetSpec{Replicas: &replicas},
+		Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+	}
+	if owner != "" {
+		controller := true
+		rs.OwnerReferences = []metav1.OwnerReference{{Kind: "Deployment", Name: owner, Controller: &controller}}
+	} else {
+		rs.Name = fmt.Sprintf("standalone-rev%d", rev)
+	}
+	return rs
+}
+
+// newFinishedPod builds a pod in the given phase whose container terminated at finished.
+func newFinishedPod(name string, phase corev1.PodPhase, finished time.Time, envConfigMap string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testGarbageNamespace},
+		Status: corev1.PodStatus{Phase: phase, ContainerStatuses: []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				FinishedAt: metav1.NewTime(finished),
+			}},
+		}}},
+	}
+	pod.Spec.Containers = []corev1.Container{{Name: "app", EnvFrom: []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: envConfigMap}},
+	}}}}
+	return pod
+}
+
+// newGarbageConfigMap builds a ConfigMap in the given namespace.
+func newGarbageConfigMap(ns, name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+}
+
+// TestFindGarbage verifies detection of every garbage kind and that live objects are kept.
+func TestFindGarbage(t *testing.T) {
+	now := time.Now()
+	objects := []runtime.Object{
+		newGarbageDeployment("web", 1, "web-config"),
+		newGarbageReplicaSet("web", 3, 2),
+		newGarbageReplicaSet("web", 2, 0),
+		newGarbageReplicaSet("web", 1, 0),
+		newGarbageReplicaSet("deleted", 1, 0),
+		newGarbageReplicaSet("", 1, 0),
+		newGarbageReplicaSet("", 2, 1),
+		newFinishedPod("job-old", corev1.PodSucceeded, now.Add(-48*time.Hour), "job-config"),
+		newFinishedPod("job-new", corev1.PodFailed, now.Add(-time.Hour), "job-config"),
+		newFinishedPod("running", corev1.PodRunning, now.Add(-48*time.Hour), "job-config"),
+		newGarbageConfigMap(testGarbageNamespace, "web-config"),
+		newGarbageConfigMap(testGarbageNamespace, "job-config"),
+		newGarbageConfigMap(testGarbageNamespace, "unused"),
+		newGarbageConfigMap(testGarbageNamespace, rootCAConfigMap),
+		newGarbageConfigMap("kube-system", "kubeadm-config"),
+	}
+
+	items, err := FindGarbage(context.Background(), fake.NewClientset(objects...),
+		GarbageOptions{FinishedPodAge: DefaultFinishedPodAge}, now)
+	if err != nil {
+		t.Fatalf("FindGarbage() error = %v", err)
+	}
+
+	want := []string{
+		"ConfigMap/unused",
+		"Pod/job-old",
+		"ReplicaSet/deleted-rev1",
+		"ReplicaSet/standalone-rev1",
+		"ReplicaSet/web-rev1",
+	}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d garbage objects, got %+v", len(want), items)
+	}
+	for i, item := range items {
+		if got := item.Kind + "/" + item.Name; got != want[i] {
+			t.Errorf("item %d: expected %s, got %s (%s)", i, want[i], got, item.Reason)
+		}
+	}
+}
+
+// TestGarbageReportStream verifies the rows emitted by the garbage report.
+func TestGarbageReportStream(t *testing.T) {
+	rows := collect(t, GarbageReport{FinishedPodAge: DefaultFinishedPodAge},
+		newGarbageConfigMap(testGarbageNamespace, "unused"))
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %v", rows)
+	}
+	if rows[0][0] != "ConfigMap" || rows[0][3] != "not referenced by any pod spec" {
+		t.Errorf("unexpected row %v", rows[0])
+	}
+	if len(rows[0]) != len(GarbageReport{}.Columns()) {
+		t.Errorf("row has %d values, want %d", len(rows[0]), len(GarbageReport{}.Columns()))
+	}
+}
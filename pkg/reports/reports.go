@@ -0,0 +1,71 @@
+// Package reports provides tabular cluster reports that can be streamed row by row.
+// Reports page through the Kubernetes API lazily, so even clusters with tens of
+// thousands of workloads can be exported without buffering the whole result in memory.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// pageSize is the number of objects requested per API list call.
+const pageSize = 500
+
+// Row is a single report row; values are ordered like the report's columns.
+type Row []string
+
+// EmitFunc receives report rows. Returning an error stops the report.
+type EmitFunc func(Row) error
+
+// Report produces rows of a tabular cluster report.
+type Report interface {
+	// Name is the identifier of the report used in URLs and on the command line.
+	Name() string
+
+	// Columns returns the column names of the report rows.
+	Columns() []string
+
+	// Stream fetches the report data page by page and emits one row per object.
+	Stream(ctx context.Context, clientset kubernetes.Interface, emit EmitFunc) error
+}
+
+// Registry holds reports by name.
+type Registry struct {
+	reports map[string]Report
+}
+
+// NewRegistry creates a Registry containing the given reports.
+func NewRegistry(reports ...Report) *Registry {
+	r := &Registry{reports: make(map[string]Report, len(reports))}
+	for _, report := range reports {
+		r.reports[report.Name()] = report
+	}
+	return r
+}
+
+// Builtin returns a Registry with all reports shipped with the application.
+func Builtin() *Registry {
+	return NewRegistry(DeploymentsReport{}, PodsReport{}, GarbageReport{FinishedPodAge: DefaultFinishedPodAge})
+}
+
+// Get returns the report with the given name.
+func (r *Registry) Get(name string) (Report, error) {
+	report, ok := r.reports[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown report %q", name)
+	}
+	return report, nil
+}
+
+// Names returns the sorted names of all registered reports.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.reports))
+	for name := range r.reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
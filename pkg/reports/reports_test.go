@@ -0,0 +1,139 @@
+// Package reports contains tests for the cluster reports.
+// This file tests the report registry and the workload reports.
+package reports
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testImage is the container image used by test objects.
+const testImage = "nginx:1.27"
+
+// newTestObjects returns two deployments and one pod in different namespaces.
+func newTestObjects() []runtime.Object {
+	replicas := int32(3)
+	deployment := func(ns, name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: testImage}},
+				}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 2, AvailableReplicas: 2, UpdatedReplicas: 3},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+		Spec:       corev1.PodSpec{NodeName: "node-a"},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{RestartCount: 2}, {RestartCount: 1}},
+		},
+	}
+	return []runtime.Object{deployment("shop", "web"), deployment("default", "hello"), pod}
+}
+
+// collect streams a report into a slice of rows.
+func collect(t *testing.T, report Report, objects ...runtime.Object) []Row {
+	t.Helper()
+	var rows []Row
+	err := report.Stream(context.Background(), fake.NewClientset(objects...), func(row Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	return rows
+}
+
+// TestRegistry tests report lookup by name.
+func TestRegistry(t *testing.T) {
+	registry := Builtin()
+
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"deployments", false},
+		{"pods", false},
+		{"unknown", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := registry.Get(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && report.Name() != tt.name {
+				t.Errorf("Get() returned report %q", report.Name())
+			}
+		})
+	}
+
+	if names := registry.Names(); len(names) != 3 || names[0] != "deployments" {
+		t.Errorf("Names() = %v", names)
+	}
+}
+
+// TestDeploymentsReport tests the rows produced by the deployments report.
+func TestDeploymentsReport(t *testing.T) {
+	report := DeploymentsReport{}
+	rows := collect(t, report, newTestObjects()...)
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	for _, row := range rows {
+		if len(row) != len(report.Columns()) {
+			t.Fatalf("row %v does not match columns %v", row, report.Columns())
+		}
+		if row[2] != "3" || row[3] != "2" || row[6] != testImage {
+			t.Errorf("unexpected row %v", row)
+		}
+	}
+}
+
+// TestPodsReport tests the rows produced by the pods report.
+func TestPodsReport(t *testing.T) {
+	rows := collect(t, PodsReport{}, newTestObjects()...)
+
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	want := Row{"shop", "web-1", "Running", "node-a", "3"}
+	for i, value := range want {
+		if rows[0][i] != value {
+			t.Errorf("column %d = %q, want %q", i, rows[0][i], value)
+		}
+	}
+}
+
+// TestStreamStopsOnEmitError tests that an emit error aborts the report.
+func TestStreamStopsOnEmitError(t *testing.T) {
+	errStop := errors.New("client gone")
+	calls := 0
+
+	err := DeploymentsReport{}.Stream(context.Background(), fake.NewClientset(newTestObjects()...), func(Row) error {
+		calls++
+		return errStop
+	})
+
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected emit error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected streaming to stop after 1 row, got %d", calls)
+	}
+}
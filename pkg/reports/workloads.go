@@ -0,0 +1,126 @@
+// Package reports provides tabular cluster reports that can be streamed row by row.
+// This file implements the deployments and pods inventory reports.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DeploymentsReport lists every deployment in the cluster with its replica status and images.
+type DeploymentsReport struct{}
+
+// Name returns the report identifier.
+func (DeploymentsReport) Name() string {
+	return "deployments"
+}
+
+// Columns returns the report columns.
+func (DeploymentsReport) Columns() []string {
+	return []string{"namespace", "name", "desired", "ready", "available", "updated", "images", "created"}
+}
+
+// Stream emits one row per deployment, fetching deployments page by page.
+func (DeploymentsReport) Stream(ctx context.Context, clientset kubernetes.Interface, emit EmitFunc) error {
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		list, err := clientset.AppsV1().Deployments("").List(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for i := range list.Items {
+			if err := emit(deploymentRow(&list.Items[i])); err != nil {
+				return err
+			}
+		}
+		if opts.Continue = list.Continue; opts.Continue == "" {
+			return nil
+		}
+	}
+}
+
+// deploymentRow converts a deployment into a report row.
+func deploymentRow(d *appsv1.Deployment) Row {
+	desired := int32(0)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+
+	images := make([]string, 0, len(d.Spec.Template.Spec.Containers))
+	for _, c := range d.Spec.Template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+
+	return Row{
+		d.Namespace,
+		d.Name,
+		formatInt(desired),
+		formatInt(d.Status.ReadyReplicas),
+		formatInt(d.Status.AvailableReplicas),
+		formatInt(d.Status.UpdatedReplicas),
+		strings.Join(images, " "),
+		d.CreationTimestamp.UTC().Format(time.RFC3339),
+	}
+}
+
+// PodsReport lists every pod in the cluster with its phase, node and restart count.
+type PodsReport struct{}
+
+// Name returns the report identifier.
+func (PodsReport) Name() string {
+	return "pods"
+}
+
+// Columns returns the report columns.
+func (PodsReport) Columns() []string {
+	return []string{"namespace", "name", "phase", "node", "restarts", "created"}
+}
+
+// Stream emits one row per pod, fetching pods page by page.
+func (PodsReport) Stream(ctx context.Context, clientset kubernetes.Interface, emit EmitFunc) error {
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		list, err := clientset.CoreV1().Pods("").List(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list pods: %w", err)
+		}
+		for i := range list.Items {
+			if err := emit(podRow(&list.Items[i])); err != nil {
+				return err
+			}
+		}
+		if opts.Continue = list.Continue; opts.Continue == "" {
+			return nil
+		}
+	}
+}
+
+// podRow converts a pod into a report row.
+func podRow(p *corev1.Pod) Row {
+	restarts := int32(0)
+	for _, status := range p.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+
+	return Row{
+		p.Namespace,
+		p.Name,
+		string(p.Status.Phase),
+		p.Spec.NodeName,
+		formatInt(restarts),
+		p.CreationTimestamp.UTC().Format(time.RFC3339),
+	}
+}
+
+// formatInt formats a 32-bit integer as a decimal string.
+func formatInt(v int32) string {
+	return strconv.FormatInt(int64(v), 10)
+}
@@ -0,0 +1,162 @@
+// Package selftest implements an end-to-end acceptance test of the k8s-controller tool
+// against a live cluster. It creates a temporary namespace, deploys a tiny workload,
+// exercises the list, watch, scale and delete code paths, and always cleans up afterwards.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Default settings for a self-test run.
+const (
+	DefaultNamespace   = "kc-selftest"
+	DefaultImage       = "registry.k8s.io/pause:3.10"
+	DefaultStepTimeout = 30 * time.Second
+)
+
+// Cluster is the subset of the k8s client exercised by the self-test.
+// It is satisfied by *k8s.Client, whose mutating methods consult its authorization hook; the clientset
+// is only used for reads.
+type Cluster interface {
+	GetClientset() kubernetes.Interface
+	CreateNamespace(ctx context.Context, name string, labels map[string]string) error
+	DeleteNamespace(ctx context.Context, name string) (k8s.NamespaceTermination, error)
+	CreateDeployment(ctx context.Context, deployment *appsv1.Deployment) (k8s.DeploymentInfo, error)
+	DeleteDeployment(ctx context.Context, ns, name string) error
+	ListDeployments(ctx context.Context, opts k8s.ListDeploymentsOptions) ([]k8s.DeploymentInfo, error)
+	Patch(ctx context.Context, gvr schema.GroupVersionResource, ns, name string,
+		patchType types.PatchType, data []byte) (*unstructured.Unstructured, error)
+}
+
+// Options configures a self-test run.
+type Options struct {
+	// Namespace is the temporary namespace created for the test. It must not already exist.
+	Namespace string
+
+	// Image is the container image of the test workload.
+	Image string
+
+	// StepTimeout bounds each individual step.
+	StepTimeout time.Duration
+}
+
+// StepResult records the outcome of a single self-test step.
+type StepResult struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Report is the pass/fail report of a self-test run.
+type Report struct {
+	Namespace string       `json:"namespace"`
+	Steps     []StepResult `json:"steps"`
+}
+
+// Passed reports whether every step of the run succeeded.
+func (r Report) Passed() bool {
+	for _, step := range r.Steps {
+		if !step.Passed {
+			return false
+		}
+	}
+	return len(r.Steps) > 0
+}
+
+// step is a named unit of the self-test.
+type step struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Runner executes the self-test against a cluster.
+type Runner struct {
+	cluster Cluster
+	opts    Options
+	logger  zerolog.Logger
+}
+
+// NewRunner creates a Runner, filling unset options with defaults.
+func NewRunner(cluster Cluster, opts Options, logger zerolog.Logger) *Runner {
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultNamespace
+	}
+	if opts.Image == "" {
+		opts.Image = DefaultImage
+	}
+	if opts.StepTimeout <= 0 {
+		opts.StepTimeout = DefaultStepTimeout
+	}
+
+	return &Runner{
+		cluster: cluster,
+		opts:    opts,
+		logger:  logger.With().Str("component", "selftest").Logger(),
+	}
+}
+
+// Run executes all steps in order, stopping at the first failure, and always runs cleanup.
+// The returned report contains one entry per executed step, including cleanup.
+func (r *Runner) Run(ctx context.Context) Report {
+	report := Report{Namespace: r.opts.Namespace}
+
+	for _, s := range r.steps() {
+		result := r.runStep(ctx, s)
+		report.Steps = append(report.Steps, result)
+		if !result.Passed {
+			break
+		}
+	}
+
+	report.Steps = append(report.Steps, r.runStep(ctx, step{name: "cleanup", run: r.cleanup}))
+	return report
+}
+
+// steps returns the ordered list of self-test steps, excluding cleanup.
+func (r *Runner) steps() []step {
+	return []step{
+		{name: "create namespace", run: r.createNamespace},
+		{name: "create deployment", run: r.createDeployment},
+		{name: "list deployments", run: r.verifyList},
+		{name: "watch deployments", run: r.verifyWatch},
+		{name: "scale deployment", run: r.verifyScale},
+		{name: "delete deployment", run: r.verifyDelete},
+	}
+}
+
+// runStep executes a single step with its own timeout and records the result.
+func (r *Runner) runStep(ctx context.Context, s step) StepResult {
+	stepCtx, cancel := context.WithTimeout(ctx, r.opts.StepTimeout)
+	defer cancel()
+
+	r.logger.Info().Str("step", s.name).Msg("Running self-test step")
+	start := time.Now()
+	err := s.run(stepCtx)
+
+	result := StepResult{Name: s.name, Passed: err == nil, Duration: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+		r.logger.Error().Err(err).Str("step", s.name).Msg("Self-test step failed")
+	}
+	return result
+}
+
+// wrapStep adds step context to an error.
+func wrapStep(action string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
@@ -0,0 +1,177 @@
+// Package selftest contains tests for the end-to-end self-test runner.
+// This file runs the self-test against a fake clientset.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// denyAuthorizer rejects every change.
+type denyAuthorizer struct{}
+
+// Authorize denies the change.
+func (denyAuthorizer) Authorize(context.Context, authz.Change) (authz.Decision, error) {
+	return authz.Decision{Reason: "change freeze"}, nil
+}
+
+// fakeCluster implements Cluster on top of a fake clientset.
+type fakeCluster struct {
+	clientset *fake.Clientset
+	listErr   error
+}
+
+// GetClientset returns the fake clientset.
+func (f *fakeCluster) GetClientset() kubernetes.Interface {
+	return f.clientset
+}
+
+// CreateNamespace creates a namespace in the fake clientset.
+func (f *fakeCluster) CreateNamespace(ctx context.Context, name string, labels map[string]string) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	_, err := f.clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	return err
+}
+
+// DeleteNamespace deletes a namespace of the fake clientset, which removes it right away.
+func (f *fakeCluster) DeleteNamespace(ctx context.Context, name string) (k8s.NamespaceTermination, error) {
+	err := f.clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	return k8s.NamespaceTermination{Name: name, Deleted: err == nil}, err
+}
+
+// CreateDeployment creates a deployment in the fake clientset.
+func (f *fakeCluster) CreateDeployment(ctx context.Context, deployment *appsv1.Deployment) (k8s.DeploymentInfo,
+	error) {
+	_, err := f.clientset.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	return k8s.DeploymentInfo{Name: deployment.Name, Namespace: deployment.Namespace}, err
+}
+
+// DeleteDeployment deletes a deployment of the fake clientset.
+func (f *fakeCluster) DeleteDeployment(ctx context.Context, ns, name string) error {
+	return f.clientset.AppsV1().Deployments(ns).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// ListDeployments lists deployment names from the fake clientset.
+func (f *fakeCluster) ListDeployments(ctx context.Context,
+	opts k8s.ListDeploymentsOptions) ([]k8s.DeploymentInfo, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	list, err := f.clientset.AppsV1().Deployments(opts.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]k8s.DeploymentInfo, 0, len(list.Items))
+	for _, d := range list.Items {
+		result = append(result, k8s.DeploymentInfo{Name: d.Name, Namespace: d.Namespace})
+	}
+	return result, nil
+}
+
+// Patch applies the patch to a deployment of the fake clientset.
+func (f *fakeCluster) Patch(ctx context.Context, _ schema.GroupVersionResource, ns, name string,
+	patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	patched, err := f.clientset.AppsV1().Deployments(ns).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(patched)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+// TestRunnerRun verifies that a full run passes and cleans up after itself.
+func TestRunnerRun(t *testing.T) {
+	cluster := &fakeCluster{clientset: fake.NewSimpleClientset()}
+	runner := NewRunner(cluster, Options{StepTimeout: 5 * time.Second}, zerolog.Nop())
+
+	report := runner.Run(context.Background())
+	if !report.Passed() {
+		t.Fatalf("expected self-test to pass, got %+v", report.Steps)
+	}
+	if len(report.Steps) != len(runner.steps())+1 {
+		t.Errorf("expected %d steps including cleanup, got %d", len(runner.steps())+1, len(report.Steps))
+	}
+
+	_, err := cluster.clientset.CoreV1().Namespaces().Get(context.Background(), DefaultNamespace, metav1.GetOptions{})
+	if err == nil {
+		t.Error("expected self-test namespace to be deleted")
+	}
+}
+
+// TestRunnerStopsOnFailure verifies that a failing step aborts the run but cleanup still happens.
+func TestRunnerStopsOnFailure(t *testing.T) {
+	cluster := &fakeCluster{clientset: fake.NewSimpleClientset(), listErr: fmt.Errorf("simulated list error")}
+	runner := NewRunner(cluster, Options{StepTimeout: time.Second}, zerolog.Nop())
+
+	report := runner.Run(context.Background())
+	if report.Passed() {
+		t.Fatal("expected self-test to fail")
+	}
+
+	last := report.Steps[len(report.Steps)-1]
+	if last.Name != "cleanup" || !last.Passed {
+		t.Errorf("expected successful cleanup as last step, got %+v", last)
+	}
+	failed := report.Steps[len(report.Steps)-2]
+	if failed.Name != "list deployments" || failed.Passed {
+		t.Errorf("expected list step to fail, got %+v", failed)
+	}
+}
+
+// TestRunnerRefusesExistingNamespace verifies that pre-existing namespaces are neither used nor deleted.
+func TestRunnerRefusesExistingNamespace(t *testing.T) {
+	existing := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: DefaultNamespace}}
+	cluster := &fakeCluster{clientset: fake.NewSimpleClientset(existing)}
+	runner := NewRunner(cluster, Options{StepTimeout: time.Second}, zerolog.Nop())
+
+	report := runner.Run(context.Background())
+	if report.Passed() {
+		t.Fatal("expected self-test to fail on existing namespace")
+	}
+
+	_, err := cluster.clientset.CoreV1().Namespaces().Get(context.Background(), DefaultNamespace, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("expected pre-existing namespace to be left in place, got %v", err)
+	}
+}
+
+// TestRunnerAuthorizesChanges verifies that the self-test changes the cluster through the client's
+// authorizing methods, so a denying authorization hook stops it before anything is created.
+func TestRunnerAuthorizesChanges(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop())
+	client.SetAuthorizer(denyAuthorizer{})
+	runner := NewRunner(client, Options{StepTimeout: time.Second}, zerolog.Nop())
+
+	report := runner.Run(context.Background())
+	if report.Passed() || len(report.Steps) != 2 {
+		t.Fatalf("expected the first step to fail followed by cleanup, got %+v", report.Steps)
+	}
+	if !strings.Contains(report.Steps[0].Error, authz.ErrDenied.Error()) {
+		t.Errorf("expected the namespace creation to be denied, got %q", report.Steps[0].Error)
+	}
+	_, err := client.GetClientset().CoreV1().Namespaces().Get(context.Background(), DefaultNamespace,
+		metav1.GetOptions{})
+	if err == nil {
+		t.Error("expected no self-test namespace to be created")
+	}
+}
@@ -0,0 +1,178 @@
+// Package selftest implements an end-to-end acceptance test of the k8s-controller tool.
+// This file contains the individual self-test steps.
+package selftest
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Names and labels of the objects created by the self-test.
+const (
+	workloadName   = "kc-selftest-workload"
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "k8s-controller-selftest"
+	watchLabel     = "kc-selftest/watched"
+	scaledReplicas = int32(2)
+)
+
+// createNamespace creates the temporary namespace, refusing to reuse an existing one.
+func (r *Runner) createNamespace(ctx context.Context) error {
+	err := r.cluster.CreateNamespace(ctx, r.opts.Namespace, map[string]string{managedByLabel: managedByValue})
+	if apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("namespace %q already exists, refusing to use it", r.opts.Namespace)
+	}
+	return wrapStep("create namespace", err)
+}
+
+// createDeployment deploys the tiny test workload.
+func (r *Runner) createDeployment(ctx context.Context) error {
+	_, err := r.cluster.CreateDeployment(ctx, r.testDeployment())
+	return wrapStep("create deployment", err)
+}
+
+// testDeployment builds the single-replica test workload.
+func (r *Runner) testDeployment() *appsv1.Deployment {
+	replicas := int32(1)
+	labels := map[string]string{"app": workloadName, managedByLabel: managedByValue}
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: workloadName, Namespace: r.opts.Namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": workloadName}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "pause", Image: r.opts.Image}},
+				},
+			},
+		},
+	}
+}
+
+// verifyList checks that the tool's deployment listing finds the test workload.
+func (r *Runner) verifyList(ctx context.Context) error {
+	deployments, err := r.cluster.ListDeployments(ctx, k8s.ListDeploymentsOptions{
+		Namespace:     r.opts.Namespace,
+		LabelSelector: managedByLabel + "=" + managedByValue,
+	})
+	if err != nil {
+		return wrapStep("list deployments", err)
+	}
+
+	for _, d := range deployments {
+		if d.Name == workloadName {
+			return nil
+		}
+	}
+	return fmt.Errorf("deployment %q not found in listing of %d deployments", workloadName, len(deployments))
+}
+
+// verifyWatch opens a watch, changes the workload and waits for the change to be delivered.
+func (r *Runner) verifyWatch(ctx context.Context) error {
+	watcher, err := r.cluster.GetClientset().AppsV1().Deployments(r.opts.Namespace).
+		Watch(ctx, metav1.ListOptions{FieldSelector: "metadata.name=" + workloadName})
+	if err != nil {
+		return wrapStep("open watch", err)
+	}
+	defer watcher.Stop()
+
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:"true"}}}`, watchLabel)
+	if err := r.patchWorkload(ctx, []byte(patch)); err != nil {
+		return err
+	}
+
+	return waitForEvent(ctx, watcher, func(d *appsv1.Deployment) bool {
+		return d.Labels[watchLabel] == "true"
+	})
+}
+
+// waitForEvent consumes watch events until a modified deployment satisfies the predicate.
+func waitForEvent(ctx context.Context, watcher watch.Interface, match func(*appsv1.Deployment) bool) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no matching watch event received: %w", ctx.Err())
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch closed before matching event was received")
+			}
+			if d, isDeployment := event.Object.(*appsv1.Deployment); isDeployment &&
+				event.Type == watch.Modified && match(d) {
+				return nil
+			}
+		}
+	}
+}
+
+// verifyScale scales the workload through the tool's patch path and reads the result back.
+func (r *Runner) verifyScale(ctx context.Context) error {
+	patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, scaledReplicas)
+	if err := r.patchWorkload(ctx, []byte(patch)); err != nil {
+		return err
+	}
+
+	deployment, err := r.cluster.GetClientset().AppsV1().Deployments(r.opts.Namespace).
+		Get(ctx, workloadName, metav1.GetOptions{})
+	if err != nil {
+		return wrapStep("read scaled deployment", err)
+	}
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != scaledReplicas {
+		return fmt.Errorf("expected %d desired replicas after scaling", scaledReplicas)
+	}
+	return nil
+}
+
+// verifyDelete deletes the workload and confirms that it is gone.
+func (r *Runner) verifyDelete(ctx context.Context) error {
+	if err := r.cluster.DeleteDeployment(ctx, r.opts.Namespace, workloadName); err != nil {
+		return wrapStep("delete deployment", err)
+	}
+
+	_, err := r.cluster.GetClientset().AppsV1().Deployments(r.opts.Namespace).
+		Get(ctx, workloadName, metav1.GetOptions{})
+	if err == nil {
+		return fmt.Errorf("deployment %q still exists after delete", workloadName)
+	}
+	if !apierrors.IsNotFound(err) {
+		return wrapStep("verify deletion", err)
+	}
+	return nil
+}
+
+// cleanup removes the temporary namespace. It only deletes namespaces labelled by the self-test.
+func (r *Runner) cleanup(ctx context.Context) error {
+	ns, err := r.cluster.GetClientset().CoreV1().Namespaces().Get(ctx, r.opts.Namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return wrapStep("read namespace", err)
+	}
+	if ns.Labels[managedByLabel] != managedByValue {
+		return fmt.Errorf("namespace %q was not created by the self-test, leaving it in place", r.opts.Namespace)
+	}
+
+	_, err = r.cluster.DeleteNamespace(ctx, r.opts.Namespace)
+	return wrapStep("delete namespace", err)
+}
+
+// patchWorkload applies a strategic merge patch to the test workload through the tool's Patch API.
+func (r *Runner) patchWorkload(ctx context.Context, patch []byte) error {
+	info, err := k8s.LookupResource("deployments")
+	if err != nil {
+		return err
+	}
+	_, err = r.cluster.Patch(ctx, info.GVR, r.opts.Namespace, workloadName, types.StrategicMergePatchType, patch)
+	return wrapStep("patch deployment", err)
+}
@@ -0,0 +1,97 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the access log middleware.
+package server
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultAccessLogSampling logs one in this many successful requests to the sampledRoutes.
+const DefaultAccessLogSampling = 10
+
+// headerRequestID carries the ID of a request, from the client or a proxy, and back in the response.
+const headerRequestID = "X-Request-ID"
+
+// maxRequestIDLength is the longest request ID accepted from a client; longer IDs are replaced.
+const maxRequestIDLength = 128
+
+// requestIDUserValue is the request user value holding the ID of the request.
+const requestIDUserValue = "requestID"
+
+// sampledRoutes are the routes polled by kubelets and scrapers, whose successful requests are only
+// logged one in Options.AccessLogSampling times to keep them from drowning the log.
+var sampledRoutes = map[string]bool{"/livez": true, "/readyz": true, "/startupz": true, "/metrics": true}
+
+// accessLog wraps a handler to log each request with its method, path, status code, response size,
+// latency, remote IP, user agent, request ID and, for traced requests, trace ID. The request ID is taken
+// from the X-Request-ID header of the request, or generated, and returned in the X-Request-ID header of
+// the response. Successful requests to the sampledRoutes are logged one in sampling times; failed
+// requests are always logged.
+func accessLog(logger zerolog.Logger, sampling int, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if sampling < 1 {
+		sampling = DefaultAccessLogSampling
+	}
+	sampled := logger.Sample(&zerolog.BasicSampler{N: uint32(sampling)})
+
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		path := string(ctx.Path())
+		id := string(ctx.Request.Header.Peek(headerRequestID))
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+		ctx.SetUserValue(requestIDUserValue, id)
+		ctx.Response.Header.Set(headerRequestID, id)
+
+		next(ctx)
+
+		status := ctx.Response.StatusCode()
+		event := logger.Info()
+		if status < fasthttp.StatusBadRequest && sampledRoutes[path] {
+			event = sampled.Info()
+		}
+		event = event.
+			Str("method", string(ctx.Method())).
+			Str("path", path).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("remote_ip", ctx.RemoteIP().String()).
+			Str("user_agent", string(ctx.UserAgent())).
+			Str("request_id", id)
+		// Streamed bodies are written after the handler returns, so their size is unknown here.
+		if !ctx.Response.IsBodyStream() {
+			event = event.Int("bytes", len(ctx.Response.Body()))
+		}
+		if traceID := requestTraceID(ctx); traceID != "" {
+			event = event.Str("trace_id", traceID)
+		}
+		if caller := requestCaller(ctx); caller != "" {
+			event = event.Str("caller", caller)
+		}
+		event.Msg("HTTP request")
+	}
+}
+
+// validRequestID reports whether a request ID from a client can be logged and returned as is: it must
+// be non-empty, at most maxRequestIDLength long and consist of printable ASCII characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID returns the ID assigned to a request by accessLog, or "" if there is none.
+func requestID(ctx *fasthttp.RequestCtx) string {
+	id, _ := ctx.UserValue(requestIDUserValue).(string)
+	return id
+}
@@ -0,0 +1,96 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the access log middleware.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+)
+
+// TestAccessLog verifies the fields of an access log entry and the propagation of request IDs.
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		wantID    string
+	}{
+		{"client request ID", "abc-123", "abc-123"},
+		{"generated request ID", "", ""},
+		{"invalid request ID", "bad\nid", ""},
+		{"too long request ID", strings.Repeat("x", maxRequestIDLength+1), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuf bytes.Buffer
+			handler := accessLog(zerolog.New(&logBuf), 0, func(ctx *fasthttp.RequestCtx) {
+				ctx.SetStatusCode(fasthttp.StatusTeapot)
+				ctx.SetBodyString("hello")
+			})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/api/v1/pods?namespace=shop")
+			ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+			ctx.Request.Header.SetUserAgent("curl/8.0")
+			if tt.requestID != "" {
+				ctx.Request.Header.Set(headerRequestID, tt.requestID)
+			}
+			handler(ctx)
+
+			var entry map[string]any
+			if err := json.Unmarshal(logBuf.Bytes(), &entry); err != nil {
+				t.Fatalf("failed to decode log entry %q: %v", logBuf.String(), err)
+			}
+			id := string(ctx.Response.Header.Peek(headerRequestID))
+			if id == "" || (tt.wantID != "" && id != tt.wantID) || id != requestID(ctx) {
+				t.Errorf("unexpected response request ID %q, want %q", id, tt.wantID)
+			}
+			want := map[string]any{"method": "POST", "path": "/api/v1/pods", "status": 418.0, "bytes": 5.0,
+				"user_agent": "curl/8.0", "request_id": id, "message": "HTTP request"}
+			for field, value := range want {
+				if entry[field] != value {
+					t.Errorf("expected %s %v in the log entry, got %v", field, value, entry[field])
+				}
+			}
+			if _, ok := entry["latency"]; !ok {
+				t.Error("expected the latency in the log entry")
+			}
+		})
+	}
+}
+
+// TestAccessLogSampling verifies that successful probe requests are sampled and all others are logged.
+func TestAccessLogSampling(t *testing.T) {
+	var logBuf bytes.Buffer
+	handler := accessLog(zerolog.New(&logBuf), 5, func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == "/readyz" {
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		}
+	})
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/livez", 2},
+		{"/readyz", 10},
+		{"/api/v1/deployments", 10},
+	}
+
+	for _, tt := range tests {
+		logBuf.Reset()
+		for range 10 {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.path)
+			handler(ctx)
+		}
+		if got := strings.Count(logBuf.String(), "\n"); got != tt.want {
+			t.Errorf("%s: expected %d log entries for 10 requests, got %d", tt.path, tt.want, got)
+		}
+	}
+}
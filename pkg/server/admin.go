@@ -0,0 +1,45 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the admin endpoints changing the server at runtime.
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/logger"
+)
+
+// logLevelPath is the path of the log level admin endpoint.
+const logLevelPath = "/admin/loglevel"
+
+// logLevel is the JSON body of the log level admin endpoint, in requests and responses.
+type logLevel struct {
+	Level    string `json:"level" doc:"Log level: debug, info, warn, error, fatal or panic"`
+	Previous string `json:"previous,omitempty" doc:"Log level before the change, in responses to PUT"`
+}
+
+// getLogLevel handles GET /admin/loglevel, returning the current log level.
+func (h *apiHandler) getLogLevel(ctx *fasthttp.RequestCtx) {
+	h.writeJSON(ctx, fasthttp.StatusOK, logLevel{Level: logger.Level()})
+}
+
+// setLogLevel handles PUT /admin/loglevel, changing the level of all loggers of the process, so that
+// production issues can be debugged without a restart. The change is logged with its caller as a
+// warning, which is seen at every level but fatal and panic, and lasts until the next change or restart.
+func (h *apiHandler) setLogLevel(ctx *fasthttp.RequestCtx) {
+	var request logLevel
+	if err := json.Unmarshal(ctx.PostBody(), &request); err != nil {
+		h.writeError(ctx, fasthttp.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	previous := logger.Level()
+	if err := logger.SetLevel(request.Level); err != nil {
+		h.writeError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.Warn().Str("level", logger.Level()).Str("previous", previous).Str("caller", requestCaller(ctx)).
+		Msg("Changed log level")
+	h.writeJSON(ctx, fasthttp.StatusOK, logLevel{Level: logger.Level(), Previous: previous})
+}
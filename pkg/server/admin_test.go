@@ -0,0 +1,76 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the admin endpoints.
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+)
+
+// TestLogLevel tests changing the log level through PUT /admin/loglevel, which always requires
+// authentication, and reading it back through GET /admin/loglevel.
+func TestLogLevel(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	auth := NewTokenAuthenticator(map[string]string{"secret": "ci"})
+
+	tests := []struct {
+		name       string
+		auth       Authenticator
+		header     string
+		body       string
+		wantStatus int
+		wantLevel  string
+	}{
+		{"no authenticator", nil, "Bearer secret", `{"level":"debug"}`, fasthttp.StatusUnauthorized, "info"},
+		{"invalid token", auth, "Bearer guess", `{"level":"debug"}`, fasthttp.StatusUnauthorized, "info"},
+		{"no token", auth, "", `{"level":"debug"}`, fasthttp.StatusUnauthorized, "info"},
+		{"valid token", auth, "Bearer secret", `{"level":"debug"}`, fasthttp.StatusOK, "debug"},
+		{"upper case level", auth, "Bearer secret", `{"level":"WARN"}`, fasthttp.StatusOK, "warn"},
+		{"unknown level", auth, "Bearer secret", `{"level":"verbose"}`, fasthttp.StatusBadRequest, "info"},
+		{"missing level", auth, "Bearer secret", `{}`, fasthttp.StatusBadRequest, "info"},
+		{"invalid body", auth, "Bearer secret", `level=debug`, fasthttp.StatusBadRequest, "info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zerolog.SetGlobalLevel(zerolog.InfoLevel)
+			handler := createHandler(zerolog.Nop(), Options{Authenticator: tt.auth})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(fasthttp.MethodPut)
+			ctx.Request.SetRequestURI(logLevelPath)
+			if tt.header != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAuthorization, tt.header)
+			}
+			ctx.Request.SetBodyString(tt.body)
+			handler(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s",
+					tt.wantStatus, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+			if tt.wantStatus == fasthttp.StatusOK {
+				var got logLevel
+				if err := json.Unmarshal(ctx.Response.Body(), &got); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if want := (logLevel{Level: tt.wantLevel, Previous: "info"}); got != want {
+					t.Errorf("expected response %+v, got %+v", want, got)
+				}
+			}
+
+			ctx = &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(logLevelPath)
+			handler(ctx)
+			var got logLevel
+			if err := json.Unmarshal(ctx.Response.Body(), &got); err != nil {
+				t.Fatalf("failed to decode GET response: %v", err)
+			}
+			if got.Level != tt.wantLevel {
+				t.Errorf("expected level %q, got %q", tt.wantLevel, got.Level)
+			}
+		})
+	}
+}
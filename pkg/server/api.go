@@ -0,0 +1,158 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the JSON API endpoints backed by the Kubernetes client.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/reports"
+	"github.com/Searge/k8s-controller/pkg/startup"
+)
+
+// errorResponse is the JSON body returned for failed API requests.
+type errorResponse struct {
+	Error string `json:"error" doc:"Human-readable reason the request failed"`
+	Hint  string `json:"hint,omitempty" doc:"Suggested fix for known Kubernetes API errors, e.g. missing RBAC"`
+}
+
+// APIType documents the JSON response of an HTTP API endpoint.
+type APIType struct {
+	// Endpoint is the method and path serving the type.
+	Endpoint string
+
+	// Description summarizes the response.
+	Description string
+
+	// Type is the Go type encoded as the response body.
+	Type reflect.Type
+}
+
+// APITypes returns the JSON response types of the HTTP API by name, for field documentation.
+func APITypes() map[string]APIType {
+	return map[string]APIType{
+		"deployments": {"GET /api/v1/deployments",
+			"Deployments of the connected cluster, in the envelope of 'kc list deployments -o json'.",
+			reflect.TypeOf(resourceList[k8s.DeploymentInfo]{})},
+		"pods": {"GET /api/v1/pods",
+			"Pods with their readiness and status, in the same envelope as the deployments.",
+			reflect.TypeOf(resourceList[k8s.PodInfo]{})},
+		"services": {"GET /api/v1/services",
+			"Services with their addresses and ports, in the same envelope as the deployments.",
+			reflect.TypeOf(resourceList[k8s.ServiceInfo]{})},
+		"nodes": {"GET /api/v1/nodes",
+			"Nodes with their status, roles and kubelet version, in the same envelope as the deployments.",
+			reflect.TypeOf(resourceList[k8s.NodeInfo]{})},
+		"namespaces": {"GET /api/v1/namespaces",
+			"Namespaces with their phase and labels, in the same envelope as the deployments.",
+			reflect.TypeOf(resourceList[k8s.NamespaceInfo]{})},
+		"deployment": {"POST /api/v1/namespaces/{namespace}/deployments, PUT .../deployments/{name}",
+			"The deployment created or replaced via the write API.",
+			reflect.TypeOf(k8s.DeploymentInfo{})},
+		"deletion": {"DELETE /api/v1/namespaces/{namespace}/deployments/{name}",
+			"The deployment deleted via the write API.",
+			reflect.TypeOf(deletedResponse{})},
+		"limits": {"GET /api/v1/limits",
+			"The server's upstream retry budget, for tuning client-side timeouts.",
+			reflect.TypeOf(limitsResponse{})},
+		"readyz": {"GET /readyz",
+			"Readiness of the server, with the reasons it is not ready.",
+			reflect.TypeOf(probeResponse{})},
+		"startupz": {"GET /startupz",
+			"Staged initialization progress of the server.",
+			reflect.TypeOf(startup.Status{})},
+		"health": {"GET /healthz/detail",
+			"Health of the server and its subsystems, for operational triage.",
+			reflect.TypeOf(healthDetail{})},
+		"version": {"GET /version",
+			"Build metadata of the server and the version of the connected Kubernetes API server.",
+			reflect.TypeOf(versionResponse{})},
+		"hook": {"POST /hooks/{name}",
+			"A webhook delivery accepted by a hook.",
+			reflect.TypeOf(hookResponse{})},
+		"loglevel": {"GET, PUT /admin/loglevel",
+			"The log level of the server, as changed at runtime.",
+			reflect.TypeOf(logLevel{})},
+		"error": {"/api/v1/* on failure",
+			"Body of failed API requests.",
+			reflect.TypeOf(errorResponse{})},
+	}
+}
+
+// apiHandler serves the Kubernetes-backed API endpoints.
+type apiHandler struct {
+	client   *k8s.Client
+	budget   RetryBudget
+	reports  *reports.Registry
+	apiCheck *apiCheck
+	logger   zerolog.Logger
+
+	// started is when the handler was created, at the start of the server.
+	started time.Time
+
+	// shutdown is done when the server shuts down, which ends the watch streams.
+	shutdown context.Context
+}
+
+// newAPIHandler creates an apiHandler. The client may be nil.
+func newAPIHandler(client *k8s.Client, budget RetryBudget, logger zerolog.Logger) *apiHandler {
+	return &apiHandler{client: client, budget: budget, reports: reports.Builtin(), apiCheck: newAPICheck(client),
+		logger: logger, started: time.Now(), shutdown: context.Background()}
+}
+
+// limits handles GET /api/v1/limits, advertising the upstream retry budget
+// so API consumers can tune their client-side timeouts.
+func (h *apiHandler) limits(ctx *fasthttp.RequestCtx) {
+	h.writeJSON(ctx, fasthttp.StatusOK, h.budget.limits())
+}
+
+// writeJSON serializes a value as the JSON response body with the given status code.
+func (h *apiHandler) writeJSON(ctx *fasthttp.RequestCtx, status int, value any) {
+	ctx.SetStatusCode(status)
+	ctx.SetContentType("application/json")
+	if err := json.NewEncoder(ctx).Encode(value); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to write JSON response")
+	}
+}
+
+// writeUpstreamError writes a JSON error response for a failed Kubernetes API call,
+// with a status code and remediation hint matching the error's category.
+func (h *apiHandler) writeUpstreamError(ctx *fasthttp.RequestCtx, err error) {
+	err = k8s.ClassifyError(err)
+	h.writeJSON(ctx, upstreamStatus(err), errorResponse{Error: err.Error(), Hint: k8s.ErrorHint(err)})
+}
+
+// upstreamStatus maps a classified Kubernetes API error to the HTTP status code of the response.
+// Authentication and authorization errors concern the server's own credentials,
+// not the caller's, so they are reported as a bad gateway like other upstream errors;
+// changes denied by the authorization hook are forbidden to the caller.
+func upstreamStatus(err error) int {
+	switch {
+	case errors.Is(err, k8s.ErrNotFound):
+		return fasthttp.StatusNotFound
+	case errors.Is(err, authz.ErrDenied):
+		return fasthttp.StatusForbidden
+	case apierrors.IsAlreadyExists(err), apierrors.IsConflict(err):
+		return fasthttp.StatusConflict
+	case apierrors.IsInvalid(err):
+		return fasthttp.StatusUnprocessableEntity
+	case errors.Is(err, k8s.ErrTimeout):
+		return fasthttp.StatusGatewayTimeout
+	default:
+		return fasthttp.StatusBadGateway
+	}
+}
+
+// writeError writes a JSON error response with the given status code.
+func (h *apiHandler) writeError(ctx *fasthttp.RequestCtx, status int, message string) {
+	h.writeJSON(ctx, status, errorResponse{Error: message})
+}
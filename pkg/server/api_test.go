@@ -0,0 +1,73 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the Kubernetes-backed JSON API endpoints.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Searge/k8s-controller/pkg/authz"
+)
+
+// TestAPITypesDocumented verifies that every top-level field of the API response types has a description.
+func TestAPITypesDocumented(t *testing.T) {
+	for name, apiType := range APITypes() {
+		if apiType.Endpoint == "" || apiType.Description == "" || apiType.Type.Kind() != reflect.Struct {
+			t.Errorf("%s: incomplete API type %+v", name, apiType)
+			continue
+		}
+		for i := range apiType.Type.NumField() {
+			if field := apiType.Type.Field(i); field.Tag.Get("doc") == "" {
+				t.Errorf("%s: field %s has no doc tag", name, field.Name)
+			}
+		}
+	}
+}
+
+// TestWriteUpstreamError verifies status codes and hints of Kubernetes API errors.
+func TestWriteUpstreamError(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantHint   bool
+	}{
+		{"not found", apierrors.NewNotFound(deployments, "web"), fasthttp.StatusNotFound, true},
+		{"forbidden", apierrors.NewForbidden(deployments, "", errors.New("no RBAC")), fasthttp.StatusBadGateway,
+			true},
+		{"timeout", context.DeadlineExceeded, fasthttp.StatusGatewayTimeout, true},
+		{"denied", fmt.Errorf("%w: no changes on Fridays", authz.ErrDenied), fasthttp.StatusForbidden, false},
+		{"already exists", apierrors.NewAlreadyExists(deployments, "web"), fasthttp.StatusConflict, false},
+		{"invalid", apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "web", nil),
+			fasthttp.StatusUnprocessableEntity, false},
+		{"other", errors.New("boom"), fasthttp.StatusBadGateway, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			newAPIHandler(nil, RetryBudget{}, zerolog.Nop()).writeUpstreamError(ctx, tt.err)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, ctx.Response.StatusCode())
+			}
+			var body errorResponse
+			if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Error != tt.err.Error() || (body.Hint != "") != tt.wantHint {
+				t.Errorf("unexpected body %+v", body)
+			}
+		})
+	}
+}
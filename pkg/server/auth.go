@@ -0,0 +1,179 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the authentication middleware protecting the API endpoints.
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// callerUserValue is the request user value holding the name of the authenticated caller.
+const callerUserValue = "caller"
+
+// Authentication errors not specific to an authenticator.
+var (
+	// errNoCredentials is returned for requests without a bearer token.
+	errNoCredentials = errors.New("missing bearer token")
+
+	// errInvalidToken is returned for bearer tokens an authenticator does not know.
+	errInvalidToken = errors.New("invalid bearer token")
+
+	// errForbidden is returned, wrapped, for authenticated callers not allowed to make a request.
+	errForbidden = errors.New("forbidden")
+)
+
+// Authenticator identifies the caller of a request.
+type Authenticator interface {
+	// Authenticate returns the name of the caller, or an error if the request carries no valid credentials.
+	Authenticate(ctx *fasthttp.RequestCtx) (string, error)
+}
+
+// TokenAuthenticator authenticates callers by static bearer tokens.
+type TokenAuthenticator struct {
+	// tokens maps each token to the name of its caller.
+	tokens map[string]string
+}
+
+// chainAuthenticator authenticates callers with the first of its authenticators accepting them.
+type chainAuthenticator []Authenticator
+
+// ChainAuthenticators returns an Authenticator accepting the callers of any of auths, tried in order,
+// e.g. callers with static tokens or JWTs.
+func ChainAuthenticators(auths ...Authenticator) Authenticator {
+	return chainAuthenticator(auths)
+}
+
+// Authenticate returns the caller name of the first authenticator accepting the request. If none does,
+// it returns the most specific error, e.g. why a JWT was rejected rather than that it is no static token.
+func (c chainAuthenticator) Authenticate(ctx *fasthttp.RequestCtx) (string, error) {
+	err := errNoCredentials
+	for _, auth := range c {
+		caller, authErr := auth.Authenticate(ctx)
+		if authErr == nil {
+			return caller, nil
+		}
+		if errors.Is(err, errNoCredentials) || errors.Is(err, errInvalidToken) {
+			err = authErr
+		}
+	}
+	return "", err
+}
+
+// NewTokenAuthenticator creates a TokenAuthenticator accepting the given tokens, mapped to caller names.
+func NewTokenAuthenticator(tokens map[string]string) *TokenAuthenticator {
+	return &TokenAuthenticator{tokens: tokens}
+}
+
+// LoadTokenFile reads the bearer tokens of a TokenAuthenticator from a file with one TOKEN[,NAME] per line.
+// Blank lines and lines starting with # are skipped; tokens without a name are named after their line.
+func LoadTokenFile(path string) (*TokenAuthenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		token, name, _ := strings.Cut(text, ",")
+		token, name = strings.TrimSpace(token), strings.TrimSpace(name)
+		if token == "" {
+			return nil, fmt.Errorf("%s:%d: empty token", path, line)
+		}
+		if name == "" {
+			name = "token-" + strconv.Itoa(line)
+		}
+		tokens[token] = name
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens in %s", path)
+	}
+	return NewTokenAuthenticator(tokens), nil
+}
+
+// Authenticate returns the caller name of the request's bearer token. All tokens are compared
+// in constant time, so response times do not reveal how much of a token matched.
+func (a *TokenAuthenticator) Authenticate(ctx *fasthttp.RequestCtx) (string, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	caller := ""
+	for candidate, name := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			caller = name
+		}
+	}
+	if caller == "" {
+		return "", errInvalidToken
+	}
+	return caller, nil
+}
+
+// bearerToken returns the bearer token of the request's Authorization header.
+func bearerToken(ctx *fasthttp.RequestCtx) (string, error) {
+	header := string(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization))
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return "", errNoCredentials
+	}
+	return token, nil
+}
+
+// requireAuth wraps a handler so that it only serves requests authenticated by auth, and responds
+// 401 Unauthorized otherwise, or 403 Forbidden if the caller is not allowed to make the request.
+// A nil authenticator rejects every request. The caller name is stored
+// in the callerUserValue of the request; requests already authenticated are served directly.
+func (h *apiHandler) requireAuth(auth Authenticator, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if requestCaller(ctx) != "" {
+			next(ctx)
+			return
+		}
+		if auth == nil {
+			h.writeUnauthorized(ctx, errors.New("no authenticator configured"))
+			return
+		}
+		caller, err := auth.Authenticate(ctx)
+		if errors.Is(err, errForbidden) {
+			h.logger.Warn().Err(err).Str("path", string(ctx.Path())).Msg("Rejected unauthorized request")
+			h.writeError(ctx, fasthttp.StatusForbidden, err.Error())
+			return
+		}
+		if err != nil {
+			h.writeUnauthorized(ctx, err)
+			return
+		}
+		ctx.SetUserValue(callerUserValue, caller)
+		next(ctx)
+	}
+}
+
+// writeUnauthorized responds 401 with a bearer challenge and the reason authentication failed.
+func (h *apiHandler) writeUnauthorized(ctx *fasthttp.RequestCtx, err error) {
+	h.logger.Warn().Err(err).Str("path", string(ctx.Path())).Msg("Rejected unauthenticated request")
+	ctx.Response.Header.Set(fasthttp.HeaderWWWAuthenticate, `Bearer realm="k8s-controller"`)
+	h.writeError(ctx, fasthttp.StatusUnauthorized, err.Error())
+}
+
+// requestCaller returns the name of the authenticated caller of a request, or "" if there is none.
+func requestCaller(ctx *fasthttp.RequestCtx) string {
+	caller, _ := ctx.UserValue(callerUserValue).(string)
+	return caller
+}
@@ -0,0 +1,187 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the authentication middleware of the API endpoints.
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+)
+
+// TestLoadTokenFile verifies parsing of token files with optional caller names and comments.
+func TestLoadTokenFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantTokens map[string]string
+		wantErr    string
+	}{
+		{"named and unnamed tokens", "# CI and ops\nabc,ci\n\n  def  \n",
+			map[string]string{"abc": "ci", "def": "token-4"}, ""},
+		{"empty token", ",ci\n", nil, "empty token"},
+		{"no tokens", "# nothing yet\n", nil, "no tokens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokens")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			auth, err := LoadTokenFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadTokenFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTokenFile() error = %v", err)
+			}
+			if len(auth.tokens) != len(tt.wantTokens) {
+				t.Errorf("expected tokens %v, got %v", tt.wantTokens, auth.tokens)
+			}
+			for token, name := range tt.wantTokens {
+				if auth.tokens[token] != name {
+					t.Errorf("expected token %q named %q, got %q", token, name, auth.tokens[token])
+				}
+			}
+		})
+	}
+
+	if _, err := LoadTokenFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing token file")
+	}
+}
+
+// TestChainAuthenticators verifies that callers of any authenticator are accepted, and that the most
+// specific error is returned otherwise.
+func TestChainAuthenticators(t *testing.T) {
+	jwtAuth, err := NewJWTAuthenticator(JWTOptions{Secret: testJWTSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := ChainAuthenticators(NewTokenAuthenticator(map[string]string{"secret": "ci"}), jwtAuth)
+	expired := signTestJWT(t, "HS256", "", testJWTSecret, map[string]any{"sub": "dashboard", "exp": 1})
+	valid := signTestJWT(t, "HS256", "", testJWTSecret, map[string]any{"sub": "dashboard", "exp": 1 << 40})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantCaller string
+		wantErr    string
+	}{
+		{"static token", "Bearer secret", "ci", ""},
+		{"JWT", "Bearer " + valid, "dashboard", ""},
+		{"unknown static token", "Bearer guess", "", "invalid bearer token"},
+		{"expired JWT", "Bearer " + expired, "", "JWT expired"},
+		{"no header", "", "", "missing bearer token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			if tt.header != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAuthorization, tt.header)
+			}
+			caller, err := chain.Authenticate(ctx)
+			if caller != tt.wantCaller || (tt.wantErr == "") != (err == nil) ||
+				(err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Authenticate() = %q, %v, want %q, %q", caller, err, tt.wantCaller, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestCreateHandlerRequireAuth verifies that with RequireAuth only the open paths are served to
+// unauthenticated callers, and that authenticated callers can also use the write API.
+func TestCreateHandlerRequireAuth(t *testing.T) {
+	handler := createHandler(zerolog.Nop(), Options{
+		RequireAuth:    true,
+		OpenPaths:      DefaultOpenPaths,
+		EnableWriteAPI: true,
+		Authenticator:  NewTokenAuthenticator(map[string]string{"secret": "ci"}),
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     string
+		wantStatus int
+	}{
+		{"open probe", fasthttp.MethodGet, "/livez", "", fasthttp.StatusOK},
+		{"protected endpoint", fasthttp.MethodGet, "/api/v1/limits", "", fasthttp.StatusUnauthorized},
+		{"protected greeting", fasthttp.MethodGet, "/", "", fasthttp.StatusUnauthorized},
+		{"authenticated endpoint", fasthttp.MethodGet, "/api/v1/limits", "Bearer secret", fasthttp.StatusOK},
+		// Without a client, requests passing authentication are answered with 503.
+		{"authenticated write", fasthttp.MethodDelete, "/api/v1/namespaces/shop/deployments/cart", "Bearer secret",
+			fasthttp.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.path)
+			if tt.header != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAuthorization, tt.header)
+			}
+			handler(ctx)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s",
+					tt.wantStatus, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		})
+	}
+}
+
+// TestRequireAuth verifies that only requests with a known bearer token reach the handler.
+func TestRequireAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		auth       Authenticator
+		header     string
+		wantStatus int
+		wantCaller string
+	}{
+		{"valid token", NewTokenAuthenticator(map[string]string{"secret": "ci"}), "Bearer secret",
+			fasthttp.StatusOK, "ci"},
+		{"invalid token", NewTokenAuthenticator(map[string]string{"secret": "ci"}), "Bearer guess",
+			fasthttp.StatusUnauthorized, ""},
+		{"basic auth", NewTokenAuthenticator(map[string]string{"secret": "ci"}), "Basic c2VjcmV0",
+			fasthttp.StatusUnauthorized, ""},
+		{"no header", NewTokenAuthenticator(map[string]string{"secret": "ci"}), "", fasthttp.StatusUnauthorized, ""},
+		{"no authenticator", nil, "Bearer secret", fasthttp.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var caller string
+			api := newAPIHandler(nil, RetryBudget{}, zerolog.Nop())
+			handler := api.requireAuth(tt.auth, func(ctx *fasthttp.RequestCtx) {
+				caller = requestCaller(ctx)
+			})
+
+			ctx := &fasthttp.RequestCtx{}
+			if tt.header != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAuthorization, tt.header)
+			}
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus || caller != tt.wantCaller {
+				t.Errorf("expected status %d and caller %q, got %d and %q",
+					tt.wantStatus, tt.wantCaller, ctx.Response.StatusCode(), caller)
+			}
+			challenge := string(ctx.Response.Header.Peek(fasthttp.HeaderWWWAuthenticate))
+			if (tt.wantStatus == fasthttp.StatusUnauthorized) != strings.HasPrefix(challenge, "Bearer") {
+				t.Errorf("unexpected challenge %q", challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the choice of the HTTP server implementation serving the handler.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Names of the HTTP backends.
+const (
+	// BackendFastHTTP serves HTTP/1.1 with fasthttp, which allocates little per request. It is the default.
+	BackendFastHTTP = "fasthttp"
+
+	// BackendNetHTTP serves HTTP/1.1 and HTTP/2, over TLS or as h2c with prior knowledge, with net/http,
+	// e.g. for clients and proxies requiring HTTP/2 such as gRPC.
+	BackendNetHTTP = "net/http"
+)
+
+// Backends are the names of the HTTP backends.
+var Backends = []string{BackendFastHTTP, BackendNetHTTP}
+
+// ALPN protocol IDs of the HTTP versions.
+const (
+	alpnHTTP1 = "http/1.1"
+	alpnHTTP2 = "h2"
+)
+
+// backend is an HTTP server implementation serving the handler of the server.
+type backend interface {
+	// Serve serves the connections accepted from ln until Shutdown.
+	Serve(ln net.Listener) error
+
+	// Shutdown stops accepting connections and waits for the in-flight requests to complete until
+	// ctx is done.
+	Shutdown(ctx context.Context) error
+
+	// ALPNProtocols returns the protocols the backend speaks over TLS, in order of preference.
+	ALPNProtocols() []string
+}
+
+// newBackend creates the backend named name, or the fasthttp backend if name is empty, serving handler
+// within limits.
+func newBackend(name string, handler fasthttp.RequestHandler, limits Limits) (backend, error) {
+	switch name {
+	case "", BackendFastHTTP:
+		return newFastHTTPBackend(handler, limits), nil
+	case BackendNetHTTP:
+		if limits.MaxConnsPerIP > 0 {
+			return nil, errors.New("the net/http backend does not support limiting connections per IP")
+		}
+		return newNetHTTPBackend(handler, limits), nil
+	default:
+		return nil, fmt.Errorf("unknown HTTP backend %q, must be one of %v", name, Backends)
+	}
+}
+
+// alpnConfig returns a copy of config offering the HTTP versions of protocols, and the other protocols
+// of config, e.g. the ACME tls-alpn-01 challenge, in the TLS handshake.
+func alpnConfig(config *tls.Config, protocols []string) *tls.Config {
+	config = config.Clone()
+	next := slices.Clone(protocols)
+	for _, protocol := range config.NextProtos {
+		if protocol != alpnHTTP1 && protocol != alpnHTTP2 && !slices.Contains(next, protocol) {
+			next = append(next, protocol)
+		}
+	}
+	config.NextProtos = next
+	return config
+}
+
+// fasthttpBackend serves the handler with fasthttp.
+type fasthttpBackend struct {
+	server *fasthttp.Server
+}
+
+// newFastHTTPBackend creates a fasthttp backend serving handler within limits.
+func newFastHTTPBackend(handler fasthttp.RequestHandler, limits Limits) *fasthttpBackend {
+	return &fasthttpBackend{server: &fasthttp.Server{
+		Handler:            handler,
+		HeaderReceived:     streamRequestConfig,
+		ErrorHandler:       requestError,
+		ReadTimeout:        limits.ReadTimeout,
+		WriteTimeout:       limits.WriteTimeout,
+		IdleTimeout:        limits.IdleTimeout,
+		MaxRequestBodySize: limits.MaxRequestBodySize,
+		MaxConnsPerIP:      limits.MaxConnsPerIP,
+		Concurrency:        limits.Concurrency,
+
+		// Makes keep-alive clients reconnect, e.g. to another replica, once draining began.
+		CloseOnShutdown: true,
+	}}
+}
+
+// Serve serves the connections accepted from ln until Shutdown.
+func (b *fasthttpBackend) Serve(ln net.Listener) error {
+	return b.server.Serve(ln)
+}
+
+// Shutdown stops accepting connections and waits for the in-flight requests to complete until ctx is done.
+func (b *fasthttpBackend) Shutdown(ctx context.Context) error {
+	return b.server.ShutdownWithContext(ctx)
+}
+
+// ALPNProtocols returns HTTP/1.1, the only version fasthttp speaks.
+func (b *fasthttpBackend) ALPNProtocols() []string {
+	return []string{alpnHTTP1}
+}
@@ -0,0 +1,77 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the choice of the HTTP server implementation.
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TestNewBackend verifies that backends are selected by name and that unsupported limits are rejected.
+func TestNewBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		limits  Limits
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: "*server.fasthttpBackend"},
+		{name: "fasthttp", backend: BackendFastHTTP, want: "*server.fasthttpBackend"},
+		{name: "fasthttp with connections per IP", backend: BackendFastHTTP, limits: Limits{MaxConnsPerIP: 5},
+			want: "*server.fasthttpBackend"},
+		{name: "net/http", backend: BackendNetHTTP, want: "*server.netHTTPBackend"},
+		{name: "net/http with connections per IP", backend: BackendNetHTTP, limits: Limits{MaxConnsPerIP: 5},
+			wantErr: true},
+		{name: "unknown", backend: "hyper", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newBackend(tt.backend, func(*fasthttp.RequestCtx) {}, tt.limits.withDefaults())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if typ := fmt.Sprintf("%T", got); typ != tt.want {
+				t.Errorf("newBackend() = %s, want %s", typ, tt.want)
+			}
+		})
+	}
+}
+
+// TestALPNConfig verifies that only the HTTP versions of the backend are offered, along with other
+// protocols such as the ACME challenge.
+func TestALPNConfig(t *testing.T) {
+	autocert := []string{alpnHTTP2, alpnHTTP1, "acme-tls/1"}
+	tests := []struct {
+		name       string
+		nextProtos []string
+		protocols  []string
+		want       []string
+	}{
+		{"fasthttp", nil, []string{alpnHTTP1}, []string{alpnHTTP1}},
+		{"net/http", nil, []string{alpnHTTP2, alpnHTTP1}, []string{alpnHTTP2, alpnHTTP1}},
+		{"fasthttp with ACME", autocert, []string{alpnHTTP1}, []string{alpnHTTP1, "acme-tls/1"}},
+		{"net/http with ACME", autocert, []string{alpnHTTP2, alpnHTTP1}, autocert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &tls.Config{NextProtos: slices.Clone(tt.nextProtos), MinVersion: tls.VersionTLS12}
+			got := alpnConfig(config, tt.protocols)
+			if !slices.Equal(got.NextProtos, tt.want) {
+				t.Errorf("NextProtos = %v, want %v", got.NextProtos, tt.want)
+			}
+			if !slices.Equal(config.NextProtos, tt.nextProtos) {
+				t.Errorf("the original config was modified: %v", config.NextProtos)
+			}
+		})
+	}
+}
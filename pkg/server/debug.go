@@ -0,0 +1,48 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the pprof and expvar debug endpoints.
+package server
+
+import (
+	"expvar"
+	"runtime"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"github.com/valyala/fasthttp/pprofhandler"
+)
+
+// Paths of the debug endpoints.
+const (
+	pprofPrefix   = "/debug/pprof/"
+	debugVarsPath = "/debug/vars"
+)
+
+// Sampling of the block and mutex profiles, which the runtime does not record by default: one
+// sample per blockProfileRate nanoseconds spent blocked and one per mutexProfileFraction contention
+// events, which keeps the overhead low enough for production.
+const (
+	blockProfileRate     = 10000
+	mutexProfileFraction = 100
+)
+
+// debugVars serves the variables published with expvar, e.g. memstats and cmdline.
+var debugVars = fasthttpadaptor.NewFastHTTPHandler(expvar.Handler())
+
+// handleDebug registers GET /debug/pprof/* with the profiles of net/http/pprof, e.g. profile (CPU), heap,
+// goroutine, block and mutex, and GET /debug/vars with the expvar variables on routes.
+func handleDebug(routes *router) {
+	routes.handle(fasthttp.MethodGet, debugVarsPath, debugVars)
+	routes.handle(fasthttp.MethodGet, strings.TrimSuffix(pprofPrefix, "/"), func(ctx *fasthttp.RequestCtx) {
+		ctx.Redirect(pprofPrefix, fasthttp.StatusMovedPermanently)
+	})
+	routes.handle(fasthttp.MethodGet, pprofPrefix+"{profile...}", pprofhandler.PprofHandler)
+	// go tool pprof posts to the symbol profile to symbolize addresses.
+	routes.handle(fasthttp.MethodPost, pprofPrefix+"symbol", pprofhandler.PprofHandler)
+}
+
+// enableRuntimeProfiles makes the runtime record the block and mutex profiles.
+func enableRuntimeProfiles() {
+	runtime.SetBlockProfileRate(blockProfileRate)
+	runtime.SetMutexProfileFraction(mutexProfileFraction)
+}
@@ -0,0 +1,47 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the pprof and expvar debug endpoints.
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+)
+
+// TestDebugEndpoints tests the debug endpoints with and without --enable-pprof.
+func TestDebugEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		enabled        bool
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"pprof index", true, "/debug/pprof/", fasthttp.StatusOK, "goroutine"},
+		{"goroutine profile", true, "/debug/pprof/goroutine?debug=1", fasthttp.StatusOK, "goroutine profile:"},
+		{"mutex profile", true, "/debug/pprof/mutex?debug=1", fasthttp.StatusOK, "--- mutex:"},
+		{"expvar", true, "/debug/vars", fasthttp.StatusOK, `"memstats"`},
+		{"redirect", true, "/debug/pprof", fasthttp.StatusMovedPermanently, ""},
+		{"disabled", false, "/debug/pprof/", fasthttp.StatusNotFound, "no endpoint serves"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{EnablePprof: tt.enabled})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.path)
+			ctx.Request.Header.SetMethod("GET")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, ctx.Response.StatusCode())
+			}
+			if body := string(ctx.Response.Body()); !strings.Contains(body, tt.expectedBody) {
+				t.Errorf("expected body to contain %q, got:\n%s", tt.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements conditional GET requests with ETag and If-None-Match.
+package server
+
+import (
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// weakETag returns the ETag of a response body in the given version. ETags are weak because bodies
+// in the same version may still differ in derived fields such as ages.
+func weakETag(version string) string {
+	return `W/"` + version + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing weakly as RFC 9110
+// requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified answers a conditional GET with 304 Not Modified if the client already has the body
+// tagged etag, and reports whether it did. It does nothing for an empty etag.
+func notModified(ctx *fasthttp.RequestCtx, etag string) bool {
+	ifNoneMatch := string(ctx.Request.Header.Peek(fasthttp.HeaderIfNoneMatch))
+	if etag == "" || ifNoneMatch == "" || !etagMatches(ifNoneMatch, etag) {
+		return false
+	}
+	ctx.Response.Header.Set(fasthttp.HeaderETag, etag)
+	ctx.SetStatusCode(fasthttp.StatusNotModified)
+	return true
+}
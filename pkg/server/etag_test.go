@@ -0,0 +1,78 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests conditional GET requests with ETag and If-None-Match.
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestEtagMatches verifies the weak comparison of If-None-Match headers.
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		{`W/"abc"`, true},
+		{`"abc"`, true},
+		{`"other", W/"abc"`, true},
+		{`*`, true},
+		{`W/"abcd"`, false},
+		{`"other"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.ifNoneMatch, weakETag("abc")); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.ifNoneMatch, got, tt.want)
+		}
+	}
+}
+
+// TestListDeploymentsETag verifies that cached deployment lists are tagged and answered with
+// 304 Not Modified until a deployment changes.
+func TestListDeploymentsETag(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	handler := createHandler(zerolog.Nop(), Options{Client: client})
+
+	get := func(ifNoneMatch string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v1/deployments?namespace=shop")
+		if ifNoneMatch != "" {
+			ctx.Request.Header.Set(fasthttp.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		handler(ctx)
+		return ctx
+	}
+
+	if etag := get("").Response.Header.Peek(fasthttp.HeaderETag); len(etag) != 0 {
+		t.Errorf("expected no ETag without the read cache, got %q", etag)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.StartCache(ctx, 0); err != nil {
+		t.Fatalf("StartCache() error = %v", err)
+	}
+	first := get("")
+	etag := string(first.Response.Header.Peek(fasthttp.HeaderETag))
+	if first.Response.StatusCode() != fasthttp.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %d and %q", first.Response.StatusCode(), etag)
+	}
+
+	cached := get(etag)
+	if cached.Response.StatusCode() != fasthttp.StatusNotModified || len(cached.Response.Body()) != 0 {
+		t.Errorf("expected 304 without a body, got %d", cached.Response.StatusCode())
+	}
+	if got := string(cached.Response.Header.Peek(fasthttp.HeaderETag)); got != etag {
+		t.Errorf("expected the 304 to carry ETag %q, got %q", etag, got)
+	}
+	if stale := get(`W/"stale"`); stale.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("expected 200 for a stale ETag, got %d", stale.Response.StatusCode())
+	}
+}
@@ -0,0 +1,122 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the streaming report export endpoint.
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Searge/k8s-controller/pkg/reports"
+)
+
+const (
+	// reportsPathPrefix is the path prefix of the report endpoints.
+	reportsPathPrefix = "/api/v1/reports/"
+
+	// exportTimeout bounds the total duration of a single report export.
+	exportTimeout = 10 * time.Minute
+
+	// exportFlushRows is the number of rows buffered before they are flushed to the client.
+	exportFlushRows = 100
+)
+
+// parseExportPath extracts the report name from /api/v1/reports/{name}/export.
+func parseExportPath(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, reportsPathPrefix)
+	if !ok {
+		return "", false
+	}
+	name, ok := strings.CutSuffix(rest, "/export")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// exportReport handles GET /api/v1/reports/{name}/export.
+// The report is streamed as a chunked response (?format=csv or ?format=jsonl) while
+// it is being paged from the Kubernetes API. Rows are flushed to the connection in
+// small batches, so a slow client blocks further API paging instead of growing a buffer.
+func (h *apiHandler) exportReport(ctx *fasthttp.RequestCtx, name string) {
+	if h.client == nil {
+		h.writeError(ctx, fasthttp.StatusServiceUnavailable, "kubernetes client not configured")
+		return
+	}
+
+	report, err := h.reports.Get(name)
+	if err != nil {
+		h.writeError(ctx, fasthttp.StatusNotFound, err.Error())
+		return
+	}
+
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format == "" {
+		format = reports.FormatCSV
+	}
+	contentType, err := reports.ContentType(format)
+	if err != nil {
+		h.writeError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType(contentType)
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	clientset := h.client.GetClientset()
+	// The pages of the report are fetched after the span of the request ended, but are still part of its trace.
+	reqCtx := requestContext(ctx)
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamCtx, cancel := context.WithTimeout(reqCtx, exportTimeout)
+		defer cancel()
+
+		rows, err := streamReport(streamCtx, report, clientset, format, w)
+		if err != nil {
+			h.logger.Error().Err(err).Str("report", name).Int("rows", rows).Msg("Report export aborted")
+			return
+		}
+		h.logger.Info().Str("report", name).Str("format", format).Int("rows", rows).Msg("Report exported")
+	})
+}
+
+// streamReport encodes the report rows into w, flushing every exportFlushRows rows.
+// A failed flush means the client went away; it stops the report so no further pages are fetched.
+// It returns the number of rows written.
+func streamReport(
+	ctx context.Context, report reports.Report, clientset kubernetes.Interface, format string, w *bufio.Writer,
+) (int, error) {
+	exporter, err := reports.NewExporter(format, w, report.Columns())
+	if err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err = report.Stream(ctx, clientset, func(row reports.Row) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := exporter.WriteRow(row); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failed to flush export to client: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+	if err := w.Flush(); err != nil {
+		return rows, fmt.Errorf("failed to flush export to client: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,106 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the streaming report export endpoint.
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestParseExportPath tests extraction of the report name from the request path.
+func TestParseExportPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		name   string
+		wantOK bool
+	}{
+		{"/api/v1/reports/deployments/export", "deployments", true},
+		{"/api/v1/reports/deployments", "", false},
+		{"/api/v1/reports//export", "", false},
+		{"/api/v1/reports/a/b/export", "", false},
+		{"/api/v1/deployments", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			name, ok := parseExportPath(tt.path)
+			if name != tt.name || ok != tt.wantOK {
+				t.Errorf("parseExportPath() = (%q, %v), want (%q, %v)", name, ok, tt.name, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestExportReportEndpoint tests GET /api/v1/reports/{name}/export.
+func TestExportReportEndpoint(t *testing.T) {
+	demoClient := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+
+	tests := []struct {
+		name           string
+		client         *k8s.Client
+		uri            string
+		expectedStatus int
+		expectedType   string
+		expectedLines  int
+		expectedPrefix string
+	}{
+		{
+			name: "csv by default", client: demoClient, uri: "/api/v1/reports/deployments/export",
+			expectedStatus: fasthttp.StatusOK, expectedType: "text/csv; charset=utf-8",
+			expectedLines: 6, expectedPrefix: "namespace,name,desired",
+		},
+		{
+			name: "json lines", client: demoClient, uri: "/api/v1/reports/deployments/export?format=jsonl",
+			expectedStatus: fasthttp.StatusOK, expectedType: "application/x-ndjson",
+			expectedLines: 5, expectedPrefix: "{",
+		},
+		{
+			name: "unknown report", client: demoClient, uri: "/api/v1/reports/nope/export",
+			expectedStatus: fasthttp.StatusNotFound,
+		},
+		{
+			name: "bad format", client: demoClient, uri: "/api/v1/reports/deployments/export?format=xml",
+			expectedStatus: fasthttp.StatusBadRequest,
+		},
+		{
+			name: "no client", uri: "/api/v1/reports/deployments/export",
+			expectedStatus: fasthttp.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Client: tt.client})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.uri)
+			ctx.Request.Header.SetMethod("GET")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, ctx.Response.StatusCode())
+			}
+			if tt.expectedStatus != fasthttp.StatusOK {
+				return
+			}
+			if contentType := string(ctx.Response.Header.ContentType()); contentType != tt.expectedType {
+				t.Errorf("expected content type %q, got %q", tt.expectedType, contentType)
+			}
+
+			body := strings.TrimSpace(string(ctx.Response.Body()))
+			lines := strings.Split(body, "\n")
+			if len(lines) != tt.expectedLines {
+				t.Errorf("expected %d lines, got %d: %q", tt.expectedLines, len(lines), body)
+			}
+			if !strings.HasPrefix(lines[0], tt.expectedPrefix) {
+				t.Errorf("expected first line to start with %q, got %q", tt.expectedPrefix, lines[0])
+			}
+		})
+	}
+}
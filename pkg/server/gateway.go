@@ -0,0 +1,203 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file serves the REST endpoints generated from the gRPC cluster service.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	apiv1 "github.com/Searge/k8s-controller/pkg/api/v1"
+)
+
+// gatewayPattern matches the paths of the REST endpoints generated from the cluster service, see
+// pkg/api/v1/gateway.yaml.
+const gatewayPattern = "/api/v2/{path...}"
+
+// gatewayWatchPath is the path of the deployment watch stream of the gateway.
+const gatewayWatchPath = "/api/v2/watch/deployments"
+
+// gateway returns a handler of the REST endpoints that grpc-gateway generates from the cluster service,
+// so that they answer like the gRPC API. It calls the service over an in-process gRPC connection, which
+// is started on the first request and stopped when the server shuts down. The requests have already
+// passed the middlewares, so the calls are not logged, rate limited or authenticated again.
+//
+// Messages are encoded as JSON with their zero fields, and the events of streams as one JSON object per
+// line. Errors are answered like those of the other endpoints, with the HTTP status of their gRPC code.
+func (h *apiHandler) gateway(opts Options) fasthttp.RequestHandler {
+	ln := fasthttputil.NewInmemoryListener()
+	var start sync.Once
+	conn, err := grpc.NewClient("passthrough:///in-process",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			start.Do(func() {
+				srv := grpc.NewServer()
+				apiv1.RegisterClusterServiceServer(srv, &grpcService{h: h, opts: opts})
+				go func() {
+					_ = srv.Serve(ln)
+				}()
+				context.AfterFunc(h.shutdown, srv.GracefulStop)
+			})
+			return ln.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		// NewClient only fails on invalid options.
+		panic(fmt.Sprintf("failed to create the gateway connection: %v", err))
+	}
+
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithErrorHandler(func(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler,
+			w http.ResponseWriter, _ *http.Request, err error) {
+			h.writeGatewayError(w, runtime.HTTPStatusFromCode(status.Code(err)), status.Convert(err).Message())
+		}),
+		runtime.WithRoutingErrorHandler(func(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler,
+			w http.ResponseWriter, r *http.Request, httpStatus int) {
+			h.writeGatewayError(w, httpStatus, fmt.Sprintf("no endpoint serves %s", r.URL.Path))
+		}),
+	)
+	// Registering a client cannot fail; the error is kept for the connections the generated code dials itself.
+	_ = apiv1.RegisterClusterServiceHandlerClient(context.Background(), mux, apiv1.NewClusterServiceClient(conn))
+	// The calls are detached from the request contexts of fasthttp, whose Done races with the shutdown of
+	// the server and misses clients that went away; the service ends its streams on shutdown itself, with an
+	// error the clients can resume from, and the heartbeats of the watch notice clients that went away.
+	watch := gatewayHeartbeat(mux, sseHeartbeatInterval)
+	return fasthttpadaptor.NewFastHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithoutCancel(r.Context()))
+		if r.URL.Path == gatewayWatchPath {
+			watch.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+// gatewayHeartbeat returns a handler serving streams with next, writing an empty line after each interval
+// without events, which line-based JSON clients skip. Like the heartbeats of the server-sent events, it
+// keeps proxies from closing quiet streams and detects clients that went away, which only fails on a write:
+// a failed heartbeat cancels the call, ending the stream of the service.
+func gatewayHeartbeat(next http.Handler, interval time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		hw := &heartbeatWriter{ResponseWriter: w, header: http.Header{}, quiet: true}
+		// Heartbeats before the first event commit the response, so it is declared JSON up front.
+		w.Header().Set("Content-Type", "application/json")
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			hw.beat(ctx, cancel, interval)
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+		next.ServeHTTP(hw, r.WithContext(ctx))
+	})
+}
+
+// heartbeatWriter is a response writer of a stream that a heartbeat goroutine writes to concurrently. The
+// handler sets the headers on its own copy, which is passed on with its writes, so that the heartbeats
+// never read headers the handler is setting.
+type heartbeatWriter struct {
+	http.ResponseWriter
+	header http.Header
+
+	mu sync.Mutex
+	// quiet is whether nothing was written since the last heartbeat.
+	quiet bool
+}
+
+// Header returns the headers of the handler.
+func (w *heartbeatWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *heartbeatWriter) WriteHeader(statusCode int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.passHeader()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter.
+func (w *heartbeatWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.passHeader()
+	w.quiet = false
+	return w.ResponseWriter.Write(p)
+}
+
+// FlushError sends the written data to the client, see http.ResponseController.
+func (w *heartbeatWriter) FlushError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.passHeader()
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// passHeader copies the headers of the handler to the response. It is called on the goroutine of the
+// handler, with mu held.
+func (w *heartbeatWriter) passHeader() {
+	for name, values := range w.header {
+		w.ResponseWriter.Header()[name] = values
+	}
+}
+
+// beat writes an empty line after each interval without writes, until ctx is cancelled or a heartbeat fails,
+// which calls cancel.
+func (w *heartbeatWriter) beat(ctx context.Context, cancel context.CancelFunc, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := w.heartbeat(); err != nil {
+			cancel()
+			return
+		}
+	}
+}
+
+// heartbeat writes an empty line if nothing was written since the last heartbeat.
+func (w *heartbeatWriter) heartbeat() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.quiet {
+		w.quiet = true
+		return nil
+	}
+	if _, err := w.ResponseWriter.Write([]byte("\n")); err != nil {
+		return err
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// writeGatewayError writes a JSON error response of the gateway with the given status code.
+func (h *apiHandler) writeGatewayError(w http.ResponseWriter, httpStatus int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	if err := json.NewEncoder(w).Encode(errorResponse{Error: message}); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to write JSON response")
+	}
+}
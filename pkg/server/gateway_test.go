@@ -0,0 +1,198 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the REST endpoints generated from the gRPC cluster service.
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestGateway verifies the responses of the gateway endpoints.
+func TestGateway(t *testing.T) {
+	demoClient := k8s.NewFakeClient(zerolog.Nop(), append(k8s.DefaultDemoObjects(time.Now()),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}})...)
+	tests := []struct {
+		name       string
+		client     *k8s.Client
+		uri        string
+		method     string
+		wantStatus int
+		contains   []string
+	}{
+		{"deployments", demoClient, "/api/v2/deployments?namespace=shop", fasthttp.MethodGet, fasthttp.StatusOK,
+			[]string{`"name":"cart"`, `"namespace":"shop"`, `"observedGeneration":"1"`}},
+		{"label selector", demoClient, "/api/v2/deployments?labelSelector=app%3Dhello", fasthttp.MethodGet,
+			fasthttp.StatusOK, []string{`{"deployments":[{"name":"hello","namespace":"default"`}},
+		{"pods", demoClient, "/api/v2/pods?namespace=shop", fasthttp.MethodGet, fasthttp.StatusOK,
+			[]string{`"name":"web-1"`, `"restarts":0`}},
+		{"no client", nil, "/api/v2/pods", fasthttp.MethodGet, fasthttp.StatusServiceUnavailable,
+			[]string{`{"error":"kubernetes client not configured"}`}},
+		{"unknown path", demoClient, "/api/v2/nodes", fasthttp.MethodGet, fasthttp.StatusNotFound,
+			[]string{`{"error":"no endpoint serves /api/v2/nodes"}`}},
+		{"other method", demoClient, "/api/v2/pods", fasthttp.MethodPost, fasthttp.StatusMethodNotAllowed,
+			[]string{`{"error":"method POST not allowed, use GET, HEAD"}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Client: tt.client})
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.uri)
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, ctx.Response.StatusCode(),
+					ctx.Response.Body())
+			}
+			if got := string(ctx.Response.Header.ContentType()); got != "application/json" {
+				t.Errorf("expected content type application/json, got %q", got)
+			}
+			// protojson randomly adds spaces to its output, so that nobody depends on it being stable.
+			var body bytes.Buffer
+			if err := json.Compact(&body, ctx.Response.Body()); err != nil {
+				t.Fatalf("invalid JSON body %s: %v", ctx.Response.Body(), err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body.String(), want) {
+					t.Errorf("expected body to contain %s, got:\n%s", want, body.String())
+				}
+			}
+		})
+	}
+}
+
+// TestGatewayWatch verifies that the watch endpoint streams one event per line until the server shuts down.
+func TestGatewayWatch(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	shutdown, stop := context.WithCancel(context.Background())
+	defer stop()
+	srv := &fasthttp.Server{Handler: newHandler(shutdown, zerolog.Nop(), Options{Client: client})}
+	ln := listenLoopback(t)
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Shutdown() }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = fmt.Fprint(conn, "GET /api/v2/watch/deployments?namespace=shop HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+
+	var resp fasthttp.Response
+	resp.StreamBody = true
+	reader := bufio.NewReader(conn)
+	if err := resp.Read(reader); err != nil {
+		t.Fatalf("Failed to read the response: %v", err)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode())
+	}
+	events := bufio.NewScanner(resp.BodyStream())
+	for range 3 {
+		if !events.Scan() {
+			t.Fatalf("expected 3 events, got error %v", events.Err())
+		}
+		var event struct {
+			Result struct {
+				Type string `json:"type"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(events.Bytes(), &event); err != nil || event.Result.Type != "ADDED" {
+			t.Errorf("unexpected event %s: %v", events.Bytes(), err)
+		}
+	}
+
+	// The stream ends with an error event on shutdown, after which the client resumes it.
+	stop()
+	if !events.Scan() || !strings.Contains(events.Text(), "server shutting down") {
+		t.Errorf("expected a shutdown error event, got %q, %v", events.Text(), events.Err())
+	}
+}
+
+// TestGatewayHeartbeat verifies that quiet streams get heartbeats after their events, and that the call
+// is cancelled once a heartbeat fails because the client went away.
+func TestGatewayHeartbeat(t *testing.T) {
+	cancelled := make(chan struct{})
+	handler := gatewayHeartbeat(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Stream", "watch")
+		_, _ = w.Write([]byte(`{"result":{}}` + "\n"))
+		_ = http.NewResponseController(w).Flush()
+		<-r.Context().Done()
+		close(cancelled)
+	}), 20*time.Millisecond)
+	srv := &fasthttp.Server{Handler: fasthttpadaptor.NewFastHTTPHandler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			handler.ServeHTTP(w, r.WithContext(context.WithoutCancel(r.Context())))
+		}))}
+	ln := listenLoopback(t)
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Shutdown() }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: localhost\r\n\r\n", gatewayWatchPath); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+
+	var resp fasthttp.Response
+	resp.StreamBody = true
+	if err := resp.Read(bufio.NewReader(conn)); err != nil {
+		t.Fatalf("Failed to read the response: %v", err)
+	}
+	if string(resp.Header.Peek("X-Stream")) != "watch" || string(resp.Header.ContentType()) != "application/json" {
+		t.Errorf("expected the headers of the handler, got %s", resp.Header.String())
+	}
+	lines := bufio.NewScanner(resp.BodyStream())
+	for _, want := range []string{`{"result":{}}`, "", ""} {
+		if !lines.Scan() || lines.Text() != want {
+			t.Fatalf("expected line %q, got %q, %v", want, lines.Text(), lines.Err())
+		}
+	}
+
+	_ = conn.Close()
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("expected the call to be cancelled after the client went away")
+	}
+}
+
+// TestGatewayHeartbeatErrors verifies that responses answered before the first heartbeat keep their status.
+func TestGatewayHeartbeatErrors(t *testing.T) {
+	handler := gatewayHeartbeat(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"not found"}`))
+	}), time.Hour)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, gatewayWatchPath, nil))
+	if rec.Code != http.StatusNotFound || rec.Body.String() != `{"error":"not found"}` {
+		t.Errorf("expected the error of the handler, got %d %q", rec.Code, rec.Body.String())
+	}
+}
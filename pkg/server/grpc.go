@@ -0,0 +1,326 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the gRPC cluster service served alongside the HTTP server.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/apimachinery/pkg/watch"
+
+	apiv1 "github.com/Searge/k8s-controller/pkg/api/v1"
+	"github.com/Searge/k8s-controller/pkg/authz"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// gRPC call metric names.
+const (
+	metricGRPCCallsTotal   = "kc_grpc_calls_total"
+	metricGRPCCallDuration = "kc_grpc_call_duration_seconds"
+)
+
+// grpcService implements the ClusterService of the gRPC API on an apiHandler, sharing the Kubernetes
+// client and retry budget of the HTTP endpoints.
+type grpcService struct {
+	apiv1.UnimplementedClusterServiceServer
+
+	h       *apiHandler
+	opts    Options
+	limiter *rate.Limiter
+}
+
+// newGRPCServer creates the gRPC server of the cluster service, serving TLS with the TLSConfig of opts.
+// Like the HTTP API, calls are logged, counted, recovered from panics, rate limited with the request rate
+// of the Limits and, with RequireAuth, authenticated. Open watch streams end when shutdown is done.
+func newGRPCServer(shutdown context.Context, logger zerolog.Logger, opts Options) *grpc.Server {
+	api := newAPIHandler(opts.Client, opts.Budget.withDefaults(), logger)
+	api.shutdown = shutdown
+	service := &grpcService{h: api, opts: opts}
+	if limits := opts.Limits.withDefaults(); limits.RequestRate > 0 {
+		service.limiter = rate.NewLimiter(rate.Limit(limits.RequestRate), limits.RequestBurst)
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (any, error) {
+			var resp any
+			err := service.intercept(ctx, info.FullMethod, func() error {
+				var err error
+				resp, err = handler(ctx, req)
+				return err
+			})
+			return resp, err
+		}),
+		grpc.StreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			return service.intercept(stream.Context(), info.FullMethod, func() error {
+				return handler(srv, stream)
+			})
+		}),
+	}
+	if opts.TLSConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(opts.TLSConfig)))
+	}
+	srv := grpc.NewServer(serverOpts...)
+	apiv1.RegisterClusterServiceServer(srv, service)
+	return srv
+}
+
+// intercept runs call, a call of method: it rejects calls beyond the rate limit and, with RequireAuth,
+// unauthenticated ones, recovers from panics, and logs and counts the call with its status code.
+func (s *grpcService) intercept(ctx context.Context, method string, call func() error) (err error) {
+	start := time.Now()
+	var caller string
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.h.logger.Error().Any("panic", recovered).Str("stack", string(debug.Stack())).
+				Str("grpc_method", method).Msg("Recovered from panic")
+			err = status.Error(codes.Internal, "internal server error")
+		}
+
+		code := status.Code(err)
+		event := s.h.logger.Info().Str("grpc_method", method).Str("grpc_code", code.String()).
+			Dur("latency", time.Since(start))
+		if caller != "" {
+			event = event.Str("caller", caller)
+		}
+		event.Msg("gRPC call")
+		if s.opts.Metrics != nil {
+			labels := metrics.Labels{"method": method}
+			s.opts.Metrics.Observe(metricGRPCCallDuration, labels, time.Since(start).Seconds())
+			labels["code"] = code.String()
+			s.opts.Metrics.Counter(metricGRPCCallsTotal, labels, 1)
+		}
+	}()
+
+	if s.limiter != nil && !s.limiter.Allow() {
+		return status.Error(codes.ResourceExhausted, "request rate limit exceeded, retry later")
+	}
+	if s.opts.RequireAuth {
+		if caller, err = s.authenticate(ctx, method); err != nil {
+			return err
+		}
+	}
+	return call()
+}
+
+// authenticate authenticates the caller of method with the Authenticator of the options, passing it the
+// bearer token of the authorization metadata as a GET request of the full method name, e.g.
+// /k8scontroller.v1.ClusterService/ListPods, so that authorizers see the RPCs as read-only paths.
+func (s *grpcService) authenticate(ctx context.Context, method string) (string, error) {
+	if s.opts.Authenticator == nil {
+		return "", status.Error(codes.Unauthenticated, "no authenticator configured")
+	}
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.Header.SetMethod(fasthttp.MethodGet)
+	reqCtx.Request.SetRequestURI(method)
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		reqCtx.Request.Header.Set(fasthttp.HeaderAuthorization, values[0])
+	}
+
+	caller, err := s.opts.Authenticator.Authenticate(reqCtx)
+	switch {
+	case errors.Is(err, errForbidden):
+		s.h.logger.Warn().Err(err).Str("grpc_method", method).Msg("Rejected unauthorized gRPC call")
+		return "", status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		s.h.logger.Warn().Err(err).Str("grpc_method", method).Msg("Rejected unauthenticated gRPC call")
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	return caller, nil
+}
+
+// ListDeployments returns the deployments of the request, sorted by namespace and name.
+func (s *grpcService) ListDeployments(_ context.Context,
+	req *apiv1.ListDeploymentsRequest) (*apiv1.ListDeploymentsResponse, error) {
+	opts := k8s.ListDeploymentsOptions{Namespace: req.GetNamespace(), LabelSelector: req.GetLabelSelector()}
+	deployments, err := grpcList(s, deploymentListKind, deploymentMeta, func(client *k8s.Client,
+		ctx context.Context) ([]k8s.DeploymentInfo, error) {
+		return client.ListDeployments(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.ListDeploymentsResponse{}
+	for _, deployment := range deployments {
+		resp.Deployments = append(resp.Deployments, deploymentProto(deployment))
+	}
+	return resp, nil
+}
+
+// ListPods returns the pods of the request, sorted by namespace and name.
+func (s *grpcService) ListPods(_ context.Context, req *apiv1.ListPodsRequest) (*apiv1.ListPodsResponse, error) {
+	pods, err := grpcList(s, podListKind, podMeta, func(client *k8s.Client, ctx context.Context) ([]k8s.PodInfo,
+		error) {
+		return client.ListPods(ctx, req.GetNamespace())
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &apiv1.ListPodsResponse{}
+	for _, pod := range pods {
+		resp.Pods = append(resp.Pods, podProto(pod))
+	}
+	return resp, nil
+}
+
+// grpcList calls list within the upstream retry budget and sorts the objects by namespace and name, as
+// serveList does for the HTTP list endpoints.
+func grpcList[T any](s *grpcService, kind listKind, meta func(T) itemMeta,
+	list func(*k8s.Client, context.Context) ([]T, error)) ([]T, error) {
+	if s.h.client == nil {
+		return nil, status.Error(codes.Unavailable, "kubernetes client not configured")
+	}
+	var items []T
+	_, err := s.h.budget.call(context.Background(), func(reqCtx context.Context) error {
+		var listErr error
+		items, listErr = list(s.h.client, reqCtx)
+		return listErr
+	})
+	if err != nil {
+		s.h.logger.Error().Err(err).Msgf("Failed to list %s for gRPC call", kind.resource)
+		return nil, upstreamGRPCError(err)
+	}
+	sortItems(items, meta, listQuery{})
+	return items, nil
+}
+
+// WatchDeployments streams the deployment changes of the request until the client cancels the call or the
+// server shuts down, which ends the stream with Unavailable, so clients resume it from the resource version
+// of the last event, e.g. on another replica.
+func (s *grpcService) WatchDeployments(req *apiv1.WatchDeploymentsRequest,
+	stream grpc.ServerStreamingServer[apiv1.DeploymentEvent]) error {
+	if s.h.client == nil {
+		return status.Error(codes.Unavailable, "kubernetes client not configured")
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	stop := context.AfterFunc(s.h.shutdown, cancel)
+	defer stop()
+
+	opts := k8s.WatchOptions{
+		Namespace:       req.GetNamespace(),
+		LabelSelector:   req.GetLabelSelector(),
+		ResourceVersion: req.GetResourceVersion(),
+	}
+	err := s.h.client.WatchDeployments(ctx, opts, func(event k8s.DeploymentEvent) error {
+		return stream.Send(deploymentEventProto(event))
+	})
+	s.h.logger.Info().Err(err).Str("namespace", opts.Namespace).Msg("Deployment watch stream closed")
+	switch {
+	case s.h.shutdown.Err() != nil:
+		return status.Error(codes.Unavailable, "server shutting down, resume the watch from the last resource version")
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case err != nil:
+		return upstreamGRPCError(err)
+	}
+	return nil
+}
+
+// upstreamGRPCError converts a failed Kubernetes API call to a gRPC status error with the code matching the
+// error's category, as upstreamStatus does for HTTP responses.
+func upstreamGRPCError(err error) error {
+	err = k8s.ClassifyError(err)
+	code := codes.Unavailable
+	switch {
+	case errors.Is(err, k8s.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, authz.ErrDenied):
+		code = codes.PermissionDenied
+	case errors.Is(err, k8s.ErrTimeout):
+		code = codes.DeadlineExceeded
+	}
+	if hint := k8s.ErrorHint(err); hint != "" {
+		return status.Errorf(code, "%v (%s)", err, hint)
+	}
+	return status.Error(code, err.Error())
+}
+
+// deploymentProto converts a deployment summary to its protobuf message.
+func deploymentProto(d k8s.DeploymentInfo) *apiv1.Deployment {
+	return &apiv1.Deployment{
+		Name:      d.Name,
+		Namespace: d.Namespace,
+		Replicas: &apiv1.Deployment_Replicas{
+			Desired:   d.Replicas.Desired,
+			Available: d.Replicas.Available,
+			Ready:     d.Replicas.Ready,
+			Updated:   d.Replicas.Updated,
+		},
+		Images:             d.Images,
+		CreatedAt:          timestamppb.New(d.CreatedAt),
+		Labels:             d.Labels,
+		Selector:           d.Selector,
+		Generation:         d.Generation,
+		ObservedGeneration: d.ObservedGeneration,
+	}
+}
+
+// podProto converts a pod summary to its protobuf message. Pods have far fewer containers than fit in an int32.
+func podProto(p k8s.PodInfo) *apiv1.Pod {
+	return &apiv1.Pod{
+		Name:       p.Name,
+		Namespace:  p.Namespace,
+		Ready:      int32(p.Ready),
+		Containers: int32(p.Containers),
+		Status:     p.Status,
+		Restarts:   p.Restarts,
+		Node:       p.Node,
+		CreatedAt:  timestamppb.New(p.CreatedAt),
+	}
+}
+
+// deploymentEventTypes maps the watch event types to their protobuf enum values.
+var deploymentEventTypes = map[watch.EventType]apiv1.DeploymentEvent_Type{
+	watch.Added:    apiv1.DeploymentEvent_ADDED,
+	watch.Modified: apiv1.DeploymentEvent_MODIFIED,
+	watch.Deleted:  apiv1.DeploymentEvent_DELETED,
+}
+
+// deploymentEventProto converts a deployment change to its protobuf message.
+func deploymentEventProto(event k8s.DeploymentEvent) *apiv1.DeploymentEvent {
+	return &apiv1.DeploymentEvent{
+		Type:            deploymentEventTypes[event.Type],
+		Deployment:      deploymentProto(event.Deployment),
+		ResourceVersion: event.ResourceVersion,
+	}
+}
+
+// shutdownGRPC stops srv from accepting calls and waits up to gracePeriod for its in-flight calls to
+// complete, then cancels the remaining ones.
+func shutdownGRPC(srv *grpc.Server, gracePeriod time.Duration, logger zerolog.Logger) error {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultShutdownGracePeriod
+	}
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+		logger.Info().Msg("gRPC server stopped")
+		return nil
+	case <-timer.C:
+		srv.Stop()
+		return fmt.Errorf("failed to drain in-flight gRPC calls within %s", gracePeriod)
+	}
+}
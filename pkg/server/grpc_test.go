@@ -0,0 +1,227 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the gRPC cluster service.
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apiv1 "github.com/Searge/k8s-controller/pkg/api/v1"
+	"github.com/Searge/k8s-controller/pkg/authz"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// dialGRPC serves the gRPC cluster service with opts on an in-memory listener until the test ends, and
+// returns a client of it. Its watch streams end when shutdown is done.
+func dialGRPC(t *testing.T, shutdown context.Context, opts Options) apiv1.ClusterServiceClient {
+	t.Helper()
+	ln := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(shutdown, zerolog.Nop(), opts)
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to create the client: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return apiv1.NewClusterServiceClient(conn)
+}
+
+// TestGRPCList verifies the deployments and pods listed over gRPC, and that calls without a Kubernetes
+// client are unavailable.
+func TestGRPCList(t *testing.T) {
+	demoClient := k8s.NewFakeClient(zerolog.Nop(), append(k8s.DefaultDemoObjects(time.Now()),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "dns-1", Namespace: "kube-system"}})...)
+	tests := []struct {
+		name      string
+		client    *k8s.Client
+		list      func(apiv1.ClusterServiceClient) ([]string, error)
+		wantNames []string
+		wantCode  codes.Code
+	}{
+		{"deployments", demoClient, func(c apiv1.ClusterServiceClient) ([]string, error) {
+			resp, err := c.ListDeployments(context.Background(), &apiv1.ListDeploymentsRequest{Namespace: "shop"})
+			var names []string
+			for _, d := range resp.GetDeployments() {
+				names = append(names, d.GetNamespace()+"/"+d.GetName())
+			}
+			return names, err
+		}, []string{"shop/cart", "shop/checkout", "shop/frontend"}, codes.OK},
+		{"label selector", demoClient, func(c apiv1.ClusterServiceClient) ([]string, error) {
+			resp, err := c.ListDeployments(context.Background(), &apiv1.ListDeploymentsRequest{
+				LabelSelector: "app=hello"})
+			var names []string
+			for _, d := range resp.GetDeployments() {
+				names = append(names, d.GetNamespace()+"/"+d.GetName())
+			}
+			return names, err
+		}, []string{"default/hello"}, codes.OK},
+		{"pods", demoClient, func(c apiv1.ClusterServiceClient) ([]string, error) {
+			resp, err := c.ListPods(context.Background(), &apiv1.ListPodsRequest{Namespace: "shop"})
+			var names []string
+			for _, p := range resp.GetPods() {
+				names = append(names, p.GetNamespace()+"/"+p.GetName())
+			}
+			return names, err
+		}, []string{"shop/web-1", "shop/web-2"}, codes.OK},
+		{"no client", nil, func(c apiv1.ClusterServiceClient) ([]string, error) {
+			_, err := c.ListPods(context.Background(), &apiv1.ListPodsRequest{})
+			return nil, err
+		}, nil, codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := tt.list(dialGRPC(t, context.Background(), Options{Client: tt.client}))
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("expected code %v, got %v", tt.wantCode, err)
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+// TestGRPCWatchDeployments verifies that a watch starts with the existing deployments and ends with
+// Unavailable when the server shuts down.
+func TestGRPCWatchDeployments(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	shutdown, stop := context.WithCancel(context.Background())
+	defer stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := dialGRPC(t, shutdown, Options{Client: client}).WatchDeployments(ctx,
+		&apiv1.WatchDeploymentsRequest{Namespace: "shop"})
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	var names []string
+	for range 3 {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("failed to receive an event: %v", err)
+		}
+		if event.GetType() != apiv1.DeploymentEvent_ADDED {
+			t.Errorf("unexpected event %v", event)
+		}
+		names = append(names, event.GetDeployment().GetName())
+	}
+	slices.Sort(names)
+	if want := []string{"cart", "checkout", "frontend"}; !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+
+	stop()
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the stream to end with Unavailable on shutdown, got %v", err)
+	}
+}
+
+// TestGRPCInterceptors verifies that calls are rate limited, authenticated with RequireAuth, and counted.
+func TestGRPCInterceptors(t *testing.T) {
+	auth := NewTokenAuthenticator(map[string]string{"secret": "ci"})
+	tests := []struct {
+		name      string
+		opts      Options
+		token     string
+		wantCodes []codes.Code
+	}{
+		{"open", Options{}, "", []codes.Code{codes.OK, codes.OK}},
+		{"rate limit", Options{Limits: Limits{RequestRate: 0.001, RequestBurst: 1}}, "",
+			[]codes.Code{codes.OK, codes.ResourceExhausted}},
+		{"no token", Options{RequireAuth: true, Authenticator: auth}, "", []codes.Code{codes.Unauthenticated}},
+		{"invalid token", Options{RequireAuth: true, Authenticator: auth}, "guess",
+			[]codes.Code{codes.Unauthenticated}},
+		{"valid token", Options{RequireAuth: true, Authenticator: auth}, "secret", []codes.Code{codes.OK}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			tt.opts.Client = k8s.NewFakeClient(zerolog.Nop())
+			tt.opts.Metrics = registry
+			client := dialGRPC(t, context.Background(), tt.opts)
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.token)
+			}
+
+			var got []codes.Code
+			for range tt.wantCodes {
+				_, err := client.ListPods(ctx, &apiv1.ListPodsRequest{})
+				got = append(got, status.Code(err))
+			}
+			if !slices.Equal(got, tt.wantCodes) {
+				t.Errorf("codes = %v, want %v", got, tt.wantCodes)
+			}
+
+			var buf bytes.Buffer
+			if err := registry.WritePrometheus(&buf); err != nil {
+				t.Fatal(err)
+			}
+			last := tt.wantCodes[len(tt.wantCodes)-1]
+			want := fmt.Sprintf(`kc_grpc_calls_total{code="%s",method="%s"} %d`, last,
+				apiv1.ClusterService_ListPods_FullMethodName, len(tt.wantCodes)-slices.Index(tt.wantCodes, last))
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("expected metrics to contain %q, got:\n%s", want, buf.String())
+			}
+		})
+	}
+}
+
+// TestUpstreamGRPCError verifies the status codes of Kubernetes API errors.
+func TestUpstreamGRPCError(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"not found", apierrors.NewNotFound(deployments, "web"), codes.NotFound},
+		{"denied", fmt.Errorf("%w: no changes on Fridays", authz.ErrDenied), codes.PermissionDenied},
+		{"timeout", context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"forbidden", apierrors.NewForbidden(deployments, "", errors.New("no RBAC")), codes.Unavailable},
+		{"other", errors.New("boom"), codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := upstreamGRPCError(tt.err)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("expected code %v, got %v", tt.want, got)
+			}
+			if !strings.Contains(status.Convert(err).Message(), tt.err.Error()) {
+				t.Errorf("expected the message to contain %q, got %q", tt.err, status.Convert(err).Message())
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the health detail endpoint.
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/buildinfo"
+	"github.com/Searge/k8s-controller/pkg/startup"
+)
+
+// Subsystem health states.
+const (
+	healthOK          = "ok"
+	healthPending     = "pending"
+	healthUnavailable = "unavailable"
+	healthDisabled    = "disabled"
+)
+
+// Names of the subsystems reported by /healthz/detail.
+const (
+	subsystemKubernetesAPI = "kubernetes-api"
+	subsystemReadCache     = "read-cache"
+	subsystemStartup       = "startup"
+)
+
+// healthDetail is the JSON body of /healthz/detail.
+type healthDetail struct {
+	Status     string `json:"status" doc:"\"ok\", or \"degraded\" if a subsystem is pending or unavailable"`
+	Version    string `json:"version" doc:"Version of the server, e.g. v1.2.0"`
+	StartedAt  string `json:"startedAt" doc:"Time the server started in RFC 3339 format"`
+	UptimeMs   int64  `json:"uptimeMs" doc:"Time since the server started, in milliseconds"`
+	Goroutines int    `json:"goroutines" doc:"Number of goroutines of the process"`
+
+	CacheSynced bool `json:"cacheSynced" doc:"True once lists are served from the informer-backed read cache"`
+
+	// LastAPIContact is only set once the Kubernetes API server has answered a request of the client.
+	LastAPIContact string `json:"lastAPIContact,omitempty" doc:"Time the Kubernetes API server last answered"`
+
+	Subsystems []subsystemHealth `json:"subsystems" doc:"Health of each subsystem"`
+}
+
+// subsystemHealth is the health of a subsystem of the server.
+type subsystemHealth struct {
+	Name    string `json:"name" doc:"Subsystem name, e.g. kubernetes-api"`
+	Status  string `json:"status" doc:"One of ok, pending, unavailable or disabled"`
+	Message string `json:"message,omitempty" doc:"Why the subsystem is not ok, or how it is configured"`
+}
+
+// healthDetail handles GET /healthz/detail. It always responds 200, for humans and dashboards triaging
+// the server, with its uptime, version and goroutines, the state of the read cache, the last contact
+// with the Kubernetes API server and the health of each subsystem. Probes use /livez and /readyz.
+func (h *apiHandler) healthDetail(ctx *fasthttp.RequestCtx, tracker *startup.Tracker, build buildinfo.Info) {
+	now := time.Now()
+	detail := healthDetail{
+		Status:     "ok",
+		Version:    build.Version,
+		StartedAt:  h.started.UTC().Format(time.RFC3339),
+		UptimeMs:   now.Sub(h.started).Milliseconds(),
+		Goroutines: runtime.NumGoroutine(),
+		Subsystems: []subsystemHealth{h.apiHealth(), h.readCacheHealth(tracker), startupHealth(tracker)},
+	}
+	if h.client != nil {
+		detail.CacheSynced = h.client.CacheSynced()
+		if last, ok := h.client.LastContact(); ok {
+			detail.LastAPIContact = last.UTC().Format(time.RFC3339)
+		}
+	}
+	for _, subsystem := range detail.Subsystems {
+		if subsystem.Status == healthPending || subsystem.Status == healthUnavailable {
+			detail.Status = "degraded"
+		}
+	}
+	h.writeJSON(ctx, fasthttp.StatusOK, detail)
+}
+
+// apiHealth reports whether the Kubernetes API server answers, with the cached check of /readyz.
+func (h *apiHandler) apiHealth() subsystemHealth {
+	health := subsystemHealth{Name: subsystemKubernetesAPI, Status: healthOK}
+	if h.client == nil {
+		health.Status, health.Message = healthUnavailable, "kubernetes client not configured"
+	} else if err := h.apiCheck.check(context.Background()); err != nil {
+		health.Status, health.Message = healthUnavailable, err.Error()
+	}
+	return health
+}
+
+// readCacheHealth reports the state of the read cache: ok once lists are served from it, pending while
+// it syncs, unavailable if it failed to sync, and disabled without --cache.
+func (h *apiHandler) readCacheHealth(tracker *startup.Tracker) subsystemHealth {
+	health := subsystemHealth{Name: subsystemReadCache, Status: healthDisabled}
+	if h.client != nil && h.client.CacheSynced() {
+		health.Status = healthOK
+		return health
+	}
+	if tracker == nil {
+		return health
+	}
+	for _, stage := range tracker.Status().Stages {
+		if stage.Name != startup.StageCachesSyncing {
+			continue
+		}
+		switch stage.State {
+		case startup.StatePending:
+			health.Status, health.Message = healthPending, "waiting for the earlier startup stages"
+		case startup.StateInProgress:
+			health.Status, health.Message = healthPending, "informer caches syncing"
+			if stage.Progress != "" {
+				health.Message += " (" + stage.Progress + ")"
+			}
+		case startup.StateSkipped:
+			// The cache is skipped when it failed to sync; the lists then go to the API server.
+			health.Status, health.Message = healthUnavailable, stage.Message
+		}
+	}
+	return health
+}
+
+// startupHealth reports whether every startup stage is done or skipped, or else the current stage.
+func startupHealth(tracker *startup.Tracker) subsystemHealth {
+	health := subsystemHealth{Name: subsystemStartup, Status: healthOK}
+	if tracker == nil {
+		return health
+	}
+	if status := tracker.Status(); !status.Started {
+		health.Status, health.Message = healthPending, fmt.Sprintf("stage %s not finished", status.Current)
+	}
+	return health
+}
@@ -0,0 +1,106 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the health detail endpoint.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/buildinfo"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/startup"
+)
+
+// TestHealthDetail tests GET /healthz/detail with missing, syncing, failed and healthy subsystems.
+func TestHealthDetail(t *testing.T) {
+	syncing := startup.NewTracker(startup.DefaultStages...)
+	syncing.Begin(startup.StageCachesSyncing)
+	syncing.Progress(startup.StageCachesSyncing, 0, 1)
+
+	failed := startup.NewTracker(startup.StageCachesSyncing)
+	failed.Skip(startup.StageCachesSyncing, "read cache unavailable: forbidden")
+
+	started := startup.NewTracker(startup.StageCachesSyncing)
+	started.Complete(startup.StageCachesSyncing)
+
+	cached := k8s.NewFakeClient(zerolog.Nop())
+	cacheCtx, stopCache := context.WithCancel(context.Background())
+	defer stopCache()
+	if err := cached.StartCache(cacheCtx, 0); err != nil {
+		t.Fatalf("StartCache() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		client          *k8s.Client
+		tracker         *startup.Tracker
+		wantStatus      string
+		wantCacheSynced bool
+		wantSubsystems  []subsystemHealth
+	}{
+		{"no client", nil, nil, "degraded", false, []subsystemHealth{
+			{subsystemKubernetesAPI, healthUnavailable, "kubernetes client not configured"},
+			{subsystemReadCache, healthDisabled, ""},
+			{subsystemStartup, healthOK, ""},
+		}},
+		{"caches syncing", k8s.NewFakeClient(zerolog.Nop()), syncing, "degraded", false, []subsystemHealth{
+			{subsystemKubernetesAPI, healthOK, ""},
+			{subsystemReadCache, healthPending, "informer caches syncing (0/1)"},
+			{subsystemStartup, healthPending, "stage config-loaded not finished"},
+		}},
+		{"cache failed", k8s.NewFakeClient(zerolog.Nop()), failed, "degraded", false, []subsystemHealth{
+			{subsystemKubernetesAPI, healthOK, ""},
+			{subsystemReadCache, healthUnavailable, "read cache unavailable: forbidden"},
+			{subsystemStartup, healthOK, ""},
+		}},
+		{"without cache", k8s.NewFakeClient(zerolog.Nop()), started, "ok", false, []subsystemHealth{
+			{subsystemKubernetesAPI, healthOK, ""},
+			{subsystemReadCache, healthDisabled, ""},
+			{subsystemStartup, healthOK, ""},
+		}},
+		{"cache synced", cached, started, "ok", true, []subsystemHealth{
+			{subsystemKubernetesAPI, healthOK, ""},
+			{subsystemReadCache, healthOK, ""},
+			{subsystemStartup, healthOK, ""},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Client: tt.client, Startup: tt.tracker,
+				Build: buildinfo.Info{Version: "v1.2.3"}})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/healthz/detail")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != fasthttp.StatusOK {
+				t.Errorf("expected status 200, got %d", ctx.Response.StatusCode())
+			}
+			var body healthDetail
+			if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.Status != tt.wantStatus || body.CacheSynced != tt.wantCacheSynced {
+				t.Errorf("expected status %q and cache synced %v, got %+v", tt.wantStatus, tt.wantCacheSynced, body)
+			}
+			if !reflect.DeepEqual(body.Subsystems, tt.wantSubsystems) {
+				t.Errorf("expected subsystems %+v, got %+v", tt.wantSubsystems, body.Subsystems)
+			}
+			if _, err := time.Parse(time.RFC3339, body.StartedAt); err != nil || body.UptimeMs < 0 ||
+				body.Version != "v1.2.3" || body.Goroutines == 0 {
+				t.Errorf("unexpected process details %+v", body)
+			}
+			// The fake clients have no API server to contact.
+			if body.LastAPIContact != "" {
+				t.Errorf("expected no API contact, got %q", body.LastAPIContact)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the webhook receiver, which runs the pipelines of configured hooks.
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/hooks"
+)
+
+// Path prefix and route pattern of the webhook receiver.
+const (
+	hooksPathPrefix = "/hooks/"
+	hookPattern     = hooksPathPrefix + "{name}"
+)
+
+// statusIgnored reports that a delivery was acknowledged without running the pipeline of the hook.
+const statusIgnored = "ignored"
+
+// hookResponse is the JSON body of an accepted webhook delivery.
+type hookResponse struct {
+	Hook     string `json:"hook" doc:"Name of the hook"`
+	Pipeline string `json:"pipeline" doc:"Name of the pipeline the hook runs"`
+	Status   string `json:"status" doc:"started, queued to run once more after the current run, or ignored"`
+}
+
+// receiveHook handles POST /hooks/{name}: a delivery signed with the secret of the hook runs its pipeline
+// in the background, and is answered 202 Accepted right away, since senders such as GitHub time out within
+// seconds. Deliveries are authenticated by their signature rather than a bearer token, so the endpoint is
+// open with RequireAuth. Replayed deliveries are rejected with 409 Conflict, see hooks.Hook.Verify.
+// Deliveries of events the hook does not listen to are acknowledged and ignored.
+func (h *apiHandler) receiveHook(dispatcher *hooks.Dispatcher) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		name := pathParamValue(ctx, "name")
+		var hook *hooks.Hook
+		if dispatcher != nil {
+			hook, _ = dispatcher.Hook(name)
+		}
+		if hook == nil {
+			h.writeError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("unknown hook %q", name))
+			return
+		}
+
+		signature := string(ctx.Request.Header.Peek(hook.SignatureHeader))
+		var timestamp string
+		if hook.TimestampHeader != "" {
+			timestamp = string(ctx.Request.Header.Peek(hook.TimestampHeader))
+		}
+		if err := hook.Verify(signature, timestamp, ctx.PostBody()); err != nil {
+			h.logger.Warn().Err(err).Str("hook", name).Msg("Rejected webhook delivery")
+			status := fasthttp.StatusUnauthorized
+			switch {
+			case errors.Is(err, hooks.ErrInvalidSignature):
+				status = fasthttp.StatusForbidden
+			case errors.Is(err, hooks.ErrReplayedDelivery):
+				status = fasthttp.StatusConflict
+			}
+			h.writeError(ctx, status, err.Error())
+			return
+		}
+
+		response := hookResponse{Hook: name, Pipeline: hook.PipelineName(), Status: statusIgnored}
+		event := string(ctx.Request.Header.Peek(hook.EventHeader))
+		if hook.Accepts(event) {
+			response.Status = dispatcher.Trigger(h.shutdown, name)
+		}
+		h.logger.Info().Str("hook", name).Str("event", event).Str("status", response.Status).
+			Str("request_id", requestID(ctx)).Msg("Received webhook delivery")
+		h.writeJSON(ctx, fasthttp.StatusAccepted, response)
+	}
+}
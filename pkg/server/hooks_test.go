@@ -0,0 +1,118 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the webhook receiver.
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/hooks"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/pipeline"
+)
+
+// TestReceiveHook tests webhook deliveries: unknown hooks, unsigned, wrongly signed, ignored, accepted and
+// replayed deliveries. The accepted delivery restarts a deployment of the demo cluster in the background.
+func TestReceiveHook(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"restart-cart.yaml": "namespace: shop\nsteps:\n  - {name: restart, restart: {deployment: cart}}\n",
+		"cart.secret":       "s3cret\n",
+		"hooks.yaml": "hooks:\n" +
+			"  - {name: cart, secretFile: cart.secret, events: [push], pipeline: restart-cart.yaml}\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loaded, err := hooks.Load(filepath.Join(dir, "hooks.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	dispatcher := hooks.NewDispatcher(loaded, pipeline.NewRunner(client, pipeline.Options{}, zerolog.Nop()),
+		zerolog.Nop())
+	auth := NewTokenAuthenticator(map[string]string{"secret": "ci"})
+	handler := createHandler(zerolog.Nop(), Options{Client: client, Hooks: dispatcher, RequireAuth: true,
+		Authenticator: auth})
+
+	body, ping := `{"ref":"refs/heads/main"}`, `{"zen":"Keep it logically awesome."}`
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	signature := sign(body)
+
+	tests := []struct {
+		name       string
+		handler    fasthttp.RequestHandler
+		path       string
+		body       string
+		signature  string
+		event      string
+		wantStatus int
+		wantHook   string
+	}{
+		{"no hooks", createHandler(zerolog.Nop(), Options{}), "/hooks/cart", body, signature, "push",
+			fasthttp.StatusNotFound, ""},
+		{"unknown hook", handler, "/hooks/web", body, signature, "push", fasthttp.StatusNotFound, ""},
+		{"unsigned", handler, "/hooks/cart", body, "", "push", fasthttp.StatusUnauthorized, ""},
+		{"wrongly signed", handler, "/hooks/cart", body, "sha256=00", "push", fasthttp.StatusForbidden, ""},
+		{"ignored event", handler, "/hooks/cart", ping, sign(ping), "ping", fasthttp.StatusAccepted, statusIgnored},
+		{"accepted", handler, "/hooks/cart", body, signature, "push", fasthttp.StatusAccepted, hooks.StatusStarted},
+		{"replayed", handler, "/hooks/cart", body, signature, "push", fasthttp.StatusConflict, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+			ctx.Request.SetRequestURI(tt.path)
+			ctx.Request.Header.Set(hooks.DefaultEventHeader, tt.event)
+			if tt.signature != "" {
+				ctx.Request.Header.Set(hooks.DefaultSignatureHeader, tt.signature)
+			}
+			ctx.Request.SetBodyString(tt.body)
+			tt.handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, ctx.Response.StatusCode(),
+					ctx.Response.Body())
+			}
+			if tt.wantHook == "" {
+				return
+			}
+			var got hookResponse
+			if err := json.Unmarshal(ctx.Response.Body(), &got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if want := (hookResponse{Hook: "cart", Pipeline: "restart-cart", Status: tt.wantHook}); got != want {
+				t.Errorf("expected response %+v, got %+v", want, got)
+			}
+		})
+	}
+
+	dispatcher.Wait()
+	deployment, err := client.GetClientset().AppsV1().Deployments("shop").Get(context.Background(), "cart",
+		metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if _, ok := deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"]; !ok {
+		t.Error("expected the accepted delivery to restart deployment shop/cart")
+	}
+}
@@ -0,0 +1,292 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the authentication of callers by JSON Web Tokens.
+package server
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/valyala/fasthttp"
+)
+
+// Timing of JWT validation.
+const (
+	// jwtLeeway is the clock skew tolerated when checking the validity period of a token.
+	jwtLeeway = time.Minute
+
+	// jwksFetchTimeout bounds fetching the OpenID configuration and the key set of the issuer.
+	jwksFetchTimeout = 10 * time.Second
+
+	// jwksMaxAge is how long a fetched key set is used before it is fetched again, so that keys
+	// removed by the issuer stop being accepted.
+	jwksMaxAge = time.Hour
+
+	// jwksMinRefresh is the shortest interval between fetches triggered by tokens signed with unknown
+	// keys, so that forged key IDs cannot make the server hammer the issuer.
+	jwksMinRefresh = time.Minute
+)
+
+// Limits of JWT validation.
+const (
+	// MinJWTSecretLength is the shortest shared secret accepted for HMAC-signed tokens, in bytes.
+	MinJWTSecretLength = 32
+
+	// minRSAKeyBits is the smallest RSA key accepted from a key set.
+	minRSAKeyBits = 2048
+
+	// maxJWKSSize bounds the documents fetched from the issuer.
+	maxJWKSSize = 1 << 20
+)
+
+// secretAlgorithms are the JWS algorithms accepted for tokens signed with a shared secret.
+var secretAlgorithms = []jose.SignatureAlgorithm{jose.HS256, jose.HS384, jose.HS512}
+
+// issuerAlgorithms are the JWS algorithms accepted for tokens signed with the keys of an issuer.
+var issuerAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+}
+
+// JWTOptions configures a JWTAuthenticator. Tokens are either signed with a shared Secret (HS256,
+// HS384 or HS512), or with the keys of the issuer (RS*, PS* or ES*), taken from JWKSURL or, if
+// it is empty, from the jwks_uri of the OpenID configuration of Issuer.
+type JWTOptions struct {
+	// Issuer is the required iss claim of tokens. If empty, the issuer is not checked.
+	Issuer string
+
+	// Audience is an entry required in the aud claim of tokens. If empty, the audience is not checked.
+	Audience string
+
+	// JWKSURL is the URL of the JSON Web Key Set of the issuer.
+	JWKSURL string
+
+	// Secret is the key of HMAC-signed tokens, at least MinJWTSecretLength bytes long.
+	Secret []byte
+
+	// HTTPClient fetches the OpenID configuration and the key set. If nil, a client with a
+	// timeout of jwksFetchTimeout is used.
+	HTTPClient *http.Client
+}
+
+// JWTAuthenticator authenticates callers by bearer JSON Web Tokens, naming them after the sub claim.
+// Tokens must be signed by the configured secret or issuer keys and carry an exp claim; iss, aud and
+// nbf are checked if configured or present.
+type JWTAuthenticator struct {
+	opts   JWTOptions
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	jwksURL   string
+	keys      []jose.JSONWebKey
+	fetchedAt time.Time
+}
+
+// NewJWTAuthenticator creates a JWTAuthenticator. Exactly one of a secret and an issuer key set,
+// given by JWKSURL or Issuer, must be configured. Key sets are fetched with the first token.
+func NewJWTAuthenticator(opts JWTOptions) (*JWTAuthenticator, error) {
+	switch {
+	case opts.Secret != nil && opts.JWKSURL != "":
+		return nil, errors.New("a JWT secret and a JWKS URL are mutually exclusive")
+	case opts.Secret != nil && len(opts.Secret) < MinJWTSecretLength:
+		return nil, fmt.Errorf("the JWT secret must be at least %d bytes long", MinJWTSecretLength)
+	case opts.Secret == nil && opts.JWKSURL == "" && opts.Issuer == "":
+		return nil, errors.New("JWT validation requires a secret, a JWKS URL or an issuer")
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: jwksFetchTimeout}
+	}
+	return &JWTAuthenticator{opts: opts, client: client, now: time.Now, jwksURL: opts.JWKSURL}, nil
+}
+
+// Authenticate returns the subject of the request's bearer token if the token is valid.
+func (a *JWTAuthenticator) Authenticate(ctx *fasthttp.RequestCtx) (string, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return a.verify(token)
+}
+
+// verify checks the signature and claims of a token and returns its subject.
+func (a *JWTAuthenticator) verify(token string) (string, error) {
+	if strings.Count(token, ".") != 2 {
+		// Not a JWT, e.g. a static token meant for another authenticator.
+		return "", errInvalidToken
+	}
+	algorithms := issuerAlgorithms
+	if a.opts.Secret != nil {
+		algorithms = secretAlgorithms
+	}
+	// Restricting the algorithms to the kind of key configured keeps e.g. an issuer's public RSA key
+	// from being used as an HMAC secret.
+	parsed, err := jwt.ParseSigned(token, algorithms)
+	var unexpected *jose.ErrUnexpectedSignatureAlgorithm
+	if errors.As(err, &unexpected) {
+		return "", fmt.Errorf("unexpected JWT algorithm %q", unexpected.Got)
+	} else if err != nil {
+		return "", fmt.Errorf("malformed JWT: %w", err)
+	}
+
+	claims, err := a.verifiedClaims(parsed)
+	if err != nil {
+		return "", err
+	}
+	if err := a.checkClaims(claims); err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
+}
+
+// verifiedClaims returns the claims of a token after checking its signature with the secret or the
+// matching keys of the issuer.
+func (a *JWTAuthenticator) verifiedClaims(token *jwt.JSONWebToken) (jwt.Claims, error) {
+	var claims jwt.Claims
+	if a.opts.Secret != nil {
+		if err := token.Claims(a.opts.Secret, &claims); err != nil {
+			return claims, fmt.Errorf("invalid JWT signature: %w", err)
+		}
+		return claims, nil
+	}
+
+	keys, err := a.signingKeys(token.Headers[0].KeyID)
+	if err != nil {
+		return claims, err
+	}
+	for _, key := range keys {
+		if err = token.Claims(key, &claims); err == nil {
+			return claims, nil
+		}
+	}
+	return claims, fmt.Errorf("invalid JWT signature: %w", err)
+}
+
+// checkClaims checks the validity period, issuer, audience and subject of a verified token.
+func (a *JWTAuthenticator) checkClaims(claims jwt.Claims) error {
+	now := a.now()
+	switch {
+	case claims.Expiry == nil:
+		return errors.New("JWT has no expiry")
+	case now.Add(-jwtLeeway).After(claims.Expiry.Time()):
+		return errors.New("JWT expired")
+	case claims.NotBefore != nil && now.Add(jwtLeeway).Before(claims.NotBefore.Time()):
+		return errors.New("JWT not valid yet")
+	case a.opts.Issuer != "" && claims.Issuer != a.opts.Issuer:
+		return fmt.Errorf("unexpected JWT issuer %q", claims.Issuer)
+	case a.opts.Audience != "" && !claims.Audience.Contains(a.opts.Audience):
+		return fmt.Errorf("JWT not issued for audience %q", a.opts.Audience)
+	case claims.Subject == "":
+		return errors.New("JWT has no subject")
+	}
+	return nil
+}
+
+// signingKeys returns the keys of the issuer with the given ID, or all keys if the ID is empty.
+// The key set is fetched if it is older than jwksMaxAge, or if no key has the ID and the last
+// fetch is older than jwksMinRefresh, which picks up rotated keys. If fetching fails, the keys
+// fetched before keep being used.
+func (a *JWTAuthenticator) signingKeys(id string) ([]jose.JSONWebKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	age := a.now().Sub(a.fetchedAt)
+	keys := a.matchingKeys(id)
+	if a.fetchedAt.IsZero() || age >= jwksMaxAge || (len(keys) == 0 && age >= jwksMinRefresh) {
+		err := a.fetchKeys()
+		if err != nil && a.fetchedAt.IsZero() {
+			return nil, err
+		}
+		keys = a.matchingKeys(id)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no JWT signing key with ID %q", id)
+	}
+	return keys, nil
+}
+
+// matchingKeys returns the cached keys with the given ID, or all of them if the ID is empty.
+func (a *JWTAuthenticator) matchingKeys(id string) []jose.JSONWebKey {
+	var keys []jose.JSONWebKey
+	for _, key := range a.keys {
+		if id == "" || key.KeyID == id {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// fetchKeys fetches the key set of the issuer, discovering its URL from the OpenID configuration of
+// the issuer first if none is configured. Keys that are not public signing keys, and RSA keys smaller
+// than minRSAKeyBits, are skipped.
+func (a *JWTAuthenticator) fetchKeys() error {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	if a.jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		url := strings.TrimSuffix(a.opts.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := a.getJSON(ctx, url, &discovery); err != nil {
+			return fmt.Errorf("failed to discover the JWKS URL: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("no jwks_uri in the OpenID configuration of %s", a.opts.Issuer)
+		}
+		a.jwksURL = discovery.JWKSURI
+	}
+
+	// Keys are decoded one by one, so that a key the library cannot decode does not reject the set.
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := a.getJSON(ctx, a.jwksURL, &set); err != nil {
+		return fmt.Errorf("failed to fetch the JWKS: %w", err)
+	}
+	var keys []jose.JSONWebKey
+	for _, raw := range set.Keys {
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(raw); err != nil || !key.IsPublic() || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		if rsaKey, isRSA := key.Key.(*rsa.PublicKey); isRSA && rsaKey.N.BitLen() < minRSAKeyBits {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no supported signing keys in the JWKS at %s", a.jwksURL)
+	}
+	a.keys, a.fetchedAt = keys, a.now()
+	return nil
+}
+
+// getJSON fetches a JSON document of at most maxJWKSSize bytes.
+func (a *JWTAuthenticator) getJSON(ctx context.Context, url string, value any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(value)
+}
@@ -0,0 +1,240 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the authentication of callers by JSON Web Tokens.
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/valyala/fasthttp"
+)
+
+// testJWTSecret is the shared secret of HMAC-signed test tokens.
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// signTestJWT signs claims with the given algorithm and key: a secret for HS256, an *rsa.PrivateKey
+// for RS256 and PS256, or an *ecdsa.PrivateKey for ES256. Tokens with other algorithms, e.g. none,
+// are encoded with an invalid signature.
+func signTestJWT(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+	signingKey := jose.SigningKey{Algorithm: jose.SignatureAlgorithm(alg), Key: key}
+	if kid != "" {
+		signingKey.Key = jose.JSONWebKey{Key: key, KeyID: kid}
+	}
+	signer, err := jose.NewSigner(signingKey, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		encode := func(value any) string {
+			data, _ := json.Marshal(value)
+			return base64.RawURLEncoding.EncodeToString(data)
+		}
+		return encode(map[string]string{"alg": alg, "kid": kid}) + "." + encode(claims) + ".dW5zaWduZWQ"
+	}
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// testIssuer serves an OpenID configuration and a key set with an RSA and an EC key, counting key set fetches.
+func testIssuer(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	b64 := base64.RawURLEncoding.EncodeToString
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		point, err := ecKey.PublicKey.Bytes()
+		if err != nil {
+			t.Error(err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rsaKey.N.Bytes()),
+				"e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(point[1:33]), "y": b64(point[33:])},
+			{"kty": "oct", "kid": "secret", "k": b64(testJWTSecret)},
+		}})
+	})
+	return server, &fetches
+}
+
+// TestJWTAuthenticator verifies signatures and claims of tokens signed with issuer keys and shared secrets.
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, _ := testIssuer(t, rsaKey, ecKey)
+
+	now := time.Now()
+	claims := func(changes map[string]any) map[string]any {
+		c := map[string]any{"iss": issuer.URL, "sub": "dashboard", "aud": []string{"k8s-controller", "other"},
+			"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(-time.Minute).Unix()}
+		for name, value := range changes {
+			if value == nil {
+				delete(c, name)
+			} else {
+				c[name] = value
+			}
+		}
+		return c
+	}
+
+	keyed, err := NewJWTAuthenticator(JWTOptions{Issuer: issuer.URL, Audience: "k8s-controller"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := NewJWTAuthenticator(JWTOptions{Secret: testJWTSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		auth    *JWTAuthenticator
+		token   string
+		wantErr string
+	}{
+		{"RS256", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(nil)), ""},
+		{"PS256", keyed, signTestJWT(t, "PS256", "rsa", rsaKey, claims(nil)), ""},
+		{"ES256", keyed, signTestJWT(t, "ES256", "ec", ecKey, claims(nil)), ""},
+		{"no key ID", keyed, signTestJWT(t, "ES256", "", ecKey, claims(nil)), ""},
+		{"single audience", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{
+			"aud": "k8s-controller"})), ""},
+		{"HS256", shared, signTestJWT(t, "HS256", "", testJWTSecret, claims(map[string]any{"aud": nil})), ""},
+		{"other key", keyed, signTestJWT(t, "RS256", "rsa", otherKey, claims(nil)), "invalid JWT signature"},
+		{"unknown key ID", keyed, signTestJWT(t, "RS256", "rotated", rsaKey, claims(nil)), "no JWT signing key"},
+		{"key of other type", keyed, signTestJWT(t, "RS256", "ec", rsaKey, claims(nil)), "invalid JWT signature"},
+		{"HMAC with issuer keys", keyed, signTestJWT(t, "HS256", "secret", testJWTSecret, claims(nil)),
+			"unexpected JWT algorithm"},
+		{"RSA with shared secret", shared, signTestJWT(t, "RS256", "", rsaKey, claims(nil)),
+			"unexpected JWT algorithm"},
+		{"none algorithm", shared, signTestJWT(t, "none", "", nil, claims(nil)), "unexpected JWT algorithm"},
+		{"wrong secret", shared, signTestJWT(t, "HS256", "", []byte(strings.Repeat("x", 32)), claims(nil)),
+			"invalid JWT signature"},
+		{"expired", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{
+			"exp": now.Add(-time.Hour).Unix()})), "JWT expired"},
+		{"no expiry", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"exp": nil})), "no expiry"},
+		{"not valid yet", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{
+			"nbf": now.Add(time.Hour).Unix()})), "not valid yet"},
+		{"other issuer", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{
+			"iss": "https://evil.example.com"})), "unexpected JWT issuer"},
+		{"other audience", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{
+			"aud": "other"})), "not issued for audience"},
+		{"no subject", keyed, signTestJWT(t, "RS256", "rsa", rsaKey, claims(map[string]any{"sub": nil})), "no subject"},
+		{"not a JWT", keyed, "static-token", "invalid bearer token"},
+		{"malformed header", keyed, "e30.e30.!!", "malformed JWT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+tt.token)
+
+			caller, err := tt.auth.Authenticate(ctx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Authenticate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || caller != "dashboard" {
+				t.Errorf("Authenticate() = %q, %v, want dashboard", caller, err)
+			}
+		})
+	}
+}
+
+// TestJWTAuthenticatorKeyRefresh verifies that key sets are cached, and fetched again for unknown
+// key IDs at most once per jwksMinRefresh and after jwksMaxAge.
+func TestJWTAuthenticatorKeyRefresh(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, fetches := testIssuer(t, rsaKey, ecKey)
+	auth, err := NewJWTAuthenticator(JWTOptions{JWKSURL: issuer.URL + "/keys"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+	claims := map[string]any{"sub": "dashboard", "exp": now.Add(2 * time.Hour).Unix()}
+
+	steps := []struct {
+		advance     time.Duration
+		kid         string
+		wantFetches int32
+	}{
+		{0, "rsa", 1},
+		{time.Second, "rsa", 1},
+		{time.Second, "rotated", 1},
+		{jwksMinRefresh, "rotated", 2},
+		{time.Second, "rotated", 2},
+		{jwksMinRefresh, "rotated", 3},
+		{jwksMaxAge, "rsa", 4},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		_, _ = auth.verify(signTestJWT(t, "RS256", step.kid, rsaKey, claims))
+		if got := fetches.Load(); got != step.wantFetches {
+			t.Errorf("step %d: expected %d key set fetches, got %d", i, step.wantFetches, got)
+		}
+	}
+}
+
+// TestNewJWTAuthenticator verifies the validation of JWT options.
+func TestNewJWTAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    JWTOptions
+		wantErr string
+	}{
+		{"secret", JWTOptions{Secret: testJWTSecret}, ""},
+		{"issuer", JWTOptions{Issuer: "https://issuer.example.com"}, ""},
+		{"JWKS URL", JWTOptions{JWKSURL: "https://issuer.example.com/keys"}, ""},
+		{"short secret", JWTOptions{Secret: []byte("short")}, "at least 32 bytes"},
+		{"secret and JWKS URL", JWTOptions{Secret: testJWTSecret, JWKSURL: "https://issuer.example.com/keys"},
+			"mutually exclusive"},
+		{"nothing", JWTOptions{Audience: "k8s-controller"}, "requires a secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTAuthenticator(tt.opts)
+			if (tt.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("NewJWTAuthenticator() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the timeouts and resource limits of the HTTP server.
+package server
+
+import (
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Default server limits, sized for production use behind a load balancer.
+const (
+	DefaultReadTimeout  = 10 * time.Second
+	DefaultWriteTimeout = 30 * time.Second
+	DefaultIdleTimeout  = 90 * time.Second
+
+	// DefaultMaxRequestBodySize is the request size limit of the Kubernetes API server, which no
+	// manifest sent to the write API can exceed anyway.
+	DefaultMaxRequestBodySize = 3 << 20
+
+	DefaultConcurrency = 10000
+)
+
+// watchStreamTimeout bounds a deployment watch stream, like the API server bounds its watches. Clients
+// resume the stream with Last-Event-ID, which also rebalances long-lived streams across replicas.
+const watchStreamTimeout = time.Hour
+
+// Limits tunes the timeouts and resource limits of the HTTP server. Zero values use defaults.
+type Limits struct {
+	// ReadTimeout bounds reading a request, including its body. Slower requests are answered with
+	// 408 Request Timeout.
+	ReadTimeout time.Duration
+
+	// WriteTimeout bounds writing a response. The deployment watch streams of /api/v1 and the gateway,
+	// report exports and the service proxy are bounded by watchStreamTimeout, exportTimeout and
+	// serviceProxyTimeout instead.
+	WriteTimeout time.Duration
+
+	// IdleTimeout bounds how long a keep-alive connection waits for its next request.
+	IdleTimeout time.Duration
+
+	// MaxRequestBodySize is the largest request body accepted, in bytes. Larger requests are answered
+	// with 413 Request Entity Too Large.
+	MaxRequestBodySize int
+
+	// MaxConnsPerIP limits the concurrent connections of each client IP, answering 429 Too Many Requests
+	// beyond. Zero allows any number, since behind a load balancer or ingress all clients share few IPs.
+	// Only the fasthttp backend supports it.
+	MaxConnsPerIP int
+
+	// Concurrency limits the concurrently served connections. The fasthttp backend answers 503 Service
+	// Unavailable beyond, the net/http backend accepts further connections once others closed. Each open
+	// watch stream holds a connection.
+	Concurrency int
+
+	// RequestRate limits the API requests of all clients together, per second, answering 429 Too Many
+	// Requests beyond. Zero allows any rate. Probes and scrapes are not limited.
+	RequestRate float64
+
+	// RequestBurst is the number of API requests allowed at once beyond RequestRate. Zero allows a
+	// second's worth of requests.
+	RequestBurst int
+}
+
+// withDefaults fills unset limits with defaults.
+func (l Limits) withDefaults() Limits {
+	if l.ReadTimeout <= 0 {
+		l.ReadTimeout = DefaultReadTimeout
+	}
+	if l.WriteTimeout <= 0 {
+		l.WriteTimeout = DefaultWriteTimeout
+	}
+	if l.IdleTimeout <= 0 {
+		l.IdleTimeout = DefaultIdleTimeout
+	}
+	if l.MaxRequestBodySize <= 0 {
+		l.MaxRequestBodySize = DefaultMaxRequestBodySize
+	}
+	if l.MaxConnsPerIP < 0 {
+		l.MaxConnsPerIP = 0
+	}
+	if l.Concurrency <= 0 {
+		l.Concurrency = DefaultConcurrency
+	}
+	if l.RequestRate <= 0 {
+		l.RequestRate, l.RequestBurst = 0, 0
+	} else if l.RequestBurst <= 0 {
+		l.RequestBurst = int(math.Ceil(min(l.RequestRate, math.MaxInt32)))
+	}
+	return l
+}
+
+// requestError answers requests the server failed to read. Unlike the default of fasthttp, which answers
+// 400 Bad Request, bodies beyond the limit are answered with 413 Request Entity Too Large.
+func requestError(ctx *fasthttp.RequestCtx, err error) {
+	var smallBuffer *fasthttp.ErrSmallBuffer
+	var netErr net.Error
+	switch {
+	case errors.Is(err, fasthttp.ErrBodyTooLarge):
+		ctx.Error("Request body too large", fasthttp.StatusRequestEntityTooLarge)
+	case errors.As(err, &smallBuffer):
+		ctx.Error("Too big request header", fasthttp.StatusRequestHeaderFieldsTooLarge)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		ctx.Error("Request timeout", fasthttp.StatusRequestTimeout)
+	default:
+		ctx.Error("Error when parsing request", fasthttp.StatusBadRequest)
+	}
+}
+
+// streamRequestConfig replaces the write timeout of the streaming endpoints, whose responses are written
+// for much longer than others.
+func streamRequestConfig(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+	path, _, _ := strings.Cut(string(header.RequestURI()), "?")
+	if path == "/api/v1/watch/deployments" || path == gatewayWatchPath {
+		return fasthttp.RequestConfig{WriteTimeout: watchStreamTimeout}
+	}
+	if _, ok := parseExportPath(path); ok {
+		return fasthttp.RequestConfig{WriteTimeout: exportTimeout}
+	}
+	if strings.HasPrefix(path, serviceProxyPathPrefix) {
+		return fasthttp.RequestConfig{WriteTimeout: serviceProxyTimeout}
+	}
+	return fasthttp.RequestConfig{}
+}
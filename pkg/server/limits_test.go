@@ -0,0 +1,211 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the timeouts and resource limits of the server.
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TestLimitsWithDefaults verifies that unset and invalid limits are replaced by defaults.
+func TestLimitsWithDefaults(t *testing.T) {
+	defaults := Limits{
+		ReadTimeout:        DefaultReadTimeout,
+		WriteTimeout:       DefaultWriteTimeout,
+		IdleTimeout:        DefaultIdleTimeout,
+		MaxRequestBodySize: DefaultMaxRequestBodySize,
+		Concurrency:        DefaultConcurrency,
+	}
+	custom := Limits{
+		ReadTimeout:        time.Second,
+		WriteTimeout:       2 * time.Second,
+		IdleTimeout:        3 * time.Second,
+		MaxRequestBodySize: 1024,
+		MaxConnsPerIP:      5,
+		Concurrency:        100,
+		RequestRate:        2.5,
+		RequestBurst:       10,
+	}
+
+	tests := []struct {
+		name   string
+		limits Limits
+		want   Limits
+	}{
+		{"zero", Limits{}, defaults},
+		{"negative", Limits{ReadTimeout: -1, MaxRequestBodySize: -1, MaxConnsPerIP: -1, Concurrency: -1,
+			RequestRate: -1, RequestBurst: 5}, defaults},
+		{"default burst", Limits{RequestRate: 2.5}, Limits{RequestRate: 2.5, RequestBurst: 3}.withDefaults()},
+		{"custom", custom, custom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.withDefaults(); got != tt.want {
+				t.Errorf("withDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStreamRequestConfig verifies that only the streaming endpoints get their own write timeout.
+func TestStreamRequestConfig(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want time.Duration
+	}{
+		{"/api/v1/watch/deployments", watchStreamTimeout},
+		{"/api/v1/watch/deployments?namespace=shop", watchStreamTimeout},
+		{"/api/v2/watch/deployments?namespace=shop", watchStreamTimeout},
+		{"/api/v1/reports/deployments/export?format=csv", exportTimeout},
+		{"/proxy/namespaces/monitoring/services/prometheus:9090/api/v1/query?query=up", serviceProxyTimeout},
+		{"/api/v1/deployments", 0},
+		{"/api/v2/deployments", 0},
+		{"/livez", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			var header fasthttp.RequestHeader
+			header.SetRequestURI(tt.uri)
+			if got := streamRequestConfig(&header).WriteTimeout; got != tt.want {
+				t.Errorf("WriteTimeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRequestError verifies the status codes of requests the server failed to read.
+func TestRequestError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"body too large", fasthttp.ErrBodyTooLarge, fasthttp.StatusRequestEntityTooLarge},
+		{"header too large", &fasthttp.ErrSmallBuffer{}, fasthttp.StatusRequestHeaderFieldsTooLarge},
+		{"timeout", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, fasthttp.StatusRequestTimeout},
+		{"malformed", errors.New("cannot find http request method"), fasthttp.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			requestError(ctx, tt.err)
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, ctx.Response.StatusCode())
+			}
+		})
+	}
+}
+
+// TestLimitsMaxRequestBodySize verifies that the server rejects request bodies beyond the limit.
+func TestLimitsMaxRequestBodySize(t *testing.T) {
+	tests := []struct {
+		name       string
+		bodySize   int
+		wantStatus int
+	}{
+		{"within limit", 1024, fasthttp.StatusOK},
+		{"beyond limit", 4096, fasthttp.StatusRequestEntityTooLarge},
+	}
+
+	for _, name := range Backends {
+		srv, err := newBackend(name, func(ctx *fasthttp.RequestCtx) {
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		}, Limits{MaxRequestBodySize: 2048}.withDefaults())
+		if err != nil {
+			t.Fatalf("newBackend(%q) error = %v", name, err)
+		}
+		ln := listenLoopback(t)
+		go func() { _ = srv.Serve(ln) }()
+		defer func() { _ = ln.Close() }()
+		client := &fasthttp.Client{Dial: func(string) (net.Conn, error) { return fasthttp.Dial(ln.Addr().String()) }}
+
+		for _, tt := range tests {
+			t.Run(name+" "+tt.name, func(t *testing.T) {
+				req := fasthttp.AcquireRequest()
+				resp := fasthttp.AcquireResponse()
+				defer fasthttp.ReleaseRequest(req)
+				defer fasthttp.ReleaseResponse(resp)
+
+				req.SetRequestURI("http://localhost/api/v1/namespaces/default/deployments")
+				req.Header.SetMethod(fasthttp.MethodPost)
+				req.SetBodyString(strings.Repeat("x", tt.bodySize))
+				if err := client.Do(req, resp); err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				if resp.StatusCode() != tt.wantStatus {
+					t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode())
+				}
+			})
+		}
+	}
+}
+
+// TestLimitsWriteTimeoutStreams verifies that the watch streams are written past the write timeout, which
+// cuts off the streams of other endpoints.
+func TestLimitsWriteTimeoutStreams(t *testing.T) {
+	const lines = 6
+	tests := []struct {
+		path         string
+		wantComplete bool
+	}{
+		{"/api/v1/watch/deployments", true},
+		{gatewayWatchPath, true},
+		{"/proxy/namespaces/shop/services/web/events", true},
+		{"/api/v2/deployments", false},
+	}
+
+	for _, name := range Backends {
+		srv, err := newBackend(name, func(ctx *fasthttp.RequestCtx) {
+			ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+				for range lines {
+					time.Sleep(50 * time.Millisecond)
+					if _, err := w.WriteString("event\n"); err != nil || w.Flush() != nil {
+						return
+					}
+				}
+			})
+		}, Limits{WriteTimeout: 100 * time.Millisecond}.withDefaults())
+		if err != nil {
+			t.Fatalf("newBackend(%q) error = %v", name, err)
+		}
+		ln := listenLoopback(t)
+		go func() { _ = srv.Serve(ln) }()
+		defer func() { _ = ln.Close() }()
+
+		for _, tt := range tests {
+			t.Run(name+" "+tt.path, func(t *testing.T) {
+				conn, err := net.Dial("tcp", ln.Addr().String())
+				if err != nil {
+					t.Fatalf("Failed to connect: %v", err)
+				}
+				defer func() { _ = conn.Close() }()
+				_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+				if _, err := fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: localhost\r\n\r\n", tt.path); err != nil {
+					t.Fatalf("Failed to send request: %v", err)
+				}
+
+				var resp fasthttp.Response
+				resp.StreamBody = true
+				if err := resp.Read(bufio.NewReader(conn)); err != nil {
+					t.Fatalf("Failed to read the response: %v", err)
+				}
+				body, _ := io.ReadAll(resp.BodyStream())
+				if complete := strings.Count(string(body), "event\n") == lines; complete != tt.wantComplete {
+					t.Errorf("expected the complete stream: %v, got %q", tt.wantComplete, body)
+				}
+			})
+		}
+	}
+}
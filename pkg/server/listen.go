@@ -0,0 +1,90 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements listening on TCP addresses and Unix domain sockets.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// UnixScheme prefixes listen addresses of Unix domain sockets, e.g. "unix:///var/run/kc.sock".
+const UnixScheme = "unix://"
+
+// DefaultSocketMode lets the owner and group of the server process connect to its Unix domain socket,
+// e.g. a sidecar sharing the group.
+const DefaultSocketMode fs.FileMode = 0o660
+
+// staleSocketDialTimeout bounds the check whether a left-over socket is still served.
+const staleSocketDialTimeout = time.Second
+
+// listenAddress returns the address the server listens on: Listen if set, all interfaces on Port otherwise.
+func (opts Options) listenAddress() string {
+	if opts.Listen != "" {
+		return opts.Listen
+	}
+	return fmt.Sprintf(":%d", opts.Port)
+}
+
+// listen listens on a TCP address, on the Unix domain socket of an address with the UnixScheme, whose
+// file gets socketMode, or DefaultSocketMode if zero, or on a socket passed by systemd with the SystemdScheme.
+func listen(address string, socketMode fs.FileMode) (net.Listener, error) {
+	if name, ok := strings.CutPrefix(address, SystemdScheme); ok {
+		return systemdListener(name, listenFDsStart)
+	}
+	path, ok := strings.CutPrefix(address, UnixScheme)
+	if !ok {
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+		}
+		return ln, nil
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("missing socket path in %s", address)
+	}
+	if socketMode == 0 {
+		socketMode = DefaultSocketMode
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	// The listener removes the socket file when it is closed on shutdown.
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("failed to set the mode of socket %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes a socket left at path by a server that was killed. Other files, and sockets
+// another server still listens on, are kept and fail the listen.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check socket %s: %w", path, err)
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("failed to listen on %s: the file exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to listen on %s: another server is listening on it", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
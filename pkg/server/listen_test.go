@@ -0,0 +1,149 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests listening on TCP addresses and Unix domain sockets.
+package server
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+)
+
+// TestListen verifies listening on TCP addresses and Unix domain sockets, and the handling of files
+// left at the socket path.
+func TestListen(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, path string)
+		address  func(path string) string
+		mode     fs.FileMode
+		wantMode fs.FileMode
+		wantErr  bool
+	}{
+		{
+			name:    "tcp",
+			address: func(string) string { return "127.0.0.1:0" },
+		},
+		{
+			name:     "unix socket",
+			address:  func(path string) string { return UnixScheme + path },
+			wantMode: DefaultSocketMode,
+		},
+		{
+			name:     "unix socket with mode",
+			address:  func(path string) string { return UnixScheme + path },
+			mode:     0o600,
+			wantMode: 0o600,
+		},
+		{
+			name: "stale socket",
+			setup: func(t *testing.T, path string) {
+				ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				ln.SetUnlinkOnClose(false)
+				_ = ln.Close()
+			},
+			address:  func(path string) string { return UnixScheme + path },
+			wantMode: DefaultSocketMode,
+		},
+		{
+			name: "socket in use",
+			setup: func(t *testing.T, path string) {
+				ln, err := net.Listen("unix", path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { _ = ln.Close() })
+			},
+			address: func(path string) string { return UnixScheme + path },
+			wantErr: true,
+		},
+		{
+			name: "regular file",
+			setup: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			address: func(path string) string { return UnixScheme + path },
+			wantErr: true,
+		},
+		{
+			name:    "missing socket path",
+			address: func(string) string { return UnixScheme },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "kc.sock")
+			if tt.setup != nil {
+				tt.setup(t, path)
+			}
+
+			ln, err := listen(tt.address(path), tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer func() { _ = ln.Close() }()
+
+			if tt.wantMode != 0 {
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("socket not created: %v", err)
+				}
+				if got := info.Mode().Perm(); got != tt.wantMode {
+					t.Errorf("socket mode = %v, want %v", got, tt.wantMode)
+				}
+			}
+		})
+	}
+}
+
+// TestStartUnixSocket verifies that the server is served on a Unix domain socket, which is removed on
+// shutdown.
+func TestStartUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kc.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Start(ctx, Options{Listen: UnixScheme + path}, zerolog.Nop())
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	client := &fasthttp.Client{Dial: func(string) (net.Conn, error) { return net.Dial("unix", path) }}
+	status, body, err := client.Get(nil, "http://localhost/livez")
+	if err != nil {
+		t.Fatalf("request over the socket failed: %v", err)
+	}
+	if status != fasthttp.StatusOK || string(body) != `{"status":"ok"}`+"\n" {
+		t.Errorf("unexpected response: %d %q", status, body)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("Start() after shutdown error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("socket not removed on shutdown: %v", err)
+	}
+}
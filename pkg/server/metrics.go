@@ -0,0 +1,56 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file records request metrics and serves them to Prometheus.
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// Request metric names.
+const (
+	metricRequestsTotal    = "kc_http_requests_total"
+	metricRequestDuration  = "kc_http_request_duration_seconds"
+	metricResponseSize     = "kc_http_response_size_bytes"
+	metricRequestsInFlight = "kc_http_requests_in_flight"
+)
+
+// instrument wraps a handler to count requests by method, route and status code, and to record their
+// durations, the sizes of their responses and the requests in flight by method and route, so that SLOs
+// can be defined per endpoint. The route label of a request path is its pattern, as returned by
+// routeLabel, so arbitrary paths cannot create unbounded series. Streamed responses, e.g. watches and
+// exports, are written after the handler returns: they leave the requests in flight before their body
+// is sent, and their unknown sizes are not recorded. It returns the handler unchanged if backend is nil.
+func instrument(backend metrics.Backend, routeLabel func(path string) string,
+	next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if backend == nil {
+		return next
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		labels := metrics.Labels{"method": string(ctx.Method()), "route": routeLabel(string(ctx.Path()))}
+		backend.Gauge(metricRequestsInFlight, labels, 1)
+		next(ctx)
+		backend.Gauge(metricRequestsInFlight, labels, -1)
+
+		backend.Observe(metricRequestDuration, labels, time.Since(start).Seconds())
+		if !ctx.Response.IsBodyStream() {
+			backend.Observe(metricResponseSize, labels, float64(len(ctx.Response.Body())))
+		}
+		labels["code"] = strconv.Itoa(ctx.Response.StatusCode())
+		backend.Counter(metricRequestsTotal, labels, 1)
+	}
+}
+
+// serveMetrics handles GET /metrics in the Prometheus text exposition format.
+func serveMetrics(ctx *fasthttp.RequestCtx, exposer metrics.Exposer, logger zerolog.Logger) {
+	ctx.SetContentType("text/plain; version=0.0.4; charset=utf-8")
+	if err := exposer.WritePrometheus(ctx); err != nil {
+		logger.Error().Err(err).Msg("Failed to write metrics")
+	}
+}
@@ -0,0 +1,82 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests request metrics and the /metrics endpoint.
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// TestRouteLabel verifies that request paths map to bounded route labels.
+func TestRouteLabel(t *testing.T) {
+	routes := newAPIHandler(nil, RetryBudget{}, zerolog.Nop()).routes(Options{EnablePprof: true})
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/livez", "/livez"},
+		{"/api/v1/deployments", "/api/v1/deployments"},
+		{"/api/v1/reports/weekly/export", "/api/v1/reports/{name}/export"},
+		{"/debug/pprof/heap", "/debug/pprof/{profile...}"},
+		{"/", "/"},
+		{"/api/v1/namespaces/shop/deployments", "/api/v1/namespaces/{namespace}/deployments"},
+		{"/api/v1/namespaces/shop/deployments/cart", "/api/v1/namespaces/{namespace}/deployments/{name}"},
+		{"/random/path", "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := routes.routeLabel(tt.path); got != tt.want {
+				t.Errorf("routeLabel(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMetricsEndpoint verifies that handled requests are recorded and exposed on /metrics.
+func TestMetricsEndpoint(t *testing.T) {
+	handler := createHandler(zerolog.Nop(), Options{Metrics: metrics.NewRegistry()})
+
+	get := func(path string) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(path)
+		ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+		handler(ctx)
+		return ctx
+	}
+
+	get("/livez")
+	get("/livez")
+	body := string(get("/metrics").Response.Body())
+
+	for _, want := range []string{
+		`kc_http_requests_total{code="200",method="GET",route="/livez"} 2`,
+		`kc_http_request_duration_seconds_count{method="GET",route="/livez"} 2`,
+		`kc_http_response_size_bytes_bucket{method="GET",route="/livez",le="100"} 2`,
+		`kc_http_requests_in_flight{method="GET",route="/livez"} 0`,
+		// The scrape is in flight while the metrics are written.
+		`kc_http_requests_in_flight{method="GET",route="/metrics"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+// TestMetricsEndpointPushBackend verifies that /metrics is not served when the backend pushes.
+func TestMetricsEndpointPushBackend(t *testing.T) {
+	handler := createHandler(zerolog.Nop(), Options{Metrics: metrics.Nop{}})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/metrics")
+	handler(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fasthttp.StatusNotFound, ctx.Response.StatusCode())
+	}
+}
@@ -0,0 +1,129 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the middleware pipeline wrapping the routes of the server.
+package server
+
+import (
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/time/rate"
+)
+
+// Middleware wraps a request handler with a cross-cutting behavior, e.g. logging or authentication.
+// Middlewares that are disabled by the options return the handler unchanged.
+type Middleware func(next fasthttp.RequestHandler) fasthttp.RequestHandler
+
+// Chain composes middlewares in the order they are registered: the first is the outermost, seeing each
+// request first and its response last.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		for _, middleware := range slices.Backward(middlewares) {
+			next = middleware(next)
+		}
+		return next
+	}
+}
+
+// apiPathPrefix is the prefix of the API endpoints, the only ones subject to the request rate limit.
+const apiPathPrefix = "/api/"
+
+// middlewares returns the middleware pipeline of the server, in order:
+//   - metrics, counting every request, including rejected ones
+//   - tracing, recording a span for every request, continuing the trace of the caller
+//   - access log, assigning the request ID the inner middlewares report errors with
+//   - recovery, answering panics with 500 Internal Server Error, so they are logged and counted
+//   - rate limit, rejecting excess API requests before authenticating them costs a review
+//   - authentication, with RequireAuth
+//   - compression, with EnableCompression
+//   - the Middlewares of opts, in their order
+//
+// Metrics and spans label requests with the patterns of routes.
+func (h *apiHandler) middlewares(opts Options, routes *router) []Middleware {
+	pipeline := []Middleware{
+		func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return instrument(opts.Metrics, routes.routeLabel, next)
+		},
+		func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return traceRequests(otel.GetTracerProvider(), otel.GetTextMapPropagator(), routes.routeLabel, next)
+		},
+		func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return accessLog(h.logger, opts.AccessLogSampling, next)
+		},
+		h.recoverPanics,
+		h.rateLimit(opts.Limits.withDefaults()),
+		h.authenticate(opts),
+		compress(opts.EnableCompression),
+	}
+	return append(pipeline, opts.Middlewares...)
+}
+
+// recoverPanics recovers from panics of the handler, logging them with their stack trace and the request
+// ID, and answers 500 Internal Server Error instead of crashing the server.
+func (h *apiHandler) recoverPanics(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			h.logger.Error().Any("panic", recovered).Str("stack", string(debug.Stack())).
+				Str("path", string(ctx.Path())).Str("request_id", requestID(ctx)).Msg("Recovered from panic")
+			ctx.Response.ResetBody()
+			h.writeError(ctx, fasthttp.StatusInternalServerError, "internal server error")
+		}()
+		next(ctx)
+	}
+}
+
+// rateLimit limits the API requests of all clients together to limits.RequestRate per second, with bursts
+// of limits.RequestBurst, answering 429 Too Many Requests beyond. Probes and scrapes are never limited.
+func (h *apiHandler) rateLimit(limits Limits) Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		if limits.RequestRate <= 0 {
+			return next
+		}
+		limiter := rate.NewLimiter(rate.Limit(limits.RequestRate), limits.RequestBurst)
+		return func(ctx *fasthttp.RequestCtx) {
+			if !strings.HasPrefix(string(ctx.Path()), apiPathPrefix) || limiter.Allow() {
+				next(ctx)
+				return
+			}
+			ctx.Response.Header.Set(fasthttp.HeaderRetryAfter, "1")
+			h.writeError(ctx, fasthttp.StatusTooManyRequests, "request rate limit exceeded, retry later")
+		}
+	}
+}
+
+// authenticate only serves requests authenticated by the Authenticator of opts with RequireAuth, except
+// for the OpenPaths and the webhook deliveries, which are authenticated by their signatures.
+func (h *apiHandler) authenticate(opts Options) Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		if !opts.RequireAuth {
+			return next
+		}
+		protected := h.requireAuth(opts.Authenticator, next)
+		return func(ctx *fasthttp.RequestCtx) {
+			path := string(ctx.Path())
+			if slices.Contains(opts.OpenPaths, path) || strings.HasPrefix(path, hooksPathPrefix) {
+				next(ctx)
+				return
+			}
+			protected(ctx)
+		}
+	}
+}
+
+// compress compresses responses with brotli or gzip, as accepted by the client, if enabled. Small and
+// already compressed responses are sent as is.
+func compress(enabled bool) Middleware {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		if !enabled {
+			return next
+		}
+		return fasthttp.CompressHandlerBrotliLevel(next, fasthttp.CompressBrotliDefaultCompression,
+			fasthttp.CompressDefaultCompression)
+	}
+}
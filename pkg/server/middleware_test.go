@@ -0,0 +1,168 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the middleware pipeline.
+package server
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// TestChain verifies that middlewares see requests in the order they are registered.
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return func(ctx *fasthttp.RequestCtx) {
+				calls = append(calls, name+" in")
+				next(ctx)
+				calls = append(calls, name+" out")
+			}
+		}
+	}
+
+	tests := []struct {
+		name        string
+		middlewares []Middleware
+		want        []string
+	}{
+		{"none", nil, []string{"handler"}},
+		{"one", []Middleware{record("a")}, []string{"a in", "handler", "a out"}},
+		{"ordered", []Middleware{record("a"), record("b")}, []string{"a in", "b in", "handler", "b out", "a out"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			Chain(tt.middlewares...)(func(*fasthttp.RequestCtx) { calls = append(calls, "handler") })(
+				&fasthttp.RequestCtx{})
+			if !slices.Equal(calls, tt.want) {
+				t.Errorf("calls = %v, want %v", calls, tt.want)
+			}
+		})
+	}
+}
+
+// TestRecoverPanics verifies that panics are answered with 500 and logged, and are counted and logged as
+// requests by the outer middlewares.
+func TestRecoverPanics(t *testing.T) {
+	var logBuf bytes.Buffer
+	registry := metrics.NewRegistry()
+	panics := func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if string(ctx.Path()) == "/api/v1/panic" {
+				panic("boom")
+			}
+			next(ctx)
+		}
+	}
+	handler := createHandler(zerolog.New(&logBuf), Options{Metrics: registry, Middlewares: []Middleware{panics}})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/panic")
+	handler(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", fasthttp.StatusInternalServerError, ctx.Response.StatusCode())
+	}
+	if body := string(ctx.Response.Body()); !strings.Contains(body, "internal server error") {
+		t.Errorf("unexpected body %q", body)
+	}
+	log := logBuf.String()
+	for _, want := range []string{`"panic":"boom"`, `"message":"Recovered from panic"`, `"status":500`} {
+		if !strings.Contains(log, want) {
+			t.Errorf("expected the log to contain %s, got:\n%s", want, log)
+		}
+	}
+	var metricsBuf bytes.Buffer
+	if err := registry.WritePrometheus(&metricsBuf); err != nil {
+		t.Fatal(err)
+	}
+	if want := `kc_http_requests_total{code="500",method="GET",route="other"} 1`; !strings.Contains(
+		metricsBuf.String(), want) {
+		t.Errorf("expected metrics to contain %q, got:\n%s", want, metricsBuf.String())
+	}
+}
+
+// TestRateLimit verifies that API requests beyond the rate are answered with 429, and that other paths
+// are not limited.
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		path   string
+		want   []int
+	}{
+		{"disabled", Limits{}, "/api/v1/limits", []int{200, 200, 200}},
+		{"burst", Limits{RequestRate: 0.001, RequestBurst: 2}, "/api/v1/limits", []int{200, 200, 429}},
+		{"default burst", Limits{RequestRate: 0.001}, "/api/v1/limits", []int{200, 429, 429}},
+		{"probes", Limits{RequestRate: 0.001}, "/livez", []int{200, 200, 200}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Limits: tt.limits})
+			var got []int
+			for range tt.want {
+				ctx := &fasthttp.RequestCtx{}
+				ctx.Request.SetRequestURI(tt.path)
+				handler(ctx)
+				got = append(got, ctx.Response.StatusCode())
+				retryAfter := string(ctx.Response.Header.Peek(fasthttp.HeaderRetryAfter))
+				if (ctx.Response.StatusCode() == fasthttp.StatusTooManyRequests) != (retryAfter != "") {
+					t.Errorf("unexpected Retry-After %q with status %d", retryAfter, ctx.Response.StatusCode())
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompress verifies that responses are compressed as accepted by the client, if enabled.
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("compressible ", 100)
+	tests := []struct {
+		name           string
+		enabled        bool
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{"disabled", false, "gzip", ""},
+		{"gzip", true, "gzip", "gzip"},
+		{"brotli", true, "br, gzip", "br"},
+		{"not accepted", true, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := compress(tt.enabled)(func(ctx *fasthttp.RequestCtx) {
+				ctx.SetContentType("text/plain")
+				ctx.SetBodyString(body)
+			})
+			ctx := &fasthttp.RequestCtx{}
+			if tt.acceptEncoding != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAcceptEncoding, tt.acceptEncoding)
+			}
+			handler(ctx)
+
+			if got := string(ctx.Response.Header.ContentEncoding()); got != tt.wantEncoding {
+				t.Fatalf("expected content encoding %q, got %q", tt.wantEncoding, got)
+			}
+			decoded, err := ctx.Response.BodyUncompressed()
+			if err != nil {
+				t.Fatalf("failed to decode the body: %v", err)
+			}
+			if string(decoded) != body {
+				t.Errorf("unexpected body %q", decoded)
+			}
+		})
+	}
+}
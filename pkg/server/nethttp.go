@@ -0,0 +1,139 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the net/http backend, which serves the fasthttp handler over HTTP/1.1 and HTTP/2.
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/netutil"
+)
+
+// netHTTPBackend serves the handler with net/http.
+type netHTTPBackend struct {
+	server      *http.Server
+	concurrency int
+}
+
+// newNetHTTPBackend creates a net/http backend serving handler within limits, over HTTP/1.1 and HTTP/2,
+// and over h2c, unencrypted HTTP/2, for clients connecting with prior knowledge.
+func newNetHTTPBackend(handler fasthttp.RequestHandler, limits Limits) *netHTTPBackend {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &netHTTPBackend{server: &http.Server{
+		Handler:      adaptHandler(handler, limits.MaxRequestBodySize),
+		ReadTimeout:  limits.ReadTimeout,
+		WriteTimeout: limits.WriteTimeout,
+		IdleTimeout:  limits.IdleTimeout,
+		Protocols:    &protocols,
+	}, concurrency: limits.Concurrency}
+}
+
+// Serve serves the connections accepted from ln until Shutdown, at most the concurrency limit at a time.
+func (b *netHTTPBackend) Serve(ln net.Listener) error {
+	err := b.server.Serve(netutil.LimitListener(ln, b.concurrency))
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections and waits for the in-flight requests to complete until ctx is done.
+func (b *netHTTPBackend) Shutdown(ctx context.Context) error {
+	return b.server.Shutdown(ctx)
+}
+
+// ALPNProtocols returns HTTP/2 and HTTP/1.1.
+func (b *netHTTPBackend) ALPNProtocols() []string {
+	return []string{alpnHTTP2, alpnHTTP1}
+}
+
+// adaptHandler serves a fasthttp handler to net/http, converting each request to a fasthttp.RequestCtx and
+// its response back. Request bodies beyond maxBodySize are answered with 413 Request Entity Too Large.
+func adaptHandler(handler fasthttp.RequestHandler, maxBodySize int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(maxBodySize)))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			} else {
+				http.Error(w, "Error when reading request", http.StatusBadRequest)
+			}
+			return
+		}
+
+		var req fasthttp.Request
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.URL.RequestURI())
+		req.Header.SetHost(r.Host)
+		for name, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+		req.SetBody(body)
+
+		var ctx fasthttp.RequestCtx
+		ctx.Init(&req, remoteAddr(r.RemoteAddr), nil)
+		if config := streamRequestConfig(&ctx.Request.Header); config.WriteTimeout > 0 {
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(config.WriteTimeout))
+		}
+		handler(&ctx)
+		writeResponse(w, &ctx.Response)
+	})
+}
+
+// writeResponse writes a response of a fasthttp handler to w. Streamed responses are flushed to the
+// client whenever the handler flushes them, e.g. after each server-sent event.
+func writeResponse(w http.ResponseWriter, resp *fasthttp.Response) {
+	header := w.Header()
+	for name, value := range resp.Header.All() {
+		switch key := http.CanonicalHeaderKey(string(name)); key {
+		case "Content-Length", "Connection", "Transfer-Encoding":
+			// Framing is up to net/http, and connection-specific headers are invalid in HTTP/2.
+		default:
+			header.Add(key, string(value))
+		}
+	}
+	w.WriteHeader(resp.StatusCode())
+
+	if !resp.IsBodyStream() {
+		_, _ = w.Write(resp.Body())
+		return
+	}
+	_ = resp.BodyWriteTo(flushWriter{w: w, controller: http.NewResponseController(w)})
+}
+
+// flushWriter flushes each write to the client.
+type flushWriter struct {
+	w          io.Writer
+	controller *http.ResponseController
+}
+
+// Write writes p and flushes it to the client.
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		err = f.controller.Flush()
+	}
+	return n, err
+}
+
+// remoteAddr parses the remote address of a request, which is not an IP address and port for requests
+// over a Unix domain socket.
+func remoteAddr(addr string) net.Addr {
+	addrPort, err := netip.ParseAddrPort(addr)
+	if err != nil {
+		return nil
+	}
+	return net.TCPAddrFromAddrPort(addrPort)
+}
@@ -0,0 +1,135 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the net/http backend.
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// serveNetHTTP serves handler with the net/http backend on a loopback listener and returns a client
+// speaking protocols to it.
+func serveNetHTTP(t *testing.T, handler fasthttp.RequestHandler, protocols *http.Protocols) *http.Client {
+	t.Helper()
+	srv := newNetHTTPBackend(handler, Limits{}.withDefaults())
+	ln := listenLoopback(t)
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, ln.Addr().String())
+			},
+			Protocols: protocols,
+		},
+		Timeout: 5 * time.Second,
+	}
+}
+
+// TestNetHTTPBackend verifies that requests and responses are converted between net/http and the fasthttp
+// handler, over HTTP/1.1 and h2c.
+func TestNetHTTPBackend(t *testing.T) {
+	handler := func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set("X-Echo", string(ctx.Request.Header.Peek("X-Test")))
+		ctx.Response.Header.Set(fasthttp.HeaderConnection, "close")
+		ctx.SetContentType("text/plain")
+		ctx.SetStatusCode(fasthttp.StatusCreated)
+		_, _ = ctx.WriteString(string(ctx.Method()) + " " + string(ctx.Path()) + "?" +
+			string(ctx.QueryArgs().Peek("q")) + " " + string(ctx.Host()) + " " + string(ctx.PostBody()))
+	}
+
+	var http1, h2c http.Protocols
+	http1.SetHTTP1(true)
+	h2c.SetUnencryptedHTTP2(true)
+	tests := []struct {
+		name      string
+		protocols *http.Protocols
+		wantProto string
+	}{
+		{"http/1.1", &http1, "HTTP/1.1"},
+		{"h2c", &h2c, "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := serveNetHTTP(t, handler, tt.protocols)
+			req, err := http.NewRequest(http.MethodPost, "http://kc.example.com/api/v1/echo?q=1",
+				strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Test", "value")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read response: %v", err)
+			}
+
+			if resp.Proto != tt.wantProto {
+				t.Errorf("expected protocol %s, got %s", tt.wantProto, resp.Proto)
+			}
+			if resp.StatusCode != http.StatusCreated {
+				t.Errorf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+			}
+			if got := resp.Header.Get("X-Echo"); got != "value" {
+				t.Errorf("expected X-Echo %q, got %q", "value", got)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "text/plain" {
+				t.Errorf("expected content type text/plain, got %q", got)
+			}
+			if want := "POST /api/v1/echo?1 kc.example.com payload"; string(body) != want {
+				t.Errorf("expected body %q, got %q", want, body)
+			}
+		})
+	}
+}
+
+// TestNetHTTPBackendStream verifies that streamed responses reach the client as the handler flushes them.
+func TestNetHTTPBackendStream(t *testing.T) {
+	release := make(chan struct{})
+	handler := func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("text/event-stream")
+		ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+			_, _ = w.WriteString("data: first\n\n")
+			_ = w.Flush()
+			<-release
+			_, _ = w.WriteString("data: second\n\n")
+		})
+	}
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	client := serveNetHTTP(t, handler, &protocols)
+	resp, err := client.Get("http://localhost/api/v1/watch/deployments")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	reader := bufio.NewReader(resp.Body)
+	if line, err := reader.ReadString('\n'); err != nil || line != "data: first\n" {
+		t.Fatalf("expected the first event before the stream ended, got %q, %v", line, err)
+	}
+	close(release)
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read the stream: %v", err)
+	}
+	if string(rest) != "\ndata: second\n\n" {
+		t.Errorf("unexpected end of the stream %q", rest)
+	}
+}
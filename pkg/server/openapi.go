@@ -0,0 +1,255 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file generates the OpenAPI document of the REST API and serves it with the Swagger UI.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/explain"
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// Paths of the OpenAPI document and the Swagger UI.
+const (
+	openAPIPath   = "/openapi.json"
+	swaggerUIPath = "/docs"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the document follows.
+const openAPIVersion = "3.0.3"
+
+// swaggerUIDist is the CDN location of the Swagger UI assets, which the page served on swaggerUIPath loads
+// into the browser, so that they need not be bundled with the binary.
+const swaggerUIDist = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5.17.14"
+
+// queryParams describes the query parameters of the API endpoints.
+var queryParams = map[string]string{
+	"namespace":     "Namespace to list from; all namespaces if empty",
+	"labelSelector": "Label selector the items must match, e.g. app=web",
+	"sortBy":        "Field to sort by: name, namespace or age; namespace and name if empty",
+	"order":         "Sort order: asc or desc",
+	"limit":         fmt.Sprintf("Page size, at most %d; all items if empty", maxListLimit),
+	"continue":      "Token of the next page, from the continue field of the previous page",
+	"format":        "Export format: csv or jsonl",
+}
+
+// pagingParams are the query parameters of the sorted and paged list endpoints.
+var pagingParams = []string{"sortBy", "order", "limit", "continue"}
+
+// apiOperation documents an endpoint of the REST API.
+type apiOperation struct {
+	method  string
+	pattern string
+	summary string
+
+	// query are the names of the query parameters in queryParams.
+	query []string
+
+	// status is the status code of a successful response, 200 if zero.
+	status int
+
+	// response names the APITypes entry of a JSON response; contentType describes other responses.
+	response    string
+	contentType string
+
+	// requestBody is the content type of the request body, if any; requestType names the APITypes entry
+	// of a JSON request body instead.
+	requestBody string
+	requestType string
+
+	// write marks endpoints of the write API, which always require authentication.
+	write bool
+
+	// auth marks other endpoints that always require authentication.
+	auth bool
+
+	// signed marks endpoints authenticating requests by their signature, which never need a bearer token.
+	signed bool
+}
+
+// apiOperations are the documented endpoints of the REST API.
+var apiOperations = []apiOperation{
+	{method: fasthttp.MethodGet, pattern: "/livez", summary: "Liveness probe", response: "readyz"},
+	{method: fasthttp.MethodGet, pattern: "/readyz", summary: "Readiness probe", response: "readyz"},
+	{method: fasthttp.MethodGet, pattern: "/startupz", summary: "Staged startup progress", response: "startupz"},
+	{method: fasthttp.MethodGet, pattern: "/version", summary: "Build and Kubernetes versions", response: "version"},
+	{method: fasthttp.MethodGet, pattern: "/healthz/detail", summary: "Health of the server and its subsystems",
+		response: "health"},
+	{method: fasthttp.MethodGet, pattern: "/api/v1/deployments", summary: "List deployments",
+		query: append([]string{"namespace", "labelSelector"}, pagingParams...), response: "deployments"},
+	{method: fasthttp.MethodGet, pattern: "/api/v1/watch/deployments",
+		summary: "Watch deployment changes as server-sent events, resumed from the Last-Event-ID header",
+		query:   []string{"namespace", "labelSelector"}, contentType: "text/event-stream"},
+	{method: fasthttp.MethodGet, pattern: "/api/v1/pods", summary: "List pods",
+		query: append([]string{"namespace"}, pagingParams...), response: "pods"},
+	{method: fasthttp.MethodGet, pattern: "/api/v1/services", summary: "List services",
+		query: append([]string{"namespace"}, pagingParams...), response: "services"},
+	{method: fasthttp.MethodGet, pattern: "/api/v1/nodes", summary: "List nodes", query: pagingParams,
+		response: "nodes"},
+	{method: fasthttp.MethodGet, pattern: "/api/v1/namespaces", summary: "List namespaces", query: pagingParams,
+		response: "namespaces"},
+	{method: fasthttp.MethodPost, pattern: deploymentsPattern, summary: "Create a deployment from a manifest",
+		status: fasthttp.StatusCreated, response: "deployment", requestBody: "application/yaml", write: true},
+	{method: fasthttp.MethodPut, pattern: deploymentPattern, summary: "Replace a deployment with a manifest",
+		response: "deployment", requestBody: "application/yaml", write: true},
+	{method: fasthttp.MethodDelete, pattern: deploymentPattern, summary: "Delete a deployment",
+		response: "deletion", write: true},
+	{method: fasthttp.MethodGet, pattern: "/api/v1/limits", summary: "Upstream timeout and retry budget",
+		response: "limits"},
+	{method: fasthttp.MethodGet, pattern: reportsPathPrefix + "{name}/export", summary: "Export a report",
+		query: []string{"format"}, contentType: "text/csv"},
+	{method: fasthttp.MethodPost, pattern: hookPattern, summary: "Run the pipeline of a hook for a signed delivery",
+		status: fasthttp.StatusAccepted, response: "hook", signed: true},
+	{method: fasthttp.MethodGet, pattern: logLevelPath, summary: "Current log level", response: "loglevel"},
+	{method: fasthttp.MethodPut, pattern: logLevelPath, summary: "Change the log level at runtime",
+		response: "loglevel", requestType: "loglevel", auth: true},
+	{method: fasthttp.MethodGet, pattern: "/metrics", summary: "Request metrics in the Prometheus text format",
+		contentType: "text/plain"},
+}
+
+// openAPIDocument generates the OpenAPI document of the endpoints served with opts.
+func openAPIDocument(opts Options) map[string]any {
+	_, scraped := opts.Metrics.(metrics.Exposer)
+	paths := map[string]any{}
+	for _, op := range apiOperations {
+		if (op.write && !opts.EnableWriteAPI) || (op.pattern == "/metrics" && !scraped) {
+			continue
+		}
+		item, ok := paths[op.pattern].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[op.pattern] = item
+		}
+		item[strings.ToLower(op.method)] = op.openAPI(opts)
+	}
+
+	schemas := map[string]any{}
+	for name, apiType := range APITypes() {
+		schema := explain.FromType(apiType.Type).OpenAPI()
+		schema["description"] = apiType.Description
+		schemas[name] = schema
+	}
+	components := map[string]any{"schemas": schemas}
+	if opts.Authenticator != nil {
+		components["securitySchemes"] = map[string]any{
+			"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+		}
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "k8s-controller API",
+			"description": "REST API of the k8s-controller server for querying and managing a Kubernetes cluster.",
+			"version":     opts.Build.Version,
+		},
+		"paths":      paths,
+		"components": components,
+	}
+}
+
+// openAPI generates the OpenAPI operation object of op.
+func (op apiOperation) openAPI(opts Options) map[string]any {
+	var params []any
+	for _, segment := range strings.Split(op.pattern, "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			params = append(params, map[string]any{"name": strings.TrimSuffix(name, "}"), "in": "path",
+				"required": true, "schema": map[string]any{"type": "string"}})
+		}
+	}
+	for _, name := range op.query {
+		params = append(params, map[string]any{"name": name, "in": "query", "description": queryParams[name],
+			"schema": map[string]any{"type": "string"}})
+	}
+
+	status := op.status
+	if status == 0 {
+		status = fasthttp.StatusOK
+	}
+	response := map[string]any{"description": fasthttp.StatusMessage(status)}
+	switch {
+	case op.response != "":
+		response["content"] = jsonContent(op.response)
+	case op.contentType != "":
+		response["content"] = map[string]any{op.contentType: map[string]any{"schema": map[string]any{"type": "string"}}}
+	}
+
+	operation := map[string]any{
+		"summary":     op.summary,
+		"operationId": operationID(op.method, op.pattern),
+		"responses": map[string]any{
+			fmt.Sprint(status): response,
+			"default":          map[string]any{"description": "Error", "content": jsonContent("error")},
+		},
+	}
+	if len(params) > 0 {
+		operation["parameters"] = params
+	}
+	if op.requestType != "" {
+		operation["requestBody"] = map[string]any{"required": true, "content": jsonContent(op.requestType)}
+	}
+	if op.requestBody != "" {
+		schema := map[string]any{"type": "string", "description": "Deployment manifest in YAML or JSON"}
+		operation["requestBody"] = map[string]any{"required": true, "content": map[string]any{
+			op.requestBody: map[string]any{"schema": schema}, "application/json": map[string]any{"schema": schema},
+		}}
+	}
+	if !op.signed && (op.write || op.auth || (opts.RequireAuth && !slices.Contains(opts.OpenPaths, op.pattern))) {
+		operation["security"] = []any{map[string]any{"bearerAuth": []any{}}}
+	}
+	return operation
+}
+
+// jsonContent returns the OpenAPI content object of a JSON body of the APITypes entry name.
+func jsonContent(name string) map[string]any {
+	return map[string]any{"application/json": map[string]any{
+		"schema": map[string]any{"$ref": "#/components/schemas/" + name},
+	}}
+}
+
+// operationID derives a unique operation ID from the method and path pattern of an endpoint, e.g.
+// "put_api_v1_namespaces_namespace_deployments_name".
+func operationID(method, pattern string) string {
+	id := strings.ToLower(method) + strings.NewReplacer("/", "_", "{", "", "}", "", ".", "").Replace(pattern)
+	return strings.TrimSuffix(id, "_")
+}
+
+// serveOpenAPI returns a handler of GET /openapi.json serving the OpenAPI document of the endpoints served
+// with opts. The document is generated once, since it only depends on the options.
+func serveOpenAPI(opts Options) fasthttp.RequestHandler {
+	// The document consists of maps, slices, strings and booleans, whose encoding cannot fail.
+	document, _ := json.Marshal(openAPIDocument(opts))
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		ctx.SetBody(document)
+	}
+}
+
+// swaggerUIPage is the Swagger UI page exploring the OpenAPI document.
+var swaggerUIPage = []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>k8s-controller API</title>
+  <link rel="stylesheet" href="` + swaggerUIDist + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="` + swaggerUIDist + `/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "` + openAPIPath + `", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`)
+
+// serveSwaggerUI handles GET /docs with the Swagger UI page.
+func serveSwaggerUI(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.SetBody(swaggerUIPage)
+}
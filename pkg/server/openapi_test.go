@@ -0,0 +1,155 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the OpenAPI document and the Swagger UI.
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/metrics"
+)
+
+// TestOpenAPIDocumentCoversRoutes verifies that every routed API endpoint is documented, and that the
+// documented responses refer to existing schemas.
+func TestOpenAPIDocumentCoversRoutes(t *testing.T) {
+	opts := Options{EnableWriteAPI: true, Metrics: metrics.NewRegistry(), Authenticator: NewTokenAuthenticator(nil)}
+	document := openAPIDocument(opts)
+	paths := document["paths"].(map[string]any)
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+
+	// The gateway endpoints are described by the protobuf service they are generated from, and the service
+	// proxy serves the APIs of the proxied services.
+	undocumented := map[string]bool{"/": true, openAPIPath: true, gatewayPattern: true,
+		serviceProxyPattern: true, serviceProxyPathPattern: true}
+	for _, rt := range newAPIHandler(nil, RetryBudget{}, zerolog.Nop()).routes(opts).routes {
+		if undocumented[rt.pattern] {
+			continue
+		}
+		item, ok := paths[rt.pattern].(map[string]any)
+		if !ok {
+			t.Errorf("%s is not documented", rt.pattern)
+			continue
+		}
+		operation, ok := item[strings.ToLower(rt.method)].(map[string]any)
+		if !ok {
+			t.Errorf("%s %s is not documented", rt.method, rt.pattern)
+			continue
+		}
+		for status, response := range operation["responses"].(map[string]any) {
+			content, _ := response.(map[string]any)["content"].(map[string]any)
+			media, _ := content["application/json"].(map[string]any)
+			if media == nil {
+				continue
+			}
+			ref := media["schema"].(map[string]any)["$ref"].(string)
+			if _, ok := schemas[strings.TrimPrefix(ref, "#/components/schemas/")]; !ok {
+				t.Errorf("%s %s %s refers to the missing schema %s", rt.method, rt.pattern, status, ref)
+			}
+		}
+	}
+}
+
+// TestOpenAPIDocumentOptions verifies that the document only describes the endpoints served with the
+// options, and which of them require authentication.
+func TestOpenAPIDocumentOptions(t *testing.T) {
+	auth := NewTokenAuthenticator(nil)
+	tests := []struct {
+		name         string
+		opts         Options
+		path         string
+		method       string
+		wantServed   bool
+		wantSecurity bool
+	}{
+		{"read endpoint", Options{}, "/api/v1/pods", "get", true, false},
+		{"write API disabled", Options{}, deploymentsPattern, "post", false, false},
+		{"write API enabled", Options{EnableWriteAPI: true, Authenticator: auth}, deploymentsPattern, "post",
+			true, true},
+		{"pushed metrics", Options{Metrics: metrics.Nop{}}, "/metrics", "get", false, false},
+		{"scraped metrics", Options{Metrics: metrics.NewRegistry()}, "/metrics", "get", true, false},
+		{"required auth", Options{RequireAuth: true, Authenticator: auth, OpenPaths: DefaultOpenPaths},
+			"/api/v1/pods", "get", true, true},
+		{"open path", Options{RequireAuth: true, Authenticator: auth, OpenPaths: DefaultOpenPaths},
+			"/livez", "get", true, false},
+		{"admin endpoint", Options{}, logLevelPath, "put", true, true},
+		{"hook", Options{RequireAuth: true, Authenticator: auth}, hookPattern, "post", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, _ := openAPIDocument(tt.opts)["paths"].(map[string]any)[tt.path].(map[string]any)
+			operation, served := item[tt.method].(map[string]any)
+			if served != tt.wantServed {
+				t.Fatalf("expected %s %s served %v, got %v", tt.method, tt.path, tt.wantServed, served)
+			}
+			if _, secured := operation["security"]; served && secured != tt.wantSecurity {
+				t.Errorf("expected security %v, got %v", tt.wantSecurity, secured)
+			}
+		})
+	}
+}
+
+// TestOpenAPIEndpoints verifies that the document is served on /openapi.json, and the Swagger UI on /docs
+// if enabled.
+func TestOpenAPIEndpoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		path        string
+		wantStatus  int
+		wantType    string
+		wantContent string
+	}{
+		{"document", Options{}, openAPIPath, fasthttp.StatusOK, "application/json", `"openapi":"3.0.3"`},
+		{"swagger UI", Options{EnableSwaggerUI: true}, swaggerUIPath, fasthttp.StatusOK, "text/html; charset=utf-8",
+			`url: "/openapi.json"`},
+		{"swagger UI disabled", Options{}, swaggerUIPath, fasthttp.StatusNotFound, "application/json", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.path)
+			createHandler(zerolog.Nop(), tt.opts)(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, ctx.Response.StatusCode())
+			}
+			if got := string(ctx.Response.Header.ContentType()); got != tt.wantType {
+				t.Errorf("expected content type %q, got %q", tt.wantType, got)
+			}
+			body := ctx.Response.Body()
+			if !strings.Contains(string(body), tt.wantContent) {
+				t.Errorf("expected the body to contain %s, got:\n%s", tt.wantContent, body)
+			}
+			if tt.wantType == "application/json" && !json.Valid(body) {
+				t.Errorf("invalid JSON body %s", body)
+			}
+		})
+	}
+}
+
+// TestOperationID verifies that operation IDs are derived from the method and path pattern.
+func TestOperationID(t *testing.T) {
+	tests := []struct {
+		method  string
+		pattern string
+		want    string
+	}{
+		{fasthttp.MethodGet, "/livez", "get_livez"},
+		{fasthttp.MethodPut, deploymentPattern, "put_api_v1_namespaces_namespace_deployments_name"},
+		{fasthttp.MethodGet, "/openapi.json", "get_openapijson"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := operationID(tt.method, tt.pattern); got != tt.want {
+				t.Errorf("operationID(%q, %q) = %q, want %q", tt.method, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements sorting and pagination of the list endpoints.
+package server
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// maxListLimit is the largest page size accepted by ?limit=.
+const maxListLimit = 1000
+
+// Values of ?sortBy=. Without it, items are sorted by namespace and name.
+const (
+	sortByName      = "name"
+	sortByNamespace = "namespace"
+	sortByAge       = "age"
+)
+
+// Values of ?order=.
+const (
+	orderAsc  = "asc"
+	orderDesc = "desc"
+)
+
+// itemMeta holds the fields list items are sorted by.
+type itemMeta struct {
+	namespace string
+	name      string
+	created   time.Time
+}
+
+// listQuery holds the sorting and pagination parameters of a list request.
+type listQuery struct {
+	SortBy string `json:"s,omitempty"`
+	Order  string `json:"o,omitempty"`
+
+	// Offset is the index of the first item of the page; it is carried by the continue token.
+	Offset int `json:"i"`
+
+	// Limit is the page size, or zero for all items.
+	Limit int `json:"-"`
+}
+
+// parseListQuery parses ?sortBy=, ?order=, ?limit= and ?continue=. A continue token must be used
+// with the sorting it was issued for, so that the next page continues the same order.
+func parseListQuery(args *fasthttp.Args) (listQuery, error) {
+	query := listQuery{SortBy: string(args.Peek("sortBy")), Order: string(args.Peek("order"))}
+	switch query.SortBy {
+	case "", sortByName, sortByNamespace, sortByAge:
+	default:
+		return listQuery{}, fmt.Errorf("unsupported sortBy %q, use %s, %s or %s",
+			query.SortBy, sortByName, sortByNamespace, sortByAge)
+	}
+	switch query.Order {
+	case "", orderAsc:
+		query.Order = ""
+	case orderDesc:
+	default:
+		return listQuery{}, fmt.Errorf("unsupported order %q, use %s or %s", query.Order, orderAsc, orderDesc)
+	}
+
+	if limit := string(args.Peek("limit")); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxListLimit {
+			return listQuery{}, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		query.Limit = n
+	}
+
+	if token := string(args.Peek("continue")); token != "" {
+		continued, err := decodeContinue(token)
+		if err != nil {
+			return listQuery{}, err
+		}
+		if continued.SortBy != query.SortBy || continued.Order != query.Order {
+			return listQuery{}, errors.New("continue token was issued for a different sortBy or order")
+		}
+		query.Offset = continued.Offset
+	}
+	return query, nil
+}
+
+// decodeContinue decodes a continue token issued by page.
+func decodeContinue(token string) (listQuery, error) {
+	var query listQuery
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &query)
+	}
+	if err != nil || query.Offset < 0 {
+		return listQuery{}, errors.New("invalid continue token")
+	}
+	return query, nil
+}
+
+// sortItems sorts items by the field of the query, breaking ties by namespace and name.
+func sortItems[T any](items []T, meta func(T) itemMeta, query listQuery) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		c := compareItems(meta(a), meta(b), query.SortBy)
+		if query.Order == orderDesc {
+			return -c
+		}
+		return c
+	})
+}
+
+// compareItems orders items by a sortBy field, then by namespace and name. Ascending age means the
+// most recently created items first.
+func compareItems(a, b itemMeta, sortBy string) int {
+	var c int
+	switch sortBy {
+	case sortByName:
+		c = cmp.Compare(a.name, b.name)
+	case sortByAge:
+		c = b.created.Compare(a.created)
+	}
+	if c != 0 {
+		return c
+	}
+	return cmp.Or(cmp.Compare(a.namespace, b.namespace), cmp.Compare(a.name, b.name))
+}
+
+// page returns the items of the query's page and the continue token of the next page, or "" if it
+// is the last one. Pages are cut from the current items, so objects created or deleted between
+// requests may shift items across page boundaries.
+func page[T any](items []T, query listQuery) ([]T, string) {
+	start := min(query.Offset, len(items))
+	if query.Limit == 0 || start+query.Limit >= len(items) {
+		return items[start:], ""
+	}
+
+	next := query
+	next.Offset = start + query.Limit
+	data, _ := json.Marshal(next)
+	return items[start:next.Offset], base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,116 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests sorting and pagination of the list endpoints.
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TestParseListQuery verifies validation of the sorting and pagination parameters.
+func TestParseListQuery(t *testing.T) {
+	_, token := page([]int{1, 2, 3}, listQuery{SortBy: sortByAge, Limit: 1})
+
+	tests := []struct {
+		name    string
+		query   string
+		want    listQuery
+		wantErr string
+	}{
+		{"defaults", "", listQuery{}, ""},
+		{"sorted page", "sortBy=age&order=desc&limit=10",
+			listQuery{SortBy: sortByAge, Order: orderDesc, Limit: 10}, ""},
+		{"ascending order", "order=asc", listQuery{}, ""},
+		{"continued", "sortBy=age&limit=1&continue=" + token, listQuery{SortBy: sortByAge, Offset: 1, Limit: 1}, ""},
+		{"unknown sortBy", "sortBy=size", listQuery{}, "unsupported sortBy"},
+		{"unknown order", "order=random", listQuery{}, "unsupported order"},
+		{"zero limit", "limit=0", listQuery{}, "limit must be"},
+		{"limit too large", "limit=1001", listQuery{}, "limit must be"},
+		{"malformed continue", "continue=%21%21", listQuery{}, "invalid continue token"},
+		{"continue with other sort", "sortBy=name&continue=" + token, listQuery{}, "different sortBy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := fasthttp.AcquireArgs()
+			defer fasthttp.ReleaseArgs(args)
+			args.Parse(tt.query)
+
+			got, err := parseListQuery(args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseListQuery(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseListQuery(%q) = %+v, %v, want %+v", tt.query, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestSortItems verifies the orders of each sortBy field, with ties broken by namespace and name.
+func TestSortItems(t *testing.T) {
+	now := time.Now()
+	items := []itemMeta{
+		{namespace: "shop", name: "cart", created: now.Add(-time.Hour)},
+		{namespace: "default", name: "web", created: now.Add(-time.Minute)},
+		{namespace: "shop", name: "api", created: now.Add(-time.Hour)},
+	}
+	identity := func(m itemMeta) itemMeta { return m }
+
+	tests := []struct {
+		query listQuery
+		want  string
+	}{
+		{listQuery{}, "web api cart"},
+		{listQuery{Order: orderDesc}, "cart api web"},
+		{listQuery{SortBy: sortByName}, "api cart web"},
+		{listQuery{SortBy: sortByNamespace}, "web api cart"},
+		{listQuery{SortBy: sortByAge}, "web api cart"},
+		{listQuery{SortBy: sortByAge, Order: orderDesc}, "cart api web"},
+	}
+
+	for _, tt := range tests {
+		sorted := append([]itemMeta(nil), items...)
+		sortItems(sorted, identity, tt.query)
+		names := make([]string, 0, len(sorted))
+		for _, item := range sorted {
+			names = append(names, item.name)
+		}
+		if got := strings.Join(names, " "); got != tt.want {
+			t.Errorf("sortItems(%+v) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestPage verifies that following the continue tokens walks all items exactly once.
+func TestPage(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	query := listQuery{SortBy: sortByName, Limit: 2}
+
+	var got []int
+	for pages := 0; pages < 10; pages++ {
+		items, next := page(items, query)
+		got = append(got, items...)
+		if next == "" {
+			break
+		}
+		continued, err := decodeContinue(next)
+		if err != nil {
+			t.Fatalf("decodeContinue() error = %v", err)
+		}
+		query.Offset = continued.Offset
+	}
+	if len(got) != 5 || got[0] != 1 || got[4] != 5 {
+		t.Errorf("expected all items once, got %v", got)
+	}
+
+	if rest, next := page(items, listQuery{Offset: 10}); len(rest) != 0 || next != "" {
+		t.Errorf("expected an empty last page past the end, got %v, %q", rest, next)
+	}
+}
@@ -0,0 +1,94 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the liveness and readiness probe endpoints.
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/startup"
+)
+
+// Timing of the Kubernetes API check of /readyz. The result of a check is reused for
+// apiCheckInterval, so frequent probes from several kubelets cost one request at most.
+const (
+	apiCheckInterval = 10 * time.Second
+	apiCheckTimeout  = 2 * time.Second
+)
+
+// probeResponse is the JSON body of /livez and /readyz.
+type probeResponse struct {
+	Status  string   `json:"status" doc:"\"ok\", or \"unavailable\" if not ready"`
+	Reasons []string `json:"reasons,omitempty" doc:"Why the server is not ready, one entry per failed check"`
+}
+
+// apiCheck checks that the Kubernetes API server answers, caching the result for apiCheckInterval.
+type apiCheck struct {
+	client *k8s.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// newAPICheck creates the API check of a client, which may be nil.
+func newAPICheck(client *k8s.Client) *apiCheck {
+	return &apiCheck{client: client, now: time.Now}
+}
+
+// check returns the result of the last check if it is recent enough, or checks again. Concurrent
+// probes wait for a running check instead of sending their own request.
+func (c *apiCheck) check(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.checkedAt.IsZero() && c.now().Sub(c.checkedAt) < apiCheckInterval {
+		return c.err
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, apiCheckTimeout)
+	defer cancel()
+	c.err = c.client.Ping(checkCtx)
+	c.checkedAt = c.now()
+	return c.err
+}
+
+// livez handles GET /livez. It responds 200 as long as the process serves requests;
+// dependencies are left to /readyz, so that their outages do not restart the server.
+func (h *apiHandler) livez(ctx *fasthttp.RequestCtx) {
+	h.writeJSON(ctx, fasthttp.StatusOK, probeResponse{Status: "ok"})
+}
+
+// readyz handles GET /readyz. It responds 200 if the Kubernetes API is reachable and the informer
+// caches have synced, and 503 with the reasons otherwise.
+func (h *apiHandler) readyz(ctx *fasthttp.RequestCtx, tracker *startup.Tracker) {
+	var reasons []string
+	if h.client == nil {
+		reasons = append(reasons, "kubernetes client not configured")
+	} else if err := h.apiCheck.check(context.Background()); err != nil {
+		reasons = append(reasons, err.Error())
+	}
+	if tracker != nil && !stageFinished(tracker.Status(), startup.StageCachesSyncing) {
+		reasons = append(reasons, "informer caches not synced")
+	}
+
+	if len(reasons) > 0 {
+		h.writeJSON(ctx, fasthttp.StatusServiceUnavailable, probeResponse{Status: "unavailable", Reasons: reasons})
+		return
+	}
+	h.writeJSON(ctx, fasthttp.StatusOK, probeResponse{Status: "ok"})
+}
+
+// stageFinished reports whether the named stage is done or skipped. Stages not tracked count as finished.
+func stageFinished(status startup.Status, name startup.Stage) bool {
+	for _, stage := range status.Stages {
+		if stage.Name == name {
+			return stage.State == startup.StateDone || stage.State == startup.StateSkipped
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the liveness and readiness probe endpoints.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/startup"
+)
+
+// TestReadyzEndpoint tests GET /readyz with missing, unreachable and ready dependencies.
+func TestReadyzEndpoint(t *testing.T) {
+	syncing := startup.NewTracker(startup.DefaultStages...)
+	syncing.Begin(startup.StageCachesSyncing)
+
+	synced := startup.NewTracker(startup.StageCachesSyncing)
+	synced.Complete(startup.StageCachesSyncing)
+
+	unreachable := k8s.NewFakeClient(zerolog.Nop())
+	unreachable.GetClientset().(*fake.Clientset).PrependReactor("list", "namespaces",
+		func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+
+	tests := []struct {
+		name           string
+		client         *k8s.Client
+		tracker        *startup.Tracker
+		expectedStatus int
+		expectedBody   probeResponse
+	}{
+		{"no client", nil, synced, fasthttp.StatusServiceUnavailable,
+			probeResponse{Status: "unavailable", Reasons: []string{"kubernetes client not configured"}}},
+		{"unreachable API", unreachable, synced, fasthttp.StatusServiceUnavailable,
+			probeResponse{Status: "unavailable", Reasons: []string{"kubernetes API unreachable: connection refused"}}},
+		{"caches syncing", k8s.NewFakeClient(zerolog.Nop()), syncing, fasthttp.StatusServiceUnavailable,
+			probeResponse{Status: "unavailable", Reasons: []string{"informer caches not synced"}}},
+		{"ready", k8s.NewFakeClient(zerolog.Nop()), synced, fasthttp.StatusOK, probeResponse{Status: "ok"}},
+		{"ready without tracker", k8s.NewFakeClient(zerolog.Nop()), nil, fasthttp.StatusOK,
+			probeResponse{Status: "ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Client: tt.client, Startup: tt.tracker})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/readyz")
+			ctx.Request.Header.SetMethod("GET")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, ctx.Response.StatusCode())
+			}
+			var body probeResponse
+			if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(body, tt.expectedBody) {
+				t.Errorf("expected body %+v, got %+v", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+// TestAPICheckCachesResult verifies that the API server is asked at most once per check interval.
+func TestAPICheckCachesResult(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop())
+	requests := 0
+	client.GetClientset().(*fake.Clientset).PrependReactor("list", "namespaces",
+		func(k8stesting.Action) (bool, runtime.Object, error) {
+			requests++
+			return false, nil, nil
+		})
+
+	now := time.Now()
+	check := newAPICheck(client)
+	check.now = func() time.Time { return now }
+
+	for range 3 {
+		if err := check.check(context.Background()); err != nil {
+			t.Fatalf("check() error = %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request within the check interval, got %d", requests)
+	}
+
+	now = now.Add(apiCheckInterval)
+	if err := check.check(context.Background()); err != nil || requests != 2 {
+		t.Errorf("expected a new request after the check interval, got %d requests (%v)", requests, err)
+	}
+}
@@ -0,0 +1,175 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the list endpoints of Kubernetes resources on a shared handler.
+package server
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// HeaderCacheAge is set on lists served from the read cache: the time since the cache last saw an
+// object of the listed resource being added, changed or deleted, or else since it synced, in
+// milliseconds. The watches keep the cache current, so a high age means the objects did not change.
+const HeaderCacheAge = "X-KC-Cache-Age"
+
+// resourceList is the response envelope of the list endpoints.
+// It mirrors the JSON output of the 'list deployments' CLI command.
+type resourceList[T any] struct {
+	Kind       string `json:"kind" doc:"Kind of the list, e.g. \"PodList\""`
+	APIVersion string `json:"apiVersion" doc:"API version of the listed kind, e.g. \"v1\" or \"apps/v1\""`
+	Items      []T    `json:"items" doc:"Objects of the requested page, or all objects without ?limit="`
+	Count      int    `json:"count" doc:"Number of items"`
+	Total      int    `json:"total" doc:"Number of objects matching the request across all pages"`
+	Continue   string `json:"continue,omitempty" doc:"Token to pass as ?continue= for the next page; unset on the last"`
+}
+
+// listKind names the objects served by a list endpoint.
+type listKind struct {
+	// kind and apiVersion are set in the response envelope.
+	kind       string
+	apiVersion string
+
+	// resource is the plural resource name used in log messages.
+	resource string
+}
+
+// Kinds of the list endpoints.
+var (
+	deploymentListKind = listKind{kind: "DeploymentList", apiVersion: "apps/v1", resource: "deployments"}
+	podListKind        = listKind{kind: "PodList", apiVersion: "v1", resource: "pods"}
+	serviceListKind    = listKind{kind: "ServiceList", apiVersion: "v1", resource: "services"}
+	nodeListKind       = listKind{kind: "NodeList", apiVersion: "v1", resource: "nodes"}
+	namespaceListKind  = listKind{kind: "NamespaceList", apiVersion: "v1", resource: "namespaces"}
+)
+
+// serveList handles a list endpoint: it calls list within the upstream retry budget and writes the
+// requested page of the objects, sorted by ?sortBy= and ?order=, in a resourceList envelope. Serving a
+// new kind only takes a lister of the Kubernetes client and the fields its objects are sorted by.
+// Lists served from the read cache carry its age in the HeaderCacheAge header.
+func serveList[T any](h *apiHandler, ctx *fasthttp.RequestCtx, kind listKind, meta func(T) itemMeta,
+	list func(*k8s.Client, context.Context) ([]T, error)) {
+	if h.client == nil {
+		h.writeError(ctx, fasthttp.StatusServiceUnavailable, "kubernetes client not configured")
+		return
+	}
+	query, err := parseListQuery(ctx.QueryArgs())
+	if err != nil {
+		h.writeError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Like the ETag of listDeployments, the age is taken before listing, so that it is never too young.
+	updated, cached := h.client.CacheUpdated(kind.resource)
+	var items []T
+	result, err := h.budget.call(requestContext(ctx), func(reqCtx context.Context) error {
+		var listErr error
+		items, listErr = list(h.client, reqCtx)
+		return listErr
+	})
+	setUpstreamHeaders(ctx, result)
+	if err != nil {
+		h.logger.Error().Err(err).Msgf("Failed to list %s for API request", kind.resource)
+		h.writeUpstreamError(ctx, err)
+		return
+	}
+
+	if cached {
+		ctx.Response.Header.Set(HeaderCacheAge, strconv.FormatInt(time.Since(updated).Milliseconds(), 10))
+	}
+	sortItems(items, meta, query)
+	pageItems, next := page(items, query)
+	h.writeJSON(ctx, fasthttp.StatusOK, resourceList[T]{
+		Kind:       kind.kind,
+		APIVersion: kind.apiVersion,
+		Items:      pageItems,
+		Count:      len(pageItems),
+		Total:      len(items),
+		Continue:   next,
+	})
+}
+
+// listDeployments handles GET /api/v1/deployments. Lists served from the read cache are tagged with
+// the version of the cached deployments, so polling clients sending If-None-Match receive
+// 304 Not Modified until a deployment of the list changes.
+func (h *apiHandler) listDeployments(ctx *fasthttp.RequestCtx) {
+	opts := k8s.ListDeploymentsOptions{
+		Namespace:     string(ctx.QueryArgs().Peek("namespace")),
+		LabelSelector: string(ctx.QueryArgs().Peek("labelSelector")),
+	}
+	// The version is taken before listing: should the cache change in between, the ETag is stale and
+	// the next request gets the full body again, rather than a 304 for a body never sent.
+	var etag string
+	if h.client != nil {
+		if version, ok := h.client.CachedDeploymentsVersion(opts); ok {
+			etag = weakETag(version)
+		}
+	}
+	if notModified(ctx, etag) {
+		return
+	}
+
+	serveList(h, ctx, deploymentListKind, deploymentMeta,
+		func(client *k8s.Client, reqCtx context.Context) ([]k8s.DeploymentInfo, error) {
+			return client.ListDeployments(reqCtx, opts)
+		})
+	if etag != "" && ctx.Response.StatusCode() == fasthttp.StatusOK {
+		ctx.Response.Header.Set(fasthttp.HeaderETag, etag)
+	}
+}
+
+// listPods handles GET /api/v1/pods.
+func (h *apiHandler) listPods(ctx *fasthttp.RequestCtx) {
+	namespace := string(ctx.QueryArgs().Peek("namespace"))
+	serveList(h, ctx, podListKind, podMeta, func(client *k8s.Client, reqCtx context.Context) ([]k8s.PodInfo, error) {
+		return client.ListPods(reqCtx, namespace)
+	})
+}
+
+// listServices handles GET /api/v1/services.
+func (h *apiHandler) listServices(ctx *fasthttp.RequestCtx) {
+	namespace := string(ctx.QueryArgs().Peek("namespace"))
+	serveList(h, ctx, serviceListKind, serviceMeta,
+		func(client *k8s.Client, reqCtx context.Context) ([]k8s.ServiceInfo, error) {
+			return client.ListServices(reqCtx, namespace)
+		})
+}
+
+// listNodes handles GET /api/v1/nodes.
+func (h *apiHandler) listNodes(ctx *fasthttp.RequestCtx) {
+	serveList(h, ctx, nodeListKind, nodeMeta, (*k8s.Client).ListNodes)
+}
+
+// listNamespaces handles GET /api/v1/namespaces.
+func (h *apiHandler) listNamespaces(ctx *fasthttp.RequestCtx) {
+	serveList(h, ctx, namespaceListKind, namespaceMeta, (*k8s.Client).ListNamespaces)
+}
+
+// deploymentMeta returns the sort fields of a deployment.
+func deploymentMeta(d k8s.DeploymentInfo) itemMeta {
+	return itemMeta{namespace: d.Namespace, name: d.Name, created: d.CreatedAt}
+}
+
+// podMeta returns the sort fields of a pod.
+func podMeta(p k8s.PodInfo) itemMeta {
+	return itemMeta{namespace: p.Namespace, name: p.Name, created: p.CreatedAt}
+}
+
+// serviceMeta returns the sort fields of a service.
+func serviceMeta(s k8s.ServiceInfo) itemMeta {
+	return itemMeta{namespace: s.Namespace, name: s.Name, created: s.CreatedAt}
+}
+
+// nodeMeta returns the sort fields of a node, which has no namespace.
+func nodeMeta(n k8s.NodeInfo) itemMeta {
+	return itemMeta{name: n.Name, created: n.CreatedAt}
+}
+
+// namespaceMeta returns the sort fields of a namespace.
+func namespaceMeta(n k8s.NamespaceInfo) itemMeta {
+	return itemMeta{name: n.Name, created: n.CreatedAt}
+}
@@ -0,0 +1,176 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the list endpoints of Kubernetes resources.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestListEndpoints tests the list endpoints of each kind with and without a client.
+func TestListEndpoints(t *testing.T) {
+	objects := append(k8s.DefaultDemoObjects(time.Now()),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "dns-1", Namespace: "kube-system"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "shop"}},
+	)
+	demoClient := k8s.NewFakeClient(zerolog.Nop(), objects...)
+
+	tests := []struct {
+		name           string
+		client         *k8s.Client
+		uri            string
+		expectedStatus int
+		expectedKind   string
+		expectedCount  int
+	}{
+		{"deployments", demoClient, "/api/v1/deployments", fasthttp.StatusOK, "DeploymentList", 5},
+		{"deployments in namespace", demoClient, "/api/v1/deployments?namespace=shop", fasthttp.StatusOK,
+			"DeploymentList", 3},
+		{"pods", demoClient, "/api/v1/pods", fasthttp.StatusOK, "PodList", 2},
+		{"pods in namespace", demoClient, "/api/v1/pods?namespace=shop", fasthttp.StatusOK, "PodList", 1},
+		{"services", demoClient, "/api/v1/services?namespace=default", fasthttp.StatusOK, "ServiceList", 0},
+		{"nodes", demoClient, "/api/v1/nodes", fasthttp.StatusOK, "NodeList", 1},
+		{"namespaces", demoClient, "/api/v1/namespaces", fasthttp.StatusOK, "NamespaceList", 3},
+		{"no client", nil, "/api/v1/pods", fasthttp.StatusServiceUnavailable, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Client: tt.client})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.uri)
+			ctx.Request.Header.SetMethod("GET")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, ctx.Response.StatusCode())
+			}
+			if tt.expectedStatus != fasthttp.StatusOK {
+				return
+			}
+
+			var list resourceList[json.RawMessage]
+			if err := json.Unmarshal(ctx.Response.Body(), &list); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if list.Kind != tt.expectedKind || list.Count != tt.expectedCount || len(list.Items) != tt.expectedCount {
+				t.Errorf("expected %d items of %s, got kind=%s count=%d items=%d",
+					tt.expectedCount, tt.expectedKind, list.Kind, list.Count, len(list.Items))
+			}
+			if list.Items == nil {
+				t.Error("expected items to be an array, got null")
+			}
+		})
+	}
+}
+
+// TestListEndpointsCacheAge verifies that lists carry the age of the read cache once they are served from it.
+func TestListEndpointsCacheAge(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	handler := createHandler(zerolog.Nop(), Options{Client: client})
+	cacheAge := func(uri string) string {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		handler(ctx)
+		if ctx.Response.StatusCode() != fasthttp.StatusOK {
+			t.Fatalf("expected status 200 for %s, got %d", uri, ctx.Response.StatusCode())
+		}
+		return string(ctx.Response.Header.Peek(HeaderCacheAge))
+	}
+
+	if age := cacheAge("/api/v1/pods"); age != "" {
+		t.Errorf("expected no cache age before the cache has synced, got %q", age)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := client.StartCache(ctx, 0); err != nil {
+		t.Fatalf("StartCache() error = %v", err)
+	}
+
+	for _, uri := range []string{"/api/v1/deployments", "/api/v1/pods?namespace=shop", "/api/v1/services",
+		"/api/v1/nodes", "/api/v1/namespaces"} {
+		t.Run(uri, func(t *testing.T) {
+			age, err := strconv.ParseInt(cacheAge(uri), 10, 64)
+			if err != nil || age < 0 {
+				t.Errorf("expected a cache age in milliseconds, got %d, %v", age, err)
+			}
+		})
+	}
+}
+
+// TestListEndpointPages verifies that the pages of a sorted list are walked with the continue tokens.
+func TestListEndpointPages(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	handler := createHandler(zerolog.Nop(), Options{Client: client})
+
+	var names []string
+	uri := "/api/v1/deployments?sortBy=name&order=desc&limit=2"
+	for pages := 1; ; pages++ {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		handler(ctx)
+
+		var list resourceList[k8s.DeploymentInfo]
+		if err := json.Unmarshal(ctx.Response.Body(), &list); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if list.Total != 5 || list.Count != len(list.Items) {
+			t.Fatalf("page %d: expected 5 deployments in total, got %+v", pages, list)
+		}
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+		if list.Continue == "" || pages == 5 {
+			break
+		}
+		uri = "/api/v1/deployments?sortBy=name&order=desc&limit=2&continue=" + list.Continue
+	}
+	if got := strings.Join(names, " "); got != "hello frontend coredns checkout cart" {
+		t.Errorf("unexpected deployments %q", got)
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/nodes?limit=-1")
+	handler(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid limit, got %d", ctx.Response.StatusCode())
+	}
+}
+
+// TestServeListItems verifies that the items of a kind are serialized with their own fields.
+func TestServeListItems(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(),
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "10.0.0.1",
+				Ports: []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP}}},
+		})
+	handler := createHandler(zerolog.Nop(), Options{Client: client})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/services")
+	handler(ctx)
+
+	var list resourceList[k8s.ServiceInfo]
+	if err := json.Unmarshal(ctx.Response.Body(), &list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if list.APIVersion != "v1" || len(list.Items) != 1 || list.Items[0].ClusterIP != "10.0.0.1" ||
+		len(list.Items[0].Ports) != 1 || list.Items[0].Ports[0] != "80/TCP" {
+		t.Errorf("unexpected list %+v", list)
+	}
+}
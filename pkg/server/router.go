@@ -0,0 +1,139 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the router dispatching requests by method and path pattern.
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// otherRoute is the route label of requests matching no route.
+const otherRoute = "other"
+
+// router dispatches requests to the handler registered for their method and path pattern. A pattern is a
+// path whose segments may be parameters: "{name}" matches a non-empty segment, and a trailing "{name...}"
+// matches the rest of the path, including nothing. The values of the parameters are stored in the request
+// user values of their names, see pathParam. Handlers for GET also serve HEAD. Patterns are matched in the
+// order they were registered.
+//
+// Paths matching a pattern only registered for other methods are answered with 405 Method Not Allowed and
+// the allowed methods, all others with 404 Not Found.
+type router struct {
+	routes     []route
+	writeError func(ctx *fasthttp.RequestCtx, status int, message string)
+}
+
+// route is a handler registered for a method and a path pattern.
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  fasthttp.RequestHandler
+}
+
+// pathParam is the value of a parameter matched in the path of a request.
+type pathParam struct {
+	name  string
+	value string
+}
+
+// newRouter creates a router without routes, answering unrouted requests with writeError.
+func newRouter(writeError func(ctx *fasthttp.RequestCtx, status int, message string)) *router {
+	return &router{writeError: writeError}
+}
+
+// handle registers handler for requests with method to paths matching pattern. It panics if the pattern
+// is invalid, since routes are registered on startup.
+func (r *router) handle(method, pattern string, handler fasthttp.RequestHandler) {
+	if !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("route pattern %q must start with /", pattern))
+	}
+	segments := strings.Split(pattern[1:], "/")
+	for i, segment := range segments {
+		if strings.HasSuffix(segment, "...}") && i != len(segments)-1 {
+			panic(fmt.Sprintf("route pattern %q has a wildcard before its last segment", pattern))
+		}
+	}
+	r.routes = append(r.routes, route{method: method, pattern: pattern, segments: segments, handler: handler})
+}
+
+// serve dispatches a request to the handler of the first route matching it.
+func (r *router) serve(ctx *fasthttp.RequestCtx) {
+	path, method := string(ctx.Path()), string(ctx.Method())
+	var allowed []string
+	for _, rt := range r.routes {
+		params, ok := rt.match(path)
+		if !ok {
+			continue
+		}
+		if rt.method == method || (method == fasthttp.MethodHead && rt.method == fasthttp.MethodGet) {
+			for _, param := range params {
+				ctx.SetUserValue(param.name, param.value)
+			}
+			rt.handler(ctx)
+			return
+		}
+		allowed = append(allowed, rt.method)
+		if rt.method == fasthttp.MethodGet {
+			allowed = append(allowed, fasthttp.MethodHead)
+		}
+	}
+
+	if len(allowed) == 0 {
+		r.writeError(ctx, fasthttp.StatusNotFound, fmt.Sprintf("no endpoint serves %s", path))
+		return
+	}
+	slices.Sort(allowed)
+	allow := strings.Join(slices.Compact(allowed), ", ")
+	ctx.Response.Header.Set(fasthttp.HeaderAllow, allow)
+	r.writeError(ctx, fasthttp.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed, use %s", method, allow))
+}
+
+// routeLabel returns the pattern of the first route matching path, whatever its method, or otherRoute.
+// Unlike paths, patterns are bounded, so they can label metrics.
+func (r *router) routeLabel(path string) string {
+	for _, rt := range r.routes {
+		if _, ok := rt.match(path); ok {
+			return rt.pattern
+		}
+	}
+	return otherRoute
+}
+
+// match reports whether path matches the pattern of the route, and returns the values of its parameters.
+func (rt route) match(path string) ([]pathParam, bool) {
+	rest, ok := strings.CutPrefix(path, "/")
+	if !ok {
+		return nil, false
+	}
+	var params []pathParam
+	for i, segment := range rt.segments {
+		if name, ok := strings.CutSuffix(segment, "...}"); ok && strings.HasPrefix(name, "{") {
+			return append(params, pathParam{name: name[1:], value: rest}), true
+		}
+		value, next, found := strings.Cut(rest, "/")
+		if found == (i == len(rt.segments)-1) {
+			// The path has more segments than the pattern, or fewer.
+			return nil, false
+		}
+		rest = next
+		if name, ok := strings.CutPrefix(segment, "{"); ok && strings.HasSuffix(name, "}") {
+			if value == "" {
+				return nil, false
+			}
+			params = append(params, pathParam{name: strings.TrimSuffix(name, "}"), value: value})
+		} else if value != segment {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// pathParamValue returns the value of the path parameter name of a routed request, or "" if there is none.
+func pathParamValue(ctx *fasthttp.RequestCtx, name string) string {
+	value, _ := ctx.UserValue(name).(string)
+	return value
+}
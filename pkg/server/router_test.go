@@ -0,0 +1,117 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the router.
+package server
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TestRouteMatch verifies matching of paths against route patterns and the extraction of path parameters.
+func TestRouteMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    []pathParam
+		wantOK  bool
+	}{
+		{"/", "/", nil, true},
+		{"/", "/livez", nil, false},
+		{"/livez", "/livez", nil, true},
+		{"/livez", "/livez/", nil, false},
+		{deploymentsPattern, "/api/v1/namespaces/shop/deployments", []pathParam{{"namespace", "shop"}}, true},
+		{deploymentPattern, "/api/v1/namespaces/shop/deployments/cart",
+			[]pathParam{{"namespace", "shop"}, {"name", "cart"}}, true},
+		{deploymentPattern, "/api/v1/namespaces/shop/deployments/", nil, false},
+		{deploymentPattern, "/api/v1/namespaces/shop/services/cart", nil, false},
+		{deploymentsPattern, "/api/v1/namespaces//deployments", nil, false},
+		{deploymentPattern, "/api/v1/namespaces/shop/deployments/cart/scale", nil, false},
+		{deploymentsPattern, "/api/v1/namespaces", nil, false},
+		{"/debug/pprof/{profile...}", "/debug/pprof/", []pathParam{{"profile", ""}}, true},
+		{"/debug/pprof/{profile...}", "/debug/pprof/heap", []pathParam{{"profile", "heap"}}, true},
+		{"/debug/pprof/{profile...}", "/debug/pprof", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			routes := newRouter(nil)
+			routes.handle(fasthttp.MethodGet, tt.pattern, nil)
+			params, ok := routes.routes[0].match(tt.path)
+			if ok != tt.wantOK || !slices.Equal(params, tt.want) {
+				t.Errorf("match(%q) = %v, %v, want %v, %v", tt.path, params, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestRouterServe verifies that requests are dispatched by method and path, with their path parameters,
+// and that other requests are answered with 405 or 404.
+func TestRouterServe(t *testing.T) {
+	routes := newRouter(func(ctx *fasthttp.RequestCtx, status int, message string) {
+		ctx.SetStatusCode(status)
+		ctx.SetBodyString(message)
+	})
+	reply := func(body string) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			ctx.SetBodyString(body + " " + pathParamValue(ctx, "namespace") + " " + pathParamValue(ctx, "name"))
+		}
+	}
+	routes.handle(fasthttp.MethodPost, deploymentsPattern, reply("create"))
+	routes.handle(fasthttp.MethodPut, deploymentPattern, reply("replace"))
+	routes.handle(fasthttp.MethodDelete, deploymentPattern, reply("delete"))
+	routes.handle(fasthttp.MethodGet, "/livez", reply("livez"))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantAllow  string
+	}{
+		{"create", fasthttp.MethodPost, "/api/v1/namespaces/shop/deployments", 200, "create shop ", ""},
+		{"replace", fasthttp.MethodPut, "/api/v1/namespaces/shop/deployments/web", 200, "replace shop web", ""},
+		{"delete", fasthttp.MethodDelete, "/api/v1/namespaces/shop/deployments/web", 200, "delete shop web", ""},
+		{"head", fasthttp.MethodHead, "/livez", 200, "livez  ", ""},
+		{"method not allowed", fasthttp.MethodGet, "/api/v1/namespaces/shop/deployments/web", 405,
+			"method GET not allowed, use DELETE, PUT", "DELETE, PUT"},
+		{"method not allowed with HEAD", fasthttp.MethodPost, "/livez", 405,
+			"method POST not allowed, use GET, HEAD", "GET, HEAD"},
+		{"not found", fasthttp.MethodGet, "/unknown", 404, "no endpoint serves /unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI(tt.path)
+			ctx.Request.Header.SetMethod(tt.method)
+			routes.serve(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, ctx.Response.StatusCode())
+			}
+			if body := string(ctx.Response.Body()); body != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, body)
+			}
+			if allow := string(ctx.Response.Header.Peek(fasthttp.HeaderAllow)); allow != tt.wantAllow {
+				t.Errorf("expected Allow %q, got %q", tt.wantAllow, allow)
+			}
+		})
+	}
+}
+
+// TestRouterHandleInvalidPattern verifies that invalid patterns are rejected on registration.
+func TestRouterHandleInvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"livez", "/debug/{path...}/profile"} {
+		t.Run(pattern, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("handle(%q) did not panic", pattern)
+				}
+			}()
+			newRouter(nil).handle(fasthttp.MethodGet, pattern, nil)
+		})
+	}
+}
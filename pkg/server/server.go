@@ -1,56 +1,357 @@
 // Package server provides HTTP server functionality for the k8s-controller application.
-// It implements a FastHTTP-based server with health check endpoints and structured logging.
+// It implements a FastHTTP-based server, optionally served by net/http for HTTP/2, with health check
+// endpoints and structured logging.
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/fs"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+
+	"github.com/Searge/k8s-controller/pkg/buildinfo"
+	"github.com/Searge/k8s-controller/pkg/hooks"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+	"github.com/Searge/k8s-controller/pkg/metrics"
+	"github.com/Searge/k8s-controller/pkg/startup"
 )
 
-// createHandler creates an HTTP handler function with the application's routing logic.
-// It accepts a zerolog.Logger for structured logging of HTTP requests and errors.
-// The handler supports the following endpoints:
-//   - GET /health: Returns a JSON health status response
-//   - GET /*: Returns a default greeting message for all other paths
-func createHandler(logger zerolog.Logger) func(ctx *fasthttp.RequestCtx) {
-	return func(ctx *fasthttp.RequestCtx) {
-		path := string(ctx.Path())
-
-		logger.Info().Msgf("Request: %s %s", ctx.Method(), path)
-
-		switch path {
-		case "/health":
-			ctx.SetStatusCode(200)
-			ctx.SetContentType("application/json")
-			if _, err := fmt.Fprintf(ctx, `{"status":"ok"}`); err != nil {
-				logger.Error().Err(err).Msg("Failed to write health response")
-			}
-		default:
-			ctx.SetContentType("text/plain")
-			if _, err := fmt.Fprintf(ctx, "Hello from k8s-controller!"); err != nil {
-				logger.Error().Err(err).Msg("Failed to write response")
+// Options configures the HTTP server.
+type Options struct {
+	// Port is the TCP port number to bind the server to on all interfaces, unless Listen is set.
+	Port int
+
+	// Listen, if set, is the address to listen on instead of Port: a TCP address such as
+	// "127.0.0.1:8080", a Unix domain socket such as "unix:///var/run/kc.sock", e.g. for sidecars,
+	// or "systemd:" for a socket passed by systemd socket activation.
+	Listen string
+
+	// GRPCListen, if set, is the address the gRPC cluster service of package apiv1 is served on alongside
+	// HTTP, in any form of Listen, e.g. ":9090" or "systemd:grpc".
+	GRPCListen string
+
+	// SocketMode is the file mode of the Unix domain sockets of Listen and GRPCListen. Zero uses
+	// DefaultSocketMode.
+	SocketMode fs.FileMode
+
+	// Client is used by the Kubernetes API endpoints.
+	// If nil, those endpoints respond with 503 Service Unavailable.
+	Client *k8s.Client
+
+	// Budget bounds upstream Kubernetes API calls per request. Zero values use defaults.
+	Budget RetryBudget
+
+	// TLSConfig, if set, makes the server serve HTTPS. Its GetCertificate callback
+	// allows rotated certificates to be picked up without a restart.
+	TLSConfig *tls.Config
+
+	// Startup tracks staged initialization progress reported by /startupz. /readyz also uses it to
+	// wait for the informer caches. If nil, /startupz always reports the server as started.
+	Startup *startup.Tracker
+
+	// Build is the build metadata of the server reported by /version.
+	Build buildinfo.Info
+
+	// EnablePprof serves the pprof profiles on /debug/pprof/ and the expvar variables on /debug/vars.
+	// They expose internals of the process, so they should only be enabled on trusted networks.
+	EnablePprof bool
+
+	// EnableSwaggerUI serves the Swagger UI on /docs, exploring the OpenAPI document served on /openapi.json.
+	// The page loads the Swagger UI from a CDN, so the browser needs to reach it.
+	EnableSwaggerUI bool
+
+	// EnableWriteAPI serves the endpoints creating, replacing and deleting deployments. They are only
+	// served to callers authenticated by Authenticator, which is required with EnableWriteAPI.
+	EnableWriteAPI bool
+
+	// EnableServiceProxy proxies requests on /proxy/namespaces/{namespace}/services/{service}/ to
+	// in-cluster services with the credentials of Client, like kubectl proxy. They are only served to
+	// callers authenticated by Authenticator, which is required with EnableServiceProxy.
+	EnableServiceProxy bool
+
+	// Hooks runs the pipelines of the hooks triggered on /hooks/{name}. If nil, no hooks are served.
+	Hooks *hooks.Dispatcher
+
+	// Authenticator authenticates the callers of the write endpoints and of the service proxy, and with
+	// RequireAuth of all others but OpenPaths.
+	Authenticator Authenticator
+
+	// RequireAuth only serves requests authenticated by Authenticator, which is then required,
+	// except for the OpenPaths.
+	RequireAuth bool
+
+	// OpenPaths are served without authentication with RequireAuth, e.g. DefaultOpenPaths for
+	// kubelet probes and metrics scrapers.
+	OpenPaths []string
+
+	// AccessLogSampling logs one in this many successful requests to the probe and metrics endpoints,
+	// which are polled frequently; other and failed requests are always logged. Values below 1 use
+	// DefaultAccessLogSampling.
+	AccessLogSampling int
+
+	// Metrics receives request counts and durations. If it is scraped (metrics.Exposer),
+	// it is served on /metrics. If nil, no metrics are recorded.
+	Metrics metrics.Backend
+
+	// Limits tunes the timeouts and resource limits of the server. Zero values use defaults.
+	Limits Limits
+
+	// Backend names the HTTP server implementation, BackendFastHTTP or BackendNetHTTP for HTTP/2.
+	// Empty uses BackendFastHTTP.
+	Backend string
+
+	// EnableCompression compresses responses with brotli or gzip for clients accepting it.
+	EnableCompression bool
+
+	// Middlewares wrap the routes with further behaviors, in order, inside the built-in middlewares
+	// logging, recovering, rate limiting, authenticating and compressing requests.
+	Middlewares []Middleware
+
+	// ShutdownGracePeriod bounds how long in-flight requests are drained on shutdown. Zero uses
+	// DefaultShutdownGracePeriod.
+	ShutdownGracePeriod time.Duration
+}
+
+// DefaultShutdownGracePeriod drains in-flight requests within the 30 seconds Kubernetes waits by default
+// before killing a terminating pod.
+const DefaultShutdownGracePeriod = 20 * time.Second
+
+// DefaultOpenPaths are the probe and metrics endpoints, which kubelets and scrapers call without
+// credentials, and which reveal no cluster data.
+var DefaultOpenPaths = []string{"/livez", "/readyz", "/startupz", "/metrics"}
+
+// newHandler creates an HTTP handler function with the application's routes, see apiHandler.routes,
+// wrapped in the middleware pipeline of the server, see apiHandler.middlewares. It accepts a zerolog.Logger
+// for the access log and errors, and the server options holding the optional Kubernetes client and retry
+// budget. Open watch streams end when shutdown is done.
+func newHandler(shutdown context.Context, logger zerolog.Logger, opts Options) func(ctx *fasthttp.RequestCtx) {
+	api := newAPIHandler(opts.Client, opts.Budget.withDefaults(), logger)
+	api.shutdown = shutdown
+	routes := api.routes(opts)
+	return Chain(api.middlewares(opts, routes)...)(routes.serve)
+}
+
+// routes creates the router of the server with the following endpoints:
+//   - GET /livez: Returns 200 while the process is alive
+//   - GET /readyz: Returns 200 if the Kubernetes API is reachable and caches synced, 503 with reasons otherwise
+//   - GET /startupz: Returns staged startup progress as JSON (503 until started)
+//   - GET /version: Returns the build metadata and the Kubernetes version as JSON
+//   - GET /healthz/detail: Returns uptime, version, goroutines, cache and API server state and the health of
+//     each subsystem as JSON
+//   - GET /api/v1/deployments: Returns deployments as JSON (?namespace=, ?labelSelector=)
+//   - GET /api/v1/watch/deployments: Streams deployment changes as server-sent events (?namespace=,
+//     ?labelSelector=), resumed from the Last-Event-ID header
+//   - GET /api/v1/pods, /api/v1/services: Return pods or services as JSON (?namespace=)
+//   - GET /api/v1/nodes, /api/v1/namespaces: Return nodes or namespaces as JSON
+//   - POST /api/v1/namespaces/{namespace}/deployments, PUT and DELETE .../deployments/{name}: Create,
+//     replace and delete deployments, if the write API is enabled and the caller authenticated
+//   - GET /api/v1/limits: Returns the upstream timeout and retry budget as JSON
+//   - GET /api/v1/reports/{name}/export: Streams a report as CSV or JSON lines (?format=csv|jsonl)
+//   - GET /api/v2/*: Serve the REST mapping of the gRPC cluster service, see apiHandler.gateway
+//   - * /proxy/namespaces/{namespace}/services/{service}/{path...}: Proxies requests to an in-cluster service,
+//     if the service proxy is enabled, see apiHandler.proxyService
+//   - POST /hooks/{name}: Runs the pipeline of a hook for a signed webhook delivery, see apiHandler.receiveHook
+//   - GET /admin/loglevel, PUT /admin/loglevel: Return or change the log level at runtime as JSON; changes
+//     always require authentication
+//   - GET /metrics: Returns metrics in the Prometheus text format, if the metrics backend is scraped
+//   - GET /debug/pprof/*, /debug/vars: Serve runtime profiles and expvar variables, if pprof is enabled
+//   - GET /openapi.json: Returns the OpenAPI document of the endpoints above
+//   - GET /docs: Serves the Swagger UI exploring the OpenAPI document, if enabled
+//   - GET /: Returns a greeting message
+//
+// Other paths respond 404 Not Found, and other methods 405 Method Not Allowed.
+func (h *apiHandler) routes(opts Options) *router {
+	routes := newRouter(h.writeError)
+	routes.handle(fasthttp.MethodGet, "/livez", h.livez)
+	routes.handle(fasthttp.MethodGet, "/readyz", func(ctx *fasthttp.RequestCtx) { h.readyz(ctx, opts.Startup) })
+	routes.handle(fasthttp.MethodGet, "/startupz", func(ctx *fasthttp.RequestCtx) { h.startupz(ctx, opts.Startup) })
+	routes.handle(fasthttp.MethodGet, "/version", func(ctx *fasthttp.RequestCtx) { h.version(ctx, opts.Build) })
+	routes.handle(fasthttp.MethodGet, "/healthz/detail", func(ctx *fasthttp.RequestCtx) {
+		h.healthDetail(ctx, opts.Startup, opts.Build)
+	})
+	routes.handle(fasthttp.MethodGet, "/api/v1/deployments", h.listDeployments)
+	routes.handle(fasthttp.MethodGet, "/api/v1/watch/deployments", h.watchDeployments)
+	routes.handle(fasthttp.MethodGet, "/api/v1/pods", h.listPods)
+	routes.handle(fasthttp.MethodGet, "/api/v1/services", h.listServices)
+	routes.handle(fasthttp.MethodGet, "/api/v1/nodes", h.listNodes)
+	routes.handle(fasthttp.MethodGet, "/api/v1/namespaces", h.listNamespaces)
+	routes.handle(fasthttp.MethodGet, "/api/v1/limits", h.limits)
+	routes.handle(fasthttp.MethodGet, reportsPathPrefix+"{name}/export", func(ctx *fasthttp.RequestCtx) {
+		h.exportReport(ctx, pathParamValue(ctx, "name"))
+	})
+	routes.handle(fasthttp.MethodGet, gatewayPattern, h.gateway(opts))
+
+	writeAPI := func(write func(ctx *fasthttp.RequestCtx, ns, name string)) fasthttp.RequestHandler {
+		if !opts.EnableWriteAPI {
+			return func(ctx *fasthttp.RequestCtx) {
+				h.writeError(ctx, fasthttp.StatusForbidden,
+					"the write API is disabled, start the server with --enable-write-api")
 			}
 		}
+		return h.requireAuth(opts.Authenticator, h.writeDeployment(write))
+	}
+	routes.handle(fasthttp.MethodPost, deploymentsPattern, writeAPI(func(ctx *fasthttp.RequestCtx, ns, _ string) {
+		h.createDeployment(ctx, ns)
+	}))
+	routes.handle(fasthttp.MethodPut, deploymentPattern, writeAPI(h.replaceDeployment))
+	routes.handle(fasthttp.MethodDelete, deploymentPattern, writeAPI(h.deleteDeployment))
+
+	proxy := func(ctx *fasthttp.RequestCtx) {
+		h.writeError(ctx, fasthttp.StatusForbidden,
+			"the service proxy is disabled, start the server with --enable-service-proxy")
+	}
+	if opts.EnableServiceProxy {
+		proxy = h.requireAuth(opts.Authenticator, h.proxyService)
+	}
+	for _, method := range serviceProxyMethods {
+		routes.handle(method, serviceProxyPattern, proxy)
+		routes.handle(method, serviceProxyPathPattern, proxy)
 	}
+
+	routes.handle(fasthttp.MethodPost, hookPattern, h.receiveHook(opts.Hooks))
+
+	routes.handle(fasthttp.MethodGet, logLevelPath, h.getLogLevel)
+	routes.handle(fasthttp.MethodPut, logLevelPath, h.requireAuth(opts.Authenticator, h.setLogLevel))
+
+	if exposer, ok := opts.Metrics.(metrics.Exposer); ok {
+		routes.handle(fasthttp.MethodGet, "/metrics", func(ctx *fasthttp.RequestCtx) {
+			serveMetrics(ctx, exposer, h.logger)
+		})
+	}
+	if opts.EnablePprof {
+		handleDebug(routes)
+	}
+
+	routes.handle(fasthttp.MethodGet, openAPIPath, serveOpenAPI(opts))
+	if opts.EnableSwaggerUI {
+		routes.handle(fasthttp.MethodGet, swaggerUIPath, serveSwaggerUI)
+	}
+
+	routes.handle(fasthttp.MethodGet, "/", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("text/plain")
+		if _, err := fmt.Fprintf(ctx, "Hello from k8s-controller!"); err != nil {
+			h.logger.Error().Err(err).Msg("Failed to write response")
+		}
+	})
+	return routes
 }
 
-// Start starts the HTTP server on the specified port.
+// Start starts the HTTP server with the given options, and the gRPC server with GRPCListen.
 // It creates a FastHTTP server with the application's handler and begins listening
-// for incoming requests. The function blocks until the server encounters an error.
+// for incoming requests. The function blocks until a server encounters an error or ctx is done.
+// It then shuts the servers down gracefully: they stop accepting connections, end the watch streams
+// and wait up to the ShutdownGracePeriod for in-flight requests to complete.
 //
 // Parameters:
-//   - port: The TCP port number to bind the server to
+//   - ctx: Context whose cancellation shuts the server down, e.g. on SIGTERM
+//   - opts: Server options, including the TCP port and the optional Kubernetes client
 //   - logger: A zerolog.Logger instance for structured logging
 //
-// Returns an error if the server fails to start or encounters a runtime error.
-func Start(port int, logger zerolog.Logger) error {
-	addr := fmt.Sprintf(":%d", port)
+// Returns an error if the server fails to start, encounters a runtime error or fails to drain
+// the in-flight requests in time, and nil after a graceful shutdown.
+func Start(ctx context.Context, opts Options, logger zerolog.Logger) error {
+	if opts.EnableWriteAPI && opts.Authenticator == nil {
+		return errors.New("the write API requires an authenticator")
+	}
+	if opts.EnableServiceProxy && opts.Authenticator == nil {
+		return errors.New("the service proxy requires an authenticator")
+	}
+	if opts.RequireAuth && opts.Authenticator == nil {
+		return errors.New("requiring authentication requires an authenticator")
+	}
+	addr := opts.listenAddress()
 
 	logger.Info().Msgf("Starting HTTP server on %s", addr)
 
-	handler := createHandler(logger)
+	handler := newHandler(ctx, logger, opts)
+	if opts.EnableWriteAPI {
+		logger.Warn().Msg("Serving the write API, authenticated callers can create, replace and delete deployments")
+	}
+	if opts.EnableServiceProxy {
+		logger.Warn().Msg("Serving the service proxy, authenticated callers can reach in-cluster services")
+	}
+	if opts.RequireAuth {
+		logger.Info().Strs("open_paths", opts.OpenPaths).Msg("Requiring authentication for the API")
+	}
+	if opts.EnablePprof {
+		logger.Warn().Msg("Serving pprof profiles on /debug/pprof/, only expose the server to trusted networks")
+		enableRuntimeProfiles()
+	}
+
+	srv, err := newBackend(opts.Backend, handler, opts.Limits.withDefaults())
+	if err != nil {
+		return err
+	}
+	ln, err := listen(addr, opts.SocketMode)
+	if err != nil {
+		return err
+	}
+	if opts.TLSConfig != nil {
+		logger.Info().Msg("Serving HTTPS")
+		ln = tls.NewListener(ln, alpnConfig(opts.TLSConfig, srv.ALPNProtocols()))
+	}
+
+	served := make(chan error, 2)
+	var grpcSrv *grpc.Server
+	if opts.GRPCListen != "" {
+		grpcLn, err := listen(opts.GRPCListen, opts.SocketMode)
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+		logger.Info().Msgf("Starting gRPC server on %s", opts.GRPCListen)
+		grpcSrv = newGRPCServer(ctx, logger, opts)
+		go func() {
+			served <- grpcSrv.Serve(grpcLn)
+		}()
+	}
+	go func() {
+		served <- srv.Serve(ln)
+	}()
 
-	return fasthttp.ListenAndServe(addr, handler)
+	select {
+	case err := <-served:
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+		return err
+	case <-ctx.Done():
+	}
+	defer func() {
+		// Closes the listener if Serve had not taken it over yet when ctx was done; the backend closes it otherwise.
+		_ = ln.Close()
+	}()
+	grpcStopped := make(chan error, 1)
+	go func() {
+		if grpcSrv == nil {
+			grpcStopped <- nil
+			return
+		}
+		grpcStopped <- shutdownGRPC(grpcSrv, opts.ShutdownGracePeriod, logger)
+	}()
+	err = shutdown(srv, opts.ShutdownGracePeriod, logger)
+	return errors.Join(err, <-grpcStopped)
+}
+
+// shutdown stops srv from accepting connections and waits up to gracePeriod for its in-flight
+// requests to complete.
+func shutdown(srv backend, gracePeriod time.Duration, logger zerolog.Logger) error {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultShutdownGracePeriod
+	}
+	logger.Info().Dur("grace_period", gracePeriod).Msg("Shutting down HTTP server, draining in-flight requests")
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		return fmt.Errorf("failed to drain in-flight requests within %s: %w", gracePeriod, err)
+	}
+	logger.Info().Msg("HTTP server stopped")
+	return nil
 }
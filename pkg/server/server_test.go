@@ -3,7 +3,10 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -14,11 +17,23 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttputil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	apiv1 "github.com/Searge/k8s-controller/pkg/api/v1"
+	"github.com/Searge/k8s-controller/pkg/k8s"
 )
 
 // HelloMessage is the default message returned by the server.
 const HelloMessage = "Hello from k8s-controller!"
 
+// createHandler creates the handler of a server that is never shut down.
+func createHandler(logger zerolog.Logger, opts Options) func(ctx *fasthttp.RequestCtx) {
+	return newHandler(context.Background(), logger, opts)
+}
+
 // TestCreateHandler tests the HTTP request routing and response generation
 // for all supported endpoints. It directly tests the handler function
 // without network dependencies.
@@ -31,11 +46,11 @@ func TestCreateHandler(t *testing.T) {
 		expectedBody   string
 	}{
 		{
-			name:           "health endpoint GET",
-			path:           "/health",
+			name:           "livez endpoint GET",
+			path:           "/livez",
 			method:         "GET",
 			expectedStatus: 200,
-			expectedBody:   `{"status":"ok"}`,
+			expectedBody:   `{"status":"ok"}` + "\n",
 		},
 		{
 			name:           "root endpoint",
@@ -48,8 +63,15 @@ func TestCreateHandler(t *testing.T) {
 			name:           "unknown endpoint",
 			path:           "/unknown",
 			method:         "GET",
-			expectedStatus: 200,
-			expectedBody:   HelloMessage,
+			expectedStatus: 404,
+			expectedBody:   `{"error":"no endpoint serves /unknown"}` + "\n",
+		},
+		{
+			name:           "method not allowed",
+			path:           "/livez",
+			method:         "POST",
+			expectedStatus: 405,
+			expectedBody:   `{"error":"method POST not allowed, use GET, HEAD"}` + "\n",
 		},
 	}
 
@@ -60,7 +82,7 @@ func TestCreateHandler(t *testing.T) {
 			logger := zerolog.New(&logBuf).With().Timestamp().Logger()
 
 			// Create handler
-			handler := createHandler(logger)
+			handler := createHandler(logger, Options{})
 
 			// Create fasthttp context
 			ctx := &fasthttp.RequestCtx{}
@@ -83,7 +105,8 @@ func TestCreateHandler(t *testing.T) {
 
 			// Verify that request was logged
 			logOutput := logBuf.String()
-			expectedLogContent := fmt.Sprintf("%s %s", tt.method, tt.path)
+			expectedLogContent := fmt.Sprintf(`"method":%q,"path":%q,"status":%d`,
+				tt.method, tt.path, tt.expectedStatus)
 			if !strings.Contains(logOutput, expectedLogContent) {
 				t.Errorf("Expected log to contain %q, got %q", expectedLogContent, logOutput)
 			}
@@ -109,9 +132,11 @@ func TestStart(t *testing.T) {
 		logger := zerolog.New(&logBuf).With().Timestamp().Logger()
 
 		// Start server in goroutine
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 		errCh := make(chan error, 1)
 		go func() {
-			errCh <- Start(port, logger)
+			errCh <- Start(ctx, Options{Port: port}, logger)
 		}()
 
 		// Give server time to start
@@ -128,7 +153,7 @@ func TestStart(t *testing.T) {
 		defer fasthttp.ReleaseRequest(req)
 		defer fasthttp.ReleaseResponse(resp)
 
-		req.SetRequestURI(fmt.Sprintf("http://localhost:%d/health", port))
+		req.SetRequestURI(fmt.Sprintf("http://localhost:%d/livez", port))
 		req.Header.SetMethod("GET")
 
 		err = client.Do(req, resp)
@@ -154,7 +179,179 @@ func TestStart(t *testing.T) {
 		default:
 			// No error yet, which is expected
 		}
+
+		// Cancelling the context shuts the server down gracefully
+		cancel()
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Errorf("Start() after shutdown error = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Server did not shut down")
+		}
+		if _, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), time.Second); err == nil {
+			t.Error("Server still accepts connections after shutdown")
+		}
 	})
+
+	t.Run("write API without authenticator", func(t *testing.T) {
+		if err := Start(context.Background(), Options{Port: 1, EnableWriteAPI: true}, zerolog.Nop()); err == nil {
+			t.Error("expected an error enabling the write API without an authenticator")
+		}
+	})
+
+	t.Run("shutdown ends watch streams", func(t *testing.T) {
+		port := freePort(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- Start(ctx, Options{Port: port, Client: k8s.NewFakeClient(zerolog.Nop()),
+				ShutdownGracePeriod: time.Minute}, zerolog.Nop())
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+		if _, err := fmt.Fprint(conn, "GET /api/v1/watch/deployments HTTP/1.1\r\nHost: localhost\r\n\r\n"); err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		reader := bufio.NewReader(conn)
+		if line, err := reader.ReadString('\n'); err != nil || !strings.Contains(line, "200") {
+			t.Fatalf("Failed to open the stream: %q, %v", line, err)
+		}
+
+		// The stream ends on shutdown instead of holding the server for the grace period.
+		cancel()
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Errorf("Start() after shutdown error = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Server did not shut down while a watch stream was open")
+		}
+	})
+
+	t.Run("gRPC alongside HTTP", func(t *testing.T) {
+		grpcAddr := fmt.Sprintf("127.0.0.1:%d", freePort(t))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- Start(ctx, Options{Port: freePort(t), GRPCListen: grpcAddr,
+				Client: k8s.NewFakeClient(zerolog.Nop()), ShutdownGracePeriod: time.Minute}, zerolog.Nop())
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("Failed to create the gRPC client: %v", err)
+		}
+		defer func() { _ = conn.Close() }()
+		client := apiv1.NewClusterServiceClient(conn)
+		if _, err := client.ListDeployments(context.Background(), &apiv1.ListDeploymentsRequest{}); err != nil {
+			t.Fatalf("ListDeployments() error = %v", err)
+		}
+		stream, err := client.WatchDeployments(context.Background(), &apiv1.WatchDeploymentsRequest{})
+		if err != nil {
+			t.Fatalf("WatchDeployments() error = %v", err)
+		}
+
+		// The gRPC watch stream ends on shutdown too.
+		cancel()
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Errorf("Start() after shutdown error = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Server did not shut down while a gRPC watch stream was open")
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+			t.Errorf("expected the stream to end with Unavailable, got %v", err)
+		}
+	})
+}
+
+// TestShutdown verifies that in-flight requests are drained within the grace period.
+func TestShutdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		delay       time.Duration
+		gracePeriod time.Duration
+		wantErr     error
+	}{
+		{"drained", 50 * time.Millisecond, 5 * time.Second, nil},
+		{"grace period exceeded", 5 * time.Second, 50 * time.Millisecond, context.DeadlineExceeded},
+	}
+
+	for _, name := range Backends {
+		for _, tt := range tests {
+			t.Run(name+" "+tt.name, func(t *testing.T) {
+				started := make(chan struct{})
+				srv, err := newBackend(name, func(ctx *fasthttp.RequestCtx) {
+					close(started)
+					time.Sleep(tt.delay)
+					ctx.SetBodyString("done")
+				}, Limits{}.withDefaults())
+				if err != nil {
+					t.Fatalf("newBackend(%q) error = %v", name, err)
+				}
+				ln := listenLoopback(t)
+				go func() { _ = srv.Serve(ln) }()
+
+				client := &fasthttp.Client{Dial: func(string) (net.Conn, error) {
+					return fasthttp.Dial(ln.Addr().String())
+				}}
+				responded := make(chan string, 1)
+				go func() {
+					_, body, _ := client.Get(nil, "http://localhost/")
+					responded <- string(body)
+				}()
+				<-started
+
+				err = shutdown(srv, tt.gracePeriod, zerolog.Nop())
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("shutdown() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantErr == nil {
+					if body := <-responded; body != "done" {
+						t.Errorf("expected the in-flight request to complete, got %q", body)
+					}
+				}
+			})
+		}
+	}
+}
+
+// listenLoopback listens on a free port of the loopback interface. Unlike in-memory listeners, its
+// connections support the read deadlines net/http uses to abort reads, e.g. while streaming.
+func listenLoopback(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	return ln
+}
+
+// freePort returns a TCP port that is free to listen on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to find available port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	if err := listener.Close(); err != nil {
+		t.Fatalf("Failed to close listener: %v", err)
+	}
+	return port
 }
 
 // testCase represents a single test case for server endpoint testing.
@@ -175,7 +372,7 @@ func setupInMemoryServer(t *testing.T) (*fasthttp.Client, func()) {
 	go func() {
 		// Create a test logger that writes to stderr
 		logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
-		handler := createHandler(logger)
+		handler := createHandler(logger, Options{})
 		if err := fasthttp.Serve(ln, handler); err != nil {
 			t.Errorf("Failed to serve: %v", err)
 		}
@@ -236,10 +433,10 @@ func executeTestRequest(t *testing.T, client *fasthttp.Client, tc testCase) {
 func TestServerHandlers(t *testing.T) {
 	tests := []testCase{
 		{
-			name:           "health endpoint",
-			path:           "/health",
+			name:           "livez endpoint",
+			path:           "/livez",
 			expectedStatus: 200,
-			expectedBody:   `{"status":"ok"}`,
+			expectedBody:   `{"status":"ok"}` + "\n",
 		},
 		{
 			name:           "default endpoint",
@@ -250,8 +447,8 @@ func TestServerHandlers(t *testing.T) {
 		{
 			name:           "unknown endpoint",
 			path:           "/unknown",
-			expectedStatus: 200,
-			expectedBody:   HelloMessage,
+			expectedStatus: 404,
+			expectedBody:   `{"error":"no endpoint serves /unknown"}` + "\n",
 		},
 	}
 
@@ -272,10 +469,12 @@ func TestServerHandlers(t *testing.T) {
 // a logger and port configuration.
 func ExampleStart() {
 	// This example shows how to start the server
-	// Note: In real usage, this would block until the server stops
+	// Note: In real usage, this would block until the server is shut down
 
-	// Start server on port 8080
-	// err := Start(8080, logger)
+	// Start server on port 8080 until SIGINT or SIGTERM
+	// ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	// defer stop()
+	// err := Start(ctx, Options{Port: 8080}, logger)
 	// if err != nil {
 	//     log.Fatal(err)
 	// }
@@ -0,0 +1,130 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the endpoint proxying requests to in-cluster services.
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Path prefix and route patterns of the service proxy, for the root of a service and for paths on it.
+const (
+	serviceProxyPathPrefix  = "/proxy/"
+	serviceProxyPattern     = serviceProxyPathPrefix + "namespaces/{namespace}/services/{service}"
+	serviceProxyPathPattern = serviceProxyPattern + "/{path...}"
+)
+
+// serviceProxyTimeout bounds writing the response of a proxied request, e.g. a long poll or an event
+// stream of the service, in place of the write timeout of the server.
+const serviceProxyTimeout = time.Hour
+
+// serviceProxyMethods are the methods proxied to services; HEAD requests are routed with GET.
+var serviceProxyMethods = []string{
+	fasthttp.MethodGet, fasthttp.MethodPost, fasthttp.MethodPut, fasthttp.MethodPatch,
+	fasthttp.MethodDelete, fasthttp.MethodOptions,
+}
+
+// proxyService handles /proxy/namespaces/{namespace}/services/{service}/{path...}, forwarding the request to
+// the service through the services/proxy subresource of the API server, with the credentials of the client,
+// like kubectl proxy does. The service is named "name", "name:port" or "scheme:name:port". It is only routed
+// with the service proxy enabled, behind requireAuth.
+//
+// The response of the service is streamed back until it ends, the server shuts down or serviceProxyTimeout
+// elapses; only its headers have to arrive within the upstream timeout. Redirects of the service are
+// rewritten to the proxy's paths.
+func (h *apiHandler) proxyService(ctx *fasthttp.RequestCtx) {
+	if h.client == nil {
+		h.writeError(ctx, fasthttp.StatusServiceUnavailable, "kubernetes client not configured")
+		return
+	}
+	ns, service := pathParamValue(ctx, "namespace"), pathParamValue(ctx, "service")
+
+	header := http.Header{}
+	for name, value := range ctx.Request.Header.All() {
+		header.Add(string(name), string(value))
+	}
+
+	reqCtx, cancel := context.WithCancel(withRequestSpan(h.shutdown, ctx))
+	timer := time.AfterFunc(h.budget.Timeout, cancel)
+	resp, err := h.client.ProxyService(reqCtx, k8s.ServiceProxyRequest{
+		Method:    string(ctx.Method()),
+		Namespace: ns,
+		Service:   service,
+		Path:      pathParamValue(ctx, "path"),
+		RawQuery:  string(ctx.QueryArgs().QueryString()),
+		Header:    header,
+		Body:      ctx.Request.Body(),
+	})
+	if !timer.Stop() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		h.writeError(ctx, fasthttp.StatusGatewayTimeout,
+			fmt.Sprintf("service %s/%s did not respond within %s", ns, service, h.budget.Timeout))
+		return
+	}
+	if err != nil {
+		cancel()
+		h.logger.Error().Err(err).Str("namespace", ns).Str("service", service).Msg("Failed to proxy request to service")
+		h.writeUpstreamError(ctx, err)
+		return
+	}
+
+	h.logger.Debug().Str("namespace", ns).Str("service", service).Str("caller", requestCaller(ctx)).
+		Int("status", resp.StatusCode).Msg("Proxied request to service")
+	ctx.SetStatusCode(resp.StatusCode)
+	for name, values := range resp.Header {
+		if name == "Content-Length" {
+			continue
+		}
+		for _, value := range values {
+			ctx.Response.Header.Add(name, value)
+		}
+	}
+	if location := resp.Header.Get("Location"); location != "" {
+		ctx.Response.Header.Set("Location", proxyLocation(location))
+	}
+	ctx.SetBodyStream(&cancelingBody{ReadCloser: resp.Body, cancel: cancel}, int(resp.ContentLength))
+}
+
+// proxyLocation rewrites a redirect location on the services/proxy subresource, as the API server
+// rewrites the redirects of services, to the matching path of the service proxy endpoint.
+func proxyLocation(location string) string {
+	rest, ok := strings.CutPrefix(location, "/api/v1/namespaces/")
+	if !ok {
+		return location
+	}
+	ns, rest, _ := strings.Cut(rest, "/")
+	rest, ok = strings.CutPrefix(rest, "services/")
+	if !ok {
+		return location
+	}
+	service, rest, _ := strings.Cut(rest, "/")
+	rest, ok = strings.CutPrefix(rest, "proxy")
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, "?")) {
+		return location
+	}
+	return "/proxy/namespaces/" + ns + "/services/" + service + rest
+}
+
+// cancelingBody is the body of a proxied response, which cancels the context of the proxied request
+// once it is closed after being streamed to the client.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the proxied request.
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,128 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the endpoint proxying requests to in-cluster services.
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestProxyService tests proxying requests to services through a fake API server: disabled, unauthenticated,
+// proxied, redirected and timed out requests.
+func TestProxyService(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/api/v1/namespaces/shop/services/cart:8080/proxy/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/api/v1/namespaces/shop/services/cart:8080/proxy/old":
+			w.Header().Set("Location", "/api/v1/namespaces/shop/services/cart:8080/proxy/new?page=2")
+			w.WriteHeader(http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Authorization", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+	}))
+	defer apiServer.Close()
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	client, err := k8s.CreateClient(k8s.ClientConfig{Server: apiServer.URL}, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("CreateClient() error = %v", err)
+	}
+
+	auth := NewTokenAuthenticator(map[string]string{"secret": "ci"})
+	enabled := Options{Client: client, EnableServiceProxy: true, Authenticator: auth,
+		Budget: RetryBudget{Timeout: 100 * time.Millisecond}}
+	tests := []struct {
+		name         string
+		opts         Options
+		method       string
+		uri          string
+		header       string
+		body         string
+		wantStatus   int
+		wantBody     string
+		wantLocation string
+	}{
+		{"disabled", Options{Client: client, Authenticator: auth}, fasthttp.MethodGet,
+			"/proxy/namespaces/shop/services/cart:8080/", "Bearer secret", "", fasthttp.StatusForbidden, "", ""},
+		{"unauthenticated", enabled, fasthttp.MethodGet, "/proxy/namespaces/shop/services/cart:8080/", "", "",
+			fasthttp.StatusUnauthorized, "", ""},
+		{"no client", Options{EnableServiceProxy: true, Authenticator: auth}, fasthttp.MethodGet,
+			"/proxy/namespaces/shop/services/cart:8080/", "Bearer secret", "",
+			fasthttp.StatusServiceUnavailable, "", ""},
+		{"get", enabled, fasthttp.MethodGet, "/proxy/namespaces/shop/services/cart:8080/metrics?format=text",
+			"Bearer secret", "", fasthttp.StatusTeapot,
+			"GET /api/v1/namespaces/shop/services/cart:8080/proxy/metrics?format=text ", ""},
+		{"service root", enabled, fasthttp.MethodGet, "/proxy/namespaces/shop/services/cart:8080", "Bearer secret", "",
+			fasthttp.StatusTeapot, "GET /api/v1/namespaces/shop/services/cart:8080/proxy/ ", ""},
+		{"post", enabled, fasthttp.MethodPost, "/proxy/namespaces/shop/services/cart:8080/api/items/",
+			"Bearer secret", `{"sku":"42"}`, fasthttp.StatusTeapot,
+			`POST /api/v1/namespaces/shop/services/cart:8080/proxy/api/items/ {"sku":"42"}`, ""},
+		{"redirect", enabled, fasthttp.MethodGet, "/proxy/namespaces/shop/services/cart:8080/old", "Bearer secret", "",
+			fasthttp.StatusMovedPermanently, "", "/proxy/namespaces/shop/services/cart:8080/new?page=2"},
+		{"timeout", enabled, fasthttp.MethodGet, "/proxy/namespaces/shop/services/cart:8080/slow", "Bearer secret", "",
+			fasthttp.StatusGatewayTimeout, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), tt.opts)
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.uri)
+			if tt.header != "" {
+				ctx.Request.Header.Set(fasthttp.HeaderAuthorization, tt.header)
+			}
+			ctx.Request.SetBodyString(tt.body)
+			handler(ctx)
+
+			body := string(ctx.Response.Body())
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, ctx.Response.StatusCode(), body)
+			}
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, body)
+			}
+			if got := string(ctx.Response.Header.Peek("Location")); got != tt.wantLocation {
+				t.Errorf("expected location %q, got %q", tt.wantLocation, got)
+			}
+			if got := ctx.Response.Header.Peek("X-Authorization"); len(got) > 0 {
+				t.Errorf("expected the caller's token not to be forwarded, got %q", got)
+			}
+		})
+	}
+}
+
+// TestProxyLocation tests rewriting redirects of services to the paths of the service proxy.
+func TestProxyLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{"/api/v1/namespaces/shop/services/cart/proxy/new", "/proxy/namespaces/shop/services/cart/new"},
+		{"/api/v1/namespaces/shop/services/cart/proxy?page=2", "/proxy/namespaces/shop/services/cart?page=2"},
+		{"/api/v1/namespaces/shop/services/cart/proxyfoo", "/api/v1/namespaces/shop/services/cart/proxyfoo"},
+		{"/api/v1/namespaces/shop/pods/cart/proxy/new", "/api/v1/namespaces/shop/pods/cart/proxy/new"},
+		{"https://example.com/login", "https://example.com/login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			if got := proxyLocation(tt.location); got != tt.want {
+				t.Errorf("proxyLocation(%q) = %q, want %q", tt.location, got, tt.want)
+			}
+		})
+	}
+}
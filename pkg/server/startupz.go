@@ -0,0 +1,26 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the startup probe endpoint.
+package server
+
+import (
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/startup"
+)
+
+// startupz handles GET /startupz. It responds 200 once every startup stage is done
+// or skipped and 503 while startup is in progress; the body always carries the
+// per-stage progress. Without a tracker the server reports itself as started.
+func (h *apiHandler) startupz(ctx *fasthttp.RequestCtx, tracker *startup.Tracker) {
+	if tracker == nil {
+		h.writeJSON(ctx, fasthttp.StatusOK, startup.Status{Started: true, Stages: []startup.StageStatus{}})
+		return
+	}
+
+	status := tracker.Status()
+	code := fasthttp.StatusOK
+	if !status.Started {
+		code = fasthttp.StatusServiceUnavailable
+	}
+	h.writeJSON(ctx, code, status)
+}
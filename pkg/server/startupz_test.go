@@ -0,0 +1,57 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the startup probe endpoint.
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/startup"
+)
+
+// TestStartupzEndpoint tests GET /startupz before, during and after startup.
+func TestStartupzEndpoint(t *testing.T) {
+	inProgress := startup.NewTracker(startup.DefaultStages...)
+	inProgress.Complete(startup.StageConfigLoaded)
+	inProgress.Progress(startup.StageK8sConnected, 0, 1)
+
+	started := startup.NewTracker(startup.StageConfigLoaded)
+	started.Complete(startup.StageConfigLoaded)
+
+	tests := []struct {
+		name           string
+		tracker        *startup.Tracker
+		expectedStatus int
+		expectedStage  startup.Stage
+	}{
+		{"no tracker", nil, fasthttp.StatusOK, ""},
+		{"in progress", inProgress, fasthttp.StatusServiceUnavailable, startup.StageK8sConnected},
+		{"started", started, fasthttp.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Startup: tt.tracker})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/startupz")
+			ctx.Request.Header.SetMethod("GET")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, ctx.Response.StatusCode())
+			}
+
+			var status startup.Status
+			if err := json.Unmarshal(ctx.Response.Body(), &status); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if status.Current != tt.expectedStage {
+				t.Errorf("expected current stage %q, got %q", tt.expectedStage, status.Current)
+			}
+		})
+	}
+}
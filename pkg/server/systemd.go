@@ -0,0 +1,69 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements listening on sockets passed by systemd socket activation.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SystemdScheme prefixes listen addresses of sockets passed by systemd socket activation: "systemd:" for
+// the first socket, "systemd:NAME" for the socket named NAME with FileDescriptorName= in the socket unit.
+const SystemdScheme = "systemd:"
+
+// listenFDsStart is the first file descriptor passed by systemd, following stdin, stdout and stderr.
+const listenFDsStart = 3
+
+// Environment variables describing the sockets passed by systemd, see sd_listen_fds(3).
+const (
+	envListenPID     = "LISTEN_PID"
+	envListenFDs     = "LISTEN_FDS"
+	envListenFDNames = "LISTEN_FDNAMES"
+)
+
+// systemdListener returns the listener of a socket systemd passed to the process as a file descriptor from
+// firstFD on: the first one, or the one named name if set. Like sd_listen_fds(3), it unsets the variables
+// describing the sockets and closes the descriptors, so that processes started by the server, e.g.
+// credential plugins, do not inherit them.
+func systemdListener(name string, firstFD int) (net.Listener, error) {
+	pid, count, names := os.Getenv(envListenPID), os.Getenv(envListenFDs), os.Getenv(envListenFDNames)
+	for _, env := range []string{envListenPID, envListenFDs, envListenFDNames} {
+		_ = os.Unsetenv(env)
+	}
+
+	if pid != strconv.Itoa(os.Getpid()) {
+		return nil, errors.New("no sockets passed by systemd socket activation: LISTEN_PID is not the server's")
+	}
+	fds, err := strconv.Atoi(count)
+	if err != nil || fds < 1 {
+		return nil, fmt.Errorf("no sockets passed by systemd socket activation: invalid LISTEN_FDS %q", count)
+	}
+	files := make([]*os.File, fds)
+	for i := range files {
+		files[i] = os.NewFile(uintptr(firstFD+i), "systemd socket "+strconv.Itoa(i))
+	}
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	index := 0
+	if name != "" {
+		index = slices.Index(strings.Split(names, ":"), name)
+		if index < 0 || index >= fds {
+			return nil, fmt.Errorf("no socket named %q passed by systemd socket activation, got %q", name, names)
+		}
+	}
+	// FileListener duplicates the descriptor, so the listener is not affected by closing the files.
+	ln, err := net.FileListener(files[index])
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on the socket passed by systemd: %w", err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,79 @@
+//go:build unix
+
+// Package server contains tests for the HTTP server functionality.
+// This file tests listening on sockets passed by systemd socket activation.
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// TestSystemdListener verifies that the socket passed by systemd is selected by name and that the
+// variables describing the sockets are checked and unset.
+func TestSystemdListener(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name    string
+		socket  string
+		pid     string
+		fds     string
+		fdNames string
+		wantErr bool
+	}{
+		{name: "first socket", pid: pid, fds: "1", fdNames: "web"},
+		{name: "named socket", socket: "web", pid: pid, fds: "1", fdNames: "web"},
+		{name: "unknown name", socket: "api", pid: pid, fds: "1", fdNames: "web", wantErr: true},
+		{name: "other process", pid: "1", fds: "1", wantErr: true},
+		{name: "no sockets", pid: pid, fds: "0", wantErr: true},
+		{name: "not activated", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcp, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = tcp.Close() }()
+			file, err := tcp.(*net.TCPListener).File()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// systemdListener takes over and closes the descriptor, so it must not be owned by file.
+			fd, err := syscall.Dup(int(file.Fd()))
+			_ = file.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(envListenPID, tt.pid)
+			t.Setenv(envListenFDs, tt.fds)
+			t.Setenv(envListenFDNames, tt.fdNames)
+
+			ln, err := systemdListener(tt.socket, fd)
+			for _, env := range []string{envListenPID, envListenFDs, envListenFDNames} {
+				if value, ok := os.LookupEnv(env); ok {
+					t.Errorf("%s = %q, expected it to be unset", env, value)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("systemdListener() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.pid != pid || tt.fds != "1" {
+				// The descriptor was not taken over, so it is still ours to close.
+				_ = syscall.Close(fd)
+			}
+			if err != nil {
+				return
+			}
+			defer func() { _ = ln.Close() }()
+
+			if ln.Addr().String() != tcp.Addr().String() {
+				t.Errorf("listener address = %s, want %s", ln.Addr(), tcp.Addr())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests serving HTTPS with a reloadable certificate.
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Searge/k8s-controller/pkg/certs"
+)
+
+// TestStartTLS verifies that the server serves HTTPS with the configured certificate, over HTTP/2 with the
+// net/http backend.
+func TestStartTLS(t *testing.T) {
+	dir := t.TempDir()
+	bundle, err := certs.Generate(certs.Options{DNSNames: []string{"localhost"}}, time.Now())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := bundle.WriteFiles(dir); err != nil {
+		t.Fatalf("WriteFiles() error = %v", err)
+	}
+	reloader, err := certs.NewReloader(dir, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(bundle.CA)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
+			ForceAttemptHTTP2: true,
+		},
+		Timeout: time.Second,
+	}
+
+	tests := []struct {
+		backend   string
+		wantProto string
+	}{
+		{BackendFastHTTP, "HTTP/1.1"},
+		{BackendNetHTTP, "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			port := freePort(t)
+			go func() {
+				_ = Start(t.Context(), Options{Port: port, TLSConfig: reloader.TLSConfig(), Backend: tt.backend},
+					zerolog.Nop())
+			}()
+			time.Sleep(50 * time.Millisecond)
+
+			resp, err := client.Get(fmt.Sprintf("https://localhost:%d/livez", port))
+			if err != nil {
+				t.Fatalf("HTTPS request failed: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK || string(body) != `{"status":"ok"}`+"\n" {
+				t.Errorf("unexpected response %d %q", resp.StatusCode, body)
+			}
+			if resp.Proto != tt.wantProto {
+				t.Errorf("expected protocol %s, got %s", tt.wantProto, resp.Proto)
+			}
+		})
+	}
+}
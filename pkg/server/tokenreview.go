@@ -0,0 +1,146 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements authentication and authorization of Kubernetes tokens by the cluster.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Timing and size of the token review cache. Decisions are cached briefly, so that a client sending
+// many requests costs few reviews, while revoked tokens and permissions take effect quickly.
+const (
+	tokenReviewTimeout   = 5 * time.Second
+	tokenReviewCacheTTL  = 30 * time.Second
+	tokenReviewCacheSize = 4096
+)
+
+// TokenReviewAuthenticator authenticates bearer tokens with TokenReviews of the cluster, and authorizes
+// the request of the token's user with a SubjectAccessReview of the request path as non-resource URL,
+// so that in-cluster clients can call the server with their ServiceAccount tokens and be granted
+// access by RBAC. The reviews are created with the server's own credentials, which need the
+// permissions of the system:auth-delegator ClusterRole.
+type TokenReviewAuthenticator struct {
+	client    *k8s.Client
+	audiences []string
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]tokenReviewEntry
+}
+
+// tokenReviewEntry is a cached authentication or authorization decision.
+type tokenReviewEntry struct {
+	identity k8s.Identity
+	err      error
+	expires  time.Time
+}
+
+// NewTokenReviewAuthenticator creates a TokenReviewAuthenticator reviewing tokens with client. If
+// audiences are given, tokens must be issued for one of them, e.g. with a projected ServiceAccount
+// token volume.
+func NewTokenReviewAuthenticator(client *k8s.Client, audiences []string) *TokenReviewAuthenticator {
+	return &TokenReviewAuthenticator{client: client, audiences: audiences, now: time.Now,
+		cache: make(map[string]tokenReviewEntry)}
+}
+
+// Authenticate returns the username of the request's bearer token if the cluster authenticates it and
+// authorizes its user to request the path with the verb of the method. Requests of users not allowed
+// fail with errForbidden.
+func (a *TokenReviewAuthenticator) Authenticate(ctx *fasthttp.RequestCtx) (string, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	tokenKey := fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+	identity, err := a.cached(tokenKey, func(reviewCtx context.Context) (k8s.Identity, error) {
+		return a.client.ReviewToken(reviewCtx, token, a.audiences)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	verb, path := requestVerb(string(ctx.Method())), string(ctx.Path())
+	_, err = a.cached(tokenKey+" "+verb+" "+path, func(reviewCtx context.Context) (k8s.Identity, error) {
+		result, err := a.client.ReviewSubjectAccess(reviewCtx, identity, verb, path)
+		switch {
+		case err != nil:
+			return k8s.Identity{}, err
+		case !result.Allowed:
+			return k8s.Identity{}, fmt.Errorf("%w: %s may not %s %s: %s",
+				errForbidden, identity.Username, verb, path, reasonOrUnknown(result.Reason))
+		}
+		return identity, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return identity.Username, nil
+}
+
+// cached returns the cached decision of key, or calls review and caches its decision. Failed reviews,
+// e.g. while the API server is unreachable, are not cached; rejected tokens and denied requests are.
+func (a *TokenReviewAuthenticator) cached(key string,
+	review func(context.Context) (k8s.Identity, error)) (k8s.Identity, error) {
+	now := a.now()
+	a.mu.Lock()
+	entry, ok := a.cache[key]
+	a.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.identity, entry.err
+	}
+
+	reviewCtx, cancel := context.WithTimeout(context.Background(), tokenReviewTimeout)
+	defer cancel()
+	identity, err := review(reviewCtx)
+	if err != nil && !errors.Is(err, k8s.ErrTokenNotAuthenticated) && !errors.Is(err, errForbidden) {
+		return k8s.Identity{}, fmt.Errorf("failed to review token: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.cache) >= tokenReviewCacheSize {
+		for cachedKey, cachedEntry := range a.cache {
+			if !now.Before(cachedEntry.expires) {
+				delete(a.cache, cachedKey)
+			}
+		}
+		if len(a.cache) >= tokenReviewCacheSize {
+			clear(a.cache)
+		}
+	}
+	a.cache[key] = tokenReviewEntry{identity: identity, err: err, expires: now.Add(tokenReviewCacheTTL)}
+	return identity, err
+}
+
+// requestVerb maps an HTTP method to the verb of a non-resource URL as the API server does, e.g.
+// POST to create.
+func requestVerb(method string) string {
+	switch method {
+	case fasthttp.MethodGet, fasthttp.MethodHead:
+		return "get"
+	case fasthttp.MethodPost:
+		return "create"
+	case fasthttp.MethodPut:
+		return "update"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// reasonOrUnknown returns the reason of an access review, which authorizers may leave empty.
+func reasonOrUnknown(reason string) string {
+	if reason == "" {
+		return "no reason given"
+	}
+	return reason
+}
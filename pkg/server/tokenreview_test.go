@@ -0,0 +1,146 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests authentication and authorization of Kubernetes tokens by the cluster.
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// testServiceAccount is the user the test token is authenticated as.
+const testServiceAccount = "system:serviceaccount:shop:dashboard"
+
+// newTokenReviewTestClient creates a client whose cluster authenticates the token "sa-token" and
+// allows its user to get /api/v1/deployments, counting the reviews. Reviews fail while down is set.
+func newTokenReviewTestClient(down *bool) (*k8s.Client, *int) {
+	client := k8s.NewFakeClient(zerolog.Nop())
+	clientset := client.GetClientset().(*fake.Clientset)
+	reviews := 0
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		if *down {
+			return true, nil, errors.New("connection refused")
+		}
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "sa-token" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: testServiceAccount}
+		}
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "subjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			reviews++
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			attrs := review.Spec.NonResourceAttributes
+			review.Status.Allowed = review.Spec.User == testServiceAccount && attrs.Verb == "get" &&
+				attrs.Path == "/api/v1/deployments"
+			return true, review, nil
+		})
+	return client, &reviews
+}
+
+// TestTokenReviewAuthenticator verifies the responses to authenticated, unauthenticated and
+// unauthorized requests.
+func TestTokenReviewAuthenticator(t *testing.T) {
+	down := false
+	client, _ := newTokenReviewTestClient(&down)
+	auth := NewTokenReviewAuthenticator(client, nil)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantCaller string
+	}{
+		{"allowed", fasthttp.MethodGet, "/api/v1/deployments", "sa-token", fasthttp.StatusOK, testServiceAccount},
+		{"other path", fasthttp.MethodGet, "/api/v1/nodes", "sa-token", fasthttp.StatusForbidden, ""},
+		{"other verb", fasthttp.MethodDelete, "/api/v1/deployments", "sa-token", fasthttp.StatusForbidden, ""},
+		{"invalid token", fasthttp.MethodGet, "/api/v1/deployments", "guess", fasthttp.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var caller string
+			api := newAPIHandler(nil, RetryBudget{}, zerolog.Nop())
+			handler := api.requireAuth(auth, func(ctx *fasthttp.RequestCtx) {
+				caller = requestCaller(ctx)
+			})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.path)
+			ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+tt.token)
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus || caller != tt.wantCaller {
+				t.Errorf("expected status %d and caller %q, got %d and %q: %s",
+					tt.wantStatus, tt.wantCaller, ctx.Response.StatusCode(), caller, ctx.Response.Body())
+			}
+		})
+	}
+}
+
+// TestTokenReviewAuthenticatorCache verifies that decisions are cached for tokenReviewCacheTTL, and
+// that failed reviews are retried.
+func TestTokenReviewAuthenticatorCache(t *testing.T) {
+	down := false
+	client, reviews := newTokenReviewTestClient(&down)
+	auth := NewTokenReviewAuthenticator(client, nil)
+	now := time.Now()
+	auth.now = func() time.Time { return now }
+
+	authenticate := func() error {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v1/deployments")
+		ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer sa-token")
+		_, err := auth.Authenticate(ctx)
+		return err
+	}
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		down        bool
+		wantErr     bool
+		wantReviews int
+	}{
+		{"first request", 0, false, false, 2},
+		{"cached", time.Second, false, false, 2},
+		{"expired", tokenReviewCacheTTL, true, true, 3},
+		{"retried", 0, false, false, 5},
+	}
+	for _, step := range steps {
+		now, down = now.Add(step.advance), step.down
+		if err := authenticate(); (err != nil) != step.wantErr || *reviews != step.wantReviews {
+			t.Errorf("%s: got error %v and %d reviews, want error %v and %d reviews",
+				step.name, err, *reviews, step.wantErr, step.wantReviews)
+		}
+	}
+}
+
+// TestRequestVerb verifies the mapping of HTTP methods to the verbs of non-resource URLs.
+func TestRequestVerb(t *testing.T) {
+	tests := map[string]string{
+		fasthttp.MethodGet: "get", fasthttp.MethodHead: "get", fasthttp.MethodPost: "create",
+		fasthttp.MethodPut: "update", fasthttp.MethodPatch: "patch", fasthttp.MethodDelete: "delete",
+	}
+	for method, want := range tests {
+		if got := requestVerb(method); got != want {
+			t.Errorf("requestVerb(%s) = %q, want %q", method, got, want)
+		}
+	}
+}
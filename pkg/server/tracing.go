@@ -0,0 +1,100 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the tracing middleware, which records a span for each request.
+package server
+
+import (
+	"context"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of the server.
+const tracerName = "github.com/Searge/k8s-controller/pkg/server"
+
+// spanUserValue is the request user value holding the server span of the request.
+const spanUserValue = "span"
+
+// traceRequests wraps a handler to record a server span for each request, named after its method and
+// route pattern, e.g. "GET /api/v1/deployments", and continuing the trace of the caller from the
+// traceparent header. Handlers pass the span on to their Kubernetes API calls with withRequestSpan. The
+// span ends when the handler returns, before streamed bodies are written. Spans are only recorded once
+// tracing.Setup installed a tracer provider.
+func traceRequests(provider trace.TracerProvider, propagator propagation.TextMapPropagator,
+	routeLabel func(path string) string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	tracer := provider.Tracer(tracerName)
+	return func(ctx *fasthttp.RequestCtx) {
+		method, path := string(ctx.Method()), string(ctx.Path())
+		route := routeLabel(path)
+		parent := propagator.Extract(context.Background(), requestHeaderCarrier{&ctx.Request.Header})
+		_, span := tracer.Start(parent, method+" "+route, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", path),
+			))
+		defer span.End()
+		ctx.SetUserValue(spanUserValue, span)
+
+		next(ctx)
+
+		status := ctx.Response.StatusCode()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		// Client errors are the caller's; only server errors fail the span of a server.
+		if status >= fasthttp.StatusInternalServerError {
+			span.SetStatus(codes.Error, fasthttp.StatusMessage(status))
+		}
+	}
+}
+
+// withRequestSpan returns parent carrying the span of the request, so the Kubernetes API calls made with
+// it are recorded as children of the request. It returns parent unchanged for requests without a span.
+func withRequestSpan(parent context.Context, ctx *fasthttp.RequestCtx) context.Context {
+	span, ok := ctx.UserValue(spanUserValue).(trace.Span)
+	if !ok {
+		return parent
+	}
+	return trace.ContextWithSpan(parent, span)
+}
+
+// requestContext returns a background context carrying the span of the request, for the Kubernetes API
+// calls of its handler.
+func requestContext(ctx *fasthttp.RequestCtx) context.Context {
+	return withRequestSpan(context.Background(), ctx)
+}
+
+// requestTraceID returns the trace ID of the request, or "" if it is not traced.
+func requestTraceID(ctx *fasthttp.RequestCtx) string {
+	span, ok := ctx.UserValue(spanUserValue).(trace.Span)
+	if !ok || !span.SpanContext().IsValid() {
+		return ""
+	}
+	return span.SpanContext().TraceID().String()
+}
+
+// requestHeaderCarrier adapts request headers to the propagation.TextMapCarrier interface.
+type requestHeaderCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+// Get returns the value of the header key, or "" if it is not set.
+func (c requestHeaderCarrier) Get(key string) string {
+	return string(c.header.Peek(key))
+}
+
+// Set sets the header key to value.
+func (c requestHeaderCarrier) Set(key, value string) {
+	c.header.Set(key, value)
+}
+
+// Keys returns the names of the headers.
+func (c requestHeaderCarrier) Keys() []string {
+	var keys []string
+	for key := range c.header.All() {
+		keys = append(keys, string(key))
+	}
+	return keys
+}
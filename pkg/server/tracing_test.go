@@ -0,0 +1,111 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the tracing middleware.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestTraceRequests verifies the server spans of requests, the continuation of the traces of callers and
+// the propagation of the span to the Kubernetes API calls of handlers.
+func TestTraceRequests(t *testing.T) {
+	const callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name        string
+		traceparent string
+		status      int
+		wantTraceID string
+		wantError   bool
+	}{
+		{"new trace", "", fasthttp.StatusOK, "", false},
+		{"caller trace", "00-" + callerTraceID + "-00f067aa0ba902b7-01", fasthttp.StatusOK, callerTraceID, false},
+		{"invalid traceparent", "00-bad-trace-01", fasthttp.StatusOK, "", false},
+		{"client error", "", fasthttp.StatusNotFound, "", false},
+		{"server error", "", fasthttp.StatusBadGateway, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			routeLabel := func(string) string { return "/api/v1/deployments/{namespace}/{name}" }
+
+			var logBuf bytes.Buffer
+			var upstream trace.SpanContext
+			handler := traceRequests(provider, propagation.TraceContext{}, routeLabel,
+				accessLog(zerolog.New(&logBuf), 0, func(ctx *fasthttp.RequestCtx) {
+					upstream = trace.SpanContextFromContext(requestContext(ctx))
+					ctx.SetStatusCode(tt.status)
+				}))
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/api/v1/deployments/shop/web")
+			if tt.traceparent != "" {
+				ctx.Request.Header.Set("traceparent", tt.traceparent)
+			}
+			handler(ctx)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != "GET /api/v1/deployments/{namespace}/{name}" || span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("unexpected span %s of kind %s", span.Name(), span.SpanKind())
+			}
+			traceID := span.SpanContext().TraceID().String()
+			if tt.wantTraceID != "" && traceID != tt.wantTraceID {
+				t.Errorf("expected trace %s of the caller, got %s", tt.wantTraceID, traceID)
+			}
+			if tt.wantTraceID == "" && span.Parent().IsValid() {
+				t.Errorf("expected a new trace, got parent %s", span.Parent().SpanID())
+			}
+			if upstream.SpanID() != span.SpanContext().SpanID() {
+				t.Errorf("expected the request span in the handler context, got %s", upstream.SpanID())
+			}
+			if (span.Status().Code == codes.Error) != tt.wantError {
+				t.Errorf("unexpected span status %v", span.Status())
+			}
+			wantStatus := attribute.Int("http.response.status_code", tt.status)
+			found := false
+			for _, attr := range span.Attributes() {
+				found = found || attr == wantStatus
+			}
+			if !found {
+				t.Errorf("expected attribute %v, got %v", wantStatus, span.Attributes())
+			}
+
+			var entry map[string]any
+			if err := json.Unmarshal(logBuf.Bytes(), &entry); err != nil {
+				t.Fatalf("failed to decode log entry %q: %v", logBuf.String(), err)
+			}
+			if entry["trace_id"] != traceID {
+				t.Errorf("expected trace_id %s in the log entry, got %v", traceID, entry["trace_id"])
+			}
+		})
+	}
+}
+
+// TestWithRequestSpan verifies that requests without a span leave the parent context unchanged.
+func TestWithRequestSpan(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	parent := context.Background()
+	if got := withRequestSpan(parent, ctx); got != parent {
+		t.Errorf("expected the parent context for a request without a span, got %v", got)
+	}
+	if id := requestTraceID(ctx); id != "" {
+		t.Errorf("expected no trace ID for a request without a span, got %s", id)
+	}
+}
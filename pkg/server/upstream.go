@@ -0,0 +1,138 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the retry budget for upstream Kubernetes API calls and exposes
+// its decisions to API consumers via response headers and the limits endpoint.
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Response headers describing the upstream retry budget decisions of a request.
+const (
+	// HeaderRetries is the number of upstream retries performed (attempts minus one).
+	HeaderRetries = "X-KC-Retries"
+
+	// HeaderUpstreamLatency is the total time spent in upstream calls, in milliseconds.
+	HeaderUpstreamLatency = "X-KC-Upstream-Latency"
+)
+
+// Default retry budget settings.
+const (
+	DefaultUpstreamTimeout = 10 * time.Second
+	DefaultMaxRetries      = 2
+	DefaultRetryBackoff    = 200 * time.Millisecond
+)
+
+// clientTimeoutMargin is added to the upstream timeout when recommending a client-side timeout.
+const clientTimeoutMargin = time.Second
+
+// RetryBudget bounds how long and how often a request may call the Kubernetes API.
+type RetryBudget struct {
+	// Timeout is the total time budget for all upstream attempts of a request.
+	Timeout time.Duration
+
+	// MaxRetries is the number of retries allowed after the first attempt fails with a transient error.
+	MaxRetries int
+
+	// Backoff is the delay before each retry; it doubles after every retry.
+	Backoff time.Duration
+}
+
+// limitsResponse is the JSON body of the limits endpoint.
+type limitsResponse struct {
+	UpstreamTimeoutMs int64 `json:"upstreamTimeoutMs" doc:"Timeout of each Kubernetes API call, in milliseconds"`
+	MaxRetries        int   `json:"maxRetries" doc:"Number of retries after a failed Kubernetes API call"`
+	RetryBackoffMs    int64 `json:"retryBackoffMs" doc:"Delay before the first retry, in milliseconds"`
+
+	RecommendedClientTimeoutMs int64 `json:"recommendedClientTimeoutMs" doc:"Client timeout covering all retries"`
+}
+
+// upstreamResult records what happened while calling upstream for a request.
+type upstreamResult struct {
+	retries int
+	latency time.Duration
+}
+
+// withDefaults fills unset budget fields with defaults.
+func (b RetryBudget) withDefaults() RetryBudget {
+	if b.Timeout <= 0 {
+		b.Timeout = DefaultUpstreamTimeout
+	}
+	if b.MaxRetries < 0 {
+		b.MaxRetries = 0
+	}
+	if b.Backoff <= 0 {
+		b.Backoff = DefaultRetryBackoff
+	}
+	return b
+}
+
+// withoutRetries returns the budget with retries disabled, for calls that are not safe to repeat.
+func (b RetryBudget) withoutRetries() RetryBudget {
+	b.MaxRetries = 0
+	return b
+}
+
+// limits returns the budget as advertised on the limits endpoint.
+func (b RetryBudget) limits() limitsResponse {
+	return limitsResponse{
+		UpstreamTimeoutMs:          b.Timeout.Milliseconds(),
+		MaxRetries:                 b.MaxRetries,
+		RetryBackoffMs:             b.Backoff.Milliseconds(),
+		RecommendedClientTimeoutMs: (b.Timeout + clientTimeoutMargin).Milliseconds(),
+	}
+}
+
+// call runs fn within the budget, retrying transient failures while time and retries remain. The context of
+// fn derives from parent, e.g. to carry the span of the request.
+func (b RetryBudget) call(parent context.Context, fn func(ctx context.Context) error) (upstreamResult, error) {
+	ctx, cancel := context.WithTimeout(parent, b.Timeout)
+	defer cancel()
+
+	var result upstreamResult
+	backoff := b.Backoff
+	for {
+		start := time.Now()
+		err := fn(ctx)
+		result.latency += time.Since(start)
+
+		if err == nil || !isRetryable(err) || result.retries >= b.MaxRetries || !hasTimeFor(ctx, backoff) {
+			return result, err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		result.retries++
+	}
+}
+
+// hasTimeFor reports whether the context deadline leaves room for waiting d plus another attempt.
+func hasTimeFor(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
+
+// isRetryable reports whether an upstream error is transient and worth retrying.
+func isRetryable(err error) bool {
+	if apierrors.IsServerTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) || apierrors.IsTimeout(err) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// setUpstreamHeaders records the retry budget decisions on the response.
+func setUpstreamHeaders(ctx *fasthttp.RequestCtx, result upstreamResult) {
+	ctx.Response.Header.Set(HeaderRetries, strconv.Itoa(result.retries))
+	ctx.Response.Header.Set(HeaderUpstreamLatency, strconv.FormatInt(result.latency.Milliseconds(), 10))
+}
@@ -0,0 +1,122 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the upstream retry budget and its reporting to API consumers.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestRetryBudgetCall verifies retry decisions for transient and permanent errors.
+func TestRetryBudgetCall(t *testing.T) {
+	transient := apierrors.NewServiceUnavailable("overloaded")
+	permanent := apierrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "x")
+
+	tests := []struct {
+		name        string
+		maxRetries  int
+		failures    []error
+		wantRetries int
+		wantErr     bool
+	}{
+		{"success first try", 2, nil, 0, false},
+		{"transient then success", 2, []error{transient}, 1, false},
+		{"retries exhausted", 1, []error{transient, transient, transient}, 1, true},
+		{"permanent error not retried", 2, []error{permanent}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := RetryBudget{Timeout: time.Second, MaxRetries: tt.maxRetries, Backoff: time.Millisecond}
+			calls := 0
+			result, err := budget.call(context.Background(), func(_ context.Context) error {
+				defer func() { calls++ }()
+				if calls < len(tt.failures) {
+					return tt.failures[calls]
+				}
+				return nil
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.retries != tt.wantRetries {
+				t.Errorf("expected %d retries, got %d", tt.wantRetries, result.retries)
+			}
+		})
+	}
+}
+
+// TestRetryBudgetRespectsDeadline verifies that no retry is attempted without enough time left.
+func TestRetryBudgetRespectsDeadline(t *testing.T) {
+	budget := RetryBudget{Timeout: 50 * time.Millisecond, MaxRetries: 5, Backoff: time.Second}
+	result, err := budget.call(context.Background(), func(_ context.Context) error {
+		return apierrors.NewServiceUnavailable("overloaded")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result.retries != 0 {
+		t.Errorf("expected no retries when backoff exceeds the budget, got %d", result.retries)
+	}
+}
+
+// TestIsRetryable verifies classification of upstream errors.
+func TestIsRetryable(t *testing.T) {
+	if !isRetryable(apierrors.NewTooManyRequests("slow down", 1)) {
+		t.Error("expected 429 to be retryable")
+	}
+	if isRetryable(apierrors.NewForbidden(schema.GroupResource{Resource: "deployments"}, "x", errors.New("no"))) {
+		t.Error("expected 403 not to be retryable")
+	}
+}
+
+// TestLimitsEndpoint verifies that the retry budget is advertised.
+func TestLimitsEndpoint(t *testing.T) {
+	handler := createHandler(zerolog.Nop(), Options{Budget: RetryBudget{Timeout: 5 * time.Second, MaxRetries: 3}})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/limits")
+	handler(ctx)
+
+	var limits limitsResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &limits); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if limits.UpstreamTimeoutMs != 5000 || limits.MaxRetries != 3 {
+		t.Errorf("unexpected limits %+v", limits)
+	}
+	if limits.RetryBackoffMs != DefaultRetryBackoff.Milliseconds() {
+		t.Errorf("expected default backoff, got %d", limits.RetryBackoffMs)
+	}
+	if limits.RecommendedClientTimeoutMs <= limits.UpstreamTimeoutMs {
+		t.Errorf("expected recommended client timeout above upstream timeout, got %+v", limits)
+	}
+}
+
+// TestUpstreamHeaders verifies that API responses carry the retry budget headers.
+func TestUpstreamHeaders(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	handler := createHandler(zerolog.Nop(), Options{Client: client})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/deployments")
+	handler(ctx)
+
+	if got := string(ctx.Response.Header.Peek(HeaderRetries)); got != "0" {
+		t.Errorf("expected %s: 0, got %q", HeaderRetries, got)
+	}
+	if got := ctx.Response.Header.Peek(HeaderUpstreamLatency); len(got) == 0 {
+		t.Errorf("expected %s header to be set", HeaderUpstreamLatency)
+	}
+}
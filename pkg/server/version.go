@@ -0,0 +1,35 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the version endpoint.
+package server
+
+import (
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/buildinfo"
+)
+
+// versionResponse is the JSON body of /version.
+type versionResponse struct {
+	App buildinfo.Info `json:"app" doc:"Build metadata of k8s-controller"`
+
+	// Kubernetes is only set if a client is configured and the API server answered.
+	Kubernetes *buildinfo.Info `json:"kubernetes,omitempty" doc:"Version of the connected Kubernetes API server"`
+
+	KubernetesError string `json:"kubernetesError,omitempty" doc:"Why the Kubernetes version is missing, if it is"`
+}
+
+// version handles GET /version. It always responds 200 with the build metadata of the server, so that
+// it also answers while the cluster is unreachable; the Kubernetes version is added if available.
+func (h *apiHandler) version(ctx *fasthttp.RequestCtx, build buildinfo.Info) {
+	response := versionResponse{App: build}
+	if h.client != nil {
+		if server, err := h.client.ServerVersion(); err != nil {
+			h.logger.Warn().Err(err).Msg("Failed to get the Kubernetes version for /version")
+			response.KubernetesError = err.Error()
+		} else {
+			kubernetes := buildinfo.FromKubernetes(server)
+			response.Kubernetes = &kubernetes
+		}
+	}
+	h.writeJSON(ctx, fasthttp.StatusOK, response)
+}
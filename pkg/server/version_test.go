@@ -0,0 +1,73 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the version endpoint.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/Searge/k8s-controller/pkg/buildinfo"
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// TestVersionEndpoint tests GET /version with and without a reachable Kubernetes API server.
+func TestVersionEndpoint(t *testing.T) {
+	build := buildinfo.Info{Version: "v1.2.0", GitCommit: "abc123", BuildDate: "2026-01-02T03:04:05Z",
+		GoVersion: "go1.25.5", Platform: "linux/amd64"}
+
+	unreachable := k8s.NewFakeClient(zerolog.Nop())
+	unreachable.GetClientset().Discovery().(*fake.FakeDiscovery).
+		PrependReactor("get", "version", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("connection refused")
+		})
+
+	tests := []struct {
+		name              string
+		client            *k8s.Client
+		wantKubernetes    string
+		wantKubernetesErr bool
+	}{
+		{"no client", nil, "", false},
+		{"connected", k8s.NewFakeClient(zerolog.Nop()), "v1.35.0", false},
+		{"unreachable", unreachable, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Client: tt.client, Build: build})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/version")
+			ctx.Request.Header.SetMethod("GET")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != fasthttp.StatusOK {
+				t.Fatalf("expected status 200, got %d", ctx.Response.StatusCode())
+			}
+			var body versionResponse
+			if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.App != build {
+				t.Errorf("expected app %+v, got %+v", build, body.App)
+			}
+			got := ""
+			if body.Kubernetes != nil {
+				got = body.Kubernetes.Version
+			}
+			if got != tt.wantKubernetes {
+				t.Errorf("expected Kubernetes version %q, got %q", tt.wantKubernetes, got)
+			}
+			if (body.KubernetesError != "") != tt.wantKubernetesErr {
+				t.Errorf("unexpected Kubernetes error %q", body.KubernetesError)
+			}
+		})
+	}
+}
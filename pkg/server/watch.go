@@ -0,0 +1,129 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the server-sent events stream of deployment changes.
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// Server-sent events settings of the watch endpoint.
+const (
+	// sseHeartbeatInterval is how often a comment is sent while no events arrive. It keeps proxies from
+	// closing idle streams and detects clients that went away, which only fails on a write.
+	sseHeartbeatInterval = 15 * time.Second
+
+	// sseRetry is the reconnection delay advertised to EventSource clients.
+	sseRetry = 3 * time.Second
+)
+
+// headerLastEventID is sent by EventSource clients on reconnection with the id of the last event received.
+const headerLastEventID = "Last-Event-ID"
+
+// deploymentWatchOptions returns the watch options of a request: the namespace and label selector of the
+// query, resumed from the Last-Event-ID header if set.
+func deploymentWatchOptions(ctx *fasthttp.RequestCtx) k8s.WatchOptions {
+	return k8s.WatchOptions{
+		Namespace:       string(ctx.QueryArgs().Peek("namespace")),
+		LabelSelector:   string(ctx.QueryArgs().Peek("labelSelector")),
+		ResourceVersion: string(ctx.Request.Header.Peek(headerLastEventID)),
+	}
+}
+
+// watchDeployments handles GET /api/v1/watch/deployments, streaming deployment changes as server-sent
+// events until the client disconnects or the server shuts down. A new stream starts with the existing
+// deployments as added events; a stream resumed with Last-Event-ID starts with the changes since that event.
+func (h *apiHandler) watchDeployments(ctx *fasthttp.RequestCtx) {
+	if h.client == nil {
+		h.writeError(ctx, fasthttp.StatusServiceUnavailable, "kubernetes client not configured")
+		return
+	}
+
+	opts := deploymentWatchOptions(ctx)
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "no-cache")
+	// Disables response buffering of nginx, which would hold back events.
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	client, shutdown := h.client, h.shutdown
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		// Clients resume the stream with Last-Event-ID after a shutdown, e.g. from another replica.
+		err := streamDeploymentEvents(shutdown, client, opts, w, sseHeartbeatInterval)
+		h.logger.Info().Err(err).Str("namespace", opts.Namespace).Msg("Deployment watch stream closed")
+	})
+}
+
+// streamDeploymentEvents writes the deployment events of a watch to w as server-sent events, with a
+// heartbeat comment after each quiet interval, until ctx is cancelled, the watch fails or a write fails
+// because the client went away. Each event is named after its type, e.g. "modified", carries the
+// DeploymentInfo as data and the resource version as id.
+func streamDeploymentEvents(ctx context.Context, client *k8s.Client, opts k8s.WatchOptions, w *bufio.Writer,
+	heartbeat time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan k8s.DeploymentEvent)
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- client.WatchDeployments(ctx, opts, func(event k8s.DeploymentEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush events to client: %w", err)
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case event := <-events:
+			err = writeDeploymentEvent(w, event)
+			ticker.Reset(heartbeat)
+		case <-ticker.C:
+			_, err = w.WriteString(": heartbeat\n\n")
+		case err := <-watchDone:
+			return err
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write events to client: %w", err)
+		}
+	}
+}
+
+// writeDeploymentEvent writes a deployment event in the server-sent events format.
+func writeDeploymentEvent(w *bufio.Writer, event k8s.DeploymentEvent) error {
+	data, err := json.Marshal(event.Deployment)
+	if err != nil {
+		return fmt.Errorf("failed to encode deployment event: %w", err)
+	}
+	if event.ResourceVersion != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", event.ResourceVersion); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", strings.ToLower(string(event.Type)), data)
+	return err
+}
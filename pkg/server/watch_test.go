@@ -0,0 +1,133 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the server-sent events stream of deployment changes.
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// cancelWriter collects a stream and cancels it once the marker was written count times.
+type cancelWriter struct {
+	buf    bytes.Buffer
+	marker string
+	count  int
+	cancel context.CancelFunc
+}
+
+// Write appends p and cancels the stream once enough markers were written.
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	n, err := w.buf.Write(p)
+	if strings.Count(w.buf.String(), w.marker) >= w.count {
+		w.cancel()
+	}
+	return n, err
+}
+
+// failingWriter fails every write, like the connection of a client that went away.
+type failingWriter struct{}
+
+// Write always fails.
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+// TestDeploymentWatchOptions verifies that the watch is filtered by the query and resumed from Last-Event-ID.
+func TestDeploymentWatchOptions(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/v1/watch/deployments?namespace=shop&labelSelector=app%3Dcart")
+	ctx.Request.Header.Set(headerLastEventID, "1234")
+
+	opts := deploymentWatchOptions(ctx)
+	if opts.Namespace != "shop" || opts.LabelSelector != "app=cart" || opts.ResourceVersion != "1234" {
+		t.Errorf("unexpected watch options %+v", opts)
+	}
+}
+
+// TestStreamDeploymentEvents verifies the events and heartbeats written to the stream.
+func TestStreamDeploymentEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		marker    string
+		count     int
+		contains  []string
+	}{
+		{"existing deployments", "shop", "event: added\n", 3,
+			[]string{"retry: 3000\n\n", `data: {"name":"cart","namespace":"shop"`}},
+		{"heartbeat", "empty", ": heartbeat\n\n", 2, []string{"retry: 3000\n\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			out := &cancelWriter{marker: tt.marker, count: tt.count, cancel: cancel}
+
+			err := streamDeploymentEvents(ctx, client, k8s.WatchOptions{Namespace: tt.namespace},
+				bufio.NewWriter(out), time.Millisecond)
+			if err != nil {
+				t.Fatalf("streamDeploymentEvents() error = %v", err)
+			}
+			if ctx.Err() == context.DeadlineExceeded {
+				t.Fatalf("expected %d markers %q before the timeout, got:\n%s", tt.count, tt.marker, out.buf.String())
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.buf.String(), want) {
+					t.Errorf("expected stream to contain %q, got:\n%s", want, out.buf.String())
+				}
+			}
+		})
+	}
+}
+
+// TestStreamDeploymentEventsClientGone verifies that the stream stops when the client went away.
+func TestStreamDeploymentEventsClientGone(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	err := streamDeploymentEvents(context.Background(), client, k8s.WatchOptions{},
+		bufio.NewWriterSize(failingWriter{}, 16), time.Millisecond)
+	if err == nil {
+		t.Error("expected an error writing to a client that went away")
+	}
+}
+
+// TestWatchDeploymentsEndpoint verifies the response headers of the stream with and without a client.
+func TestWatchDeploymentsEndpoint(t *testing.T) {
+	tests := []struct {
+		name            string
+		client          *k8s.Client
+		wantStatus      int
+		wantContentType string
+	}{
+		{"stream", k8s.NewFakeClient(zerolog.Nop()), fasthttp.StatusOK, "text/event-stream"},
+		{"no client", nil, fasthttp.StatusServiceUnavailable, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createHandler(zerolog.Nop(), Options{Client: tt.client})
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/api/v1/watch/deployments")
+			handler(ctx)
+
+			if ctx.Response.StatusCode() != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, ctx.Response.StatusCode())
+			}
+			if got := string(ctx.Response.Header.ContentType()); got != tt.wantContentType {
+				t.Errorf("expected content type %q, got %q", tt.wantContentType, got)
+			}
+		})
+	}
+}
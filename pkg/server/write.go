@@ -0,0 +1,143 @@
+// Package server provides HTTP server functionality for the k8s-controller application.
+// This file implements the write endpoints creating, replacing and deleting deployments.
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/valyala/fasthttp"
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// namespacesPathPrefix is the path prefix of the namespaced write endpoints.
+const namespacesPathPrefix = "/api/v1/namespaces/"
+
+// Route patterns of the write endpoints.
+const (
+	deploymentsPattern = namespacesPathPrefix + "{namespace}/deployments"
+	deploymentPattern  = deploymentsPattern + "/{name}"
+)
+
+// deletedResponse is the JSON body of a successful delete.
+type deletedResponse struct {
+	Kind      string `json:"kind" doc:"Kind of the deleted object, e.g. \"Deployment\""`
+	Namespace string `json:"namespace" doc:"Namespace of the deleted object"`
+	Name      string `json:"name" doc:"Name of the deleted object; its pods are removed in the background"`
+}
+
+// writeDeployment adapts write, a write of the deployment named by the path parameters of the request, to
+// the handler of a write endpoint: POST /api/v1/namespaces/{namespace}/deployments, without a name, or PUT
+// and DELETE /api/v1/namespaces/{namespace}/deployments/{name}. They are only routed with the write API
+// enabled, behind requireAuth.
+func (h *apiHandler) writeDeployment(write func(ctx *fasthttp.RequestCtx, ns, name string)) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if h.client == nil {
+			h.writeError(ctx, fasthttp.StatusServiceUnavailable, "kubernetes client not configured")
+			return
+		}
+		write(ctx, pathParamValue(ctx, "namespace"), pathParamValue(ctx, "name"))
+	}
+}
+
+// createDeployment creates the deployment of the request body in ns and responds 201 with it.
+// Creates are not retried, since a create that timed out may still have succeeded.
+func (h *apiHandler) createDeployment(ctx *fasthttp.RequestCtx, ns string) {
+	deployment, ok := h.decodeDeployment(ctx, ns, "")
+	if !ok {
+		return
+	}
+
+	var created k8s.DeploymentInfo
+	result, err := h.budget.withoutRetries().call(requestContext(ctx), func(reqCtx context.Context) error {
+		var createErr error
+		created, createErr = h.client.CreateDeployment(reqCtx, deployment)
+		return createErr
+	})
+	setUpstreamHeaders(ctx, result)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create deployment for API request")
+		h.writeUpstreamError(ctx, err)
+		return
+	}
+
+	h.logWrite(ctx, "created", ns, deployment.Name)
+	h.writeJSON(ctx, fasthttp.StatusCreated, created)
+}
+
+// replaceDeployment replaces the deployment ns/name with the request body and responds 200 with it.
+func (h *apiHandler) replaceDeployment(ctx *fasthttp.RequestCtx, ns, name string) {
+	deployment, ok := h.decodeDeployment(ctx, ns, name)
+	if !ok {
+		return
+	}
+
+	var replaced k8s.DeploymentInfo
+	result, err := h.budget.call(requestContext(ctx), func(reqCtx context.Context) error {
+		var replaceErr error
+		replaced, replaceErr = h.client.ReplaceDeployment(reqCtx, deployment)
+		return replaceErr
+	})
+	setUpstreamHeaders(ctx, result)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to replace deployment for API request")
+		h.writeUpstreamError(ctx, err)
+		return
+	}
+
+	h.logWrite(ctx, "replaced", ns, name)
+	h.writeJSON(ctx, fasthttp.StatusOK, replaced)
+}
+
+// deleteDeployment deletes the deployment ns/name and responds 200 once the API server accepted it.
+func (h *apiHandler) deleteDeployment(ctx *fasthttp.RequestCtx, ns, name string) {
+	result, err := h.budget.call(requestContext(ctx), func(reqCtx context.Context) error {
+		return h.client.DeleteDeployment(reqCtx, ns, name)
+	})
+	setUpstreamHeaders(ctx, result)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to delete deployment for API request")
+		h.writeUpstreamError(ctx, err)
+		return
+	}
+
+	h.logWrite(ctx, "deleted", ns, name)
+	h.writeJSON(ctx, fasthttp.StatusOK, deletedResponse{Kind: "Deployment", Namespace: ns, Name: name})
+}
+
+// decodeDeployment decodes the deployment manifest of the request body, which may omit the namespace
+// and, for name, the name of the path, but not contradict them. It responds 400 if the body is invalid.
+func (h *apiHandler) decodeDeployment(ctx *fasthttp.RequestCtx, ns, name string) (*appsv1.Deployment, bool) {
+	deployment, err := k8s.DecodeDeployment(ctx.Request.Body())
+	if err != nil {
+		h.writeError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	if deployment.Namespace == "" {
+		deployment.Namespace = ns
+	}
+	switch {
+	case deployment.Namespace != ns:
+		err = fmt.Errorf("namespace %q of the manifest does not match %q of the path", deployment.Namespace, ns)
+	case name != "" && deployment.Name != name:
+		err = fmt.Errorf("name %q of the manifest does not match %q of the path", deployment.Name, name)
+	}
+	if err != nil {
+		h.writeError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return deployment, true
+}
+
+// logWrite records a successful write with the caller that made it.
+func (h *apiHandler) logWrite(ctx *fasthttp.RequestCtx, action, ns, name string) {
+	h.logger.Info().
+		Str("caller", requestCaller(ctx)).
+		Str("request_id", requestID(ctx)).
+		Str("namespace", ns).
+		Str("name", name).
+		Msgf("Deployment %s via API", action)
+}
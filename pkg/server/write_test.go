@@ -0,0 +1,112 @@
+// Package server contains tests for the HTTP server functionality.
+// This file tests the write endpoints creating, replacing and deleting deployments.
+package server
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+
+	"github.com/Searge/k8s-controller/pkg/k8s"
+)
+
+// testWriteToken is the bearer token accepted by the write API in these tests.
+const testWriteToken = "s3cret"
+
+// testDeploymentManifest returns a deployment manifest of web in ns with the given replicas.
+func testDeploymentManifest(ns string, replicas int) string {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: NAMESPACE
+spec:
+  replicas: REPLICAS
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web}
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+`
+	return strings.NewReplacer("NAMESPACE", ns, "REPLICAS", strconv.Itoa(replicas)).Replace(manifest)
+}
+
+// TestWriteDeploymentEndpoints walks a deployment through create, replace and delete, including
+// the responses to disabled, unauthenticated and invalid requests.
+func TestWriteDeploymentEndpoints(t *testing.T) {
+	client := k8s.NewFakeClient(zerolog.Nop(), k8s.DefaultDemoObjects(time.Now())...)
+	auth := NewTokenAuthenticator(map[string]string{testWriteToken: "ci"})
+	enabled := createHandler(zerolog.Nop(), Options{Client: client, EnableWriteAPI: true, Authenticator: auth})
+	disabled := createHandler(zerolog.Nop(), Options{Client: client})
+
+	steps := []struct {
+		name         string
+		handler      fasthttp.RequestHandler
+		method       string
+		path         string
+		token        string
+		body         string
+		wantStatus   int
+		wantReplicas int32
+	}{
+		{"disabled", disabled, fasthttp.MethodPost, "/api/v1/namespaces/shop/deployments", testWriteToken,
+			testDeploymentManifest("shop", 2), fasthttp.StatusForbidden, 0},
+		{"unauthenticated", enabled, fasthttp.MethodPost, "/api/v1/namespaces/shop/deployments", "",
+			testDeploymentManifest("shop", 2), fasthttp.StatusUnauthorized, 0},
+		{"create", enabled, fasthttp.MethodPost, "/api/v1/namespaces/shop/deployments", testWriteToken,
+			testDeploymentManifest("shop", 2), fasthttp.StatusCreated, 2},
+		{"create again", enabled, fasthttp.MethodPost, "/api/v1/namespaces/shop/deployments", testWriteToken,
+			testDeploymentManifest("shop", 2), fasthttp.StatusConflict, 0},
+		{"create in other namespace", enabled, fasthttp.MethodPost, "/api/v1/namespaces/default/deployments",
+			testWriteToken, testDeploymentManifest("shop", 2), fasthttp.StatusBadRequest, 0},
+		{"create invalid", enabled, fasthttp.MethodPost, "/api/v1/namespaces/shop/deployments", testWriteToken,
+			"apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n", fasthttp.StatusBadRequest, 0},
+		{"replace", enabled, fasthttp.MethodPut, "/api/v1/namespaces/shop/deployments/web", testWriteToken,
+			testDeploymentManifest("shop", 4), fasthttp.StatusOK, 4},
+		{"replace other name", enabled, fasthttp.MethodPut, "/api/v1/namespaces/shop/deployments/cart",
+			testWriteToken, testDeploymentManifest("shop", 4), fasthttp.StatusBadRequest, 0},
+		{"list is not served", enabled, fasthttp.MethodGet, "/api/v1/namespaces/shop/deployments", testWriteToken,
+			"", fasthttp.StatusMethodNotAllowed, 0},
+		{"delete", enabled, fasthttp.MethodDelete, "/api/v1/namespaces/shop/deployments/web", testWriteToken,
+			"", fasthttp.StatusOK, 0},
+		{"delete again", enabled, fasthttp.MethodDelete, "/api/v1/namespaces/shop/deployments/web", testWriteToken,
+			"", fasthttp.StatusNotFound, 0},
+		{"replace deleted", enabled, fasthttp.MethodPut, "/api/v1/namespaces/shop/deployments/web", testWriteToken,
+			testDeploymentManifest("shop", 4), fasthttp.StatusNotFound, 0},
+	}
+
+	for _, step := range steps {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(step.path)
+		ctx.Request.Header.SetMethod(step.method)
+		if step.token != "" {
+			ctx.Request.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+step.token)
+		}
+		ctx.Request.SetBodyString(step.body)
+		step.handler(ctx)
+
+		if ctx.Response.StatusCode() != step.wantStatus {
+			t.Fatalf("%s: expected status %d, got %d: %s",
+				step.name, step.wantStatus, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+		if step.wantReplicas == 0 {
+			continue
+		}
+		var info k8s.DeploymentInfo
+		if err := json.Unmarshal(ctx.Response.Body(), &info); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", step.name, err)
+		}
+		if info.Name != "web" || info.Namespace != "shop" || info.Replicas.Desired != step.wantReplicas {
+			t.Errorf("%s: unexpected deployment %+v", step.name, info)
+		}
+	}
+}
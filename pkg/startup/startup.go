@@ -0,0 +1,168 @@
+// Package startup tracks staged initialization progress of the application.
+// The state is reported by the /startupz endpoint, so Kubernetes startup probes
+// and humans can tell exactly where a slow boot is stuck.
+package startup
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Stage names a step of the application startup.
+type Stage string
+
+// Startup stages, in the order they are completed during boot.
+const (
+	StageConfigLoaded  Stage = "config-loaded"
+	StageK8sConnected  Stage = "k8s-connected"
+	StageCachesSyncing Stage = "caches-syncing"
+	StageLeaderElected Stage = "leader-elected"
+)
+
+// DefaultStages are the stages of the serve command.
+var DefaultStages = []Stage{StageConfigLoaded, StageK8sConnected, StageCachesSyncing, StageLeaderElected}
+
+// State is the state of a single stage.
+type State string
+
+// Stage states.
+const (
+	StatePending    State = "pending"
+	StateInProgress State = "in-progress"
+	StateDone       State = "done"
+	StateSkipped    State = "skipped"
+)
+
+// StageStatus reports the state of a single stage.
+type StageStatus struct {
+	Name       Stage  `json:"name" doc:"Stage name, e.g. k8s-connected"`
+	State      State  `json:"state" doc:"One of pending, in-progress, done or skipped"`
+	Done       int    `json:"done,omitempty" doc:"Completed units of work, e.g. synced caches"`
+	Total      int    `json:"total,omitempty" doc:"Total units of work of the stage"`
+	Progress   string `json:"progress,omitempty" doc:"Done and total as \"done/total\""`
+	Message    string `json:"message,omitempty" doc:"Last message of the stage, e.g. a connection error"`
+	DurationMs int64  `json:"durationMs,omitempty" doc:"Time the stage took, in milliseconds"`
+}
+
+// Status reports the startup progress of the application.
+type Status struct {
+	// Started is true once every stage is done or skipped.
+	Started bool `json:"started" doc:"True once every stage is done or skipped"`
+
+	// Current is the first stage that is neither done nor skipped.
+	Current Stage `json:"current,omitempty" doc:"First stage that is neither done nor skipped"`
+
+	// ElapsedMs is the time since the tracker was created, in milliseconds.
+	ElapsedMs int64 `json:"elapsedMs" doc:"Time since startup began, in milliseconds"`
+
+	// Stages reports each stage, in startup order.
+	Stages []StageStatus `json:"stages" doc:"Each stage, in startup order"`
+}
+
+// stage holds the mutable state of a single stage.
+type stage struct {
+	status    StageStatus
+	startedAt time.Time
+}
+
+// Tracker records the progress of startup stages. It is safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	created time.Time
+	stages  []*stage
+	now     func() time.Time
+}
+
+// NewTracker creates a Tracker for the given stages, all pending.
+func NewTracker(stages ...Stage) *Tracker {
+	t := &Tracker{now: time.Now}
+	t.created = t.now()
+	for _, name := range stages {
+		t.stages = append(t.stages, &stage{status: StageStatus{Name: name, State: StatePending}})
+	}
+	return t
+}
+
+// Begin marks a stage as in progress.
+func (t *Tracker) Begin(name Stage) {
+	t.update(name, func(s *stage) {
+		s.status.State = StateInProgress
+	})
+}
+
+// Progress records that done out of total items of a stage are complete,
+// e.g. the number of synced informer caches. The stage is marked in progress.
+func (t *Tracker) Progress(name Stage, done, total int) {
+	t.update(name, func(s *stage) {
+		s.status.State = StateInProgress
+		s.status.Done, s.status.Total = done, total
+		s.status.Progress = fmt.Sprintf("%d/%d", done, total)
+	})
+}
+
+// Fail records a message on an in-progress stage, e.g. the last connection error.
+// The stage stays in progress, since startup is expected to retry.
+func (t *Tracker) Fail(name Stage, err error) {
+	t.update(name, func(s *stage) {
+		s.status.State = StateInProgress
+		s.status.Message = err.Error()
+	})
+}
+
+// Complete marks a stage as done.
+func (t *Tracker) Complete(name Stage) {
+	t.finish(name, StateDone, "")
+}
+
+// Skip marks a stage as not applicable, with a reason.
+func (t *Tracker) Skip(name Stage, reason string) {
+	t.finish(name, StateSkipped, reason)
+}
+
+// finish moves a stage into a final state and records its duration.
+func (t *Tracker) finish(name Stage, state State, message string) {
+	t.update(name, func(s *stage) {
+		s.status.State = state
+		s.status.Message = message
+		s.status.DurationMs = t.now().Sub(s.startedAt).Milliseconds()
+	})
+}
+
+// update applies fn to the named stage. The first transition out of pending
+// starts the stage's clock. Unknown stages are ignored.
+func (t *Tracker) update(name Stage, fn func(*stage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.stages {
+		if s.status.Name != name {
+			continue
+		}
+		if s.status.State == StatePending {
+			s.startedAt = t.now()
+		}
+		fn(s)
+		return
+	}
+}
+
+// Status returns a snapshot of the startup progress.
+func (t *Tracker) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := Status{
+		Started:   true,
+		ElapsedMs: t.now().Sub(t.created).Milliseconds(),
+		Stages:    make([]StageStatus, 0, len(t.stages)),
+	}
+	for _, s := range t.stages {
+		status.Stages = append(status.Stages, s.status)
+		if s.status.State != StateDone && s.status.State != StateSkipped && status.Started {
+			status.Started = false
+			status.Current = s.status.Name
+		}
+	}
+	return status
+}
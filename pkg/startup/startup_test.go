@@ -0,0 +1,73 @@
+// Package startup contains tests for startup progress tracking.
+// This file tests stage transitions and status reporting.
+package startup
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTrackerStages verifies stage transitions and the reported current stage.
+func TestTrackerStages(t *testing.T) {
+	tracker := NewTracker(DefaultStages...)
+
+	status := tracker.Status()
+	if status.Started || status.Current != StageConfigLoaded || len(status.Stages) != len(DefaultStages) {
+		t.Fatalf("unexpected initial status %+v", status)
+	}
+
+	tracker.Complete(StageConfigLoaded)
+	tracker.Fail(StageK8sConnected, errors.New("connection refused"))
+	status = tracker.Status()
+	if status.Current != StageK8sConnected {
+		t.Errorf("expected current stage %s, got %s", StageK8sConnected, status.Current)
+	}
+	if got := status.Stages[1]; got.State != StateInProgress || got.Message != "connection refused" {
+		t.Errorf("unexpected k8s-connected stage %+v", got)
+	}
+
+	tracker.Complete(StageK8sConnected)
+	tracker.Progress(StageCachesSyncing, 2, 5)
+	status = tracker.Status()
+	if got := status.Stages[2]; status.Current != StageCachesSyncing || got.Progress != "2/5" || got.Total != 5 {
+		t.Errorf("unexpected caches-syncing stage %+v (current %s)", got, status.Current)
+	}
+
+	tracker.Complete(StageCachesSyncing)
+	tracker.Skip(StageLeaderElected, "leader election not enabled")
+	status = tracker.Status()
+	if !status.Started || status.Current != "" {
+		t.Errorf("expected startup to be complete, got %+v", status)
+	}
+	if got := status.Stages[1]; got.Message != "" {
+		t.Errorf("expected completion to clear the failure message, got %q", got.Message)
+	}
+}
+
+// TestTrackerDurations verifies that stage durations are measured from the first transition.
+func TestTrackerDurations(t *testing.T) {
+	clock := time.Unix(0, 0)
+	tracker := NewTracker(StageK8sConnected)
+	tracker.now = func() time.Time { return clock }
+
+	tracker.Begin(StageK8sConnected)
+	clock = clock.Add(1500 * time.Millisecond)
+	tracker.Fail(StageK8sConnected, errors.New("timeout"))
+	clock = clock.Add(500 * time.Millisecond)
+	tracker.Complete(StageK8sConnected)
+
+	if got := tracker.Status().Stages[0].DurationMs; got != 2000 {
+		t.Errorf("expected duration 2000ms, got %d", got)
+	}
+}
+
+// TestTrackerUnknownStage verifies that updates of unknown stages are ignored.
+func TestTrackerUnknownStage(t *testing.T) {
+	tracker := NewTracker(StageConfigLoaded)
+	tracker.Complete(StageLeaderElected)
+
+	if status := tracker.Status(); status.Started || len(status.Stages) != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
@@ -0,0 +1,127 @@
+// Package telemetry provides opt-in, anonymized usage and latency telemetry for CLI commands.
+// This file implements the local aggregation of events into per-command statistics.
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Stats aggregates the events of one command.
+type Stats struct {
+	Command    string `json:"command"`
+	Count      int    `json:"count"`
+	Failures   int    `json:"failures"`
+	TotalMs    int64  `json:"totalMs"`
+	MaxMs      int64  `json:"maxMs"`
+	LastFailed bool   `json:"lastFailed"`
+}
+
+// AverageMs returns the mean duration of the command in milliseconds.
+func (s Stats) AverageMs() int64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalMs / int64(s.Count)
+}
+
+// FailureRate returns the fraction of failed invocations.
+func (s Stats) FailureRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Count)
+}
+
+// DefaultStatsPath returns the stats file in the user's configuration directory.
+func DefaultStatsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "k8s-controller", "telemetry.json"), nil
+}
+
+// LoadStats reads the aggregated statistics, slowest average first.
+// A missing file yields no statistics.
+func LoadStats(path string) ([]Stats, error) {
+	byCommand, err := readStats(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]Stats, 0, len(byCommand))
+	for _, s := range byCommand {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].AverageMs() != stats[j].AverageMs() {
+			return stats[i].AverageMs() > stats[j].AverageMs()
+		}
+		return stats[i].Command < stats[j].Command
+	})
+	return stats, nil
+}
+
+// RecordStats adds an event to the statistics in the stats file.
+// Concurrent invocations may lose an update; the statistics are indicative, not exact.
+func RecordStats(path string, event Event) error {
+	byCommand, err := readStats(path)
+	if err != nil {
+		return err
+	}
+
+	s := byCommand[event.Command]
+	s.Command = event.Command
+	s.Count++
+	s.TotalMs += event.DurationMs
+	s.MaxMs = max(s.MaxMs, event.DurationMs)
+	s.LastFailed = !event.Success
+	if !event.Success {
+		s.Failures++
+	}
+	byCommand[event.Command] = s
+
+	return writeStats(path, byCommand)
+}
+
+// readStats reads the stats file into a map keyed by command.
+func readStats(path string) (map[string]Stats, error) {
+	byCommand := make(map[string]Stats)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return byCommand, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read telemetry stats: %w", err)
+	}
+	if err := json.Unmarshal(data, &byCommand); err != nil {
+		return nil, fmt.Errorf("failed to parse telemetry stats: %w", err)
+	}
+	return byCommand, nil
+}
+
+// writeStats atomically replaces the stats file.
+func writeStats(path string, byCommand map[string]Stats) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+	data, err := json.MarshalIndent(byCommand, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry stats: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write telemetry stats: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace telemetry stats: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package telemetry contains tests for the command telemetry.
+// This file tests the local aggregation of events.
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRecordStats verifies aggregation per command and ordering by average duration.
+func TestRecordStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "telemetry.json")
+
+	events := []Event{
+		{Command: testCommand, DurationMs: 100, Success: true},
+		{Command: testCommand, DurationMs: 300, Success: false},
+		{Command: "version", DurationMs: 5, Success: true},
+		{Command: "drain", DurationMs: 9000, Success: true},
+	}
+	for _, event := range events {
+		if err := RecordStats(path, event); err != nil {
+			t.Fatalf("RecordStats() error = %v", err)
+		}
+	}
+
+	stats, err := LoadStats(path)
+	if err != nil {
+		t.Fatalf("LoadStats() error = %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 commands, got %+v", stats)
+	}
+	if stats[0].Command != "drain" || stats[2].Command != "version" {
+		t.Errorf("expected slowest command first, got %+v", stats)
+	}
+
+	list := stats[1]
+	if list.Count != 2 || list.Failures != 1 || list.AverageMs() != 200 || list.MaxMs != 300 || !list.LastFailed {
+		t.Errorf("unexpected aggregate %+v", list)
+	}
+	if list.FailureRate() != 0.5 {
+		t.Errorf("FailureRate() = %v, want 0.5", list.FailureRate())
+	}
+}
+
+// TestLoadStatsErrors verifies handling of missing and corrupt stats files.
+func TestLoadStatsErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	stats, err := LoadStats(filepath.Join(dir, "missing.json"))
+	if err != nil || len(stats) != 0 {
+		t.Errorf("expected no stats for a missing file, got %+v (%v)", stats, err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0o600); err != nil {
+		t.Fatalf("failed to write corrupt file: %v", err)
+	}
+	if _, err := LoadStats(corrupt); err == nil {
+		t.Error("expected error for a corrupt stats file")
+	}
+	if err := RecordStats(corrupt, Event{Command: testCommand}); err == nil {
+		t.Error("expected RecordStats to refuse overwriting a corrupt stats file")
+	}
+}
@@ -0,0 +1,139 @@
+// Package telemetry provides opt-in, anonymized usage and latency telemetry for CLI commands.
+// Each invocation produces an Event holding only the command name, its duration and whether it
+// succeeded; arguments, flag values and cluster data are never recorded. Events are aggregated
+// in a local stats file and can additionally be POSTed to an endpoint run by a platform team.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// DefaultTimeout bounds the delivery of an event to the endpoint, so telemetry never slows the CLI noticeably.
+const DefaultTimeout = 2 * time.Second
+
+// Event is the anonymized record of a single command invocation.
+type Event struct {
+	// Command is the command path without the binary name, e.g. "list deployments".
+	Command string `json:"command"`
+
+	// DurationMs is the wall-clock duration of the command in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+
+	// Success reports whether the command exited successfully.
+	Success bool `json:"success"`
+
+	// Version is the version of the CLI.
+	Version string `json:"version"`
+
+	// OS and Arch identify the platform the CLI runs on.
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+
+	// Hour is the start of the hour the command ran in; finer timestamps are not recorded.
+	Hour time.Time `json:"hour"`
+}
+
+// Config configures telemetry. Telemetry is disabled unless StatsPath or Endpoint is set.
+type Config struct {
+	// StatsPath is the file aggregating events locally. Empty disables local aggregation.
+	StatsPath string
+
+	// Endpoint receives each event as a JSON POST. Empty disables sending.
+	Endpoint string
+
+	// Timeout bounds each delivery to the endpoint. Zero uses DefaultTimeout.
+	Timeout time.Duration
+
+	// Version is reported with each event.
+	Version string
+}
+
+// Session measures a single command invocation.
+type Session struct {
+	cfg     Config
+	command string
+	start   time.Time
+	now     func() time.Time
+	client  *http.Client
+}
+
+// Start begins measuring a command. It returns nil if telemetry is disabled;
+// all Session methods are safe to call on a nil Session.
+func Start(cfg Config, command string) *Session {
+	if cfg.StatsPath == "" && cfg.Endpoint == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	return &Session{
+		cfg:     cfg,
+		command: command,
+		start:   time.Now(),
+		now:     time.Now,
+		client:  &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Finish records the outcome of the command locally and delivers it to the endpoint.
+// Both steps are attempted; the first error is returned.
+func (s *Session) Finish(ctx context.Context, success bool) error {
+	if s == nil {
+		return nil
+	}
+
+	end := s.now()
+	event := Event{
+		Command:    s.command,
+		DurationMs: end.Sub(s.start).Milliseconds(),
+		Success:    success,
+		Version:    s.cfg.Version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Hour:       end.UTC().Truncate(time.Hour),
+	}
+
+	var err error
+	if s.cfg.StatsPath != "" {
+		err = RecordStats(s.cfg.StatsPath, event)
+	}
+	if s.cfg.Endpoint != "" {
+		if sendErr := s.send(ctx, event); sendErr != nil && err == nil {
+			err = sendErr
+		}
+	}
+	return err
+}
+
+// send POSTs the event as JSON to the endpoint, which must answer with a 2xx status.
+func (s *Session) send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
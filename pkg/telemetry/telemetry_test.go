@@ -0,0 +1,73 @@
+// Package telemetry contains tests for the command telemetry.
+// This file tests sessions and event delivery to the endpoint.
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCommand is the command name used by test sessions.
+const testCommand = "list deployments"
+
+// TestStartDisabled verifies that telemetry is off without a stats path or endpoint.
+func TestStartDisabled(t *testing.T) {
+	session := Start(Config{Version: "v1"}, testCommand)
+	if session != nil {
+		t.Fatal("expected nil session when telemetry is disabled")
+	}
+	if err := session.Finish(context.Background(), true); err != nil {
+		t.Errorf("Finish() on nil session error = %v", err)
+	}
+}
+
+// TestSessionFinish verifies that events are aggregated locally and sent to the endpoint.
+func TestSessionFinish(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"accepted", http.StatusAccepted, false},
+		{"rejected", http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received Event
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Errorf("failed to decode event: %v", err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			statsPath := filepath.Join(t.TempDir(), "telemetry.json")
+			session := Start(Config{StatsPath: statsPath, Endpoint: server.URL, Version: "v1"}, testCommand)
+			session.now = func() time.Time { return session.start.Add(1500 * time.Millisecond) }
+
+			err := session.Finish(context.Background(), false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Finish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if received.Command != testCommand || received.DurationMs != 1500 || received.Success {
+				t.Errorf("unexpected event %+v", received)
+			}
+			if received.Hour.Minute() != 0 || received.Hour.Second() != 0 {
+				t.Errorf("expected event time truncated to the hour, got %v", received.Hour)
+			}
+
+			stats, err := LoadStats(statsPath)
+			if err != nil || len(stats) != 1 || stats[0].Failures != 1 {
+				t.Errorf("expected one failed run to be recorded locally even if sending fails, got %+v (%v)",
+					stats, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package tracing records OpenTelemetry spans of the server and its Kubernetes API calls and exports them to a
+// collector. This file installs the tracer provider, its OTLP/HTTP exporter and the propagation of trace context.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// serviceName is reported as the service.name resource attribute, as with the OTLP metrics backend.
+const serviceName = "k8s-controller"
+
+// DefaultOTLPEndpoint is the OTLP/HTTP traces URL of a collector running next to the server.
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// DefaultSampleRatio records every trace started by the server.
+const DefaultSampleRatio = 1.0
+
+// Config configures the export of spans.
+type Config struct {
+	// Endpoint is the OTLP/HTTP traces URL of the collector, e.g. DefaultOTLPEndpoint; empty disables tracing.
+	Endpoint string
+
+	// SampleRatio is the fraction of the traces started by the server that are recorded, from 0 to 1. Requests
+	// continuing the trace of a caller follow the sampling decision of the caller instead.
+	SampleRatio float64
+
+	// Version is reported as the service.version resource attribute.
+	Version string
+}
+
+// Setup installs a global tracer provider exporting the spans to cfg.Endpoint in batches, and the W3C trace
+// context and baggage propagators, so the server continues the traces of its callers and passes them on to
+// the Kubernetes API. The returned function exports the pending spans and stops exporting. Without an
+// endpoint, Setup installs nothing: spans are not recorded and the returned function does nothing.
+func Setup(cfg Config, logger zerolog.Logger) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if err := validateEndpoint(cfg.Endpoint); err != nil {
+		return nil, err
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid trace sample ratio %g, must be between 0 and 1", cfg.SampleRatio)
+	}
+
+	attributes := []attribute.KeyValue{attribute.String("service.name", serviceName)}
+	if cfg.Version != "" {
+		attributes = append(attributes, attribute.String("service.version", cfg.Version))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attributes...)),
+	)
+
+	// Failed exports are reported to the error handler by the batch span processor.
+	logger = logger.With().Str("component", "tracing").Logger()
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn().Err(err).Msg("Failed to export spans")
+	}))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// validateEndpoint checks that the collector endpoint is an absolute HTTP(S) URL.
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid trace endpoint '%s': %w", endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid trace endpoint '%s', must be an http or https URL", endpoint)
+	}
+	return nil
+}
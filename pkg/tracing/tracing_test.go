@@ -0,0 +1,101 @@
+// Package tracing contains tests for recording and exporting spans.
+// This file tests the setup of the tracer provider.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestSetupValidation verifies that invalid configurations are rejected and that tracing is off without an
+// endpoint.
+func TestSetupValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"no endpoint", Config{}, false},
+		{"no scheme", Config{Endpoint: "otel-collector:4318/v1/traces", SampleRatio: 1}, true},
+		{"unsupported scheme", Config{Endpoint: "grpc://otel-collector:4317", SampleRatio: 1}, true},
+		{"negative sample ratio", Config{Endpoint: DefaultOTLPEndpoint, SampleRatio: -0.5}, true},
+		{"sample ratio above one", Config{Endpoint: DefaultOTLPEndpoint, SampleRatio: 1.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(tt.config, zerolog.Nop())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Setup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if err := shutdown(context.Background()); err != nil {
+					t.Errorf("shutdown() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestSetupExportsSpans verifies that spans of the global tracer are exported with the service resource,
+// and that the trace context of callers is continued.
+func TestSetupExportsSpans(t *testing.T) {
+	requests := make(chan *collectorpb.ExportTraceServiceRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := &collectorpb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		requests <- request
+	}))
+	defer collector.Close()
+	defer func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	config := Config{Endpoint: collector.URL + "/v1/traces", SampleRatio: 0, Version: "v1.2.0"}
+	shutdown, err := Setup(config, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	// A sampled caller is traced despite the sample ratio of 0.
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	carrier := propagation.MapCarrier{"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	_, span := otel.Tracer("test").Start(ctx, "request")
+	span.End()
+	// Unsampled traces started here are not exported.
+	_, span = otel.Tracer("test").Start(context.Background(), "unsampled")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	request := <-requests
+
+	resource := map[string]string{}
+	for _, attr := range request.ResourceSpans[0].Resource.Attributes {
+		resource[attr.Key] = attr.Value.GetStringValue()
+	}
+	if resource["service.name"] != "k8s-controller" || resource["service.version"] != "v1.2.0" {
+		t.Errorf("unexpected resource attributes %v", resource)
+	}
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 || spans[0].Name != "request" || hex.EncodeToString(spans[0].TraceId) != traceID {
+		t.Errorf("expected only the span continuing trace %s, got %+v", traceID, spans)
+	}
+}